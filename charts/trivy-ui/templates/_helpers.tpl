@@ -67,3 +67,14 @@ Create the namespace name
 {{- define "trivy-ui.namespace" -}}
 {{- default .Release.Namespace .Values.namespace }}
 {{- end }}
+
+{{/*
+Join a map as comma-separated key=value pairs, the format of list variables such as REPORT_RETENTION
+*/}}
+{{- define "trivy-ui.keyValues" -}}
+{{- $pairs := list }}
+{{- range $key, $value := . }}
+{{- $pairs = append $pairs (printf "%s=%v" $key $value) }}
+{{- end }}
+{{- join "," $pairs }}
+{{- end }}
@@ -0,0 +1,65 @@
+// Package agent implements the push side of agent mode: an agent running inside a cluster
+// forwards informer events to trivy-ui instead of trivy-ui watching the cluster itself.
+package agent
+
+import (
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+// PushPath is the server endpoint agents post batches to.
+const PushPath = "/api/v1/agent/events"
+
+// Event operations mirror the methods of kubernetes.CacheUpdater.
+const (
+	OpSet        = "set"
+	OpDelete     = "delete"
+	OpInvalidate = "invalidate"
+	OpSyncState  = "sync"
+	// OpDeleteNamespace carries the deleted namespace in Namespace
+	OpDeleteNamespace = "deletenamespace"
+)
+
+type Event struct {
+	Op        string               `json:"op"`
+	Namespace string               `json:"namespace,omitempty"`
+	Type      string               `json:"type,omitempty"`
+	Name      string               `json:"name,omitempty"`
+	Status    string               `json:"status,omitempty"`
+	Data      interface{}          `json:"data,omitempty"`
+	Findings  []kubernetes.Finding `json:"findings,omitempty"`
+	ScannedAt time.Time            `json:"scannedAt,omitzero"`
+	State     string               `json:"state,omitempty"`
+	// UID and ResourceVersion are the report CR's
+	UID             string `json:"uid,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// Batch is one push request. Namespaces and Kinds are sent with every batch so the
+// server can rebuild its view of the cluster after a restart.
+type Batch struct {
+	Cluster    string              `json:"cluster"`
+	Version    string              `json:"version,omitempty"`
+	Namespaces []string            `json:"namespaces,omitempty"`
+	Kinds      []config.ReportKind `json:"kinds,omitempty"`
+	Events     []Event             `json:"events"`
+}
+
+// Report converts a set event back into the informer report it was created from.
+func (e Event) Report(cluster string) *kubernetes.Report {
+	return &kubernetes.Report{
+		Type:      e.Type,
+		Cluster:   cluster,
+		Namespace: e.Namespace,
+		Name:      e.Name,
+		Status:    e.Status,
+		Data:      e.Data,
+		Findings:  e.Findings,
+		ScannedAt: e.ScannedAt,
+
+		UID:             e.UID,
+		ResourceVersion: e.ResourceVersion,
+	}
+}
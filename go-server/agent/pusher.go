@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
+)
+
+const (
+	defaultFlushInterval = 2 * time.Second
+	maxBatchEvents       = 500
+	// maxQueuedEvents bounds memory while the server is unreachable; the informer resync
+	// re-sends every report, so dropped events are recovered eventually.
+	maxQueuedEvents = 20000
+)
+
+type PusherOptions struct {
+	ServerURL     string
+	Cluster       string
+	Token         string
+	TLSConfig     *tls.Config
+	FlushInterval time.Duration
+	// ClusterInfo returns the version and namespaces attached to every batch.
+	ClusterInfo func(ctx context.Context) (version string, namespaces []string)
+}
+
+// Pusher implements kubernetes.CacheUpdater by queueing events and posting them to trivy-ui.
+type Pusher struct {
+	opts   PusherOptions
+	client *http.Client
+
+	mu      sync.Mutex
+	queue   []Event
+	dropped int
+	wake    chan struct{}
+}
+
+var _ kubernetes.CacheUpdater = (*Pusher)(nil)
+
+func NewPusher(opts PusherOptions) *Pusher {
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	return &Pusher{
+		opts: opts,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: opts.TLSConfig, Proxy: http.ProxyFromEnvironment},
+		},
+		wake: make(chan struct{}, 1),
+	}
+}
+
+func (p *Pusher) enqueue(e Event) {
+	p.mu.Lock()
+	if len(p.queue) >= maxQueuedEvents {
+		p.queue = p.queue[1:]
+		p.dropped++
+	}
+	p.queue = append(p.queue, e)
+	full := len(p.queue) >= maxBatchEvents
+	p.mu.Unlock()
+
+	if full {
+		select {
+		case p.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (p *Pusher) SetReport(cluster, namespace, reportType, name string, report *kubernetes.Report) {
+	p.enqueue(Event{
+		Op:        OpSet,
+		Namespace: namespace,
+		Type:      reportType,
+		Name:      name,
+		Status:    report.Status,
+		Data:      report.Data,
+		Findings:  report.Findings,
+		ScannedAt: report.ScannedAt,
+
+		UID:             report.UID,
+		ResourceVersion: report.ResourceVersion,
+	})
+}
+
+func (p *Pusher) DeleteReport(cluster, namespace, reportType, name string) {
+	p.enqueue(Event{Op: OpDelete, Namespace: namespace, Type: reportType, Name: name})
+}
+
+func (p *Pusher) DeleteNamespace(cluster, namespace string) {
+	p.enqueue(Event{Op: OpDeleteNamespace, Namespace: namespace})
+}
+
+func (p *Pusher) InvalidateReportDetail(cluster, namespace, reportType, name string) {
+	p.enqueue(Event{Op: OpInvalidate, Namespace: namespace, Type: reportType, Name: name})
+}
+
+func (p *Pusher) UpdateSyncState(clusterName string, state string) {
+	p.enqueue(Event{Op: OpSyncState, State: state})
+}
+
+// Run flushes the queue until ctx is cancelled. An empty flush still posts a batch,
+// which doubles as the agent heartbeat.
+func (p *Pusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.opts.FlushInterval)
+	defer ticker.Stop()
+
+	heartbeat := time.Now()
+	backoff := time.Duration(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+		if backoff > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+		}
+
+		p.mu.Lock()
+		n := min(len(p.queue), maxBatchEvents)
+		events := append([]Event(nil), p.queue[:n]...)
+		droppedBefore := p.dropped
+		p.mu.Unlock()
+
+		if len(events) == 0 && time.Since(heartbeat) < 30*time.Second {
+			continue
+		}
+
+		if err := p.push(ctx, events); err != nil {
+			backoff = min(max(2*backoff, time.Second), time.Minute)
+			utils.LogWarning("Failed to push events, will retry", map[string]interface{}{
+				"events":  len(events),
+				"backoff": backoff.String(),
+				"error":   err.Error(),
+			})
+			continue
+		}
+		backoff = 0
+		heartbeat = time.Now()
+
+		p.mu.Lock()
+		// events dropped by enqueue while pushing came off the front of the sent range
+		n = max(n-(p.dropped-droppedBefore), 0)
+		p.queue = p.queue[n:]
+		p.mu.Unlock()
+	}
+}
+
+func (p *Pusher) push(ctx context.Context, events []Event) error {
+	batch := Batch{
+		Cluster: p.opts.Cluster,
+		Kinds:   config.GetGlobalRegistry().GetAllReports(),
+		Events:  events,
+	}
+	if p.opts.ClusterInfo != nil {
+		batch.Version, batch.Namespaces = p.opts.ClusterInfo(ctx)
+	}
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.opts.ServerURL, "/")+PushPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.opts.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"trivy-ui/kubernetes"
+)
+
+func TestPusher_PostsQueuedEvents(t *testing.T) {
+	batches := make(chan Batch, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PushPath || r.Header.Get("Authorization") != "Bearer bootstrap" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var b Batch
+		json.NewDecoder(r.Body).Decode(&b)
+		batches <- b
+	}))
+	defer srv.Close()
+
+	p := NewPusher(PusherOptions{ServerURL: srv.URL, Cluster: "edge-1", Token: "bootstrap", FlushInterval: 10 * time.Millisecond})
+	p.SetReport("edge-1", "default", "vulnerabilityreports", "app", &kubernetes.Report{
+		Status:   "Critical",
+		Findings: []kubernetes.Finding{{VulnerabilityID: "CVE-1", Severity: "CRITICAL"}},
+	})
+	p.DeleteReport("edge-1", "default", "vulnerabilityreports", "old")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	select {
+	case b := <-batches:
+		if b.Cluster != "edge-1" || len(b.Events) != 2 {
+			t.Fatalf("unexpected batch %+v", b)
+		}
+		if b.Events[0].Op != OpSet || len(b.Events[0].Findings) != 1 || b.Events[1].Op != OpDelete {
+			t.Fatalf("unexpected events %+v", b.Events)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no batch received")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		p.mu.Lock()
+		n := len(p.queue)
+		p.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("queue not drained after successful push")
+}
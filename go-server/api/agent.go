@@ -0,0 +1,128 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"trivy-ui/agent"
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
+)
+
+const maxAgentBatchBytes = 32 << 20
+
+// AgentAuthenticator resolves the cluster a push request is allowed to write to.
+type AgentAuthenticator interface {
+	Authenticate(r *http.Request) (cluster string, ok bool)
+}
+
+// tokenAuthenticator accepts bearer tokens bound to a name: the cluster for agent
+// bootstrap tokens, the user for AUTH_TOKENS.
+type tokenAuthenticator struct {
+	tokens map[string]string // name -> token
+}
+
+func (a tokenAuthenticator) Authenticate(r *http.Request) (string, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return "", false
+	}
+	for cluster, expected := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			return cluster, true
+		}
+	}
+	return "", false
+}
+
+// certAuthenticator accepts client certificates verified against AGENT_CA_FILE by the TLS
+// listener; the certificate common name is the cluster name.
+type certAuthenticator struct{}
+
+func (certAuthenticator) Authenticate(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	return cn, cn != ""
+}
+
+func agentAuthenticators(cfg *config.Config) []AgentAuthenticator {
+	var auths []AgentAuthenticator
+	if cfg.AgentCAFile != "" {
+		auths = append(auths, certAuthenticator{})
+	}
+	if len(cfg.AgentTokens) > 0 {
+		auths = append(auths, tokenAuthenticator{tokens: cfg.AgentTokens})
+	}
+	return auths
+}
+
+// PushAgentEvents applies a batch of informer events pushed by an in-cluster agent.
+// The pushed cluster is registered like a watched one, without a Kubernetes client.
+func (h *Handler) PushAgentEvents(w http.ResponseWriter, r *http.Request) {
+	auths := agentAuthenticators(config.Get())
+	if len(auths) == 0 {
+		writeError(w, http.StatusNotFound, "Agent push is not enabled")
+		return
+	}
+	cluster := ""
+	for _, a := range auths {
+		if c, ok := a.Authenticate(r); ok {
+			cluster = c
+			break
+		}
+	}
+	if cluster == "" {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var batch agent.Batch
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAgentBatchBytes)).Decode(&batch); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if batch.Cluster != "" && batch.Cluster != cluster {
+		writeError(w, http.StatusForbidden, "Credentials are not valid for cluster "+batch.Cluster)
+		return
+	}
+	if cc := h.clusterReg.Get(cluster); cc != nil && !cc.Pushed {
+		writeError(w, http.StatusConflict, "Cluster is already watched directly")
+		return
+	}
+
+	h.crdReg.Register(batch.Kinds...)
+	h.clusterReg.RegisterPushed(cluster, batch.Version, batch.Namespaces)
+	applyAgentEvents(NewCacheUpdater(h.clusterReg), cluster, batch.Events)
+
+	utils.LogModuleDebug(utils.ModuleHandlers, "Applied agent events", map[string]interface{}{
+		"cluster": cluster,
+		"events":  len(batch.Events),
+	})
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    map[string]int{"applied": len(batch.Events)},
+	})
+}
+
+func applyAgentEvents(updater kubernetes.CacheUpdater, cluster string, events []agent.Event) {
+	for _, e := range events {
+		switch e.Op {
+		case agent.OpSet:
+			updater.SetReport(cluster, e.Namespace, e.Type, e.Name, e.Report(cluster))
+		case agent.OpDelete:
+			updater.DeleteReport(cluster, e.Namespace, e.Type, e.Name)
+		case agent.OpDeleteNamespace:
+			updater.DeleteNamespace(cluster, e.Namespace)
+		case agent.OpInvalidate:
+			updater.InvalidateReportDetail(cluster, e.Namespace, e.Type, e.Name)
+		case agent.OpSyncState:
+			updater.UpdateSyncState(cluster, e.State)
+		}
+	}
+}
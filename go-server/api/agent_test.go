@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"trivy-ui/agent"
+	"trivy-ui/kubernetes"
+)
+
+func TestTokenAuthenticator(t *testing.T) {
+	auth := tokenAuthenticator{tokens: map[string]string{"edge-1": "t1", "edge-2": "t2"}}
+
+	r := httptest.NewRequest("POST", agent.PushPath, nil)
+	r.Header.Set("Authorization", "Bearer t2")
+	if cluster, ok := auth.Authenticate(r); !ok || cluster != "edge-2" {
+		t.Fatalf("expected edge-2, got %q %v", cluster, ok)
+	}
+
+	r.Header.Set("Authorization", "Bearer wrong")
+	if _, ok := auth.Authenticate(r); ok {
+		t.Fatal("expected invalid token to be rejected")
+	}
+}
+
+type recordingUpdater struct {
+	kubernetes.CacheUpdater
+	calls []string
+}
+
+func (u *recordingUpdater) SetReport(cluster, namespace, reportType, name string, report *kubernetes.Report) {
+	u.calls = append(u.calls, "set:"+cluster+":"+name+":"+report.Status)
+}
+
+func (u *recordingUpdater) DeleteReport(cluster, namespace, reportType, name string) {
+	u.calls = append(u.calls, "delete:"+cluster+":"+name)
+}
+
+func TestApplyAgentEvents_UsesAuthenticatedCluster(t *testing.T) {
+	u := &recordingUpdater{}
+	applyAgentEvents(u, "edge-1", []agent.Event{
+		{Op: agent.OpSet, Namespace: "ns", Type: "vulnerabilityreports", Name: "a", Status: "High"},
+		{Op: agent.OpDelete, Namespace: "ns", Type: "vulnerabilityreports", Name: "b"},
+		{Op: "unknown"},
+	})
+	if len(u.calls) != 2 || u.calls[0] != "set:edge-1:a:High" || u.calls[1] != "delete:edge-1:b" {
+		t.Fatalf("unexpected calls %v", u.calls)
+	}
+}
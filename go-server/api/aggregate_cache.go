@@ -0,0 +1,137 @@
+package api
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// aggregateMaxAge bounds how long a result is served without any invalidation, for
+// inputs that change without informer events (SLA ageing, triage state).
+const aggregateMaxAge = 5 * time.Minute
+
+type aggregateEntry struct {
+	value      interface{}
+	computedAt time.Time
+}
+
+type AggregateStats struct {
+	Endpoint       string  `json:"endpoint"`
+	Hits           int64   `json:"hits"`
+	Recomputes     int64   `json:"recomputes"`
+	Invalidations  int64   `json:"invalidations"`
+	Entries        int     `json:"entries"`
+	LastComputeMs  float64 `json:"lastComputeMs"`
+	TotalComputeMs float64 `json:"totalComputeMs"`
+}
+
+// aggregateCache memoizes results of aggregate endpoints keyed by endpoint, scope and
+// filter params. A scope is a cluster name, or "" for fleet-wide results; a report change
+// in a cluster invalidates that cluster's scope and the fleet-wide scope.
+type aggregateCache struct {
+	mu      sync.Mutex
+	entries map[string]aggregateEntry
+	// generations detect results computed concurrently with an invalidation
+	generations map[string]uint64
+	stats       map[string]*AggregateStats
+}
+
+var aggregates = newAggregateCache()
+
+func newAggregateCache() *aggregateCache {
+	return &aggregateCache{
+		entries:     make(map[string]aggregateEntry),
+		generations: make(map[string]uint64),
+		stats:       make(map[string]*AggregateStats),
+	}
+}
+
+func aggregateKey(endpoint, scope, params string) string {
+	return endpoint + "\x00" + scope + "\x00" + params
+}
+
+func (a *aggregateCache) endpointStats(endpoint string) *AggregateStats {
+	s := a.stats[endpoint]
+	if s == nil {
+		s = &AggregateStats{Endpoint: endpoint}
+		a.stats[endpoint] = s
+	}
+	return s
+}
+
+// getOrCompute returns the cached result or runs compute and caches it.
+func (a *aggregateCache) getOrCompute(endpoint, scope, params string, compute func() interface{}) interface{} {
+	key := aggregateKey(endpoint, scope, params)
+
+	a.mu.Lock()
+	if e, ok := a.entries[key]; ok && time.Since(e.computedAt) < aggregateMaxAge {
+		a.endpointStats(endpoint).Hits++
+		a.mu.Unlock()
+		return e.value
+	}
+	gen := a.generations[scope]
+	a.mu.Unlock()
+
+	start := time.Now()
+	value := compute()
+	elapsed := float64(time.Since(start).Microseconds()) / 1000
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.endpointStats(endpoint)
+	s.Recomputes++
+	s.LastComputeMs = elapsed
+	s.TotalComputeMs += elapsed
+	if a.generations[scope] == gen {
+		a.entries[key] = aggregateEntry{value: value, computedAt: time.Now()}
+	}
+	return value
+}
+
+// invalidate drops results for cluster and fleet-wide results.
+func (a *aggregateCache) invalidate(cluster string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.generations[cluster]++
+	if cluster != "" {
+		a.generations[""]++
+	}
+	for key := range a.entries {
+		parts := strings.SplitN(key, "\x00", 3)
+		if len(parts) == 3 && (parts[1] == cluster || parts[1] == "") {
+			delete(a.entries, key)
+			a.endpointStats(parts[0]).Invalidations++
+		}
+	}
+}
+
+// invalidateAll drops every result, e.g. after triage changes that affect SLA figures.
+func (a *aggregateCache) invalidateAll() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key := range a.entries {
+		parts := strings.SplitN(key, "\x00", 3)
+		a.generations[parts[1]]++
+		a.endpointStats(parts[0]).Invalidations++
+	}
+	a.generations[""]++
+	a.entries = make(map[string]aggregateEntry)
+}
+
+func (a *aggregateCache) snapshot() []AggregateStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entries := make(map[string]int)
+	for key := range a.entries {
+		entries[strings.SplitN(key, "\x00", 2)[0]]++
+	}
+	result := make([]AggregateStats, 0, len(a.stats))
+	for endpoint, s := range a.stats {
+		stat := *s
+		stat.Entries = entries[endpoint]
+		result = append(result, stat)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Endpoint < result[j].Endpoint })
+	return result
+}
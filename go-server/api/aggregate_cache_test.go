@@ -0,0 +1,50 @@
+package api
+
+import "testing"
+
+func TestAggregateCacheInvalidation(t *testing.T) {
+	a := newAggregateCache()
+	calls := 0
+	compute := func() interface{} {
+		calls++
+		return calls
+	}
+
+	a.getOrCompute("overview", "prod", "prod", compute)
+	a.getOrCompute("overview", "prod", "prod", compute)
+	a.getOrCompute("overview", "", "", compute)
+	a.getOrCompute("overview", "dev", "dev", compute)
+	if calls != 3 {
+		t.Fatalf("expected 3 computes, got %d", calls)
+	}
+
+	// a prod change drops the prod and fleet-wide results but keeps dev
+	a.invalidate("prod")
+	a.getOrCompute("overview", "prod", "prod", compute)
+	a.getOrCompute("overview", "", "", compute)
+	a.getOrCompute("overview", "dev", "dev", compute)
+	if calls != 5 {
+		t.Fatalf("expected 5 computes after invalidation, got %d", calls)
+	}
+
+	stats := a.snapshot()
+	if len(stats) != 1 {
+		t.Fatalf("expected stats for one endpoint, got %d", len(stats))
+	}
+	s := stats[0]
+	if s.Hits != 2 || s.Recomputes != 5 || s.Invalidations != 2 || s.Entries != 3 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
+
+func TestAggregateCacheDiscardsResultComputedDuringInvalidation(t *testing.T) {
+	a := newAggregateCache()
+	a.getOrCompute("base-images", "prod", "p", func() interface{} {
+		a.invalidate("prod")
+		return "stale"
+	})
+	got := a.getOrCompute("base-images", "prod", "p", func() interface{} { return "fresh" })
+	if got != "fresh" {
+		t.Errorf("expected stale result to be discarded, got %v", got)
+	}
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/utils"
+)
+
+const (
+	// maxFiringAlerts bounds the alerts kept in memory; the oldest are dropped first
+	maxFiringAlerts = 1000
+	// alertDefaultTTL is how long a firing alert without endsAt is shown after the last
+	// notification; Alertmanager re-sends firing alerts every repeat_interval
+	alertDefaultTTL = 24 * time.Hour
+
+	alertNamespaceLabel = "namespace"
+
+	maxAlertmanagerBodyBytes = 4 << 20
+)
+
+// alertmanagerPayload is the body of an Alertmanager webhook notification (version 4).
+type alertmanagerPayload struct {
+	Version  string              `json:"version"`
+	Status   string              `json:"status"`
+	Receiver string              `json:"receiver"`
+	Alerts   []alertmanagerAlert `json:"alerts"`
+}
+
+type alertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// UIAlert is a firing Alertmanager alert shown as a banner on the views of its cluster and
+// namespace. Alerts without a cluster label are shown everywhere.
+type UIAlert struct {
+	Fingerprint  string            `json:"fingerprint"`
+	Name         string            `json:"name"`
+	Severity     string            `json:"severity,omitempty"`
+	Cluster      string            `json:"cluster,omitempty"`
+	Namespace    string            `json:"namespace,omitempty"`
+	Summary      string            `json:"summary,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt,omitzero"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+	Receiver     string            `json:"receiver,omitempty"`
+	Labels       map[string]string `json:"labels"`
+	receivedAt   time.Time
+}
+
+// relevantTo reports whether an alert belongs on the view of a cluster and namespace; an
+// empty cluster or namespace is a view of everything below it.
+func (a UIAlert) relevantTo(cluster, namespace string) bool {
+	if a.Cluster != "" && cluster != "" && a.Cluster != cluster {
+		return false
+	}
+	return a.Namespace == "" || namespace == "" || a.Namespace == namespace
+}
+
+func (a UIAlert) expired(now time.Time) bool {
+	if !a.EndsAt.IsZero() {
+		return !now.Before(a.EndsAt)
+	}
+	return now.Sub(a.receivedAt) > alertDefaultTTL
+}
+
+// alertBoard holds the firing alerts by fingerprint.
+type alertBoard struct {
+	mu     sync.Mutex
+	alerts map[string]UIAlert
+}
+
+var firingAlerts = newAlertBoard()
+
+func newAlertBoard() *alertBoard {
+	return &alertBoard{alerts: make(map[string]UIAlert)}
+}
+
+// apply records firing alerts and removes resolved ones; it returns how many of each.
+func (b *alertBoard) apply(payload alertmanagerPayload, clusterLabels []string, now time.Time) (firing, resolved int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, a := range payload.Alerts {
+		key := a.Fingerprint
+		if key == "" {
+			key = labelsKey(a.Labels)
+		}
+		if a.Status == "resolved" {
+			delete(b.alerts, key)
+			resolved++
+			continue
+		}
+		alert := UIAlert{
+			Fingerprint:  key,
+			Name:         a.Labels["alertname"],
+			Severity:     a.Labels["severity"],
+			Namespace:    a.Labels[alertNamespaceLabel],
+			Summary:      a.Annotations["summary"],
+			Description:  a.Annotations["description"],
+			StartsAt:     a.StartsAt,
+			GeneratorURL: a.GeneratorURL,
+			Receiver:     payload.Receiver,
+			Labels:       a.Labels,
+			receivedAt:   now,
+		}
+		// Alertmanager sets endsAt on firing alerts to their resolve timeout
+		if a.EndsAt.After(now) {
+			alert.EndsAt = a.EndsAt
+		}
+		for _, label := range clusterLabels {
+			if v := a.Labels[label]; v != "" {
+				alert.Cluster = v
+				break
+			}
+		}
+		b.alerts[key] = alert
+		firing++
+	}
+	b.pruneLocked(now)
+	return firing, resolved
+}
+
+func (b *alertBoard) pruneLocked(now time.Time) {
+	for key, a := range b.alerts {
+		if a.expired(now) {
+			delete(b.alerts, key)
+		}
+	}
+	if len(b.alerts) <= maxFiringAlerts {
+		return
+	}
+	keys := make([]string, 0, len(b.alerts))
+	for key := range b.alerts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return b.alerts[keys[i]].receivedAt.Before(b.alerts[keys[j]].receivedAt) })
+	for _, key := range keys[:len(keys)-maxFiringAlerts] {
+		delete(b.alerts, key)
+	}
+}
+
+// list returns the alerts relevant to a view, most recently started first.
+func (b *alertBoard) list(cluster, namespace string, now time.Time) []UIAlert {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pruneLocked(now)
+	result := []UIAlert{}
+	for _, a := range b.alerts {
+		if a.relevantTo(cluster, namespace) {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].StartsAt.Equal(result[j].StartsAt) {
+			return result[i].StartsAt.After(result[j].StartsAt)
+		}
+		return result[i].Fingerprint < result[j].Fingerprint
+	})
+	return result
+}
+
+// countByCluster counts the firing alerts scoped to each cluster.
+func (b *alertBoard) countByCluster(now time.Time) map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pruneLocked(now)
+	counts := make(map[string]int)
+	for _, a := range b.alerts {
+		if a.Cluster != "" {
+			counts[a.Cluster]++
+		}
+	}
+	return counts
+}
+
+// labelsKey identifies an alert by its label set when the sender gave no fingerprint.
+func labelsKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// ReceiveAlertmanagerWebhook accepts Alertmanager webhook notifications; firing alerts
+// are kept for the UI until they resolve or expire.
+func (h *Handler) ReceiveAlertmanagerWebhook(w http.ResponseWriter, r *http.Request) {
+	var payload alertmanagerPayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAlertmanagerBodyBytes)).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid Alertmanager payload")
+		return
+	}
+	firing, resolved := firingAlerts.apply(payload, config.Get().AlertClusterLabels, time.Now())
+	utils.LogModuleDebug(utils.ModuleHandlers, "Received Alertmanager notification", map[string]interface{}{
+		"receiver": payload.Receiver,
+		"firing":   firing,
+		"resolved": resolved,
+	})
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    map[string]int{"firing": firing, "resolved": resolved},
+	})
+}
+
+// GetAlerts lists the firing alerts for the view given by the cluster and namespace
+// parameters.
+func (h *Handler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    firingAlerts.list(q.Get("cluster"), q.Get("namespace"), time.Now()),
+	})
+}
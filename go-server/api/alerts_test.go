@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const alertmanagerBody = `{
+  "version": "4", "status": "firing", "receiver": "trivy-ui",
+  "alerts": [
+    {"status": "firing", "fingerprint": "a1", "startsAt": "2026-03-09T10:00:00Z",
+     "labels": {"alertname": "KubeAPIDown", "severity": "critical", "cluster": "prod"},
+     "annotations": {"summary": "API server unreachable"}},
+    {"status": "firing", "fingerprint": "a2", "startsAt": "2026-03-09T11:00:00Z",
+     "labels": {"alertname": "PodCrashLooping", "cluster": "prod", "namespace": "payments"}},
+    {"status": "firing", "fingerprint": "a3", "startsAt": "2026-03-09T09:00:00Z",
+     "labels": {"alertname": "Watchdog"}}
+  ]
+}`
+
+func useTestAlertBoard(t *testing.T) *alertBoard {
+	t.Helper()
+	prev := firingAlerts
+	firingAlerts = newAlertBoard()
+	t.Cleanup(func() { firingAlerts = prev })
+	return firingAlerts
+}
+
+func TestAlertmanagerWebhook(t *testing.T) {
+	useTestAlertBoard(t)
+	h := &Handler{}
+
+	rec := httptest.NewRecorder()
+	h.ReceiveAlertmanagerWebhook(rec, httptest.NewRequest(http.MethodPost, "/api/v1/integrations/alertmanager", strings.NewReader(alertmanagerBody)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"firing":3`) {
+		t.Fatalf("webhook: %d %s", rec.Code, rec.Body.String())
+	}
+
+	names := func(query string) []string {
+		rec := httptest.NewRecorder()
+		h.GetAlerts(rec, httptest.NewRequest(http.MethodGet, "/api/v1/alerts"+query, nil))
+		var resp struct {
+			Data []UIAlert `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		var result []string
+		for _, a := range resp.Data {
+			result = append(result, a.Name)
+		}
+		return result
+	}
+	if got := fmt.Sprint(names("")); got != "[PodCrashLooping KubeAPIDown Watchdog]" {
+		t.Fatalf("all alerts = %s", got)
+	}
+	if got := fmt.Sprint(names("?cluster=staging")); got != "[Watchdog]" {
+		t.Fatalf("staging alerts = %s", got)
+	}
+	if got := fmt.Sprint(names("?cluster=prod&namespace=default")); got != "[KubeAPIDown Watchdog]" {
+		t.Fatalf("prod/default alerts = %s", got)
+	}
+
+	resolved := `{"status": "resolved", "alerts": [{"status": "resolved", "fingerprint": "a1", "labels": {"alertname": "KubeAPIDown"}}]}`
+	rec = httptest.NewRecorder()
+	h.ReceiveAlertmanagerWebhook(rec, httptest.NewRequest(http.MethodPost, "/api/v1/integrations/alertmanager", strings.NewReader(resolved)))
+	if got := fmt.Sprint(names("?cluster=prod")); got != "[PodCrashLooping Watchdog]" {
+		t.Fatalf("alerts after resolve = %s", got)
+	}
+	if counts := firingAlerts.countByCluster(time.Now()); counts["prod"] != 1 || len(counts) != 1 {
+		t.Fatalf("counts = %v", counts)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ReceiveAlertmanagerWebhook(rec, httptest.NewRequest(http.MethodPost, "/api/v1/integrations/alertmanager", strings.NewReader("{")))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("malformed payload: %d", rec.Code)
+	}
+}
+
+func TestAlertBoardExpiry(t *testing.T) {
+	b := newAlertBoard()
+	now := time.Now()
+	payload := alertmanagerPayload{Alerts: []alertmanagerAlert{
+		{Status: "firing", Labels: map[string]string{"alertname": "Short", "k8s_cluster": "lab"}, EndsAt: now.Add(time.Minute)},
+		{Status: "firing", Labels: map[string]string{"alertname": "Long"}},
+	}}
+	b.apply(payload, []string{"cluster", "k8s_cluster"}, now)
+	if got := b.list("lab", "", now); len(got) != 2 || got[0].Cluster != "lab" && got[1].Cluster != "lab" {
+		t.Fatalf("alerts = %+v", got)
+	}
+	if got := b.list("", "", now.Add(2*time.Minute)); len(got) != 1 || got[0].Name != "Long" {
+		t.Fatalf("alerts after endsAt = %+v", got)
+	}
+	if got := b.list("", "", now.Add(alertDefaultTTL+time.Minute)); len(got) != 0 {
+		t.Fatalf("alerts after TTL = %+v", got)
+	}
+
+	many := alertmanagerPayload{}
+	for i := 0; i < maxFiringAlerts+10; i++ {
+		many.Alerts = append(many.Alerts, alertmanagerAlert{Status: "firing", Fingerprint: fmt.Sprint(i)})
+	}
+	b.apply(many, nil, now)
+	if n := len(b.list("", "", now)); n != maxFiringAlerts {
+		t.Fatalf("kept %d alerts, want %d", n, maxFiringAlerts)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// archiveReport copies the cached summary of a report into the store before it is
+// removed from the cache, when ARCHIVE_DELETED_REPORTS is enabled.
+func archiveReport(c *Cache, key string) {
+	if !config.Get().ArchiveDeleted {
+		return
+	}
+	st := store.Get()
+	if st == nil {
+		return
+	}
+	value, found := c.Get(key)
+	if !found {
+		return
+	}
+	report, ok := convertCacheValue[Report](value)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := storeCallContext(context.Background())
+	defer cancel()
+	err := st.ArchiveReport(ctx, store.ArchivedReport{
+		ReportRef: store.ReportRef{
+			Cluster:    report.Cluster,
+			Namespace:  report.Namespace,
+			ReportType: report.Type,
+			ReportName: report.Name,
+		},
+		Status:     report.Status,
+		Data:       report.Data,
+		ArchivedAt: time.Now(),
+	})
+	if err != nil {
+		utils.LogWarning("Failed to archive deleted report", map[string]interface{}{"key": key, "error": err.Error()})
+	}
+}
+
+func (h *Handler) ListArchivedReports(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := store.ArchiveFilter{
+		Cluster:    q.Get("cluster"),
+		Namespace:  q.Get("namespace"),
+		ReportType: q.Get("type"),
+	}
+	if since := q.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid since parameter, expected RFC3339")
+			return
+		}
+		filter.Since = t
+	}
+
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	reports, err := st.ListArchived(ctx, filter)
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		utils.LogWarning("Failed to list archived reports", map[string]interface{}{"error": err.Error()})
+		writeError(w, http.StatusInternalServerError, "Failed to list archived reports")
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    reports,
+	})
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/credentials"
+	"trivy-ui/signatures"
+	"trivy-ui/utils"
+)
+
+const (
+	signatureCacheTTL = 10 * time.Minute
+	signatureTimeout  = 30 * time.Second
+)
+
+var (
+	signaturePolicyOnce sync.Once
+	signaturePolicyMu   sync.RWMutex
+	signaturePolicy     *signatures.Policy
+
+	signatureRegistry = &signatures.Registry{
+		HTTP: &http.Client{Timeout: signatureTimeout},
+		Auth: func(string) (string, string) {
+			return credentials.Get(credentials.RegistryUsername), credentials.Get(credentials.RegistryPassword)
+		},
+	}
+
+	signatureResultsMu sync.Mutex
+	signatureResults   = make(map[string]signatures.Result)
+)
+
+// signatureStatusRank orders statuses from the one that says most about an image.
+var signatureStatusRank = map[string]int{
+	signatures.StatusVerified:   3,
+	signatures.StatusUnverified: 2,
+	signatures.StatusError:      1,
+	signatures.StatusUnsigned:   0,
+}
+
+// ImageAttestations is the signature and attestation status of an image next to the
+// findings of its latest scan.
+type ImageAttestations struct {
+	Digest    string         `json:"digest"`
+	Images    []string       `json:"images"`
+	Clusters  []string       `json:"clusters"`
+	Workloads int            `json:"workloads"`
+	Severity  SeverityTotals `json:"severity"`
+	// Status is the best status of the repositories the image is pulled from
+	Status string `json:"status"`
+	// UnsignedVulnerable flags images without a verified signature that have critical or
+	// high findings
+	UnsignedVulnerable bool                `json:"unsignedVulnerable"`
+	Repositories       []signatures.Result `json:"repositories"`
+}
+
+// getSignaturePolicy loads SIGNATURE_POLICY_FILE once, and again on a config reload;
+// without a valid policy signatures are listed but none is verified.
+func getSignaturePolicy() *signatures.Policy {
+	signaturePolicyOnce.Do(func() {
+		path := config.Get().SignaturePolicyFile
+		if path == "" {
+			return
+		}
+		policy, err := signatures.LoadPolicy(path)
+		if err != nil {
+			utils.LogWarning("Failed to load signature policy, signatures are not verified", map[string]interface{}{"path": path, "error": err.Error()})
+			return
+		}
+		setSignaturePolicy(policy)
+	})
+	signaturePolicyMu.RLock()
+	defer signaturePolicyMu.RUnlock()
+	return signaturePolicy
+}
+
+// setSignaturePolicy replaces the policy and forgets results checked against the old one.
+func setSignaturePolicy(policy *signatures.Policy) {
+	signaturePolicyOnce.Do(func() {})
+	signaturePolicyMu.Lock()
+	signaturePolicy = policy
+	signaturePolicyMu.Unlock()
+	signatureResultsMu.Lock()
+	signatureResults = make(map[string]signatures.Result)
+	signatureResultsMu.Unlock()
+}
+
+// verifyImage checks an image's signatures in a repository, reusing results younger than
+// signatureCacheTTL unless refresh is set.
+func verifyImage(ctx context.Context, repository, digest string, refresh bool) signatures.Result {
+	key := repository + "@" + digest
+	signatureResultsMu.Lock()
+	cached, ok := signatureResults[key]
+	signatureResultsMu.Unlock()
+	if ok && !refresh && time.Since(cached.CheckedAt) < signatureCacheTTL {
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, signatureTimeout)
+	defer cancel()
+	verifier := &signatures.Verifier{Policy: getSignaturePolicy(), Registry: signatureRegistry}
+	result := verifier.Verify(ctx, repository, digest)
+	if result.Status == signatures.StatusError {
+		utils.LogWarning("Failed to check image signatures", map[string]interface{}{"repository": repository, "digest": digest, "error": result.Error})
+		return result
+	}
+	signatureResultsMu.Lock()
+	signatureResults[key] = result
+	signatureResultsMu.Unlock()
+	return result
+}
+
+// GetImageAttestations handles GET /api/v1/images/{digest}/attestations: the cosign
+// signatures and attestations of a scanned image in each repository it is pulled from,
+// with its findings. refresh=true checks the registries again.
+func (h *Handler) GetImageAttestations(w http.ResponseWriter, r *http.Request, digest string) {
+	cluster := clusterParam(r)
+	result := ImageAttestations{Digest: digest, Images: []string{}, Clusters: []string{}, Repositories: []signatures.Result{}}
+	images, clusters, repositories := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+	var latest *Report
+
+	for _, kind := range h.crdReg.GetAllReports() {
+		if !strings.HasSuffix(kind.Name, "vulnerabilityreports") {
+			continue
+		}
+		for _, report := range h.cache.GetReports(kind.Name, cluster, nil) {
+			artifact := reportSection(report, "artifact")
+			if d, _ := artifact["digest"].(string); d != digest {
+				continue
+			}
+			result.Workloads++
+			clusters[report.Cluster] = true
+			if ref := reportImageRef(report); ref != "" {
+				images[ref] = true
+			}
+			if _, repo := reportRepository(report); repo != "" {
+				repositories[repo] = true
+			}
+			if latest == nil || reportTime(report).After(reportTime(*latest)) {
+				latest = &report
+			}
+		}
+	}
+	if latest == nil {
+		writeError(w, http.StatusNotFound, "No scanned image with this digest")
+		return
+	}
+
+	c, hi, m, l := extractSummaryCounts(*latest)
+	result.Severity = SeverityTotals{Critical: c, High: hi, Medium: m, Low: l}
+	result.Images, result.Clusters = sortedKeys(images), sortedKeys(clusters)
+	refresh := r.URL.Query().Get("refresh") == "true"
+	result.Status = signatures.StatusUnsigned
+	for _, repo := range sortedKeys(repositories) {
+		verified := verifyImage(r.Context(), repo, digest, refresh)
+		result.Repositories = append(result.Repositories, verified)
+		if signatureStatusRank[verified.Status] > signatureStatusRank[result.Status] {
+			result.Status = verified.Status
+		}
+	}
+	sort.SliceStable(result.Repositories, func(i, j int) bool {
+		return signatureStatusRank[result.Repositories[i].Status] > signatureStatusRank[result.Repositories[j].Status]
+	})
+	result.UnsignedVulnerable = result.Status != signatures.StatusVerified && (c > 0 || hi > 0)
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    result,
+	})
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/signatures"
+)
+
+func TestGetImageAttestations(t *testing.T) {
+	var requests atomic.Int32
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer registry.Close()
+	host, _ := url.Parse(registry.URL)
+	setSignaturePolicy(nil)
+	t.Cleanup(func() { setSignaturePolicy(nil) })
+
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	report := hygieneReport("app", "c1", "v1", digest, "", 2)
+	report.Data.(map[string]interface{})["report"].(map[string]interface{})["registry"] = map[string]interface{}{"server": host.Host}
+	config.GetGlobalRegistry().Register(config.ReportKind{Name: "vulnerabilityreports", Kind: "VulnerabilityReport", Namespaced: true})
+	h := &Handler{cache: &stubCacheService{reports: map[string][]Report{"vulnerabilityreports": {report}}}, crdReg: config.GetGlobalRegistry()}
+
+	get := func(digest string) (int, ImageAttestations) {
+		rec := httptest.NewRecorder()
+		h.GetImageAttestations(rec, httptest.NewRequest(http.MethodGet, "/api/v1/images/"+digest+"/attestations", nil), digest)
+		var resp struct{ Data ImageAttestations }
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp.Data
+	}
+	code, result := get(digest)
+	if code != http.StatusOK || result.Status != signatures.StatusUnsigned || !result.UnsignedVulnerable || result.Severity.Critical != 2 {
+		t.Fatalf("got %d %+v", code, result)
+	}
+	if len(result.Repositories) != 1 || result.Repositories[0].Repository != host.Host+"/org/app" || result.Images[0] != host.Host+"/org/app:v1" {
+		t.Errorf("repositories: %+v images: %v", result.Repositories, result.Images)
+	}
+	// the result is reused until it expires
+	seen := requests.Load()
+	if get(digest); requests.Load() != seen {
+		t.Errorf("registry queried again: %d requests, want %d", requests.Load(), seen)
+	}
+	if code, _ := get("sha256:" + strings.Repeat("ff", 32)); code != http.StatusNotFound {
+		t.Errorf("unknown digest: got %d", code)
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"trivy-ui/agent"
+	"trivy-ui/config"
+	"trivy-ui/utils"
+)
+
+// isAdminPath reports whether a path belongs to the admin API, which is never public.
+func isAdminPath(path string) bool {
+	return strings.HasPrefix(path, "/api/v1/admin/") || strings.HasPrefix(path, "/api/v2/admin/")
+}
+
+// isReadRequest reports whether a request only reads data. Bulk detail lookups are
+// POSTs for body size reasons and Grafana posts its queries; neither mutates anything.
+func isReadRequest(r *http.Request) bool {
+	if isAdminPath(r.URL.Path) {
+		return false
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return strings.HasPrefix(r.URL.Path, "/api/v1/type/") && strings.HasSuffix(r.URL.Path, "/details") ||
+			strings.HasPrefix(r.URL.Path, "/api/grafana/")
+	}
+	return false
+}
+
+// AuthHandler enforces AUTH_MODE. In mixed mode reads stay public and every mutating
+// request (rescan, delete, triage, cluster registration, ...) needs a bearer token from
+// AUTH_TOKENS. The admin API needs one in every mode and is off without AUTH_TOKENS. The
+// agent push endpoint authenticates its own callers.
+func AuthHandler(next http.Handler, cfg *config.Config) http.Handler {
+	mixed := cfg.AuthMode == config.AuthModeMixed
+	if mixed && len(cfg.AuthTokens) == 0 {
+		utils.LogWarning("AUTH_MODE=mixed without AUTH_TOKENS, all mutating requests will be rejected", nil)
+	}
+	auth := tokenAuthenticator{tokens: cfg.AuthTokens}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin := isAdminPath(r.URL.Path)
+		if !admin && (!mixed || isReadRequest(r) || r.URL.Path == agent.PushPath) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if admin && len(cfg.AuthTokens) == 0 {
+			writeError(w, http.StatusNotFound, "The admin API needs AUTH_TOKENS")
+			return
+		}
+		user, ok := auth.Authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="trivy-ui"`)
+			writeError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+		utils.LogModuleDebug(utils.ModuleHandlers, "Authenticated request", map[string]interface{}{
+			"user":   user,
+			"method": r.Method,
+			"path":   r.URL.Path,
+		})
+		next.ServeHTTP(w, r)
+	})
+}
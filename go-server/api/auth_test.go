@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trivy-ui/agent"
+	"trivy-ui/config"
+)
+
+func TestAuthHandlerMixedMode(t *testing.T) {
+	cfg := &config.Config{AuthMode: config.AuthModeMixed, AuthTokens: map[string]string{"alice": "s3cret"}}
+	h := AuthHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), cfg)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"public read", http.MethodGet, "/api/v1/overview", "", http.StatusNoContent},
+		{"bulk details read", http.MethodPost, "/api/v1/type/vulnerabilityreports/details", "", http.StatusNoContent},
+		{"agent push has own auth", http.MethodPost, agent.PushPath, "", http.StatusNoContent},
+		{"write without token", http.MethodDelete, "/api/v1/clusters/prod", "", http.StatusUnauthorized},
+		{"write with wrong token", http.MethodPatch, "/api/v1/triage", "nope", http.StatusUnauthorized},
+		{"write with token", http.MethodPatch, "/api/v1/triage", "s3cret", http.StatusNoContent},
+		{"admin read without token", http.MethodGet, "/api/v1/admin/cache/stats", "", http.StatusUnauthorized},
+		{"v2 admin read without token", http.MethodGet, "/api/v2/admin/selftest", "", http.StatusUnauthorized},
+		{"admin read with token", http.MethodGet, "/api/v1/admin/cache/stats", "s3cret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("got status %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthHandlerNoneModeIsPassthrough(t *testing.T) {
+	h := AuthHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), &config.Config{AuthMode: config.AuthModeNone})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/clusters/prod", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
+
+func TestAuthHandlerNoneModeGatesAdmin(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	serve := func(cfg *config.Config, method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		AuthHandler(next, cfg).ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	open := &config.Config{AuthMode: config.AuthModeNone}
+	for _, path := range []string{"/api/v1/admin/cache/stats", "/api/v1/admin/cache/dump", "/api/v2/admin/selftest"} {
+		if code := serve(open, http.MethodGet, path, ""); code != http.StatusNotFound {
+			t.Errorf("%s without AUTH_TOKENS: got status %d, want 404", path, code)
+		}
+	}
+	if code := serve(open, http.MethodPost, "/api/v1/admin/reload", ""); code != http.StatusNotFound {
+		t.Errorf("reload without AUTH_TOKENS: got status %d, want 404", code)
+	}
+
+	tokens := &config.Config{AuthMode: config.AuthModeNone, AuthTokens: map[string]string{"alice": "s3cret"}}
+	if code := serve(tokens, http.MethodPut, "/api/v1/admin/logging", ""); code != http.StatusUnauthorized {
+		t.Errorf("admin without token: got status %d, want 401", code)
+	}
+	if code := serve(tokens, http.MethodPut, "/api/v1/admin/logging", "s3cret"); code != http.StatusNoContent {
+		t.Errorf("admin with token: got status %d, want 204", code)
+	}
+	if code := serve(tokens, http.MethodDelete, "/api/v1/clusters/prod", ""); code != http.StatusNoContent {
+		t.Errorf("non-admin write in none mode: got status %d, want 204", code)
+	}
+}
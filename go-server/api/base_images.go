@@ -0,0 +1,243 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// OS types images are classified into by their OS family.
+const (
+	OSTypeLinux   = "linux"
+	OSTypeWindows = "windows"
+)
+
+type BaseImageSummary struct {
+	// OSType is linux or windows, which are patched separately
+	OSType    string         `json:"osType"`
+	Family    string         `json:"family"`
+	Name      string         `json:"name"`
+	EOSL      bool           `json:"eosl"`
+	Workloads int            `json:"workloads"`
+	Images    int            `json:"images"`
+	Clusters  []string       `json:"clusters"`
+	Severity  SeverityTotals `json:"severity"`
+}
+
+// reportSection returns data.report.<key> (or data.<key> for flattened summaries) as a map.
+func reportSection(r Report, key string) map[string]interface{} {
+	data, ok := r.Data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if reportObj, ok := data["report"].(map[string]interface{}); ok {
+		if section, ok := reportObj[key].(map[string]interface{}); ok {
+			return section
+		}
+	}
+	if section, ok := data[key].(map[string]interface{}); ok {
+		return section
+	}
+	return nil
+}
+
+func reportImageRef(r Report) string {
+	_, repo := reportRepository(r)
+	if repo == "" {
+		return ""
+	}
+	artifact := reportSection(r, "artifact")
+	if tag, _ := artifact["tag"].(string); tag != "" {
+		return repo + ":" + tag
+	}
+	if digest, _ := artifact["digest"].(string); digest != "" {
+		return repo + "@" + digest
+	}
+	return repo
+}
+
+// reportOS classifies the scanned image by the OS Trivy detected. Trivy finds no OS
+// packages in most Windows images, so those are also recognised by their repository.
+func reportOS(r Report) (osType, family string) {
+	osInfo := reportSection(r, "os")
+	family, _ = osInfo["family"].(string)
+	if strings.EqualFold(family, OSTypeWindows) || isWindowsRepository(r) {
+		if family == "" {
+			family = OSTypeWindows
+		}
+		return OSTypeWindows, family
+	}
+	if family == "" {
+		return "", ""
+	}
+	return OSTypeLinux, family
+}
+
+// isWindowsRepository matches the Windows base images Microsoft publishes, e.g.
+// mcr.microsoft.com/windows/servercore.
+func isWindowsRepository(r Report) bool {
+	repo, _ := reportRepository(r)
+	repo = strings.ToLower(repo)
+	return strings.HasPrefix(repo, "windows/") || strings.Contains(repo, "/windows/") ||
+		strings.Contains(repo, "nanoserver") || strings.Contains(repo, "servercore")
+}
+
+// GetBaseImages aggregates vulnerability reports by the OS family/version of the scanned image.
+func (h *Handler) GetBaseImages(w http.ResponseWriter, r *http.Request) {
+	clusterFilter, namespaceFilters, _, _ := h.parseQueryParams(r)
+	familyFilter := strings.ToLower(r.URL.Query().Get("family"))
+	osTypeFilter := strings.ToLower(r.URL.Query().Get("osType"))
+
+	params := strings.Join([]string{clusterFilter, strings.Join(namespaceFilters, ","), familyFilter, osTypeFilter}, "|")
+	result := aggregates.getOrCompute("base-images", aggregateScope(clusterFilter), params, func() interface{} {
+		return h.computeBaseImages(clusterFilter, namespaceFilters, familyFilter, osTypeFilter)
+	})
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    result,
+	})
+}
+
+func (h *Handler) computeBaseImages(clusterFilter string, namespaceFilters []string, familyFilter, osTypeFilter string) []BaseImageSummary {
+	type aggregate struct {
+		summary  BaseImageSummary
+		images   map[string]bool
+		clusters map[string]bool
+	}
+	byDistro := make(map[string]*aggregate)
+
+	for _, kind := range h.crdReg.GetAllReports() {
+		for _, report := range h.cache.GetReports(kind.Name, clusterFilter, namespaceFilters) {
+			osType, family := reportOS(report)
+			if family == "" {
+				continue
+			}
+			if familyFilter != "" && strings.ToLower(family) != familyFilter {
+				continue
+			}
+			if osTypeFilter != "" && osType != osTypeFilter {
+				continue
+			}
+			osInfo := reportSection(report, "os")
+			name, _ := osInfo["name"].(string)
+
+			key := fmt.Sprintf("%s:%s", family, name)
+			agg, ok := byDistro[key]
+			if !ok {
+				agg = &aggregate{
+					summary:  BaseImageSummary{OSType: osType, Family: family, Name: name},
+					images:   make(map[string]bool),
+					clusters: make(map[string]bool),
+				}
+				byDistro[key] = agg
+			}
+			if eosl, ok := osInfo["eosl"].(bool); ok && eosl {
+				agg.summary.EOSL = true
+			}
+			agg.summary.Workloads++
+			if ref := reportImageRef(report); ref != "" {
+				agg.images[ref] = true
+			}
+			agg.clusters[report.Cluster] = true
+
+			c, hi, m, l := extractSummaryCounts(report)
+			agg.summary.Severity.Critical += c
+			agg.summary.Severity.High += hi
+			agg.summary.Severity.Medium += m
+			agg.summary.Severity.Low += l
+		}
+	}
+
+	result := make([]BaseImageSummary, 0, len(byDistro))
+	for _, agg := range byDistro {
+		agg.summary.Images = len(agg.images)
+		for c := range agg.clusters {
+			agg.summary.Clusters = append(agg.summary.Clusters, c)
+		}
+		sort.Strings(agg.summary.Clusters)
+		result = append(result, agg.summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Workloads != result[j].Workloads {
+			return result[i].Workloads > result[j].Workloads
+		}
+		if result[i].Family != result[j].Family {
+			return result[i].Family < result[j].Family
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// OSTypeSummary rolls the base images of one OS type up, for fleets mixing Windows and
+// Linux nodes whose images are patched in separate workflows.
+type OSTypeSummary struct {
+	OSType    string         `json:"osType"`
+	Families  []string       `json:"families"`
+	Workloads int            `json:"workloads"`
+	Images    int            `json:"images"`
+	EOSL      int            `json:"eosl"`
+	Clusters  []string       `json:"clusters"`
+	Severity  SeverityTotals `json:"severity"`
+}
+
+func rollupOSTypes(baseImages []BaseImageSummary) []OSTypeSummary {
+	byType := make(map[string]*OSTypeSummary)
+	families := make(map[string]map[string]bool)
+	clusters := make(map[string]map[string]bool)
+	for _, b := range baseImages {
+		sum, ok := byType[b.OSType]
+		if !ok {
+			sum = &OSTypeSummary{OSType: b.OSType}
+			byType[b.OSType] = sum
+			families[b.OSType] = make(map[string]bool)
+			clusters[b.OSType] = make(map[string]bool)
+		}
+		families[b.OSType][b.Family] = true
+		for _, c := range b.Clusters {
+			clusters[b.OSType][c] = true
+		}
+		sum.Workloads += b.Workloads
+		sum.Images += b.Images
+		if b.EOSL {
+			sum.EOSL += b.Workloads
+		}
+		sum.Severity.Critical += b.Severity.Critical
+		sum.Severity.High += b.Severity.High
+		sum.Severity.Medium += b.Severity.Medium
+		sum.Severity.Low += b.Severity.Low
+	}
+
+	result := make([]OSTypeSummary, 0, len(byType))
+	for osType, sum := range byType {
+		for f := range families[osType] {
+			sum.Families = append(sum.Families, f)
+		}
+		for c := range clusters[osType] {
+			sum.Clusters = append(sum.Clusters, c)
+		}
+		sort.Strings(sum.Families)
+		sort.Strings(sum.Clusters)
+		result = append(result, *sum)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].OSType < result[j].OSType })
+	return result
+}
+
+// GetOSTypes reports workloads and vulnerability totals per OS type (linux, windows).
+func (h *Handler) GetOSTypes(w http.ResponseWriter, r *http.Request) {
+	clusterFilter, namespaceFilters, _, _ := h.parseQueryParams(r)
+	params := strings.Join([]string{clusterFilter, strings.Join(namespaceFilters, ",")}, "|")
+	result := aggregates.getOrCompute("os-types", aggregateScope(clusterFilter), params, func() interface{} {
+		return rollupOSTypes(h.computeBaseImages(clusterFilter, namespaceFilters, "", ""))
+	})
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    result,
+	})
+}
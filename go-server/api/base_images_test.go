@@ -0,0 +1,73 @@
+package api
+
+import "testing"
+
+func TestReportSection_NestedAndFlat(t *testing.T) {
+	nested := Report{Data: map[string]interface{}{
+		"report": map[string]interface{}{"os": map[string]interface{}{"family": "debian", "name": "10.13"}},
+	}}
+	if osInfo := reportSection(nested, "os"); osInfo == nil || osInfo["family"] != "debian" {
+		t.Fatalf("nested os not found: %v", osInfo)
+	}
+	flat := Report{Data: map[string]interface{}{"os": map[string]interface{}{"family": "alpine"}}}
+	if osInfo := reportSection(flat, "os"); osInfo == nil || osInfo["family"] != "alpine" {
+		t.Fatalf("flat os not found: %v", osInfo)
+	}
+	if reportSection(Report{}, "os") != nil {
+		t.Fatal("expected nil for empty report")
+	}
+}
+
+func TestReportImageRef(t *testing.T) {
+	r := Report{Data: map[string]interface{}{
+		"report": map[string]interface{}{
+			"registry": map[string]interface{}{"server": "ghcr.io"},
+			"artifact": map[string]interface{}{"repository": "org/app", "tag": "v1.2.0"},
+		},
+	}}
+	if got := reportImageRef(r); got != "ghcr.io/org/app:v1.2.0" {
+		t.Fatalf("unexpected ref %q", got)
+	}
+}
+
+func TestReportOS(t *testing.T) {
+	image := func(repo, family string) Report {
+		report := map[string]interface{}{"artifact": map[string]interface{}{"repository": repo}}
+		if family != "" {
+			report["os"] = map[string]interface{}{"family": family}
+		}
+		return Report{Data: map[string]interface{}{"report": report}}
+	}
+	for _, tc := range []struct {
+		report         Report
+		osType, family string
+	}{
+		{image("library/nginx", "debian"), OSTypeLinux, "debian"},
+		{image("windows/servercore", ""), OSTypeWindows, "windows"},
+		{image("dotnet/framework/aspnet", "windows"), OSTypeWindows, "windows"},
+		{image("org/app", ""), "", ""},
+	} {
+		osType, family := reportOS(tc.report)
+		if osType != tc.osType || family != tc.family {
+			t.Errorf("reportOS(%v) = %q, %q", tc.report.Data, osType, family)
+		}
+	}
+}
+
+func TestRollupOSTypes(t *testing.T) {
+	rollup := rollupOSTypes([]BaseImageSummary{
+		{OSType: OSTypeLinux, Family: "alpine", Workloads: 3, Images: 2, Clusters: []string{"a"}, Severity: SeverityTotals{Critical: 1}},
+		{OSType: OSTypeLinux, Family: "debian", EOSL: true, Workloads: 2, Images: 1, Clusters: []string{"a", "b"}},
+		{OSType: OSTypeWindows, Family: "windows", Workloads: 1, Images: 1, Clusters: []string{"b"}, Severity: SeverityTotals{High: 4}},
+	})
+	if len(rollup) != 2 {
+		t.Fatalf("rollup = %+v", rollup)
+	}
+	linux, windows := rollup[0], rollup[1]
+	if linux.Workloads != 5 || linux.Images != 3 || linux.EOSL != 2 || len(linux.Families) != 2 || len(linux.Clusters) != 2 || linux.Severity.Critical != 1 {
+		t.Errorf("linux = %+v", linux)
+	}
+	if windows.OSType != OSTypeWindows || windows.Severity.High != 4 {
+		t.Errorf("windows = %+v", windows)
+	}
+}
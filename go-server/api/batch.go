@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// maxBatchItems bounds the items of one batch request.
+const maxBatchItems = 100
+
+// Batch item statuses.
+const (
+	BatchStatusOK    = "ok"
+	BatchStatusError = "error"
+)
+
+// BatchItemStatus is embedded in the per-item results of batch endpoints. A failing item
+// never fails the whole batch; clients read status, code and error of every item.
+type BatchItemStatus struct {
+	Status string `json:"status"`
+	// Code is the HTTP status the item would have had as a single request
+	Code  int    `json:"code"`
+	Error string `json:"error,omitempty"`
+}
+
+func batchOK() BatchItemStatus {
+	return BatchItemStatus{Status: BatchStatusOK, Code: http.StatusOK}
+}
+
+func batchError(code int, err string) BatchItemStatus {
+	return BatchItemStatus{Status: BatchStatusError, Code: code, Error: err}
+}
+
+// writeBatch writes the per-item results of a batch: 200 when every item succeeded and
+// 207 Multi-Status when any failed, with the failure count in the message.
+func writeBatch(w http.ResponseWriter, results interface{}, failed, total int) {
+	if failed == 0 {
+		writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: results})
+		return
+	}
+	writeJSON(w, http.StatusMultiStatus, Response{
+		Code:    CodeSuccess,
+		Message: fmt.Sprintf("%d of %d items failed", failed, total),
+		Data:    results,
+	})
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteBatch(t *testing.T) {
+	results := []BulkDetailResult{
+		{ReportRef: ReportRef{Cluster: "c1", Name: "a"}, BatchItemStatus: batchOK(), Report: &Report{Name: "a"}},
+		{ReportRef: ReportRef{Cluster: "c1", Name: "b"}, BatchItemStatus: batchError(http.StatusNotFound, "not found")},
+	}
+
+	rec := httptest.NewRecorder()
+	writeBatch(rec, results, 1, 2)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("expected 207 for partial failure, got %d", rec.Code)
+	}
+	var resp struct {
+		Message string                   `json:"message"`
+		Data    []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Message != "1 of 2 items failed" {
+		t.Fatalf("unexpected message %q", resp.Message)
+	}
+	if resp.Data[0]["status"] != BatchStatusOK || resp.Data[1]["status"] != BatchStatusError ||
+		resp.Data[1]["code"] != float64(http.StatusNotFound) || resp.Data[1]["error"] != "not found" {
+		t.Fatalf("per-item status must be flattened into each result: %+v", resp.Data)
+	}
+
+	rec = httptest.NewRecorder()
+	writeBatch(rec, results[:1], 0, 1)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 when every item succeeded, got %d", rec.Code)
+	}
+}
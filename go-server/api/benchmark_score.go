@@ -0,0 +1,121 @@
+package api
+
+import (
+	"math"
+	"strings"
+)
+
+// Weights of the two parts of the benchmark score.
+const (
+	benchmarkComplianceWeight  = 0.6
+	benchmarkConfigAuditWeight = 0.4
+)
+
+// BenchmarkScore condenses a cluster's CIS compliance and config audits into one number
+// from 0 to 100:
+//
+//	compliance  = 100 * passed / (passed + failed) controls of the CIS compliance reports
+//	configAudit = 100 * config audit reports without failed critical or high checks / config audit reports
+//	score       = 0.6 * compliance + 0.4 * configAudit
+//
+// A cluster without a CIS compliance report, e.g. one whose operator runs no compliance
+// scans, is scored on its config audits alone and the other way round. Clusters with
+// neither have no score.
+type BenchmarkScore struct {
+	Score float64 `json:"score"`
+	// Compliance and ConfigAudit are the parts the score was computed from
+	Compliance         *float64 `json:"compliance,omitempty"`
+	ConfigAudit        *float64 `json:"configAudit,omitempty"`
+	ControlsPassed     int      `json:"controlsPassed"`
+	ControlsFailed     int      `json:"controlsFailed"`
+	AuditedResources   int      `json:"auditedResources"`
+	CompliantResources int      `json:"compliantResources"`
+}
+
+// benchmarkTotals are the inputs of a benchmark score, kept per cluster by the fleet
+// aggregator.
+type benchmarkTotals struct {
+	passed, failed   int
+	audited, cleaned int
+}
+
+func (b *benchmarkTotals) add(o benchmarkTotals, sign int) {
+	b.passed += sign * o.passed
+	b.failed += sign * o.failed
+	b.audited += sign * o.audited
+	b.cleaned += sign * o.cleaned
+}
+
+// benchmarkContribution reads what a report adds to its cluster's benchmark score: the
+// control counts of a CIS compliance report, or one audited resource for a config audit.
+func benchmarkContribution(report Report, reportType string) benchmarkTotals {
+	switch reportType {
+	case "clustercompliancereports":
+		if !strings.Contains(strings.ToLower(report.Name), "cis") {
+			return benchmarkTotals{}
+		}
+		summary := reportSummaryMap(report)
+		return benchmarkTotals{passed: summaryInt(summary, "passCount"), failed: summaryInt(summary, "failCount")}
+	case "configauditreports", "clusterconfigauditreports":
+		c, h, _, _ := extractSummaryCounts(report)
+		b := benchmarkTotals{audited: 1}
+		if c+h == 0 {
+			b.cleaned = 1
+		}
+		return b
+	}
+	return benchmarkTotals{}
+}
+
+// score computes the benchmark score, or nil when there is nothing to score.
+func (b benchmarkTotals) score() *BenchmarkScore {
+	s := &BenchmarkScore{
+		ControlsPassed:     b.passed,
+		ControlsFailed:     b.failed,
+		AuditedResources:   b.audited,
+		CompliantResources: b.cleaned,
+	}
+	var total, weights float64
+	if controls := b.passed + b.failed; controls > 0 {
+		compliance := roundScore(100 * float64(b.passed) / float64(controls))
+		s.Compliance = &compliance
+		total += benchmarkComplianceWeight * compliance
+		weights += benchmarkComplianceWeight
+	}
+	if b.audited > 0 {
+		audit := roundScore(100 * float64(b.cleaned) / float64(b.audited))
+		s.ConfigAudit = &audit
+		total += benchmarkConfigAuditWeight * audit
+		weights += benchmarkConfigAuditWeight
+	}
+	if weights == 0 {
+		return nil
+	}
+	s.Score = roundScore(total / weights)
+	return s
+}
+
+// roundScore keeps one decimal, enough to track a score over time.
+func roundScore(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// reportSummaryMap returns report.summary of a cached report.
+func reportSummaryMap(report Report) map[string]interface{} {
+	data, _ := report.Data.(map[string]interface{})
+	reportObj, _ := data["report"].(map[string]interface{})
+	summary, _ := reportObj["summary"].(map[string]interface{})
+	return summary
+}
+
+func summaryInt(summary map[string]interface{}, key string) int {
+	switch n := summary[key].(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case int64:
+		return int(n)
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/loadgen"
+)
+
+// benchmarkFleets are the sizes the list and summary benchmarks run against; compare runs
+// with benchstat before and after cache or index changes.
+var benchmarkFleets = []loadgen.Options{
+	{Clusters: 5, ReportsPerCluster: 200, Seed: 1},
+	{Clusters: 20, ReportsPerCluster: 1000, Seed: 1},
+}
+
+// newSyntheticRouter fills a private cache with a generated fleet, applied through the
+// agent event path like pushed clusters, and routes requests to it.
+func newSyntheticRouter(b *testing.B, opts loadgen.Options) *Router {
+	b.Helper()
+	c := useTestCache(b)
+	prevFleet := fleet
+	fleet = newFleetAggregator()
+	b.Cleanup(func() {
+		fleet = prevFleet
+		queryResultCache.Clear()
+		aggregates.invalidateAll()
+	})
+
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	crdReg := config.GetGlobalRegistry()
+	crdReg.Register(loadgen.Kinds()...)
+	updater := NewCacheUpdater(reg)
+	for i := 0; i < opts.Clusters; i++ {
+		name := loadgen.ClusterName(i)
+		reg.RegisterPushed(name, "v1.30.2", loadgen.Namespaces(opts))
+		applyAgentEvents(updater, name, loadgen.Events(loadgen.Reports(opts, i)))
+	}
+	c.cache.Wait()
+	queryResultCache.Clear()
+	aggregates.invalidateAll()
+	return NewRouter(nil, b.TempDir(), svc, reg, crdReg)
+}
+
+func fleetName(opts loadgen.Options) string {
+	return fmt.Sprintf("clusters=%d/reports=%d", opts.Clusters, opts.ReportsPerCluster)
+}
+
+// benchmarkGet requests path b.N times; reset runs before every request to measure the
+// uncached path.
+func benchmarkGet(b *testing.B, path string, reset func()) {
+	for _, opts := range benchmarkFleets {
+		b.Run(fleetName(opts), func(b *testing.B) {
+			router := newSyntheticRouter(b, opts)
+			b.ReportAllocs()
+			for b.Loop() {
+				if reset != nil {
+					reset()
+				}
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				if rec.Code != http.StatusOK {
+					b.Fatalf("GET %s: status %d: %s", path, rec.Code, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+const benchmarkListPath = "/api/v1/reports?type=" + loadgen.ReportType + "&page=1&pageSize=50"
+
+func BenchmarkListReports(b *testing.B) {
+	benchmarkGet(b, benchmarkListPath, queryResultCache.Clear)
+}
+
+func BenchmarkListReportsCached(b *testing.B) {
+	benchmarkGet(b, benchmarkListPath, nil)
+}
+
+func BenchmarkListReportsSorted(b *testing.B) {
+	benchmarkGet(b, benchmarkListPath+"&onlyVulnerable=true&sort=-effectiveSeverity", queryResultCache.Clear)
+}
+
+func BenchmarkListReportsSearch(b *testing.B) {
+	benchmarkGet(b, benchmarkListPath+"&search=app-0001", queryResultCache.Clear)
+}
+
+func BenchmarkListReportsFilter(b *testing.B) {
+	benchmarkGet(b, benchmarkListPath+"&filter=critical%3E0", queryResultCache.Clear)
+}
+
+func BenchmarkOverview(b *testing.B) {
+	benchmarkGet(b, "/api/v1/overview", aggregates.invalidateAll)
+}
+
+func BenchmarkOverviewCluster(b *testing.B) {
+	benchmarkGet(b, "/api/v1/overview?cluster="+loadgen.ClusterName(0), aggregates.invalidateAll)
+}
+
+func BenchmarkFleetSummary(b *testing.B) {
+	benchmarkGet(b, "/api/v1/fleet/summary", nil)
+}
+
+func BenchmarkClusters(b *testing.B) {
+	benchmarkGet(b, "/api/clusters", nil)
+}
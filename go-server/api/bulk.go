@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const bulkDetailWorkers = 8
+
+type ReportRef struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type BulkDetailResult struct {
+	ReportRef
+	BatchItemStatus
+	Report *Report `json:"report,omitempty"`
+}
+
+// GetReportDetailsBulk returns full details for many reports of one type in a single
+// response. Cache hits are served directly; misses fan out to Kubernetes with bounded
+// concurrency. Results keep the request order and failures are reported per report.
+func (h *Handler) GetReportDetailsBulk(w http.ResponseWriter, r *http.Request, typeName string) {
+	reportKind := h.crdReg.ResolveReport(typeName)
+	if reportKind == nil {
+		writeError(w, http.StatusBadRequest, "Invalid report type")
+		return
+	}
+
+	var refs []ReportRef
+	if err := json.NewDecoder(r.Body).Decode(&refs); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(refs) == 0 {
+		writeError(w, http.StatusBadRequest, "No reports requested")
+		return
+	}
+	if len(refs) > maxBatchItems {
+		writeError(w, http.StatusBadRequest, "Too many reports requested")
+		return
+	}
+
+	dedupe := wantsDedupe(r)
+	ignore := ignoreUnfixable(r)
+	results := make([]BulkDetailResult, len(refs))
+	var g errgroup.Group
+	g.SetLimit(bulkDetailWorkers)
+
+	for i, ref := range refs {
+		results[i].ReportRef = ref
+		if ref.Cluster == "" || ref.Name == "" {
+			results[i].BatchItemStatus = batchError(http.StatusBadRequest, "cluster and name are required")
+			continue
+		}
+
+		g.Go(func() error {
+			if err := r.Context().Err(); err != nil {
+				results[i].BatchItemStatus = batchError(http.StatusServiceUnavailable, err.Error())
+				return nil
+			}
+
+			report, err := h.loadReportDetail(r.Context(), *reportKind, ref.Cluster, ref.Namespace, ref.Name)
+			if err != nil {
+				results[i].BatchItemStatus = batchError(detailErrorCode(err), err.Error())
+				return nil
+			}
+			if dedupe {
+				report = dedupeReportDetail(report)
+			}
+			if ignore {
+				report = withoutUnfixable(report)
+			}
+			report.Provenance = reportProvenance(report)
+			report = withFindingLinks(report)
+			report = withReportAge(report, time.Now())
+			results[i].BatchItemStatus = batchOK()
+			results[i].Report = &report
+			return nil
+		})
+	}
+	g.Wait()
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	failed := 0
+	for _, res := range results {
+		if res.Status != BatchStatusOK {
+			failed++
+		}
+	}
+	writeBatch(w, results, failed, len(results))
+}
+
+// detailErrorCode maps a loadReportDetail error to the status of a single detail request.
+func detailErrorCode(err error) int {
+	if errors.Is(err, errClusterClientNotFound) || apierrors.IsNotFound(err) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
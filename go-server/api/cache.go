@@ -10,13 +10,17 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"trivy-ui/config"
 	"trivy-ui/kubernetes"
+	"trivy-ui/store"
 	"trivy-ui/utils"
 
 	"github.com/dgraph-io/ristretto"
+	"github.com/dgraph-io/ristretto/z"
+	"golang.org/x/sync/errgroup"
 )
 
 var globalCache *Cache
@@ -56,25 +60,11 @@ func getTypeVersion(reportType string) uint64 {
 	return 0
 }
 
-// reportCounts stores atomic counters for report totals and vulnerability counts
-// Key format: "count:<cluster>:<type>" or "count:<cluster>:<ns>:<type>"
-type reportCounters struct {
-	mu     sync.RWMutex
-	counts map[string]*counterPair
-}
-
-type counterPair struct {
-	total    int
-	withVuln int
-}
-
-var counters = &reportCounters{
-	counts: make(map[string]*counterPair),
-}
-
 type CacheItem struct {
 	Value      interface{} `json:"value"`
 	Expiration int64       `json:"expiration"`
+	// cost is the estimated size of key and value, not persisted
+	cost int64
 }
 
 type Cache struct {
@@ -83,10 +73,39 @@ type Cache struct {
 	cacheFile  string
 	items      map[string]CacheItem
 	reportKeys map[string]bool
-	keyMap     map[uint64]string
+	// keyMap resolves the hashed keys ristretto passes to OnEvict
+	keyMap     map[cacheKeyHash]string
 	typeIndex  map[string]map[string]bool
+	// nameIndex maps type and report name to the keys of reports with that name, so a
+	// report can be found without knowing its cluster and namespace
+	nameIndex map[string]map[string]map[string]bool
+	// bytes sums the estimated cost of items
+	bytes int64
+	// rollups count the reports per type, cluster and namespace
+	rollups *reportRollups
+	// changes counts writes to items and savedChanges its value at the last save, so
+	// periodic saves skip an unchanged cache
+	changes      uint64
+	savedChanges atomic.Uint64
+	// saveMu keeps a shutdown save from racing a periodic one on the cache file
+	saveMu sync.Mutex
+
+	hits        atomic.Int64
+	misses      atomic.Int64
+	evictions   atomic.Int64
+	collisions  atomic.Int64
+	lastPersist atomic.Int64
 }
 
+// cacheKeyHash is ristretto's 128-bit identity of a key: its hash and conflict hash.
+type cacheKeyHash struct {
+	key      uint64
+	conflict uint64
+}
+
+// cacheMaxCost bounds the estimated size of values held by ristretto.
+const cacheMaxCost = 1 << 30
+
 func InitCache() error {
 	cfg := config.Get()
 	cacheFilePath := "cache.json"
@@ -94,52 +113,69 @@ func InitCache() error {
 		cacheFilePath = filepath.Join(cfg.DataPath, "cache.json")
 	}
 	
-	globalCache = &Cache{
-		cacheFile:  cacheFilePath,
+	c, err := newCache(cacheFilePath, cacheMaxCost)
+	if err != nil {
+		return err
+	}
+	globalCache = c
+
+	if err := globalCache.LoadFromFile(); err != nil {
+		utils.LogWarning("Failed to load cache from file", map[string]interface{}{"error": err.Error()})
+	}
+	if cfg.SnapshotPeer != "" && !globalCache.HasCacheData() {
+		bootstrapCache(globalCache, cfg.SnapshotPeer)
+	}
+
+	go globalCache.periodicSave()
+	go globalCache.periodicTrendRecord()
+
+	return nil
+}
+
+func newCache(cacheFile string, maxCost int64) (*Cache, error) {
+	c := &Cache{
+		cacheFile:  cacheFile,
 		items:      make(map[string]CacheItem),
 		reportKeys: make(map[string]bool),
-		keyMap:     make(map[uint64]string),
+		keyMap:     make(map[cacheKeyHash]string),
 		typeIndex:  make(map[string]map[string]bool),
+		nameIndex:  make(map[string]map[string]map[string]bool),
+		rollups:    newReportRollups(),
 	}
 
 	config := &ristretto.Config{
 		NumCounters: 1e7,
-		MaxCost:     1 << 30,
+		MaxCost:     maxCost,
 		BufferItems: 64,
-		OnEvict: func(item *ristretto.Item) {
-			if globalCache != nil {
-				globalCache.mu.Lock()
-				if keyStr, ok := globalCache.keyMap[item.Key]; ok {
-					delete(globalCache.items, keyStr)
-					if strings.HasPrefix(keyStr, "report:") {
-						delete(globalCache.reportKeys, keyStr)
-						if typ := reportTypeFromKey(keyStr); typ != "" {
-							if idx, ok := globalCache.typeIndex[typ]; ok {
-								delete(idx, keyStr)
-							}
-						}
-					}
-					delete(globalCache.keyMap, item.Key)
-				}
-				globalCache.mu.Unlock()
-			}
-		},
+		OnEvict:     c.onEvict,
 	}
 	ristrettoCache, err := ristretto.NewCache(config)
 	if err != nil {
-		return fmt.Errorf("failed to create cache: %w", err)
+		return nil, fmt.Errorf("failed to create cache: %w", err)
 	}
+	c.cache = ristrettoCache
+	return c, nil
+}
 
-	globalCache.cache = ristrettoCache
-
-	if err := globalCache.LoadFromFile(); err != nil {
-		utils.LogWarning("Failed to load cache from file", map[string]interface{}{"error": err.Error()})
+// onEvict forgets an entry ristretto evicted or expired. Reports stay in the items map,
+// which Get falls back to, so only ristretto's hot copy is lost; other entries go.
+func (c *Cache) onEvict(item *ristretto.Item) {
+	h := cacheKeyHash{key: item.Key, conflict: item.Conflict}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keyStr, ok := c.keyMap[h]
+	if !ok {
+		return
+	}
+	c.evictions.Add(1)
+	if _, stillCached := c.cache.Get(keyStr); stillCached {
+		// set again while the eviction was in flight
+		return
+	}
+	delete(c.keyMap, h)
+	if !strings.HasPrefix(keyStr, "report:") {
+		c.dropItem(keyStr)
 	}
-
-	go globalCache.periodicSave()
-	go globalCache.periodicTrendRecord()
-
-	return nil
 }
 
 func GetCache() *Cache {
@@ -155,6 +191,7 @@ func GetCache() *Cache {
 func (c *Cache) Get(key string) (interface{}, bool) {
 	// First try ristretto cache
 	if value, found := c.cache.Get(key); found {
+		c.hits.Add(1)
 		return value, true
 	}
 	// Fallback to items map
@@ -163,9 +200,11 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 	if item, found := c.items[key]; found {
 		now := time.Now().Unix()
 		if strings.HasPrefix(key, "report:") || item.Expiration > now {
+			c.hits.Add(1)
 			return item.Value, true
 		}
 	}
+	c.misses.Add(1)
 	return nil, false
 }
 
@@ -182,38 +221,93 @@ func (c *Cache) Set(key string, value interface{}, expiration time.Duration) {
 	keyHash := c.hashKey(key)
 	c.cache.SetWithTTL(key, value, cost, expiration)
 	c.mu.Lock()
+	if prev, ok := c.keyMap[keyHash]; ok && prev != key {
+		c.collisions.Add(1)
+		utils.LogWarning("Cache key hash collision", map[string]interface{}{"key": key, "previous": prev})
+	}
 	c.keyMap[keyHash] = key
-	c.items[key] = CacheItem{
+	c.putItem(key, CacheItem{
 		Value:      value,
 		Expiration: time.Now().Add(expiration).Unix(),
-	}
+		cost:       cost,
+	})
 	if isReport {
 		c.reportKeys[key] = true
-		if typ := reportTypeFromKey(key); typ != "" {
-			if c.typeIndex[typ] == nil {
-				c.typeIndex[typ] = make(map[string]bool)
-			}
-			c.typeIndex[typ][key] = true
+		if typ := c.indexReportKey(key); typ != "" {
 			incrementTypeVersion(typ)
 		}
+		// under c.mu like in Delete, so a concurrent Delete cannot leave the report in
+		// the aggregates after it left the items
+		fleet.set(key, value)
+		c.rollups.set(key, value)
+		suggestions.set(key, value)
+		aggregates.invalidate(clusterFromReportKey(key))
 	}
 	c.mu.Unlock()
 }
 
+type cacheEntry struct {
+	key   string
+	value interface{}
+}
+
+// SetBatch sets several entries with the same expiration, taking the cache lock once.
+func (c *Cache) SetBatch(entries []cacheEntry, expiration time.Duration) {
+	if len(entries) == 0 {
+		return
+	}
+	costs := make([]int64, len(entries))
+	for i, e := range entries {
+		costs[i] = int64(len(e.key)) + estimateSize(e.value)
+		c.cache.SetWithTTL(e.key, e.value, costs[i], expiration)
+	}
+	expiresAt := time.Now().Add(expiration).Unix()
+	types := make(map[string]bool)
+	clusters := make(map[string]bool)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, e := range entries {
+		keyHash := c.hashKey(e.key)
+		if prev, ok := c.keyMap[keyHash]; ok && prev != e.key {
+			c.collisions.Add(1)
+			utils.LogWarning("Cache key hash collision", map[string]interface{}{"key": e.key, "previous": prev})
+		}
+		c.keyMap[keyHash] = e.key
+		c.putItem(e.key, CacheItem{Value: e.value, Expiration: expiresAt, cost: costs[i]})
+		if strings.HasPrefix(e.key, "report:") {
+			c.reportKeys[e.key] = true
+			if typ := c.indexReportKey(e.key); typ != "" {
+				types[typ] = true
+			}
+			fleet.set(e.key, e.value)
+			c.rollups.set(e.key, e.value)
+			suggestions.set(e.key, e.value)
+			clusters[clusterFromReportKey(e.key)] = true
+		}
+	}
+	for typ := range types {
+		incrementTypeVersion(typ)
+	}
+	for cluster := range clusters {
+		aggregates.invalidate(cluster)
+	}
+}
+
 func (c *Cache) Delete(key string) {
 	keyHash := c.hashKey(key)
 	c.cache.Del(key)
 	c.mu.Lock()
-	delete(c.items, key)
+	c.dropItem(key)
 	delete(c.keyMap, keyHash)
 	if strings.HasPrefix(key, "report:") {
 		delete(c.reportKeys, key)
-		if typ := reportTypeFromKey(key); typ != "" {
-			if idx, ok := c.typeIndex[typ]; ok {
-				delete(idx, key)
-			}
+		if typ := c.unindexReportKey(key); typ != "" {
 			incrementTypeVersion(typ)
 		}
+		fleet.remove(key)
+		c.rollups.remove(key)
+		suggestions.remove(key)
+		aggregates.invalidate(clusterFromReportKey(key))
 	}
 	c.mu.Unlock()
 }
@@ -229,25 +323,23 @@ func (c *Cache) deleteReportEntryByKey(key string) {
 		return
 	}
 
-	value, found := c.Get(key)
-	if !found {
-		c.Delete(key)
-		c.Delete(reportDetailKey(cluster, namespace, reportType, name))
-		return
-	}
-
-	hasVuln := false
-	switch typed := value.(type) {
-	case Report:
-		hasVuln = hasVulnerabilitiesInReport(typed)
-	case map[string]interface{}:
-		report := Report{Data: typed["data"]}
-		hasVuln = hasVulnerabilitiesInReport(report)
-	}
-
 	c.Delete(key)
 	c.Delete(reportDetailKey(cluster, namespace, reportType, name))
-	DecrementReportCount(cluster, namespace, reportType, hasVuln)
+}
+
+// NamespaceReportKeys returns the keys of the cached reports of one namespace.
+func (c *Cache) NamespaceReportKeys(cluster, namespace string) []string {
+	prefix := "report:" + cluster + ":" + namespace + ":"
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []string
+	for key := range c.reportKeys {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func (c *Cache) Items() map[string]interface{} {
@@ -321,6 +413,35 @@ func (c *Cache) ItemsByType(typeName string) map[string]interface{} {
 	return result
 }
 
+// FindReportKeys returns the keys of cached reports of a type with the given name,
+// narrowed to a cluster and namespace when they are not empty. It only visits reports
+// sharing the name, not every report of the type.
+func (c *Cache) FindReportKeys(typeName, cluster, namespace, name string) []string {
+	if cluster != "" && namespace != "" {
+		key := reportKey(cluster, namespace, typeName, name)
+		c.mu.RLock()
+		_, ok := c.typeIndex[typeName][key]
+		c.mu.RUnlock()
+		if ok {
+			return []string{key}
+		}
+		return nil
+	}
+
+	c.mu.RLock()
+	keys := make([]string, 0, len(c.nameIndex[typeName][name]))
+	for k := range c.nameIndex[typeName][name] {
+		kc, kns, _, _, ok := parseReportCacheKey(k)
+		if !ok || (cluster != "" && kc != cluster) || (namespace != "" && kns != namespace) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	c.mu.RUnlock()
+	sort.Strings(keys)
+	return keys
+}
+
 func (c *Cache) GetReports(typeName, clusterFilter string, namespaceFilters []string) []Report {
 	version := getTypeVersion(typeName)
 	namespacesStr := strings.Join(namespaceFilters, ",")
@@ -339,6 +460,7 @@ func (c *Cache) GetReports(typeName, clusterFilter string, namespaceFilters []st
 		return nil
 	}
 
+	clusters := clusterSet(clusterFilter)
 	var reports []Report
 	for k := range idx {
 		parts := strings.SplitN(k, ":", 5)
@@ -348,7 +470,7 @@ func (c *Cache) GetReports(typeName, clusterFilter string, namespaceFilters []st
 		cluster := parts[1]
 		namespace := parts[2]
 
-		if clusterFilter != "" && cluster != clusterFilter {
+		if clusters != nil && !clusters[cluster] {
 			continue
 		}
 
@@ -401,52 +523,24 @@ func (c *Cache) GetReports(typeName, clusterFilter string, namespaceFilters []st
 }
 
 func (c *Cache) GetReportCount(reportType, cluster string) (total int, withVulnerabilities int) {
-	items := c.Items()
-	
-	for k, v := range items {
-		if !strings.HasPrefix(k, "report:") {
-			continue
-		}
-		parts := strings.SplitN(strings.TrimPrefix(k, "report:"), ":", 4)
-		if len(parts) < 4 {
-			continue
-		}
-		itemCluster := parts[0]
-		itemType := parts[2]
-
-		if reportType != "" && itemType != reportType {
-			continue
-		}
-		if cluster != "" && itemCluster != cluster {
-			continue
-		}
-
-		total++
+	rollup := c.rollups.sum(reportType, cluster, nil)
+	return rollup.Reports, rollup.WithVulnerabilities
+}
 
-		var report Report
-		switch val := v.(type) {
-		case Report:
-			report = val
-		case map[string]interface{}:
-			b, err := json.Marshal(val)
-			if err != nil {
-				continue
-			}
-			if err := json.Unmarshal(b, &report); err != nil {
-				continue
-			}
-		default:
-			continue
-		}
+// GetReportRollup returns the report counts of a type as GetReports would select its
+// reports, without reading them.
+func (c *Cache) GetReportRollup(typeName, clusterFilter string, namespaceFilters []string) ReportRollup {
+	return c.rollups.sum(typeName, clusterFilter, namespaceFilters)
+}
 
-		if hasVulnerabilitiesInReport(report) {
-			withVulnerabilities++
-		}
-	}
-	return
+// GetReportRollups returns the report counts per type and namespace of the clusters in a
+// comma-separated filter, of all clusters when it is empty.
+func (c *Cache) GetReportRollups(clusterFilter string) []NamespaceRollup {
+	return c.rollups.list(clusterFilter)
 }
 
-// hasVulnerabilitiesInReport checks if a report has any vulnerabilities
+// hasVulnerabilitiesInReport checks if a report has findings, as the summary extractor
+// of its kind reads them
 func hasVulnerabilitiesInReport(report Report) bool {
 	if report.Data == nil {
 		return false
@@ -457,49 +551,68 @@ func hasVulnerabilitiesInReport(report Report) bool {
 		return false
 	}
 
-	var summary map[string]interface{}
-
-	if reportObj, ok := data["report"].(map[string]interface{}); ok {
-		if s, ok := reportObj["summary"].(map[string]interface{}); ok {
-			summary = s
-		}
-	}
-
-	if summary == nil {
+	if _, ok := data["report"].(map[string]interface{}); !ok {
 		if s, ok := data["summary"].(map[string]interface{}); ok {
-			summary = s
+			data = map[string]interface{}{"report": map[string]interface{}{"summary": s}}
 		}
 	}
-
-	if summary == nil {
-		return false
-	}
-
-	severities := []string{"criticalCount", "highCount", "mediumCount", "lowCount"}
-	for _, key := range severities {
-		if count, ok := summary[key].(float64); ok && count > 0 {
-			return true
-		}
-		if count, ok := summary[key].(int); ok && count > 0 {
-			return true
-		}
-		if count, ok := summary[key].(int64); ok && count > 0 {
-			return true
-		}
-	}
-
-	return false
+	return kubernetes.ExtractSummary(report.Type, data).HasFindings
 }
 
 func (c *Cache) GetStats() map[string]interface{} {
 	c.mu.RLock()
 	itemCount := len(c.items)
 	reportCount := len(c.reportKeys)
+	memoryBytes := c.bytes
+	byPrefix := make(map[string]int)
+	for k := range c.items {
+		prefix, _, _ := strings.Cut(k, ":")
+		byPrefix[prefix]++
+	}
 	c.mu.RUnlock()
 
+	hits, misses := c.hits.Load(), c.misses.Load()
+	hitRatio := 0.0
+	if hits+misses > 0 {
+		hitRatio = float64(hits) / float64(hits+misses)
+	}
+	var lastPersist *time.Time
+	if ts := c.lastPersist.Load(); ts != 0 {
+		t := time.Unix(0, ts).UTC()
+		lastPersist = &t
+	}
+
 	return map[string]interface{}{
-		"total_items":  itemCount,
-		"report_items": reportCount,
+		"total_items":       itemCount,
+		"report_items":      reportCount,
+		"entries_by_prefix": byPrefix,
+		"memory_bytes":      memoryBytes,
+		"hits":              hits,
+		"misses":            misses,
+		"hit_ratio":         hitRatio,
+		"evictions":         c.evictions.Load(),
+		"key_collisions":    c.collisions.Load(),
+		"last_persist":      lastPersist,
+		"cache_file":        c.cacheFile,
+	}
+}
+
+// putItem stores an item and accounts for its cost. The caller holds c.mu.
+func (c *Cache) putItem(key string, item CacheItem) {
+	if old, ok := c.items[key]; ok {
+		c.bytes -= old.cost
+	}
+	c.items[key] = item
+	c.bytes += item.cost
+	c.changes++
+}
+
+// dropItem removes an item and its cost. The caller holds c.mu.
+func (c *Cache) dropItem(key string) {
+	if old, ok := c.items[key]; ok {
+		c.bytes -= old.cost
+		delete(c.items, key)
+		c.changes++
 	}
 }
 
@@ -526,12 +639,23 @@ func (c *Cache) LoadFromFile() error {
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.loadItemsLocked(items)
+	// what was loaded is on disk already
+	c.savedChanges.Store(c.changes)
 
-	// Reset counters before rebuilding from cache
-	ResetReportCounts()
+	return nil
+}
 
+// loadItemsLocked fills the cache with the entries of cache.json or a snapshot, rebuilding
+// the report indexes and rollups. c.mu must be held.
+func (c *Cache) loadItemsLocked(items map[string]CacheItem) {
 	now := time.Now().Unix()
+	cfg := config.Get()
 	for k, item := range items {
+		if cfg.NamespaceExcluded(namespaceFromCacheKey(k)) {
+			// excluded since the cache was saved
+			continue
+		}
 		isReport := strings.HasPrefix(k, "report:")
 		if isReport {
 			var report Report
@@ -549,73 +673,45 @@ func (c *Cache) LoadFromFile() error {
 			cost := int64(len(k)) + estimateSize(item.Value)
 			c.cache.SetWithTTL(k, item.Value, cost, expiration)
 			if isReport {
-				c.items[k] = CacheItem{
+				c.putItem(k, CacheItem{
 					Value:      item.Value,
 					Expiration: time.Now().Add(expiration).Unix(),
-				}
+					cost:       cost,
+				})
 				c.reportKeys[k] = true
-				if typ := reportTypeFromKey(k); typ != "" {
-					if c.typeIndex[typ] == nil {
-						c.typeIndex[typ] = make(map[string]bool)
-					}
-					c.typeIndex[typ][k] = true
-				}
-				c.updateCountersFromReportKey(k, item.Value)
+				c.indexReportKey(k)
+				fleet.set(k, item.Value)
+				c.rollups.set(k, item.Value)
+				suggestions.set(k, item.Value)
 			} else {
-				c.items[k] = item
+				item.cost = cost
+				c.putItem(k, item)
 			}
 		} else if isReport {
 			if val, found := c.cache.Get(k); found {
 				cost := int64(len(k)) + estimateSize(val)
 				c.cache.SetWithTTL(k, val, cost, 7*24*time.Hour)
-				c.items[k] = CacheItem{
+				c.putItem(k, CacheItem{
 					Value:      val,
 					Expiration: time.Now().Add(7 * 24 * time.Hour).Unix(),
-				}
+					cost:       cost,
+				})
 				c.reportKeys[k] = true
-				if typ := reportTypeFromKey(k); typ != "" {
-					if c.typeIndex[typ] == nil {
-						c.typeIndex[typ] = make(map[string]bool)
-					}
-					c.typeIndex[typ][k] = true
-				}
-				c.updateCountersFromReportKey(k, val)
+				c.indexReportKey(k)
+				fleet.set(k, val)
+				c.rollups.set(k, val)
+				suggestions.set(k, val)
 			}
 		}
 	}
-
-	return nil
-}
-
-// updateCountersFromReportKey parses a report key and updates counters
-// Key format: "report:<cluster>:<namespace>:<type>:<name>"
-func (c *Cache) updateCountersFromReportKey(key string, value interface{}) {
-	parts := strings.SplitN(key, ":", 5)
-	if len(parts) < 5 {
-		return
-	}
-	cluster := parts[1]
-	namespace := parts[2]
-	reportType := parts[3]
-
-	// Check if report has vulnerabilities
-	hasVuln := false
-	if report, ok := value.(Report); ok {
-		hasVuln = hasVulnerabilitiesInReport(report)
-	} else if reportMap, ok := value.(map[string]interface{}); ok {
-		// Convert map to Report struct for vulnerability check
-		report := Report{Data: reportMap["data"]}
-		hasVuln = hasVulnerabilitiesInReport(report)
-	}
-
-	// Increment counters (don't hold cache mutex - use counter's own mutex)
-	IncrementReportCount(cluster, namespace, reportType, hasVuln)
 }
 
 func (c *Cache) SaveToFile() error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
 
+	c.mu.RLock()
+	changes := c.changes
 	now := time.Now().Unix()
 	validItems := make(map[string]CacheItem)
 	for k, item := range c.items {
@@ -623,19 +719,52 @@ func (c *Cache) SaveToFile() error {
 			validItems[k] = item
 		}
 	}
-
 	data, err := json.MarshalIndent(validItems, "", "  ")
+	c.mu.RUnlock()
 	if err != nil {
 		return fmt.Errorf("failed to marshal cache data: %w", err)
 	}
 
-	if err := os.WriteFile(c.cacheFile, data, 0644); err != nil {
+	// write next to the file and rename, so a save cut short by a restart leaves the
+	// previous cache.json intact
+	tmp := c.cacheFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp, c.cacheFile); err != nil {
 		return fmt.Errorf("failed to write cache file: %w", err)
 	}
+	c.savedChanges.Store(changes)
+	c.lastPersist.Store(time.Now().UnixNano())
 
 	return nil
 }
 
+// SaveIfChanged saves the cache unless nothing was written to it since the last save,
+// and tells whether it saved.
+func (c *Cache) SaveIfChanged() (bool, error) {
+	c.mu.RLock()
+	changed := c.changes != c.savedChanges.Load()
+	c.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+	return true, c.SaveToFile()
+}
+
+// SaveCache saves the global cache if it changed, for a shutdown to keep what was
+// written since the last periodic save.
+func SaveCache() error {
+	if globalCache == nil {
+		return nil
+	}
+	saved, err := globalCache.SaveIfChanged()
+	if saved && err == nil {
+		utils.LogInfo("Cache saved", map[string]interface{}{"file": globalCache.cacheFile})
+	}
+	return err
+}
+
 func getCache() *Cache {
 	return GetCache()
 }
@@ -663,6 +792,77 @@ func parseReportCacheKey(key string) (cluster, namespace, reportType, name strin
 	return parts[0], parts[1], parts[2], parts[3], true
 }
 
+// namespaceFromCacheKey returns the namespace of report:, detail: and namespace: keys.
+func namespaceFromCacheKey(key string) string {
+	prefix, rest, _ := strings.Cut(key, ":")
+	parts := strings.SplitN(rest, ":", 3)
+	switch prefix {
+	case "report", "detail", "namespace":
+		if len(parts) >= 2 {
+			return parts[1]
+		}
+	}
+	return ""
+}
+
+// clusterFromCacheKey returns the cluster of cluster, namespace, report and detail keys.
+func clusterFromCacheKey(key string) string {
+	prefix, rest, _ := strings.Cut(key, ":")
+	switch prefix {
+	case "cluster":
+		return rest
+	case "report", "detail", "namespace":
+		cluster, _, _ := strings.Cut(rest, ":")
+		return cluster
+	}
+	return ""
+}
+
+func clusterFromReportKey(key string) string {
+	cluster, _, _, _, _ := parseReportCacheKey(key)
+	return cluster
+}
+
+// indexReportKey adds a report key to the type and name indexes and returns its type.
+// The caller holds c.mu.
+func (c *Cache) indexReportKey(key string) string {
+	_, _, typ, name, ok := parseReportCacheKey(key)
+	if !ok || typ == "" {
+		return ""
+	}
+	if c.typeIndex[typ] == nil {
+		c.typeIndex[typ] = make(map[string]bool)
+	}
+	c.typeIndex[typ][key] = true
+	if c.nameIndex[typ] == nil {
+		c.nameIndex[typ] = make(map[string]map[string]bool)
+	}
+	if c.nameIndex[typ][name] == nil {
+		c.nameIndex[typ][name] = make(map[string]bool)
+	}
+	c.nameIndex[typ][name][key] = true
+	return typ
+}
+
+// unindexReportKey removes a report key from the type and name indexes and returns its
+// type. The caller holds c.mu.
+func (c *Cache) unindexReportKey(key string) string {
+	_, _, typ, name, ok := parseReportCacheKey(key)
+	if !ok || typ == "" {
+		return ""
+	}
+	if idx, ok := c.typeIndex[typ]; ok {
+		delete(idx, key)
+	}
+	if names, ok := c.nameIndex[typ]; ok {
+		delete(names[name], key)
+		if len(names[name]) == 0 {
+			delete(names, name)
+		}
+	}
+	return typ
+}
+
 func reportTypeFromKey(key string) string {
 	if !strings.HasPrefix(key, "report:") {
 		return ""
@@ -674,12 +874,10 @@ func reportTypeFromKey(key string) string {
 	return parts[2]
 }
 
-func (c *Cache) hashKey(key string) uint64 {
-	var hash uint64
-	for _, b := range []byte(key) {
-		hash = hash*31 + uint64(b)
-	}
-	return hash
+// hashKey hashes a key the way ristretto does, so evicted items can be mapped back.
+func (c *Cache) hashKey(key string) cacheKeyHash {
+	k, conflict := z.KeyToHash(key)
+	return cacheKeyHash{key: k, conflict: conflict}
 }
 
 func estimateSize(value interface{}) int64 {
@@ -720,10 +918,15 @@ func estimateSize(value interface{}) int64 {
 }
 
 func (c *Cache) periodicSave() {
-	ticker := time.NewTicker(60 * time.Second)
+	interval := config.Get().SaveInterval
+	if interval <= 0 {
+		// saved on shutdown only
+		return
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	for range ticker.C {
-		if err := c.SaveToFile(); err != nil {
+		if _, err := c.SaveIfChanged(); err != nil {
 			utils.LogWarning("Failed to save cache", map[string]interface{}{"error": err.Error()})
 		}
 	}
@@ -740,20 +943,20 @@ func (c *Cache) ValidateAndCleanup(ctx context.Context) {
 	c.mu.RUnlock()
 
 	if len(reportKeysCopy) == 0 {
-		utils.LogDebug("No cache data to validate")
+		utils.LogModuleDebug(utils.ModuleCache, "No cache data to validate")
 		return
 	}
 
 	clients := GetAllClusterClients()
 	if len(clients) == 0 {
-		utils.LogDebug("No cluster clients available, skipping cache validation")
+		utils.LogModuleDebug(utils.ModuleCache, "No cluster clients available, skipping cache validation")
 		return
 	}
 
 	registry := config.GetGlobalRegistry()
 	reports := registry.GetAllReports()
 	if len(reports) == 0 {
-		utils.LogDebug("No report types discovered, skipping cache validation")
+		utils.LogModuleDebug(utils.ModuleCache, "No report types discovered, skipping cache validation")
 		return
 	}
 
@@ -783,8 +986,8 @@ func (c *Cache) ValidateAndCleanup(ctx context.Context) {
 	}
 
 	const batchSize = 50
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 3)
+	var g errgroup.Group
+	g.SetLimit(3)
 
 	for clusterName, clusterClient := range clients {
 		expectedReports := clusterReports[clusterName]
@@ -804,33 +1007,28 @@ func (c *Cache) ValidateAndCleanup(ctx context.Context) {
 			}
 			batch := reportKeysList[i:end]
 
-			wg.Add(1)
-			semaphore <- struct{}{}
-			go func(name string, cc *ClusterClient, batch []string) {
-				defer wg.Done()
-				defer func() { <-semaphore }()
-
-				select {
-				case <-ctx.Done():
-					return
-				default:
+			name, cc := clusterName, clusterClient
+			g.Go(func() error {
+				if ctx.Err() != nil {
+					return nil
 				}
 
+				if cc.Client == nil {
+					return nil
+				}
 				informerManager := cc.Client.GetInformer()
 				if informerManager == nil {
-					return
+					return nil
 				}
 
 				informers := informerManager.GetAllInformers()
 				if len(informers) == 0 {
-					return
+					return nil
 				}
 
 				for _, key := range batch {
-					select {
-					case <-ctx.Done():
-						return
-					default:
+					if ctx.Err() != nil {
+						return nil
 					}
 
 					parts := strings.SplitN(key, ":", 3)
@@ -848,6 +1046,10 @@ func (c *Cache) ValidateAndCleanup(ctx context.Context) {
 
 					informer, hasInformer := informers[typ]
 					if !hasInformer {
+						// reports cached before the kind was disabled; not archived, see purgeReportKind
+						if !config.Get().ReportKinds.Enabled(name, typ) {
+							c.deleteReportEntryByKey(reportKey(name, ns, typ, repName))
+						}
 						continue
 					}
 
@@ -857,8 +1059,9 @@ func (c *Cache) ValidateAndCleanup(ctx context.Context) {
 						_, exists, _ := store.GetByKey(storeKey)
 						if !exists {
 							cacheKey := reportKey(name, ns, typ, repName)
+							archiveReport(c, cacheKey)
 							c.deleteReportEntryByKey(cacheKey)
-							utils.LogDebug("Removed stale cache entry", map[string]interface{}{
+							utils.LogModuleDebug(utils.ModuleCache, "Removed stale cache entry", map[string]interface{}{
 								"cluster":   name,
 								"namespace": ns,
 								"type":      typ,
@@ -878,8 +1081,9 @@ func (c *Cache) ValidateAndCleanup(ctx context.Context) {
 						}
 						if !found {
 							cacheKey := reportKey(name, ns, typ, repName)
+							archiveReport(c, cacheKey)
 							c.deleteReportEntryByKey(cacheKey)
-							utils.LogDebug("Removed stale cache entry", map[string]interface{}{
+							utils.LogModuleDebug(utils.ModuleCache, "Removed stale cache entry", map[string]interface{}{
 								"cluster":   name,
 								"namespace": ns,
 								"type":      typ,
@@ -888,10 +1092,11 @@ func (c *Cache) ValidateAndCleanup(ctx context.Context) {
 						}
 					}
 				}
-			}(clusterName, clusterClient, batch)
+				return nil
+			})
 		}
 	}
-	wg.Wait()
+	g.Wait()
 	
 	utils.LogInfo("Cache validation and cleanup completed")
 }
@@ -931,6 +1136,56 @@ func (c *CacheUpdaterImpl) SetReport(cluster, namespace, reportType, name string
 		return
 	}
 
+	apiReport := newCachedReport(cluster, namespace, reportType, name, report, time.Now())
+	key := reportKey(cluster, namespace, reportType, name)
+	prev, _ := cache.Get(key)
+	cache.Set(key, apiReport, 7*24*time.Hour)
+	recordCachedReport(apiReport, report.Findings)
+	publishReportUpdate(prev, apiReport)
+}
+
+// SetReports caches a batch of informer reports of one cluster under a single cache lock.
+func (c *CacheUpdaterImpl) SetReports(cluster string, reports []*kubernetes.Report) {
+	cache := getCache()
+	if cache == nil {
+		utils.LogError("Cache is nil in SetReports", map[string]interface{}{"cluster": cluster, "reports": len(reports)})
+		return
+	}
+
+	now := time.Now()
+	entries := make([]cacheEntry, len(reports))
+	prev := make([]interface{}, len(reports))
+	for i, report := range reports {
+		apiReport := newCachedReport(cluster, report.Namespace, report.Type, report.Name, report, now)
+		entries[i] = cacheEntry{key: reportKey(cluster, report.Namespace, report.Type, report.Name), value: apiReport}
+		prev[i], _ = cache.Get(entries[i].key)
+	}
+	cache.SetBatch(entries, 7*24*time.Hour)
+	for i, report := range reports {
+		recordCachedReport(entries[i].value.(Report), report.Findings)
+		publishReportUpdate(prev[i], entries[i].value.(Report))
+	}
+}
+
+// publishReportUpdate sends a report.updated event, and the outbound webhook events, for
+// a new or rescanned report; the informer resyncs that cache unchanged reports again send
+// none.
+func publishReportUpdate(prev interface{}, report Report) {
+	if old, ok := convertCacheValue[Report](prev); ok && old.Status == report.Status && old.ScannedAt.Equal(report.ScannedAt) {
+		return
+	}
+	publishWebhookEvents(prev, report)
+	changeEvents.publish(ChangeEvent{
+		Type:       EventReportUpdated,
+		Cluster:    report.Cluster,
+		Namespace:  report.Namespace,
+		ReportType: report.Type,
+		Name:       report.Name,
+	})
+}
+
+// newCachedReport builds the cached summary of an informer report.
+func newCachedReport(cluster, namespace, reportType, name string, report *kubernetes.Report, now time.Time) Report {
 	apiReport := Report{
 		Type:      reportType,
 		Cluster:   cluster,
@@ -938,11 +1193,37 @@ func (c *CacheUpdaterImpl) SetReport(cluster, namespace, reportType, name string
 		Name:      name,
 		Status:    report.Status,
 		Data:      report.Data,
-		UpdatedAt: time.Now(),
+		ScannedAt: report.ScannedAt,
+		UpdatedAt: now,
+		CachedAt:  now,
+		Fixable:   countFixable(report.Findings),
+
+		UID:             report.UID,
+		ResourceVersion: report.ResourceVersion,
+	}
+	findings := report.Findings
+	if findings != nil {
+		findings = applySeverityOverrides(&apiReport, findings, severityOverrides.get())
 	}
+	if findings != nil && len(getSeverityRules()) > 0 {
+		sev, totals := effectiveSeverity(findings, severityModifiers(cluster, namespace))
+		apiReport.EffectiveSeverity = sev
+		apiReport.EffectiveSummary = &totals
+	}
+	if report.Findings != nil {
+		apiReport.Exposure = reportExposure(apiReport)
+	}
+	apiReport.Provenance = reportProvenance(apiReport)
+	return apiReport
+}
 
-	key := reportKey(cluster, namespace, reportType, name)
-	cache.Set(key, apiReport, 7*24*time.Hour)
+// recordCachedReport stores what the database keeps of a newly cached report.
+func recordCachedReport(report Report, findings []kubernetes.Finding) {
+	if findings != nil {
+		recordFindings(report.Cluster, report.Namespace, report.Type, report.Name, findings)
+		suggestions.setCVEs(reportKey(report.Cluster, report.Namespace, report.Type, report.Name), findings)
+	}
+	recordReportHistory(report)
 }
 
 func (c *CacheUpdaterImpl) InvalidateReportDetail(cluster, namespace, reportType, name string) {
@@ -959,19 +1240,50 @@ func (c *CacheUpdaterImpl) DeleteReport(cluster, namespace, reportType, name str
 		return
 	}
 
+	archiveReport(cache, reportKey(cluster, namespace, reportType, name))
 	cache.DeleteReportEntry(cluster, namespace, reportType, name)
+	deleteReportBlob(cluster, namespace, reportType, name)
+	changeEvents.publish(ChangeEvent{
+		Type:       EventReportDeleted,
+		Cluster:    cluster,
+		Namespace:  namespace,
+		ReportType: reportType,
+		Name:       name,
+	})
 }
 
-func (c *CacheUpdaterImpl) IncrementCount(cluster, namespace, reportType string, hasVuln bool) {
-	IncrementReportCount(cluster, namespace, reportType, hasVuln)
-}
+// DeleteNamespace removes the cached reports of a deleted namespace, resolves its open
+// findings and drops it from the cluster's namespace list.
+func (c *CacheUpdaterImpl) DeleteNamespace(cluster, namespace string) {
+	cache := getCache()
+	if cache == nil {
+		return
+	}
 
-func (c *CacheUpdaterImpl) DecrementCount(cluster, namespace, reportType string, hasVuln bool) {
-	DecrementReportCount(cluster, namespace, reportType, hasVuln)
-}
+	keys := cache.NamespaceReportKeys(cluster, namespace)
+	for _, key := range keys {
+		_, _, reportType, name, _ := parseReportCacheKey(key)
+		c.DeleteReport(cluster, namespace, reportType, name)
+	}
+	cache.Delete(namespaceKey(cluster, namespace))
+	if c.reg != nil {
+		if client := c.reg.Get(cluster); client != nil {
+			client.removeNamespace(namespace)
+		}
+	}
+	purgeNamespaceFindings(cluster, namespace)
 
-func (c *CacheUpdaterImpl) AdjustVulnCount(cluster, namespace, reportType string, delta int) {
-	AdjustVulnCount(cluster, namespace, reportType, delta)
+	changeEvents.publish(ChangeEvent{
+		Type:      EventNamespaceDeleted,
+		Cluster:   cluster,
+		Namespace: namespace,
+		Reports:   len(keys),
+	})
+	utils.LogInfo("Purged reports of deleted namespace", map[string]interface{}{
+		"cluster":   cluster,
+		"namespace": namespace,
+		"reports":   len(keys),
+	})
 }
 
 func (c *CacheUpdaterImpl) UpdateSyncState(clusterName string, state string) {
@@ -990,7 +1302,7 @@ func reportDetailKey(cluster, ns, typ, name string) string {
 }
 
 // GetReportDetail retrieves full report details from cache
-func GetReportDetail(cluster, namespace, reportType, name string) (Report, bool) {
+func GetReportDetail(ctx context.Context, cluster, namespace, reportType, name string) (Report, bool) {
 	cache := getCache()
 	if cache == nil {
 		return Report{}, false
@@ -999,7 +1311,7 @@ func GetReportDetail(cluster, namespace, reportType, name string) (Report, bool)
 	key := reportDetailKey(cluster, namespace, reportType, name)
 	if value, found := cache.Get(key); found {
 		if report, ok := value.(Report); ok {
-			return report, true
+			return hydrateReportDetail(ctx, store.Get(), report)
 		}
 		// Try JSON conversion
 		if mapVal, ok := value.(map[string]interface{}); ok {
@@ -1007,7 +1319,7 @@ func GetReportDetail(cluster, namespace, reportType, name string) (Report, bool)
 			if err == nil {
 				var report Report
 				if err := json.Unmarshal(b, &report); err == nil {
-					return report, true
+					return hydrateReportDetail(ctx, store.Get(), report)
 				}
 			}
 		}
@@ -1022,10 +1334,15 @@ func SetReportDetail(report Report) {
 		return
 	}
 
+	annotateSeverityOverrides(&report)
+	annotateEffectiveSeverity(report)
 	key := reportDetailKey(report.Cluster, report.Namespace, report.Type, report.Name)
 	// Use random TTL between 5-10 minutes to avoid thundering herd
 	ttl := 5*time.Minute + time.Duration(rand.Intn(5))*time.Minute
-	cache.Set(key, report, ttl)
+	// the detail is cached for everyone; storing its blob does not depend on the caller
+	ctx, cancel := storeCallContext(context.Background())
+	defer cancel()
+	cache.Set(key, externalizeLargeFields(ctx, store.Get(), report, config.Get().OversizedReportBytes), ttl)
 }
 
 // RefreshReportDetailAsync fetches full report from K8s and updates cache asynchronously
@@ -1036,7 +1353,7 @@ func RefreshReportDetailAsync(cluster, namespace, reportType, name string, repor
 	// Check if refresh is already in progress
 	if _, inProgress := refreshInProgress.LoadOrStore(key, true); inProgress {
 		// Refresh already in progress, skip
-		utils.LogDebug("Async refresh already in progress, skipping", map[string]interface{}{
+		utils.LogModuleDebug(utils.ModuleCache, "Async refresh already in progress, skipping", map[string]interface{}{
 			"cluster":   cluster,
 			"namespace": namespace,
 			"type":      reportType,
@@ -1050,16 +1367,17 @@ func RefreshReportDetailAsync(cluster, namespace, reportType, name string, repor
 		defer refreshInProgress.Delete(key)
 		
 		clusterClient := GetClusterClient(cluster)
-		if clusterClient == nil {
+		if clusterClient == nil || clusterClient.Source == nil {
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		// outlives the request that triggered it
+		ctx, cancel := kubeCallContext(context.Background())
 		defer cancel()
 
-		fullReport, err := clusterClient.Client.GetReportDetails(ctx, reportKind, namespace, name)
+		fullReport, err := clusterClient.Source.GetReportDetails(ctx, reportKind, namespace, name)
 		if err != nil {
-			utils.LogDebug("Async refresh failed", map[string]interface{}{
+			utils.LogModuleDebug(utils.ModuleCache, "Async refresh failed", map[string]interface{}{
 				"cluster":   cluster,
 				"namespace": namespace,
 				"type":      reportType,
@@ -1070,6 +1388,7 @@ func RefreshReportDetailAsync(cluster, namespace, reportType, name string, repor
 		}
 
 		if fullReport != nil {
+			now := time.Now()
 			report := Report{
 				Type:      reportType,
 				Cluster:   cluster,
@@ -1077,10 +1396,15 @@ func RefreshReportDetailAsync(cluster, namespace, reportType, name string, repor
 				Name:      name,
 				Status:    fullReport.Status,
 				Data:      fullReport.Data,
-				UpdatedAt: time.Now(),
+				ScannedAt: fullReport.ScannedAt,
+				UpdatedAt: now,
+				CachedAt:  now,
+
+				UID:             fullReport.UID,
+				ResourceVersion: fullReport.ResourceVersion,
 			}
 			SetReportDetail(report)
-			utils.LogDebug("Async refresh completed", map[string]interface{}{
+			utils.LogModuleDebug(utils.ModuleCache, "Async refresh completed", map[string]interface{}{
 				"cluster":   cluster,
 				"namespace": namespace,
 				"type":      reportType,
@@ -1090,167 +1414,8 @@ func RefreshReportDetailAsync(cluster, namespace, reportType, name string, repor
 	}()
 }
 
-// countKey generates a key for the counter map
-// Format: "<cluster>:<type>" for cluster-level, "<cluster>:<ns>:<type>" for namespace-level
-func countKey(cluster, namespace, reportType string) string {
-	if namespace == "" {
-		return fmt.Sprintf("%s:%s", cluster, reportType)
-	}
-	return fmt.Sprintf("%s:%s:%s", cluster, namespace, reportType)
-}
-
-// IncrementReportCount increments the report count for a given cluster/namespace/type
-func IncrementReportCount(cluster, namespace, reportType string, hasVuln bool) {
-	counters.mu.Lock()
-	defer counters.mu.Unlock()
-
-	// Update cluster-level count
-	clusterKey := countKey(cluster, "", reportType)
-	if counters.counts[clusterKey] == nil {
-		counters.counts[clusterKey] = &counterPair{}
-	}
-	counters.counts[clusterKey].total++
-	if hasVuln {
-		counters.counts[clusterKey].withVuln++
-	}
-
-	// Update namespace-level count (if namespace is not empty)
-	if namespace != "" {
-		nsKey := countKey(cluster, namespace, reportType)
-		if counters.counts[nsKey] == nil {
-			counters.counts[nsKey] = &counterPair{}
-		}
-		counters.counts[nsKey].total++
-		if hasVuln {
-			counters.counts[nsKey].withVuln++
-		}
-	}
-}
-
-// DecrementReportCount decrements the report count for a given cluster/namespace/type
-func DecrementReportCount(cluster, namespace, reportType string, hasVuln bool) {
-	counters.mu.Lock()
-	defer counters.mu.Unlock()
-
-	// Update cluster-level count
-	clusterKey := countKey(cluster, "", reportType)
-	if cp := counters.counts[clusterKey]; cp != nil {
-		if cp.total > 0 {
-			cp.total--
-		}
-		if hasVuln && cp.withVuln > 0 {
-			cp.withVuln--
-		}
-	}
-
-	// Update namespace-level count (if namespace is not empty)
-	if namespace != "" {
-		nsKey := countKey(cluster, namespace, reportType)
-		if cp := counters.counts[nsKey]; cp != nil {
-			if cp.total > 0 {
-				cp.total--
-			}
-			if hasVuln && cp.withVuln > 0 {
-				cp.withVuln--
-			}
-		}
-	}
-}
-
-// AdjustVulnCount adjusts only the withVuln counter by delta (used when vuln status changes on update)
-func AdjustVulnCount(cluster, namespace, reportType string, delta int) {
-	counters.mu.Lock()
-	defer counters.mu.Unlock()
-
-	// Update cluster-level vuln count
-	clusterKey := countKey(cluster, "", reportType)
-	if cp := counters.counts[clusterKey]; cp != nil {
-		cp.withVuln += delta
-		if cp.withVuln < 0 {
-			cp.withVuln = 0
-		}
-	}
-
-	// Update namespace-level vuln count (if namespace is not empty)
-	if namespace != "" {
-		nsKey := countKey(cluster, namespace, reportType)
-		if cp := counters.counts[nsKey]; cp != nil {
-			cp.withVuln += delta
-			if cp.withVuln < 0 {
-				cp.withVuln = 0
-			}
-		}
-	}
-}
-
-// GetReportCounts returns the total and withVulnerabilities count for a cluster/type
-// If cluster is empty, returns counts across all clusters for the given type
-func GetReportCounts(cluster, reportType string) (total, withVuln int, found bool) {
-	counters.mu.RLock()
-	defer counters.mu.RUnlock()
-
-	if cluster != "" {
-		// Specific cluster
-		key := countKey(cluster, "", reportType)
-		if cp := counters.counts[key]; cp != nil {
-			return cp.total, cp.withVuln, true
-		}
-		return 0, 0, false
-	}
-
-	// Aggregate across all clusters
-	for key, cp := range counters.counts {
-		// Only count cluster-level keys (format: "cluster:type", not "cluster:ns:type")
-		parts := strings.Split(key, ":")
-		if len(parts) == 2 && parts[1] == reportType {
-			total += cp.total
-			withVuln += cp.withVuln
-			found = true
-		}
-	}
-	return total, withVuln, found
-}
-
-// GetReportCountsByNamespace returns counts filtered by namespace(s)
-func GetReportCountsByNamespace(cluster, reportType string, namespaces []string) (total, withVuln int, found bool) {
-	// If no namespace filter or "all" is in the list, return cluster-level counts
-	hasAll := false
-	for _, ns := range namespaces {
-		if ns == "all" {
-			hasAll = true
-			break
-		}
-	}
-
-	if len(namespaces) == 0 || hasAll {
-		// Call GetReportCounts directly without holding the lock to avoid deadlock
-		return GetReportCounts(cluster, reportType)
-	}
-
-	// Sum counts for specified namespaces
-	counters.mu.RLock()
-	defer counters.mu.RUnlock()
-
-	for _, ns := range namespaces {
-		key := countKey(cluster, ns, reportType)
-		if cp := counters.counts[key]; cp != nil {
-			total += cp.total
-			withVuln += cp.withVuln
-			found = true
-		}
-	}
-	return total, withVuln, found
-}
-
-// ResetReportCounts clears all counters (used during re-initialization)
-func ResetReportCounts() {
-	counters.mu.Lock()
-	defer counters.mu.Unlock()
-	counters.counts = make(map[string]*counterPair)
-}
-
 // GetReportDetailWithTTL retrieves report detail and its remaining TTL
-func GetReportDetailWithTTL(cluster, namespace, reportType, name string) (Report, bool, time.Duration) {
+func GetReportDetailWithTTL(ctx context.Context, cluster, namespace, reportType, name string) (Report, bool, time.Duration) {
 	cache := getCache()
 	if cache == nil {
 		return Report{}, false, 0
@@ -1276,7 +1441,10 @@ func GetReportDetailWithTTL(cluster, namespace, reportType, name string) (Report
 	// Get the actual value
 	if value, found := cache.Get(key); found {
 		if report, ok := value.(Report); ok {
-			return report, true, remaining
+			if report, ok := hydrateReportDetail(ctx, store.Get(), report); ok {
+				return report, true, remaining
+			}
+			return Report{}, false, 0
 		}
 		// Try JSON conversion
 		if mapVal, ok := value.(map[string]interface{}); ok {
@@ -1284,7 +1452,9 @@ func GetReportDetailWithTTL(cluster, namespace, reportType, name string) (Report
 			if err == nil {
 				var report Report
 				if err := json.Unmarshal(b, &report); err == nil {
-					return report, true, remaining
+					if report, ok := hydrateReportDetail(ctx, store.Get(), report); ok {
+						return report, true, remaining
+					}
 				}
 			}
 		}
@@ -1336,7 +1506,13 @@ func extractSummaryCounts(report Report) (int, int, int, int) {
 	return getInt("criticalCount"), getInt("highCount"), getInt("mediumCount"), getInt("lowCount")
 }
 
-func (c *Cache) GetOverviewData(clusterFilter string) *ClusterOverview {
+// GetOverviewData aggregates the cached reports; ignoreUnfixable counts only findings with a
+// fixed version and keep, when set, selects the reports counted.
+func (c *Cache) GetOverviewData(clusterFilter string, ignoreUnfixable bool, keep func(Report) bool) *ClusterOverview {
+	if !ignoreUnfixable && keep == nil {
+		return c.rollups.overview(clusterFilter)
+	}
+
 	overview := &ClusterOverview{
 		SeverityTotals: SeverityTotals{},
 		ScanTypesBreakdown: make(map[string]TypeBreakdown),
@@ -1351,6 +1527,7 @@ func (c *Cache) GetOverviewData(clusterFilter string) *ClusterOverview {
 	workloadScores := make(map[string]*WorkloadSummary)
 	nsScores := make(map[string]*NamespaceSummary)
 	clusterScores := make(map[string]*ClusterSummary)
+	clusters := clusterSet(clusterFilter)
 
 	for key, item := range c.items {
 		if !strings.HasPrefix(key, "report:") {
@@ -1362,9 +1539,15 @@ func (c *Cache) GetOverviewData(clusterFilter string) *ClusterOverview {
 			continue
 		}
 
-		if clusterFilter != "" && report.Cluster != clusterFilter {
+		if clusters != nil && !clusters[report.Cluster] {
+			continue
+		}
+		if keep != nil && !keep(report) {
 			continue
 		}
+		if ignoreUnfixable {
+			report = withoutUnfixable(report)
+		}
 
 		overview.TotalReports++
 
@@ -1409,6 +1592,13 @@ func (c *Cache) GetOverviewData(clusterFilter string) *ClusterOverview {
 		}
 	}
 
+	rankOverview(overview, workloadScores, nsScores, clusterScores, len(clusters) == 1)
+	return overview
+}
+
+// rankOverview adds the five most vulnerable workloads to an overview and, ranked, the
+// vulnerable clusters, or for a single cluster its vulnerable namespaces.
+func rankOverview(overview *ClusterOverview, workloadScores map[string]*WorkloadSummary, nsScores map[string]*NamespaceSummary, clusterScores map[string]*ClusterSummary, singleCluster bool) {
 	for _, w := range workloadScores {
 		overview.TopVulnerableWorkloads = append(overview.TopVulnerableWorkloads, *w)
 	}
@@ -1422,7 +1612,7 @@ func (c *Cache) GetOverviewData(clusterFilter string) *ClusterOverview {
 		overview.TopVulnerableWorkloads = overview.TopVulnerableWorkloads[:5]
 	}
 
-	if clusterFilter == "" {
+	if !singleCluster {
 		for _, cScore := range clusterScores {
 			overview.VulnerableClusters = append(overview.VulnerableClusters, *cScore)
 		}
@@ -1443,8 +1633,6 @@ func (c *Cache) GetOverviewData(clusterFilter string) *ClusterOverview {
 			return overview.VulnerableNamespaces[i].High > overview.VulnerableNamespaces[j].High
 		})
 	}
-
-	return overview
 }
 
 func (c *Cache) recordTrend() {
@@ -1460,7 +1648,7 @@ func (c *Cache) recordTrend() {
 		json.Unmarshal(data, &records)
 	}
 
-	global := c.GetOverviewData("")
+	global := c.GetOverviewData("", false, nil)
 	now := time.Now()
 	
 	records = append(records, TrendRecord{
@@ -1472,7 +1660,7 @@ func (c *Cache) recordTrend() {
 	})
 
 	for _, cluster := range global.VulnerableClusters {
-		co := c.GetOverviewData(cluster.Name)
+		co := c.GetOverviewData(cluster.Name, false, nil)
 		records = append(records, TrendRecord{
 			Timestamp: now,
 			Cluster:   cluster.Name,
@@ -1514,11 +1702,33 @@ func (c *Cache) GetTrends(clusterFilter string, days int) []TrendRecord {
 
 	cutoff := time.Now().Add(-time.Duration(days*24) * time.Hour)
 	
+	// without a filter the fleet-wide records are returned; with one, the records of its
+	// clusters are added up per recording
+	clusters := clusterSet(clusterFilter)
 	var filtered []TrendRecord
+	index := make(map[time.Time]int)
 	for _, r := range records {
-		if r.Cluster == clusterFilter && r.Timestamp.After(cutoff) {
-			filtered = append(filtered, r)
+		if !r.Timestamp.After(cutoff) {
+			continue
+		}
+		if clusters == nil {
+			if r.Cluster == "" {
+				filtered = append(filtered, r)
+			}
+			continue
+		}
+		if r.Cluster == "" || !clusters[r.Cluster] {
+			continue
+		}
+		i, ok := index[r.Timestamp]
+		if !ok {
+			index[r.Timestamp] = len(filtered)
+			filtered = append(filtered, TrendRecord{Timestamp: r.Timestamp, Cluster: clusterFilter})
+			i = len(filtered) - 1
 		}
+		filtered[i].Critical += r.Critical
+		filtered[i].High += r.High
+		filtered[i].Medium += r.Medium
 	}
 	return filtered
 }
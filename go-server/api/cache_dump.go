@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	cacheDumpDefaultLimit = 1000
+	cacheDumpMaxLimit     = 10000
+	// cacheDumpChunk is how many entries are encoded in parallel before being written
+	cacheDumpChunk = 256
+)
+
+// cacheDumpValuePrefixes are the key prefixes whose values are dumped as they are. They
+// hold cluster and namespace metadata and counters; other non-report values are dumped by
+// size only.
+var cacheDumpValuePrefixes = []string{"cluster:", "namespace:", "count:"}
+
+// CacheDumpEntry is one line of /api/v1/admin/cache/dump. Reports are reduced to their
+// identity and severity counts, so the dump carries no findings.
+type CacheDumpEntry struct {
+	Key       string              `json:"key"`
+	ExpiresAt time.Time           `json:"expiresAt"`
+	Size      int64               `json:"size"`
+	Report    *CacheReportSummary `json:"report,omitempty"`
+	Value     interface{}         `json:"value,omitempty"`
+}
+
+// CacheReportSummary is what the cache dump shows of a cached report.
+type CacheReportSummary struct {
+	Type      string         `json:"type"`
+	Cluster   string         `json:"cluster"`
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Status    string         `json:"status,omitempty"`
+	Summary   SeverityTotals `json:"summary"`
+	Fixable   int            `json:"fixable,omitempty"`
+	ScannedAt time.Time      `json:"scannedAt,omitzero"`
+	CachedAt  time.Time      `json:"cachedAt"`
+}
+
+type cacheDumpItem struct {
+	key  string
+	item CacheItem
+}
+
+// dumpItems returns up to limit live entries whose key has prefix and sorts after the
+// given key, in key order, and whether more follow. Report details are left out: they
+// hold the full findings.
+func (c *Cache) dumpItems(prefix, after string, limit int) ([]cacheDumpItem, bool) {
+	now := time.Now().Unix()
+	c.mu.RLock()
+	keys := make([]string, 0)
+	for k, item := range c.items {
+		if !strings.HasPrefix(k, prefix) || k <= after || strings.HasPrefix(k, "detail:") {
+			continue
+		}
+		if !strings.HasPrefix(k, "report:") && item.Expiration <= now {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	more := len(keys) > limit
+	if more {
+		keys = keys[:limit]
+	}
+	items := make([]cacheDumpItem, len(keys))
+	for i, k := range keys {
+		items[i] = cacheDumpItem{key: k, item: c.items[k]}
+	}
+	c.mu.RUnlock()
+	return items, more
+}
+
+func cacheDumpEntry(key string, item CacheItem) CacheDumpEntry {
+	entry := CacheDumpEntry{Key: key, ExpiresAt: time.Unix(item.Expiration, 0).UTC(), Size: item.cost}
+	if entry.Size == 0 {
+		entry.Size = int64(len(key)) + estimateSize(item.Value)
+	}
+	if strings.HasPrefix(key, "report:") {
+		if report, ok := convertCacheValue[Report](item.Value); ok {
+			c, h, m, l := extractSummaryCounts(report)
+			entry.Report = &CacheReportSummary{
+				Type:      report.Type,
+				Cluster:   report.Cluster,
+				Namespace: report.Namespace,
+				Name:      report.Name,
+				Status:    report.Status,
+				Summary:   SeverityTotals{Critical: c, High: h, Medium: m, Low: l},
+				Fixable:   report.Fixable,
+				ScannedAt: report.ScannedAt,
+				CachedAt:  report.CachedAt,
+			}
+		}
+		return entry
+	}
+	for _, p := range cacheDumpValuePrefixes {
+		if strings.HasPrefix(key, p) {
+			entry.Value = item.Value
+			break
+		}
+	}
+	return entry
+}
+
+// encodeCacheDump encodes entries as NDJSON lines, in order, spreading the work over the
+// CPUs.
+func encodeCacheDump(items []cacheDumpItem) ([][]byte, error) {
+	lines := make([][]byte, len(items))
+	var g errgroup.Group
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	for i := range items {
+		g.Go(func() error {
+			line, err := json.Marshal(cacheDumpEntry(items[i].key, items[i].item))
+			if err != nil {
+				return err
+			}
+			lines[i] = append(line, '\n')
+			return nil
+		})
+	}
+	return lines, g.Wait()
+}
+
+// DumpCache handles GET /api/v1/admin/cache/dump: it streams the cache entries whose key
+// starts with ?prefix= as NDJSON, one entry per line, so a support engineer can capture the
+// state behind a bug report without the cache file and its findings. Pages hold ?limit=
+// entries (1000 by default); when more follow, X-Next-After names the key to pass as
+// ?after= for the next page.
+func (h *Handler) DumpCache(w http.ResponseWriter, r *http.Request) {
+	c := getCache()
+	if c == nil {
+		writeError(w, http.StatusServiceUnavailable, "Cache not initialized")
+		return
+	}
+	q := r.URL.Query()
+	limit := cacheDumpDefaultLimit
+	if v := q.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = min(l, cacheDumpMaxLimit)
+	}
+
+	items, more := c.dumpItems(q.Get("prefix"), q.Get("after"), limit)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-store")
+	if more {
+		w.Header().Set("X-Next-After", items[len(items)-1].key)
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	for start := 0; start < len(items); start += cacheDumpChunk {
+		lines, err := encodeCacheDump(items[start:min(start+cacheDumpChunk, len(items))])
+		if err != nil {
+			// the status is sent; a truncated dump is all that can be reported
+			return
+		}
+		for _, line := range lines {
+			if _, err := w.Write(line); err != nil {
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDumpCache(t *testing.T) {
+	c := useTestCache(t)
+	for _, name := range []string{"a", "b", "c"} {
+		c.Set(reportKey("prod", "shop", "vulnerabilityreports", name), makeReport(name, "prod", "shop", "vulnerabilityreports", 2), 0)
+		c.Set(reportDetailKey("prod", "shop", "vulnerabilityreports", name), makeReport(name, "prod", "shop", "vulnerabilityreports", 2), 0)
+	}
+	c.Set(reportKey("dev", "shop", "vulnerabilityreports", "a"), makeReport("a", "dev", "shop", "vulnerabilityreports", 1), 0)
+	h := &Handler{}
+
+	dump := func(query string) ([]CacheDumpEntry, string) {
+		rec := httptest.NewRecorder()
+		h.DumpCache(rec, httptest.NewRequest("GET", "/api/v1/admin/cache/dump?"+query, nil))
+		if rec.Code != 200 {
+			t.Fatalf("dump %q: status %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var entries []CacheDumpEntry
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			var e CacheDumpEntry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+			}
+			entries = append(entries, e)
+		}
+		return entries, rec.Header().Get("X-Next-After")
+	}
+
+	entries, next := dump("prefix=report:prod&limit=2")
+	if len(entries) != 2 || entries[0].Key != reportKey("prod", "shop", "vulnerabilityreports", "a") {
+		t.Fatalf("unexpected first page %+v", entries)
+	}
+	if r := entries[0].Report; r == nil || r.Summary.Critical != 2 || r.Name != "a" || entries[0].Value != nil {
+		t.Errorf("report not reduced to its summary: %+v", entries[0])
+	}
+	if next != entries[1].Key {
+		t.Errorf("next cursor %q, want %q", next, entries[1].Key)
+	}
+
+	entries, next = dump("prefix=report:prod&limit=2&after=" + next)
+	if len(entries) != 1 || entries[0].Report.Name != "c" || next != "" {
+		t.Errorf("unexpected last page %+v, next %q", entries, next)
+	}
+
+	entries, _ = dump("prefix=detail:")
+	if len(entries) != 0 {
+		t.Errorf("details dumped: %+v", entries)
+	}
+
+	rec := httptest.NewRecorder()
+	h.DumpCache(rec, httptest.NewRequest("GET", "/api/v1/admin/cache/dump?limit=0", nil))
+	if rec.Code != 400 {
+		t.Errorf("limit=0: status %d", rec.Code)
+	}
+}
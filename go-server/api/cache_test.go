@@ -2,9 +2,47 @@ package api
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
 )
 
+// TestMain points DATA_PATH at a temporary directory for the whole package: InitCache
+// starts the trend recorder and periodic saves in the background, which outlive any one
+// test and would otherwise write trend-history.json and cache.json into the source tree.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "trivy-ui-api-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	config.Get().DataPath = dir
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// useTestCache installs a private cache as the global one for the rest of the test.
+func useTestCache(tb testing.TB) *Cache {
+	tb.Helper()
+	c, err := newCache(filepath.Join(tb.TempDir(), "cache.json"), cacheMaxCost)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	prev := globalCache
+	globalCache = c
+	tb.Cleanup(func() { globalCache = prev })
+	return c
+}
+
 func TestParseReportCacheKey_Valid(t *testing.T) {
 	key := "report:cluster1:default:vulnerabilityreports:my-report"
 	cluster, ns, rType, name, ok := parseReportCacheKey(key)
@@ -131,37 +169,6 @@ func TestHasVulnerabilitiesInReport_False(t *testing.T) {
 	}
 }
 
-func TestIncrementDecrementCount_Symmetry(t *testing.T) {
-	ResetReportCounts()
-	IncrementReportCount("c1", "ns", "vuln", true)
-	IncrementReportCount("c1", "ns", "vuln", true)
-	IncrementReportCount("c1", "ns", "vuln", false)
-
-	total, withVuln, found := GetReportCounts("c1", "vuln")
-	if !found || total != 3 || withVuln != 2 {
-		t.Fatalf("after increments: total=%d withVuln=%d found=%v", total, withVuln, found)
-	}
-
-	DecrementReportCount("c1", "ns", "vuln", true)
-	total, withVuln, _ = GetReportCounts("c1", "vuln")
-	if total != 2 || withVuln != 1 {
-		t.Fatalf("after decrement: total=%d withVuln=%d", total, withVuln)
-	}
-	ResetReportCounts()
-}
-
-func TestIncrementCount_NamespaceLevel(t *testing.T) {
-	ResetReportCounts()
-	IncrementReportCount("c1", "kube-system", "vuln", false)
-	IncrementReportCount("c1", "default", "vuln", true)
-
-	total, withVuln, found := GetReportCountsByNamespace("c1", "vuln", []string{"kube-system"})
-	if !found || total != 1 || withVuln != 0 {
-		t.Fatalf("namespace filter: total=%d withVuln=%d found=%v", total, withVuln, found)
-	}
-	ResetReportCounts()
-}
-
 func TestEvictQueryCacheForType(t *testing.T) {
 	queryResultCache.Store("vuln|c||foo|false|1|10|0", QueryResult{Total: 99})
 	queryResultCache.Store("config|c||bar|false|1|10|0", QueryResult{Total: 55})
@@ -197,11 +204,11 @@ func TestHashKey_Deterministic(t *testing.T) {
 func TestHashKey_Distinct(t *testing.T) {
 	c := &Cache{}
 	keys := []string{"a", "b", "report:c1:ns:vuln:r1", "report:c2:ns:vuln:r1"}
-	seen := make(map[uint64]string)
+	seen := make(map[cacheKeyHash]string)
 	for _, k := range keys {
 		h := c.hashKey(k)
 		if prev, ok := seen[h]; ok {
-			t.Fatalf("collision: %q and %q hash to %d", k, prev, h)
+			t.Fatalf("collision: %q and %q hash to %v", k, prev, h)
 		}
 		seen[h] = k
 	}
@@ -287,3 +294,266 @@ func TestGetReports_NamespaceFilterMatch(t *testing.T) {
 	c.Delete(reportKey("c", "ns-a", typ, "r1"))
 	c.Delete(reportKey("c", "ns-b", typ, "r2"))
 }
+
+func TestNamespaceFromCacheKey(t *testing.T) {
+	tests := map[string]string{
+		"report:c:ns:type:name": "ns",
+		"detail:c:ns:type:name": "ns",
+		"namespace:c:ns":        "ns",
+		"cluster:c":             "",
+	}
+	for key, want := range tests {
+		if got := namespaceFromCacheKey(key); got != want {
+			t.Errorf("namespaceFromCacheKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestClusterFromCacheKey(t *testing.T) {
+	cases := map[string]string{
+		"cluster:prod":                             "prod",
+		"namespace:prod:default":                   "prod",
+		"report:prod:ns:vulnerabilityreports:name": "prod",
+		"detail:prod:ns:vulnerabilityreports:name": "prod",
+		"report_type_version:vulnerabilityreports": "",
+	}
+	for key, want := range cases {
+		if got := clusterFromCacheKey(key); got != want {
+			t.Errorf("clusterFromCacheKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestFindReportKeys(t *testing.T) {
+	if err := InitCache(); err != nil {
+		t.Skipf("cannot init cache: %v", err)
+	}
+	c := GetCache()
+	typ := "findkeystype"
+	keys := []string{
+		reportKey("c1", "ns-a", typ, "app"),
+		reportKey("c2", "ns-b", typ, "app"),
+		reportKey("c1", "ns-a", typ, "other"),
+	}
+	for _, k := range keys {
+		c.Set(k, Report{Type: typ}, 0)
+	}
+	defer func() {
+		for _, k := range keys {
+			c.Delete(k)
+		}
+	}()
+
+	if got := c.FindReportKeys(typ, "", "", "app"); len(got) != 2 || got[0] != keys[0] || got[1] != keys[1] {
+		t.Fatalf("by name = %v", got)
+	}
+	if got := c.FindReportKeys(typ, "c2", "", "app"); len(got) != 1 || got[0] != keys[1] {
+		t.Fatalf("by cluster = %v", got)
+	}
+	if got := c.FindReportKeys(typ, "c1", "ns-a", "app"); len(got) != 1 || got[0] != keys[0] {
+		t.Fatalf("exact = %v", got)
+	}
+	if got := c.FindReportKeys(typ, "c1", "ns-b", "app"); len(got) != 0 {
+		t.Fatalf("wrong namespace = %v", got)
+	}
+
+	c.Delete(keys[0])
+	if got := c.FindReportKeys(typ, "", "", "app"); len(got) != 1 || got[0] != keys[1] {
+		t.Fatalf("after delete = %v", got)
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	if err := InitCache(); err != nil {
+		t.Skipf("cannot init cache: %v", err)
+	}
+	c := GetCache()
+	before := c.GetStats()
+
+	c.Set("statstest:a", "some value", 0)
+	defer c.Delete("statstest:a")
+	c.Get("statstest:a")
+	c.Get("statstest:missing")
+
+	stats := c.GetStats()
+	if n := stats["entries_by_prefix"].(map[string]int)["statstest"]; n != 1 {
+		t.Errorf("entries for prefix = %d, want 1", n)
+	}
+	if stats["memory_bytes"].(int64) <= before["memory_bytes"].(int64) {
+		t.Errorf("memory estimate did not grow: %v -> %v", before["memory_bytes"], stats["memory_bytes"])
+	}
+	if stats["hits"].(int64) <= before["hits"].(int64) || stats["misses"].(int64) <= before["misses"].(int64) {
+		t.Errorf("hit/miss counters not updated: %v", stats)
+	}
+
+	c.Delete("statstest:a")
+	if after := c.GetStats(); after["memory_bytes"] != before["memory_bytes"] {
+		t.Errorf("memory estimate not released: %v -> %v", before["memory_bytes"], after["memory_bytes"])
+	}
+}
+
+func TestEvictionStorm_KeepsBookkeepingConsistent(t *testing.T) {
+	c, err := newCache(filepath.Join(t.TempDir(), "cache.json"), 20_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := strings.Repeat("x", 500)
+	for i := 0; i < 2000; i++ {
+		c.Set(fmt.Sprintf("storm:%d", i), value, time.Minute)
+		if i%10 == 0 {
+			c.Set(reportKey("c", "ns", "stormtype", fmt.Sprintf("r%d", i)), Report{Name: fmt.Sprintf("r%d", i)}, 0)
+		}
+	}
+	c.cache.Wait()
+
+	if c.evictions.Load() == 0 {
+		t.Fatal("expected evictions with a tiny cache")
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for h, k := range c.keyMap {
+		if c.hashKey(k) != h {
+			t.Errorf("keyMap entry %q filed under a foreign hash", k)
+		}
+		if _, ok := c.items[k]; !ok {
+			t.Errorf("keyMap entry %q without item", k)
+		}
+	}
+	for i := 0; i < 2000; i += 10 {
+		k := reportKey("c", "ns", "stormtype", fmt.Sprintf("r%d", i))
+		if _, ok := c.items[k]; !ok {
+			t.Fatalf("report %q lost to eviction", k)
+		}
+		if !c.typeIndex["stormtype"][k] {
+			t.Fatalf("report %q dropped from the type index", k)
+		}
+	}
+	var bytes int64
+	for _, item := range c.items {
+		bytes += item.cost
+	}
+	if bytes != c.bytes {
+		t.Errorf("memory estimate %d, items sum to %d", c.bytes, bytes)
+	}
+}
+
+func TestSetDelete_KeepRollupsConsistent(t *testing.T) {
+	c, err := newCache(filepath.Join(t.TempDir(), "cache.json"), cacheMaxCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := reportKey("c", "ns", "racetype", "r")
+	for i := 0; i < 200; i++ {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.Set(key, makeReport("r", "c", "ns", "racetype", 1), 0)
+		}()
+		go func() {
+			defer wg.Done()
+			c.Delete(key)
+		}()
+		wg.Wait()
+
+		c.mu.RLock()
+		_, cached := c.items[key]
+		c.mu.RUnlock()
+		if rollup := c.GetReportRollup("racetype", "", nil); (rollup.Reports == 1) != cached {
+			t.Fatalf("round %d: report cached=%t but rolled up %d times", i, cached, rollup.Reports)
+		}
+	}
+}
+
+func TestOnEvict_IgnoresKeySetAgain(t *testing.T) {
+	c, err := newCache(filepath.Join(t.TempDir(), "cache.json"), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("live:a", "v", time.Minute)
+	c.cache.Wait()
+
+	// an eviction of the previous value arriving after the key was set again
+	h := c.hashKey("live:a")
+	c.onEvict(&ristretto.Item{Key: h.key, Conflict: h.conflict})
+	if v, ok := c.Get("live:a"); !ok || v != "v" {
+		t.Fatalf("live entry dropped by stale eviction: %v %v", v, ok)
+	}
+
+	c.cache.Del("live:a")
+	c.cache.Wait()
+	c.onEvict(&ristretto.Item{Key: h.key, Conflict: h.conflict})
+	c.mu.RLock()
+	_, inItems := c.items["live:a"]
+	_, inKeyMap := c.keyMap[h]
+	c.mu.RUnlock()
+	if inItems || inKeyMap {
+		t.Error("evicted entry kept")
+	}
+}
+
+func TestSet_DetectsHashCollision(t *testing.T) {
+	c, err := newCache(filepath.Join(t.TempDir(), "cache.json"), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// pretend another key already owns the hash of "b"
+	c.keyMap[c.hashKey("b")] = "a"
+	c.Set("b", "v", time.Minute)
+	if c.collisions.Load() != 1 {
+		t.Errorf("collisions = %d, want 1", c.collisions.Load())
+	}
+	if c.keyMap[c.hashKey("b")] != "b" {
+		t.Error("keyMap not pointing at the newest key")
+	}
+}
+
+func TestSaveIfChanged_SkipsUnchangedCache(t *testing.T) {
+	c := useTestCache(t)
+	if saved, err := c.SaveIfChanged(); err != nil || saved {
+		t.Fatalf("empty cache: saved=%v err=%v", saved, err)
+	}
+
+	c.Set("cluster:a", Cluster{Name: "a"}, time.Hour)
+	if saved, err := c.SaveIfChanged(); err != nil || !saved {
+		t.Fatalf("after Set: saved=%v err=%v", saved, err)
+	}
+	if saved, _ := c.SaveIfChanged(); saved {
+		t.Error("saved again without changes")
+	}
+
+	c.Delete("cluster:a")
+	if saved, _ := c.SaveIfChanged(); !saved {
+		t.Error("delete not saved")
+	}
+
+	c.Set("cluster:b", Cluster{Name: "b"}, time.Hour)
+	if err := c.SaveToFile(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := newCache(c.cacheFile, cacheMaxCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.LoadFromFile(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.items["cluster:b"]; !ok {
+		t.Error("saved item not loaded")
+	}
+	if saved, _ := loaded.SaveIfChanged(); saved {
+		t.Error("loaded cache saved without changes")
+	}
+}
+
+func TestSetReport_KeepsUIDAndResourceVersion(t *testing.T) {
+	c := useTestCache(t)
+	updater := NewCacheUpdater(NewClusterRegistry(&CacheServiceImpl{cache: c}))
+	updater.SetReport("prod", "web", "vulnerabilityreports", "replicaset-web", &kubernetes.Report{Status: "High", UID: "5f0c", ResourceVersion: "4711"})
+
+	value, _ := c.Get(reportKey("prod", "web", "vulnerabilityreports", "replicaset-web"))
+	report, _ := convertCacheValue[Report](value)
+	if report.UID != "5f0c" || report.ResourceVersion != "4711" {
+		t.Fatalf("uid = %q, resourceVersion = %q", report.UID, report.ResourceVersion)
+	}
+}
@@ -2,12 +2,17 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"trivy-ui/config"
 	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
 )
 
 var (
@@ -15,25 +20,48 @@ var (
 	registryOnce    sync.Once
 )
 
+// ErrDuplicateCluster is returned when a cluster is registered under a second name; the
+// name becomes an alias of the cluster registered first instead of counting it twice.
+var ErrDuplicateCluster = errors.New("cluster already registered under another name")
+
 type ClusterClient struct {
-	Name         string
+	Name string
+	// Source serves the cluster's reports: Client for watched clusters, a file source for
+	// clusters loaded from REPORT_DIRS, nil for pushed clusters
+	Source       kubernetes.ReportSource
 	Client       *kubernetes.Client
 	APIServerURL string
+	// UID is the kube-system namespace UID, empty when it could not be read
+	UID          string
+	// Aliases are the names of duplicate registrations merged into this cluster
+	Aliases      []string
 	Version      string
+	NodeCount    int
+	Platform     string
 	Namespaces   []string
 	SyncState    string
-	mu           sync.RWMutex
+	// Pushed clusters have no Client; an in-cluster agent pushes their reports
+	Pushed   bool
+	LastPush time.Time
+	mu       sync.RWMutex
 }
 
 type ClusterRegistry struct {
-	mu       sync.RWMutex
-	clients  map[string]*ClusterClient
+	mu      sync.RWMutex
+	clients map[string]*ClusterClient
+	// retired keeps the clients of removed clusters, so restoring one within this process
+	// can watch it again
+	retired  map[string]*ClusterClient
+	// aliases maps the names of duplicate registrations to the cluster they reach
+	aliases  map[string]string
 	cacheSvc CacheService
 }
 
 func NewClusterRegistry(cacheSvc CacheService) *ClusterRegistry {
 	return &ClusterRegistry{
 		clients:  make(map[string]*ClusterClient),
+		retired:  make(map[string]*ClusterClient),
+		aliases:  make(map[string]string),
 		cacheSvc: cacheSvc,
 	}
 }
@@ -64,10 +92,14 @@ func SetClusterClient(clusterName string, client *kubernetes.Client) error {
 	return GetDefaultRegistry().Set(clusterName, client)
 }
 
+// Get returns a registered cluster by name or alias.
 func (r *ClusterRegistry) Get(clusterName string) *ClusterClient {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.clients[clusterName]
+	if cc, ok := r.clients[clusterName]; ok {
+		return cc
+	}
+	return r.clients[r.aliases[clusterName]]
 }
 
 func (r *ClusterRegistry) All() map[string]*ClusterClient {
@@ -89,8 +121,15 @@ func (r *ClusterRegistry) Set(clusterName string, client *kubernetes.Client) err
 	if versionInfo, err := client.Clientset().Discovery().ServerVersion(); err == nil {
 		version = versionInfo.GitVersion
 	}
-	
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	uid, err := client.GetClusterUID(ctx)
+	cancel()
+	if err != nil {
+		utils.LogModuleDebug(utils.ModuleInformer, "Failed to read cluster UID", map[string]interface{}{"cluster": clusterName, "error": err.Error()})
+	}
+	
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
 	namespaces, err := client.GetNamespaces(ctx)
 	cancel()
 	
@@ -98,22 +137,46 @@ func (r *ClusterRegistry) Set(clusterName string, client *kubernetes.Client) err
 		namespaces = r.recoverNamespaces(clusterName)
 	}
 
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	info, err := client.GetClusterInfo(ctx, version)
+	cancel()
+	if err != nil {
+		utils.LogWarning("Failed to read cluster nodes", map[string]interface{}{"cluster": clusterName, "error": err.Error()})
+		// keep what the last successful registration saw
+		if cached, ok := r.cachedCluster(clusterName); ok {
+			info.NodeCount = cached.NodeCount
+			if info.Platform == "" {
+				info.Platform = cached.Platform
+			}
+		}
+	}
+
 	r.mu.Lock()
+	if dup := r.duplicateLocked(clusterName, apiServerURL, uid); dup != nil {
+		r.aliases[clusterName] = dup.Name
+		r.mu.Unlock()
+		return r.mergeDuplicate(dup, clusterName)
+	}
+	delete(r.aliases, clusterName)
 	r.clients[clusterName] = &ClusterClient{
 		Name:         clusterName,
+		Source:       client,
 		Client:       client,
 		APIServerURL: apiServerURL,
+		UID:          uid,
 		Version:      version,
+		NodeCount:    info.NodeCount,
+		Platform:     info.Platform,
 		Namespaces:   namespaces,
 	}
+	cc := r.clients[clusterName]
 	r.mu.Unlock()
+	client.OnConnectivityChange(clusterName, func(t kubernetes.ConnectivityTransition) {
+		publishConnectivityChange(clusterName, t)
+	})
 
-	clusterInfo := Cluster{
-		Name:        clusterName,
-		Description: fmt.Sprintf("API Server: %s, version: %s", apiServerURL, version),
-	}
 	if r.cacheSvc != nil {
-		r.cacheSvc.Set(clusterKey(clusterName), clusterInfo, 0)
+		r.cacheSvc.Set(clusterKey(clusterName), cc.info(), 0)
 		for _, ns := range namespaces {
 			nsObj := Namespace{Cluster: clusterName, Name: ns}
 			r.cacheSvc.Set(namespaceKey(clusterName, ns), nsObj, 0)
@@ -123,6 +186,252 @@ func (r *ClusterRegistry) Set(clusterName string, client *kubernetes.Client) err
 	return nil
 }
 
+// duplicateLocked returns the cluster registered under another name that the same API
+// server and UID reach. r.mu must be held.
+func (r *ClusterRegistry) duplicateLocked(clusterName, apiServerURL, uid string) *ClusterClient {
+	for name, cc := range r.clients {
+		if name != clusterName && sameCluster(cc.APIServerURL, cc.UID, apiServerURL, uid) {
+			return cc
+		}
+	}
+	return nil
+}
+
+// sameCluster compares two registrations by their kube-system UID when both are known and
+// by their normalized API server URL otherwise, so a cluster reached through two different
+// addresses is still recognised and two clusters behind the same tunnel address are not.
+func sameCluster(urlA, uidA, urlB, uidB string) bool {
+	if uidA != "" && uidB != "" {
+		return uidA == uidB
+	}
+	a := normalizeServerURL(urlA)
+	return a != "" && a == normalizeServerURL(urlB)
+}
+
+// normalizeServerURL lowercases an API server URL and drops its default port and trailing
+// slash.
+func normalizeServerURL(raw string) string {
+	raw = strings.TrimRight(strings.ToLower(strings.TrimSpace(raw)), "/")
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	host := u.Hostname()
+	if port := u.Port(); port != "" && !(u.Scheme == "https" && port == "443") && !(u.Scheme == "http" && port == "80") {
+		host += ":" + port
+	}
+	return u.Scheme + "://" + host + u.Path
+}
+
+// mergeDuplicate records clusterName as an alias of dup and drops what an earlier run cached
+// under the alias, so reports are not counted once per name.
+func (r *ClusterRegistry) mergeDuplicate(dup *ClusterClient, clusterName string) error {
+	dup.mu.Lock()
+	if !slices.Contains(dup.Aliases, clusterName) {
+		dup.Aliases = append(dup.Aliases, clusterName)
+	}
+	dup.mu.Unlock()
+	utils.LogWarning("Cluster is already registered under another name, merged as an alias", map[string]interface{}{"cluster": clusterName, "registeredAs": dup.Name})
+
+	if r.cacheSvc != nil {
+		r.cacheSvc.Set(clusterKey(dup.Name), dup.info(), 0)
+		for k := range r.cacheSvc.Items() {
+			if clusterFromCacheKey(k) == clusterName {
+				r.cacheSvc.Delete(k)
+			}
+		}
+	}
+	aggregates.invalidate(clusterName)
+	return fmt.Errorf("%w: %q is %q", ErrDuplicateCluster, clusterName, dup.Name)
+}
+
+// SetSource registers a cluster served by a report source other than the Kubernetes API,
+// such as report files. It has no Client, so Kubernetes-only features skip it.
+func (r *ClusterRegistry) SetSource(clusterName string, src kubernetes.ReportSource) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	namespaces, err := src.GetNamespaces(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	cc := &ClusterClient{Name: clusterName, Source: src, Namespaces: namespaces}
+	r.mu.Lock()
+	r.clients[clusterName] = cc
+	r.mu.Unlock()
+
+	if r.cacheSvc != nil {
+		r.cacheSvc.Set(clusterKey(clusterName), cc.info(), 0)
+		for _, ns := range namespaces {
+			r.cacheSvc.Set(namespaceKey(clusterName, ns), Namespace{Cluster: clusterName, Name: ns}, 0)
+		}
+	}
+	return nil
+}
+
+// LoadReportDirs registers a cluster per REPORT_DIRS entry, served from the report files
+// in its directory.
+func LoadReportDirs(reg *ClusterRegistry, crdReg *config.CRDRegistry, dirs map[string]string) {
+	for name, dir := range dirs {
+		if reg.Get(name) != nil {
+			utils.LogWarning("Cluster name already registered, report directory ignored", map[string]interface{}{"cluster": name, "dir": dir})
+			continue
+		}
+		src, kinds, err := kubernetes.LoadReportFiles(dir)
+		if err != nil {
+			utils.LogWarning("Failed to load report files", map[string]interface{}{"cluster": name, "dir": dir, "error": err.Error()})
+			continue
+		}
+		crdReg.Register(kinds...)
+		if err := reg.SetSource(name, src); err != nil {
+			utils.LogWarning("Failed to register report directory", map[string]interface{}{"cluster": name, "error": err.Error()})
+			continue
+		}
+		if err := src.Watch(name, NewCacheUpdater(reg)); err != nil {
+			utils.LogWarning("Failed to load reports", map[string]interface{}{"cluster": name, "error": err.Error()})
+		}
+		utils.LogInfo("Loaded report files", map[string]interface{}{"cluster": name, "dir": dir, "kinds": len(kinds)})
+	}
+}
+
+// RegisterPushed records a cluster whose reports arrive from a push agent, creating it
+// on the first batch and refreshing its version, namespaces and last push time afterwards.
+func (r *ClusterRegistry) RegisterPushed(clusterName, version string, namespaces []string) *ClusterClient {
+	r.mu.Lock()
+	cc, ok := r.clients[clusterName]
+	if !ok {
+		cc = &ClusterClient{Name: clusterName, Pushed: true}
+		r.clients[clusterName] = cc
+	}
+	r.mu.Unlock()
+
+	cc.mu.Lock()
+	cc.LastPush = time.Now()
+	if version != "" {
+		cc.Version = version
+	}
+	if len(namespaces) > 0 {
+		cc.Namespaces = namespaces
+	}
+	cc.mu.Unlock()
+
+	if !ok && r.cacheSvc != nil {
+		r.cacheSvc.Set(clusterKey(clusterName), cc.info(), 0)
+	}
+	if r.cacheSvc != nil {
+		for _, ns := range namespaces {
+			r.cacheSvc.Set(namespaceKey(clusterName, ns), Namespace{Cluster: clusterName, Name: ns}, 0)
+		}
+	}
+	return cc
+}
+
+// Remove drops a cluster, stopping its informer and purging its cached cluster,
+// namespace and report entries. Within CLUSTER_RETENTION they can be restored, see
+// Restore.
+func (r *ClusterRegistry) Remove(clusterName string) bool {
+	r.mu.Lock()
+	cc, ok := r.clients[clusterName]
+	delete(r.clients, clusterName)
+	for alias, name := range r.aliases {
+		if name == clusterName {
+			delete(r.aliases, alias)
+		}
+	}
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	if cc.Source != nil {
+		cc.Source.StopWatch()
+	}
+	r.retire(cc, config.Get().ClusterRetention)
+	if r.cacheSvc != nil {
+		for k := range r.cacheSvc.Items() {
+			if clusterFromCacheKey(k) == clusterName {
+				r.cacheSvc.Delete(k)
+			}
+		}
+	}
+	aggregates.invalidate(clusterName)
+	changeEvents.forget(clusterName)
+	clusterWorkloads.forget(clusterName)
+	return true
+}
+
+// SecretClusterHandler registers clusters discovered from kubeconfig Secrets; names
+// already taken by kubeconfig files or push agents are refused.
+type SecretClusterHandler struct {
+	reg *ClusterRegistry
+}
+
+func NewSecretClusterHandler(reg *ClusterRegistry) *SecretClusterHandler {
+	return &SecretClusterHandler{reg: reg}
+}
+
+func (h *SecretClusterHandler) AddCluster(name string, client *kubernetes.Client) error {
+	if h.reg.Get(name) != nil {
+		return fmt.Errorf("cluster %q is already registered", name)
+	}
+	if err := h.reg.Set(name, client); err != nil {
+		return err
+	}
+	if err := client.StartInformer(name, NewCacheUpdater(h.reg)); err != nil {
+		utils.LogWarning("Failed to start informer", map[string]interface{}{"cluster": name, "error": err.Error()})
+	}
+	return nil
+}
+
+func (h *SecretClusterHandler) RemoveCluster(name string) {
+	h.reg.Remove(name)
+}
+
+// info builds the cluster's API object.
+func (cc *ClusterClient) info() Cluster {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+	c := Cluster{
+		Name:              cc.Name,
+		SyncState:         cc.SyncState,
+		APIServerURL:      cc.APIServerURL,
+		UID:               cc.UID,
+		Aliases:           slices.Clone(cc.Aliases),
+		KubernetesVersion: cc.Version,
+		NodeCount:         cc.NodeCount,
+		Platform:          cc.Platform,
+		Pushed:            cc.Pushed,
+	}
+	if cc.Client != nil {
+		auth := cc.Client.AuthHealth()
+		c.Auth = &auth
+		conn := cc.Client.Connectivity()
+		c.Connectivity = conn.State
+		if conn.State == kubernetes.ConnectivityUnreachable || conn.State == kubernetes.ConnectivityUnauthorized {
+			c.StaleSince = staleSince(conn)
+		}
+	}
+	if cc.Pushed {
+		c.Description = fmt.Sprintf("Agent push, version: %s", cc.Version)
+	} else {
+		c.Description = fmt.Sprintf("API Server: %s, version: %s", cc.APIServerURL, cc.Version)
+	}
+	return c
+}
+
+// cachedCluster returns the cluster object cached by an earlier registration, which
+// survives restarts in the persisted cache.
+func (r *ClusterRegistry) cachedCluster(clusterName string) (Cluster, bool) {
+	if r.cacheSvc == nil {
+		return Cluster{}, false
+	}
+	v, ok := r.cacheSvc.Get(clusterKey(clusterName))
+	if !ok {
+		return Cluster{}, false
+	}
+	return convertCacheValue[Cluster](v)
+}
+
 func (r *ClusterRegistry) recoverNamespaces(clusterName string) []string {
 	if r.cacheSvc == nil {
 		return nil
@@ -182,8 +491,19 @@ func (r *ClusterRegistry) recoverNamespaces(clusterName string) []string {
 	return namespaces
 }
 
+// removeNamespace drops a deleted namespace from the cluster's list.
+func (cc *ClusterClient) removeNamespace(namespace string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.Namespaces = slices.DeleteFunc(slices.Clone(cc.Namespaces), func(ns string) bool { return ns == namespace })
+}
+
 func (cc *ClusterClient) RefreshNamespaces(ctx context.Context) error {
-	namespaces, err := cc.Client.GetNamespaces(ctx)
+	if cc.Source == nil {
+		// pushed clusters report their namespaces with every batch
+		return nil
+	}
+	namespaces, err := cc.Source.GetNamespaces(ctx)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+func TestClusterInfo(t *testing.T) {
+	cc := &ClusterClient{
+		Name:         "prod",
+		APIServerURL: "https://prod.example:6443",
+		Version:      "v1.30.2-eks-1552ad0",
+		NodeCount:    12,
+		Platform:     "EKS",
+	}
+	info := cc.info()
+	if info.APIServerURL != cc.APIServerURL || info.KubernetesVersion != cc.Version || info.NodeCount != 12 || info.Platform != "EKS" || info.Pushed {
+		t.Fatalf("unexpected cluster %+v", info)
+	}
+	if info.Description != "API Server: https://prod.example:6443, version: v1.30.2-eks-1552ad0" {
+		t.Errorf("description = %q", info.Description)
+	}
+}
+
+func TestRegisterPushedCachesClusterFields(t *testing.T) {
+	cache := &mapCacheService{items: map[string]interface{}{}}
+	reg := NewClusterRegistry(cache)
+	reg.RegisterPushed("edge", "v1.29.0", []string{"default"})
+
+	cached, ok := reg.cachedCluster("edge")
+	if !ok {
+		t.Fatal("cluster not cached")
+	}
+	if !cached.Pushed || cached.KubernetesVersion != "v1.29.0" || cached.APIServerURL != "" {
+		t.Errorf("unexpected cached cluster %+v", cached)
+	}
+}
+
+func TestReportSourceServesClusterData(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	kind := config.ReportKind{Name: "vulnerabilityreports", Kind: "VulnerabilityReport", APIVersion: "aquasecurity.github.io/v1alpha1", Namespaced: true}
+	crdReg := config.GetGlobalRegistry()
+	crdReg.Register(kind)
+
+	src := kubernetes.NewMemorySource()
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"report": map[string]interface{}{"summary": map[string]interface{}{"criticalCount": float64(1)}},
+	}}
+	obj.SetNamespace("web")
+	obj.SetName("replicaset-web")
+	src.Put(kind, obj)
+	if err := reg.SetSource("lab", src); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), crdReg)
+
+	rec := httptest.NewRecorder()
+	h.GetReportDetails(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reports/detail?type=vulnerabilityreports&cluster=lab&namespace=web&name=replicaset-web", nil))
+	var resp struct {
+		Data Report `json:"data"`
+	}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &resp) != nil || resp.Data.Status != "Critical" {
+		t.Fatalf("detail: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetNamespacesByCluster(rec, httptest.NewRequest(http.MethodGet, "/api/clusters/lab/namespaces?refresh=1", nil), "lab")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"name":"web"`) {
+		t.Fatalf("namespaces: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestReportDetailsAmbiguousName(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	kind := config.ReportKind{Name: "vulnerabilityreports", Kind: "VulnerabilityReport", APIVersion: "aquasecurity.github.io/v1alpha1", Namespaced: true}
+	crdReg := config.GetGlobalRegistry()
+	crdReg.Register(kind)
+
+	src := kubernetes.NewMemorySource()
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetNamespace("web")
+	obj.SetName("replicaset-web")
+	src.Put(kind, obj)
+	if err := reg.SetSource("lab", src); err != nil {
+		t.Fatal(err)
+	}
+	for _, cluster := range []string{"lab", "prod"} {
+		c.Set(reportKey(cluster, "web", kind.Name, "replicaset-web"), makeReport("replicaset-web", cluster, "web", kind.Name, 1), 0)
+	}
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), crdReg)
+
+	rec := httptest.NewRecorder()
+	h.GetReportDetailsV1(rec, httptest.NewRequest(http.MethodGet, "/api/v1/type/vulnerabilityreports/replicaset-web", nil), kind.Name, "replicaset-web")
+	var conflict struct {
+		Data []ReportCandidate `json:"data"`
+	}
+	if rec.Code != http.StatusConflict || json.Unmarshal(rec.Body.Bytes(), &conflict) != nil || len(conflict.Data) != 2 {
+		t.Fatalf("ambiguous: %d %s", rec.Code, rec.Body.String())
+	}
+	if got := conflict.Data[0].URL; got != "/api/v1/reports/lab/vulnerabilityreports/web/replicaset-web" {
+		t.Errorf("candidate url = %s", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetReportDetailsV1(rec, httptest.NewRequest(http.MethodGet, "/api/v1/type/vulnerabilityreports/replicaset-web?cluster=lab", nil), kind.Name, "replicaset-web")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Location") != conflict.Data[0].URL {
+		t.Fatalf("narrowed: %d %v %s", rec.Code, rec.Header(), rec.Body.String())
+	}
+
+	// kind and short names resolve to the same report
+	for _, alias := range []string{"VulnerabilityReport", "vuln", "VulnerabilityReports"} {
+		rec = httptest.NewRecorder()
+		h.GetReportDetailsV1(rec, httptest.NewRequest(http.MethodGet, "/api/v1/type/"+alias+"/replicaset-web?cluster=lab", nil), alias, "replicaset-web")
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Location") != conflict.Data[0].URL {
+			t.Errorf("%s: %d %s", alias, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestRemoveAndRestoreCluster(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	kind := config.ReportKind{Name: "vulnerabilityreports", Kind: "VulnerabilityReport", APIVersion: "aquasecurity.github.io/v1alpha1", Namespaced: true}
+	config.GetGlobalRegistry().Register(kind)
+
+	src := kubernetes.NewMemorySource()
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetNamespace("web")
+	obj.SetName("replicaset-web")
+	src.Put(kind, obj)
+	if err := reg.SetSource("lab", src); err != nil {
+		t.Fatal(err)
+	}
+	key := reportKey("lab", "web", kind.Name, "replicaset-web")
+	c.Set(key, makeReport("replicaset-web", "lab", "web", kind.Name, 1), 0)
+
+	reg.Remove("lab")
+	if _, ok := c.Get(key); ok {
+		t.Fatal("report of a removed cluster still listed")
+	}
+	retired := reg.Retired()
+	if len(retired) != 1 || len(retired[0].Reports) != 1 || retired[0].Cluster.Name != "lab" {
+		t.Fatalf("retired = %+v", retired)
+	}
+
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), config.GetGlobalRegistry())
+	rec := httptest.NewRecorder()
+	h.GetClusters(rec, httptest.NewRequest(http.MethodGet, "/api/clusters?includeInactive=true", nil))
+	if !strings.Contains(rec.Body.String(), `"inactive":true`) {
+		t.Fatalf("clusters: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.RestoreCluster(rec, httptest.NewRequest(http.MethodPost, "/api/v1/clusters/lab/restore", nil), "lab")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"watching":true`) {
+		t.Fatalf("restore: %d %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := c.Get(key); !ok || reg.Get("lab") == nil || len(reg.Retired()) != 0 {
+		t.Fatal("cluster not restored")
+	}
+
+	rec = httptest.NewRecorder()
+	h.RestoreCluster(rec, httptest.NewRequest(http.MethodPost, "/api/v1/clusters/lab/restore", nil), "lab")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("restoring a registered cluster: %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.RestoreCluster(rec, httptest.NewRequest(http.MethodPost, "/api/v1/clusters/gone/restore", nil), "gone")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("restoring an unknown cluster: %d", rec.Code)
+	}
+}
+
+func TestDuplicateClusterMergedAsAlias(t *testing.T) {
+	for _, tc := range []struct {
+		urlA, uidA, urlB, uidB string
+		same                   bool
+	}{
+		{"https://k8s.example:443", "", "https://K8S.example/", "", true},
+		{"https://k8s.example:6443", "", "https://k8s.example", "", false},
+		// the UID wins over the address when both are known
+		{"https://127.0.0.1:6443", "a", "https://127.0.0.1:6443", "b", false},
+		{"https://10.0.0.1", "a", "https://prod.example", "a", true},
+		{"", "", "", "", false},
+	} {
+		if got := sameCluster(tc.urlA, tc.uidA, tc.urlB, tc.uidB); got != tc.same {
+			t.Errorf("sameCluster(%q, %q, %q, %q) = %v", tc.urlA, tc.uidA, tc.urlB, tc.uidB, got)
+		}
+	}
+
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	reg.clients["prod"] = &ClusterClient{Name: "prod", APIServerURL: "https://prod.example", UID: "uid-1"}
+	// reports cached under the second name by an earlier run
+	aliasKey := reportKey("prod-admin", "web", "vulnerabilityreports", "replicaset-web")
+	c.Set(aliasKey, makeReport("replicaset-web", "prod-admin", "web", "vulnerabilityreports", 1), 0)
+
+	if reg.duplicateLocked("prod", "https://prod.example", "uid-1") != nil {
+		t.Fatal("re-registering a cluster under its own name is not a duplicate")
+	}
+	dup := reg.duplicateLocked("prod-admin", "https://prod.example:443", "uid-1")
+	if dup == nil {
+		t.Fatal("duplicate not detected")
+	}
+	reg.aliases["prod-admin"] = dup.Name
+	if err := reg.mergeDuplicate(dup, "prod-admin"); !errors.Is(err, ErrDuplicateCluster) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, ok := c.Get(aliasKey); ok {
+		t.Error("reports cached under the alias kept")
+	}
+	if reg.Get("prod-admin") != dup || len(reg.All()) != 1 {
+		t.Error("alias does not resolve to the registered cluster")
+	}
+	if info, ok := reg.cachedCluster("prod"); !ok || len(info.Aliases) != 1 || info.Aliases[0] != "prod-admin" || info.UID != "uid-1" {
+		t.Errorf("cached cluster = %+v", info)
+	}
+
+	reg.Remove("prod")
+	if reg.Get("prod-admin") != nil {
+		t.Error("alias kept after removing its cluster")
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"trivy-ui/kubernetes"
+)
+
+// ClusterStats are a cluster's report counts from the fleet totals, so listing clusters
+// never scans their reports.
+type ClusterStats struct {
+	Reports           int            `json:"reports"`
+	VulnerableReports int            `json:"vulnerableReports"`
+	Severity          SeverityTotals `json:"severity"`
+	// WorstSeverity is the highest severity with a finding, None without any
+	WorstSeverity string `json:"worstSeverity"`
+	RiskScore     int    `json:"riskScore"`
+	// Kinds counts the reports of each kind
+	Kinds map[string]int `json:"kinds,omitempty"`
+}
+
+// clusterStats returns the stats of one cluster; clusters without reports have zero
+// stats.
+func (f *fleetAggregator) clusterStats(cluster string) *ClusterStats {
+	stats := &ClusterStats{WorstSeverity: kubernetes.StatusNone}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	totals := f.clusters[cluster]
+	if totals == nil {
+		return stats
+	}
+	stats.Reports = totals.Reports
+	stats.VulnerableReports = totals.VulnerableReports
+	stats.Severity = totals.Severity
+	stats.RiskScore = riskScore(totals.Severity)
+	stats.WorstSeverity = worstSeverity(totals.Severity)
+	stats.Kinds = make(map[string]int, len(totals.kinds))
+	for name, kind := range totals.kinds {
+		stats.Kinds[name] = kind.Reports
+	}
+	return stats
+}
+
+func worstSeverity(s SeverityTotals) string {
+	switch {
+	case s.Critical > 0:
+		return "Critical"
+	case s.High > 0:
+		return "High"
+	case s.Medium > 0:
+		return "Medium"
+	case s.Low > 0:
+		return "Low"
+	}
+	return kubernetes.StatusNone
+}
+
+func withClusterStats(clusters []Cluster) {
+	for i := range clusters {
+		clusters[i].Stats = fleet.clusterStats(clusters[i].Name)
+	}
+}
+
+// clusterListParams are the parameters that turn GET /api/clusters into a page; without
+// any of them the full list is returned as a plain array, as older clients expect.
+var clusterListParams = []string{"search", "tag", "sort", "page", "pageSize"}
+
+func isClusterListQuery(r *http.Request) bool {
+	q := r.URL.Query()
+	for _, p := range clusterListParams {
+		if q.Has(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidClusterSort accepts "name", "severity", "reports" or "riskScore", optionally
+// prefixed with "-" for descending order.
+func IsValidClusterSort(sortBy string) bool {
+	switch strings.TrimPrefix(sortBy, "-") {
+	case "", "name", "severity", "reports", "riskScore":
+		return true
+	}
+	return false
+}
+
+// matchesClusterSearch matches the search term, case-insensitively, against a cluster's
+// name, aliases, API server URL, platform and tag values.
+func matchesClusterSearch(c Cluster, term string) bool {
+	fields := append([]string{c.Name, c.APIServerURL, c.Platform}, c.Aliases...)
+	for _, v := range c.Tags {
+		fields = append(fields, v)
+	}
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortClusters sorts clusters by a field, by name when it is empty. Severity compares
+// critical findings first, then high, medium and low; ascending puts the least affected
+// clusters first. Ties are broken by name.
+func sortClusters(clusters []Cluster, sortBy string) {
+	field := strings.TrimPrefix(sortBy, "-")
+	desc := strings.HasPrefix(sortBy, "-")
+	statsOf := func(c Cluster) ClusterStats {
+		if c.Stats == nil {
+			return ClusterStats{}
+		}
+		return *c.Stats
+	}
+	compare := func(a, b Cluster) int {
+		sa, sb := statsOf(a), statsOf(b)
+		switch field {
+		case "severity":
+			for _, pair := range [][2]int{
+				{sa.Severity.Critical, sb.Severity.Critical},
+				{sa.Severity.High, sb.Severity.High},
+				{sa.Severity.Medium, sb.Severity.Medium},
+				{sa.Severity.Low, sb.Severity.Low},
+			} {
+				if pair[0] != pair[1] {
+					return pair[0] - pair[1]
+				}
+			}
+		case "reports":
+			return sa.Reports - sb.Reports
+		case "riskScore":
+			return sa.RiskScore - sb.RiskScore
+		}
+		return 0
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if c := compare(clusters[i], clusters[j]); c != 0 {
+			if desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		if field == "name" && desc {
+			return clusters[i].Name > clusters[j].Name
+		}
+		return clusters[i].Name < clusters[j].Name
+	})
+}
+
+// clusterPage narrows clusters to those matching the request's search and tag filters,
+// sorts them and returns the requested page. pageSize defaults to 50, at most 200.
+func clusterPage(r *http.Request, clusters []Cluster) PaginatedResponse {
+	q := r.URL.Query()
+	search := strings.TrimSpace(q.Get("search"))
+	tags := parseTagFilters(q["tag"])
+	matched := make([]Cluster, 0, len(clusters))
+	for _, c := range clusters {
+		if search != "" && !matchesClusterSearch(c, strings.ToLower(search)) {
+			continue
+		}
+		if len(tags) > 0 && len(taggedClusters(map[string]map[string]string{c.Name: c.Tags}, tags)) == 0 {
+			continue
+		}
+		matched = append(matched, c)
+	}
+	sortBy := q.Get("sort")
+	sortClusters(matched, sortBy)
+
+	page, pageSize := 1, 50
+	if parsed, err := strconv.Atoi(q.Get("page")); err == nil && parsed > 0 {
+		page = parsed
+	}
+	if parsed, err := strconv.Atoi(q.Get("pageSize")); err == nil && parsed > 0 && parsed <= 200 {
+		pageSize = parsed
+	}
+	totalPages := (len(matched) + pageSize - 1) / pageSize
+	start := min((page-1)*pageSize, len(matched))
+	end := min(start+pageSize, len(matched))
+
+	var tagParams []string
+	for _, t := range q["tag"] {
+		if t = strings.TrimSpace(t); t != "" {
+			tagParams = append(tagParams, t)
+		}
+	}
+	return PaginatedResponse{
+		Total:      len(matched),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+		Sort:       sortBy,
+		Filters:    AppliedFilters{Search: search, Tags: tagParams},
+		Data:       matched[start:end],
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trivy-ui/config"
+)
+
+func TestGetClustersPage(t *testing.T) {
+	svc := &CacheServiceImpl{cache: useTestCache(t)}
+	prevFleet := fleet
+	fleet = newFleetAggregator()
+	cfg := config.Get()
+	prevTags := cfg.ClusterTags
+	cfg.ClusterTags = map[string]map[string]string{"prod-eu": {"tier": "prod"}, "prod-us": {"tier": "prod"}, "dev": {"tier": "dev"}}
+	t.Cleanup(func() {
+		fleet = prevFleet
+		cfg.ClusterTags = prevTags
+	})
+
+	reg := NewClusterRegistry(svc)
+	for _, name := range []string{"prod-eu", "prod-us", "dev"} {
+		reg.RegisterPushed(name, "v1.30.2", []string{"default"})
+	}
+	fleet.set(reportKey("prod-eu", "default", "vulnerabilityreports", "a"), makeReport("a", "prod-eu", "default", "vulnerabilityreports", 1))
+	fleet.set(reportKey("prod-us", "default", "vulnerabilityreports", "a"), makeReport("a", "prod-us", "default", "vulnerabilityreports", 4))
+	fleet.set(reportKey("prod-us", "default", "configauditreports", "b"), makeReport("b", "prod-us", "default", "configauditreports", 0))
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), config.GetGlobalRegistry())
+
+	list := func(query string) (int, PaginatedResponse, []Cluster) {
+		rec := httptest.NewRecorder()
+		h.GetClusters(rec, httptest.NewRequest(http.MethodGet, "/api/clusters?"+query, nil))
+		var resp struct {
+			Data json.RawMessage `json:"data"`
+		}
+		json.NewDecoder(rec.Body).Decode(&resp)
+		var page PaginatedResponse
+		var clusters []Cluster
+		if query == "" {
+			json.Unmarshal(resp.Data, &clusters)
+		} else {
+			json.Unmarshal(resp.Data, &page)
+			raw, _ := json.Marshal(page.Data)
+			json.Unmarshal(raw, &clusters)
+		}
+		return rec.Code, page, clusters
+	}
+
+	_, _, clusters := list("")
+	if len(clusters) != 3 {
+		t.Fatalf("expected the plain list without paging parameters, got %+v", clusters)
+	}
+	for _, c := range clusters {
+		if c.Stats == nil {
+			t.Fatalf("expected stats on %s", c.Name)
+		}
+		if c.Name == "prod-us" && (c.Stats.Reports != 2 || c.Stats.VulnerableReports != 1 || c.Stats.WorstSeverity != "Critical" || c.Stats.Kinds["configauditreports"] != 1) {
+			t.Fatalf("unexpected stats %+v", c.Stats)
+		}
+		if c.Name == "dev" && (c.Stats.Reports != 0 || c.Stats.WorstSeverity != "None") {
+			t.Fatalf("unexpected stats of a cluster without reports %+v", c.Stats)
+		}
+	}
+
+	_, page, clusters := list("sort=-severity&pageSize=2")
+	if page.Total != 3 || page.TotalPages != 2 || !page.HasNext || len(clusters) != 2 || clusters[0].Name != "prod-us" || clusters[1].Name != "prod-eu" {
+		t.Fatalf("expected the worst clusters first, got %+v %+v", page, clusters)
+	}
+	_, page, clusters = list("sort=-severity&pageSize=2&page=2")
+	if len(clusters) != 1 || clusters[0].Name != "dev" || page.HasNext || !page.HasPrev {
+		t.Fatalf("unexpected second page %+v %+v", page, clusters)
+	}
+
+	_, page, clusters = list("tag=tier:prod&search=US")
+	if page.Total != 1 || clusters[0].Name != "prod-us" || page.Filters.Search != "US" || len(page.Filters.Tags) != 1 {
+		t.Fatalf("expected the search and tag filters combined, got %+v %+v", page, clusters)
+	}
+	_, _, clusters = list("sort=-name")
+	if clusters[0].Name != "prod-us" || clusters[2].Name != "dev" {
+		t.Fatalf("expected names in descending order, got %+v", clusters)
+	}
+	if code, _, _ := list("sort=nodes"); code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown sort to be rejected, got %d", code)
+	}
+}
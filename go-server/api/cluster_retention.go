@@ -0,0 +1,172 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"trivy-ui/utils"
+)
+
+// RetiredCluster is what is kept of a removed cluster until its retention ends: the
+// cluster as last listed and its cached reports.
+type RetiredCluster struct {
+	Cluster    Cluster   `json:"cluster"`
+	RemovedAt  time.Time `json:"removedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Namespaces []string  `json:"namespaces,omitempty"`
+	Reports    []Report  `json:"reports,omitempty"`
+}
+
+var (
+	errClusterNotRetired = errors.New("cluster was not removed or its retention has ended")
+	errClusterActive     = errors.New("cluster is registered")
+)
+
+func retiredClusterKey(name string) string {
+	return "retired:" + name
+}
+
+// retire keeps the cached entries of a cluster being removed for the retention period,
+// so an accidentally deleted registration does not lose them.
+func (r *ClusterRegistry) retire(cc *ClusterClient, retention time.Duration) {
+	if retention <= 0 || r.cacheSvc == nil {
+		return
+	}
+	now := time.Now()
+	retired := RetiredCluster{Cluster: cc.info(), RemovedAt: now, ExpiresAt: now.Add(retention)}
+	if cached, ok := r.cachedCluster(cc.Name); ok {
+		retired.Cluster = cached
+	}
+	for k, v := range r.cacheSvc.Items() {
+		if clusterFromCacheKey(k) != cc.Name {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(k, "report:"):
+			if report, ok := convertCacheValue[Report](v); ok {
+				retired.Reports = append(retired.Reports, report)
+			}
+		case strings.HasPrefix(k, "namespace:"):
+			retired.Namespaces = append(retired.Namespaces, namespaceFromCacheKey(k))
+		}
+	}
+	sort.Strings(retired.Namespaces)
+	r.cacheSvc.Set(retiredClusterKey(cc.Name), retired, retention)
+
+	r.mu.Lock()
+	r.retired[cc.Name] = cc
+	r.mu.Unlock()
+	utils.LogInfo("Cluster removed, keeping its reports for restore", map[string]interface{}{
+		"cluster":   cc.Name,
+		"reports":   len(retired.Reports),
+		"expiresAt": retired.ExpiresAt,
+	})
+}
+
+// Retired lists the removed clusters that can still be restored.
+func (r *ClusterRegistry) Retired() []RetiredCluster {
+	if r.cacheSvc == nil {
+		return nil
+	}
+	var result []RetiredCluster
+	for k, v := range r.cacheSvc.Items() {
+		name, ok := strings.CutPrefix(k, "retired:")
+		if !ok || r.Get(name) != nil {
+			continue
+		}
+		if retired, ok := convertCacheValue[RetiredCluster](v); ok {
+			result = append(result, retired)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Cluster.Name < result[j].Cluster.Name })
+	return result
+}
+
+// Restore puts back the cached entries of a removed cluster. A cluster removed by this
+// process is watched again; otherwise its reports are served from the cache until it is
+// registered again. It reports whether the cluster is watched.
+func (r *ClusterRegistry) Restore(clusterName string) (RetiredCluster, bool, error) {
+	if r.Get(clusterName) != nil {
+		return RetiredCluster{}, false, errClusterActive
+	}
+	var retired RetiredCluster
+	found := false
+	if r.cacheSvc != nil {
+		if v, ok := r.cacheSvc.Get(retiredClusterKey(clusterName)); ok {
+			retired, found = convertCacheValue[RetiredCluster](v)
+		}
+	}
+	r.mu.Lock()
+	cc := r.retired[clusterName]
+	delete(r.retired, clusterName)
+	r.mu.Unlock()
+	if !found {
+		return RetiredCluster{}, false, errClusterNotRetired
+	}
+
+	r.cacheSvc.Delete(retiredClusterKey(clusterName))
+	r.cacheSvc.Set(clusterKey(clusterName), retired.Cluster, 0)
+	for _, ns := range retired.Namespaces {
+		r.cacheSvc.Set(namespaceKey(clusterName, ns), Namespace{Cluster: clusterName, Name: ns}, 0)
+	}
+	for _, report := range retired.Reports {
+		r.cacheSvc.Set(reportKey(clusterName, report.Namespace, report.Type, report.Name), report, 0)
+	}
+	aggregates.invalidate(clusterName)
+
+	if cc == nil {
+		return retired, false, nil
+	}
+	r.mu.Lock()
+	r.clients[clusterName] = cc
+	r.mu.Unlock()
+	if cc.Source != nil {
+		if err := cc.Source.Watch(clusterName, NewCacheUpdater(r)); err != nil {
+			utils.LogWarning("Failed to watch restored cluster", map[string]interface{}{"cluster": clusterName, "error": err.Error()})
+		}
+	}
+	return retired, true, nil
+}
+
+// inactiveClusters lists the removed clusters flagged inactive, for ?includeInactive.
+func (h *Handler) inactiveClusters() []Cluster {
+	var clusters []Cluster
+	for _, retired := range h.clusterReg.Retired() {
+		c := retired.Cluster
+		removedAt := retired.RemovedAt
+		c.Inactive = true
+		c.RemovedAt = &removedAt
+		c.SyncState = "Inactive"
+		clusters = append(clusters, c)
+	}
+	return clusters
+}
+
+// RestoreCluster restores a removed cluster with its cached reports.
+func (h *Handler) RestoreCluster(w http.ResponseWriter, r *http.Request, name string) {
+	retired, watching, err := h.clusterReg.Restore(name)
+	switch {
+	case errors.Is(err, errClusterActive):
+		writeError(w, http.StatusConflict, "Cluster is registered")
+		return
+	case err != nil:
+		writeError(w, http.StatusNotFound, "No removed cluster to restore")
+		return
+	}
+	message := "Success"
+	if !watching {
+		message = "Reports restored; the cluster is watched again once it is registered"
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: message,
+		Data: map[string]interface{}{
+			"cluster":  retired.Cluster,
+			"reports":  len(retired.Reports),
+			"watching": watching,
+		},
+	})
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"trivy-ui/config"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// maxClusterTags bounds the tags of one cluster.
+const maxClusterTags = 32
+
+// noClusters is a cluster filter no cluster matches, for tag filters no cluster has.
+const noClusters = "\x00"
+
+// clusterTagCache holds the tags set through the API, read from the store on first use.
+type clusterTagCache struct {
+	mu     sync.Mutex
+	loaded bool
+	tags   map[string]map[string]string
+}
+
+var apiClusterTags = &clusterTagCache{}
+
+func (c *clusterTagCache) get() map[string]map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		st := store.Get()
+		if st == nil {
+			return nil
+		}
+		ctx, cancel := storeCallContext(context.Background())
+		tags, err := st.ListClusterTags(ctx)
+		cancel()
+		if err != nil {
+			utils.LogWarning("Failed to load cluster tags", map[string]interface{}{"error": err.Error()})
+			return nil
+		}
+		c.tags, c.loaded = tags, true
+	}
+	return c.tags
+}
+
+func (c *clusterTagCache) set(cluster string, tags map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		// loaded in full on the next read
+		return
+	}
+	if len(tags) == 0 {
+		delete(c.tags, cluster)
+	} else {
+		c.tags[cluster] = tags
+	}
+}
+
+// allClusterTags returns the tags of every tagged cluster: those of CLUSTER_TAGS, with
+// tags set through the API replacing configured ones of the same key.
+func allClusterTags() map[string]map[string]string {
+	all := make(map[string]map[string]string)
+	for _, source := range []map[string]map[string]string{config.Get().ClusterTags, apiClusterTags.get()} {
+		for cluster, tags := range source {
+			if all[cluster] == nil {
+				all[cluster] = make(map[string]string, len(tags))
+			}
+			for k, v := range tags {
+				all[cluster][k] = v
+			}
+		}
+	}
+	return all
+}
+
+// clusterTagsOf returns the tags of one cluster, or nil when it has none.
+func clusterTagsOf(cluster string) map[string]string {
+	return allClusterTags()[cluster]
+}
+
+// withClusterTags sets the tags of listed clusters.
+func withClusterTags(clusters []Cluster) {
+	all := allClusterTags()
+	for i := range clusters {
+		clusters[i].Tags = all[clusters[i].Name]
+	}
+}
+
+// tagFilter is one tag=key:value parameter; a filter without a value matches clusters
+// having the key.
+type tagFilter struct {
+	key, value string
+	anyValue   bool
+}
+
+func parseTagFilters(params []string) []tagFilter {
+	var filters []tagFilter
+	for _, p := range params {
+		for _, item := range strings.Split(p, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(item, ":")
+			filters = append(filters, tagFilter{key: strings.TrimSpace(key), value: strings.TrimSpace(value), anyValue: !ok})
+		}
+	}
+	return filters
+}
+
+// taggedClusters returns the clusters whose tags match every filter, sorted.
+func taggedClusters(all map[string]map[string]string, filters []tagFilter) []string {
+	var clusters []string
+	for cluster, tags := range all {
+		matched := true
+		for _, f := range filters {
+			v, ok := tags[f.key]
+			if !ok || (!f.anyValue && v != f.value) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Strings(clusters)
+	return clusters
+}
+
+// clusterParam returns the cluster filter of a request: its cluster parameter narrowed to
+// the clusters matching its tag parameters, as a comma-separated list. Clusters match
+// when they have all the tags asked for; when none does the filter matches no cluster.
+func clusterParam(r *http.Request) string {
+	cluster := r.URL.Query().Get("cluster")
+	filters := parseTagFilters(r.URL.Query()["tag"])
+	if len(filters) == 0 {
+		return cluster
+	}
+	clusters := taggedClusters(allClusterTags(), filters)
+	if cluster != "" {
+		selected := clusterSet(cluster)
+		kept := clusters[:0]
+		for _, c := range clusters {
+			if selected[c] {
+				kept = append(kept, c)
+			}
+		}
+		clusters = kept
+	}
+	if len(clusters) == 0 {
+		return noClusters
+	}
+	return strings.Join(clusters, ",")
+}
+
+// clusterSet returns the clusters of a cluster filter, or nil for no filter.
+func clusterSet(filter string) map[string]bool {
+	if filter == "" {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, c := range strings.Split(filter, ",") {
+		set[c] = true
+	}
+	return set
+}
+
+// aggregateScope is the aggregate cache scope of a cluster filter: the cluster when it
+// names one, otherwise fleet-wide, which any report change invalidates.
+func aggregateScope(filter string) string {
+	if strings.Contains(filter, ",") || filter == noClusters {
+		return ""
+	}
+	return filter
+}
+
+// validateClusterTags checks tags given through the API; keys cannot hold the separators
+// of tag filters.
+func validateClusterTags(tags map[string]string) error {
+	if len(tags) > maxClusterTags {
+		return fmt.Errorf("at most %d tags per cluster", maxClusterTags)
+	}
+	for k, v := range tags {
+		if k == "" || len(k) > 63 || strings.ContainsAny(k, ":,= \t") {
+			return fmt.Errorf("invalid tag key %q", k)
+		}
+		if v == "" || len(v) > 255 || strings.Contains(v, ",") {
+			return fmt.Errorf("invalid value for tag %q", k)
+		}
+	}
+	return nil
+}
+
+// ClusterTags serves GET and PUT on /api/v1/clusters/{name}/tags. PUT replaces the tags
+// set through the API; configured tags of other keys are kept.
+func (h *Handler) ClusterTags(w http.ResponseWriter, r *http.Request, cluster string) {
+	if r.Method == http.MethodPut {
+		st := requireStore(w)
+		if st == nil {
+			return
+		}
+		var body struct {
+			Tags map[string]string `json:"tags"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if err := validateClusterTags(body.Tags); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		ctx, cancel := storeCallContext(r.Context())
+		defer cancel()
+		if err := st.SetClusterTags(ctx, cluster, body.Tags); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		apiClusterTags.set(cluster, body.Tags)
+		utils.LogInfo("Cluster tags set", map[string]interface{}{"cluster": cluster, "tags": body.Tags})
+	}
+	tags := clusterTagsOf(cluster)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    map[string]interface{}{"cluster": cluster, "tags": tags},
+	})
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+)
+
+func TestTaggedClusters(t *testing.T) {
+	all := map[string]map[string]string{
+		"prod-eu": {"region": "eu-west-1", "tier": "prod"},
+		"prod-us": {"region": "us-east-1", "tier": "prod"},
+		"dev":     {"tier": "dev"},
+	}
+	tests := []struct {
+		params []string
+		want   []string
+	}{
+		{[]string{"tier:prod"}, []string{"prod-eu", "prod-us"}},
+		{[]string{"tier:prod", "region:eu-west-1"}, []string{"prod-eu"}},
+		{[]string{"tier:prod,region:us-east-1"}, []string{"prod-us"}},
+		{[]string{"region"}, []string{"prod-eu", "prod-us"}},
+		{[]string{"tier:staging"}, nil},
+	}
+	for _, tt := range tests {
+		if got := taggedClusters(all, parseTagFilters(tt.params)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tags %v matched %v, want %v", tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestGetReportsClusterList(t *testing.T) {
+	c := useTestCache(t)
+	for _, cluster := range []string{"prod-eu", "prod-us", "dev"} {
+		c.Set(reportKey(cluster, "default", "tagtestreports", "r"), makeReport("r", cluster, "default", "tagtestreports", 1), 0)
+	}
+
+	reports := c.GetReports("tagtestreports", "prod-eu,prod-us", nil)
+	if len(reports) != 2 {
+		t.Fatalf("expected the reports of both prod clusters, got %d", len(reports))
+	}
+	for _, r := range reports {
+		if r.Cluster == "dev" {
+			t.Errorf("report of an unselected cluster listed: %+v", r)
+		}
+	}
+	if reports := c.GetReports("tagtestreports", noClusters, nil); len(reports) != 0 {
+		t.Errorf("filter matching no cluster listed %d reports", len(reports))
+	}
+	if overview := c.GetOverviewData("prod-eu,prod-us", false, nil); overview.TotalReports != 2 || len(overview.VulnerableClusters) != 2 {
+		t.Errorf("overview = %d reports, %d clusters", overview.TotalReports, len(overview.VulnerableClusters))
+	}
+}
+
+func TestValidateClusterTags(t *testing.T) {
+	if err := validateClusterTags(map[string]string{"region": "eu-west-1", "tier": "prod"}); err != nil {
+		t.Fatalf("valid tags rejected: %v", err)
+	}
+	for _, tags := range []map[string]string{
+		{"": "x"},
+		{"a:b": "x"},
+		{"tier": ""},
+		{"tier": "a,b"},
+	} {
+		if err := validateClusterTags(tags); err == nil {
+			t.Errorf("tags %v accepted", tags)
+		}
+	}
+}
+
+func useTestClusterTags(t *testing.T, tags map[string]map[string]string) {
+	t.Helper()
+	cfg := config.Get()
+	prev := cfg.ClusterTags
+	t.Cleanup(func() { cfg.ClusterTags = prev })
+	cfg.ClusterTags = tags
+}
+
+func TestGetOverviewTrendsClusterTags(t *testing.T) {
+	useTestClusterTags(t, map[string]map[string]string{"prod-eu": {"tier": "prod"}, "prod-us": {"tier": "prod"}})
+	c := useTestCache(t)
+	h := &Handler{cache: &CacheServiceImpl{cache: c}}
+	now := time.Now().UTC().Truncate(time.Second)
+	records, _ := json.Marshal([]TrendRecord{
+		{Timestamp: now.Add(-2 * time.Hour), Critical: 9},
+		{Timestamp: now.Add(-2 * time.Hour), Cluster: "prod-eu", Critical: 2},
+		{Timestamp: now.Add(-2 * time.Hour), Cluster: "prod-us", Critical: 3, High: 1},
+		{Timestamp: now.Add(-2 * time.Hour), Cluster: "dev", Critical: 4},
+		{Timestamp: now.Add(-time.Hour), Critical: 5},
+		{Timestamp: now.Add(-time.Hour), Cluster: "prod-us", Critical: 1},
+	})
+	if err := os.WriteFile(filepath.Join(config.Get().DataPath, "trend-history.json"), records, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(filepath.Join(config.Get().DataPath, "trend-history.json")) })
+
+	trends := func(query string) []TrendRecord {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.GetOverviewTrends(rec, httptest.NewRequest(http.MethodGet, "/api/v1/overview/trends?"+query, nil))
+		var resp struct{ Data []TrendRecord }
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		return resp.Data
+	}
+	if got := trends("tag=tier:prod"); len(got) != 2 || got[0].Critical != 5 || got[0].High != 1 || got[1].Critical != 1 {
+		t.Errorf("expected the prod clusters added up per recording, got %+v", got)
+	}
+	if got := trends("cluster=dev"); len(got) != 1 || got[0].Critical != 4 {
+		t.Errorf("expected dev's trend, got %+v", got)
+	}
+	if got := trends("tag=tier:staging"); len(got) != 0 {
+		t.Errorf("expected no trend for tags matching no cluster, got %+v", got)
+	}
+	if got := trends(""); len(got) != 2 || got[0].Critical != 9 {
+		t.Errorf("expected the fleet-wide trend, got %+v", got)
+	}
+}
+
+func TestStreamEventsClusterTags(t *testing.T) {
+	useTestClusterTags(t, map[string]map[string]string{"prod-eu": {"tier": "prod"}})
+	hub := useTestEventHub(t)
+	h := &Handler{}
+	srv := httptest.NewServer(http.HandlerFunc(h.StreamEvents))
+	defer srv.Close()
+
+	hub.publish(ChangeEvent{Type: EventReportDeleted, Cluster: "dev", Name: "r1"})
+	hub.publish(ChangeEvent{Type: EventReportDeleted, Cluster: "prod-eu", Name: "r2"})
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"?tag=tier:prod", nil)
+	req.Header.Set("Last-Event-ID", hub.epoch+"-0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line = strings.TrimSpace(line); line != "id: "+hub.epoch+"-2" {
+		t.Fatalf("expected only prod-eu's event replayed, got %q", line)
+	}
+}
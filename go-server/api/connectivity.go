@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
+)
+
+// ConnectivityUntracked is the connectivity of clusters the server does not connect to:
+// pushed clusters and clusters loaded from report directories.
+const ConnectivityUntracked = "untracked"
+
+// ClusterConnectivity is the connectivity history of a cluster.
+type ClusterConnectivity struct {
+	Cluster string `json:"cluster"`
+	kubernetes.Connectivity
+	// StaleSince is set while the API server does not answer: the cached reports are as
+	// old as the last request it answered
+	StaleSince *time.Time `json:"staleSince,omitempty"`
+	// LastPush is when the agent of a pushed cluster last sent reports
+	LastPush *time.Time `json:"lastPush,omitempty"`
+}
+
+// staleSince is the time the data of a cluster that stopped answering was last current,
+// the start of the outage when no request ever succeeded.
+func staleSince(c kubernetes.Connectivity) *time.Time {
+	if c.LastSuccess != nil {
+		return c.LastSuccess
+	}
+	since := c.Since
+	return &since
+}
+
+// publishConnectivityChange sends a cluster.connectivity event and logs the transition.
+func publishConnectivityChange(cluster string, t kubernetes.ConnectivityTransition) {
+	fields := map[string]interface{}{"cluster": cluster, "state": t.State}
+	if t.Error != "" {
+		fields["error"] = t.Error
+		utils.LogWarning("Cluster API server connectivity changed", fields)
+	} else {
+		utils.LogInfo("Cluster API server connectivity changed", fields)
+	}
+	changeEvents.publish(ChangeEvent{Type: EventClusterConnectivity, Cluster: cluster, State: t.State, Time: t.At})
+}
+
+// GetClusterConnectivity handles GET /api/v1/clusters/{name}/connectivity.
+func (h *Handler) GetClusterConnectivity(w http.ResponseWriter, r *http.Request, cluster string) {
+	cc := h.clusterReg.Get(cluster)
+	if cc == nil {
+		writeError(w, http.StatusNotFound, "Cluster not found")
+		return
+	}
+	result := ClusterConnectivity{Cluster: cc.Name}
+	if cc.Client != nil {
+		result.Connectivity = cc.Client.Connectivity()
+		if result.State == kubernetes.ConnectivityUnreachable || result.State == kubernetes.ConnectivityUnauthorized {
+			result.StaleSince = staleSince(result.Connectivity)
+		}
+	} else {
+		result.Connectivity = kubernetes.Connectivity{State: ConnectivityUntracked, Transitions: []kubernetes.ConnectivityTransition{}}
+		cc.mu.RLock()
+		if !cc.LastPush.IsZero() {
+			lastPush := cc.LastPush
+			result.LastPush = &lastPush
+		}
+		cc.mu.RUnlock()
+	}
+	writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: result})
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+func TestGetClusterConnectivity(t *testing.T) {
+	svc := &stubCacheService{}
+	reg := NewClusterRegistry(svc)
+	reg.RegisterPushed("edge", "v1.30.2", []string{"default"})
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), config.GetGlobalRegistry())
+
+	rec := httptest.NewRecorder()
+	h.GetClusterConnectivity(rec, httptest.NewRequest(http.MethodGet, "/api/v1/clusters/missing/connectivity", nil), "missing")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown cluster: got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetClusterConnectivity(rec, httptest.NewRequest(http.MethodGet, "/api/v1/clusters/edge/connectivity", nil), "edge")
+	var resp struct {
+		Data ClusterConnectivity `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.State != ConnectivityUntracked || resp.Data.LastPush == nil || resp.Data.StaleSince != nil {
+		t.Errorf("pushed cluster: got %+v", resp.Data)
+	}
+}
+
+func TestStaleSince(t *testing.T) {
+	outage := time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	lastSuccess := outage.Add(-time.Minute)
+	if got := staleSince(kubernetes.Connectivity{State: kubernetes.ConnectivityUnreachable, Since: outage, LastSuccess: &lastSuccess}); !got.Equal(lastSuccess) {
+		t.Errorf("got %s, want the last success", got)
+	}
+	// unreachable from the start: stale since the outage began
+	if got := staleSince(kubernetes.Connectivity{State: kubernetes.ConnectivityUnreachable, Since: outage}); !got.Equal(outage) {
+		t.Errorf("got %s, want the outage start", got)
+	}
+}
+
+func TestPublishConnectivityChange(t *testing.T) {
+	hub := useTestEventHub(t)
+	events, unsubscribe := hub.subscribe()
+	defer unsubscribe()
+
+	at := time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	publishConnectivityChange("prod", kubernetes.ConnectivityTransition{State: kubernetes.ConnectivityUnreachable, At: at, Error: "connection refused"})
+	e := <-events
+	if e.Type != EventClusterConnectivity || e.Cluster != "prod" || e.State != kubernetes.ConnectivityUnreachable || !e.Time.Equal(at) {
+		t.Errorf("got %+v", e)
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// groupByWorkload is the groupBy value that lists a workload's per-container reports
+// together.
+const groupByWorkload = "workload"
+
+// ReportWorkload is the workload a report was created for, from the operator's labels,
+// which keep the full names the report name may truncate. Reports of a ReplicaSet belong
+// to its Deployment.
+type ReportWorkload struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// ResourceKind and ResourceName are the scanned resource, e.g. the ReplicaSet
+	ResourceKind string `json:"resourceKind"`
+	ResourceName string `json:"resourceName"`
+}
+
+// WorkloadGroup is the reports of one workload, one per container for vulnerability and
+// exposed secret reports.
+type WorkloadGroup struct {
+	Cluster   string         `json:"cluster"`
+	Namespace string         `json:"namespace,omitempty"`
+	Workload  ReportWorkload `json:"workload"`
+	// Status is the worst status of the reports
+	Status     string         `json:"status"`
+	Severity   SeverityTotals `json:"severity"`
+	Containers []string       `json:"containers"`
+	Reports    []Report       `json:"reports"`
+}
+
+// reportLabel returns a label of the report's resource.
+func reportLabel(r Report, name string) string {
+	data, _ := r.Data.(map[string]interface{})
+	metadata, _ := data["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	value, _ := labels[name].(string)
+	return value
+}
+
+// reportContainer returns the container a report scanned, or "" for reports about a
+// whole resource.
+func reportContainer(r Report) string {
+	return reportLabel(r, "trivy-operator.container.name")
+}
+
+// reportWorkloadRef returns the workload of a report, or nil without operator labels.
+func reportWorkloadRef(r Report) *ReportWorkload {
+	kind, name := reportResource(r)
+	if kind == "" || name == "" {
+		return nil
+	}
+	w := &ReportWorkload{Kind: kind, Name: name, ResourceKind: kind, ResourceName: name}
+	if kind == "ReplicaSet" {
+		w.Name = reportWorkload(r)
+		if w.Name != name {
+			w.Kind = "Deployment"
+		}
+	}
+	return w
+}
+
+// withContainers returns copies of the reports with their container and workload set.
+// Items may be shared with the query cache, so they are never modified in place.
+func withContainers(reports []Report) []Report {
+	result := make([]Report, len(reports))
+	for i, r := range reports {
+		r.Container = reportContainer(r)
+		r.Workload = reportWorkloadRef(r)
+		result[i] = r
+	}
+	return result
+}
+
+// containerParam returns the container filter of a request: a comma-separated list of
+// container names.
+func containerParam(r *http.Request) []string {
+	var containers []string
+	for _, c := range strings.Split(r.URL.Query().Get("container"), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			containers = append(containers, c)
+		}
+	}
+	return containers
+}
+
+// matchesContainers reports whether a report scanned one of the containers.
+func matchesContainers(r Report, containers []string) bool {
+	container := reportContainer(r)
+	for _, c := range containers {
+		if c == container {
+			return true
+		}
+	}
+	return false
+}
+
+// groupReportsByWorkload groups reports by cluster, namespace and workload, in the order
+// of each workload's first report. Reports without operator labels are groups of their
+// own.
+func groupReportsByWorkload(reports []Report) []WorkloadGroup {
+	var groups []WorkloadGroup
+	index := make(map[string]int)
+	for _, r := range reports {
+		workload := reportWorkloadRef(r)
+		if workload == nil {
+			workload = &ReportWorkload{Name: r.Name, ResourceName: r.Name}
+		}
+		key := r.Cluster + "\x00" + r.Namespace + "\x00" + workload.Kind + "\x00" + workload.Name
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, WorkloadGroup{
+				Cluster:    r.Cluster,
+				Namespace:  r.Namespace,
+				Workload:   *workload,
+				Containers: []string{},
+				Reports:    []Report{},
+			})
+		}
+		g := &groups[i]
+		c, h, m, l := extractSummaryCounts(r)
+		g.Severity.Critical += c
+		g.Severity.High += h
+		g.Severity.Medium += m
+		g.Severity.Low += l
+		if container := reportContainer(r); container != "" {
+			g.Containers = append(g.Containers, container)
+		}
+		g.Reports = append(g.Reports, r)
+	}
+	for i := range groups {
+		groups[i].Status = worstSeverity(groups[i].Severity)
+	}
+	return groups
+}
+
+// writeReportList writes a page of a report list, or with groupBy=workload a page of the
+// workloads the matching reports belong to.
+func (h *Handler) writeReportList(w http.ResponseWriter, r *http.Request, q ReportQuery) {
+	switch groupBy := r.URL.Query().Get("groupBy"); groupBy {
+	case "":
+	case groupByWorkload:
+		// group every matching report, then page through the groups
+		all := q
+		all.Page, all.PageSize = 1, math.MaxInt32
+		result := h.querySvc.ListReports(all)
+		groups := groupReportsByWorkload(withReportAges(withContainers(withWorkloadStates(withReportLinks(result.Items))), time.Now()))
+		start := min((q.Page-1)*q.PageSize, len(groups))
+		end := min(start+q.PageSize, len(groups))
+		page := newPaginatedResponse(q, QueryResult{Total: len(groups), WithVulnerabilities: result.WithVulnerabilities}, groups[start:end])
+		writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: page})
+		return
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid groupBy parameter, expected workload")
+		return
+	}
+
+	result := h.querySvc.ListReports(q)
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    newPaginatedResponse(q, result, withReportAges(withContainers(withWorkloadStates(withReportLinks(result.Items))), time.Now())),
+	})
+}
+
+// containerSibling returns the name of the report of the given container for the same
+// resource as a report, so a detail request can switch containers by name.
+func (h *Handler) containerSibling(typeName, cluster, namespace, reportName, container string) (string, bool) {
+	var namespaces []string
+	if namespace != "" {
+		namespaces = []string{namespace}
+	}
+	reports := h.cache.GetReports(typeName, cluster, namespaces)
+	var resourceKind, resourceName string
+	for _, r := range reports {
+		if r.Name == reportName && r.Namespace == namespace {
+			if reportContainer(r) == container {
+				return reportName, true
+			}
+			resourceKind, resourceName = reportResource(r)
+			break
+		}
+	}
+	if resourceName == "" {
+		return "", false
+	}
+	for _, r := range reports {
+		if r.Namespace != namespace || reportContainer(r) != container {
+			continue
+		}
+		if kind, name := reportResource(r); kind == resourceKind && name == resourceName {
+			return r.Name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trivy-ui/config"
+)
+
+// containerReport is a vulnerability report of one container of a ReplicaSet.
+func containerReport(name, replicaSet, container string, critical float64) Report {
+	r := makeReport(name, "c1", "web", "containertestreports", critical)
+	r.Data.(map[string]interface{})["metadata"] = map[string]interface{}{"labels": map[string]interface{}{
+		"trivy-operator.resource.kind":  "ReplicaSet",
+		"trivy-operator.resource.name":  replicaSet,
+		"trivy-operator.container.name": container,
+	}}
+	return r
+}
+
+func TestReportListContainers(t *testing.T) {
+	cache := &stubCacheService{reports: map[string][]Report{"containertestreports": {
+		containerReport("replicaset-frontend-7d9f8-nginx", "frontend-7d9f8", "nginx", 1),
+		containerReport("replicaset-frontend-7d9f8-istio-proxy", "frontend-7d9f8", "istio-proxy", 0),
+		containerReport("replicaset-api-5c6b4-api", "api-5c6b4", "api", 2),
+	}}}
+	t.Cleanup(func() { queryResultCache.Clear() })
+	h := &Handler{cache: cache, querySvc: NewQueryService(cache), crdReg: config.GetGlobalRegistry()}
+
+	list := func(query string, data interface{}) int {
+		rec := httptest.NewRecorder()
+		h.GetReportsByTypeV1(rec, httptest.NewRequest(http.MethodGet, "/api/v1/type/containertestreports?"+query, nil), "containertestreports")
+		json.NewDecoder(rec.Body).Decode(&struct {
+			Data interface{} `json:"data"`
+		}{Data: data})
+		return rec.Code
+	}
+
+	var reports struct {
+		Total int      `json:"total"`
+		Data  []Report `json:"data"`
+	}
+	list("container=nginx,api", &reports)
+	if reports.Total != 2 {
+		t.Fatalf("expected the nginx and api reports, got %+v", reports)
+	}
+	first := reports.Data[0]
+	if first.Container == "" || first.Workload == nil || first.Workload.Kind != "Deployment" || first.Workload.ResourceKind != "ReplicaSet" {
+		t.Fatalf("expected container and workload set, got %+v", first)
+	}
+
+	var groups struct {
+		Total int             `json:"total"`
+		Data  []WorkloadGroup `json:"data"`
+	}
+	list("groupBy=workload&pageSize=1", &groups)
+	if groups.Total != 2 || len(groups.Data) != 1 {
+		t.Fatalf("expected one of two workloads, got %+v", groups)
+	}
+	g := groups.Data[0]
+	if g.Workload.Name != "frontend" || len(g.Reports) != 2 || len(g.Containers) != 2 || g.Status != "Critical" || g.Severity.Critical != 1 {
+		t.Fatalf("unexpected group %+v", g)
+	}
+
+	if code := list("groupBy=pod", &groups); code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown groupBy to be rejected, got %d", code)
+	}
+}
+
+func TestContainerSibling(t *testing.T) {
+	cache := &stubCacheService{reports: map[string][]Report{"containertestreports": {
+		containerReport("replicaset-frontend-7d9f8-nginx", "frontend-7d9f8", "nginx", 1),
+		containerReport("replicaset-frontend-7d9f8-istio-proxy", "frontend-7d9f8", "istio-proxy", 0),
+		containerReport("replicaset-api-5c6b4-istio-proxy", "api-5c6b4", "istio-proxy", 0),
+	}}}
+	h := &Handler{cache: cache}
+
+	if name, ok := h.containerSibling("containertestreports", "c1", "web", "replicaset-frontend-7d9f8-nginx", "istio-proxy"); !ok || name != "replicaset-frontend-7d9f8-istio-proxy" {
+		t.Fatalf("expected the sidecar of the same ReplicaSet, got %q %v", name, ok)
+	}
+	if name, ok := h.containerSibling("containertestreports", "c1", "web", "replicaset-frontend-7d9f8-nginx", "nginx"); !ok || name != "replicaset-frontend-7d9f8-nginx" {
+		t.Fatalf("expected the report itself, got %q %v", name, ok)
+	}
+	if _, ok := h.containerSibling("containertestreports", "c1", "web", "replicaset-frontend-7d9f8-nginx", "api"); ok {
+		t.Fatal("expected no report of a container of another workload")
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Per-call timeouts on top of the caller's context. Handlers pass r.Context(), so a call
+// ends when either the client disconnects or the API server or database is too slow;
+// background work passes its own context.
+const (
+	kubeCallTimeout  = 10 * time.Second
+	storeCallTimeout = 5 * time.Second
+)
+
+func kubeCallContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, kubeCallTimeout)
+}
+
+func storeCallContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, storeCallTimeout)
+}
+
+// clientGone reports whether ctx ended because the client disconnected; nothing needs to
+// be written back then.
+func clientGone(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.Canceled)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	if clientGone(ctx) {
+		t.Fatal("live context reported as gone")
+	}
+	cancel()
+	if !clientGone(ctx) {
+		t.Fatal("canceled context not reported as gone")
+	}
+
+	// a timed-out call is the server's problem, not a disconnect
+	timedOut, cancel := context.WithTimeout(t.Context(), time.Nanosecond)
+	defer cancel()
+	<-timedOut.Done()
+	if clientGone(timedOut) {
+		t.Fatal("deadline exceeded reported as client gone")
+	}
+}
+
+func TestCallContextsFollowParent(t *testing.T) {
+	parent, cancel := context.WithCancel(t.Context())
+	kubeCtx, kubeCancel := kubeCallContext(parent)
+	defer kubeCancel()
+	storeCtx, storeCancel := storeCallContext(parent)
+	defer storeCancel()
+
+	if d, ok := kubeCtx.Deadline(); !ok || time.Until(d) > kubeCallTimeout {
+		t.Fatalf("kube call deadline = %v, %v", d, ok)
+	}
+	if d, ok := storeCtx.Deadline(); !ok || time.Until(d) > storeCallTimeout {
+		t.Fatalf("store call deadline = %v, %v", d, ok)
+	}
+
+	cancel()
+	for name, ctx := range map[string]context.Context{"kube": kubeCtx, "store": storeCtx} {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatalf("%s call context outlived its request", name)
+		}
+		if !clientGone(ctx) {
+			t.Fatalf("%s call context: canceled request not reported as gone", name)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"trivy-ui/frameworks"
+	"trivy-ui/kubernetes"
+)
+
+// Control coverage statuses.
+const (
+	ControlPass        = "pass"
+	ControlFail        = "fail"
+	ControlNotAssessed = "not-assessed"
+)
+
+// ControlCoverage is how a cluster fares on one framework control.
+type ControlCoverage struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Status is fail when a mapped check fails on any resource, pass when the cluster has
+	// audit reports and none of them fails a mapped check, and not-assessed without reports
+	Status       string   `json:"status"`
+	Checks       []string `json:"checks"`
+	FailedChecks []string `json:"failedChecks"`
+	// Resources are the failing reports as namespace/name, cluster-scoped ones as name
+	Resources []string `json:"resources"`
+}
+
+type ControlCoverageSummary struct {
+	Pass        int `json:"pass"`
+	Fail        int `json:"fail"`
+	NotAssessed int `json:"notAssessed"`
+}
+
+// ClusterControlCoverage is the control coverage of one cluster.
+type ClusterControlCoverage struct {
+	Cluster   string `json:"cluster"`
+	Framework string `json:"framework"`
+	// AuditedReports counts the config audit, RBAC and infra assessment reports evaluated
+	AuditedReports int                    `json:"auditedReports"`
+	Summary        ControlCoverageSummary `json:"summary"`
+	Controls       []ControlCoverage      `json:"controls"`
+}
+
+// computeControlCoverage maps the failed checks of every audit report to the controls of
+// framework, per cluster. Clusters without audit reports are listed as not assessed.
+func (h *Handler) computeControlCoverage(clusterFilter, framework string) []ClusterControlCoverage {
+	type clusterAggregate struct {
+		audited   int
+		failed    map[string]map[string]bool
+		resources map[string]map[string]bool
+	}
+	byCluster := make(map[string]*clusterAggregate)
+	aggregate := func(cluster string) *clusterAggregate {
+		agg, ok := byCluster[cluster]
+		if !ok {
+			agg = &clusterAggregate{failed: make(map[string]map[string]bool), resources: make(map[string]map[string]bool)}
+			byCluster[cluster] = agg
+		}
+		return agg
+	}
+	selected := clusterSet(clusterFilter)
+	if h.clusterReg != nil {
+		for name := range h.clusterReg.All() {
+			if selected == nil || selected[name] {
+				aggregate(name)
+			}
+		}
+	}
+
+	for _, kind := range h.crdReg.GetAllReports() {
+		for _, report := range h.cache.GetReports(kind.Name, clusterFilter, nil) {
+			data, ok := report.Data.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			failed := kubernetes.FailedCheckIDs(data)
+			if failed == nil {
+				continue
+			}
+			agg := aggregate(report.Cluster)
+			agg.audited++
+			resource := report.Name
+			if report.Namespace != "" {
+				resource = report.Namespace + "/" + report.Name
+			}
+			for _, checkID := range failed {
+				check, ok := frameworks.Lookup(checkID)
+				if !ok {
+					continue
+				}
+				for _, id := range check.ControlIDs(framework) {
+					if agg.failed[id] == nil {
+						agg.failed[id], agg.resources[id] = make(map[string]bool), make(map[string]bool)
+					}
+					agg.failed[id][checkID] = true
+					agg.resources[id][resource] = true
+				}
+			}
+		}
+	}
+
+	result := make([]ClusterControlCoverage, 0, len(byCluster))
+	for cluster, agg := range byCluster {
+		coverage := ClusterControlCoverage{Cluster: cluster, Framework: framework, AuditedReports: agg.audited, Controls: []ControlCoverage{}}
+		for _, control := range frameworks.Controls(framework) {
+			c := ControlCoverage{ID: control.ID, Title: control.Title, Checks: control.Checks, FailedChecks: sortedKeys(agg.failed[control.ID]), Resources: sortedKeys(agg.resources[control.ID])}
+			switch {
+			case agg.audited == 0:
+				c.Status = ControlNotAssessed
+				coverage.Summary.NotAssessed++
+			case len(c.FailedChecks) > 0:
+				c.Status = ControlFail
+				coverage.Summary.Fail++
+			default:
+				c.Status = ControlPass
+				coverage.Summary.Pass++
+			}
+			coverage.Controls = append(coverage.Controls, c)
+		}
+		result = append(result, coverage)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Cluster < result[j].Cluster })
+	return result
+}
+
+// controlCoverageCSV writes one row per cluster and control, for auditors.
+func controlCoverageCSV(coverage []ClusterControlCoverage) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"cluster", "framework", "control", "title", "status", "checks", "failedChecks", "failingResources", "resources"})
+	for _, cluster := range coverage {
+		for _, c := range cluster.Controls {
+			w.Write([]string{cluster.Cluster, cluster.Framework, c.ID, c.Title, c.Status,
+				strings.Join(c.Checks, " "), strings.Join(c.FailedChecks, " "),
+				strconv.Itoa(len(c.Resources)), strings.Join(c.Resources, "; ")})
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// GetControlCoverage handles GET /api/v1/compliance/controls: the coverage of the
+// framework parameter's controls (nist-800-53 or iso-27001) per cluster, optionally for
+// one cluster, as JSON or with format=csv as a spreadsheet download.
+func (h *Handler) GetControlCoverage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	framework := q.Get("framework")
+	if !frameworks.Supported(framework) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("framework must be %s or %s", frameworks.NIST80053, frameworks.ISO27001))
+		return
+	}
+	format := q.Get("format")
+	if format != "" && format != ExportFormatCSV {
+		writeError(w, http.StatusBadRequest, "format must be csv")
+		return
+	}
+	cluster := clusterParam(r)
+
+	coverage := aggregates.getOrCompute("controls", aggregateScope(cluster), cluster+"|"+framework, func() interface{} {
+		return h.computeControlCoverage(cluster, framework)
+	}).([]ClusterControlCoverage)
+
+	if format == "" {
+		writeJSON(w, http.StatusOK, Response{
+			Code:    CodeSuccess,
+			Message: "Success",
+			Data:    coverage,
+		})
+		return
+	}
+	data, err := controlCoverageCSV(coverage)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	scope := cluster
+	if scope == "" || strings.Contains(scope, ",") {
+		scope = "clusters"
+	}
+	name := fmt.Sprintf("control-coverage-%s-%s-%s.csv", framework, exportFileSlug(scope), time.Now().UTC().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.Write(data)
+}
+
+// GetControlMapping handles GET /api/v1/compliance/mapping: the bundled table of check IDs
+// and the controls they map to.
+func (h *Handler) GetControlMapping(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: frameworks.Checks()})
+}
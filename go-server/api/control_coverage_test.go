@@ -0,0 +1,64 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/frameworks"
+)
+
+func TestComputeControlCoverage(t *testing.T) {
+	config.GetGlobalRegistry().Register(config.ReportKind{Name: "configauditreports", Namespaced: true})
+	cache := &stubCacheService{reports: map[string][]Report{"configauditreports": {
+		auditReport("daemonset-agent", "c1", "monitoring", "KSV017", "KSV999"),
+		auditReport("deploy-api", "c1", "apps", "KSV017"),
+		auditReport("deploy-web", "c2", "apps"),
+	}}}
+	h := &Handler{cache: cache, crdReg: config.GetGlobalRegistry()}
+
+	coverage := h.computeControlCoverage("", frameworks.NIST80053)
+	if len(coverage) != 2 || coverage[0].Cluster != "c1" || coverage[0].AuditedReports != 2 {
+		t.Fatalf("unexpected coverage %+v", coverage)
+	}
+	control := func(c ClusterControlCoverage, id string) ControlCoverage {
+		for _, control := range c.Controls {
+			if control.ID == id {
+				return control
+			}
+		}
+		t.Fatalf("control %s missing", id)
+		return ControlCoverage{}
+	}
+	ac6 := control(coverage[0], "AC-6")
+	if ac6.Status != ControlFail || strings.Join(ac6.FailedChecks, ",") != "KSV017" || strings.Join(ac6.Resources, ",") != "apps/deploy-api,monitoring/daemonset-agent" {
+		t.Fatalf("c1 AC-6 = %+v", ac6)
+	}
+	if got := control(coverage[0], "SC-6").Status; got != ControlPass {
+		t.Errorf("c1 SC-6 = %s", got)
+	}
+	if got := control(coverage[1], "AC-6").Status; got != ControlPass {
+		t.Errorf("c2 AC-6 = %s", got)
+	}
+	if s := coverage[1].Summary; s.Fail != 0 || s.Pass != len(frameworks.Controls(frameworks.NIST80053)) {
+		t.Errorf("c2 summary = %+v", s)
+	}
+
+	data, err := controlCoverageCSV(coverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1+2*len(frameworks.Controls(frameworks.NIST80053)) {
+		t.Fatalf("expected a row per cluster and control, got %d lines", len(lines))
+	}
+	found := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "c1,nist-800-53,AC-6,Least Privilege,fail,") && strings.HasSuffix(line, ",KSV017,2,apps/deploy-api; monitoring/daemonset-agent") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("AC-6 row missing from %s", data)
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// wantsDedupe reads the dedupe query parameter shared by detail and CVE endpoints.
+func wantsDedupe(r *http.Request) bool {
+	return r.URL.Query().Get("dedupe") == "true"
+}
+
+// vulnerabilityDedupeKey identifies a vulnerability independently of the layer or
+// target it was reported for.
+func vulnerabilityDedupeKey(id, resource, installedVersion string) string {
+	return id + "|" + resource + "|" + installedVersion
+}
+
+// dedupeVulnerabilities collapses raw report vulnerabilities sharing CVE, package and
+// installed version into one entry carrying "occurrences" and the sorted "targets".
+// Entries are copied; the input maps are shared with the cache and stay untouched.
+func dedupeVulnerabilities(raw []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(raw))
+	index := make(map[string]int, len(raw))
+	targets := make(map[int]map[string]bool)
+	for _, v := range raw {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := m["vulnerabilityID"].(string)
+		resource, _ := m["resource"].(string)
+		installed, _ := m["installedVersion"].(string)
+		target, _ := m["target"].(string)
+		key := vulnerabilityDedupeKey(id, resource, installed)
+
+		i, seen := index[key]
+		if !seen {
+			entry := make(map[string]interface{}, len(m)+2)
+			for k, val := range m {
+				entry[k] = val
+			}
+			entry["occurrences"] = 0
+			delete(entry, "target")
+			i = len(result)
+			index[key] = i
+			targets[i] = make(map[string]bool)
+			result = append(result, entry)
+		}
+		entry := result[i].(map[string]interface{})
+		entry["occurrences"] = entry["occurrences"].(int) + 1
+		if target != "" {
+			targets[i][target] = true
+		}
+	}
+	for i, set := range targets {
+		list := make([]string, 0, len(set))
+		for t := range set {
+			list = append(list, t)
+		}
+		sort.Strings(list)
+		result[i].(map[string]interface{})["targets"] = list
+	}
+	return result
+}
+
+// dedupeReportDetail returns a copy of a detail report whose vulnerabilities are
+// deduplicated, with "dedupedSummary" counting each distinct vulnerability once.
+// Reports without vulnerabilities are returned unchanged.
+func dedupeReportDetail(report Report) Report {
+	data, ok := report.Data.(map[string]interface{})
+	if !ok {
+		return report
+	}
+	body, ok := data["report"].(map[string]interface{})
+	if !ok {
+		return report
+	}
+	raw, ok := body["vulnerabilities"].([]interface{})
+	if !ok {
+		return report
+	}
+
+	deduped := dedupeVulnerabilities(raw)
+	summary := map[string]int{"criticalCount": 0, "highCount": 0, "mediumCount": 0, "lowCount": 0, "unknownCount": 0}
+	for _, v := range deduped {
+		severity, _ := v.(map[string]interface{})["severity"].(string)
+		switch strings.ToUpper(severity) {
+		case "CRITICAL":
+			summary["criticalCount"]++
+		case "HIGH":
+			summary["highCount"]++
+		case "MEDIUM":
+			summary["mediumCount"]++
+		case "LOW":
+			summary["lowCount"]++
+		default:
+			summary["unknownCount"]++
+		}
+	}
+
+	newBody := make(map[string]interface{}, len(body)+1)
+	for k, v := range body {
+		newBody[k] = v
+	}
+	newBody["vulnerabilities"] = deduped
+	newBody["dedupedSummary"] = summary
+	newData := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		newData[k] = v
+	}
+	newData["report"] = newBody
+	report.Data = newData
+	return report
+}
+
+// dedupeImageVulnerabilities is dedupeVulnerabilities for the typed CVE views.
+func dedupeImageVulnerabilities(vulns []ImageVulnerability) []ImageVulnerability {
+	result := make([]ImageVulnerability, 0, len(vulns))
+	index := make(map[string]int, len(vulns))
+	for _, v := range vulns {
+		key := vulnerabilityDedupeKey(v.VulnerabilityID, v.Resource, v.InstalledVersion)
+		target := v.Target
+		i, seen := index[key]
+		if !seen {
+			i = len(result)
+			index[key] = i
+			// targets replace the single target of the first occurrence
+			v.Target = ""
+			result = append(result, v)
+		}
+		result[i].Occurrences++
+		if target != "" && !containsString(result[i].Targets, target) {
+			result[i].Targets = append(result[i].Targets, target)
+		}
+	}
+	for i := range result {
+		sort.Strings(result[i].Targets)
+	}
+	return result
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func vuln(id, resource, installed, target, severity string) map[string]interface{} {
+	return map[string]interface{}{
+		"vulnerabilityID":  id,
+		"resource":         resource,
+		"installedVersion": installed,
+		"target":           target,
+		"severity":         severity,
+	}
+}
+
+func TestDedupeReportDetail(t *testing.T) {
+	raw := []interface{}{
+		vuln("CVE-1", "openssl", "1.0", "layer-a", "CRITICAL"),
+		vuln("CVE-1", "openssl", "1.0", "layer-b", "CRITICAL"),
+		vuln("CVE-1", "openssl", "1.0", "layer-a", "CRITICAL"),
+		vuln("CVE-1", "openssl", "1.1", "layer-c", "CRITICAL"),
+		vuln("CVE-2", "zlib", "2.0", "", "LOW"),
+	}
+	report := Report{Data: map[string]interface{}{"report": map[string]interface{}{"vulnerabilities": raw}}}
+
+	deduped := dedupeReportDetail(report)
+	body := deduped.Data.(map[string]interface{})["report"].(map[string]interface{})
+	vulns := body["vulnerabilities"].([]interface{})
+	if len(vulns) != 3 {
+		t.Fatalf("expected 3 distinct vulnerabilities, got %d", len(vulns))
+	}
+	first := vulns[0].(map[string]interface{})
+	if first["occurrences"] != 3 || !reflect.DeepEqual(first["targets"], []string{"layer-a", "layer-b"}) {
+		t.Fatalf("unexpected first entry %+v", first)
+	}
+	summary := body["dedupedSummary"].(map[string]int)
+	if summary["criticalCount"] != 2 || summary["lowCount"] != 1 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if len(report.Data.(map[string]interface{})["report"].(map[string]interface{})["vulnerabilities"].([]interface{})) != 5 {
+		t.Fatal("the cached report must not be modified")
+	}
+	if _, ok := raw[0].(map[string]interface{})["occurrences"]; ok {
+		t.Fatal("input entries must not be modified")
+	}
+}
+
+func TestDedupeImageVulnerabilities(t *testing.T) {
+	vulns := []ImageVulnerability{
+		{VulnerabilityID: "CVE-1", Resource: "openssl", InstalledVersion: "1.0", Target: "b"},
+		{VulnerabilityID: "CVE-1", Resource: "openssl", InstalledVersion: "1.0", Target: "a"},
+		{VulnerabilityID: "CVE-2", Resource: "zlib", InstalledVersion: "2.0"},
+	}
+	got := dedupeImageVulnerabilities(vulns)
+	if len(got) != 2 || got[0].Occurrences != 2 || !reflect.DeepEqual(got[0].Targets, []string{"a", "b"}) || got[0].Target != "" {
+		t.Fatalf("unexpected result %+v", got)
+	}
+	if got[1].Occurrences != 1 || got[1].Targets != nil {
+		t.Fatalf("unexpected single entry %+v", got[1])
+	}
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"trivy-ui/config"
+	"trivy-ui/credentials"
+	"trivy-ui/defectdojo"
+	"trivy-ui/kubernetes"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+var errDefectDojoDisabled = errors.New("DefectDojo is not configured, set DEFECTDOJO_URL")
+
+// DefectDojoPushRequest selects the reports whose findings are pushed to DefectDojo.
+type DefectDojoPushRequest struct {
+	Query store.ExportQuery `json:"query"`
+	// Target overrides the configured product and engagement as product/engagement
+	Target string `json:"target,omitempty"`
+}
+
+// DefectDojoImport is the outcome of reimporting one test.
+type DefectDojoImport struct {
+	Product    string `json:"product"`
+	Engagement string `json:"engagement"`
+	Test       string `json:"test"`
+	Findings   int    `json:"findings"`
+	TestID     int    `json:"testId,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func newDefectDojoClient(cfg *config.Config) (*defectdojo.Client, error) {
+	if cfg.DefectDojoURL == "" {
+		return nil, errDefectDojoDisabled
+	}
+	return defectdojo.New(cfg.DefectDojoURL, func() string {
+		return credentials.Get(credentials.DefectDojoAPIKey)
+	})
+}
+
+func defectDojoMapping(cfg *config.Config) defectdojo.Mapping {
+	return defectdojo.Mapping{
+		ProductType: cfg.DefectDojoProductType,
+		Product:     cfg.DefectDojoProduct,
+		Engagement:  cfg.DefectDojoEngagement,
+		Products:    cfg.DefectDojoProducts,
+	}
+}
+
+// defectDojoScans groups the findings of reports into one scan per product and
+// engagement. Reports of kinds without findings are skipped; clean vulnerability reports
+// still make their scan, so a reimport closes the findings that were fixed.
+func defectDojoScans(reports []Report, reportType string, m defectdojo.Mapping) []defectdojo.Scan {
+	byTarget := make(map[[2]string]*defectdojo.Scan)
+	for _, r := range reports {
+		data, ok := r.Data.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		findings := kubernetes.ExtractFindings(data)
+		if findings == nil {
+			continue
+		}
+		product, engagement := m.Resolve(r.Cluster, r.Namespace)
+		key := [2]string{product, engagement}
+		scan, ok := byTarget[key]
+		if !ok {
+			scan = &defectdojo.Scan{
+				ProductType: m.ProductType,
+				Product:     product,
+				Engagement:  engagement,
+				Test:        "trivy-ui " + reportType,
+				Findings:    []defectdojo.Finding{},
+			}
+			byTarget[key] = scan
+		}
+		for _, f := range findings {
+			scan.Findings = append(scan.Findings, defectDojoFinding(r, f))
+		}
+	}
+
+	scans := make([]defectdojo.Scan, 0, len(byTarget))
+	for _, scan := range byTarget {
+		scans = append(scans, *scan)
+	}
+	sort.Slice(scans, func(i, j int) bool {
+		if scans[i].Product != scans[j].Product {
+			return scans[i].Product < scans[j].Product
+		}
+		return scans[i].Engagement < scans[j].Engagement
+	})
+	return scans
+}
+
+// defectDojoSeverities maps Trivy severities to DefectDojo's; anything else is Info.
+var defectDojoSeverities = map[string]string{
+	"CRITICAL": "Critical",
+	"HIGH":     "High",
+	"MEDIUM":   "Medium",
+	"LOW":      "Low",
+}
+
+func defectDojoFinding(r Report, f kubernetes.Finding) defectdojo.Finding {
+	severity, ok := defectDojoSeverities[strings.ToUpper(f.Severity)]
+	if !ok {
+		severity = "Info"
+	}
+	image := reportImageRef(r)
+	var desc strings.Builder
+	fmt.Fprintf(&desc, "%s in %s %s", f.VulnerabilityID, f.Resource, f.InstalledVersion)
+	if image != "" {
+		fmt.Fprintf(&desc, " of image %s", image)
+	}
+	fmt.Fprintf(&desc, ".\n\nCluster: %s\nNamespace: %s\nReport: %s/%s", r.Cluster, r.Namespace, r.Type, r.Name)
+	if f.Target != "" {
+		fmt.Fprintf(&desc, "\nTarget: %s", f.Target)
+	}
+	fmt.Fprintf(&desc, "\ntrivy-ui: %s", reportDetailPath(r.Cluster, r.Type, r.Namespace, r.Name))
+
+	finding := defectdojo.Finding{
+		Title:            fmt.Sprintf("%s in %s %s", f.VulnerabilityID, f.Resource, f.InstalledVersion),
+		Severity:         severity,
+		Description:      desc.String(),
+		ComponentName:    f.Resource,
+		ComponentVersion: f.InstalledVersion,
+		FilePath:         f.Target,
+		VulnerabilityIDs: []string{f.VulnerabilityID},
+		CVSSv3:           f.CVSSVector,
+		CVSSv3Score:      f.Score,
+		UniqueID:         strings.Join([]string{r.Cluster, r.Namespace, r.Name, f.Resource, f.VulnerabilityID}, "/"),
+		StaticFinding:    true,
+	}
+	if f.FixedVersion != "" {
+		finding.Mitigation = fmt.Sprintf("Upgrade %s to %s.", f.Resource, f.FixedVersion)
+	}
+	return finding
+}
+
+// pushToDefectDojo reimports the findings of the reports selected by q, one test per
+// product and engagement. A failed test does not stop the others; the error sums them up.
+func pushToDefectDojo(ctx context.Context, querySvc QueryService, q store.ExportQuery, target string) ([]DefectDojoImport, error) {
+	cfg := config.Get()
+	client, err := newDefectDojoClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := defectDojoMapping(cfg).WithTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := exportReports(querySvc, q)
+	if err != nil {
+		return nil, err
+	}
+
+	scans := defectDojoScans(reports, q.Type, mapping)
+	results := make([]DefectDojoImport, len(scans))
+	failed := 0
+	for i, scan := range scans {
+		results[i] = DefectDojoImport{
+			Product:    scan.Product,
+			Engagement: scan.Engagement,
+			Test:       scan.Test,
+			Findings:   len(scan.Findings),
+		}
+		testID, err := client.Reimport(ctx, scan)
+		if err != nil {
+			failed++
+			results[i].Error = err.Error()
+			utils.LogWarning("DefectDojo import failed", map[string]interface{}{
+				"product": scan.Product, "engagement": scan.Engagement, "error": err.Error(),
+			})
+			continue
+		}
+		results[i].TestID = testID
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d DefectDojo imports failed: %s", failed, len(scans), firstImportError(results))
+	}
+	return results, nil
+}
+
+func firstImportError(results []DefectDojoImport) string {
+	for _, r := range results {
+		if r.Error != "" {
+			return r.Error
+		}
+	}
+	return ""
+}
+
+// PushToDefectDojo pushes the findings of the selected reports to DefectDojo on demand;
+// with async=true the push is queued as a job instead.
+func (h *Handler) PushToDefectDojo(w http.ResponseWriter, r *http.Request) {
+	var req DefectDojoPushRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	reportKind := h.crdReg.ResolveReport(req.Query.Type)
+	if reportKind == nil {
+		writeError(w, http.StatusBadRequest, "Invalid report type")
+		return
+	}
+	req.Query.Type = reportKind.Name
+	if r.URL.Query().Get("async") == "true" {
+		st := requireStore(w)
+		if st == nil {
+			return
+		}
+		h.enqueueJob(w, r, st, JobRequest{Kind: JobKindDefectDojo, Query: req.Query, Target: req.Target})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), exportRunTimeout)
+	defer cancel()
+	results, err := pushToDefectDojo(ctx, h.querySvc, req.Query, req.Target)
+	switch {
+	case errors.Is(err, errDefectDojoDisabled):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil && results == nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		writeJSON(w, http.StatusBadGateway, Response{Code: CodeError, Message: err.Error(), Data: results})
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    results,
+	})
+}
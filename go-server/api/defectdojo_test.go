@@ -0,0 +1,48 @@
+package api
+
+import (
+	"testing"
+
+	"trivy-ui/defectdojo"
+)
+
+func TestDefectDojoScans(t *testing.T) {
+	withFindings := func(r Report, findings ...map[string]interface{}) Report {
+		items := make([]interface{}, len(findings))
+		for i, f := range findings {
+			items[i] = f
+		}
+		r.Data.(map[string]interface{})["report"].(map[string]interface{})["findings"] = items
+		return r
+	}
+	reports := []Report{
+		withFindings(makeReport("web-1", "prod", "web", "vulnerabilityreports", 1),
+			map[string]interface{}{"vulnerabilityID": "CVE-2024-1", "severity": "CRITICAL", "resource": "openssl", "installedVersion": "3.0.1", "fixedVersion": "3.0.2"}),
+		withFindings(makeReport("web-2", "prod", "web", "vulnerabilityreports", 0),
+			map[string]interface{}{"vulnerabilityID": "CVE-2024-2", "severity": "UNKNOWN", "resource": "zlib", "installedVersion": "1.2"}),
+		// clean report: its scan closes fixed findings
+		withFindings(makeReport("api-1", "prod", "api", "vulnerabilityreports", 0)),
+		// no findings index at all: not a vulnerability report
+		makeReport("audit", "prod", "api", "configauditreports", 0),
+	}
+	m := defectdojo.Mapping{ProductType: "Kubernetes", Product: "{cluster}", Engagement: "{namespace}"}
+
+	scans := defectDojoScans(reports, "vulnerabilityreports", m)
+	if len(scans) != 2 {
+		t.Fatalf("scans = %+v", scans)
+	}
+	api, web := scans[0], scans[1]
+	if api.Engagement != "api" || len(api.Findings) != 0 {
+		t.Errorf("api scan = %+v", api)
+	}
+	if web.Product != "prod" || web.Engagement != "web" || web.Test != "trivy-ui vulnerabilityreports" || len(web.Findings) != 2 {
+		t.Fatalf("web scan = %+v", web)
+	}
+	f := web.Findings[0]
+	if f.Severity != "Critical" || f.Mitigation != "Upgrade openssl to 3.0.2." || f.UniqueID != "prod/web/web-1/openssl/CVE-2024-1" {
+		t.Errorf("finding = %+v", f)
+	}
+	if web.Findings[1].Severity != "Info" || web.Findings[1].Mitigation != "" {
+		t.Errorf("unknown severity finding = %+v", web.Findings[1])
+	}
+}
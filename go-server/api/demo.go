@@ -0,0 +1,436 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+)
+
+// demoNamesTTL is how long the pseudonym table is used before it is rebuilt, so clusters,
+// namespaces and images that appear later are covered within that time.
+const demoNamesTTL = 30 * time.Second
+
+var (
+	demoAdjectives = []string{
+		"amber", "brisk", "calm", "cobalt", "crimson", "dusty", "eager", "fancy",
+		"gentle", "golden", "hidden", "icy", "jolly", "keen", "lively", "lunar",
+		"mellow", "misty", "noble", "olive", "proud", "quiet", "rapid", "rusty",
+		"silent", "silver", "solar", "steady", "swift", "tidy", "vivid", "witty",
+	}
+	demoNouns = []string{
+		"badger", "beacon", "canyon", "cedar", "comet", "coral", "delta", "ember",
+		"falcon", "fjord", "garnet", "glacier", "harbor", "heron", "island", "juniper",
+		"lagoon", "lynx", "maple", "meadow", "nebula", "orchid", "otter", "pebble",
+		"quartz", "raven", "river", "summit", "tundra", "walrus", "willow", "zephyr",
+	}
+	demoServices = []string{"api", "web", "jobs", "data", "edge", "auth", "billing", "search"}
+
+	// demoKeptNamespaces are the namespaces of Kubernetes and common add-ons, which say
+	// nothing about who runs the cluster
+	demoKeptNamespaces = map[string]bool{
+		"default": true, "kube-system": true, "kube-public": true, "kube-node-lease": true,
+		"trivy-system": true, "cert-manager": true, "ingress-nginx": true, "monitoring": true,
+	}
+	// demoPublicRegistries keep their host; only the repository path is replaced
+	demoPublicRegistries = map[string]bool{
+		"docker.io": true, "index.docker.io": true, "ghcr.io": true, "quay.io": true, "gcr.io": true,
+		"registry.k8s.io": true, "mcr.microsoft.com": true, "public.ecr.aws": true,
+	}
+)
+
+// demoTable is one build of the pseudonyms: forward maps real names to pseudonyms and
+// reverse maps them back, for the names clients send in requests.
+type demoTable struct {
+	forward map[string]string
+	reverse map[string]string
+	builtAt time.Time
+}
+
+// demoNames replaces cluster names, namespaces and image repositories in API traffic
+// when DEMO_MODE is on. Pseudonyms are derived from the names with an HMAC keyed by
+// DEMO_SEED, so they stay the same across restarts and replicas.
+type demoNames struct {
+	mu    sync.Mutex
+	table *demoTable
+}
+
+var demo = &demoNames{}
+
+// current returns the table, rebuilding it when it expired.
+func (d *demoNames) current(reg *ClusterRegistry) *demoTable {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.table == nil || time.Since(d.table.builtAt) > demoNamesTTL {
+		d.table = buildDemoTable(config.Get().DemoSeed, reg)
+	}
+	return d.table
+}
+
+// demoPseudonym picks a name from the word lists with the HMAC of the kind and value.
+func demoPseudonym(seed, kind, value string) uint64 {
+	mac := hmac.New(sha256.New, []byte(seed))
+	mac.Write([]byte(kind + "\x00" + value))
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+func demoWords(seed, kind, value string) string {
+	h := demoPseudonym(seed, kind, value)
+	return demoAdjectives[h%uint64(len(demoAdjectives))] + "-" + demoNouns[(h>>8)%uint64(len(demoNouns))]
+}
+
+func demoNamespace(seed, value string) string {
+	h := demoPseudonym(seed, "namespace", value)
+	return demoNouns[h%uint64(len(demoNouns))] + "-" + demoServices[(h>>8)%uint64(len(demoServices))]
+}
+
+// splitImageRepository splits a repository, without tag or digest, into its registry
+// host, empty when it has none, and its path.
+func splitImageRepository(repo string) (host, path string) {
+	if i := strings.Index(repo, "/"); i > 0 {
+		first := repo[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			return first, repo[i+1:]
+		}
+	}
+	return "", repo
+}
+
+// imageRepository strips the tag or digest from an image reference.
+func imageRepository(ref string) string {
+	if i := strings.Index(ref, "@"); i > 0 {
+		return ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i]
+	}
+	return ref
+}
+
+func buildDemoTable(seed string, reg *ClusterRegistry) *demoTable {
+	var clusters, namespaces []string
+	if reg != nil {
+		for name, cc := range reg.All() {
+			clusters = append(clusters, name)
+			cc.mu.RLock()
+			clusters = append(clusters, cc.Aliases...)
+			namespaces = append(namespaces, cc.Namespaces...)
+			cc.mu.RUnlock()
+		}
+	}
+	// cached reports outlive removed clusters
+	clusters = append(clusters, suggestions.clusters()...)
+	namespaces = append(namespaces, suggestions.values(SuggestNamespace)...)
+	var repos []string
+	for _, image := range suggestions.values(SuggestImage) {
+		repos = append(repos, imageRepository(image))
+	}
+
+	t := &demoTable{forward: make(map[string]string), reverse: make(map[string]string), builtAt: time.Now()}
+	// names are added in sorted order, so a collision gets the same suffix on every build
+	add := func(real, pseudonym string) {
+		if real == "" || t.forward[real] != "" {
+			return
+		}
+		name := pseudonym
+		for n := 2; t.reverse[name] != ""; n++ {
+			name = pseudonym + "-" + strconv.Itoa(n)
+		}
+		t.forward[real], t.reverse[name] = name, real
+	}
+	for _, c := range sortedUnique(clusters) {
+		add(c, demoWords(seed, "cluster", c))
+	}
+	for _, ns := range sortedUnique(namespaces) {
+		if !demoKeptNamespaces[ns] {
+			add(ns, demoNamespace(seed, ns))
+		}
+	}
+	for _, repo := range sortedUnique(repos) {
+		host, path := splitImageRepository(repo)
+		if (host == "" || host == "docker.io" || host == "index.docker.io") && strings.HasPrefix(path, "library/") {
+			continue
+		}
+		segments := strings.Split(path, "/")
+		for i, s := range segments {
+			segments[i] = demoWords(seed, "repository", s)
+		}
+		pseudoPath := strings.Join(segments, "/")
+		if host != "" && !demoPublicRegistries[host] {
+			add(host, demoNouns[demoPseudonym(seed, "registry", host)%uint64(len(demoNouns))]+".registry.example")
+			host = t.forward[host]
+		}
+		// reports keep the registry and the path apart, lists join them
+		add(path, pseudoPath)
+		if host != "" {
+			add(repo, host+"/"+t.forward[path])
+		}
+	}
+	return t
+}
+
+func sortedUnique(values []string) []string {
+	values = dedupeStrings(values)
+	sort.Strings(values)
+	return values
+}
+
+// rewrite maps one string through a table: a whole name, a cluster/namespace pair, or
+// the repository of an image reference with a tag or digest.
+func (t *demoTable) rewrite(s string, table map[string]string) string {
+	if v, ok := table[s]; ok {
+		return v
+	}
+	if repo := imageRepository(s); repo != s {
+		if v, ok := table[repo]; ok {
+			return v + s[len(repo):]
+		}
+	}
+	if cluster, namespace, ok := strings.Cut(s, "/"); ok && !strings.Contains(namespace, "/") {
+		c, cok := table[cluster]
+		n, nok := table[namespace]
+		if cok || nok {
+			if !cok {
+				c = cluster
+			}
+			if !nok {
+				n = namespace
+			}
+			return c + "/" + n
+		}
+	}
+	return s
+}
+
+// rewriteJSON rewrites the strings and object keys of a decoded JSON value.
+func (t *demoTable) rewriteJSON(v interface{}, table map[string]string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return t.rewrite(v, table)
+	case []interface{}:
+		for i := range v {
+			v[i] = t.rewriteJSON(v[i], table)
+		}
+		return v
+	case map[string]interface{}:
+		// keys are only names when they all are; objects of other maps keep theirs, so a
+		// namespace called "data" does not rename the field
+		names := true
+		for key := range v {
+			if _, ok := table[key]; !ok && !demoKeptNamespaces[key] {
+				names = false
+				break
+			}
+		}
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if names {
+				key = t.rewrite(key, table)
+			}
+			out[key] = t.rewriteJSON(value, table)
+		}
+		return out
+	}
+	return v
+}
+
+func (t *demoTable) rewriteJSONBytes(data []byte, table map[string]string) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return data, false
+	}
+	out, err := json.Marshal(t.rewriteJSON(v, table))
+	if err != nil {
+		return data, false
+	}
+	if bytes.HasSuffix(data, []byte("\n")) {
+		out = append(out, '\n')
+	}
+	return out, true
+}
+
+// rewriteRequest maps the pseudonyms of a request's path, query and JSON body back to the
+// real names, so links and filters built from anonymized responses keep working.
+func (t *demoTable) rewriteRequest(req *http.Request) {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, s := range segments {
+		if real, ok := t.reverse[s]; ok {
+			segments[i] = real
+		}
+	}
+	req.URL.Path = strings.Join(segments, "/")
+	req.URL.RawPath = ""
+
+	query := req.URL.Query()
+	for key, values := range query {
+		for i, value := range values {
+			parts := strings.Split(value, ",")
+			for j, p := range parts {
+				parts[j] = t.rewrite(p, t.reverse)
+			}
+			values[i] = strings.Join(parts, ",")
+		}
+		query[key] = values
+	}
+	req.URL.RawQuery = query.Encode()
+
+	if req.Body != nil && strings.Contains(req.Header.Get("Content-Type"), "json") {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err == nil {
+			body, _ = t.rewriteJSONBytes(body, t.reverse)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+}
+
+const (
+	demoUndecided = iota
+	// demoBuffered responses (JSON, CSV) are rewritten whole when the handler returns
+	demoBuffered
+	// demoStreamed responses (server-sent events) are rewritten event by event
+	demoStreamed
+	demoPassed
+)
+
+// demoResponseWriter rewrites the real names in a response to their pseudonyms.
+type demoResponseWriter struct {
+	http.ResponseWriter
+	table  *demoTable
+	mode   int
+	status int
+	buf    bytes.Buffer
+}
+
+func (w *demoResponseWriter) WriteHeader(code int) {
+	if w.mode != demoUndecided {
+		return
+	}
+	contentType := w.Header().Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "json"), strings.HasPrefix(contentType, "text/csv"):
+		w.mode, w.status = demoBuffered, code
+		w.Header().Del("Content-Length")
+		return
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		w.mode = demoStreamed
+	default:
+		w.mode = demoPassed
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *demoResponseWriter) Write(b []byte) (int, error) {
+	if w.mode == demoUndecided {
+		w.WriteHeader(http.StatusOK)
+	}
+	switch w.mode {
+	case demoBuffered:
+		return w.buf.Write(b)
+	case demoStreamed:
+		// events are written whole, one per call
+		var out bytes.Buffer
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		scanner.Buffer(make([]byte, 0, 64*1024), len(b)+1)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if data, ok := bytes.CutPrefix(line, []byte("data: ")); ok {
+				data, _ = w.table.rewriteJSONBytes(data, w.table.forward)
+				line = append([]byte("data: "), data...)
+			}
+			out.Write(line)
+			out.WriteByte('\n')
+		}
+		if _, err := w.ResponseWriter.Write(out.Bytes()); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController flush streamed responses.
+func (w *demoResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish writes a buffered response, rewritten.
+func (w *demoResponseWriter) finish() {
+	if w.mode != demoBuffered {
+		return
+	}
+	body := w.buf.Bytes()
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		body = w.table.rewriteCSV(body)
+	} else {
+		body, _ = w.table.rewriteJSONBytes(body, w.table.forward)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(body)
+}
+
+func (t *demoTable) rewriteCSV(data []byte) []byte {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return data
+	}
+	for _, record := range records {
+		for i, field := range record {
+			record[i] = t.rewrite(field, t.forward)
+		}
+	}
+	var out bytes.Buffer
+	cw := csv.NewWriter(&out)
+	cw.WriteAll(records)
+	return out.Bytes()
+}
+
+// demoShareScope maps the names of a share scope to their pseudonyms, or back with
+// reverse. In demo mode share tokens carry pseudonyms, since anyone holding a link can
+// decode its scope.
+func (h *Handler) demoShareScope(scope ShareScope, reverse bool) ShareScope {
+	if !config.Get().DemoMode {
+		return scope
+	}
+	table := demo.current(h.clusterReg)
+	names := table.forward
+	if reverse {
+		names = table.reverse
+	}
+	data, err := json.Marshal(scope)
+	if err != nil {
+		return scope
+	}
+	data, _ = table.rewriteJSONBytes(data, names)
+	var mapped ShareScope
+	if err := json.Unmarshal(data, &mapped); err != nil {
+		return scope
+	}
+	return mapped
+}
+
+// serveDemo serves an API request with the real names of the request and response
+// replaced.
+func (r *Router) serveDemo(w http.ResponseWriter, req *http.Request, serve func(http.ResponseWriter, *http.Request)) {
+	table := demo.current(r.handler.clusterReg)
+	table.rewriteRequest(req)
+	dw := &demoResponseWriter{ResponseWriter: w, table: table}
+	serve(dw, req)
+	dw.finish()
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+)
+
+func TestDemoTable(t *testing.T) {
+	prev := suggestions
+	suggestions = newSuggestIndex()
+	t.Cleanup(func() { suggestions = prev })
+	report := makeReport("replicaset-api", "prod", "payments", "vulnerabilityreports", 1)
+	report.Data.(map[string]interface{})["report"].(map[string]interface{})["artifact"] = map[string]interface{}{"repository": "acme/payments-api", "tag": "1.4"}
+	report.Data.(map[string]interface{})["report"].(map[string]interface{})["registry"] = map[string]interface{}{"server": "registry.acme.internal"}
+	suggestions.set(reportKey("prod", "payments", "vulnerabilityreports", "replicaset-api"), report)
+	nginx := makeReport("replicaset-proxy", "prod", "kube-system", "vulnerabilityreports", 0)
+	nginx.Data.(map[string]interface{})["report"].(map[string]interface{})["artifact"] = map[string]interface{}{"repository": "library/nginx", "tag": "1.25"}
+	suggestions.set(reportKey("prod", "kube-system", "vulnerabilityreports", "replicaset-proxy"), nginx)
+
+	table := buildDemoTable("seed", nil)
+	again := buildDemoTable("seed", nil)
+	if table.forward["prod"] == "" || table.forward["prod"] != again.forward["prod"] {
+		t.Fatalf("expected a stable pseudonym for the cluster, got %q and %q", table.forward["prod"], again.forward["prod"])
+	}
+	if other := buildDemoTable("other", nil); other.forward["payments"] == table.forward["payments"] && other.forward["prod"] == table.forward["prod"] {
+		t.Fatal("expected another seed to give other pseudonyms")
+	}
+	for _, kept := range []string{"kube-system", "library/nginx"} {
+		if _, ok := table.forward[kept]; ok {
+			t.Errorf("expected %s to be kept", kept)
+		}
+	}
+
+	image := table.rewrite("registry.acme.internal/acme/payments-api:1.4", table.forward)
+	if strings.Contains(image, "acme") || !strings.HasSuffix(image, ".registry.example/"+table.forward["acme/payments-api"]+":1.4") {
+		t.Fatalf("unexpected image pseudonym %q", image)
+	}
+	if got := table.rewrite(image, table.reverse); got != "registry.acme.internal/acme/payments-api:1.4" {
+		t.Fatalf("expected the image back, got %q", got)
+	}
+	if got := table.rewrite("prod/payments", table.forward); got != table.forward["prod"]+"/"+table.forward["payments"] {
+		t.Fatalf("unexpected cluster/namespace pseudonym %q", got)
+	}
+
+	out, _ := table.rewriteJSONBytes([]byte(`{"data":{"prod":1},"status":"prod"}`), table.forward)
+	if want := `{"data":{"` + table.forward["prod"] + `":1},"status":"` + table.forward["prod"] + `"}`; string(out) != want {
+		t.Fatalf("got %s, want %s", out, want)
+	}
+}
+
+func TestDemoMode(t *testing.T) {
+	cfg := config.Get()
+	prevMode, prevSuggestions, prevDemo := cfg.DemoMode, suggestions, demo
+	cfg.DemoMode, suggestions, demo = true, newSuggestIndex(), &demoNames{}
+	t.Cleanup(func() { cfg.DemoMode, suggestions, demo = prevMode, prevSuggestions, prevDemo })
+
+	c := useTestCache(t)
+	config.GetGlobalRegistry().Register(config.ReportKind{Name: "vulnerabilityreports", Kind: "VulnerabilityReport", Namespaced: true})
+	report := makeReport("replicaset-api", "prod", "payments", "vulnerabilityreports", 1)
+	report.Data.(map[string]interface{})["report"].(map[string]interface{})["artifact"] = map[string]interface{}{"repository": "acme/payments-api", "tag": "1.4"}
+	c.Set(reportKey("prod", "payments", "vulnerabilityreports", "replicaset-api"), report, time.Hour)
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	reg.RegisterPushed("prod", "v1.30.2", []string{"payments"})
+	router := NewRouter(nil, t.TempDir(), svc, reg, config.GetGlobalRegistry())
+
+	table := demo.current(reg)
+	cluster := table.forward["prod"]
+	if cluster == "" {
+		t.Fatal("expected the cluster to have a pseudonym")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/type/vulnerabilityreports?"+url.Values{"cluster": {cluster}}.Encode(), nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, "replicaset-api") {
+		t.Fatalf("expected the report listed by the cluster pseudonym, got %d %s", rec.Code, body)
+	}
+	for _, real := range []string{`"prod"`, "payments", "acme"} {
+		if strings.Contains(body, real) {
+			t.Errorf("response leaks %s: %s", real, body)
+		}
+	}
+	if !strings.Contains(body, table.forward["payments"]) || !strings.Contains(body, table.forward["acme/payments-api"]) {
+		t.Errorf("expected pseudonyms in %s", body)
+	}
+
+	// share links carry pseudonyms and their views are anonymized too
+	prevSecret := cfg.ShareLinkSecret
+	cfg.ShareLinkSecret = "secret"
+	t.Cleanup(func() { cfg.ShareLinkSecret = prevSecret })
+	share := `{"type":"vulnerabilityreports","cluster":"` + cluster + `","namespaces":["` + table.forward["payments"] + `"]}`
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/share", strings.NewReader(share))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	var created struct {
+		Data ShareLink `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("share link: %d %s", rec.Code, rec.Body.String())
+	}
+	payload, _, _ := strings.Cut(created.Data.Token, ".")
+	if raw, _ := base64.RawURLEncoding.DecodeString(payload); strings.Contains(string(raw), `"prod"`) || strings.Contains(string(raw), "payments") {
+		t.Errorf("share token leaks real names: %s", raw)
+	}
+	for _, path := range []string{created.Data.URL, created.Data.URL + "?format=csv"} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		body = rec.Body.String()
+		if rec.Code != http.StatusOK || !strings.Contains(body, "replicaset-api") {
+			t.Fatalf("shared view %s: %d %s", path, rec.Code, body)
+		}
+		for _, real := range []string{"prod", "payments", "acme"} {
+			if strings.Contains(body, real) {
+				t.Errorf("shared view %s leaks %s: %s", path, real, body)
+			}
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("metrics served in demo mode: %d", rec.Code)
+	}
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"trivy-ui/utils"
+)
+
+// Change event types streamed on /api/v1/events.
+const (
+	EventReportUpdated    = "report.updated"
+	EventReportDeleted    = "report.deleted"
+	EventNamespaceDeleted = "namespace.deleted"
+	// EventClusterConnectivity is sent when a cluster's API server becomes unreachable,
+	// rejects the credentials, or answers again
+	EventClusterConnectivity = "cluster.connectivity"
+	// EventStreamReset tells a reconnecting client that events it missed are no longer
+	// kept, so it has to reload its view
+	EventStreamReset = "stream.reset"
+)
+
+const (
+	// eventBufferSize is how many events a subscriber may fall behind before it is
+	// disconnected; the browser reconnects and reloads its view
+	eventBufferSize = 256
+	// eventReplaySize is how many recent events are kept per cluster for clients that
+	// reconnect with Last-Event-ID
+	eventReplaySize = 1024
+	// eventReplayChunk is how many replayed events are written between flushes
+	eventReplayChunk = 128
+	// eventHeartbeat keeps idle streams open through proxies
+	eventHeartbeat = 30 * time.Second
+)
+
+// ChangeEvent is a change pushed to open views over server-sent events, so they can drop
+// deleted reports without polling.
+type ChangeEvent struct {
+	ID         uint64 `json:"id"`
+	Type       string `json:"type"`
+	Cluster    string `json:"cluster"`
+	Namespace  string `json:"namespace,omitempty"`
+	ReportType string `json:"reportType,omitempty"`
+	Name       string `json:"name,omitempty"`
+	// Reports is how many reports a namespace.deleted event removed
+	Reports int `json:"reports,omitempty"`
+	// State is the new connectivity state of a cluster.connectivity event
+	State string    `json:"state,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// eventHub fans change events out to the connected streams and keeps the recent events
+// of each cluster for replay.
+type eventHub struct {
+	// epoch tells the IDs of this process from those of an earlier one: streamed events
+	// have the ID <epoch>-<ID>, and the numbering starts over at each start
+	epoch       string
+	mu          sync.Mutex
+	nextID      uint64
+	subscribers map[chan ChangeEvent]struct{}
+	recent      map[string]*eventRing
+	// forgotten is the ID of the newest event of a removed cluster
+	forgotten uint64
+}
+
+// eventRing holds the last eventReplaySize events of a cluster, oldest at start.
+type eventRing struct {
+	events []ChangeEvent
+	start  int
+	// evicted is the ID of the newest event that no longer fits
+	evicted uint64
+}
+
+func (r *eventRing) add(e ChangeEvent) {
+	if len(r.events) < eventReplaySize {
+		r.events = append(r.events, e)
+		return
+	}
+	r.evicted = r.events[r.start].ID
+	r.events[r.start] = e
+	r.start = (r.start + 1) % len(r.events)
+}
+
+// since appends the events after id to out, oldest first, and reports whether all of
+// them are still kept.
+func (r *eventRing) since(id uint64, out []ChangeEvent) ([]ChangeEvent, bool) {
+	for i := range r.events {
+		if e := r.events[(r.start+i)%len(r.events)]; e.ID > id {
+			out = append(out, e)
+		}
+	}
+	return out, r.evicted <= id
+}
+
+var changeEvents = newEventHub()
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		subscribers: make(map[chan ChangeEvent]struct{}),
+		recent:      make(map[string]*eventRing),
+	}
+}
+
+// publish numbers the event and hands it to every subscriber. Subscribers whose buffer
+// is full are disconnected rather than silently missing events.
+func (h *eventHub) publish(e ChangeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID++
+	e.ID = h.nextID
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	ring := h.recent[e.Cluster]
+	if ring == nil {
+		ring = &eventRing{}
+		h.recent[e.Cluster] = ring
+	}
+	ring.add(e)
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe returns a channel of the events published from now on and a function that
+// ends the subscription. The channel is closed when the subscriber falls behind.
+func (h *eventHub) subscribe() (<-chan ChangeEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.addSubscriber()
+}
+
+// resume subscribes like subscribe and also returns the events of clusters, or of every
+// cluster when nil, published after lastID. complete is false when some of them are
+// no longer kept, or lastID is of another epoch, from before a restart; head is the ID
+// of the newest event.
+func (h *eventHub) resume(clusters map[string]bool, epoch string, lastID uint64) (missed []ChangeEvent, complete bool, head uint64, events <-chan ChangeEvent, unsubscribe func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	complete = epoch == h.epoch && lastID <= h.nextID && lastID >= h.forgotten
+	for topic, ring := range h.recent {
+		if clusters != nil && !clusters[topic] {
+			continue
+		}
+		var kept bool
+		if missed, kept = ring.since(lastID, missed); !kept {
+			complete = false
+		}
+	}
+	sort.Slice(missed, func(i, j int) bool { return missed[i].ID < missed[j].ID })
+	events, unsubscribe = h.addSubscriber()
+	return missed, complete, h.nextID, events, unsubscribe
+}
+
+// forget drops the events kept for replay of a removed cluster. Clients that have not
+// seen all of them get a stream.reset when they resume.
+func (h *eventHub) forget(cluster string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ring := h.recent[cluster]
+	if ring == nil {
+		return
+	}
+	delete(h.recent, cluster)
+	if n := len(ring.events); n > 0 {
+		h.forgotten = max(h.forgotten, ring.events[(ring.start+n-1)%n].ID)
+	}
+}
+
+// addSubscriber must be called with h.mu held.
+func (h *eventHub) addSubscriber() (<-chan ChangeEvent, func()) {
+	ch := make(chan ChangeEvent, eventBufferSize)
+	h.subscribers[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// lastEventID is the epoch and ID a reconnecting client saw last: the Last-Event-ID
+// header EventSource sends, or the lastEventId parameter for clients that cannot set
+// headers. A bare ID, from before epochs, has no epoch.
+func lastEventID(r *http.Request) (string, uint64, bool) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	var epoch string
+	if i := strings.LastIndexByte(value, '-'); i >= 0 {
+		epoch, value = value[:i], value[i+1:]
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	return epoch, id, err == nil
+}
+
+func writeEvent(w io.Writer, epoch string, e ChangeEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %s-%d\nevent: %s\ndata: %s\n\n", epoch, e.ID, e.Type, data)
+	return err
+}
+
+// StreamEvents streams change events as server-sent events, optionally limited to some
+// clusters with the cluster and tag parameters. A client reconnecting with Last-Event-ID first gets
+// the events it missed, or a stream.reset event when they are no longer kept.
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	cluster := r.URL.Query().Get("cluster")
+	clusters := clusterSet(clusterParam(r))
+	rc := http.NewResponseController(w)
+	var (
+		events      <-chan ChangeEvent
+		unsubscribe func()
+		missed      []ChangeEvent
+		complete    = true
+		head        uint64
+	)
+	hub := changeEvents
+	epoch, lastID, resuming := lastEventID(r)
+	if resuming {
+		missed, complete, head, events, unsubscribe = hub.resume(clusters, epoch, lastID)
+	} else {
+		events, unsubscribe = hub.subscribe()
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// nginx buffers responses unless told otherwise
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		utils.LogWarning("Event stream not supported by response writer", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	if !complete {
+		// the reset carries the newest ID so the next reconnect resumes from here
+		if writeEvent(w, hub.epoch, ChangeEvent{ID: head, Type: EventStreamReset, Cluster: cluster, Time: time.Now()}) != nil || rc.Flush() != nil {
+			return
+		}
+		missed = nil
+	}
+	for i, e := range missed {
+		if err := writeEvent(w, hub.epoch, e); err != nil {
+			return
+		}
+		if (i+1)%eventReplayChunk == 0 || i == len(missed)-1 {
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if clusters != nil && !clusters[e.Cluster] {
+				continue
+			}
+			if err := writeEvent(w, hub.epoch, e); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/kubernetes"
+)
+
+func useTestEventHub(t *testing.T) *eventHub {
+	t.Helper()
+	prev := changeEvents
+	changeEvents = newEventHub()
+	t.Cleanup(func() { changeEvents = prev })
+	return changeEvents
+}
+
+func TestDeleteNamespacePurgesReports(t *testing.T) {
+	c := useTestCache(t)
+	hub := useTestEventHub(t)
+	reg := NewClusterRegistry(&CacheServiceImpl{cache: c})
+	reg.RegisterPushed("prod", "v1.30.2", []string{"payments", "web"})
+	updater := NewCacheUpdater(reg)
+	for _, r := range []struct{ ns, name string }{{"payments", "replicaset-api"}, {"payments", "replicaset-worker"}, {"web", "replicaset-web"}} {
+		updater.SetReport("prod", r.ns, "vulnerabilityreports", r.name, &kubernetes.Report{Status: "High"})
+	}
+	c.Set(namespaceKey("prod", "payments"), Namespace{Cluster: "prod", Name: "payments"}, 0)
+
+	events, unsubscribe := hub.subscribe()
+	defer unsubscribe()
+	updater.DeleteNamespace("prod", "payments")
+
+	if keys := c.NamespaceReportKeys("prod", "payments"); len(keys) != 0 {
+		t.Fatalf("reports left in deleted namespace: %v", keys)
+	}
+	if keys := c.NamespaceReportKeys("prod", "web"); len(keys) != 1 {
+		t.Fatalf("reports of other namespaces should stay: %v", keys)
+	}
+	if _, ok := c.Get(namespaceKey("prod", "payments")); ok {
+		t.Fatal("namespace entry should be removed")
+	}
+	if got := fmt.Sprint(reg.Get("prod").Namespaces); got != "[web]" {
+		t.Fatalf("cluster namespaces = %s", got)
+	}
+
+	var got []string
+	for len(events) > 0 {
+		e := <-events
+		got = append(got, fmt.Sprintf("%d %s %s/%s %d", e.ID, e.Type, e.Namespace, e.Name, e.Reports))
+	}
+	// events 1-3 are the report.updated events of caching the reports
+	want := "[4 report.deleted payments/replicaset-api 0 5 report.deleted payments/replicaset-worker 0 6 namespace.deleted payments/ 2]"
+	if fmt.Sprint(got) != want {
+		t.Fatalf("events = %v, want %s", got, want)
+	}
+}
+
+func TestEventHubDropsSlowSubscribers(t *testing.T) {
+	hub := newEventHub()
+	events, unsubscribe := hub.subscribe()
+	for i := 0; i < eventBufferSize+1; i++ {
+		hub.publish(ChangeEvent{Type: EventReportDeleted})
+	}
+	n := 0
+	for range events {
+		n++
+	}
+	if n != eventBufferSize {
+		t.Fatalf("received %d events before disconnect, want %d", n, eventBufferSize)
+	}
+	unsubscribe()
+}
+
+func TestStreamEvents(t *testing.T) {
+	hub := useTestEventHub(t)
+	h := &Handler{}
+	srv := httptest.NewServer(http.HandlerFunc(h.StreamEvents))
+	defer srv.Close()
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"?cluster=prod", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %s", ct)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		hub.mu.Lock()
+		n := len(hub.subscribers)
+		hub.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream did not subscribe")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	hub.publish(ChangeEvent{Type: EventNamespaceDeleted, Cluster: "staging", Namespace: "web"})
+	hub.publish(ChangeEvent{Type: EventNamespaceDeleted, Cluster: "prod", Namespace: "payments", Reports: 2})
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if lines[0] != "id: "+hub.epoch+"-2" || lines[1] != "event: namespace.deleted" {
+		t.Fatalf("event lines = %q", lines)
+	}
+	var e ChangeEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Cluster != "prod" || e.Namespace != "payments" || e.Reports != 2 {
+		t.Fatalf("event = %+v", e)
+	}
+}
+
+func TestReportUpdatedOnlyOnChange(t *testing.T) {
+	c := useTestCache(t)
+	hub := useTestEventHub(t)
+	updater := NewCacheUpdater(NewClusterRegistry(&CacheServiceImpl{cache: c}))
+	scanned := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	updater.SetReport("prod", "web", "vulnerabilityreports", "replicaset-web", &kubernetes.Report{Status: "High", ScannedAt: scanned})
+	// a resync caches the same scan again
+	updater.SetReport("prod", "web", "vulnerabilityreports", "replicaset-web", &kubernetes.Report{Status: "High", ScannedAt: scanned})
+	updater.SetReport("prod", "web", "vulnerabilityreports", "replicaset-web", &kubernetes.Report{Status: "Low", ScannedAt: scanned.Add(time.Hour)})
+
+	missed, complete, head, _, unsubscribe := hub.resume(clusterSet("prod"), hub.epoch, 0)
+	unsubscribe()
+	if !complete || head != 2 || len(missed) != 2 || missed[0].Type != EventReportUpdated || missed[1].Name != "replicaset-web" {
+		t.Fatalf("events = %+v complete=%v head=%d", missed, complete, head)
+	}
+}
+
+func TestEventHubResume(t *testing.T) {
+	hub := newEventHub()
+	for i := 0; i < 10; i++ {
+		cluster := "prod"
+		if i%2 == 1 {
+			cluster = "staging"
+		}
+		hub.publish(ChangeEvent{Type: EventReportDeleted, Cluster: cluster})
+	}
+
+	missed, complete, head, _, unsubscribe := hub.resume(clusterSet("staging"), hub.epoch, 6)
+	unsubscribe()
+	if got := fmt.Sprint(eventIDs(missed)); !complete || head != 10 || got != "[8 10]" {
+		t.Fatalf("staging after 6: %s complete=%v head=%d", got, complete, head)
+	}
+	missed, _, _, _, unsubscribe = hub.resume(nil, hub.epoch, 7)
+	unsubscribe()
+	if got := fmt.Sprint(eventIDs(missed)); got != "[8 9 10]" {
+		t.Fatalf("all after 7: %s", got)
+	}
+	// an ID from before a restart cannot be resumed from
+	if _, complete, _, _, unsubscribe := hub.resume(nil, hub.epoch, 11); complete {
+		t.Fatal("resume past the newest event should be incomplete")
+	} else {
+		unsubscribe()
+	}
+
+	for i := 0; i < eventReplaySize; i++ {
+		hub.publish(ChangeEvent{Type: EventReportDeleted, Cluster: "prod"})
+	}
+	if _, complete, _, _, unsubscribe := hub.resume(clusterSet("prod"), hub.epoch, 8); complete {
+		t.Fatal("evicted prod events should make the replay incomplete")
+	} else {
+		unsubscribe()
+	}
+	missed, complete, _, _, unsubscribe = hub.resume(clusterSet("staging"), hub.epoch, 8)
+	unsubscribe()
+	if got := fmt.Sprint(eventIDs(missed)); !complete || got != "[10]" {
+		t.Fatalf("staging replay should be unaffected by prod evictions: %s complete=%v", got, complete)
+	}
+}
+
+func eventIDs(events []ChangeEvent) []uint64 {
+	ids := make([]uint64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestStreamEventsReplay(t *testing.T) {
+	hub := useTestEventHub(t)
+	h := &Handler{}
+	srv := httptest.NewServer(http.HandlerFunc(h.StreamEvents))
+	defer srv.Close()
+
+	for i := 0; i < 3; i++ {
+		hub.publish(ChangeEvent{Type: EventReportDeleted, Cluster: "prod", Name: fmt.Sprint("report-", i+1)})
+	}
+	readIDs := func(lastEventID string, n int) []string {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"?cluster=prod", nil)
+		req.Header.Set("Last-Event-ID", lastEventID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		reader := bufio.NewReader(resp.Body)
+		var lines []string
+		for len(lines) < n {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasPrefix(line, "id: ") || strings.HasPrefix(line, "event: ") {
+				lines = append(lines, strings.TrimSpace(line))
+			}
+		}
+		return lines
+	}
+	id := func(n int) string { return fmt.Sprintf("%s-%d", hub.epoch, n) }
+	if got := fmt.Sprint(readIDs(id(1), 4)); got != "[id: "+id(2)+" event: report.deleted id: "+id(3)+" event: report.deleted]" {
+		t.Fatalf("replay = %s", got)
+	}
+	if got := fmt.Sprint(readIDs(id(42), 2)); got != "[id: "+id(3)+" event: stream.reset]" {
+		t.Fatalf("reset = %s", got)
+	}
+	// IDs of an earlier process, with its epoch or from before epochs, cannot be resumed
+	// from even when this one has published as many events
+	for _, stale := range []string{"0-1", "1"} {
+		if got := fmt.Sprint(readIDs(stale, 2)); got != "[id: "+id(3)+" event: stream.reset]" {
+			t.Fatalf("reset after %s = %s", stale, got)
+		}
+	}
+}
+
+func TestEventHubForgetsRemovedClusters(t *testing.T) {
+	hub := useTestEventHub(t)
+	reg := NewClusterRegistry(&CacheServiceImpl{cache: useTestCache(t)})
+	reg.RegisterPushed("prod", "v1.30.2", nil)
+	hub.publish(ChangeEvent{Type: EventReportDeleted, Cluster: "staging"})
+	hub.publish(ChangeEvent{Type: EventReportDeleted, Cluster: "prod"})
+	hub.publish(ChangeEvent{Type: EventReportDeleted, Cluster: "staging"})
+
+	reg.Remove("prod")
+	hub.mu.Lock()
+	_, kept := hub.recent["prod"]
+	hub.mu.Unlock()
+	if kept {
+		t.Fatal("events of a removed cluster should not be kept")
+	}
+	if _, complete, _, _, unsubscribe := hub.resume(nil, hub.epoch, 1); complete {
+		t.Fatal("resume from before a forgotten event should be incomplete")
+	} else {
+		unsubscribe()
+	}
+	missed, complete, _, _, unsubscribe := hub.resume(nil, hub.epoch, 2)
+	unsubscribe()
+	if got := fmt.Sprint(eventIDs(missed)); !complete || got != "[3]" {
+		t.Fatalf("after 2: %s complete=%v", got, complete)
+	}
+}
@@ -0,0 +1,468 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/credentials"
+	"trivy-ui/export"
+	"trivy-ui/schedule"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+
+	exportCheckInterval = 30 * time.Second
+	exportRunTimeout    = 5 * time.Minute
+	exportMaxReports    = 1 << 30
+)
+
+// ExportScheduleView adds the computed next run time to a stored schedule.
+type ExportScheduleView struct {
+	store.ExportSchedule
+	NextRunAt *time.Time `json:"nextRunAt,omitempty"`
+}
+
+// exportsRunning prevents a manual run and a scheduled run of the same export overlapping.
+var exportsRunning sync.Map
+
+// exportSettings resolves the destination credentials for one run, picking up rotations.
+func exportSettings(cfg *config.Config) export.Settings {
+	return export.Settings{
+		SMTPHost:       cfg.SMTPHost,
+		SMTPPort:       cfg.SMTPPort,
+		SMTPUsername:   credentials.Get(credentials.SMTPUsername),
+		SMTPPassword:   credentials.Get(credentials.SMTPPassword),
+		SMTPFrom:       cfg.SMTPFrom,
+		S3Endpoint:     cfg.S3Endpoint,
+		S3Region:       cfg.S3Region,
+		S3AccessKey:    credentials.Get(credentials.AWSAccessKeyID),
+		S3SecretKey:    credentials.Get(credentials.AWSSecretAccessKey),
+		S3SessionToken: credentials.Get(credentials.AWSSessionToken),
+		WebhookSecret:  credentials.Get(credentials.WebhookSecret),
+	}
+}
+
+// nextExportRun is the first cron match after the last run, or after creation for new
+// schedules. Runs missed while trivy-ui was down happen once on startup.
+func nextExportRun(s store.ExportSchedule) time.Time {
+	cron, err := schedule.Parse(s.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	from := s.CreatedAt
+	if s.LastRunAt != nil {
+		from = *s.LastRunAt
+	}
+	return cron.Next(from.In(time.Local))
+}
+
+func exportView(s store.ExportSchedule) ExportScheduleView {
+	view := ExportScheduleView{ExportSchedule: s}
+	if next := nextExportRun(s); s.Enabled && !next.IsZero() {
+		view.NextRunAt = &next
+	}
+	return view
+}
+
+func (h *Handler) validateExportSchedule(s store.ExportSchedule) error {
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.New("name is required")
+	}
+	if _, err := schedule.Parse(s.Cron); err != nil {
+		return fmt.Errorf("invalid cron: %w", err)
+	}
+	// DefectDojo receives findings rather than a file
+	if s.Destination != export.DestinationDefectDojo && s.Format != ExportFormatCSV && s.Format != ExportFormatJSON {
+		return errors.New("format must be csv or json")
+	}
+	if h.crdReg.GetReportByName(s.Query.Type) == nil {
+		return errors.New("invalid report type")
+	}
+	if _, err := parseReportFilter(s.Query.Filter); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	// routed exports go to the targets of their routes
+	if s.Destination == export.DestinationRouted {
+		if getNotificationRoutes().empty() {
+			return errors.New("routed exports need NOTIFICATION_ROUTES_FILE")
+		}
+		return nil
+	}
+	if err := export.ValidateTarget(s.Destination, s.Target); err != nil {
+		return err
+	}
+	if s.Destination == export.DestinationDefectDojo {
+		_, err := newDefectDojoClient(config.Get())
+		return err
+	}
+	// reject destinations the server has no credentials for at save time, not at 6am
+	_, err := export.New(s.Destination, exportSettings(config.Get()))
+	return err
+}
+
+// exportReports returns every report selected by an export query.
+func exportReports(querySvc QueryService, q store.ExportQuery) ([]Report, error) {
+	expr, err := parseReportFilter(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	result := querySvc.ListReports(ReportQuery{
+		Type:           q.Type,
+		Cluster:        q.Cluster,
+		Namespaces:     q.Namespaces,
+		Search:         q.Search,
+		OnlyVulnerable: q.OnlyVulnerable,
+		Filter:         q.Filter,
+		FilterExpr:     expr,
+		Page:           1,
+		PageSize:       exportMaxReports,
+	})
+	return result.Items, nil
+}
+
+// buildExport renders the reports selected by a schedule's query.
+func buildExport(querySvc QueryService, s store.ExportSchedule, now time.Time) (export.File, error) {
+	reports, err := exportReports(querySvc, s.Query)
+	if err != nil {
+		return export.File{}, err
+	}
+	return exportFile(reports, s.Name, s.Format, now)
+}
+
+// exportFile renders reports as a csv or json file named after name and now.
+func exportFile(reports []Report, name, format string, now time.Time) (export.File, error) {
+	summary := exportSummary(reports, name)
+	name = fmt.Sprintf("%s-%s.%s", exportFileSlug(name), now.Format("20060102-1504"), format)
+	if format == ExportFormatJSON {
+		data, err := json.MarshalIndent(withReportLinks(reports), "", "  ")
+		return export.File{Name: name, ContentType: "application/json", Data: data, Summary: summary}, err
+	}
+	data, err := reportsCSV(withReportLinks(reports))
+	return export.File{Name: name, ContentType: "text/csv", Data: data, Summary: summary}, err
+}
+
+// exportSummaryReports is how many of the most critical reports an export summary lists.
+const exportSummaryReports = 5
+
+// exportSummary is the text posted by message destinations such as Slack: the severity
+// totals of an export and its most critical reports.
+func exportSummary(reports []Report, name string) string {
+	var totals SeverityTotals
+	for _, r := range reports {
+		c, hi, m, l := extractSummaryCounts(r)
+		totals.Critical += c
+		totals.High += hi
+		totals.Medium += m
+		totals.Low += l
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*: %d reports, %d critical, %d high, %d medium, %d low",
+		name, len(reports), totals.Critical, totals.High, totals.Medium, totals.Low)
+
+	worst := append([]Report(nil), reports...)
+	sort.SliceStable(worst, func(i, j int) bool {
+		ci, hi, _, _ := extractSummaryCounts(worst[i])
+		cj, hj, _, _ := extractSummaryCounts(worst[j])
+		if ci != cj {
+			return ci > cj
+		}
+		return hi > hj
+	})
+	for _, r := range worst[:min(len(worst), exportSummaryReports)] {
+		c, hi, _, _ := extractSummaryCounts(r)
+		if c+hi == 0 {
+			break
+		}
+		fmt.Fprintf(&b, "\n• %s/%s/%s: %d critical, %d high", r.Cluster, r.Namespace, r.Name, c, hi)
+	}
+	return b.String()
+}
+
+func reportsCSV(reports []Report) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	linkColumns := reportLinkColumns()
+	w.Write(append([]string{"cluster", "namespace", "type", "name", "status", "image", "critical", "high", "medium", "low", "fixable", "scannedAt"}, linkColumns...))
+	for _, r := range reports {
+		c, hi, m, l := extractSummaryCounts(r)
+		scanned := ""
+		if !r.ScannedAt.IsZero() {
+			scanned = r.ScannedAt.UTC().Format(time.RFC3339)
+		}
+		row := []string{r.Cluster, r.Namespace, r.Type, r.Name, r.Status, reportImageRef(r),
+			strconv.Itoa(c), strconv.Itoa(hi), strconv.Itoa(m), strconv.Itoa(l), strconv.Itoa(r.Fixable), scanned}
+		for _, name := range linkColumns {
+			row = append(row, r.Links[name])
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func exportFileSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, strings.TrimSpace(name))
+	if slug == "" {
+		return "export"
+	}
+	return slug
+}
+
+// runExport builds and delivers one export and records the outcome.
+func runExport(ctx context.Context, st *store.Store, querySvc QueryService, s store.ExportSchedule) error {
+	if _, running := exportsRunning.LoadOrStore(s.ID, true); running {
+		return errors.New("export is already running")
+	}
+	defer exportsRunning.Delete(s.ID)
+
+	now := time.Now()
+	err := func() error {
+		if s.Destination == export.DestinationDefectDojo {
+			_, err := pushToDefectDojo(ctx, querySvc, s.Query, s.Target)
+			return err
+		}
+		if s.Destination == export.DestinationRouted {
+			return deliverRouted(ctx, querySvc, s, now)
+		}
+		deliverer, err := export.New(s.Destination, exportSettings(config.Get()))
+		if err != nil {
+			return err
+		}
+		file, err := buildExport(querySvc, s, now)
+		if err != nil {
+			return err
+		}
+		return deliverer.Deliver(ctx, s.Target, file)
+	}()
+
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+		utils.LogWarning("Scheduled export failed", map[string]interface{}{"id": s.ID, "name": s.Name, "error": errMsg})
+	} else {
+		utils.LogInfo("Scheduled export delivered", map[string]interface{}{"id": s.ID, "name": s.Name, "destination": s.Destination})
+	}
+	// record the outcome even when the caller went away meanwhile
+	recCtx, cancel := storeCallContext(context.WithoutCancel(ctx))
+	defer cancel()
+	if recErr := st.RecordExportRun(recCtx, s.ID, now, errMsg); recErr != nil {
+		utils.LogWarning("Failed to record export run", map[string]interface{}{"id": s.ID, "error": recErr.Error()})
+	}
+	return err
+}
+
+// loadMuteWindows reads the mute windows for one scheduler pass and purges ad-hoc windows
+// that ended long ago. When they cannot be read, exports run unmuted rather than not at all.
+func loadMuteWindows(ctx context.Context, st *store.Store, now time.Time) []store.MuteWindow {
+	dbCtx, cancel := storeCallContext(ctx)
+	defer cancel()
+	if _, err := st.DeleteExpiredMuteWindows(dbCtx, now.Add(-muteWindowRetention)); err != nil {
+		utils.LogWarning("Failed to purge expired mute windows", map[string]interface{}{"error": err.Error()})
+	}
+	windows, err := st.ListMuteWindows(dbCtx)
+	if err != nil {
+		utils.LogWarning("Failed to load mute windows", map[string]interface{}{"error": err.Error()})
+	}
+	return windows
+}
+
+// skipMutedExport records a due run as muted, so it is not delivered when the window closes.
+func skipMutedExport(ctx context.Context, st *store.Store, s store.ExportSchedule, m store.MuteWindow, until, now time.Time) {
+	utils.LogInfo("Scheduled export muted", map[string]interface{}{
+		"id": s.ID, "name": s.Name, "muteWindow": m.ID, "reason": m.Reason, "until": until.Format(time.RFC3339),
+	})
+	recCtx, cancel := storeCallContext(ctx)
+	defer cancel()
+	if err := st.RecordExportMuted(recCtx, s.ID, now); err != nil {
+		utils.LogWarning("Failed to record muted export", map[string]interface{}{"id": s.ID, "error": err.Error()})
+	}
+}
+
+// StartExportScheduler runs due export schedules until ctx is cancelled; runs due while a
+// mute window covers the schedule's cluster are skipped.
+func StartExportScheduler(ctx context.Context, cache CacheService) {
+	querySvc := NewQueryService(cache)
+	go func() {
+		ticker := time.NewTicker(exportCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			st := store.Get()
+			if st == nil {
+				continue
+			}
+			listCtx, cancel := storeCallContext(ctx)
+			schedules, err := st.ListExportSchedules(listCtx)
+			cancel()
+			if err != nil {
+				utils.LogWarning("Failed to load export schedules", map[string]interface{}{"error": err.Error()})
+				continue
+			}
+			now := time.Now()
+			mutes := loadMuteWindows(ctx, st, now)
+			for _, s := range schedules {
+				if next := nextExportRun(s); !s.Enabled || next.IsZero() || next.After(now) {
+					continue
+				}
+				if m, until, muted := activeMute(mutes, s.Query.Cluster, now); muted {
+					skipMutedExport(ctx, st, s, m, until, now)
+					continue
+				}
+				runCtx, cancel := context.WithTimeout(ctx, exportRunTimeout)
+				runExport(runCtx, st, querySvc, s)
+				cancel()
+			}
+		}
+	}()
+}
+
+func (h *Handler) ListExportSchedules(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	schedules, err := st.ListExportSchedules(ctx)
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		utils.LogWarning("Failed to list export schedules", map[string]interface{}{"error": err.Error()})
+		writeError(w, http.StatusInternalServerError, "Failed to list export schedules")
+		return
+	}
+	views := make([]ExportScheduleView, len(schedules))
+	for i, s := range schedules {
+		views[i] = exportView(s)
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    views,
+	})
+}
+
+func (h *Handler) CreateExportSchedule(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	sched := store.ExportSchedule{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&sched); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := h.validateExportSchedule(sched); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	created, err := st.CreateExportSchedule(ctx, sched)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    exportView(created),
+	})
+}
+
+// ExportScheduleByID serves GET, PUT and DELETE on /api/v1/export-schedules/{id} and
+// POST on /api/v1/export-schedules/{id}/run, which queues the run as a job with async=true.
+func (h *Handler) ExportScheduleByID(w http.ResponseWriter, r *http.Request, idStr, action string) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid export schedule id")
+		return
+	}
+	dbCtx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	existing, err := st.GetExportSchedule(dbCtx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "Export schedule not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	switch {
+	case action == "run" && r.URL.Query().Get("async") == "true":
+		h.enqueueJob(w, r, st, JobRequest{Kind: JobKindExportSchedule, ScheduleID: id})
+		return
+	case action == "run":
+		ctx, cancel := context.WithTimeout(r.Context(), exportRunTimeout)
+		defer cancel()
+		if err := runExport(ctx, st, h.querySvc, existing); err != nil {
+			writeError(w, http.StatusBadGateway, "Export failed: "+err.Error())
+			return
+		}
+		reloadCtx, cancel := storeCallContext(r.Context())
+		defer cancel()
+		existing, _ = st.GetExportSchedule(reloadCtx, id)
+	case r.Method == http.MethodPut:
+		sched := existing
+		if err := json.NewDecoder(r.Body).Decode(&sched); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		sched.ID = id
+		if err := h.validateExportSchedule(sched); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if existing, err = st.UpdateExportSchedule(dbCtx, sched); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	case r.Method == http.MethodDelete:
+		if err := st.DeleteExportSchedule(dbCtx, id); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    exportView(existing),
+	})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/store"
+)
+
+func TestRunExportDeliversCSV(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	var body, contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body, contentType = string(b), r.Header.Get("Content-Type")
+	}))
+	defer srv.Close()
+
+	cache := &stubCacheService{reports: map[string][]Report{
+		"exporttestreports": {
+			makeReport("app-a", "prod", "payments", "exporttestreports", 2),
+			makeReport("app-b", "prod", "payments", "exporttestreports", 0),
+		},
+	}}
+	sched, err := st.CreateExportSchedule(t.Context(), store.ExportSchedule{
+		Name: "Weekly Compliance", Cron: "@weekly", Format: ExportFormatCSV, Enabled: true,
+		Query:       store.ExportQuery{Type: "exporttestreports", Filter: "critical > 0"},
+		Destination: "webhook", Target: srv.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runExport(context.Background(), st, NewQueryService(cache), sched); err != nil {
+		t.Fatalf("runExport: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if contentType != "text/csv" || len(lines) != 2 || !strings.HasPrefix(lines[1], "prod,payments,exporttestreports,app-a,") {
+		t.Fatalf("unexpected export %q: %q", contentType, body)
+	}
+
+	got, _ := st.GetExportSchedule(t.Context(), sched.ID)
+	if got.LastStatus != store.ExportStatusSuccess || got.LastRunAt == nil {
+		t.Errorf("expected successful run to be recorded, got %+v", got)
+	}
+}
+
+func TestNextExportRun(t *testing.T) {
+	created := time.Date(2026, 3, 4, 10, 17, 0, 0, time.Local)
+	s := store.ExportSchedule{Cron: "0 6 * * *", CreatedAt: created}
+	if got, want := nextExportRun(s), time.Date(2026, 3, 5, 6, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("first run %v, want %v", got, want)
+	}
+	last := time.Date(2026, 3, 5, 6, 0, 5, 0, time.Local)
+	s.LastRunAt = &last
+	if got, want := nextExportRun(s), time.Date(2026, 3, 6, 6, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("next run %v, want %v", got, want)
+	}
+}
+
+func TestExportFileSlug(t *testing.T) {
+	if got := exportFileSlug("Weekly Compliance / PCI"); got != "weekly-compliance---pci" {
+		t.Errorf("got %q", got)
+	}
+	if got := exportFileSlug("  "); got != "export" {
+		t.Errorf("got %q", got)
+	}
+}
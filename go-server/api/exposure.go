@@ -0,0 +1,55 @@
+package api
+
+import "trivy-ui/kubernetes"
+
+// Exposure multiplier weights: a privileged container is as good as the node, host
+// namespaces expose node networking and processes, and root eases container escapes.
+const (
+	exposureWeightPrivileged    = 1.0
+	exposureWeightHostNamespace = 0.5
+	exposureWeightRunAsRoot     = 0.25
+)
+
+// ReportExposure is the security context of the workload a vulnerability report belongs to.
+type ReportExposure struct {
+	kubernetes.Exposure
+	// Exposed is set when any risky setting is present
+	Exposed bool `json:"exposed"`
+	// Multiplier weighs the report's severity by its exposure; 1 means no risky settings
+	Multiplier float64 `json:"multiplier"`
+}
+
+func newReportExposure(e kubernetes.Exposure) *ReportExposure {
+	multiplier := 1.0
+	if e.Privileged {
+		multiplier += exposureWeightPrivileged
+	}
+	if e.HostNetwork || e.HostPID || e.HostIPC {
+		multiplier += exposureWeightHostNamespace
+	}
+	if e.RunAsRoot {
+		multiplier += exposureWeightRunAsRoot
+	}
+	return &ReportExposure{Exposure: e, Exposed: e.Exposed(), Multiplier: multiplier}
+}
+
+// reportExposure correlates a report with its workload's security context, or returns nil
+// when the workload is unknown.
+func reportExposure(r Report) *ReportExposure {
+	kind, name := reportResource(r)
+	w, ok := workloadFor(r.Cluster, kind, r.Namespace, name)
+	if !ok {
+		return nil
+	}
+	return newReportExposure(w.Exposure)
+}
+
+// exposureRank orders reports by severity weighted with their exposure multiplier, so
+// vulnerable and privileged workloads come first.
+func exposureRank(r Report) float64 {
+	rank := float64(severityRank[newReportRecord(r).effectiveSeverity()])
+	if r.Exposure != nil {
+		rank *= r.Exposure.Multiplier
+	}
+	return rank
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"testing"
+
+	"trivy-ui/kubernetes"
+)
+
+func TestNewReportExposure(t *testing.T) {
+	if e := newReportExposure(kubernetes.Exposure{}); e.Exposed || e.Multiplier != 1 {
+		t.Fatalf("unexpected exposure %+v", e)
+	}
+	e := newReportExposure(kubernetes.Exposure{Privileged: true, HostNetwork: true, HostPID: true, RunAsRoot: true})
+	if !e.Exposed || e.Multiplier != 2.75 {
+		t.Fatalf("expected host namespaces to count once, got %+v", e)
+	}
+}
+
+func TestSortReports_Exposure(t *testing.T) {
+	high := makeReport("high-privileged", "c1", "apps", "vulnerabilityreports", 0)
+	high.Data.(map[string]interface{})["report"].(map[string]interface{})["summary"] = map[string]interface{}{"highCount": float64(1)}
+	high.Exposure = newReportExposure(kubernetes.Exposure{Privileged: true, RunAsRoot: true})
+	critical := makeReport("critical-restricted", "c1", "apps", "vulnerabilityreports", 1)
+	critical.Exposure = newReportExposure(kubernetes.Exposure{})
+	clean := makeReport("clean-privileged", "c1", "apps", "vulnerabilityreports", 0)
+	clean.Exposure = newReportExposure(kubernetes.Exposure{Privileged: true})
+
+	sorted := sortReports([]Report{clean, critical, high}, "-exposure")
+	if sorted[0].Name != "high-privileged" || sorted[1].Name != "critical-restricted" || sorted[2].Name != "clean-privileged" {
+		t.Fatalf("unexpected order %s, %s, %s", sorted[0].Name, sorted[1].Name, sorted[2].Name)
+	}
+
+	expr, err := parseReportFilter("privileged = true and vulnerable = true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var matched []string
+	for _, r := range sorted {
+		if expr.Eval(newReportRecord(r)) {
+			matched = append(matched, r.Name)
+		}
+	}
+	if len(matched) != 1 || matched[0] != "high-privileged" {
+		t.Fatalf("unexpected matches %v", matched)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// clusterFanoutLimit bounds how many clusters a live fan-out queries at once, so a large
+// fleet does not open a connection to every API server in the same instant.
+const clusterFanoutLimit = 8
+
+// fanOutClusters calls fn for every cluster concurrently, at most limit at a time. Each
+// call gets its own timeout on top of ctx, so one slow API server cannot hold up the
+// others past it; a timeout of zero leaves only ctx. A failing cluster does not cancel
+// the rest. The errors are returned by cluster name, nil when every call succeeded.
+func fanOutClusters(ctx context.Context, clients map[string]*ClusterClient, limit int, timeout time.Duration, fn func(ctx context.Context, name string, cc *ClusterClient) error) map[string]error {
+	var (
+		g    errgroup.Group
+		mu   sync.Mutex
+		errs map[string]error
+	)
+	if limit > 0 {
+		g.SetLimit(limit)
+	}
+	for name, cc := range clients {
+		if ctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			callCtx, cancel := ctx, context.CancelFunc(func() {})
+			if timeout > 0 {
+				callCtx, cancel = context.WithTimeout(ctx, timeout)
+			}
+			defer cancel()
+			err := ctx.Err()
+			if err == nil {
+				err = fn(callCtx, name, cc)
+			}
+			if err != nil {
+				mu.Lock()
+				if errs == nil {
+					errs = make(map[string]error)
+				}
+				errs[name] = err
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	return errs
+}
+
+// joinClusterErrors combines the errors of a fan-out, prefixed with their cluster and in
+// name order, into one error.
+func joinClusterErrors(errs map[string]error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	joined := make([]error, 0, len(names))
+	for _, name := range names {
+		joined = append(joined, fmt.Errorf("cluster %s: %w", name, errs[name]))
+	}
+	return errors.Join(joined...)
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"trivy-ui/kubernetes"
+)
+
+func testClients(n int) map[string]*ClusterClient {
+	clients := make(map[string]*ClusterClient, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("c%02d", i)
+		clients[name] = &ClusterClient{Name: name}
+	}
+	return clients
+}
+
+func TestFanOutClustersBoundsParallelism(t *testing.T) {
+	var running, peak, calls atomic.Int32
+	errs := fanOutClusters(t.Context(), testClients(20), 4, 0, func(ctx context.Context, name string, cc *ClusterClient) error {
+		calls.Add(1)
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("errs = %v", errs)
+	}
+	if calls.Load() != 20 {
+		t.Fatalf("calls = %d, want 20", calls.Load())
+	}
+	if p := peak.Load(); p > 4 || p < 2 {
+		t.Fatalf("peak concurrency = %d, want 2..4", p)
+	}
+}
+
+func TestFanOutClustersPerClusterTimeout(t *testing.T) {
+	errBroken := errors.New("connection refused")
+	errs := fanOutClusters(t.Context(), testClients(3), 2, 20*time.Millisecond, func(ctx context.Context, name string, cc *ClusterClient) error {
+		switch name {
+		case "c00":
+			<-ctx.Done()
+			return ctx.Err()
+		case "c01":
+			return errBroken
+		}
+		return nil
+	})
+	if len(errs) != 2 || !errors.Is(errs["c00"], context.DeadlineExceeded) || errs["c01"] != errBroken {
+		t.Fatalf("errs = %v", errs)
+	}
+
+	joined := joinClusterErrors(errs)
+	if !errors.Is(joined, errBroken) || joined.Error() != "cluster c00: context deadline exceeded\ncluster c01: connection refused" {
+		t.Fatalf("joined = %q", joined)
+	}
+	if joinClusterErrors(nil) != nil {
+		t.Fatal("no errors should join to nil")
+	}
+}
+
+func TestFanOutClustersStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	var calls atomic.Int32
+	fanOutClusters(ctx, testClients(5), 2, time.Second, func(context.Context, string, *ClusterClient) error {
+		calls.Add(1)
+		return nil
+	})
+	if calls.Load() != 0 {
+		t.Fatalf("calls = %d after cancel, want 0", calls.Load())
+	}
+}
+
+// failingSource is a report source whose namespace listing fails once fail is set.
+type failingSource struct {
+	*kubernetes.MemorySource
+	fail atomic.Bool
+}
+
+func (s *failingSource) GetNamespaces(ctx context.Context) ([]string, error) {
+	if s.fail.Load() {
+		return nil, errors.New("unauthorized")
+	}
+	return s.MemorySource.GetNamespaces(ctx)
+}
+
+func TestGetClustersRefreshFansOut(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	good := kubernetes.NewMemorySource()
+	good.AddNamespace("web")
+	if err := reg.SetSource("good", good); err != nil {
+		t.Fatal(err)
+	}
+	bad := &failingSource{MemorySource: kubernetes.NewMemorySource()}
+	if err := reg.SetSource("bad", bad); err != nil {
+		t.Fatal(err)
+	}
+	bad.fail.Store(true)
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), nil)
+
+	rec := httptest.NewRecorder()
+	h.GetClusters(rec, httptest.NewRequest(http.MethodGet, "/api/clusters?refresh=1", nil))
+	var resp struct {
+		Data []Cluster `json:"data"`
+	}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &resp) != nil {
+		t.Fatalf("clusters: %d %s", rec.Code, rec.Body.String())
+	}
+	refreshErrors := make(map[string]string)
+	for _, cl := range resp.Data {
+		refreshErrors[cl.Name] = cl.RefreshError
+	}
+	if len(refreshErrors) != 2 || refreshErrors["good"] != "" || refreshErrors["bad"] != "unauthorized" {
+		t.Fatalf("refresh errors = %v", refreshErrors)
+	}
+	c.cache.Wait()
+	if _, ok := svc.Get(namespaceKey("good", "web")); !ok {
+		t.Fatal("refreshed namespace not cached")
+	}
+}
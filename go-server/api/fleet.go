@@ -0,0 +1,249 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Risk weights per severity used to rank clusters in the fleet view.
+const (
+	riskWeightCritical = 10
+	riskWeightHigh     = 5
+	riskWeightMedium   = 2
+	riskWeightLow      = 1
+)
+
+type KindTotals struct {
+	Reports           int            `json:"reports"`
+	VulnerableReports int            `json:"vulnerableReports"`
+	Severity          SeverityTotals `json:"severity"`
+}
+
+type FleetClusterSummary struct {
+	Name      string `json:"name"`
+	RiskScore int    `json:"riskScore"`
+	// BenchmarkScore is unset for clusters without compliance or config audit reports
+	BenchmarkScore *BenchmarkScore `json:"benchmarkScore,omitempty"`
+	KindTotals
+}
+
+type FleetSummary struct {
+	TotalClusters        int                   `json:"totalClusters"`
+	ClustersWithCritical int                   `json:"clustersWithCritical"`
+	TotalReports         int                   `json:"totalReports"`
+	VulnerableReports    int                   `json:"vulnerableReports"`
+	Severity             SeverityTotals        `json:"severity"`
+	WorstClusters        []FleetClusterSummary `json:"worstClusters"`
+	Kinds                map[string]KindTotals `json:"kinds"`
+	// BenchmarkScore is computed over the reports of all clusters, so larger clusters weigh more
+	BenchmarkScore *BenchmarkScore `json:"benchmarkScore,omitempty"`
+}
+
+func riskScore(s SeverityTotals) int {
+	return s.Critical*riskWeightCritical + s.High*riskWeightHigh + s.Medium*riskWeightMedium + s.Low*riskWeightLow
+}
+
+func (t *KindTotals) add(s SeverityTotals, vulnerable bool, sign int) {
+	t.Reports += sign
+	if vulnerable {
+		t.VulnerableReports += sign
+	}
+	t.Severity.Critical += sign * s.Critical
+	t.Severity.High += sign * s.High
+	t.Severity.Medium += sign * s.Medium
+	t.Severity.Low += sign * s.Low
+}
+
+func (t *KindTotals) merge(o KindTotals) {
+	t.Reports += o.Reports
+	t.VulnerableReports += o.VulnerableReports
+	t.Severity.Critical += o.Severity.Critical
+	t.Severity.High += o.Severity.High
+	t.Severity.Medium += o.Severity.Medium
+	t.Severity.Low += o.Severity.Low
+}
+
+type fleetContribution struct {
+	cluster    string
+	reportType string
+	severity   SeverityTotals
+	vulnerable bool
+	benchmark  benchmarkTotals
+}
+
+type fleetClusterTotals struct {
+	KindTotals
+	kinds     map[string]*KindTotals
+	benchmark benchmarkTotals
+}
+
+// fleetAggregator keeps per-cluster and per-kind totals up to date as report entries are
+// written to and removed from the cache, so the fleet summary never scans all reports.
+type fleetAggregator struct {
+	mu       sync.RWMutex
+	reports  map[string]fleetContribution
+	clusters map[string]*fleetClusterTotals
+}
+
+var fleet = newFleetAggregator()
+
+func newFleetAggregator() *fleetAggregator {
+	return &fleetAggregator{
+		reports:  make(map[string]fleetContribution),
+		clusters: make(map[string]*fleetClusterTotals),
+	}
+}
+
+func (f *fleetAggregator) set(key string, value interface{}) {
+	report, ok := convertCacheValue[Report](value)
+	if !ok {
+		return
+	}
+	c, h, m, l := extractSummaryCounts(report)
+	contrib := fleetContribution{
+		cluster:    report.Cluster,
+		reportType: report.Type,
+		severity:   SeverityTotals{Critical: c, High: h, Medium: m, Low: l},
+		vulnerable: c+h+m+l > 0,
+	}
+	if contrib.cluster == "" || contrib.reportType == "" {
+		if cluster, _, reportType, _, ok := parseReportCacheKey(key); ok {
+			contrib.cluster, contrib.reportType = cluster, reportType
+		}
+	}
+	contrib.benchmark = benchmarkContribution(report, contrib.reportType)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if old, ok := f.reports[key]; ok {
+		f.apply(old, -1)
+	}
+	f.reports[key] = contrib
+	f.apply(contrib, 1)
+}
+
+func (f *fleetAggregator) remove(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if old, ok := f.reports[key]; ok {
+		f.apply(old, -1)
+		delete(f.reports, key)
+	}
+}
+
+func (f *fleetAggregator) apply(c fleetContribution, sign int) {
+	totals := f.clusters[c.cluster]
+	if totals == nil {
+		totals = &fleetClusterTotals{kinds: make(map[string]*KindTotals)}
+		f.clusters[c.cluster] = totals
+	}
+	totals.add(c.severity, c.vulnerable, sign)
+	totals.benchmark.add(c.benchmark, sign)
+	kind := totals.kinds[c.reportType]
+	if kind == nil {
+		kind = &KindTotals{}
+		totals.kinds[c.reportType] = kind
+	}
+	kind.add(c.severity, c.vulnerable, sign)
+
+	if kind.Reports == 0 {
+		delete(totals.kinds, c.reportType)
+	}
+	if totals.Reports == 0 {
+		delete(f.clusters, c.cluster)
+	}
+}
+
+// summary builds the fleet view; knownClusters adds clusters that currently have no
+// reports, and a non-nil selected limits the view to those clusters.
+func (f *fleetAggregator) summary(knownClusters []string, selected map[string]bool, limit int) FleetSummary {
+	s := FleetSummary{
+		WorstClusters: []FleetClusterSummary{},
+		Kinds:         make(map[string]KindTotals),
+	}
+
+	var fleetTotals KindTotals
+	var fleetBenchmark benchmarkTotals
+	f.mu.RLock()
+	seen := make(map[string]bool, len(f.clusters))
+	for name, totals := range f.clusters {
+		if selected != nil && !selected[name] {
+			continue
+		}
+		seen[name] = true
+		fleetTotals.merge(totals.KindTotals)
+		fleetBenchmark.add(totals.benchmark, 1)
+		if totals.Severity.Critical > 0 {
+			s.ClustersWithCritical++
+		}
+		s.WorstClusters = append(s.WorstClusters, FleetClusterSummary{
+			Name:           name,
+			RiskScore:      riskScore(totals.Severity),
+			BenchmarkScore: totals.benchmark.score(),
+			KindTotals:     totals.KindTotals,
+		})
+		for kindName, kind := range totals.kinds {
+			k := s.Kinds[kindName]
+			k.merge(*kind)
+			s.Kinds[kindName] = k
+		}
+	}
+	f.mu.RUnlock()
+
+	s.TotalReports = fleetTotals.Reports
+	s.VulnerableReports = fleetTotals.VulnerableReports
+	s.Severity = fleetTotals.Severity
+	s.BenchmarkScore = fleetBenchmark.score()
+	s.TotalClusters = len(seen)
+	for _, name := range knownClusters {
+		if !seen[name] {
+			seen[name] = true
+			s.TotalClusters++
+		}
+	}
+
+	sort.Slice(s.WorstClusters, func(i, j int) bool {
+		if s.WorstClusters[i].RiskScore != s.WorstClusters[j].RiskScore {
+			return s.WorstClusters[i].RiskScore > s.WorstClusters[j].RiskScore
+		}
+		return s.WorstClusters[i].Name < s.WorstClusters[j].Name
+	})
+	if limit > 0 && len(s.WorstClusters) > limit {
+		s.WorstClusters = s.WorstClusters[:limit]
+	}
+	return s
+}
+
+// benchmarkScore returns the benchmark score of one cluster, or nil when it has none.
+func (f *fleetAggregator) benchmarkScore(cluster string) *BenchmarkScore {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if totals := f.clusters[cluster]; totals != nil {
+		return totals.benchmark.score()
+	}
+	return nil
+}
+
+// GetFleetSummary aggregates all clusters into one view from the pre-computed totals.
+func (h *Handler) GetFleetSummary(w http.ResponseWriter, r *http.Request) {
+	limit := 10
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	selected := clusterSet(clusterParam(r))
+	var clusters []string
+	for name := range h.clusterReg.All() {
+		if selected == nil || selected[name] {
+			clusters = append(clusters, name)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    fleet.summary(clusters, selected, limit),
+	})
+}
@@ -0,0 +1,75 @@
+package api
+
+import "testing"
+
+func TestFleetAggregator_IncrementalUpdates(t *testing.T) {
+	f := newFleetAggregator()
+	f.set("report:prod:ns:vulnerabilityreports:a", makeReport("a", "prod", "ns", "vulnerabilityreports", 2))
+	f.set("report:prod:ns:vulnerabilityreports:b", makeReport("b", "prod", "ns", "vulnerabilityreports", 0))
+	f.set("report:dev:ns:vulnerabilityreports:c", makeReport("c", "dev", "ns", "vulnerabilityreports", 1))
+
+	// re-setting a report replaces its previous contribution
+	f.set("report:dev:ns:vulnerabilityreports:c", makeReport("c", "dev", "ns", "vulnerabilityreports", 5))
+
+	s := f.summary([]string{"prod", "dev", "idle"}, nil, 10)
+	if s.TotalClusters != 3 || s.ClustersWithCritical != 2 || s.TotalReports != 3 || s.Severity.Critical != 7 {
+		t.Fatalf("unexpected summary %+v", s)
+	}
+	if s.WorstClusters[0].Name != "dev" || s.WorstClusters[0].RiskScore != 5*riskWeightCritical {
+		t.Fatalf("unexpected ranking %+v", s.WorstClusters)
+	}
+	if k := s.Kinds["vulnerabilityreports"]; k.Reports != 3 || k.VulnerableReports != 2 {
+		t.Fatalf("unexpected kind totals %+v", k)
+	}
+
+	s = f.summary([]string{"prod"}, map[string]bool{"prod": true}, 10)
+	if s.TotalClusters != 1 || s.TotalReports != 2 || s.Severity.Critical != 2 || len(s.WorstClusters) != 1 {
+		t.Fatalf("unexpected summary of selected clusters %+v", s)
+	}
+
+	f.remove("report:dev:ns:vulnerabilityreports:c")
+	s = f.summary(nil, nil, 10)
+	if s.TotalClusters != 1 || s.Severity.Critical != 2 || len(s.WorstClusters) != 1 {
+		t.Fatalf("unexpected summary after removal %+v", s)
+	}
+}
+
+func complianceReport(name, cluster string, pass, fail float64) Report {
+	return Report{
+		Type: "clustercompliancereports", Cluster: cluster, Name: name,
+		Data: map[string]interface{}{"report": map[string]interface{}{"summary": map[string]interface{}{"passCount": pass, "failCount": fail}}},
+	}
+}
+
+func TestFleetAggregator_BenchmarkScore(t *testing.T) {
+	f := newFleetAggregator()
+	f.set("report:prod::clustercompliancereports:k8s-cis-1.23", complianceReport("k8s-cis-1.23", "prod", 80, 20))
+	// only CIS compliance reports count
+	f.set("report:prod::clustercompliancereports:k8s-nsa-1.0", complianceReport("k8s-nsa-1.0", "prod", 0, 50))
+	f.set("report:prod:ns:configauditreports:a", makeReport("a", "prod", "ns", "configauditreports", 0))
+	f.set("report:prod:ns:configauditreports:b", makeReport("b", "prod", "ns", "configauditreports", 2))
+	f.set("report:dev:ns:configauditreports:c", makeReport("c", "dev", "ns", "configauditreports", 0))
+	f.set("report:idle:ns:vulnerabilityreports:d", makeReport("d", "idle", "ns", "vulnerabilityreports", 1))
+
+	// 0.6 * 80 + 0.4 * 50
+	if s := f.benchmarkScore("prod"); s == nil || s.Score != 68 || *s.Compliance != 80 || *s.ConfigAudit != 50 {
+		t.Fatalf("prod score = %+v", s)
+	}
+	// config audits alone
+	if s := f.benchmarkScore("dev"); s == nil || s.Score != 100 || s.Compliance != nil {
+		t.Fatalf("dev score = %+v", s)
+	}
+	if s := f.benchmarkScore("idle"); s != nil {
+		t.Fatalf("idle cluster scored %+v", s)
+	}
+
+	// 0.6 * 80 + 0.4 * 2/3
+	if s := f.summary(nil, nil, 10).BenchmarkScore; s == nil || s.Score != 74.7 || s.AuditedResources != 3 {
+		t.Fatalf("fleet score = %+v", s)
+	}
+
+	f.remove("report:prod::clustercompliancereports:k8s-cis-1.23")
+	if s := f.benchmarkScore("prod"); s == nil || s.Score != 50 || s.Compliance != nil {
+		t.Fatalf("prod score after removal = %+v", s)
+	}
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metrics served to Grafana's SimpleJSON protocol, which the JSON and Infinity datasources
+// speak as well: current severity totals and their recorded history as time series, and
+// the most vulnerable workloads, namespaces and clusters as tables.
+const (
+	grafanaSeverityPrefix = "severity."
+	grafanaTrendPrefix    = "trend."
+	grafanaTopWorkloads   = "top.workloads"
+	grafanaTopNamespaces  = "top.namespaces"
+	grafanaTopClusters    = "top.clusters"
+)
+
+// grafanaTopLimit is the number of rows of a top table unless a target asks for fewer
+// or more
+const grafanaTopLimit = 10
+
+// grafanaMetrics lists the targets /api/grafana/search offers, in the order it offers them.
+var grafanaMetrics = []string{
+	grafanaSeverityPrefix + "critical",
+	grafanaSeverityPrefix + "high",
+	grafanaSeverityPrefix + "medium",
+	grafanaSeverityPrefix + "low",
+	grafanaTrendPrefix + "critical",
+	grafanaTrendPrefix + "high",
+	grafanaTrendPrefix + "medium",
+	grafanaTopWorkloads,
+	grafanaTopNamespaces,
+	grafanaTopClusters,
+}
+
+type grafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+type grafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []grafanaTarget `json:"targets"`
+}
+
+// grafanaTarget is one query of a panel. Its payload (data in older plugin versions)
+// takes a cluster, a comma-separated list as in ?cluster=, and the limit of top tables.
+type grafanaTarget struct {
+	Target  string         `json:"target"`
+	RefID   string         `json:"refId"`
+	Payload grafanaOptions `json:"payload"`
+	Data    grafanaOptions `json:"data"`
+}
+
+type grafanaOptions struct {
+	Cluster string `json:"cluster"`
+	Limit   int    `json:"limit"`
+}
+
+func (t grafanaTarget) options() grafanaOptions {
+	if t.Payload != (grafanaOptions{}) {
+		return t.Payload
+	}
+	return t.Data
+}
+
+// grafanaSeries is a time series, its datapoints [value, unix milliseconds] pairs.
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	RefID      string       `json:"refId,omitempty"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+type grafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+type grafanaTable struct {
+	Type    string          `json:"type"`
+	RefID   string          `json:"refId,omitempty"`
+	Columns []grafanaColumn `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// writeGrafana writes a body as is: Grafana reads bare arrays, not the API's envelope.
+func writeGrafana(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// GrafanaTest answers the datasource's connection test.
+func (h *Handler) GrafanaTest(w http.ResponseWriter, r *http.Request) {
+	writeGrafana(w, map[string]string{"status": "ok"})
+}
+
+// GrafanaSearch lists the metrics whose name contains the search target, or the cluster
+// names for the target "clusters", so dashboards can offer them as variables.
+func (h *Handler) GrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	var req grafanaSearchRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	if req.Target == "clusters" {
+		names := []string{}
+		for name := range h.clusterReg.All() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		writeGrafana(w, names)
+		return
+	}
+	metrics := []string{}
+	for _, m := range grafanaMetrics {
+		if strings.Contains(m, req.Target) {
+			metrics = append(metrics, m)
+		}
+	}
+	writeGrafana(w, metrics)
+}
+
+// GrafanaQuery answers the targets of a panel in order. Severity targets are the current
+// totals at the end of the range, trend targets the hourly recorded history within it.
+func (h *Handler) GrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var req grafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	now := time.Now()
+	to := req.Range.To
+	if to.IsZero() || to.After(now) {
+		to = now
+	}
+	from := req.Range.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+
+	results := make([]interface{}, 0, len(req.Targets))
+	for _, t := range req.Targets {
+		opts := t.options()
+		switch {
+		case strings.HasPrefix(t.Target, grafanaSeverityPrefix):
+			severity := strings.TrimPrefix(t.Target, grafanaSeverityPrefix)
+			var totals ReportRollup
+			for _, rollup := range h.cache.GetReportRollups(opts.Cluster) {
+				totals.merge(rollup.ReportRollup)
+			}
+			value, ok := severityTotal(totals.Severity, severity)
+			if !ok {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown target %q", t.Target))
+				return
+			}
+			results = append(results, grafanaSeries{
+				Target:     t.Target,
+				RefID:      t.RefID,
+				Datapoints: [][2]float64{{float64(value), float64(to.UnixMilli())}},
+			})
+		case strings.HasPrefix(t.Target, grafanaTrendPrefix):
+			severity := strings.TrimPrefix(t.Target, grafanaTrendPrefix)
+			if _, ok := trendCount(TrendRecord{}, severity); !ok {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown target %q", t.Target))
+				return
+			}
+			days := int(math.Ceil(now.Sub(from).Hours() / 24))
+			series := grafanaSeries{Target: t.Target, RefID: t.RefID, Datapoints: [][2]float64{}}
+			for _, record := range h.cache.GetTrends(opts.Cluster, days) {
+				if record.Timestamp.Before(from) || record.Timestamp.After(to) {
+					continue
+				}
+				value, _ := trendCount(record, severity)
+				series.Datapoints = append(series.Datapoints, [2]float64{float64(value), float64(record.Timestamp.UnixMilli())})
+			}
+			results = append(results, series)
+		case t.Target == grafanaTopWorkloads:
+			results = append(results, h.grafanaTopWorkloads(t, opts))
+		case t.Target == grafanaTopNamespaces, t.Target == grafanaTopClusters:
+			results = append(results, h.grafanaTopRollups(t, opts))
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown target %q", t.Target))
+			return
+		}
+	}
+	writeGrafana(w, results)
+}
+
+// grafanaTopWorkloads tables the workloads the overview ranks, at most five.
+func (h *Handler) grafanaTopWorkloads(t grafanaTarget, opts grafanaOptions) grafanaTable {
+	table := grafanaTable{
+		Type:  "table",
+		RefID: t.RefID,
+		Columns: []grafanaColumn{
+			{"Cluster", "string"}, {"Namespace", "string"}, {"Workload", "string"},
+			{"Type", "string"}, {"Critical", "number"}, {"High", "number"},
+		},
+		Rows: [][]interface{}{},
+	}
+	overview := h.cache.GetOverviewData(opts.Cluster, false, nil)
+	if overview == nil {
+		return table
+	}
+	for i, wl := range overview.TopVulnerableWorkloads {
+		if opts.Limit > 0 && i == opts.Limit {
+			break
+		}
+		table.Rows = append(table.Rows, []interface{}{wl.Cluster, wl.Namespace, wl.Name, wl.Type, wl.Critical, wl.High})
+	}
+	return table
+}
+
+// grafanaTopRollups tables the namespaces or clusters with the most critical, then high,
+// findings, read from the cache's rollups.
+func (h *Handler) grafanaTopRollups(t grafanaTarget, opts grafanaOptions) grafanaTable {
+	byNamespace := t.Target == grafanaTopNamespaces
+	type row struct {
+		cluster, namespace string
+		severity           SeverityTotals
+	}
+	rows := make(map[[2]string]*row)
+	for _, rollup := range h.cache.GetReportRollups(opts.Cluster) {
+		key := [2]string{rollup.Cluster, ""}
+		if byNamespace {
+			if rollup.Namespace == "" {
+				continue
+			}
+			key[1] = rollup.Namespace
+		}
+		rw := rows[key]
+		if rw == nil {
+			rw = &row{cluster: key[0], namespace: key[1]}
+			rows[key] = rw
+		}
+		rw.severity.Critical += rollup.Severity.Critical
+		rw.severity.High += rollup.Severity.High
+		rw.severity.Medium += rollup.Severity.Medium
+		rw.severity.Low += rollup.Severity.Low
+	}
+	ranked := make([]*row, 0, len(rows))
+	for _, rw := range rows {
+		ranked = append(ranked, rw)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.severity.Critical != b.severity.Critical {
+			return a.severity.Critical > b.severity.Critical
+		}
+		if a.severity.High != b.severity.High {
+			return a.severity.High > b.severity.High
+		}
+		if a.cluster != b.cluster {
+			return a.cluster < b.cluster
+		}
+		return a.namespace < b.namespace
+	})
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = grafanaTopLimit
+	}
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	table := grafanaTable{Type: "table", RefID: t.RefID, Rows: [][]interface{}{}}
+	table.Columns = append(table.Columns, grafanaColumn{"Cluster", "string"})
+	if byNamespace {
+		table.Columns = append(table.Columns, grafanaColumn{"Namespace", "string"})
+	}
+	table.Columns = append(table.Columns,
+		grafanaColumn{"Critical", "number"}, grafanaColumn{"High", "number"},
+		grafanaColumn{"Medium", "number"}, grafanaColumn{"Low", "number"})
+	for _, rw := range ranked {
+		cells := []interface{}{rw.cluster}
+		if byNamespace {
+			cells = append(cells, rw.namespace)
+		}
+		table.Rows = append(table.Rows, append(cells, rw.severity.Critical, rw.severity.High, rw.severity.Medium, rw.severity.Low))
+	}
+	return table
+}
+
+func severityTotal(s SeverityTotals, severity string) (int, bool) {
+	switch severity {
+	case "critical":
+		return s.Critical, true
+	case "high":
+		return s.High, true
+	case "medium":
+		return s.Medium, true
+	case "low":
+		return s.Low, true
+	}
+	return 0, false
+}
+
+// trendCount returns a severity of a trend record, which keeps critical, high and medium.
+func trendCount(r TrendRecord, severity string) (int, bool) {
+	switch severity {
+	case "critical":
+		return r.Critical, true
+	case "high":
+		return r.High, true
+	case "medium":
+		return r.Medium, true
+	}
+	return 0, false
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+)
+
+func TestGrafanaSearch(t *testing.T) {
+	reg := NewClusterRegistry(&stubCacheService{})
+	reg.RegisterPushed("b", "v1.30.0", nil)
+	reg.RegisterPushed("a", "v1.30.0", nil)
+	h := &Handler{clusterReg: reg}
+
+	search := func(body string) []string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.GrafanaSearch(rec, httptest.NewRequest(http.MethodPost, "/api/grafana/search", strings.NewReader(body)))
+		var names []string
+		if err := json.Unmarshal(rec.Body.Bytes(), &names); err != nil {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		return names
+	}
+	if names := search(""); len(names) != len(grafanaMetrics) {
+		t.Errorf("expected every metric without a target, got %v", names)
+	}
+	if names := search(`{"target":"top."}`); len(names) != 3 || names[0] != grafanaTopWorkloads {
+		t.Errorf("expected the top tables, got %v", names)
+	}
+	if names := search(`{"target":"clusters"}`); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("expected the cluster names, got %v", names)
+	}
+}
+
+func TestGrafanaQuery(t *testing.T) {
+	c := useTestCache(t)
+	c.Set(reportKey("a", "web", "vulnerabilityreports", "r1"), makeReport("r1", "a", "web", "vulnerabilityreports", 2), 0)
+	c.Set(reportKey("a", "db", "vulnerabilityreports", "r2"), makeReport("r2", "a", "db", "vulnerabilityreports", 5), 0)
+	c.Set(reportKey("b", "web", "vulnerabilityreports", "r3"), makeReport("r3", "b", "web", "vulnerabilityreports", 1), 0)
+	h := &Handler{cache: &CacheServiceImpl{cache: c}}
+
+	cfg := config.Get()
+	prevPath := cfg.DataPath
+	t.Cleanup(func() { cfg.DataPath = prevPath })
+	cfg.DataPath = t.TempDir()
+	now := time.Now().UTC()
+	records, _ := json.Marshal([]TrendRecord{
+		{Timestamp: now.Add(-72 * time.Hour), Critical: 1},
+		{Timestamp: now.Add(-2 * time.Hour), Critical: 6},
+		{Timestamp: now.Add(-time.Hour), Critical: 8},
+		{Timestamp: now.Add(-time.Hour), Cluster: "a", Critical: 7},
+	})
+	if err := os.WriteFile(filepath.Join(cfg.DataPath, "trend-history.json"), records, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	query := func(body string) (int, []json.RawMessage) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.GrafanaQuery(rec, httptest.NewRequest(http.MethodPost, "/api/grafana/query", strings.NewReader(body)))
+		var results []json.RawMessage
+		json.Unmarshal(rec.Body.Bytes(), &results)
+		return rec.Code, results
+	}
+
+	from := now.Add(-24 * time.Hour).Format(time.RFC3339)
+	code, results := query(`{"range":{"from":"` + from + `","to":"` + now.Format(time.RFC3339) + `"},"targets":[
+		{"target":"severity.critical","refId":"A"},
+		{"target":"severity.critical","refId":"B","payload":{"cluster":"b"}},
+		{"target":"trend.critical","refId":"C"},
+		{"target":"top.namespaces","refId":"D","payload":{"limit":2}},
+		{"target":"top.clusters","refId":"E","data":{"cluster":"a"}}
+	]}`)
+	if code != http.StatusOK || len(results) != 5 {
+		t.Fatalf("status %d, results %s", code, results)
+	}
+
+	var series grafanaSeries
+	json.Unmarshal(results[0], &series)
+	if series.RefID != "A" || len(series.Datapoints) != 1 || series.Datapoints[0][0] != 8 {
+		t.Errorf("severity.critical = %+v", series)
+	}
+	json.Unmarshal(results[1], &series)
+	if series.Datapoints[0][0] != 1 {
+		t.Errorf("expected cluster b's criticals, got %+v", series)
+	}
+	json.Unmarshal(results[2], &series)
+	if len(series.Datapoints) != 2 || series.Datapoints[0][0] != 6 || series.Datapoints[1][0] != 8 {
+		t.Errorf("expected the global trend within the range, got %+v", series)
+	}
+
+	var table grafanaTable
+	json.Unmarshal(results[3], &table)
+	if table.Type != "table" || len(table.Columns) != 6 || len(table.Rows) != 2 || table.Rows[0][1] != "db" || table.Rows[1][1] != "web" {
+		t.Errorf("top.namespaces = %+v", table)
+	}
+	table = grafanaTable{}
+	json.Unmarshal(results[4], &table)
+	if len(table.Columns) != 5 || len(table.Rows) != 1 || table.Rows[0][0] != "a" || table.Rows[0][1] != float64(7) {
+		t.Errorf("top.clusters = %+v", table)
+	}
+
+	if code, _ := query(`{"targets":[{"target":"trend.low"}]}`); code != http.StatusBadRequest {
+		t.Errorf("expected an unknown target rejected, got %d", code)
+	}
+}
@@ -3,8 +3,10 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,6 +15,7 @@ import (
 
 	"trivy-ui/config"
 	"trivy-ui/kubernetes"
+	"trivy-ui/store"
 	"trivy-ui/utils"
 )
 
@@ -28,23 +31,106 @@ type Response struct {
 }
 
 type PaginatedResponse struct {
-	Total               int         `json:"total"`
-	WithVulnerabilities int         `json:"withVulnerabilities,omitempty"`
-	Page                int         `json:"page"`
-	PageSize            int         `json:"pageSize"`
-	Data                interface{} `json:"data"`
+	Total               int  `json:"total"`
+	WithVulnerabilities int  `json:"withVulnerabilities,omitempty"`
+	Page                int  `json:"page"`
+	PageSize            int  `json:"pageSize"`
+	TotalPages          int  `json:"totalPages"`
+	HasNext             bool `json:"hasNext"`
+	HasPrev             bool `json:"hasPrev"`
+	// Sort and Filters echo what was applied, so clients can show them without keeping
+	// their own copy of the request
+	Sort    string         `json:"sort,omitempty"`
+	Filters AppliedFilters `json:"filters"`
+	Data    interface{}    `json:"data"`
+}
+
+// AppliedFilters are the filters a list was narrowed by; unset filters are omitted.
+type AppliedFilters struct {
+	Cluster         string   `json:"cluster,omitempty"`
+	Namespaces      []string `json:"namespaces,omitempty"`
+	Search          string   `json:"search,omitempty"`
+	OnlyVulnerable  bool     `json:"onlyVulnerable,omitempty"`
+	Filter          string   `json:"filter,omitempty"`
+	IgnoreUnfixable bool     `json:"ignoreUnfixable,omitempty"`
+	RunningOnly     bool     `json:"runningOnly,omitempty"`
+	Containers      []string `json:"containers,omitempty"`
+	// Tags are the tag filters of a cluster list
+	Tags []string `json:"tags,omitempty"`
+}
+
+// newPaginatedResponse wraps a page of a report query with its paging and filters.
+func newPaginatedResponse(q ReportQuery, result QueryResult, data interface{}) PaginatedResponse {
+	totalPages := 0
+	if q.PageSize > 0 {
+		totalPages = (result.Total + q.PageSize - 1) / q.PageSize
+	}
+	return PaginatedResponse{
+		Total:               result.Total,
+		WithVulnerabilities: result.WithVulnerabilities,
+		Page:                q.Page,
+		PageSize:            q.PageSize,
+		TotalPages:          totalPages,
+		HasNext:             q.Page < totalPages,
+		HasPrev:             q.Page > 1,
+		Sort:                q.Sort,
+		Filters: AppliedFilters{
+			Cluster:         q.Cluster,
+			Namespaces:      q.Namespaces,
+			Search:          q.Search,
+			OnlyVulnerable:  q.OnlyVulnerable,
+			Filter:          q.Filter,
+			IgnoreUnfixable: q.IgnoreUnfixable,
+			RunningOnly:     q.RunningOnly,
+			Containers:      q.Containers,
+		},
+		Data: data,
+	}
 }
 
 type Cluster struct {
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	SyncState   string `json:"syncState,omitempty"`
+	Name string `json:"name"`
+	// Description is a display summary of the fields below, kept for older clients
+	Description       string `json:"description,omitempty"`
+	SyncState         string `json:"syncState,omitempty"`
+	APIServerURL      string `json:"apiServerUrl,omitempty"`
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	NodeCount         int    `json:"nodeCount,omitempty"`
+	// UID is the kube-system namespace UID, which identifies the cluster across kubeconfigs
+	UID string `json:"uid,omitempty"`
+	// Aliases are the other names the cluster was loaded under, merged into this one
+	Aliases []string `json:"aliases,omitempty"`
+	// Platform is the managed platform or distribution, see kubernetes.Platform*
+	Platform string `json:"platform,omitempty"`
+	// Pushed clusters send their reports through an agent
+	Pushed bool `json:"pushed,omitempty"`
+	// RefreshError is set when ?refresh=1 could not reach the cluster
+	RefreshError string `json:"refreshError,omitempty"`
+	// FiringAlerts counts the Alertmanager alerts firing for the cluster, see /api/v1/alerts
+	FiringAlerts int `json:"firingAlerts,omitempty"`
+	// Inactive clusters were removed and can be restored until their retention ends
+	Inactive  bool       `json:"inactive,omitempty"`
+	RemovedAt *time.Time `json:"removedAt,omitempty"`
+	// Auth is the health of the connection's credentials, for clusters the server connects to
+	Auth *kubernetes.AuthHealth `json:"auth,omitempty"`
+	// Connectivity is whether the API server answers, see /api/v1/clusters/{name}/connectivity
+	Connectivity string `json:"connectivity,omitempty"`
+	// StaleSince is set while the cluster is unreachable: its reports are as old as this
+	StaleSince *time.Time `json:"staleSince,omitempty"`
+	// Tags are the cluster's key/value tags, from CLUSTER_TAGS or /api/v1/clusters/{name}/tags
+	Tags map[string]string `json:"tags,omitempty"`
+	// BenchmarkScore rates the cluster's CIS compliance and config audits from 0 to 100
+	BenchmarkScore *BenchmarkScore `json:"benchmarkScore,omitempty"`
+	// Stats are the cluster's report counts and worst severity
+	Stats *ClusterStats `json:"stats,omitempty"`
 }
 
 type Namespace struct {
 	Cluster     string `json:"cluster"`
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
+	// ScanStatus tells whether trivy-operator scans the namespace, see kubernetes.ScanStatus*
+	ScanStatus string `json:"scanStatus,omitempty"`
 }
 
 type Report struct {
@@ -54,7 +140,42 @@ type Report struct {
 	Name      string      `json:"name"`
 	Status    string      `json:"status,omitempty"`
 	Data      interface{} `json:"data"`
-	UpdatedAt time.Time   `json:"updated_at"`
+	// UpdatedAt is kept for older clients and equals CachedAt
+	UpdatedAt time.Time `json:"updated_at"`
+	// ScannedAt is the operator's scan time from the CR; CachedAt is when trivy-ui stored the report
+	ScannedAt time.Time `json:"scannedAt,omitzero"`
+	CachedAt  time.Time `json:"cachedAt"`
+	// UID and ResourceVersion are the CR's metadata; a changed resourceVersion means the
+	// report changed, so clients can skip reloading details that did not
+	UID             string `json:"uid,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// Fixable counts findings with a fixed version available
+	Fixable int `json:"fixable,omitempty"`
+	// EffectiveSeverity and EffectiveSummary re-rate findings with SEVERITY_RULES_FILE
+	EffectiveSeverity string          `json:"effectiveSeverity,omitempty"`
+	EffectiveSummary  *SeverityTotals `json:"effectiveSummary,omitempty"`
+	// SeverityOverrides are the findings whose severity /api/v1/severity-overrides replaced;
+	// the summary counts them at their new severity and report.originalSummary does not
+	SeverityOverrides []SeverityOverrideHit `json:"severityOverrides,omitempty"`
+	// Externalized marks cached details whose large fields live in the store
+	Externalized bool `json:"externalized,omitempty"`
+	// Exposure is the security context of the scanned workload (vulnerability reports only)
+	Exposure *ReportExposure `json:"exposure,omitempty"`
+	// Links are the rendered report-level LINK_TEMPLATES; set on responses, never cached
+	Links map[string]string `json:"customLinks,omitempty"`
+	// Provenance is the image's source commit and base image from its OCI annotations
+	Provenance *ImageProvenance `json:"provenance,omitempty"`
+	// WorkloadState is whether the scanned workload runs pods; set on list responses,
+	// never cached
+	WorkloadState *kubernetes.WorkloadState `json:"workloadState,omitempty"`
+	// Container and Workload are the scanned container and its workload, from the
+	// operator's labels; set on responses, never cached
+	Container string          `json:"container,omitempty"`
+	Workload  *ReportWorkload `json:"workload,omitempty"`
+	// CreatedAt is the report resource's creationTimestamp and Age the time since, e.g.
+	// 1d2h; set on responses, never cached
+	CreatedAt time.Time `json:"creationTimestamp,omitzero"`
+	Age       string    `json:"age,omitempty"`
 }
 
 type SeverityTotals struct {
@@ -98,6 +219,7 @@ type ClusterOverview struct {
 	TopVulnerableWorkloads []WorkloadSummary        `json:"top_vulnerable_workloads"`
 	VulnerableClusters     []ClusterSummary         `json:"vulnerable_clusters,omitempty"`
 	VulnerableNamespaces   []NamespaceSummary       `json:"vulnerable_namespaces,omitempty"`
+	SLA                    []store.SLACompliance    `json:"sla,omitempty"`
 }
 
 type TrendRecord struct {
@@ -115,13 +237,19 @@ type Handler struct {
 	crdReg     *config.CRDRegistry
 }
 
+// CacheService is the read/write contract handlers use for the report cache in cache.go,
+// the only cache in the server. Keys follow the report:/detail:/cluster:/namespace:
+// formats produced by reportKey and friends; persistence goes through SaveToFile/LoadFromFile.
 type CacheService interface {
 	Get(key string) (interface{}, bool)
 	Items() map[string]interface{}
 	ItemsByType(typeName string) map[string]interface{}
+	FindReportKeys(typeName, cluster, namespace, name string) []string
 	GetReports(typeName, clusterFilter string, namespaceFilters []string) []Report
 	GetReportCount(reportType, cluster string) (int, int)
-	GetOverviewData(cluster string) *ClusterOverview
+	GetReportRollup(typeName, clusterFilter string, namespaceFilters []string) ReportRollup
+	GetReportRollups(clusterFilter string) []NamespaceRollup
+	GetOverviewData(cluster string, ignoreUnfixable bool, keep func(Report) bool) *ClusterOverview
 	GetTrends(clusterFilter string, days int) []TrendRecord
 	GetStats() map[string]interface{}
 	Set(key string, value interface{}, expiration time.Duration)
@@ -168,6 +296,10 @@ func (c *CacheServiceImpl) ItemsByType(typeName string) map[string]interface{} {
 	return c.getCache().ItemsByType(typeName)
 }
 
+func (c *CacheServiceImpl) FindReportKeys(typeName, cluster, namespace, name string) []string {
+	return c.getCache().FindReportKeys(typeName, cluster, namespace, name)
+}
+
 func (c *CacheServiceImpl) GetReports(typeName, clusterFilter string, namespaceFilters []string) []Report {
 	return c.getCache().GetReports(typeName, clusterFilter, namespaceFilters)
 }
@@ -176,8 +308,16 @@ func (c *CacheServiceImpl) GetReportCount(reportType, cluster string) (int, int)
 	return c.getCache().GetReportCount(reportType, cluster)
 }
 
-func (c *CacheServiceImpl) GetOverviewData(cluster string) *ClusterOverview {
-	return c.getCache().GetOverviewData(cluster)
+func (c *CacheServiceImpl) GetReportRollup(typeName, clusterFilter string, namespaceFilters []string) ReportRollup {
+	return c.getCache().GetReportRollup(typeName, clusterFilter, namespaceFilters)
+}
+
+func (c *CacheServiceImpl) GetReportRollups(clusterFilter string) []NamespaceRollup {
+	return c.getCache().GetReportRollups(clusterFilter)
+}
+
+func (c *CacheServiceImpl) GetOverviewData(cluster string, ignoreUnfixable bool, keep func(Report) bool) *ClusterOverview {
+	return c.getCache().GetOverviewData(cluster, ignoreUnfixable, keep)
 }
 
 func (c *CacheServiceImpl) GetTrends(clusterFilter string, days int) []TrendRecord {
@@ -258,13 +398,16 @@ func ReportKey(cluster, ns, typ, name string) string {
 	return reportKey(cluster, ns, typ, name)
 }
 
-func (h *Handler) refreshCRDRegistry() {
+func (h *Handler) refreshCRDRegistry(ctx context.Context) {
 	registry := h.crdReg
 	clients := h.clusterReg.All()
 	if len(clients) > 0 {
 		for _, cc := range clients {
 			if cc.Client != nil && cc.Client.Config() != nil {
-				if err := registry.RefreshIfNeeded(cc.Client.Config()); err != nil {
+				refreshCtx, cancel := kubeCallContext(ctx)
+				err := registry.RefreshIfNeeded(refreshCtx, cc.Client.Config())
+				cancel()
+				if err != nil && !clientGone(ctx) {
 					utils.LogWarning("Failed to refresh CRDs", map[string]interface{}{"error": err.Error()})
 				}
 				break
@@ -274,7 +417,7 @@ func (h *Handler) refreshCRDRegistry() {
 }
 
 func (h *Handler) GetReportTypes(w http.ResponseWriter, r *http.Request) {
-	h.refreshCRDRegistry()
+	h.refreshCRDRegistry(r.Context())
 	reportTypes := h.crdReg.GetAllReports()
 	writeJSON(w, http.StatusOK, Response{
 		Code:    CodeSuccess,
@@ -284,7 +427,7 @@ func (h *Handler) GetReportTypes(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetTypesV1(w http.ResponseWriter, r *http.Request) {
-	h.refreshCRDRegistry()
+	h.refreshCRDRegistry(r.Context())
 	reportTypes := h.crdReg.GetAllReports()
 	writeJSON(w, http.StatusOK, Response{
 		Code:    CodeSuccess,
@@ -301,6 +444,18 @@ func (h *Handler) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if mode := config.Get().IngestMode; mode != config.IngestModeKubernetes {
+		// no cluster clients or CRDs in this mode; reports come from files or push agents
+		if err := ingestReadiness(r.Context(), config.Get(), store.Get()); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ready"))
+		return
+	}
+
 	registry := h.crdReg
 
 	if !registry.IsDiscovered() {
@@ -320,9 +475,33 @@ func (h *Handler) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("ready"))
 }
 
-// GetCacheStats 获取缓存统计信息
+// ingestReadiness checks what file and API ingest depend on instead of cluster clients:
+// a reachable storage backend, an initialised cache and, for API ingest, agent credentials.
+func ingestReadiness(ctx context.Context, cfg *config.Config, st *store.Store) error {
+	if st == nil {
+		return fmt.Errorf("storage backend not available")
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := st.Ping(pingCtx); err != nil {
+		return fmt.Errorf("storage backend not reachable: %v", err)
+	}
+	if getCache() == nil {
+		return fmt.Errorf("cache not initialised")
+	}
+	if cfg.IngestMode == config.IngestModeAPI && !cfg.AgentPushEnabled() {
+		return fmt.Errorf("no agent credentials configured for API ingest")
+	}
+	return nil
+}
+
+// GetCacheStats 获取缓存统计信息: entries by key prefix, estimated memory, hit/miss and
+// eviction counters, last persist time and the aggregate cache counters.
 func (h *Handler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
 	stats := h.cache.GetStats()
+	if stats != nil {
+		stats["aggregates"] = aggregates.snapshot()
+	}
 	writeJSON(w, http.StatusOK, Response{
 		Code:    CodeSuccess,
 		Message: "Success",
@@ -333,31 +512,47 @@ func (h *Handler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetClusters(w http.ResponseWriter, r *http.Request) {
 	refresh := r.URL.Query().Get("refresh") == "1"
 	emptyKey := "empty:clusters"
+	if !IsValidClusterSort(r.URL.Query().Get("sort")) {
+		writeError(w, http.StatusBadRequest, "Invalid sort, expected name, severity, reports or riskScore, optionally prefixed with -")
+		return
+	}
+	// respond lists clusters with their stats, as a page when the request asks for one
+	respond := func(message string, clusters []Cluster) {
+		withClusterStats(clusters)
+		var data interface{} = clusters
+		if isClusterListQuery(r) {
+			data = clusterPage(r, clusters)
+		}
+		writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: message, Data: data})
+	}
 
 	var clusters []Cluster
 	clusterClients := h.clusterReg.All()
+	var refreshErrs map[string]error
+	if refresh {
+		refreshErrs = h.refreshAllNamespaces(r.Context(), clusterClients)
+	}
+	alertCounts := firingAlerts.countByCluster(time.Now())
 	for name, cc := range clusterClients {
-		cc.mu.RLock()
-		syncState := cc.SyncState
-		cc.mu.RUnlock()
-		if syncState == "" {
-			syncState = "Cached"
+		clusterInfo := cc.info()
+		if clusterInfo.SyncState == "" {
+			clusterInfo.SyncState = "Cached"
 		}
-		clusterInfo := Cluster{
-			Name:        name,
-			Description: fmt.Sprintf("API Server: %s, version: %s", cc.APIServerURL, cc.Version),
-			SyncState:   syncState,
+		if err := refreshErrs[name]; err != nil {
+			clusterInfo.RefreshError = err.Error()
 		}
 		h.cache.Set(clusterKey(clusterInfo.Name), clusterInfo, 0)
+		clusterInfo.FiringAlerts = alertCounts[name]
+		clusterInfo.BenchmarkScore = fleet.benchmarkScore(name)
 		clusters = append(clusters, clusterInfo)
 	}
+	if r.URL.Query().Get("includeInactive") == "true" {
+		clusters = append(clusters, h.inactiveClusters()...)
+	}
+	withClusterTags(clusters)
 
 	if len(clusters) > 0 {
-		writeJSON(w, http.StatusOK, Response{
-			Code:    CodeSuccess,
-			Message: "Success (k8s)",
-			Data:    clusters,
-		})
+		respond("Success (k8s)", clusters)
 		return
 	}
 
@@ -376,30 +571,40 @@ func (h *Handler) GetClusters(w http.ResponseWriter, r *http.Request) {
 			}
 			clusters = append(clusters, cluster)
 		}
+		withClusterTags(clusters)
 		if len(clusters) > 0 {
-			writeJSON(w, http.StatusOK, Response{
-				Code:    CodeSuccess,
-				Message: "Success (cache)",
-				Data:    clusters,
-			})
+			respond("Success (cache)", clusters)
 			return
 		}
 		if _, found := h.cache.Get(emptyKey); found {
-			writeJSON(w, http.StatusOK, Response{
-				Code:    CodeSuccess,
-				Message: "Success (empty)",
-				Data:    []Cluster{},
-			})
+			respond("Success (empty)", []Cluster{})
 			return
 		}
 	}
 
 	h.cache.Set(emptyKey, true, 0)
-	writeJSON(w, http.StatusOK, Response{
-		Code:    CodeSuccess,
-		Message: "Success (k8s empty)",
-		Data:    []Cluster{},
+	respond("Success (k8s empty)", []Cluster{})
+}
+
+// refreshAllNamespaces re-lists the namespaces of every cluster from its source, all
+// clusters at once, and caches them. It returns the errors by cluster.
+func (h *Handler) refreshAllNamespaces(ctx context.Context, clients map[string]*ClusterClient) map[string]error {
+	errs := fanOutClusters(ctx, clients, clusterFanoutLimit, kubeCallTimeout, func(ctx context.Context, name string, cc *ClusterClient) error {
+		if err := cc.RefreshNamespaces(ctx); err != nil {
+			return err
+		}
+		cc.mu.RLock()
+		namespaces := append([]string(nil), cc.Namespaces...)
+		cc.mu.RUnlock()
+		for _, ns := range namespaces {
+			h.cache.Set(namespaceKey(name, ns), Namespace{Cluster: name, Name: ns}, 0)
+		}
+		return nil
 	})
+	if err := joinClusterErrors(errs); err != nil && !clientGone(ctx) {
+		utils.LogWarning("Failed to refresh namespaces", map[string]interface{}{"error": err.Error()})
+	}
+	return errs
 }
 
 func (h *Handler) GetNamespacesByCluster(w http.ResponseWriter, r *http.Request, cluster string) {
@@ -430,7 +635,7 @@ func (h *Handler) GetNamespacesByCluster(w http.ResponseWriter, r *http.Request,
 			writeJSON(w, http.StatusOK, Response{
 				Code:    CodeSuccess,
 				Message: "Success (cache)",
-				Data:    namespaces,
+				Data:    withScanStatus(operatorScopeFor(cluster), namespaces),
 			})
 			return
 		}
@@ -450,12 +655,27 @@ func (h *Handler) GetNamespacesByCluster(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	if clusterClient.Source == nil {
+		clusterClient.mu.RLock()
+		namespaces := make([]Namespace, 0, len(clusterClient.Namespaces))
+		for _, ns := range clusterClient.Namespaces {
+			namespaces = append(namespaces, Namespace{Cluster: cluster, Name: ns})
+		}
+		clusterClient.mu.RUnlock()
+		writeJSON(w, http.StatusOK, Response{
+			Code:    CodeSuccess,
+			Message: "Success (agent)",
+			Data:    withScanStatus(kubernetes.OperatorScope{}, namespaces),
+		})
+		return
+	}
+
 	// Use a shorter timeout for namespace listing (5 seconds)
 	// This prevents long waits if K8s client is not ready yet
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	nsList, err := clusterClient.Client.GetNamespaces(ctx)
+	nsList, err := clusterClient.Source.GetNamespaces(ctx)
 	if err != nil {
 		// Check if context was canceled or timed out
 		if ctx.Err() == context.DeadlineExceeded {
@@ -496,29 +716,12 @@ func (h *Handler) GetNamespacesByCluster(w http.ResponseWriter, r *http.Request,
 	writeJSON(w, http.StatusOK, Response{
 		Code:    CodeSuccess,
 		Message: "Success (k8s)",
-		Data:    namespaces,
+		Data:    withScanStatus(operatorScopeFor(cluster), namespaces),
 	})
 }
 
-func (h *Handler) parseReportKey(key string) (cluster, namespace, reportType, reportName string, ok bool) {
-	prefix := "report:"
-	if !strings.HasPrefix(key, prefix) {
-		return "", "", "", "", false
-	}
-	keyWithoutPrefix := strings.TrimPrefix(key, prefix)
-	parts := strings.Split(keyWithoutPrefix, ":")
-	if len(parts) < 4 {
-		return "", "", "", "", false
-	}
-	cluster = parts[0]
-	namespace = parts[1]
-	reportType = parts[2]
-	reportName = strings.Join(parts[3:], ":")
-	return cluster, namespace, reportType, reportName, true
-}
-
 func (h *Handler) parseQueryParams(r *http.Request) (clusterFilter string, namespaceFilters []string, page, pageSize int) {
-	clusterFilter = r.URL.Query().Get("cluster")
+	clusterFilter = clusterParam(r)
 	namespaceParam := r.URL.Query().Get("namespace")
 	if namespaceParam != "" {
 		namespaceFilters = strings.Split(namespaceParam, ",")
@@ -590,52 +793,70 @@ func (h *Handler) hasVulnerabilities(report Report) bool {
 }
 
 func (h *Handler) GetReportsByTypeV1(w http.ResponseWriter, r *http.Request, typeName string) {
+	typeName = h.reportTypeName(typeName)
 	clusterFilter, namespaceFilters, page, pageSize := h.parseQueryParams(r)
 
+	sortBy := r.URL.Query().Get("sort")
+	if !IsValidReportSort(sortBy) {
+		writeError(w, http.StatusBadRequest, "Invalid sort parameter")
+		return
+	}
+
+	filterText := r.URL.Query().Get("filter")
+	filterExpr, err := parseReportFilter(filterText)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid filter: "+err.Error())
+		return
+	}
+
 	q := ReportQuery{
-		Type:       typeName,
-		Cluster:    clusterFilter,
-		Namespaces: namespaceFilters,
-		Page:       page,
-		PageSize:   pageSize,
+		Type:            typeName,
+		Cluster:         clusterFilter,
+		Namespaces:      namespaceFilters,
+		Sort:            sortBy,
+		Filter:          filterText,
+		FilterExpr:      filterExpr,
+		IgnoreUnfixable: ignoreUnfixable(r),
+		RunningOnly:     runningOnly(r),
+		Containers:      containerParam(r),
+		Page:            page,
+		PageSize:        pageSize,
 	}
 
-	result := h.querySvc.ListReports(q)
+	h.writeReportList(w, r, q)
+}
 
-	writeJSON(w, http.StatusOK, Response{
-		Code:    CodeSuccess,
-		Message: "Success",
-		Data: PaginatedResponse{
-			Total:               result.Total,
-			WithVulnerabilities: result.WithVulnerabilities,
-			Page:                page,
-			PageSize:            pageSize,
-			Data:                result.Items,
-		},
-	})
+// reportTypeName returns the resource name of the report kind a type parameter refers
+// to, which may be its kind or short name in any case. Names of kinds not registered
+// (yet) are kept, and list no reports.
+func (h *Handler) reportTypeName(typeName string) string {
+	if reportKind := h.crdReg.ResolveReport(typeName); reportKind != nil {
+		return reportKind.Name
+	}
+	return typeName
 }
 
 func (h *Handler) getReportDetails(w http.ResponseWriter, r *http.Request, cluster, namespace, typeName, reportName string, allowFallback bool) {
-	reportKind := h.crdReg.GetReportByName(typeName)
+	reportKind := h.crdReg.ResolveReport(typeName)
 	if reportKind == nil {
 		writeError(w, http.StatusBadRequest, "Invalid report type")
 		return
 	}
+	typeName = reportKind.Name
 
-	if cluster == "" {
-		if !allowFallback {
-			writeError(w, http.StatusBadRequest, "Missing cluster parameter")
+	if cluster == "" && !allowFallback {
+		writeError(w, http.StatusBadRequest, "Missing cluster parameter")
+		return
+	}
+	if allowFallback && (cluster == "" || (namespace == "" && reportKind.Namespaced)) {
+		// resolve the missing location from the reports sharing the name
+		keys := h.cache.FindReportKeys(typeName, cluster, namespace, reportName)
+		if len(keys) > 1 {
+			writeAmbiguousReport(w, keys)
 			return
 		}
-		items := h.cache.ItemsByType(typeName)
-		for k := range items {
-			c, ns, _, reportNameFromKey, ok := h.parseReportKey(k)
-			if !ok || reportNameFromKey != reportName {
-				continue
-			}
-			cluster = c
-			namespace = ns
-			break
+		if len(keys) == 1 {
+			cluster, namespace, _, _, _ = parseReportCacheKey(keys[0])
 		}
 	}
 
@@ -643,41 +864,132 @@ func (h *Handler) getReportDetails(w http.ResponseWriter, r *http.Request, clust
 		writeError(w, http.StatusNotFound, "Report not found")
 		return
 	}
+	if container := r.URL.Query().Get("container"); container != "" {
+		sibling, ok := h.containerSibling(typeName, cluster, namespace, reportName, container)
+		if !ok {
+			writeError(w, http.StatusNotFound, "No report of container "+container)
+			return
+		}
+		reportName = sibling
+	}
 
-	if cachedDetail, found, ttlRemaining := GetReportDetailWithTTL(cluster, namespace, typeName, reportName); found {
-		if ttlRemaining < 2*time.Minute {
-			RefreshReportDetailAsync(cluster, namespace, typeName, reportName, *reportKind)
+	report, err := h.loadReportDetail(r.Context(), *reportKind, cluster, namespace, reportName)
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
 		}
-		writeJSON(w, http.StatusOK, Response{
-			Code:    CodeSuccess,
-			Message: "Success",
-			Data:    cachedDetail,
-		})
+		if errors.Is(err, errClusterClientNotFound) {
+			writeError(w, http.StatusInternalServerError, "Cluster client not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to fetch report details")
 		return
 	}
 
+	if wantsDedupe(r) {
+		report = dedupeReportDetail(report)
+	}
+	if ignoreUnfixable(r) {
+		report = withoutUnfixable(report)
+	}
+	report.Provenance = reportProvenance(report)
+	report = withFindingLinks(report)
+	report.Container = reportContainer(report)
+	report.Workload = reportWorkloadRef(report)
+	report = withReportAge(report, time.Now())
+
+	w.Header().Set("Content-Location", reportDetailPath(cluster, typeName, namespace, reportName))
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    report,
+	})
+}
+
+// ReportCandidate is one of the reports a detail request without cluster or namespace
+// could mean.
+type ReportCandidate struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace,omitempty"`
+	URL       string `json:"url"`
+}
+
+// writeAmbiguousReport answers a detail request matching several reports with 409 and
+// the canonical URL of each, instead of serving whichever was found first.
+func writeAmbiguousReport(w http.ResponseWriter, keys []string) {
+	candidates := make([]ReportCandidate, 0, len(keys))
+	for _, key := range keys {
+		cluster, namespace, typeName, name, ok := parseReportCacheKey(key)
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, ReportCandidate{
+			Cluster:   cluster,
+			Namespace: namespace,
+			URL:       reportDetailPath(cluster, typeName, namespace, name),
+		})
+	}
+	writeJSON(w, http.StatusConflict, Response{
+		Code:    CodeError,
+		Message: "Report name is ambiguous, pass cluster and namespace",
+		Data:    candidates,
+	})
+}
+
+// reportDetailPath is the canonical detail URL of a report, naming its cluster and
+// namespace; cluster-scoped reports use "_" for the namespace.
+func reportDetailPath(cluster, typeName, namespace, name string) string {
+	if namespace == "" {
+		namespace = "_"
+	}
+	return "/api/v1/reports/" + url.PathEscape(cluster) + "/" + url.PathEscape(typeName) + "/" +
+		url.PathEscape(namespace) + "/" + url.PathEscape(name)
+}
+
+var errClusterClientNotFound = errors.New("cluster client not found")
+
+// loadReportDetail serves a full report from the detail cache, falling back to a live
+// Kubernetes fetch that repopulates the cache.
+func (h *Handler) loadReportDetail(ctx context.Context, reportKind config.ReportKind, cluster, namespace, reportName string) (Report, error) {
+	typeName := reportKind.Name
+	if cachedDetail, found, ttlRemaining := GetReportDetailWithTTL(ctx, cluster, namespace, typeName, reportName); found {
+		if ttlRemaining < 2*time.Minute {
+			RefreshReportDetailAsync(cluster, namespace, typeName, reportName, reportKind)
+		}
+		return cachedDetail, nil
+	}
+
 	clusterClient := h.clusterReg.Get(cluster)
 	if clusterClient == nil {
-		writeError(w, http.StatusInternalServerError, "Cluster client not found")
-		return
+		return Report{}, errClusterClientNotFound
+	}
+	if clusterClient.Source == nil {
+		// agents push summaries only; serve the summary as the detail view
+		if value, found := h.cache.Get(reportKey(cluster, namespace, typeName, reportName)); found {
+			if report, ok := convertCacheValue[Report](value); ok {
+				return report, nil
+			}
+		}
+		return Report{}, errClusterClientNotFound
 	}
 
-	fullReport, err := clusterClient.Client.GetReportDetails(r.Context(), *reportKind, namespace, reportName)
+	kubeCtx, cancel := kubeCallContext(ctx)
+	defer cancel()
+	fullReport, err := clusterClient.Source.GetReportDetails(kubeCtx, reportKind, namespace, reportName)
 	if err != nil {
-		if r.Context().Err() == context.Canceled {
-			return
+		if !clientGone(ctx) {
+			utils.LogWarning("Failed to fetch report from Kubernetes", map[string]interface{}{
+				"cluster":   cluster,
+				"namespace": namespace,
+				"type":      typeName,
+				"name":      reportName,
+				"error":     err.Error(),
+			})
 		}
-		utils.LogWarning("Failed to fetch report from Kubernetes", map[string]interface{}{
-			"cluster":   cluster,
-			"namespace": namespace,
-			"type":      typeName,
-			"name":      reportName,
-			"error":     err.Error(),
-		})
-		writeError(w, http.StatusInternalServerError, "Failed to fetch report details")
-		return
+		return Report{}, err
 	}
 
+	now := time.Now()
 	report := Report{
 		Type:      typeName,
 		Cluster:   cluster,
@@ -685,16 +997,16 @@ func (h *Handler) getReportDetails(w http.ResponseWriter, r *http.Request, clust
 		Name:      reportName,
 		Status:    fullReport.Status,
 		Data:      fullReport.Data,
-		UpdatedAt: time.Now(),
+		ScannedAt: fullReport.ScannedAt,
+		UpdatedAt: now,
+		CachedAt:  now,
+
+		UID:             fullReport.UID,
+		ResourceVersion: fullReport.ResourceVersion,
 	}
 
 	SetReportDetail(report)
-
-	writeJSON(w, http.StatusOK, Response{
-		Code:    CodeSuccess,
-		Message: "Success",
-		Data:    report,
-	})
+	return report, nil
 }
 
 func (h *Handler) GetReportDetails(w http.ResponseWriter, r *http.Request) {
@@ -722,8 +1034,33 @@ func (h *Handler) GetReportDetailsV1(w http.ResponseWriter, r *http.Request, typ
 }
 
 func (h *Handler) GetOverview(w http.ResponseWriter, r *http.Request) {
-	cluster := r.URL.Query().Get("cluster")
-	overview := h.cache.GetOverviewData(cluster)
+	cluster := clusterParam(r)
+	ignore := ignoreUnfixable(r)
+	running := runningOnly(r)
+	overview := aggregates.getOrCompute("overview", aggregateScope(cluster), fmt.Sprintf("%s|%t|%t", cluster, ignore, running), func() interface{} {
+		var keep func(Report) bool
+		if running {
+			warmWorkloadStates(cluster)
+			keep = reportRunning
+		}
+		overview := h.cache.GetOverviewData(cluster, ignore, keep)
+		if st := store.Get(); st != nil && overview != nil {
+			// the overview is shared between requests, so one client leaving must not cut it short
+			ctx, cancel := storeCallContext(context.Background())
+			var clusters []string
+			if cluster != "" {
+				clusters = strings.Split(cluster, ",")
+			}
+			sla, err := st.SLACompliance(ctx, config.Get().SLAWindows, clusters, time.Now())
+			cancel()
+			if err != nil {
+				utils.LogWarning("Failed to compute SLA compliance", map[string]interface{}{"error": err.Error()})
+			} else {
+				overview.SLA = sla
+			}
+		}
+		return overview
+	})
 	writeJSON(w, http.StatusOK, Response{
 		Code: CodeSuccess,
 		Data: overview,
@@ -731,7 +1068,7 @@ func (h *Handler) GetOverview(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetOverviewTrends(w http.ResponseWriter, r *http.Request) {
-	cluster := r.URL.Query().Get("cluster")
+	cluster := clusterParam(r)
 	daysStr := r.URL.Query().Get("days")
 	days := 30
 	if d, err := strconv.Atoi(daysStr); err == nil && d > 0 {
@@ -753,32 +1090,40 @@ func (h *Handler) GetReportsV1(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Missing type parameter")
 		return
 	}
+	typeName = h.reportTypeName(typeName)
 
 	clusterFilter, namespaceFilters, page, pageSize := h.parseQueryParams(r)
 	search := r.URL.Query().Get("search")
 	onlyVulnerable := r.URL.Query().Get("onlyVulnerable") == "true"
 
-	q := ReportQuery{
-		Type:           typeName,
-		Cluster:        clusterFilter,
-		Namespaces:     namespaceFilters,
-		Search:         search,
-		OnlyVulnerable: onlyVulnerable,
-		Page:           page,
-		PageSize:       pageSize,
+	sortBy := r.URL.Query().Get("sort")
+	if !IsValidReportSort(sortBy) {
+		writeError(w, http.StatusBadRequest, "Invalid sort parameter")
+		return
+	}
+
+	filterText := r.URL.Query().Get("filter")
+	filterExpr, err := parseReportFilter(filterText)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid filter: "+err.Error())
+		return
 	}
 
-	result := h.querySvc.ListReports(q)
+	q := ReportQuery{
+		Type:            typeName,
+		Cluster:         clusterFilter,
+		Namespaces:      namespaceFilters,
+		Search:          search,
+		OnlyVulnerable:  onlyVulnerable,
+		Sort:            sortBy,
+		Filter:          filterText,
+		FilterExpr:      filterExpr,
+		IgnoreUnfixable: ignoreUnfixable(r),
+		RunningOnly:     runningOnly(r),
+		Containers:      containerParam(r),
+		Page:            page,
+		PageSize:        pageSize,
+	}
 
-	writeJSON(w, http.StatusOK, Response{
-		Code:    CodeSuccess,
-		Message: "Success",
-		Data: PaginatedResponse{
-			Total:               result.Total,
-			WithVulnerabilities: result.WithVulnerabilities,
-			Page:                page,
-			PageSize:            pageSize,
-			Data:                result.Items,
-		},
-	})
+	h.writeReportList(w, r, q)
 }
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"trivy-ui/config"
+)
+
+// Groups of ImageHygieneSummary, comparing the findings of images by their hygiene.
+const (
+	hygieneGroupMutable = "mutable"
+	hygieneGroupPinned  = "pinned"
+	hygieneGroupStale   = "stale"
+	hygieneGroupFresh   = "fresh"
+)
+
+// ImageHygiene is the age and reference of one image across the workloads running it.
+type ImageHygiene struct {
+	Image      string `json:"image"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+	// Created is when the image was built, as recorded by the scanner or the image's
+	// org.opencontainers.image.created annotation; images without it have no age
+	Created *time.Time `json:"created,omitempty"`
+	AgeDays *int       `json:"ageDays,omitempty"`
+	Stale   bool       `json:"stale"`
+	// Mutable images are referenced by a tag, which can be moved to another image; Latest
+	// ones by latest or by no tag at all
+	Mutable   bool           `json:"mutable"`
+	Latest    bool           `json:"latest"`
+	Workloads int            `json:"workloads"`
+	Clusters  []string       `json:"clusters"`
+	Severity  SeverityTotals `json:"severity"`
+}
+
+// ImageHygieneGroup totals the images of one group; AvgCriticalHigh is the mean number of
+// critical and high vulnerabilities per image.
+type ImageHygieneGroup struct {
+	Images          int            `json:"images"`
+	Workloads       int            `json:"workloads"`
+	Severity        SeverityTotals `json:"severity"`
+	AvgCriticalHigh float64        `json:"avgCriticalHigh"`
+}
+
+type ImageHygieneSummary struct {
+	Images     int `json:"images"`
+	Mutable    int `json:"mutable"`
+	Latest     int `json:"latest"`
+	Stale      int `json:"stale"`
+	UnknownAge int `json:"unknownAge"`
+	// StaleAfterDays is the age from which images are stale, IMAGE_STALE_AGE or staleAfter
+	StaleAfterDays int                          `json:"staleAfterDays"`
+	Groups         map[string]ImageHygieneGroup `json:"groups"`
+	Items          []ImageHygiene               `json:"items"`
+}
+
+// reportImageCreated returns when the scanned image was built, or zero when unknown.
+func reportImageCreated(r Report) time.Time {
+	created, _ := reportSection(r, "artifact")["created"].(string)
+	if created == "" {
+		created = reportImageAnnotations(r)(ociCreatedAnnotation)
+	}
+	t, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// imageMutability classifies an image reference: pinned by digest, or a tag that may
+// move, latest when the tag is latest or left out.
+func imageMutability(tag, digest string) (mutable, latest bool) {
+	if tag == "" {
+		return digest == "", digest == ""
+	}
+	return true, tag == "latest"
+}
+
+// GetImageHygiene handles GET /api/v1/images/hygiene.
+func (h *Handler) GetImageHygiene(w http.ResponseWriter, r *http.Request) {
+	clusterFilter, namespaceFilters, _, _ := h.parseQueryParams(r)
+	staleAfter := config.Get().ImageStaleAge
+	if v := r.URL.Query().Get("staleAfter"); v != "" {
+		d, err := config.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "Invalid staleAfter duration")
+			return
+		}
+		staleAfter = d
+	}
+
+	params := strings.Join([]string{clusterFilter, strings.Join(namespaceFilters, ","), staleAfter.String()}, "|")
+	result := aggregates.getOrCompute("image-hygiene", aggregateScope(clusterFilter), params, func() interface{} {
+		return h.computeImageHygiene(clusterFilter, namespaceFilters, staleAfter, time.Now())
+	}).(ImageHygieneSummary)
+
+	switch r.URL.Query().Get("mutable") {
+	case "true":
+		result.Items = filterImageHygiene(result.Items, func(i ImageHygiene) bool { return i.Mutable })
+	case "false":
+		result.Items = filterImageHygiene(result.Items, func(i ImageHygiene) bool { return !i.Mutable })
+	}
+	if r.URL.Query().Get("stale") == "true" {
+		result.Items = filterImageHygiene(result.Items, func(i ImageHygiene) bool { return i.Stale })
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    result,
+	})
+}
+
+func filterImageHygiene(items []ImageHygiene, keep func(ImageHygiene) bool) []ImageHygiene {
+	kept := []ImageHygiene{}
+	for _, i := range items {
+		if keep(i) {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
+
+// computeImageHygiene collects the images of vulnerability reports. An image run by
+// several workloads is listed once, with the findings of its most recent scan.
+func (h *Handler) computeImageHygiene(clusterFilter string, namespaceFilters []string, staleAfter time.Duration, now time.Time) ImageHygieneSummary {
+	type imageAggregate struct {
+		image    ImageHygiene
+		latest   Report
+		clusters map[string]bool
+	}
+	byImage := make(map[string]*imageAggregate)
+
+	for _, kind := range h.crdReg.GetAllReports() {
+		if !strings.HasSuffix(kind.Name, "vulnerabilityreports") {
+			continue
+		}
+		for _, report := range h.cache.GetReports(kind.Name, clusterFilter, namespaceFilters) {
+			ref := reportImageRef(report)
+			if ref == "" {
+				continue
+			}
+			agg, ok := byImage[ref]
+			if !ok {
+				_, repo := reportRepository(report)
+				artifact := reportSection(report, "artifact")
+				tag, _ := artifact["tag"].(string)
+				digest, _ := artifact["digest"].(string)
+				agg = &imageAggregate{
+					image:    ImageHygiene{Image: ref, Repository: repo, Tag: tag, Digest: digest},
+					latest:   report,
+					clusters: make(map[string]bool),
+				}
+				agg.image.Mutable, agg.image.Latest = imageMutability(tag, digest)
+				byImage[ref] = agg
+			}
+			agg.image.Workloads++
+			agg.clusters[report.Cluster] = true
+			if reportTime(report).After(reportTime(agg.latest)) {
+				agg.latest = report
+			}
+			if created := reportImageCreated(report); !created.IsZero() && agg.image.Created == nil {
+				agg.image.Created = &created
+			}
+		}
+	}
+
+	result := ImageHygieneSummary{
+		StaleAfterDays: int(staleAfter.Hours() / 24),
+		Groups:         make(map[string]ImageHygieneGroup),
+		Items:          make([]ImageHygiene, 0, len(byImage)),
+	}
+	for _, agg := range byImage {
+		image := agg.image
+		c, hi, m, l := extractSummaryCounts(agg.latest)
+		image.Severity = SeverityTotals{Critical: c, High: hi, Medium: m, Low: l}
+		for cluster := range agg.clusters {
+			image.Clusters = append(image.Clusters, cluster)
+		}
+		sort.Strings(image.Clusters)
+
+		result.Images++
+		groups := []string{hygieneGroupPinned}
+		if image.Mutable {
+			result.Mutable++
+			groups[0] = hygieneGroupMutable
+		}
+		if image.Latest {
+			result.Latest++
+		}
+		if image.Created == nil {
+			result.UnknownAge++
+		} else {
+			age := int(now.Sub(*image.Created).Hours() / 24)
+			image.AgeDays = &age
+			image.Stale = now.Sub(*image.Created) >= staleAfter
+			if image.Stale {
+				result.Stale++
+				groups = append(groups, hygieneGroupStale)
+			} else {
+				groups = append(groups, hygieneGroupFresh)
+			}
+		}
+		for _, name := range groups {
+			g := result.Groups[name]
+			g.Images++
+			g.Workloads += image.Workloads
+			g.Severity.Critical += c
+			g.Severity.High += hi
+			g.Severity.Medium += m
+			g.Severity.Low += l
+			result.Groups[name] = g
+		}
+		result.Items = append(result.Items, image)
+	}
+	for name, g := range result.Groups {
+		g.AvgCriticalHigh = roundScore(float64(g.Severity.Critical+g.Severity.High) / float64(g.Images))
+		result.Groups[name] = g
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		a, b := result.Items[i], result.Items[j]
+		if a.Stale != b.Stale {
+			return a.Stale
+		}
+		if a.Mutable != b.Mutable {
+			return a.Mutable
+		}
+		if a.Severity.Critical != b.Severity.Critical {
+			return a.Severity.Critical > b.Severity.Critical
+		}
+		return a.Image < b.Image
+	})
+	return result
+}
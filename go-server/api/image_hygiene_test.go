@@ -0,0 +1,65 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+)
+
+func hygieneReport(name, cluster, tag, digest, created string, critical float64) Report {
+	artifact := map[string]interface{}{"repository": "org/app", "tag": tag, "digest": digest}
+	if created != "" {
+		artifact["annotations"] = map[string]interface{}{ociCreatedAnnotation: created}
+	}
+	return Report{Name: name, Cluster: cluster, Namespace: "apps", Type: "vulnerabilityreports", Data: map[string]interface{}{
+		"report": map[string]interface{}{
+			"artifact": artifact,
+			"summary":  map[string]interface{}{"criticalCount": critical},
+		},
+	}}
+}
+
+func TestImageMutability(t *testing.T) {
+	for _, tc := range []struct {
+		tag, digest     string
+		mutable, latest bool
+	}{
+		{"v1.2.0", "", true, false},
+		{"latest", "sha256:abc", true, true},
+		{"", "", true, true},
+		{"", "sha256:abc", false, false},
+	} {
+		if mutable, latest := imageMutability(tc.tag, tc.digest); mutable != tc.mutable || latest != tc.latest {
+			t.Errorf("imageMutability(%q, %q) = %v, %v", tc.tag, tc.digest, mutable, latest)
+		}
+	}
+}
+
+func TestComputeImageHygiene(t *testing.T) {
+	config.GetGlobalRegistry().Register(config.ReportKind{Name: "vulnerabilityreports", Namespaced: true})
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cache := &stubCacheService{reports: map[string][]Report{"vulnerabilityreports": {
+		hygieneReport("old-1", "c1", "latest", "", "2025-12-01T00:00:00Z", 4),
+		hygieneReport("old-2", "c2", "latest", "", "2025-12-01T00:00:00Z", 4),
+		hygieneReport("new", "c1", "", "sha256:abc", "2026-05-20T00:00:00Z", 0),
+		hygieneReport("unknown", "c1", "v2", "", "", 1),
+	}}}
+	h := &Handler{cache: cache, crdReg: config.GetGlobalRegistry()}
+
+	s := h.computeImageHygiene("", nil, 90*24*time.Hour, now)
+	if s.Images != 3 || s.Mutable != 2 || s.Latest != 1 || s.Stale != 1 || s.UnknownAge != 1 {
+		t.Fatalf("unexpected summary %+v", s)
+	}
+	first := s.Items[0]
+	if first.Image != "org/app:latest" || !first.Stale || first.Workloads != 2 || len(first.Clusters) != 2 || *first.AgeDays != 182 {
+		t.Fatalf("stale image should come first: %+v", first)
+	}
+	// an image run by two workloads counts its findings once
+	if g := s.Groups[hygieneGroupMutable]; g.Images != 2 || g.Severity.Critical != 5 || g.AvgCriticalHigh != 2.5 {
+		t.Fatalf("unexpected mutable group %+v", g)
+	}
+	if g := s.Groups[hygieneGroupFresh]; g.Images != 1 || g.Severity.Critical != 0 {
+		t.Fatalf("unexpected fresh group %+v", g)
+	}
+}
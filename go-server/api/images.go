@@ -0,0 +1,248 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ImageVulnerability struct {
+	VulnerabilityID  string `json:"vulnerabilityID"`
+	Resource         string `json:"resource"`
+	Severity         string `json:"severity"`
+	InstalledVersion string `json:"installedVersion,omitempty"`
+	FixedVersion     string `json:"fixedVersion,omitempty"`
+	Title            string `json:"title,omitempty"`
+	Target           string `json:"target,omitempty"`
+	// Occurrences and Targets are set when duplicates are collapsed with dedupe=true
+	Occurrences int      `json:"occurrences,omitempty"`
+	Targets     []string `json:"targets,omitempty"`
+}
+
+type ImageTagSide struct {
+	Tag        string           `json:"tag"`
+	Report     ReportRef        `json:"report"`
+	Clusters   []string         `json:"clusters"`
+	Severity   SeverityTotals   `json:"severity"`
+	Provenance *ImageProvenance `json:"provenance,omitempty"`
+}
+
+type ImageCompareResult struct {
+	Repository string               `json:"repository"`
+	From       ImageTagSide         `json:"from"`
+	To         ImageTagSide         `json:"to"`
+	Added      []ImageVulnerability `json:"added"`
+	Removed    []ImageVulnerability `json:"removed"`
+	Unchanged  int                  `json:"unchanged"`
+	Delta      SeverityTotals       `json:"delta"`
+}
+
+// reportRepository returns the artifact repository with and without the registry server.
+func reportRepository(r Report) (repo, full string) {
+	artifact := reportSection(r, "artifact")
+	if artifact == nil {
+		return "", ""
+	}
+	repo, _ = artifact["repository"].(string)
+	full = repo
+	if registry := reportSection(r, "registry"); registry != nil {
+		if server, _ := registry["server"].(string); server != "" {
+			full = server + "/" + repo
+		}
+	}
+	return repo, full
+}
+
+func reportTag(r Report) string {
+	artifact := reportSection(r, "artifact")
+	if artifact == nil {
+		return ""
+	}
+	tag, _ := artifact["tag"].(string)
+	return tag
+}
+
+func reportVulnerabilities(r Report) []ImageVulnerability {
+	data, ok := r.Data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if inner, ok := data["report"].(map[string]interface{}); ok {
+		data = inner
+	}
+	raw, _ := data["vulnerabilities"].([]interface{})
+	vulns := make([]ImageVulnerability, 0, len(raw))
+	for _, v := range raw {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var iv ImageVulnerability
+		iv.VulnerabilityID, _ = m["vulnerabilityID"].(string)
+		iv.Resource, _ = m["resource"].(string)
+		iv.Severity, _ = m["severity"].(string)
+		iv.InstalledVersion, _ = m["installedVersion"].(string)
+		iv.FixedVersion, _ = m["fixedVersion"].(string)
+		iv.Title, _ = m["title"].(string)
+		iv.Target, _ = m["target"].(string)
+		if iv.VulnerabilityID != "" {
+			vulns = append(vulns, iv)
+		}
+	}
+	return vulns
+}
+
+// diffVulnerabilities matches findings by vulnerability ID and package.
+func diffVulnerabilities(from, to []ImageVulnerability) (added, removed []ImageVulnerability, unchanged int) {
+	key := func(v ImageVulnerability) string { return v.VulnerabilityID + "|" + v.Resource }
+	fromSet := make(map[string]bool, len(from))
+	for _, v := range from {
+		fromSet[key(v)] = true
+	}
+	toSet := make(map[string]bool, len(to))
+	added, removed = []ImageVulnerability{}, []ImageVulnerability{}
+	for _, v := range to {
+		k := key(v)
+		if toSet[k] {
+			continue
+		}
+		toSet[k] = true
+		if fromSet[k] {
+			unchanged++
+		} else {
+			added = append(added, v)
+		}
+	}
+	for _, v := range from {
+		k := key(v)
+		if !toSet[k] {
+			removed = append(removed, v)
+			toSet[k] = true // report duplicates once
+		}
+	}
+	return added, removed, unchanged
+}
+
+func severityCount(t *SeverityTotals, severity string, sign int) {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL":
+		t.Critical += sign
+	case "HIGH":
+		t.High += sign
+	case "MEDIUM":
+		t.Medium += sign
+	case "LOW":
+		t.Low += sign
+	}
+}
+
+// GetImageCompare diffs the vulnerabilities of two tags of one repository, using the most
+// recently scanned report of each tag across all clusters.
+func (h *Handler) GetImageCompare(w http.ResponseWriter, r *http.Request) {
+	repository := r.URL.Query().Get("repository")
+	var tags []string
+	for _, t := range strings.Split(r.URL.Query().Get("tags"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	if repository == "" || len(tags) != 2 {
+		writeError(w, http.StatusBadRequest, "repository and exactly two tags are required")
+		return
+	}
+	dedupe := wantsDedupe(r)
+
+	type candidate struct {
+		report   Report
+		clusters map[string]bool
+	}
+	found := make(map[string]*candidate, 2)
+	for _, kind := range h.crdReg.GetAllReports() {
+		for _, report := range h.cache.GetReports(kind.Name, "", nil) {
+			repo, full := reportRepository(report)
+			if repo != repository && full != repository {
+				continue
+			}
+			tag := reportTag(report)
+			if tag != tags[0] && tag != tags[1] {
+				continue
+			}
+			c, ok := found[tag]
+			if !ok {
+				c = &candidate{report: report, clusters: make(map[string]bool)}
+				found[tag] = c
+			}
+			c.clusters[report.Cluster] = true
+			if reportTime(report).After(reportTime(c.report)) {
+				c.report = report
+			}
+		}
+	}
+	for _, tag := range tags {
+		if found[tag] == nil {
+			writeError(w, http.StatusNotFound, "No report found for tag "+tag)
+			return
+		}
+	}
+
+	sides := make([]ImageTagSide, 2)
+	vulns := make([][]ImageVulnerability, 2)
+	for i, tag := range tags {
+		c := found[tag]
+		kind := h.crdReg.GetReportByName(c.report.Type)
+		if kind == nil {
+			writeError(w, http.StatusInternalServerError, "Unknown report type "+c.report.Type)
+			return
+		}
+		detail, err := h.loadReportDetail(r.Context(), *kind, c.report.Cluster, c.report.Namespace, c.report.Name)
+		if err != nil {
+			if r.Context().Err() == context.Canceled {
+				return
+			}
+			writeError(w, http.StatusBadGateway, "Failed to load report for tag "+tag+": "+err.Error())
+			return
+		}
+		vulns[i] = reportVulnerabilities(detail)
+		if dedupe {
+			vulns[i] = dedupeImageVulnerabilities(vulns[i])
+		}
+
+		side := ImageTagSide{
+			Tag:        tag,
+			Report:     ReportRef{Cluster: c.report.Cluster, Namespace: c.report.Namespace, Name: c.report.Name},
+			Provenance: reportProvenance(detail),
+		}
+		for cluster := range c.clusters {
+			side.Clusters = append(side.Clusters, cluster)
+		}
+		sort.Strings(side.Clusters)
+		for _, v := range vulns[i] {
+			severityCount(&side.Severity, v.Severity, 1)
+		}
+		sides[i] = side
+	}
+
+	result := ImageCompareResult{Repository: repository, From: sides[0], To: sides[1]}
+	result.Added, result.Removed, result.Unchanged = diffVulnerabilities(vulns[0], vulns[1])
+	for _, v := range result.Added {
+		severityCount(&result.Delta, v.Severity, 1)
+	}
+	for _, v := range result.Removed {
+		severityCount(&result.Delta, v.Severity, -1)
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    result,
+	})
+}
+
+func reportTime(r Report) time.Time {
+	if !r.ScannedAt.IsZero() {
+		return r.ScannedAt
+	}
+	return r.CachedAt
+}
@@ -0,0 +1,39 @@
+package api
+
+import "testing"
+
+func TestDiffVulnerabilities(t *testing.T) {
+	from := []ImageVulnerability{
+		{VulnerabilityID: "CVE-1", Resource: "openssl", Severity: "CRITICAL"},
+		{VulnerabilityID: "CVE-2", Resource: "zlib", Severity: "HIGH"},
+	}
+	to := []ImageVulnerability{
+		{VulnerabilityID: "CVE-2", Resource: "zlib", Severity: "HIGH"},
+		{VulnerabilityID: "CVE-3", Resource: "curl", Severity: "MEDIUM"},
+		{VulnerabilityID: "CVE-3", Resource: "curl", Severity: "MEDIUM"},
+	}
+
+	added, removed, unchanged := diffVulnerabilities(from, to)
+	if len(added) != 1 || added[0].VulnerabilityID != "CVE-3" {
+		t.Fatalf("unexpected added %+v", added)
+	}
+	if len(removed) != 1 || removed[0].VulnerabilityID != "CVE-1" {
+		t.Fatalf("unexpected removed %+v", removed)
+	}
+	if unchanged != 1 {
+		t.Fatalf("expected 1 unchanged got %d", unchanged)
+	}
+}
+
+func TestReportRepository_WithRegistry(t *testing.T) {
+	r := Report{Data: map[string]interface{}{
+		"report": map[string]interface{}{
+			"artifact": map[string]interface{}{"repository": "foo/bar", "tag": "v1.2.0"},
+			"registry": map[string]interface{}{"server": "ghcr.io"},
+		},
+	}}
+	repo, full := reportRepository(r)
+	if repo != "foo/bar" || full != "ghcr.io/foo/bar" || reportTag(r) != "v1.2.0" {
+		t.Fatalf("unexpected repo=%q full=%q tag=%q", repo, full, reportTag(r))
+	}
+}
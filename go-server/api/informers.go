@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"trivy-ui/kubernetes"
+)
+
+// ClusterInformerEvents are the informer events of one cluster per report kind, to tell
+// whether missing data is the operator not updating reports or trivy-ui not applying them.
+// The objects held by the informers are in /api/v1/admin/runtime.
+type ClusterInformerEvents struct {
+	Cluster   string `json:"cluster"`
+	SyncState string `json:"syncState,omitempty"`
+	// Informer is false for clusters without informers, e.g. pushed clusters and those
+	// loaded from report files
+	Informer bool                        `json:"informer"`
+	Kinds    []kubernetes.KindEventStats `json:"kinds"`
+}
+
+func (h *Handler) informerStats(clusters map[string]bool) []ClusterInformerEvents {
+	result := []ClusterInformerEvents{}
+	for name, cc := range h.clusterReg.All() {
+		if clusters != nil && !clusters[name] {
+			continue
+		}
+		stats := ClusterInformerEvents{Cluster: name, SyncState: cc.info().SyncState, Kinds: []kubernetes.KindEventStats{}}
+		if cc.Client != nil {
+			if informer := cc.Client.GetInformer(); informer != nil {
+				stats.Informer, stats.Kinds = true, informer.EventStats()
+			}
+		}
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Cluster < result[j].Cluster })
+	return result
+}
+
+// GetInformerStats handles GET /api/v1/admin/informers: per cluster and report kind, the
+// adds, updates and deletes received in total and over the last minute, the no-op resync
+// updates, the dropped events and when the last events arrived. ?cluster= narrows it.
+func (h *Handler) GetInformerStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    h.informerStats(clusterSet(clusterParam(r))),
+	})
+}
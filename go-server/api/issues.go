@@ -0,0 +1,264 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"trivy-ui/config"
+	"trivy-ui/credentials"
+	"trivy-ui/issues"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+var (
+	issueOnce      sync.Once
+	issueTracker   issues.Tracker
+	issueTemplates *issues.Templates
+	issueInitErr   error
+)
+
+// getIssueTracker builds the configured tracker once; a nil tracker means the integration is disabled.
+func getIssueTracker() (issues.Tracker, *issues.Templates, error) {
+	issueOnce.Do(func() {
+		cfg := config.Get()
+		if cfg.IssueProvider == "" {
+			return
+		}
+		issueTracker, issueInitErr = issues.NewWithTokenSource(cfg.IssueProvider, cfg.IssueAPIURL, func() string {
+			return credentials.Get(credentials.IssueToken)
+		})
+		if issueInitErr != nil {
+			return
+		}
+		issueTemplates, issueInitErr = issues.LoadTemplates(cfg.IssueTemplate)
+	})
+	return issueTracker, issueTemplates, issueInitErr
+}
+
+type IssueRequest struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	FindingID string `json:"findingId"`
+	Resource  string `json:"resource"`
+	Team      string `json:"team"`
+}
+
+type IssueResponse struct {
+	store.IssueLink
+	Created bool `json:"created"`
+}
+
+// resolveIssueTeam picks the team for a finding: explicit request, then triage assignee.
+func resolveIssueTeam(ctx context.Context, st *store.Store, req IssueRequest) string {
+	if req.Team != "" {
+		return req.Team
+	}
+	ctx, cancel := storeCallContext(ctx)
+	defer cancel()
+	records, err := st.ListTriage(ctx, store.TriageFilter{
+		Cluster:    req.Cluster,
+		Namespace:  req.Namespace,
+		ReportType: req.Type,
+		ReportName: req.Name,
+		FindingID:  req.FindingID,
+	})
+	if err == nil {
+		for _, rec := range records {
+			if rec.Resource == req.Resource && rec.Assignee != "" {
+				return rec.Assignee
+			}
+		}
+	}
+	return "default"
+}
+
+// CreateIssue files a GitHub/GitLab issue for a finding in the repository mapped to its team.
+// If an issue was already filed for the finding in that repository, the existing link is returned.
+func (h *Handler) CreateIssue(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	tracker, templates, err := getIssueTracker()
+	if err != nil {
+		utils.LogWarning("Issue integration misconfigured", map[string]interface{}{"error": err.Error()})
+		writeError(w, http.StatusServiceUnavailable, "Issue integration misconfigured")
+		return
+	}
+	if tracker == nil {
+		writeError(w, http.StatusServiceUnavailable, "Issue integration not configured")
+		return
+	}
+
+	var req IssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Cluster == "" || req.Type == "" || req.Name == "" || req.FindingID == "" {
+		writeError(w, http.StatusBadRequest, "cluster, type, name and findingId are required")
+		return
+	}
+
+	team := resolveIssueTeam(r.Context(), st, req)
+	repos := config.Get().IssueRepos
+	repo, ok := repos[team]
+	if !ok {
+		repo, ok = repos["default"]
+	}
+	if !ok {
+		writeError(w, http.StatusBadRequest, "No repository mapped for team: "+team)
+		return
+	}
+
+	lookupCtx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	existing, err := st.GetIssueLink(lookupCtx, tracker.Provider(), repo, req.FindingID, req.Resource)
+	if err == nil {
+		writeJSON(w, http.StatusOK, Response{
+			Code:    CodeSuccess,
+			Message: "Success",
+			Data:    IssueResponse{IssueLink: existing},
+		})
+		return
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		if clientGone(r.Context()) {
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to look up issue link")
+		return
+	}
+
+	data := h.issueTemplateData(r, st, req)
+	data.Team = team
+	title, body, err := templates.Render(data)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to render issue template: "+err.Error())
+		return
+	}
+
+	issue, err := tracker.CreateIssue(r.Context(), repo, title, body, config.Get().IssueLabels)
+	if err != nil {
+		utils.LogWarning("Failed to create issue", map[string]interface{}{
+			"provider": tracker.Provider(),
+			"repo":     repo,
+			"finding":  req.FindingID,
+			"error":    err.Error(),
+		})
+		writeError(w, http.StatusBadGateway, "Failed to create issue")
+		return
+	}
+
+	// the issue exists now; keep its link even if the client disconnected meanwhile
+	saveCtx, cancel := storeCallContext(context.WithoutCancel(r.Context()))
+	defer cancel()
+	link, err := st.SaveIssueLink(saveCtx, store.IssueLink{
+		Provider:  tracker.Provider(),
+		Repo:      repo,
+		FindingID: req.FindingID,
+		Resource:  req.Resource,
+		Number:    issue.Number,
+		URL:       issue.URL,
+		ReportRef: store.ReportRef{Cluster: req.Cluster, Namespace: req.Namespace, ReportType: req.Type, ReportName: req.Name},
+	})
+	if err != nil {
+		utils.LogWarning("Issue created but link not stored", map[string]interface{}{"url": issue.URL, "error": err.Error()})
+	}
+	writeJSON(w, http.StatusCreated, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    IssueResponse{IssueLink: link, Created: true},
+	})
+}
+
+// issueTemplateData collects CVE details from the report and the workloads affected by the finding.
+func (h *Handler) issueTemplateData(r *http.Request, st *store.Store, req IssueRequest) issues.TemplateData {
+	data := issues.TemplateData{FindingID: req.FindingID, Resource: req.Resource}
+
+	if reportKind := h.crdReg.GetReportByName(req.Type); reportKind != nil {
+		if report, err := h.loadReportDetail(r.Context(), *reportKind, req.Cluster, req.Namespace, req.Name); err == nil {
+			fillVulnerabilityDetails(&data, report)
+		}
+	}
+
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	refs, err := st.ListAffectedReports(ctx, req.FindingID, req.Resource)
+	if err != nil || len(refs) == 0 {
+		refs = []store.ReportRef{{Cluster: req.Cluster, Namespace: req.Namespace, ReportType: req.Type, ReportName: req.Name}}
+	}
+	for _, ref := range refs {
+		data.Workloads = append(data.Workloads, issues.Workload{Cluster: ref.Cluster, Namespace: ref.Namespace, Name: ref.ReportName})
+	}
+	return data
+}
+
+func fillVulnerabilityDetails(data *issues.TemplateData, report Report) {
+	reportObj, ok := report.Data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if inner, ok := reportObj["report"].(map[string]interface{}); ok {
+		reportObj = inner
+	}
+	vulns, _ := reportObj["vulnerabilities"].([]interface{})
+	for _, v := range vulns {
+		vuln, ok := v.(map[string]interface{})
+		if !ok || vuln["vulnerabilityID"] != data.FindingID {
+			continue
+		}
+		if pkg, _ := vuln["resource"].(string); data.Resource != "" && pkg != data.Resource {
+			continue
+		}
+		data.Severity, _ = vuln["severity"].(string)
+		data.Title, _ = vuln["title"].(string)
+		data.Description, _ = vuln["description"].(string)
+		data.InstalledVersion, _ = vuln["installedVersion"].(string)
+		data.FixedVersion, _ = vuln["fixedVersion"].(string)
+		data.PrimaryLink, _ = vuln["primaryLink"].(string)
+		data.Score, _ = vuln["score"].(float64)
+		if data.Resource == "" {
+			data.Resource, _ = vuln["resource"].(string)
+		}
+		break
+	}
+	data.Severity = strings.ToUpper(data.Severity)
+}
+
+func (h *Handler) ListIssues(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+
+	q := r.URL.Query()
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	links, err := st.ListIssueLinks(ctx, store.IssueLinkFilter{
+		Provider:  q.Get("provider"),
+		Repo:      q.Get("repo"),
+		FindingID: q.Get("findingId"),
+		Cluster:   q.Get("cluster"),
+	})
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		utils.LogWarning("Failed to list issue links", map[string]interface{}{"error": err.Error()})
+		writeError(w, http.StatusInternalServerError, "Failed to list issues")
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    links,
+	})
+}
@@ -0,0 +1,357 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/defectdojo"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// Job kinds.
+const (
+	// JobKindExport renders the selected reports as a csv or json file to download
+	JobKindExport = "export"
+	// JobKindDefectDojo pushes the findings of the selected reports to DefectDojo
+	JobKindDefectDojo = "defectdojo"
+	// JobKindExportSchedule runs a saved export schedule and delivers it to its destination
+	JobKindExportSchedule = "export-schedule"
+)
+
+const (
+	// jobPollInterval bounds how long a job queued by another replica waits for a worker
+	jobPollInterval  = 5 * time.Second
+	jobPurgeInterval = time.Hour
+	jobListLimit     = 100
+)
+
+// JobRequest creates a job; which fields apply depends on Kind.
+type JobRequest struct {
+	Kind string `json:"kind"`
+	// Format is csv or json for exports
+	Format string            `json:"format,omitempty"`
+	Query  store.ExportQuery `json:"query"`
+	// Target overrides the DefectDojo product and engagement, as for /api/v1/integrations/defectdojo
+	Target     string `json:"target,omitempty"`
+	ScheduleID int64  `json:"scheduleId,omitempty"`
+}
+
+// jobRunner runs queued jobs on a pool of workers. Jobs are claimed from the store, so
+// they survive restarts and are never run twice.
+type jobRunner struct {
+	querySvc QueryService
+	wake     chan struct{}
+	// running maps the ids of the jobs running here to the cancel func of their context
+	running sync.Map
+}
+
+var jobs = &jobRunner{wake: make(chan struct{}, 1)}
+
+// notify wakes an idle worker for a newly queued job.
+func (j *jobRunner) notify() {
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+}
+
+// StartJobWorkers starts the job workers and the purge of finished jobs past their
+// retention. Jobs left running by a previous process are queued again first.
+func StartJobWorkers(ctx context.Context, cache CacheService, workers int, retention time.Duration) {
+	st := store.Get()
+	if st == nil || workers <= 0 {
+		return
+	}
+	jobs.querySvc = NewQueryService(cache)
+
+	requeueCtx, cancel := storeCallContext(ctx)
+	if n, err := st.RequeueRunningJobs(requeueCtx); err != nil {
+		utils.LogWarning("Failed to requeue interrupted jobs", map[string]interface{}{"error": err.Error()})
+	} else if n > 0 {
+		utils.LogInfo("Requeued interrupted jobs", map[string]interface{}{"count": n})
+	}
+	cancel()
+
+	for i := 0; i < workers; i++ {
+		go jobs.work(ctx, st)
+	}
+	go func() {
+		ticker := time.NewTicker(jobPurgeInterval)
+		defer ticker.Stop()
+		for {
+			purgeCtx, cancel := storeCallContext(ctx)
+			if n, err := st.DeleteFinishedJobs(purgeCtx, time.Now().Add(-retention)); err != nil {
+				utils.LogWarning("Failed to purge finished jobs", map[string]interface{}{"error": err.Error()})
+			} else if n > 0 {
+				utils.LogInfo("Purged finished jobs", map[string]interface{}{"count": n})
+			}
+			cancel()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (j *jobRunner) work(ctx context.Context, st *store.Store) {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for {
+		for j.runNext(ctx, st) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-j.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext runs the oldest queued job, and reports whether there was one.
+func (j *jobRunner) runNext(ctx context.Context, st *store.Store) bool {
+	claimCtx, cancel := storeCallContext(ctx)
+	job, found, err := st.ClaimJob(claimCtx)
+	cancel()
+	if err != nil {
+		utils.LogWarning("Failed to claim job", map[string]interface{}{"error": err.Error()})
+		return false
+	}
+	if !found {
+		return false
+	}
+	// let another worker take the next job meanwhile
+	j.notify()
+
+	runCtx, cancelRun := context.WithTimeout(ctx, exportRunTimeout)
+	j.running.Store(job.ID, cancelRun)
+	start := time.Now()
+	result, err := j.run(runCtx, st, job)
+	j.running.Delete(job.ID)
+	cancelRun()
+
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+		utils.LogWarning("Job failed", map[string]interface{}{"id": job.ID, "kind": job.Kind, "error": errMsg})
+	} else {
+		utils.LogInfo("Job finished", map[string]interface{}{"id": job.ID, "kind": job.Kind, "duration": time.Since(start).String()})
+	}
+	recCtx, cancel := storeCallContext(context.WithoutCancel(ctx))
+	defer cancel()
+	if err := st.FinishJob(recCtx, job.ID, errMsg, result); err != nil {
+		utils.LogWarning("Failed to record job outcome", map[string]interface{}{"id": job.ID, "error": err.Error()})
+	}
+	return true
+}
+
+// run does the work of one job and returns its result, if it has one.
+func (j *jobRunner) run(ctx context.Context, st *store.Store, job store.Job) (*store.JobResult, error) {
+	var req JobRequest
+	if err := json.Unmarshal(job.Params, &req); err != nil {
+		return nil, fmt.Errorf("invalid job parameters: %w", err)
+	}
+	switch job.Kind {
+	case JobKindExport:
+		reports, err := exportReports(j.querySvc, req.Query)
+		if err != nil {
+			return nil, err
+		}
+		file, err := exportFile(reports, req.Query.Type, req.Format, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return &store.JobResult{Name: file.Name, ContentType: file.ContentType, Data: file.Data}, nil
+	case JobKindDefectDojo:
+		results, err := pushToDefectDojo(ctx, j.querySvc, req.Query, req.Target)
+		if results == nil {
+			return nil, err
+		}
+		data, marshalErr := json.MarshalIndent(results, "", "  ")
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		return &store.JobResult{Name: fmt.Sprintf("defectdojo-%d.json", job.ID), ContentType: "application/json", Data: data}, err
+	case JobKindExportSchedule:
+		dbCtx, cancel := storeCallContext(ctx)
+		sched, err := st.GetExportSchedule(dbCtx, req.ScheduleID)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("export schedule %d: %w", req.ScheduleID, err)
+		}
+		return nil, runExport(ctx, st, j.querySvc, sched)
+	}
+	return nil, fmt.Errorf("unknown job kind %q", job.Kind)
+}
+
+// validateJobRequest checks a job can run before it is queued, and canonicalizes its
+// report type.
+func (h *Handler) validateJobRequest(ctx context.Context, st *store.Store, req *JobRequest) error {
+	if req.Kind == JobKindExportSchedule {
+		dbCtx, cancel := storeCallContext(ctx)
+		defer cancel()
+		if _, err := st.GetExportSchedule(dbCtx, req.ScheduleID); err != nil {
+			return fmt.Errorf("export schedule %d: %w", req.ScheduleID, err)
+		}
+		return nil
+	}
+	reportKind := h.crdReg.ResolveReport(req.Query.Type)
+	if reportKind == nil {
+		return errors.New("invalid report type")
+	}
+	req.Query.Type = reportKind.Name
+	if _, err := parseReportFilter(req.Query.Filter); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	switch req.Kind {
+	case JobKindExport:
+		if req.Format != ExportFormatCSV && req.Format != ExportFormatJSON {
+			return errors.New("format must be csv or json")
+		}
+	case JobKindDefectDojo:
+		if _, err := newDefectDojoClient(config.Get()); err != nil {
+			return err
+		}
+		if _, _, err := defectdojo.ParseTarget(req.Target); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("kind must be %s, %s or %s", JobKindExport, JobKindDefectDojo, JobKindExportSchedule)
+	}
+	return nil
+}
+
+// enqueueJob validates and queues a job and answers 202 Accepted with it.
+func (h *Handler) enqueueJob(w http.ResponseWriter, r *http.Request, st *store.Store, req JobRequest) {
+	if err := h.validateJobRequest(r.Context(), st, &req); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errDefectDojoDisabled) {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	params, err := json.Marshal(req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	job, err := st.CreateJob(ctx, req.Kind, params)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jobs.notify()
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/jobs/%d", job.ID))
+	writeJSON(w, http.StatusAccepted, Response{
+		Code:    CodeSuccess,
+		Message: "Job queued",
+		Data:    job,
+	})
+}
+
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	list, err := st.ListJobs(ctx, jobListLimit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    list,
+	})
+}
+
+func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	var req JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	h.enqueueJob(w, r, st, req)
+}
+
+// JobByID serves GET /api/v1/jobs/{id}, GET /api/v1/jobs/{id}/result and
+// POST /api/v1/jobs/{id}/cancel.
+func (h *Handler) JobByID(w http.ResponseWriter, r *http.Request, idStr, action string) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid job id")
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+
+	var job store.Job
+	switch action {
+	case "result":
+		result, found, err := st.GetJobResult(ctx, id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !found {
+			writeError(w, http.StatusNotFound, "Job has no result")
+			return
+		}
+		w.Header().Set("Content-Type", result.ContentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": result.Name}))
+		w.Write(result.Data)
+		return
+	case "cancel":
+		job, err = st.CancelJob(ctx, id)
+		if errors.Is(err, store.ErrJobFinished) {
+			writeError(w, http.StatusConflict, "Job already finished")
+			return
+		}
+		if err == nil {
+			if cancelRun, ok := jobs.running.Load(id); ok {
+				cancelRun.(context.CancelFunc)()
+			}
+			utils.LogInfo("Job cancelled", map[string]interface{}{"id": id, "kind": job.Kind})
+		}
+	default:
+		job, err = st.GetJob(ctx, id)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    job,
+	})
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trivy-ui/store"
+)
+
+func TestJobRunnerRunsExport(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	cache := &stubCacheService{reports: map[string][]Report{
+		"jobtestreports": {
+			makeReport("app-a", "prod", "payments", "jobtestreports", 2),
+			makeReport("app-b", "prod", "payments", "jobtestreports", 0),
+		},
+	}}
+	runner := &jobRunner{querySvc: NewQueryService(cache), wake: make(chan struct{}, 1)}
+
+	params, _ := json.Marshal(JobRequest{
+		Kind: JobKindExport, Format: ExportFormatCSV,
+		Query: store.ExportQuery{Type: "jobtestreports", Filter: "critical > 0"},
+	})
+	job, err := st.CreateJob(t.Context(), JobKindExport, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !runner.runNext(context.Background(), st) {
+		t.Fatal("queued job not run")
+	}
+	if runner.runNext(context.Background(), st) {
+		t.Fatal("job run twice")
+	}
+
+	got, err := st.GetJob(t.Context(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != store.JobStatusSucceeded || got.ResultType != "text/csv" || !strings.HasSuffix(got.ResultName, ".csv") {
+		t.Fatalf("unexpected job %+v", got)
+	}
+	result, found, err := st.GetJobResult(t.Context(), job.ID)
+	if err != nil || !found {
+		t.Fatalf("result not stored: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(result.Data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "prod,payments,jobtestreports,app-a,") {
+		t.Errorf("unexpected export %q", result.Data)
+	}
+}
+
+func TestJobRunnerRecordsFailure(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	runner := &jobRunner{querySvc: NewQueryService(&stubCacheService{}), wake: make(chan struct{}, 1)}
+	params, _ := json.Marshal(JobRequest{Kind: JobKindExportSchedule, ScheduleID: 42})
+	job, err := st.CreateJob(t.Context(), JobKindExportSchedule, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner.runNext(context.Background(), st)
+
+	got, _ := st.GetJob(t.Context(), job.ID)
+	if got.Status != store.JobStatusFailed || !strings.Contains(got.Error, "export schedule 42") {
+		t.Errorf("unexpected job %+v", got)
+	}
+	if _, found, _ := st.GetJobResult(t.Context(), job.ID); found {
+		t.Error("failed job has a result")
+	}
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"trivy-ui/config"
+	"trivy-ui/utils"
+)
+
+// linkPlaceholder matches the {{variable}} placeholders of a link template.
+var linkPlaceholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// reportLinkVars are available to every template; a template using only these is
+// rendered once per report.
+var reportLinkVars = map[string]bool{
+	"cluster": true, "namespace": true, "name": true, "type": true,
+	"registry": true, "repository": true, "tag": true, "digest": true, "image": true,
+	"source": true, "revision": true, "commitUrl": true,
+}
+
+// findingLinkVars are only known per vulnerability or check; templates using any of them
+// are rendered per finding in report details.
+var findingLinkVars = map[string]bool{
+	"cve": true, "package": true, "installedVersion": true, "fixedVersion": true, "checkID": true,
+}
+
+type linkTemplate struct {
+	name    string
+	raw     string
+	finding bool
+}
+
+var (
+	linkTemplatesOnce sync.Once
+	linkTemplatesList []linkTemplate
+)
+
+// getLinkTemplates parses LINK_TEMPLATES once. Templates with unknown variables are dropped.
+func getLinkTemplates() []linkTemplate {
+	linkTemplatesOnce.Do(func() {
+		linkTemplatesList = parseLinkTemplates(config.Get().LinkTemplates)
+	})
+	return linkTemplatesList
+}
+
+func parseLinkTemplates(raw map[string]string) []linkTemplate {
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var templates []linkTemplate
+	for _, name := range names {
+		t := linkTemplate{name: name, raw: raw[name]}
+		valid := true
+		for _, m := range linkPlaceholder.FindAllStringSubmatch(t.raw, -1) {
+			switch {
+			case findingLinkVars[m[1]]:
+				t.finding = true
+			case !reportLinkVars[m[1]]:
+				utils.LogWarning("Unknown link template variable, template ignored", map[string]interface{}{"template": name, "variable": m[1]})
+				valid = false
+			}
+		}
+		if valid {
+			templates = append(templates, t)
+		}
+	}
+	return templates
+}
+
+// renderLink fills a template; it fails when a variable has no value, so links never point
+// at half-filled URLs.
+func renderLink(raw string, vars map[string]string) (string, bool) {
+	ok := true
+	link := linkPlaceholder.ReplaceAllStringFunc(raw, func(match string) string {
+		value := vars[linkPlaceholder.FindStringSubmatch(match)[1]]
+		if value == "" {
+			ok = false
+		}
+		return escapeLinkValue(value)
+	})
+	return link, ok
+}
+
+// escapeLinkValue path-escapes a value but keeps slashes, so repositories such as
+// "library/nginx" stay readable path segments.
+func escapeLinkValue(value string) string {
+	segments := strings.Split(value, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func renderLinks(templates []linkTemplate, finding bool, vars map[string]string) map[string]string {
+	var links map[string]string
+	for _, t := range templates {
+		if t.finding != finding {
+			continue
+		}
+		if link, ok := renderLink(t.raw, vars); ok {
+			if links == nil {
+				links = make(map[string]string)
+			}
+			links[t.name] = link
+		}
+	}
+	return links
+}
+
+func reportLinkValues(r Report) map[string]string {
+	repo, _ := reportRepository(r)
+	vars := map[string]string{
+		"cluster":    r.Cluster,
+		"namespace":  r.Namespace,
+		"name":       r.Name,
+		"type":       r.Type,
+		"repository": repo,
+		"tag":        reportTag(r),
+		"image":      reportImageRef(r),
+	}
+	if registry := reportSection(r, "registry"); registry != nil {
+		vars["registry"], _ = registry["server"].(string)
+	}
+	if artifact := reportSection(r, "artifact"); artifact != nil {
+		vars["digest"], _ = artifact["digest"].(string)
+	}
+	if p := reportProvenance(r); p != nil {
+		vars["source"], vars["revision"], vars["commitUrl"] = p.Source, p.Revision, p.CommitURL
+	}
+	return vars
+}
+
+// withReportLinks returns copies of the reports with their report-level links set. Items
+// may be shared with the query cache, so they are never modified in place.
+func withReportLinks(reports []Report) []Report {
+	return reportsWithLinks(getLinkTemplates(), reports)
+}
+
+func reportsWithLinks(templates []linkTemplate, reports []Report) []Report {
+	if len(templates) == 0 {
+		return reports
+	}
+	result := make([]Report, len(reports))
+	for i, r := range reports {
+		r.Links = renderLinks(templates, false, reportLinkValues(r))
+		result[i] = r
+	}
+	return result
+}
+
+// withFindingLinks returns a copy of a report detail with report links set and a
+// "customLinks" object added to every vulnerability and check that a finding template
+// resolves for. Trivy already uses "links" for the advisory references.
+func withFindingLinks(report Report) Report {
+	return detailWithLinks(getLinkTemplates(), report)
+}
+
+func detailWithLinks(templates []linkTemplate, report Report) Report {
+	if len(templates) == 0 {
+		return report
+	}
+	vars := reportLinkValues(report)
+	report.Links = renderLinks(templates, false, vars)
+
+	data, ok := report.Data.(map[string]interface{})
+	if !ok {
+		return report
+	}
+	body, ok := data["report"].(map[string]interface{})
+	if !ok {
+		return report
+	}
+	newBody := make(map[string]interface{}, len(body))
+	for k, v := range body {
+		newBody[k] = v
+	}
+	for _, field := range []string{"vulnerabilities", "checks"} {
+		raw, ok := body[field].([]interface{})
+		if !ok {
+			continue
+		}
+		linked := make([]interface{}, len(raw))
+		for i, v := range raw {
+			linked[i] = withLinksEntry(templates, vars, v)
+		}
+		newBody[field] = linked
+	}
+	newData := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		newData[k] = v
+	}
+	newData["report"] = newBody
+	report.Data = newData
+	return report
+}
+
+func withLinksEntry(templates []linkTemplate, reportVars map[string]string, v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	vars := make(map[string]string, len(reportVars)+len(findingLinkVars))
+	for k, val := range reportVars {
+		vars[k] = val
+	}
+	vars["cve"], _ = m["vulnerabilityID"].(string)
+	vars["package"], _ = m["resource"].(string)
+	vars["installedVersion"], _ = m["installedVersion"].(string)
+	vars["fixedVersion"], _ = m["fixedVersion"].(string)
+	vars["checkID"], _ = m["checkID"].(string)
+
+	links := renderLinks(templates, true, vars)
+	if links == nil {
+		return v
+	}
+	entry := make(map[string]interface{}, len(m)+1)
+	for k, val := range m {
+		entry[k] = val
+	}
+	entry["customLinks"] = links
+	return entry
+}
+
+// reportLinkColumns are the CSV export columns for report-level templates.
+func reportLinkColumns() []string {
+	var names []string
+	for _, t := range getLinkTemplates() {
+		if !t.finding {
+			names = append(names, t.name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,54 @@
+package api
+
+import "testing"
+
+func TestParseLinkTemplates(t *testing.T) {
+	templates := parseLinkTemplates(map[string]string{
+		"vulnDB":   "https://vuln.corp/{{cve}}",
+		"registry": "https://harbor.corp/{{ repository }}",
+		"broken":   "https://example.com/{{unknown}}",
+	})
+	if len(templates) != 2 {
+		t.Fatalf("expected the template with an unknown variable to be dropped, got %+v", templates)
+	}
+	if templates[0].name != "registry" || templates[0].finding || !templates[1].finding {
+		t.Fatalf("unexpected templates %+v", templates)
+	}
+}
+
+func TestRenderLink(t *testing.T) {
+	link, ok := renderLink("https://harbor.corp/{{repository}}?tag={{tag}}", map[string]string{"repository": "library/my app", "tag": "1.0"})
+	if !ok || link != "https://harbor.corp/library/my%20app?tag=1.0" {
+		t.Fatalf("unexpected %q %v", link, ok)
+	}
+	if _, ok := renderLink("https://harbor.corp/{{repository}}:{{tag}}", map[string]string{"repository": "nginx"}); ok {
+		t.Fatal("missing variables must not render")
+	}
+}
+
+func TestDetailWithLinks(t *testing.T) {
+	templates := parseLinkTemplates(map[string]string{
+		"vulnDB":   "https://vuln.corp/{{cve}}",
+		"registry": "https://harbor.corp/{{repository}}",
+	})
+	vuln := map[string]interface{}{"vulnerabilityID": "CVE-2024-1", "resource": "openssl"}
+	report := Report{Cluster: "c1", Namespace: "default", Name: "r1", Data: map[string]interface{}{
+		"report": map[string]interface{}{
+			"artifact":        map[string]interface{}{"repository": "library/nginx", "tag": "1.25"},
+			"vulnerabilities": []interface{}{vuln},
+		},
+	}}
+
+	got := detailWithLinks(templates, report)
+	if got.Links["registry"] != "https://harbor.corp/library/nginx" {
+		t.Fatalf("unexpected report links %v", got.Links)
+	}
+	vulns := got.Data.(map[string]interface{})["report"].(map[string]interface{})["vulnerabilities"].([]interface{})
+	links := vulns[0].(map[string]interface{})["customLinks"].(map[string]string)
+	if links["vulnDB"] != "https://vuln.corp/CVE-2024-1" || links["registry"] != "" {
+		t.Fatalf("unexpected finding links %v", links)
+	}
+	if _, ok := vuln["customLinks"]; ok {
+		t.Fatal("cached vulnerability entries must not be modified")
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"trivy-ui/utils"
+)
+
+// LoggingConfig is the log configuration served and changed at /api/v1/admin/logging.
+type LoggingConfig struct {
+	Level utils.LogLevel `json:"level"`
+	// DebugModules are the modules logging at debug level whatever the level
+	DebugModules []string `json:"debugModules"`
+	// Modules lists the modules that can be given in DebugModules
+	Modules []string `json:"modules"`
+}
+
+// LoggingUpdate changes the log configuration; fields left out are kept.
+type LoggingUpdate struct {
+	Level        *string   `json:"level"`
+	DebugModules *[]string `json:"debugModules"`
+}
+
+func currentLoggingConfig() LoggingConfig {
+	return LoggingConfig{
+		Level:        utils.GetLogLevel(),
+		DebugModules: utils.DebugModules(),
+		Modules:      utils.LogModules,
+	}
+}
+
+// applyLoggingUpdate validates an update and applies it to the running logger.
+func applyLoggingUpdate(u LoggingUpdate) error {
+	level := utils.GetLogLevel()
+	if u.Level != nil {
+		parsed, ok := utils.ParseLogLevel(*u.Level)
+		if !ok {
+			return fmt.Errorf("invalid log level %q, expected debug, info, warning or error", *u.Level)
+		}
+		level = parsed
+	}
+	modules := utils.DebugModules()
+	if u.DebugModules != nil {
+		modules = make([]string, 0, len(*u.DebugModules))
+		for _, m := range *u.DebugModules {
+			m = strings.ToLower(strings.TrimSpace(m))
+			if !slices.Contains(utils.LogModules, m) {
+				return fmt.Errorf("unknown module %q, expected one of %s", m, strings.Join(utils.LogModules, ", "))
+			}
+			modules = append(modules, m)
+		}
+	}
+	utils.SetLogConfig(level, modules)
+	return nil
+}
+
+// Logging serves GET and PUT on /api/v1/admin/logging, to raise the log level or enable
+// the debug logs of one module without a restart. Changes last until the next restart.
+func (h *Handler) Logging(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPut {
+		var u LoggingUpdate
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if err := applyLoggingUpdate(u); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		cfg := currentLoggingConfig()
+		utils.LogInfo("Log configuration changed", map[string]interface{}{"level": cfg.Level, "debugModules": cfg.DebugModules})
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    currentLoggingConfig(),
+	})
+}
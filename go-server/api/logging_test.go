@@ -0,0 +1,43 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"trivy-ui/utils"
+)
+
+func TestApplyLoggingUpdate(t *testing.T) {
+	level, modules := utils.GetLogLevel(), utils.DebugModules()
+	t.Cleanup(func() { utils.SetLogConfig(level, modules) })
+
+	debug := "DEBUG"
+	if err := applyLoggingUpdate(LoggingUpdate{Level: &debug, DebugModules: &[]string{"informer", " Cache "}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := utils.GetLogLevel(); got != utils.LevelDebug {
+		t.Errorf("level = %q", got)
+	}
+	if got := utils.DebugModules(); !reflect.DeepEqual(got, []string{"cache", "informer"}) {
+		t.Errorf("modules = %v", got)
+	}
+
+	// fields left out are kept
+	warn := "warn"
+	if err := applyLoggingUpdate(LoggingUpdate{Level: &warn}); err != nil {
+		t.Fatal(err)
+	}
+	if got := utils.DebugModules(); utils.GetLogLevel() != utils.LevelWarning || len(got) != 2 {
+		t.Errorf("level %q, modules %v", utils.GetLogLevel(), got)
+	}
+
+	bogus := "verbose"
+	for _, u := range []LoggingUpdate{{Level: &bogus}, {DebugModules: &[]string{"scheduler"}}} {
+		if err := applyLoggingUpdate(u); err == nil {
+			t.Errorf("update %+v accepted", u)
+		}
+	}
+	if utils.GetLogLevel() != utils.LevelWarning {
+		t.Error("rejected update applied")
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/export"
+	"trivy-ui/store"
+)
+
+// CacheCompaction is the outcome of CompactCache.
+type CacheCompaction struct {
+	File        string `json:"file"`
+	Entries     int    `json:"entries"`
+	Reports     int    `json:"reports"`
+	BytesBefore int64  `json:"bytesBefore"`
+	BytesAfter  int64  `json:"bytesAfter"`
+}
+
+// CompactCache rewrites the cache file with only what loading it keeps: expired entries
+// and the reports of namespaces excluded since it was saved are dropped. It must not run
+// next to a server saving the same file.
+func CompactCache() (CacheCompaction, error) {
+	c := GetCache()
+	if c == nil {
+		return CacheCompaction{}, fmt.Errorf("cache could not be initialised")
+	}
+	result := CacheCompaction{File: c.cacheFile}
+	// the size before is read after loading, which does not write the file
+	if info, err := os.Stat(c.cacheFile); err == nil {
+		result.BytesBefore = info.Size()
+	}
+	c.mu.RLock()
+	result.Entries, result.Reports = len(c.items), len(c.reportKeys)
+	c.mu.RUnlock()
+
+	if err := c.SaveToFile(); err != nil {
+		return result, err
+	}
+	if info, err := os.Stat(c.cacheFile); err == nil {
+		result.BytesAfter = info.Size()
+	}
+	return result, nil
+}
+
+// ExportCachedReports renders the cached reports matching a query as a csv or json file,
+// without cluster access. The report type is resolved when its CRD was discovered and used
+// as given otherwise.
+func ExportCachedReports(q store.ExportQuery, format string, now time.Time) (export.File, error) {
+	if format != ExportFormatCSV && format != ExportFormatJSON {
+		return export.File{}, fmt.Errorf("format must be csv or json")
+	}
+	if kind := config.GetGlobalRegistry().ResolveReport(q.Type); kind != nil {
+		q.Type = kind.Name
+	}
+	reports, err := exportReports(NewQueryService(NewCacheServiceImpl()), q)
+	if err != nil {
+		return export.File{}, err
+	}
+	return exportFile(reports, q.Type, format, now)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/store"
+)
+
+func TestCompactCacheAndExport(t *testing.T) {
+	c := useTestCache(t)
+	for _, cluster := range []string{"prod", "dev"} {
+		c.Set(reportKey(cluster, "shop", "clitestreports", "app"), makeReport("app", cluster, "shop", "clitestreports", 1), 0)
+	}
+	c.Set("note", "expired", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	result, err := CompactCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Reports != 2 || result.BytesAfter == 0 {
+		t.Errorf("unexpected compaction %+v", result)
+	}
+	if err := c.LoadFromFile(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("note"); ok {
+		t.Error("expired entry kept")
+	}
+
+	file, err := ExportCachedReports(store.ExportQuery{Type: "clitestreports", Cluster: "prod"}, ExportFormatCSV, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(file.Data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "prod,shop,clitestreports,app,") {
+		t.Errorf("unexpected export %q", file.Data)
+	}
+	if _, err := ExportCachedReports(store.ExportQuery{Type: "clitestreports"}, "pdf", time.Now()); err == nil {
+		t.Error("unsupported format accepted")
+	}
+}
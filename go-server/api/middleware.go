@@ -37,6 +37,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush
+// server-sent events.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func getClientIP(r *http.Request) string {
 	// Check proxy headers first (in order of trust)
 	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
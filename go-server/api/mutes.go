@@ -0,0 +1,305 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"trivy-ui/schedule"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// muteWindowRetention is how long ended ad-hoc windows stay listed before they are purged.
+const muteWindowRetention = 7 * 24 * time.Hour
+
+// MuteWindowView adds whether a stored window is open now and when it closes or next opens.
+type MuteWindowView struct {
+	store.MuteWindow
+	Active      bool       `json:"active"`
+	ActiveUntil *time.Time `json:"activeUntil,omitempty"`
+	NextStartAt *time.Time `json:"nextStartAt,omitempty"`
+}
+
+// NotificationScheduleStatus is the delivery state of one scheduled export.
+type NotificationScheduleStatus struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Destination string     `json:"destination"`
+	Cluster     string     `json:"cluster,omitempty"`
+	Enabled     bool       `json:"enabled"`
+	NextRunAt   *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt   *time.Time `json:"lastRunAt,omitempty"`
+	LastStatus  string     `json:"lastStatus,omitempty"`
+	// MutedBy is the open window that will skip the schedule's runs
+	MutedBy    int64      `json:"mutedBy,omitempty"`
+	MutedUntil *time.Time `json:"mutedUntil,omitempty"`
+}
+
+type NotificationStatus struct {
+	// Muted is set while a window covering every cluster is open
+	Muted      bool       `json:"muted"`
+	MutedUntil *time.Time `json:"mutedUntil,omitempty"`
+	// MutedClusters maps clusters with an open window of their own to when it closes
+	MutedClusters map[string]time.Time         `json:"mutedClusters"`
+	Windows       []MuteWindowView             `json:"windows"`
+	Schedules     []NotificationScheduleStatus `json:"schedules"`
+}
+
+// muteWindowOpen reports whether a window is open at now and, if so, when it closes. A
+// recurring window is open when a cron match lies less than its duration before now.
+func muteWindowOpen(m store.MuteWindow, now time.Time) (bool, time.Time) {
+	if m.Cron != "" {
+		cron, err := schedule.Parse(m.Cron)
+		if err != nil || m.DurationMinutes <= 0 {
+			return false, time.Time{}
+		}
+		duration := time.Duration(m.DurationMinutes) * time.Minute
+		start := cron.Next(now.Add(-duration).In(time.Local))
+		if start.IsZero() || start.After(now) {
+			return false, time.Time{}
+		}
+		return true, start.Add(duration)
+	}
+	if m.EndsAt == nil || !now.Before(*m.EndsAt) || (m.StartsAt != nil && now.Before(*m.StartsAt)) {
+		return false, time.Time{}
+	}
+	return true, *m.EndsAt
+}
+
+// nextMuteStart is when a closed window opens next, or the zero time if it never does.
+func nextMuteStart(m store.MuteWindow, now time.Time) time.Time {
+	if m.Cron != "" {
+		cron, err := schedule.Parse(m.Cron)
+		if err != nil {
+			return time.Time{}
+		}
+		return cron.Next(now.In(time.Local))
+	}
+	if m.StartsAt != nil && now.Before(*m.StartsAt) {
+		return *m.StartsAt
+	}
+	return time.Time{}
+}
+
+// activeMute returns the open window that covers cluster, the one closing last if several
+// do. Windows without a cluster cover everything; a cluster's window only mutes
+// notifications scoped to that cluster, not fleet-wide ones.
+func activeMute(windows []store.MuteWindow, cluster string, now time.Time) (store.MuteWindow, time.Time, bool) {
+	var found store.MuteWindow
+	var until time.Time
+	for _, m := range windows {
+		if m.Cluster != "" && m.Cluster != cluster {
+			continue
+		}
+		if open, end := muteWindowOpen(m, now); open && end.After(until) {
+			found, until = m, end
+		}
+	}
+	return found, until, !until.IsZero()
+}
+
+func muteView(m store.MuteWindow, now time.Time) MuteWindowView {
+	view := MuteWindowView{MuteWindow: m}
+	if open, until := muteWindowOpen(m, now); open {
+		view.Active, view.ActiveUntil = true, &until
+	} else if next := nextMuteStart(m, now); !next.IsZero() {
+		view.NextStartAt = &next
+	}
+	return view
+}
+
+func validateMuteWindow(m store.MuteWindow, now time.Time) error {
+	if m.Cron != "" {
+		if _, err := schedule.Parse(m.Cron); err != nil {
+			return errors.New("invalid cron: " + err.Error())
+		}
+		if m.DurationMinutes <= 0 {
+			return errors.New("durationMinutes is required for a recurring window")
+		}
+		if m.StartsAt != nil || m.EndsAt != nil {
+			return errors.New("a recurring window has no startsAt or endsAt")
+		}
+		return nil
+	}
+	if m.EndsAt == nil {
+		return errors.New("endsAt or cron is required")
+	}
+	if m.DurationMinutes != 0 {
+		return errors.New("durationMinutes only applies to recurring windows")
+	}
+	if !m.EndsAt.After(now) {
+		return errors.New("endsAt must be in the future")
+	}
+	if m.StartsAt != nil && !m.EndsAt.After(*m.StartsAt) {
+		return errors.New("endsAt must be after startsAt")
+	}
+	return nil
+}
+
+func (h *Handler) ListMuteWindows(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	windows, err := st.ListMuteWindows(ctx)
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		utils.LogWarning("Failed to list mute windows", map[string]interface{}{"error": err.Error()})
+		writeError(w, http.StatusInternalServerError, "Failed to list mute windows")
+		return
+	}
+	now := time.Now()
+	views := make([]MuteWindowView, len(windows))
+	for i, m := range windows {
+		views[i] = muteView(m, now)
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    views,
+	})
+}
+
+func (h *Handler) CreateMuteWindow(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	var m store.MuteWindow
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	now := time.Now()
+	if err := validateMuteWindow(m, now); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	created, err := st.CreateMuteWindow(ctx, m)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.LogInfo("Notifications muted", map[string]interface{}{"id": created.ID, "cluster": created.Cluster, "reason": created.Reason})
+	writeJSON(w, http.StatusCreated, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    muteView(created, now),
+	})
+}
+
+// MuteWindowByID serves GET and DELETE on /api/v1/notifications/mutes/{id}.
+func (h *Handler) MuteWindowByID(w http.ResponseWriter, r *http.Request, idStr string) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid mute window id")
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	if r.Method == http.MethodDelete {
+		err = st.DeleteMuteWindow(ctx, id)
+		if err == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	var m store.MuteWindow
+	if err == nil {
+		m, err = st.GetMuteWindow(ctx, id)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "Mute window not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    muteView(m, time.Now()),
+	})
+}
+
+// GetNotificationStatus reports the mute windows and, for every scheduled export, when it
+// runs next and whether an open window will skip that run.
+func (h *Handler) GetNotificationStatus(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	windows, err := st.ListMuteWindows(ctx)
+	var schedules []store.ExportSchedule
+	if err == nil {
+		schedules, err = st.ListExportSchedules(ctx)
+	}
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		utils.LogWarning("Failed to load notification status", map[string]interface{}{"error": err.Error()})
+		writeError(w, http.StatusInternalServerError, "Failed to load notification status")
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    notificationStatus(windows, schedules, time.Now()),
+	})
+}
+
+func notificationStatus(windows []store.MuteWindow, schedules []store.ExportSchedule, now time.Time) NotificationStatus {
+	status := NotificationStatus{
+		MutedClusters: make(map[string]time.Time),
+		Windows:       make([]MuteWindowView, 0, len(windows)),
+		Schedules:     make([]NotificationScheduleStatus, 0, len(schedules)),
+	}
+	for _, m := range windows {
+		view := muteView(m, now)
+		status.Windows = append(status.Windows, view)
+		if !view.Active {
+			continue
+		}
+		if m.Cluster == "" {
+			if status.MutedUntil == nil || view.ActiveUntil.After(*status.MutedUntil) {
+				status.Muted, status.MutedUntil = true, view.ActiveUntil
+			}
+		} else if view.ActiveUntil.After(status.MutedClusters[m.Cluster]) {
+			status.MutedClusters[m.Cluster] = *view.ActiveUntil
+		}
+	}
+	for _, s := range schedules {
+		sched := NotificationScheduleStatus{
+			ID:          s.ID,
+			Name:        s.Name,
+			Destination: s.Destination,
+			Cluster:     s.Query.Cluster,
+			Enabled:     s.Enabled,
+			NextRunAt:   exportView(s).NextRunAt,
+			LastRunAt:   s.LastRunAt,
+			LastStatus:  s.LastStatus,
+		}
+		if m, until, muted := activeMute(windows, s.Query.Cluster, now); muted && s.Enabled {
+			sched.MutedBy, sched.MutedUntil = m.ID, &until
+		}
+		status.Schedules = append(status.Schedules, sched)
+	}
+	return status
+}
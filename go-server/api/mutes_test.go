@@ -0,0 +1,95 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"trivy-ui/store"
+)
+
+func TestMuteWindowOpen(t *testing.T) {
+	now := time.Date(2026, 3, 7, 3, 0, 0, 0, time.Local) // a Saturday
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	for _, tc := range []struct {
+		name  string
+		m     store.MuteWindow
+		open  bool
+		until time.Time
+	}{
+		{"until date", store.MuteWindow{EndsAt: &later}, true, later},
+		{"ended", store.MuteWindow{EndsAt: &earlier}, false, time.Time{}},
+		{"not started", store.MuteWindow{StartsAt: &later, EndsAt: ptrTime(later.Add(time.Hour))}, false, time.Time{}},
+		{"recurring open", store.MuteWindow{Cron: "0 2 * * sat", DurationMinutes: 120}, true, now.Add(time.Hour)},
+		{"recurring closed", store.MuteWindow{Cron: "0 2 * * sat", DurationMinutes: 30}, false, time.Time{}},
+		{"recurring other day", store.MuteWindow{Cron: "0 2 * * sun", DurationMinutes: 120}, false, time.Time{}},
+	} {
+		open, until := muteWindowOpen(tc.m, now)
+		if open != tc.open || !until.Equal(tc.until) {
+			t.Errorf("%s: open=%v until=%v, want %v %v", tc.name, open, until, tc.open, tc.until)
+		}
+	}
+}
+
+func ptrTime(t time.Time) *time.Time { return &t }
+
+func TestActiveMuteScope(t *testing.T) {
+	now := time.Now()
+	windows := []store.MuteWindow{
+		{ID: 1, Cluster: "prod", EndsAt: ptrTime(now.Add(2 * time.Hour))},
+		{ID: 2, EndsAt: ptrTime(now.Add(time.Hour))},
+	}
+	if m, until, ok := activeMute(windows, "prod", now); !ok || m.ID != 1 || !until.Equal(*windows[0].EndsAt) {
+		t.Fatalf("prod: %+v %v %v", m, until, ok)
+	}
+	if m, _, ok := activeMute(windows, "", now); !ok || m.ID != 2 {
+		t.Fatalf("fleet-wide exports must only be muted by global windows: %+v %v", m, ok)
+	}
+	if _, _, ok := activeMute(windows[:1], "staging", now); ok {
+		t.Fatal("a cluster window must not mute other clusters")
+	}
+}
+
+func TestValidateMuteWindow(t *testing.T) {
+	now := time.Now()
+	valid := []store.MuteWindow{
+		{Cluster: "prod", EndsAt: ptrTime(now.Add(time.Hour))},
+		{Cron: "@weekly", DurationMinutes: 60},
+	}
+	for _, m := range valid {
+		if err := validateMuteWindow(m, now); err != nil {
+			t.Errorf("%+v: %v", m, err)
+		}
+	}
+	invalid := []store.MuteWindow{
+		{},
+		{EndsAt: ptrTime(now.Add(-time.Hour))},
+		{StartsAt: ptrTime(now.Add(2 * time.Hour)), EndsAt: ptrTime(now.Add(time.Hour))},
+		{Cron: "@weekly"},
+		{Cron: "not cron", DurationMinutes: 60},
+		{Cron: "@weekly", DurationMinutes: 60, EndsAt: ptrTime(now.Add(time.Hour))},
+	}
+	for _, m := range invalid {
+		if validateMuteWindow(m, now) == nil {
+			t.Errorf("%+v: expected an error", m)
+		}
+	}
+}
+
+func TestNotificationStatus(t *testing.T) {
+	now := time.Now()
+	until := now.Add(time.Hour)
+	windows := []store.MuteWindow{{ID: 7, Cluster: "prod", Reason: "upgrade", EndsAt: &until}}
+	schedules := []store.ExportSchedule{
+		{ID: 1, Name: "prod", Cron: "@daily", Enabled: true, Query: store.ExportQuery{Cluster: "prod"}, CreatedAt: now},
+		{ID: 2, Name: "fleet", Cron: "@daily", Enabled: true, CreatedAt: now},
+	}
+	status := notificationStatus(windows, schedules, now)
+	if status.Muted || !status.MutedClusters["prod"].Equal(until) || len(status.Windows) != 1 || !status.Windows[0].Active {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status.Schedules[0].MutedBy != 7 || status.Schedules[1].MutedBy != 0 || status.Schedules[0].NextRunAt == nil {
+		t.Fatalf("unexpected schedules %+v", status.Schedules)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"trivy-ui/config"
+)
+
+const (
+	defaultSuggestLimit = 20
+	maxSuggestLimit     = 100
+)
+
+// How a namespace name matches the query, best first.
+const (
+	suggestMatchExact = iota
+	suggestMatchPrefix
+	suggestMatchSegment
+	suggestMatchSubstring
+)
+
+type NamespaceSuggestion struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	// Reports counts the namespace's reports of all types
+	Reports int `json:"reports"`
+	match   int
+}
+
+// SuggestNamespaces handles GET /api/v1/namespaces/suggest.
+func (h *Handler) SuggestNamespaces(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := defaultSuggestLimit
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxSuggestLimit)
+	}
+	var clusters []string
+	for _, c := range strings.Split(query.Get("clusters"), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			clusters = append(clusters, c)
+		}
+	}
+	if len(clusters) == 0 {
+		for name := range h.clusterReg.All() {
+			clusters = append(clusters, name)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    h.suggestNamespaces(strings.TrimSpace(query.Get("q")), clusters, limit),
+	})
+}
+
+// suggestNamespaces ranks the namespaces of clusters matching q by match quality, then
+// by report count, so busy namespaces come first. An empty q lists the busiest ones.
+func (h *Handler) suggestNamespaces(q string, clusters []string, limit int) []NamespaceSuggestion {
+	q = strings.ToLower(q)
+	cfg := config.Get()
+	// reports of all types per cluster and namespace, from the cache's rollups
+	reports := make(map[string]map[string]int)
+	for _, rollup := range h.cache.GetReportRollups(strings.Join(clusters, ",")) {
+		if rollup.Namespace == "" {
+			continue
+		}
+		if reports[rollup.Cluster] == nil {
+			reports[rollup.Cluster] = make(map[string]int)
+		}
+		reports[rollup.Cluster][rollup.Namespace] += rollup.Reports
+	}
+	result := []NamespaceSuggestion{}
+	for _, cluster := range clusters {
+		counts := reports[cluster]
+		names := make(map[string]bool, len(counts))
+		for ns := range counts {
+			names[ns] = true
+		}
+		if cc := h.clusterReg.Get(cluster); cc != nil {
+			cc.mu.RLock()
+			for _, ns := range cc.Namespaces {
+				names[ns] = true
+			}
+			cc.mu.RUnlock()
+		}
+		for ns := range names {
+			if cfg.NamespaceExcluded(ns) {
+				continue
+			}
+			match, ok := suggestMatch(strings.ToLower(ns), q)
+			if !ok {
+				continue
+			}
+			result = append(result, NamespaceSuggestion{Cluster: cluster, Namespace: ns, Reports: counts[ns], match: match})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.match != b.match {
+			return a.match < b.match
+		}
+		if a.Reports != b.Reports {
+			return a.Reports > b.Reports
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Cluster < b.Cluster
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// suggestMatch rates how name matches q; a segment match starts after a '-' or '.'.
+func suggestMatch(name, q string) (int, bool) {
+	switch {
+	case q == "":
+		return suggestMatchSubstring, true
+	case name == q:
+		return suggestMatchExact, true
+	case strings.HasPrefix(name, q):
+		return suggestMatchPrefix, true
+	case strings.Contains(name, "-"+q), strings.Contains(name, "."+q):
+		return suggestMatchSegment, true
+	case strings.Contains(name, q):
+		return suggestMatchSubstring, true
+	}
+	return 0, false
+}
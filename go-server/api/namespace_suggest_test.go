@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSuggestNamespaces(t *testing.T) {
+	c := useTestCache(t)
+	cache := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(cache)
+	reg.RegisterPushed("suggest-a", "", []string{"payments", "payroll", "team-pay", "default", "repay"})
+	reg.RegisterPushed("suggest-b", "", []string{"payments"})
+	h := &Handler{cache: cache, clusterReg: reg}
+
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprint("r", i)
+		c.Set(reportKey("suggest-a", "payroll", "vulnerabilityreports", name), makeReport(name, "suggest-a", "payroll", "vulnerabilityreports", 0), 0)
+	}
+	c.Set(reportKey("suggest-b", "payments", "vulnerabilityreports", "r"), makeReport("r", "suggest-b", "payments", "vulnerabilityreports", 1), 0)
+	c.Set(reportKey("suggest-b", "payments", "configauditreports", "r"), makeReport("r", "suggest-b", "payments", "configauditreports", 0), 0)
+	// cluster-scoped reports belong to no namespace
+	c.Set(reportKey("suggest-a", "", "clustervulnerabilityreports", "node"), makeReport("node", "suggest-a", "", "clustervulnerabilityreports", 1), 0)
+
+	got := h.suggestNamespaces("Pay", []string{"suggest-a", "suggest-b"}, 10)
+	want := []NamespaceSuggestion{
+		{Cluster: "suggest-a", Namespace: "payroll", Reports: 3},
+		{Cluster: "suggest-b", Namespace: "payments", Reports: 2},
+		{Cluster: "suggest-a", Namespace: "payments", Reports: 0},
+		{Cluster: "suggest-a", Namespace: "team-pay", Reports: 0},
+		{Cluster: "suggest-a", Namespace: "repay", Reports: 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got[i].Cluster != want[i].Cluster || got[i].Namespace != want[i].Namespace || got[i].Reports != want[i].Reports {
+			t.Errorf("suggestion %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := h.suggestNamespaces("pay", []string{"suggest-b"}, 10); len(got) != 1 || got[0].Cluster != "suggest-b" {
+		t.Errorf("cluster filter ignored: %+v", got)
+	}
+	if got := h.suggestNamespaces("", []string{"suggest-a"}, 2); len(got) != 2 || got[0].Namespace != "payroll" {
+		t.Errorf("empty query = %+v", got)
+	}
+}
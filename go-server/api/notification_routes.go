@@ -0,0 +1,344 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+
+	"trivy-ui/config"
+	"trivy-ui/export"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// defaultRouteName names the route of reports no other route matches
+const defaultRouteName = "default"
+
+var (
+	notificationRoutesOnce sync.Once
+	notificationRoutesMu   sync.RWMutex
+	notificationRoutes     NotificationRoutes
+	notificationRoutesErr  error
+)
+
+// NotificationRoute sends the reports it matches to a destination. It matches a report of
+// its cluster, any cluster when unset, whose namespace labels match NamespaceSelector and
+// whose workload's annotations match OwnerSelector; empty selectors match everything.
+type NotificationRoute struct {
+	Name              string `json:"name"`
+	Cluster           string `json:"cluster,omitempty"`
+	NamespaceSelector string `json:"namespaceSelector,omitempty"`
+	OwnerSelector     string `json:"ownerSelector,omitempty"`
+	Destination       string `json:"destination"`
+	Target            string `json:"target"`
+
+	namespaceSelector labels.Selector
+	ownerSelector     labels.Selector
+}
+
+// NotificationRoutes are tried in order, the first match wins; reports no route matches go
+// to Default, or nowhere when it is unset.
+type NotificationRoutes struct {
+	Routes  []NotificationRoute `json:"routes"`
+	Default *NotificationRoute  `json:"default,omitempty"`
+}
+
+// RouteSubject is what a route is matched against: where a report lives and who owns it.
+type RouteSubject struct {
+	Cluster         string            `json:"cluster"`
+	Namespace       string            `json:"namespace"`
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
+	// OwnerKind and OwnerName are the workload the report is about, from its labels
+	OwnerKind        string            `json:"ownerKind,omitempty"`
+	OwnerName        string            `json:"ownerName,omitempty"`
+	OwnerAnnotations map[string]string `json:"ownerAnnotations,omitempty"`
+}
+
+// RouteDecision is the route a report or namespace would take, for
+// /api/v1/notifications/routes/test.
+type RouteDecision struct {
+	Subject RouteSubject `json:"subject"`
+	// Route is empty when no route matches and there is no default route
+	Route       string `json:"route,omitempty"`
+	Default     bool   `json:"default,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Target      string `json:"target,omitempty"`
+}
+
+// parseNotificationRoutes reads a YAML or JSON file of the form
+// {"routes": [...], "default": {"destination": ..., "target": ...}}.
+func parseNotificationRoutes(data []byte) (NotificationRoutes, error) {
+	var routes NotificationRoutes
+	if err := yaml.Unmarshal(data, &routes); err != nil {
+		return routes, fmt.Errorf("failed to parse notification routes: %w", err)
+	}
+	names := make(map[string]bool)
+	for i := range routes.Routes {
+		route := &routes.Routes[i]
+		if route.Name == "" || route.Name == defaultRouteName || names[route.Name] {
+			return routes, fmt.Errorf("route %d: name must be set, unique and not %q", i+1, defaultRouteName)
+		}
+		names[route.Name] = true
+		if err := route.compile(); err != nil {
+			return routes, fmt.Errorf("route %s: %w", route.Name, err)
+		}
+	}
+	if routes.Default != nil {
+		routes.Default.Name = defaultRouteName
+		routes.Default.Cluster, routes.Default.NamespaceSelector, routes.Default.OwnerSelector = "", "", ""
+		if err := routes.Default.compile(); err != nil {
+			return routes, fmt.Errorf("default route: %w", err)
+		}
+	}
+	return routes, nil
+}
+
+func (r *NotificationRoute) compile() error {
+	var err error
+	if r.namespaceSelector, err = labels.Parse(r.NamespaceSelector); err != nil {
+		return fmt.Errorf("invalid namespaceSelector: %w", err)
+	}
+	if r.ownerSelector, err = labels.Parse(r.OwnerSelector); err != nil {
+		return fmt.Errorf("invalid ownerSelector: %w", err)
+	}
+	if r.Destination == export.DestinationRouted || r.Destination == export.DestinationDefectDojo {
+		return fmt.Errorf("destination %q cannot be routed to", r.Destination)
+	}
+	return export.ValidateTarget(r.Destination, r.Target)
+}
+
+func (r *NotificationRoute) matches(s RouteSubject) bool {
+	return (r.Cluster == "" || r.Cluster == s.Cluster) &&
+		r.namespaceSelector.Matches(labels.Set(s.NamespaceLabels)) &&
+		r.ownerSelector.Matches(labels.Set(s.OwnerAnnotations))
+}
+
+// match returns the route a subject takes and whether it is the default route, or nil.
+func (rs NotificationRoutes) match(s RouteSubject) (*NotificationRoute, bool) {
+	for i := range rs.Routes {
+		if rs.Routes[i].matches(s) {
+			return &rs.Routes[i], false
+		}
+	}
+	return rs.Default, rs.Default != nil
+}
+
+func (rs NotificationRoutes) empty() bool {
+	return len(rs.Routes) == 0 && rs.Default == nil
+}
+
+// usesOwnerAnnotations reports whether any route selects on workload annotations, which
+// takes listing every workload of a cluster.
+func (rs NotificationRoutes) usesOwnerAnnotations() bool {
+	for _, r := range rs.Routes {
+		if r.OwnerSelector != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getNotificationRoutes loads NOTIFICATION_ROUTES_FILE once, and again on a config
+// reload; an invalid file disables routing.
+func getNotificationRoutes() NotificationRoutes {
+	routes, _ := loadedNotificationRoutes()
+	return routes
+}
+
+// loadedNotificationRoutes also returns why the file could not be loaded at startup.
+func loadedNotificationRoutes() (NotificationRoutes, error) {
+	notificationRoutesOnce.Do(func() {
+		path := config.Get().NotificationRoutesFile
+		if path == "" {
+			return
+		}
+		routes, err := loadNotificationRoutes(path)
+		if err != nil {
+			utils.LogWarning("Failed to load notification routes, routed exports disabled", map[string]interface{}{"path": path, "error": err.Error()})
+		}
+		setNotificationRoutes(routes, err)
+	})
+	notificationRoutesMu.RLock()
+	defer notificationRoutesMu.RUnlock()
+	return notificationRoutes, notificationRoutesErr
+}
+
+func loadNotificationRoutes(path string) (NotificationRoutes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NotificationRoutes{}, err
+	}
+	return parseNotificationRoutes(data)
+}
+
+func setNotificationRoutes(routes NotificationRoutes, err error) {
+	notificationRoutesOnce.Do(func() {})
+	if err != nil {
+		routes = NotificationRoutes{}
+	}
+	notificationRoutesMu.Lock()
+	notificationRoutes, notificationRoutesErr = routes, err
+	notificationRoutesMu.Unlock()
+}
+
+// workloadAnnotationsFor returns a workload's annotations from its cluster's workload
+// cache. Pushed clusters have no client and no annotations.
+func workloadAnnotationsFor(cluster, kind, namespace, name string) map[string]string {
+	w, _ := workloadFor(cluster, kind, namespace, name)
+	return w.Annotations
+}
+
+// routeSubject describes a report for route matching. Workload annotations are only read
+// when a route selects on them.
+func routeSubject(rs NotificationRoutes, r Report) RouteSubject {
+	s := RouteSubject{Cluster: r.Cluster, Namespace: r.Namespace, NamespaceLabels: namespaceLabelsFor(r.Cluster, r.Namespace)}
+	s.OwnerKind, s.OwnerName = reportResource(r)
+	if rs.usesOwnerAnnotations() {
+		s.OwnerAnnotations = workloadAnnotationsFor(r.Cluster, s.OwnerKind, r.Namespace, s.OwnerName)
+	}
+	return s
+}
+
+// redactTarget hides the path of URL targets, which for Slack and many webhooks is the
+// credential.
+func redactTarget(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return target
+	}
+	if u.Path == "" || u.Path == "/" {
+		return u.Scheme + "://" + u.Host
+	}
+	return u.Scheme + "://" + u.Host + "/…"
+}
+
+func redactedRoute(r NotificationRoute) NotificationRoute {
+	r.Target = redactTarget(r.Target)
+	return r
+}
+
+// deliverRouted splits the reports of a routed export by route and delivers each part to
+// its route's destination, named after the export and the route. Reports no route takes
+// are left out.
+func deliverRouted(ctx context.Context, querySvc QueryService, s store.ExportSchedule, now time.Time) error {
+	routes := getNotificationRoutes()
+	if routes.empty() {
+		return errors.New("no notification routes, set NOTIFICATION_ROUTES_FILE")
+	}
+	reports, err := exportReports(querySvc, s.Query)
+	if err != nil {
+		return err
+	}
+	groups := make(map[string][]Report)
+	byName := make(map[string]*NotificationRoute)
+	unrouted := 0
+	for _, r := range reports {
+		route, _ := routes.match(routeSubject(routes, r))
+		if route == nil {
+			unrouted++
+			continue
+		}
+		groups[route.Name] = append(groups[route.Name], r)
+		byName[route.Name] = route
+	}
+	if unrouted > 0 {
+		utils.LogInfo("Reports matched no notification route", map[string]interface{}{"id": s.ID, "name": s.Name, "reports": unrouted})
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	settings := exportSettings(config.Get())
+	var errs []error
+	for _, name := range names {
+		route := byName[name]
+		err := func() error {
+			deliverer, err := export.New(route.Destination, settings)
+			if err != nil {
+				return err
+			}
+			file, err := exportFile(groups[name], s.Name+"-"+name, s.Format, now)
+			if err != nil {
+				return err
+			}
+			return deliverer.Deliver(ctx, route.Target, file)
+		}()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("route %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ListNotificationRoutes handles GET /api/v1/notifications/routes: the routes of
+// NOTIFICATION_ROUTES_FILE with the paths of their URL targets hidden.
+func (h *Handler) ListNotificationRoutes(w http.ResponseWriter, r *http.Request) {
+	routes, loadErr := loadedNotificationRoutes()
+	view := NotificationRoutes{Routes: make([]NotificationRoute, 0, len(routes.Routes))}
+	for _, route := range routes.Routes {
+		view.Routes = append(view.Routes, redactedRoute(route))
+	}
+	if routes.Default != nil {
+		d := redactedRoute(*routes.Default)
+		view.Default = &d
+	}
+	data := map[string]interface{}{"file": config.Get().NotificationRoutesFile, "routes": view.Routes, "default": view.Default}
+	if loadErr != nil {
+		data["error"] = loadErr.Error()
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    data,
+	})
+}
+
+// TestNotificationRoute handles GET /api/v1/notifications/routes/test: the route a report,
+// given by ?cluster=, ?namespace=, ?type= and ?name=, would take and what it was matched
+// on. Without type and name the namespace alone is matched, with no workload annotations.
+func (h *Handler) TestNotificationRoute(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	cluster, namespace := q.Get("cluster"), q.Get("namespace")
+	if cluster == "" {
+		writeError(w, http.StatusBadRequest, "Missing cluster parameter")
+		return
+	}
+	report := Report{Cluster: cluster, Namespace: namespace}
+	if typeName, name := q.Get("type"), q.Get("name"); typeName != "" || name != "" {
+		kind := h.crdReg.ResolveReport(typeName)
+		if kind == nil || name == "" {
+			writeError(w, http.StatusBadRequest, "type and name must name a report")
+			return
+		}
+		value, found := h.cache.Get(reportKey(cluster, namespace, kind.Name, name))
+		cached, ok := convertCacheValue[Report](value)
+		if !found || !ok {
+			writeError(w, http.StatusNotFound, "Report not found")
+			return
+		}
+		report = cached
+	}
+
+	routes := getNotificationRoutes()
+	decision := RouteDecision{Subject: routeSubject(routes, report)}
+	if route, isDefault := routes.match(decision.Subject); route != nil {
+		decision.Route, decision.Default = route.Name, isDefault
+		decision.Destination, decision.Target = route.Destination, redactTarget(route.Target)
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    decision,
+	})
+}
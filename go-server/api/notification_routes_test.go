@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/store"
+)
+
+// useNotificationRoutes replaces the routes of NOTIFICATION_ROUTES_FILE for a test.
+func useNotificationRoutes(t *testing.T, routes NotificationRoutes) {
+	t.Helper()
+	old, oldErr := loadedNotificationRoutes()
+	setNotificationRoutes(routes, nil)
+	t.Cleanup(func() { setNotificationRoutes(old, oldErr) })
+}
+
+func TestParseNotificationRoutes(t *testing.T) {
+	routes, err := parseNotificationRoutes([]byte(`
+routes:
+  - name: payments
+    namespaceSelector: team=payments
+    destination: slack
+    target: https://hooks.slack.com/services/T0/B0/secret
+  - name: platform-owned
+    cluster: prod
+    ownerSelector: owner in (platform,sre)
+    destination: webhook
+    target: https://alerts.example.com/trivy
+default:
+  destination: slack
+  target: https://hooks.slack.com/services/T0/B1/secret
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		subject RouteSubject
+		route   string
+	}{
+		{RouteSubject{Cluster: "prod", NamespaceLabels: map[string]string{"team": "payments"}}, "payments"},
+		{RouteSubject{Cluster: "prod", OwnerAnnotations: map[string]string{"owner": "sre"}}, "platform-owned"},
+		{RouteSubject{Cluster: "dev", OwnerAnnotations: map[string]string{"owner": "sre"}}, defaultRouteName},
+		{RouteSubject{Cluster: "dev"}, defaultRouteName},
+	}
+	for _, c := range cases {
+		route, isDefault := routes.match(c.subject)
+		if route == nil || route.Name != c.route || isDefault != (c.route == defaultRouteName) {
+			t.Errorf("%+v: got %v, want %s", c.subject, route, c.route)
+		}
+	}
+	if !routes.usesOwnerAnnotations() {
+		t.Error("ownerSelector not detected")
+	}
+
+	for _, bad := range []string{
+		`routes: [{destination: slack, target: "https://hooks.slack.com/x"}]`,
+		`routes: [{name: a, destination: slack, target: "https://x"}, {name: a, destination: slack, target: "https://y"}]`,
+		`routes: [{name: a, namespaceSelector: "team in (", destination: slack, target: "https://x"}]`,
+		`routes: [{name: a, destination: slack, target: "not a url"}]`,
+		`routes: [{name: a, destination: routed}]`,
+		`default: {destination: defectdojo, target: "1"}`,
+	} {
+		if _, err := parseNotificationRoutes([]byte(bad)); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestRedactTarget(t *testing.T) {
+	if got := redactTarget("https://hooks.slack.com/services/T0/B0/secret"); got != "https://hooks.slack.com/…" {
+		t.Errorf("got %q", got)
+	}
+	if got := redactTarget("ops@example.com"); got != "ops@example.com" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRunRoutedExport(t *testing.T) {
+	st, err := store.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	var mu sync.Mutex
+	bodies := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[r.URL.Path] = string(b)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	routes, err := parseNotificationRoutes([]byte(`{
+		"routes": [{"name": "staging", "cluster": "staging", "destination": "webhook", "target": "` + srv.URL + `/staging"}],
+		"default": {"destination": "slack", "target": "` + srv.URL + `/slack"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	useNotificationRoutes(t, routes)
+
+	cache := &stubCacheService{reports: map[string][]Report{
+		"routetestreports": {
+			makeReport("app-a", "prod", "payments", "routetestreports", 2),
+			makeReport("app-b", "staging", "payments", "routetestreports", 1),
+		},
+	}}
+	sched, err := st.CreateExportSchedule(t.Context(), store.ExportSchedule{
+		Name: "Weekly", Cron: "@weekly", Format: ExportFormatCSV, Enabled: true,
+		Query:       store.ExportQuery{Type: "routetestreports"},
+		Destination: "routed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runExport(context.Background(), st, NewQueryService(cache), sched); err != nil {
+		t.Fatalf("runExport: %v", err)
+	}
+
+	if got := bodies["/staging"]; !strings.Contains(got, "app-b") || strings.Contains(got, "app-a") {
+		t.Errorf("staging route got %q", got)
+	}
+	var slack struct{ Text string }
+	json.Unmarshal([]byte(bodies["/slack"]), &slack)
+	if !strings.Contains(slack.Text, "*Weekly-default*") || !strings.Contains(slack.Text, "prod/payments/app-a: 2 critical") {
+		t.Errorf("default route got %q", slack.Text)
+	}
+}
+
+func TestTestNotificationRoute(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := config.GetGlobalRegistry()
+	reg.Register(config.ReportKind{Name: "vulnerabilityreports", Namespaced: true})
+	h := NewHandler(nil, svc, NewClusterRegistry(svc), NewQueryService(svc), reg)
+
+	routes, err := parseNotificationRoutes([]byte(`{
+		"routes": [{"name": "edge", "cluster": "edge", "destination": "webhook", "target": "https://alerts.example.com/edge"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	useNotificationRoutes(t, routes)
+
+	report := makeReport("app", "edge", "web", "vulnerabilityreports", 1)
+	c.Set(reportKey("edge", "web", "vulnerabilityreports", "app"), report, time.Hour)
+
+	get := func(query string) (int, RouteDecision) {
+		rec := httptest.NewRecorder()
+		h.TestNotificationRoute(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications/routes/test?"+query, nil))
+		var resp struct{ Data RouteDecision }
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp.Data
+	}
+	code, decision := get("cluster=edge&namespace=web&type=vulnerabilityreports&name=app")
+	if code != http.StatusOK || decision.Route != "edge" || decision.Target != "https://alerts.example.com/…" {
+		t.Errorf("got %d %+v", code, decision)
+	}
+	if code, decision := get("cluster=prod&namespace=web"); code != http.StatusOK || decision.Route != "" {
+		t.Errorf("expected no route without a default, got %d %+v", code, decision)
+	}
+	if code, _ := get("cluster=edge&namespace=web&type=vulnerabilityreports&name=missing"); code != http.StatusNotFound {
+		t.Errorf("missing report: got %d", code)
+	}
+}
@@ -0,0 +1,350 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+const (
+	// maxKubeconfigBytes bounds a pasted kubeconfig
+	maxKubeconfigBytes = 1 << 20
+	// onboardingTimeout bounds the requests made to validate a kubeconfig
+	onboardingTimeout = 15 * time.Second
+
+	defaultOnboardingServiceAccount = "trivy-ui-reader"
+	defaultOnboardingNamespace      = "trivy-ui"
+	defaultOperatorNamespace        = "trivy-system"
+)
+
+// dnsLabel matches the names Kubernetes accepts for namespaces and ServiceAccounts.
+var dnsLabel = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// OnboardingManifests are the objects that give trivy-ui read access to a cluster.
+type OnboardingManifests struct {
+	ServiceAccount    string                  `json:"serviceAccount"`
+	Namespace         string                  `json:"namespace"`
+	OperatorNamespace string                  `json:"operatorNamespace"`
+	Features          []string                `json:"features"`
+	ReportKinds       []string                `json:"reportKinds"`
+	Permissions       []kubernetes.Permission `json:"permissions"`
+	YAML              string                  `json:"yaml"`
+}
+
+// OnboardingValidation is the outcome of checking a pasted kubeconfig against what
+// trivy-ui needs.
+type OnboardingValidation struct {
+	Valid       bool     `json:"valid"`
+	Server      string   `json:"server,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	ReportKinds []string `json:"reportKinds"`
+	// OperatorInstalled is false when the cluster serves no Trivy report kinds yet
+	OperatorInstalled bool                     `json:"operatorInstalled"`
+	Checks            []kubernetes.AccessCheck `json:"checks"`
+	// Missing are the denied rules; MissingYAML grants exactly those
+	Missing     []kubernetes.Permission `json:"missing"`
+	MissingYAML string                  `json:"missingYaml,omitempty"`
+	// DuplicateOf is the registered cluster the kubeconfig reaches, if any
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	// SecretYAML registers the cluster through the kubeconfig Secret watcher
+	SecretYAML string `json:"secretYaml,omitempty"`
+}
+
+// onboardingFeatures parses a comma separated features parameter; empty means all of
+// them. The reports feature is always included.
+func onboardingFeatures(param string) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		return kubernetes.OnboardingFeatures, nil
+	}
+	features := []string{kubernetes.FeatureReports}
+	for _, f := range strings.Split(param, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" || slices.Contains(features, f) {
+			continue
+		}
+		if !slices.Contains(kubernetes.OnboardingFeatures, f) {
+			return nil, fmt.Errorf("unknown feature %q, expected one of %s", f, strings.Join(kubernetes.OnboardingFeatures, ", "))
+		}
+		features = append(features, f)
+	}
+	return features, nil
+}
+
+// knownReportKinds returns the resource names of the report kinds this instance reads.
+func (h *Handler) knownReportKinds() []string {
+	var kinds []string
+	for _, k := range h.crdReg.GetAllReports() {
+		kinds = append(kinds, k.Name)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// onboardingRBAC returns the ClusterRole, and the Role of the operator namespace when a
+// permission needs one, that grant perms, as YAML documents. Without a ServiceAccount
+// the bindings are left out.
+func onboardingRBAC(name, serviceAccount, namespace, operatorNamespace string, perms []kubernetes.Permission) []map[string]interface{} {
+	var clusterRules, namespacedRules []interface{}
+	for _, p := range perms {
+		if p.Namespaced() {
+			namespacedRules = append(namespacedRules, p.Rule)
+		} else {
+			clusterRules = append(clusterRules, p.Rule)
+		}
+	}
+	labels := map[string]interface{}{"app.kubernetes.io/name": "trivy-ui"}
+	subjects := []interface{}{map[string]interface{}{"kind": "ServiceAccount", "name": serviceAccount, "namespace": namespace}}
+	var docs []map[string]interface{}
+	if len(clusterRules) > 0 {
+		docs = append(docs, map[string]interface{}{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "ClusterRole",
+			"metadata":   map[string]interface{}{"name": name, "labels": labels},
+			"rules":      clusterRules,
+		})
+		if serviceAccount != "" {
+			docs = append(docs, map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "ClusterRoleBinding",
+				"metadata":   map[string]interface{}{"name": name, "labels": labels},
+				"roleRef":    map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": "ClusterRole", "name": name},
+				"subjects":   subjects,
+			})
+		}
+	}
+	if len(namespacedRules) > 0 {
+		docs = append(docs, map[string]interface{}{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "Role",
+			"metadata":   map[string]interface{}{"name": name, "namespace": operatorNamespace, "labels": labels},
+			"rules":      namespacedRules,
+		})
+		if serviceAccount != "" {
+			docs = append(docs, map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "RoleBinding",
+				"metadata":   map[string]interface{}{"name": name, "namespace": operatorNamespace, "labels": labels},
+				"roleRef":    map[string]interface{}{"apiGroup": "rbac.authorization.k8s.io", "kind": "Role", "name": name},
+				"subjects":   subjects,
+			})
+		}
+	}
+	return docs
+}
+
+// yamlDocuments joins objects into a multi-document YAML stream.
+func yamlDocuments(docs []map[string]interface{}) (string, error) {
+	parts := make([]string, 0, len(docs))
+	for _, d := range docs {
+		out, err := yaml.Marshal(d)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, string(out))
+	}
+	return strings.Join(parts, "---\n"), nil
+}
+
+// GetOnboardingManifests handles GET /api/v1/onboarding/manifests: the namespace,
+// ServiceAccount, token Secret and RBAC that let trivy-ui read a cluster, granting only
+// the report kinds this instance knows (or those of ?kinds=) and the features asked for.
+// The parameters are serviceAccount, namespace, operatorNamespace and features; format=yaml
+// returns the manifests alone, ready for kubectl apply.
+func (h *Handler) GetOnboardingManifests(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	m := OnboardingManifests{
+		ServiceAccount:    defaultString(q.Get("serviceAccount"), defaultOnboardingServiceAccount),
+		Namespace:         defaultString(q.Get("namespace"), defaultOnboardingNamespace),
+		OperatorNamespace: defaultString(q.Get("operatorNamespace"), defaultOperatorNamespace),
+	}
+	for _, name := range []string{m.ServiceAccount, m.Namespace, m.OperatorNamespace} {
+		if !dnsLabel.MatchString(name) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid name %q", name))
+			return
+		}
+	}
+	features, err := onboardingFeatures(q.Get("features"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	m.Features = features
+	m.ReportKinds = h.knownReportKinds()
+	if kinds := q.Get("kinds"); kinds != "" {
+		m.ReportKinds = nil
+		for _, k := range strings.Split(kinds, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				if kind := h.crdReg.ResolveReport(k); kind != nil {
+					k = kind.Name
+				}
+				m.ReportKinds = append(m.ReportKinds, k)
+			}
+		}
+	}
+	m.Permissions = kubernetes.RequiredPermissions(m.ReportKinds, m.Features)
+
+	labels := map[string]interface{}{"app.kubernetes.io/name": "trivy-ui"}
+	docs := []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": m.Namespace},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "ServiceAccount",
+			"metadata":   map[string]interface{}{"name": m.ServiceAccount, "namespace": m.Namespace, "labels": labels},
+		},
+		{
+			// a long-lived token for the kubeconfig trivy-ui is given
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"type":       "kubernetes.io/service-account-token",
+			"metadata": map[string]interface{}{
+				"name":        m.ServiceAccount + "-token",
+				"namespace":   m.Namespace,
+				"labels":      labels,
+				"annotations": map[string]interface{}{"kubernetes.io/service-account.name": m.ServiceAccount},
+			},
+		},
+	}
+	docs = append(docs, onboardingRBAC(m.ServiceAccount, m.ServiceAccount, m.Namespace, m.OperatorNamespace, m.Permissions)...)
+	if m.YAML, err = yamlDocuments(docs); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if q.Get("format") == "yaml" {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("Content-Disposition", `attachment; filename="trivy-ui-rbac.yaml"`)
+		w.Write([]byte(m.YAML))
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: m})
+}
+
+// ValidateOnboardingKubeconfig handles POST /api/v1/onboarding/validate with
+// {"kubeconfig": "...", "name": "...", "operatorNamespace": "...", "features": "..."}: connects
+// with the kubeconfig, discovers the report kinds the cluster serves and checks, with access
+// reviews, every permission trivy-ui needs for them. Denied rules come back with a
+// ClusterRole granting just those. Problems with the kubeconfig are reported in the result,
+// not as an error status, so the wizard can show them next to the input.
+func (h *Handler) ValidateOnboardingKubeconfig(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Kubeconfig        string `json:"kubeconfig"`
+		Name              string `json:"name"`
+		OperatorNamespace string `json:"operatorNamespace"`
+		Features          string `json:"features"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxKubeconfigBytes)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(body.Kubeconfig) == "" {
+		writeError(w, http.StatusBadRequest, "kubeconfig is required")
+		return
+	}
+	if body.Name != "" && !dnsLabel.MatchString(body.Name) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid cluster name %q", body.Name))
+		return
+	}
+	operatorNamespace := defaultString(body.OperatorNamespace, defaultOperatorNamespace)
+	features, err := onboardingFeatures(body.Features)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), onboardingTimeout)
+	defer cancel()
+	result := h.validateKubeconfig(ctx, []byte(body.Kubeconfig), operatorNamespace, features)
+	if result.Valid && body.Name != "" {
+		result.SecretYAML, _ = yamlDocuments([]map[string]interface{}{kubeconfigSecret(body.Name, body.Kubeconfig)})
+	}
+	writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: result})
+}
+
+func (h *Handler) validateKubeconfig(ctx context.Context, data []byte, operatorNamespace string, features []string) OnboardingValidation {
+	result := OnboardingValidation{ReportKinds: []string{}, Checks: []kubernetes.AccessCheck{}, Missing: []kubernetes.Permission{}}
+	clientConfig := kubernetes.DefaultClientConfig()
+	clientConfig.Timeout = onboardingTimeout
+	client, err := kubernetes.NewClientFromKubeconfig(data, clientConfig)
+	if err != nil {
+		result.Error = "Invalid kubeconfig: " + err.Error()
+		return result
+	}
+	result.Server = client.Config().Host
+	if result.Version, err = client.ServerVersion(); err != nil {
+		result.Error = "Cannot reach the API server: " + err.Error()
+		return result
+	}
+
+	kinds, err := client.DiscoverReportKinds(ctx)
+	if err != nil {
+		result.Error = "Cannot discover the Trivy report kinds: " + err.Error()
+		return result
+	}
+	result.OperatorInstalled = len(kinds) > 0
+	if result.OperatorInstalled {
+		result.ReportKinds = kinds
+	}
+
+	checks, err := client.CheckAccess(ctx, kubernetes.RequiredPermissions(kinds, features), operatorNamespace)
+	if err != nil {
+		result.Error = "Cannot review access: " + err.Error()
+		return result
+	}
+	result.Checks = checks
+	if missing := kubernetes.MissingPermissions(checks); len(missing) > 0 {
+		result.Missing = missing
+		result.MissingYAML, _ = yamlDocuments(onboardingRBAC(defaultOnboardingServiceAccount, "", "", operatorNamespace, missing))
+	}
+	// trivy-ui cannot list reports without them, whatever else it may do
+	result.Valid = !slices.ContainsFunc(result.Missing, func(p kubernetes.Permission) bool {
+		return p.Feature == kubernetes.FeatureReports
+	})
+
+	uid, _ := client.GetClusterUID(ctx)
+	h.clusterReg.mu.RLock()
+	if dup := h.clusterReg.duplicateLocked("", result.Server, uid); dup != nil {
+		result.DuplicateOf = dup.Name
+	}
+	h.clusterReg.mu.RUnlock()
+	return result
+}
+
+// kubeconfigSecret returns a Secret the kubeconfig Secret watcher registers as a cluster.
+func kubeconfigSecret(name, kubeconfig string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "Opaque",
+		"metadata": map[string]interface{}{
+			"name":        "kubeconfig-" + name,
+			"namespace":   defaultString(config.Get().KubeconfigSecretNamespace, defaultOnboardingNamespace),
+			"labels":      map[string]interface{}{kubernetes.KubeconfigSecretLabel: "true"},
+			"annotations": map[string]interface{}{kubernetes.ClusterNameAnnotation: name},
+		},
+		"data": map[string]interface{}{"kubeconfig": base64.StdEncoding.EncodeToString([]byte(kubeconfig))},
+	}
+}
+
+// defaultString returns s, or def when s is blank.
+func defaultString(s, def string) string {
+	if s = strings.TrimSpace(s); s == "" {
+		return def
+	}
+	return s
+}
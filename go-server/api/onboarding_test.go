@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+func TestGetOnboardingManifests(t *testing.T) {
+	reg := config.GetGlobalRegistry()
+	reg.Register(config.ReportKind{Name: "vulnerabilityreports", Kind: "VulnerabilityReport", Namespaced: true})
+	h := &Handler{crdReg: reg}
+
+	rec := httptest.NewRecorder()
+	h.GetOnboardingManifests(rec, httptest.NewRequest(http.MethodGet, "/api/v1/onboarding/manifests?kinds=vuln&features=nodes&namespace=security", nil))
+	var resp struct {
+		Data OnboardingManifests `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d: %v", rec.Code, err)
+	}
+	m := resp.Data
+	if len(m.ReportKinds) != 1 || m.ReportKinds[0] != "vulnerabilityreports" {
+		t.Fatalf("expected aliases resolved to resource names, got %v", m.ReportKinds)
+	}
+	if len(m.Features) != 2 || len(m.Permissions) != 4 {
+		t.Fatalf("expected the reports and nodes rules only, got %v %+v", m.Features, m.Permissions)
+	}
+	var kinds []string
+	for _, doc := range strings.Split(m.YAML, "---\n") {
+		var obj struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Subjects []struct {
+				Namespace string `json:"namespace"`
+			} `json:"subjects"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			t.Fatalf("invalid document %q: %v", doc, err)
+		}
+		if len(obj.Subjects) == 1 && obj.Subjects[0].Namespace != "security" {
+			t.Fatalf("expected the ServiceAccount of the namespace asked for, got %+v", obj.Subjects)
+		}
+		kinds = append(kinds, obj.Kind)
+	}
+	if strings.Join(kinds, ",") != "Namespace,ServiceAccount,Secret,ClusterRole,ClusterRoleBinding" {
+		t.Fatalf("unexpected documents %v", kinds)
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetOnboardingManifests(rec, httptest.NewRequest(http.MethodGet, "/api/v1/onboarding/manifests?format=yaml", nil))
+	if rec.Header().Get("Content-Type") != "application/yaml" || !strings.Contains(rec.Body.String(), "kind: RoleBinding") {
+		t.Fatalf("expected all features as raw YAML, got %q", rec.Body.String())
+	}
+
+	for _, query := range []string{"features=secrets", "namespace=Not_Valid"} {
+		rec = httptest.NewRecorder()
+		h.GetOnboardingManifests(rec, httptest.NewRequest(http.MethodGet, "/api/v1/onboarding/manifests?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+// fakeAPIServer serves the discovery, access review and kube-system requests of a
+// kubeconfig validation, denying the verbs of denied ("verb resource").
+func fakeAPIServer(t *testing.T, denied ...string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/version":
+			fmt.Fprint(w, `{"major":"1","minor":"30","gitVersion":"v1.30.2"}`)
+		case "/api":
+			fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+		case "/apis":
+			fmt.Fprint(w, `{"kind":"APIGroupList","apiVersion":"v1","groups":[{"name":"aquasecurity.github.io","versions":[{"groupVersion":"aquasecurity.github.io/v1alpha1","version":"v1alpha1"}],"preferredVersion":{"groupVersion":"aquasecurity.github.io/v1alpha1","version":"v1alpha1"}}]}`)
+		case "/apis/aquasecurity.github.io/v1alpha1":
+			fmt.Fprint(w, `{"kind":"APIResourceList","groupVersion":"aquasecurity.github.io/v1alpha1","resources":[{"name":"vulnerabilityreports","namespaced":true,"kind":"VulnerabilityReport","verbs":["get","list","watch"]},{"name":"vulnerabilityreports/status","namespaced":true,"kind":"VulnerabilityReport","verbs":["get"]}]}`)
+		case "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews":
+			// client-go sends built-in types as protobuf
+			body, _ := io.ReadAll(r.Body)
+			obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(body, nil, nil)
+			review, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+			if err != nil || !ok {
+				http.Error(w, "invalid review", http.StatusBadRequest)
+				return
+			}
+			attrs := review.Spec.ResourceAttributes
+			review.Status.Allowed = !slices.Contains(denied, attrs.Verb+" "+attrs.Resource)
+			json.NewEncoder(w).Encode(review)
+		case "/api/v1/namespaces/kube-system":
+			fmt.Fprint(w, `{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"kube-system","uid":"uid-1"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testKubeconfig(server string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: c
+  cluster:
+    server: %s
+users:
+- name: u
+  user:
+    token: t
+contexts:
+- name: c
+  context:
+    cluster: c
+    user: u
+current-context: c
+`, server)
+}
+
+func validateOnboarding(t *testing.T, h *Handler, body map[string]string) (int, OnboardingValidation) {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	rec := httptest.NewRecorder()
+	h.ValidateOnboardingKubeconfig(rec, httptest.NewRequest(http.MethodPost, "/api/v1/onboarding/validate", strings.NewReader(string(data))))
+	var resp struct {
+		Data OnboardingValidation `json:"data"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	return rec.Code, resp.Data
+}
+
+func TestValidateOnboardingKubeconfig(t *testing.T) {
+	reg := NewClusterRegistry(&stubCacheService{})
+	h := &Handler{clusterReg: reg, crdReg: config.GetGlobalRegistry()}
+
+	srv := fakeAPIServer(t)
+	code, result := validateOnboarding(t, h, map[string]string{"kubeconfig": testKubeconfig(srv.URL), "name": "eu-1"})
+	if code != http.StatusOK || !result.Valid || result.Error != "" {
+		t.Fatalf("expected a valid kubeconfig, got %d %+v", code, result)
+	}
+	if result.Version != "v1.30.2" || !result.OperatorInstalled || len(result.ReportKinds) != 1 || result.ReportKinds[0] != "vulnerabilityreports" {
+		t.Fatalf("unexpected discovery %+v", result)
+	}
+	if len(result.Missing) != 0 || len(result.Checks) == 0 {
+		t.Fatalf("expected every check allowed, got %+v", result.Checks)
+	}
+	if !strings.Contains(result.SecretYAML, kubernetes.KubeconfigSecretLabel) || !strings.Contains(result.SecretYAML, "eu-1") {
+		t.Fatalf("expected a kubeconfig Secret for eu-1, got %q", result.SecretYAML)
+	}
+
+	srv = fakeAPIServer(t, "list namespaces", "list nodes")
+	_, result = validateOnboarding(t, h, map[string]string{"kubeconfig": testKubeconfig(srv.URL), "features": "nodes"})
+	if result.Valid || len(result.Missing) != 2 || result.SecretYAML != "" {
+		t.Fatalf("expected the denied rules reported, got %+v", result)
+	}
+	if !strings.Contains(result.MissingYAML, "kind: ClusterRole") || !strings.Contains(result.MissingYAML, "- namespaces") {
+		t.Fatalf("expected a ClusterRole granting the missing rules, got %q", result.MissingYAML)
+	}
+
+	srv = fakeAPIServer(t, "list nodes")
+	_, result = validateOnboarding(t, h, map[string]string{"kubeconfig": testKubeconfig(srv.URL), "features": "nodes"})
+	if !result.Valid || len(result.Missing) != 1 {
+		t.Fatalf("expected optional features not to invalidate the kubeconfig, got %+v", result)
+	}
+
+	_, result = validateOnboarding(t, h, map[string]string{"kubeconfig": "not: [a kubeconfig"})
+	if result.Valid || !strings.HasPrefix(result.Error, "Invalid kubeconfig") {
+		t.Fatalf("expected an invalid kubeconfig error, got %+v", result)
+	}
+	if code, _ := validateOnboarding(t, h, map[string]string{}); code != http.StatusBadRequest {
+		t.Fatalf("expected a missing kubeconfig to be rejected, got %d", code)
+	}
+}
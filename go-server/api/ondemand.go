@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/credentials"
+	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
+)
+
+// OnDemandCluster is the synthetic cluster the reports of on-demand scans are kept under.
+const OnDemandCluster = "ondemand"
+
+const (
+	onDemandNamespace = "default"
+	// maxOnDemandScans is how many scans run at once; each pulls an image
+	maxOnDemandScans = 2
+)
+
+var (
+	onDemandMu    sync.Mutex
+	onDemandSlots = make(chan struct{}, maxOnDemandScans)
+)
+
+// ScanRequest asks for an on-demand scan of an image, e.g. "nginx:1.27" or
+// "registry.example.com/app@sha256:...".
+type ScanRequest struct {
+	Image string `json:"image"`
+}
+
+// onDemandReportName derives a report name from an image reference: lower case, with
+// anything but letters, digits, dots and dashes replaced, and shortened with a hash when
+// longer than a Kubernetes name may be.
+func onDemandReportName(image string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, image)
+	name = strings.Trim(name, "-.")
+	if len(name) > 63 {
+		sum := sha256.Sum256([]byte(image))
+		name = strings.TrimRight(name[:52], "-.") + "-" + hex.EncodeToString(sum[:])[:10]
+	}
+	return name
+}
+
+// onDemandKind is the vulnerability report kind, registered when no cluster has its CRD.
+func (h *Handler) onDemandKind() config.ReportKind {
+	if kind := h.crdReg.ResolveReport("vulnerabilityreports"); kind != nil {
+		return *kind
+	}
+	kind := config.ReportKind{
+		Name:       "vulnerabilityreports",
+		ShortName:  "vulnerabilityreport",
+		APIVersion: config.TrivyGroup + "/" + config.DefaultAPIVersion,
+		Namespaced: true,
+		Kind:       "VulnerabilityReport",
+	}
+	h.crdReg.Register(kind)
+	return kind
+}
+
+// onDemandSource returns the report source of the on-demand cluster, registering the
+// cluster on the first scan. Like a report directory it is served from memory, so the
+// details of earlier scans are gone after a restart while their summaries stay cached.
+func (h *Handler) onDemandSource() (*kubernetes.MemorySource, error) {
+	onDemandMu.Lock()
+	defer onDemandMu.Unlock()
+	if cc := h.clusterReg.Get(OnDemandCluster); cc != nil {
+		if src, ok := cc.Source.(*kubernetes.MemorySource); ok {
+			return src, nil
+		}
+		return nil, fmt.Errorf("cluster name %q is already used by another cluster", OnDemandCluster)
+	}
+	src := kubernetes.NewMemorySource()
+	src.AddNamespace(onDemandNamespace)
+	if err := h.clusterReg.SetSource(OnDemandCluster, src); err != nil {
+		return nil, err
+	}
+	if err := src.Watch(OnDemandCluster, NewCacheUpdater(h.clusterReg)); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// scanImage scans an image and stores the result as a report of the on-demand cluster,
+// replacing the previous scan of the same image, and returns its details.
+func (h *Handler) scanImage(ctx context.Context, scanner kubernetes.TrivyScanner, image string) (Report, error) {
+	kind := h.onDemandKind()
+	src, err := h.onDemandSource()
+	if err != nil {
+		return Report{}, err
+	}
+	name := onDemandReportName(image)
+	obj, err := scanner.ScanImage(ctx, image, onDemandNamespace, name)
+	if err != nil {
+		return Report{}, err
+	}
+	src.Put(kind, obj)
+	return h.loadReportDetail(ctx, kind, OnDemandCluster, onDemandNamespace, name)
+}
+
+// ScanImage handles POST /api/v1/scan: it scans an image through the trivy server of
+// TRIVY_SERVER_URL, e.g. before deploying it, keeps the result as a report of the
+// "ondemand" cluster and returns it.
+func (h *Handler) ScanImage(w http.ResponseWriter, r *http.Request) {
+	cfg := config.Get()
+	if cfg.TrivyServerURL == "" {
+		writeError(w, http.StatusNotFound, "On-demand scans are disabled, set TRIVY_SERVER_URL")
+		return
+	}
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	image := strings.TrimSpace(req.Image)
+	if image == "" || strings.HasPrefix(image, "-") || strings.ContainsAny(image, " \t\n") {
+		writeError(w, http.StatusBadRequest, "Missing or invalid image")
+		return
+	}
+
+	select {
+	case onDemandSlots <- struct{}{}:
+		defer func() { <-onDemandSlots }()
+	default:
+		writeError(w, http.StatusTooManyRequests, "Too many scans in progress, retry later")
+		return
+	}
+
+	scanner := kubernetes.TrivyScanner{
+		Binary:    cfg.TrivyBinary,
+		ServerURL: cfg.TrivyServerURL,
+		Token:     credentials.Get(credentials.TrivyServerToken),
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), cfg.TrivyScanTimeout)
+	defer cancel()
+	start := time.Now()
+	report, err := h.scanImage(ctx, scanner, image)
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		utils.LogWarning("On-demand scan failed", map[string]interface{}{"image": image, "error": err.Error()})
+		status := http.StatusBadGateway
+		if errors.Is(err, context.DeadlineExceeded) {
+			status = http.StatusGatewayTimeout
+		}
+		writeError(w, status, "Scan failed: "+err.Error())
+		return
+	}
+	critical, high, _, _ := extractSummaryCounts(report)
+	utils.LogInfo("On-demand scan finished", map[string]interface{}{
+		"image":    image,
+		"report":   report.Name,
+		"critical": critical,
+		"high":     high,
+		"duration": time.Since(start).String(),
+	})
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    report,
+	})
+}
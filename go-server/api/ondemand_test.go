@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+func fakeTrivyScanner(t *testing.T, critical int) kubernetes.TrivyScanner {
+	t.Helper()
+	vulns := strings.TrimSuffix(strings.Repeat(`{"VulnerabilityID": "CVE-2024-1", "PkgName": "openssl", "InstalledVersion": "3.0.1", "Severity": "CRITICAL"},`, critical), ",")
+	out := `{"ArtifactName": "ghcr.io/org/app:v1", "Results": [{"Target": "app", "Vulnerabilities": [` + vulns + `]}]}`
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "out.json"), []byte(out), 0644)
+	binary := filepath.Join(dir, "trivy")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\ncat "+filepath.Join(dir, "out.json")+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return kubernetes.TrivyScanner{Binary: binary, ServerURL: "http://trivy:4954"}
+}
+
+func TestOnDemandScan(t *testing.T) {
+	if got := onDemandReportName("ghcr.io/Org/app:v1"); got != "ghcr.io-org-app-v1" {
+		t.Errorf("report name %q", got)
+	}
+	if got := onDemandReportName(strings.Repeat("a", 80) + ":v1"); len(got) > 63 {
+		t.Errorf("report name %q longer than 63", got)
+	}
+
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := config.GetGlobalRegistry()
+	h := NewHandler(nil, svc, NewClusterRegistry(svc), NewQueryService(svc), reg)
+
+	report, err := h.scanImage(context.Background(), fakeTrivyScanner(t, 2), "ghcr.io/org/app:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Cluster != OnDemandCluster || report.Namespace != "default" || report.Name != "ghcr.io-org-app-v1" {
+		t.Fatalf("unexpected report %s/%s/%s", report.Cluster, report.Namespace, report.Name)
+	}
+	if critical, _, _, _ := extractSummaryCounts(report); critical != 2 {
+		t.Errorf("critical = %d, want 2", critical)
+	}
+	if h.clusterReg.Get(OnDemandCluster) == nil {
+		t.Fatal("on-demand cluster not registered")
+	}
+	key := reportKey(OnDemandCluster, "default", "vulnerabilityreports", "ghcr.io-org-app-v1")
+	if _, ok := c.Get(key); !ok {
+		t.Fatal("scan not cached")
+	}
+
+	// a rescan of the image replaces its report
+	if _, err := h.scanImage(context.Background(), fakeTrivyScanner(t, 0), "ghcr.io/org/app:v1"); err != nil {
+		t.Fatal(err)
+	}
+	value, _ := c.Get(key)
+	cached, _ := convertCacheValue[Report](value)
+	if critical, _, _, _ := extractSummaryCounts(cached); critical != 0 {
+		t.Errorf("rescan not applied, critical = %d", critical)
+	}
+}
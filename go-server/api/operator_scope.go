@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
+)
+
+const operatorScopeTTL = 5 * time.Minute
+
+var (
+	operatorScopesMu sync.Mutex
+	operatorScopes   = make(map[string]*cachedOperatorScope)
+)
+
+type cachedOperatorScope struct {
+	mu        sync.Mutex
+	scope     kubernetes.OperatorScope
+	fetchedAt time.Time
+}
+
+// operatorScopeFor returns a cluster's trivy-operator namespace settings from a cache
+// refreshed every few minutes. Pushed clusters have no client and an unknown scope.
+func operatorScopeFor(cluster string) kubernetes.OperatorScope {
+	cc := GetClusterClient(cluster)
+	if cc == nil || cc.Client == nil {
+		return kubernetes.OperatorScope{}
+	}
+
+	operatorScopesMu.Lock()
+	entry := operatorScopes[cluster]
+	if entry == nil {
+		entry = &cachedOperatorScope{}
+		operatorScopes[cluster] = entry
+	}
+	operatorScopesMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if time.Since(entry.fetchedAt) > operatorScopeTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		scope, err := cc.Client.GetOperatorScope(ctx)
+		cancel()
+		if err != nil {
+			utils.LogWarning("Failed to read trivy-operator namespace settings", map[string]interface{}{"cluster": cluster, "error": err.Error()})
+			scope = kubernetes.OperatorScope{}
+		}
+		entry.scope = scope
+		// also back off after failures
+		entry.fetchedAt = time.Now()
+	}
+	return entry.scope
+}
+
+// withScanStatus returns copies of the namespaces annotated with whether the operator
+// scans them, so an empty namespace can be told apart from an unscanned one.
+func withScanStatus(scope kubernetes.OperatorScope, namespaces []Namespace) []Namespace {
+	result := make([]Namespace, len(namespaces))
+	for i, ns := range namespaces {
+		ns.ScanStatus = scope.ScanStatus(ns.Name)
+		result[i] = ns
+	}
+	return result
+}
+
+// GetOperatorScope handles GET /api/clusters/{cluster}/operator-scope.
+func (h *Handler) GetOperatorScope(w http.ResponseWriter, r *http.Request, cluster string) {
+	if h.clusterReg.Get(cluster) == nil {
+		writeError(w, http.StatusNotFound, "Cluster not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: operatorScopeFor(cluster)})
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"testing"
+
+	"trivy-ui/kubernetes"
+)
+
+func TestWithScanStatus(t *testing.T) {
+	scope := kubernetes.OperatorScope{Found: true, TargetNamespaces: []string{"apps"}, ExcludeNamespaces: []string{"kube-*"}}
+	namespaces := []Namespace{{Cluster: "c1", Name: "apps"}, {Cluster: "c1", Name: "kube-system"}, {Cluster: "c1", Name: "default"}}
+
+	got := withScanStatus(scope, namespaces)
+	want := []string{kubernetes.ScanStatusScanned, kubernetes.ScanStatusExcluded, kubernetes.ScanStatusNotTargeted}
+	for i, ns := range got {
+		if ns.ScanStatus != want[i] {
+			t.Errorf("%s: expected %s, got %s", ns.Name, want[i], ns.ScanStatus)
+		}
+	}
+	if namespaces[0].ScanStatus != "" {
+		t.Fatal("input namespaces must not be modified")
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+
+	"trivy-ui/metrics"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// externalizedFields are the report arrays that make details large; oversized details
+// keep them in the store and only the remaining fields in memory.
+var externalizedFields = map[string]bool{
+	"vulnerabilities": true,
+	"checks":          true,
+	"secrets":         true,
+	"components":      true,
+	"dependencies":    true,
+}
+
+// externalizeLargeFields returns the copy of a report detail to cache. Details larger
+// than threshold bytes have their large arrays saved as a store blob and dropped from
+// the copy; the report passed in is never modified.
+func externalizeLargeFields(ctx context.Context, st *store.Store, report Report, threshold int) Report {
+	if threshold <= 0 || st == nil {
+		return report
+	}
+	data, ok := report.Data.(map[string]interface{})
+	if !ok {
+		return report
+	}
+	body, ok := data["report"].(map[string]interface{})
+	if !ok || metrics.SerializedSize(data) <= threshold {
+		return report
+	}
+
+	large := make(map[string]interface{})
+	slim := make(map[string]interface{}, len(body))
+	for k, v := range body {
+		if externalizedFields[k] {
+			large[k] = v
+		} else {
+			slim[k] = v
+		}
+	}
+	if len(large) == 0 {
+		return report
+	}
+	blob, err := json.Marshal(large)
+	if err != nil {
+		return report
+	}
+	if err := st.SaveReportBlob(ctx, reportRef(report), blob); err != nil {
+		utils.LogWarning("Failed to externalize oversized report, keeping it in memory", map[string]interface{}{
+			"cluster": report.Cluster,
+			"type":    report.Type,
+			"name":    report.Name,
+			"error":   err.Error(),
+		})
+		return report
+	}
+
+	slimData := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		slimData[k] = v
+	}
+	slimData["report"] = slim
+	report.Data = slimData
+	report.Externalized = true
+	metrics.ExternalizedReports.WithLabelValues(report.Type).Inc()
+	return report
+}
+
+// hydrateReportDetail merges the externalized fields back into a cached detail. It
+// reports false when the blob is gone so callers refetch the report instead of
+// serving it without findings.
+func hydrateReportDetail(ctx context.Context, st *store.Store, report Report) (Report, bool) {
+	if !report.Externalized {
+		return report, true
+	}
+	data, ok := report.Data.(map[string]interface{})
+	if st == nil || !ok {
+		return Report{}, false
+	}
+	ctx, cancel := storeCallContext(ctx)
+	defer cancel()
+	blob, found, err := st.GetReportBlob(ctx, reportRef(report))
+	if err != nil || !found {
+		return Report{}, false
+	}
+	var large map[string]interface{}
+	if err := json.Unmarshal(blob, &large); err != nil {
+		return Report{}, false
+	}
+
+	body, _ := data["report"].(map[string]interface{})
+	full := make(map[string]interface{}, len(body)+len(large))
+	for k, v := range body {
+		full[k] = v
+	}
+	for k, v := range large {
+		full[k] = v
+	}
+	fullData := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		fullData[k] = v
+	}
+	fullData["report"] = full
+	report.Data = fullData
+	report.Externalized = false
+	return report, true
+}
+
+func deleteReportBlob(cluster, namespace, reportType, name string) {
+	if st := store.Get(); st != nil {
+		ctx, cancel := storeCallContext(context.Background())
+		defer cancel()
+		st.DeleteReportBlob(ctx, store.ReportRef{Cluster: cluster, Namespace: namespace, ReportType: reportType, ReportName: name})
+	}
+}
+
+func reportRef(report Report) store.ReportRef {
+	return store.ReportRef{Cluster: report.Cluster, Namespace: report.Namespace, ReportType: report.Type, ReportName: report.Name}
+}
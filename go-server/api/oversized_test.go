@@ -0,0 +1,81 @@
+package api
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trivy-ui/store"
+)
+
+func oversizedReport() Report {
+	vulns := make([]interface{}, 0, 50)
+	for i := 0; i < 50; i++ {
+		vulns = append(vulns, map[string]interface{}{"vulnerabilityID": "CVE-" + strings.Repeat("1", 10)})
+	}
+	return Report{
+		Type: "vulnerabilityreports", Cluster: "c1", Namespace: "ns", Name: "big",
+		Data: map[string]interface{}{
+			"kind": "VulnerabilityReport",
+			"report": map[string]interface{}{
+				"summary":         map[string]interface{}{"criticalCount": 1.0},
+				"vulnerabilities": vulns,
+			},
+		},
+	}
+}
+
+func TestExternalizeLargeFields_RoundTrip(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	full := oversizedReport()
+	cached := externalizeLargeFields(t.Context(), st, full, 100)
+	if !cached.Externalized {
+		t.Fatal("expected report above the threshold to be externalized")
+	}
+	body := cached.Data.(map[string]interface{})["report"].(map[string]interface{})
+	if _, ok := body["vulnerabilities"]; ok {
+		t.Fatal("cached copy should not hold vulnerabilities")
+	}
+	if _, ok := body["summary"]; !ok {
+		t.Fatal("cached copy should keep the summary")
+	}
+	if _, ok := full.Data.(map[string]interface{})["report"].(map[string]interface{})["vulnerabilities"]; !ok {
+		t.Fatal("the original report must not be modified")
+	}
+
+	hydrated, ok := hydrateReportDetail(t.Context(), st, cached)
+	if !ok || hydrated.Externalized {
+		t.Fatalf("expected hydrated report, got %v %+v", ok, hydrated)
+	}
+	vulns := hydrated.Data.(map[string]interface{})["report"].(map[string]interface{})["vulnerabilities"].([]interface{})
+	if len(vulns) != 50 {
+		t.Fatalf("expected 50 vulnerabilities back, got %d", len(vulns))
+	}
+
+	if err := st.DeleteReportBlob(t.Context(), reportRef(cached)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hydrateReportDetail(t.Context(), st, cached); ok {
+		t.Fatal("missing blob should be treated as a cache miss")
+	}
+}
+
+func TestExternalizeLargeFields_BelowThreshold(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	if cached := externalizeLargeFields(t.Context(), st, oversizedReport(), 1<<20); cached.Externalized {
+		t.Fatal("small report should stay in memory")
+	}
+	if cached := externalizeLargeFields(t.Context(), nil, oversizedReport(), 100); cached.Externalized {
+		t.Fatal("without a store the report should stay in memory")
+	}
+}
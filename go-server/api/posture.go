@@ -0,0 +1,232 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strings"
+)
+
+// Posture factors, the signals a workload's grade is made of.
+const (
+	postureVulnerabilities   = "vulnerabilities"
+	postureMisconfigurations = "misconfigurations"
+	postureSecrets           = "secrets"
+	postureRBAC              = "rbac"
+	postureSecurityContext   = "securityContext"
+)
+
+// postureWeights are the points deducted per finding of each severity, critical first,
+// and the most a factor can deduct.
+var postureWeights = map[string]struct {
+	perSeverity [4]float64
+	max         float64
+}{
+	postureVulnerabilities:   {[4]float64{10, 4, 1, 0.25}, 40},
+	postureMisconfigurations: {[4]float64{8, 4, 1, 0.25}, 25},
+	postureSecrets:           {[4]float64{15, 10, 3, 1}, 25},
+	postureRBAC:              {[4]float64{5, 2, 0.5, 0}, 10},
+}
+
+// Points the security context factor deducts, at most postureContextMax.
+const (
+	posturePrivileged    = 10
+	postureHostNamespace = 5
+	postureRunAsRoot     = 3
+	postureContextMax    = 15
+)
+
+// postureReportSuffixes map the namespaced report kinds to their factor; kinds are matched
+// by suffix like elsewhere, so renamed groups still count.
+var postureReportSuffixes = []struct {
+	suffix, factor string
+}{
+	{"vulnerabilityreports", postureVulnerabilities},
+	{"configauditreports", postureMisconfigurations},
+	{"exposedsecretreports", postureSecrets},
+	{"rbacassessmentreports", postureRBAC},
+}
+
+// PostureFactor is one signal of a workload's posture and the points it cost.
+type PostureFactor struct {
+	Name string `json:"name"`
+	// Known is false when the signal is not available, e.g. no report of the kind or, for
+	// the security context, a cluster without a client; unknown factors deduct nothing
+	Known      bool            `json:"known"`
+	Reports    int             `json:"reports"`
+	Severity   *SeverityTotals `json:"severity,omitempty"`
+	Exposure   *ReportExposure `json:"exposure,omitempty"`
+	Deduction  float64         `json:"deduction"`
+	MaxPenalty float64         `json:"maxPenalty"`
+}
+
+// WorkloadPosture grades a workload from A to F by combining its vulnerabilities,
+// misconfigurations, exposed secrets, the RBAC findings of its namespace and its security
+// context. The score starts at 100 and each factor deducts points per finding, weighted
+// by severity and capped per factor, so one noisy signal cannot hide the others:
+//
+//	A >= 90, B >= 80, C >= 70, D >= 60, F below
+//
+// RBAC assessments are made for Roles, not workloads, so the factor counts those of the
+// workload's namespace.
+type WorkloadPosture struct {
+	Cluster   string          `json:"cluster"`
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name"`
+	Kind      string          `json:"kind,omitempty"`
+	Score     float64         `json:"score"`
+	Grade     string          `json:"grade"`
+	Factors   []PostureFactor `json:"factors"`
+}
+
+func postureGrade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	}
+	return "F"
+}
+
+// severityDeduction weighs severity totals for a factor, capped at its maximum.
+func severityDeduction(factor string, s SeverityTotals) float64 {
+	w := postureWeights[factor]
+	points := float64(s.Critical)*w.perSeverity[0] + float64(s.High)*w.perSeverity[1] +
+		float64(s.Medium)*w.perSeverity[2] + float64(s.Low)*w.perSeverity[3]
+	return roundScore(math.Min(points, w.max))
+}
+
+func contextDeduction(e *ReportExposure) float64 {
+	points := 0.0
+	if e.Privileged {
+		points += posturePrivileged
+	}
+	if e.HostNetwork || e.HostPID || e.HostIPC {
+		points += postureHostNamespace
+	}
+	if e.RunAsRoot {
+		points += postureRunAsRoot
+	}
+	return math.Min(points, postureContextMax)
+}
+
+// latestWorkloadReports keeps the reports of the most recently scanned resource of a
+// workload, so the reports of the ReplicaSets of earlier rollouts are not counted twice.
+func latestWorkloadReports(reports []Report) []Report {
+	if len(reports) == 0 {
+		return nil
+	}
+	_, latest := reportResource(reports[0])
+	latestAt := reportTime(reports[0])
+	for _, r := range reports[1:] {
+		if reportTime(r).After(latestAt) {
+			_, latest = reportResource(r)
+			latestAt = reportTime(r)
+		}
+	}
+	var kept []Report
+	for _, r := range reports {
+		if _, resource := reportResource(r); resource == latest {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// computeWorkloadPosture grades a workload from the cached reports of its namespace, and
+// reports false when the workload has no report.
+func (h *Handler) computeWorkloadPosture(cluster, namespace, name string, exposure func(Report) *ReportExposure) (WorkloadPosture, bool) {
+	posture := WorkloadPosture{Cluster: cluster, Namespace: namespace, Name: name}
+	totals := make(map[string]*SeverityTotals)
+	counts := make(map[string]int)
+	var sample *Report
+
+	for _, kind := range h.crdReg.GetAllReports() {
+		if !kind.Namespaced {
+			continue
+		}
+		factor := ""
+		for _, s := range postureReportSuffixes {
+			if strings.HasSuffix(kind.Name, s.suffix) {
+				factor = s.factor
+				break
+			}
+		}
+		if factor == "" {
+			continue
+		}
+		var matched []Report
+		for _, r := range h.cache.GetReports(kind.Name, cluster, []string{namespace}) {
+			if factor == postureRBAC || reportWorkload(r) == name {
+				matched = append(matched, r)
+			}
+		}
+		if factor != postureRBAC {
+			matched = latestWorkloadReports(matched)
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		if totals[factor] == nil {
+			totals[factor] = &SeverityTotals{}
+		}
+		for i, r := range matched {
+			c, hi, m, l := extractSummaryCounts(r)
+			totals[factor].Critical += c
+			totals[factor].High += hi
+			totals[factor].Medium += m
+			totals[factor].Low += l
+			counts[factor]++
+			if factor != postureRBAC && sample == nil {
+				sample = &matched[i]
+			}
+		}
+	}
+	if sample == nil {
+		return posture, false
+	}
+	posture.Kind, _ = reportResource(*sample)
+	if posture.Kind == "ReplicaSet" {
+		posture.Kind = "Deployment"
+	}
+
+	score := 100.0
+	for _, factor := range []string{postureVulnerabilities, postureMisconfigurations, postureSecrets, postureRBAC} {
+		f := PostureFactor{Name: factor, MaxPenalty: postureWeights[factor].max}
+		if t := totals[factor]; t != nil {
+			f.Known, f.Reports, f.Severity = true, counts[factor], t
+			f.Deduction = severityDeduction(factor, *t)
+		}
+		score -= f.Deduction
+		posture.Factors = append(posture.Factors, f)
+	}
+	ctx := PostureFactor{Name: postureSecurityContext, MaxPenalty: postureContextMax}
+	if e := exposure(*sample); e != nil {
+		ctx.Known, ctx.Exposure = true, e
+		ctx.Deduction = contextDeduction(e)
+	}
+	score -= ctx.Deduction
+	posture.Factors = append(posture.Factors, ctx)
+
+	posture.Score = roundScore(math.Max(score, 0))
+	posture.Grade = postureGrade(posture.Score)
+	return posture, true
+}
+
+// GetWorkloadScore handles GET /api/v1/workloads/{cluster}/{namespace}/{name}/score.
+func (h *Handler) GetWorkloadScore(w http.ResponseWriter, r *http.Request, cluster, namespace, name string) {
+	posture, ok := h.computeWorkloadPosture(cluster, namespace, name, reportExposure)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Workload not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    posture,
+	})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+func postureReport(name, typ, kind, resource string, critical, high float64, scanned time.Time) Report {
+	return Report{Name: name, Cluster: "prod", Namespace: "shop", Type: typ, ScannedAt: scanned, Data: map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{
+			"trivy-operator.resource.kind": kind,
+			"trivy-operator.resource.name": resource,
+		}},
+		"report": map[string]interface{}{"summary": map[string]interface{}{"criticalCount": critical, "highCount": high}},
+	}}
+}
+
+func TestWorkloadPosture(t *testing.T) {
+	reg := config.GetGlobalRegistry()
+	for _, name := range []string{"posturetestvulnerabilityreports", "posturetestconfigauditreports", "posturetestrbacassessmentreports"} {
+		reg.Register(config.ReportKind{Name: name, Namespaced: true})
+	}
+	now := time.Now()
+	cache := &stubCacheService{reports: map[string][]Report{
+		"posturetestvulnerabilityreports": {
+			// the ReplicaSet of the previous rollout is not counted
+			postureReport("replicaset-web-old-app", "posturetestvulnerabilityreports", "ReplicaSet", "web-old", 5, 0, now.Add(-time.Hour)),
+			postureReport("replicaset-web-new-app", "posturetestvulnerabilityreports", "ReplicaSet", "web-new", 1, 2, now),
+			postureReport("replicaset-api-1-app", "posturetestvulnerabilityreports", "ReplicaSet", "api-1", 9, 9, now),
+		},
+		"posturetestconfigauditreports": {
+			postureReport("replicaset-web-new", "posturetestconfigauditreports", "ReplicaSet", "web-new", 0, 1, now),
+		},
+		"posturetestrbacassessmentreports": {
+			postureReport("role-reader", "posturetestrbacassessmentreports", "Role", "reader", 0, 1, now),
+		},
+	}}
+	h := &Handler{cache: cache, crdReg: reg}
+	privileged := func(Report) *ReportExposure {
+		return newReportExposure(kubernetes.Exposure{Privileged: true, RunAsRoot: true})
+	}
+
+	posture, ok := h.computeWorkloadPosture("prod", "shop", "web", privileged)
+	if !ok {
+		t.Fatal("workload not found")
+	}
+	factors := make(map[string]PostureFactor)
+	for _, f := range posture.Factors {
+		factors[f.Name] = f
+	}
+	// 100 - (10 + 2*4) - 4 - 2 - (10 + 3)
+	if posture.Score != 63 || posture.Grade != "D" || posture.Kind != "Deployment" {
+		t.Errorf("unexpected posture %+v", posture)
+	}
+	if f := factors[postureVulnerabilities]; f.Reports != 1 || f.Deduction != 18 || f.Severity.Critical != 1 {
+		t.Errorf("unexpected vulnerabilities factor %+v", f)
+	}
+	if f := factors[postureSecrets]; f.Known || f.Deduction != 0 {
+		t.Errorf("secrets without reports should be unknown: %+v", f)
+	}
+	if f := factors[postureSecurityContext]; !f.Known || f.Deduction != 13 {
+		t.Errorf("unexpected security context factor %+v", f)
+	}
+
+	// deductions are capped per factor
+	api, _ := h.computeWorkloadPosture("prod", "shop", "api", func(Report) *ReportExposure { return nil })
+	if api.Score != 58 || api.Grade != "F" {
+		t.Errorf("unexpected capped posture %+v", api)
+	}
+	if _, ok := h.computeWorkloadPosture("prod", "shop", "missing", privileged); ok {
+		t.Error("workload without reports graded")
+	}
+}
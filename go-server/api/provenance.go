@@ -0,0 +1,123 @@
+package api
+
+import (
+	"net/url"
+	"strings"
+)
+
+// OCI image annotations describing where an image was built from, see
+// https://github.com/opencontainers/image-spec/blob/main/annotations.md
+const (
+	ociSourceAnnotation     = "org.opencontainers.image.source"
+	ociRevisionAnnotation   = "org.opencontainers.image.revision"
+	ociBaseNameAnnotation   = "org.opencontainers.image.base.name"
+	ociBaseDigestAnnotation = "org.opencontainers.image.base.digest"
+	ociCreatedAnnotation    = "org.opencontainers.image.created"
+)
+
+// ImageProvenance is the source repository, commit and base image of a scanned image, as
+// declared by its OCI annotations. CommitURL points at the exact commit for the Git hosts
+// we know the URL layout of.
+type ImageProvenance struct {
+	Source     string `json:"source,omitempty"`
+	Revision   string `json:"revision,omitempty"`
+	CommitURL  string `json:"commitUrl,omitempty"`
+	BaseImage  string `json:"baseImage,omitempty"`
+	BaseDigest string `json:"baseDigest,omitempty"`
+}
+
+// reportProvenance reads the image's OCI annotations. It returns nil when the image
+// declares none.
+func reportProvenance(r Report) *ImageProvenance {
+	lookup := reportImageAnnotations(r)
+	p := ImageProvenance{
+		Source:     repositoryURL(lookup(ociSourceAnnotation)),
+		Revision:   lookup(ociRevisionAnnotation),
+		BaseImage:  lookup(ociBaseNameAnnotation),
+		BaseDigest: lookup(ociBaseDigestAnnotation),
+	}
+	if p == (ImageProvenance{}) {
+		return nil
+	}
+	p.CommitURL = commitURL(p.Source, p.Revision)
+	return &p
+}
+
+// reportImageAnnotations looks OCI annotations up in the report's artifact, where
+// scanners that record image annotations or config labels put them, then in the report's
+// own annotations and labels.
+func reportImageAnnotations(r Report) func(key string) string {
+	lookups := []map[string]interface{}{}
+	if artifact := reportSection(r, "artifact"); artifact != nil {
+		for _, key := range []string{"annotations", "labels"} {
+			if m, ok := artifact[key].(map[string]interface{}); ok {
+				lookups = append(lookups, m)
+			}
+		}
+	}
+	if data, ok := r.Data.(map[string]interface{}); ok {
+		if metadata, ok := data["metadata"].(map[string]interface{}); ok {
+			for _, key := range []string{"annotations", "labels"} {
+				if m, ok := metadata[key].(map[string]interface{}); ok {
+					lookups = append(lookups, m)
+				}
+			}
+		}
+	}
+	return func(key string) string {
+		for _, m := range lookups {
+			if v, _ := m[key].(string); v != "" {
+				return strings.TrimSpace(v)
+			}
+		}
+		return ""
+	}
+}
+
+// repositoryURL turns the source annotation into a browsable URL: scp-like and ssh
+// remotes become https on the same host and the .git suffix is dropped. Values that are
+// not URLs are kept.
+func repositoryURL(source string) string {
+	if source == "" {
+		return ""
+	}
+	if rest, ok := strings.CutPrefix(source, "git@"); ok && !strings.Contains(rest, "://") {
+		if host, path, ok := strings.Cut(rest, ":"); ok {
+			source = "https://" + host + "/" + path
+		}
+	}
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return source
+	}
+	switch u.Scheme {
+	case "ssh", "git", "git+ssh":
+		// the ssh port says nothing about the web UI's
+		u.Scheme, u.User, u.Host = "https", nil, u.Hostname()
+	case "git+https":
+		u.Scheme = "https"
+	}
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), ".git")
+	return u.String()
+}
+
+// commitURL links a revision on its repository's web UI. GitLab and Bitbucket use their
+// own paths; GitHub, Gitea, Forgejo and Azure DevOps share /commit/.
+func commitURL(source, revision string) string {
+	if revision == "" || strings.ContainsAny(revision, " /?#") {
+		return ""
+	}
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return source + "/-/commit/" + revision
+	case host == "bitbucket.org":
+		return source + "/commits/" + revision
+	default:
+		return source + "/commit/" + revision
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import "testing"
+
+func TestRepositoryURL(t *testing.T) {
+	for source, want := range map[string]string{
+		"https://github.com/acme/shop":            "https://github.com/acme/shop",
+		"https://github.com/acme/shop.git":        "https://github.com/acme/shop",
+		"git@github.com:acme/shop.git":            "https://github.com/acme/shop",
+		"ssh://git@gitlab.corp:2222/team/api.git": "https://gitlab.corp/team/api",
+		"not a url": "not a url",
+	} {
+		if got := repositoryURL(source); got != want {
+			t.Errorf("repositoryURL(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
+
+func TestCommitURL(t *testing.T) {
+	for _, tc := range []struct{ source, revision, want string }{
+		{"https://github.com/acme/shop", "4f2a9c1", "https://github.com/acme/shop/commit/4f2a9c1"},
+		{"https://gitlab.corp/team/api", "4f2a9c1", "https://gitlab.corp/team/api/-/commit/4f2a9c1"},
+		{"https://bitbucket.org/acme/shop", "4f2a9c1", "https://bitbucket.org/acme/shop/commits/4f2a9c1"},
+		{"https://github.com/acme/shop", "", ""},
+		{"https://github.com/acme/shop", "refs/heads/main", ""},
+		{"not a url", "4f2a9c1", ""},
+	} {
+		if got := commitURL(tc.source, tc.revision); got != tc.want {
+			t.Errorf("commitURL(%q, %q) = %q, want %q", tc.source, tc.revision, got, tc.want)
+		}
+	}
+}
+
+func TestReportProvenance(t *testing.T) {
+	report := Report{Data: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				ociSourceAnnotation:   "https://github.com/acme/ignored",
+				ociBaseNameAnnotation: "docker.io/library/alpine:3.20",
+			},
+		},
+		"report": map[string]interface{}{
+			"artifact": map[string]interface{}{
+				"repository": "acme/shop",
+				"labels": map[string]interface{}{
+					ociSourceAnnotation:   "git@github.com:acme/shop.git",
+					ociRevisionAnnotation: "4f2a9c1",
+				},
+			},
+		},
+	}}
+	p := reportProvenance(report)
+	want := ImageProvenance{
+		Source:    "https://github.com/acme/shop",
+		Revision:  "4f2a9c1",
+		CommitURL: "https://github.com/acme/shop/commit/4f2a9c1",
+		BaseImage: "docker.io/library/alpine:3.20",
+	}
+	if p == nil || *p != want {
+		t.Fatalf("provenance = %+v, want %+v", p, want)
+	}
+
+	templates := parseLinkTemplates(map[string]string{"commit": "{{commitUrl}}"})
+	if got := reportsWithLinks(templates, []Report{report})[0].Links["commit"]; got != want.CommitURL {
+		t.Fatalf("commit link = %q", got)
+	}
+
+	if reportProvenance(Report{Data: map[string]interface{}{"report": map[string]interface{}{}}}) != nil {
+		t.Fatal("expected no provenance without annotations")
+	}
+}
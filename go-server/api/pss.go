@@ -0,0 +1,156 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"trivy-ui/kubernetes"
+	"trivy-ui/pss"
+)
+
+type PSSViolation struct {
+	pss.Control
+	// Resources are the reports whose resource fails the check
+	Resources []string `json:"resources"`
+}
+
+type NamespacePSS struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	// Level is the most restrictive Pod Security Standard the namespace's workloads meet
+	Level      string         `json:"level"`
+	Violations []PSSViolation `json:"violations"`
+}
+
+type PSSSummary struct {
+	Namespaces int `json:"namespaces"`
+	Restricted int `json:"restricted"`
+	Baseline   int `json:"baseline"`
+	Privileged int `json:"privileged"`
+}
+
+type PSSRollup struct {
+	// Level is the standard the listed namespaces violate
+	Level      string         `json:"level"`
+	Namespaces []NamespacePSS `json:"namespaces"`
+	Summary    PSSSummary     `json:"summary"`
+}
+
+// GetPSS handles GET /api/v1/pss.
+func (h *Handler) GetPSS(w http.ResponseWriter, r *http.Request) {
+	clusterFilter, namespaceFilters, _, _ := h.parseQueryParams(r)
+	level := r.URL.Query().Get("level")
+	if level == "" {
+		level = pss.LevelRestricted
+	}
+	if level != pss.LevelBaseline && level != pss.LevelRestricted {
+		writeError(w, http.StatusBadRequest, "level must be baseline or restricted")
+		return
+	}
+
+	params := strings.Join([]string{clusterFilter, strings.Join(namespaceFilters, ","), level}, "|")
+	result := aggregates.getOrCompute("pss", aggregateScope(clusterFilter), params, func() interface{} {
+		return h.computePSS(clusterFilter, namespaceFilters, level)
+	})
+	writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: result})
+}
+
+// GetPSSControls handles GET /api/v1/pss/controls.
+func (h *Handler) GetPSSControls(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: pss.Controls()})
+}
+
+// computePSS rates every namespace with config audit results against the Pod Security
+// Standards and lists those violating level.
+func (h *Handler) computePSS(clusterFilter string, namespaceFilters []string, level string) PSSRollup {
+	type namespaceAggregate struct {
+		ns         NamespacePSS
+		failed     []string
+		violations map[string]*PSSViolation
+	}
+	byNamespace := make(map[string]*namespaceAggregate)
+
+	for _, kind := range h.crdReg.GetAllReports() {
+		for _, report := range h.cache.GetReports(kind.Name, clusterFilter, namespaceFilters) {
+			if report.Namespace == "" {
+				continue
+			}
+			data, ok := report.Data.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			failed := kubernetes.FailedCheckIDs(data)
+			if failed == nil {
+				continue
+			}
+			key := report.Cluster + "/" + report.Namespace
+			agg, ok := byNamespace[key]
+			if !ok {
+				agg = &namespaceAggregate{
+					ns:         NamespacePSS{Cluster: report.Cluster, Namespace: report.Namespace},
+					violations: make(map[string]*PSSViolation),
+				}
+				byNamespace[key] = agg
+			}
+			for _, id := range failed {
+				control, ok := pss.Lookup(id)
+				if !ok {
+					continue
+				}
+				agg.failed = append(agg.failed, id)
+				v, ok := agg.violations[id]
+				if !ok {
+					v = &PSSViolation{Control: control}
+					agg.violations[id] = v
+				}
+				v.Resources = append(v.Resources, report.Name)
+			}
+		}
+	}
+
+	result := PSSRollup{Level: level, Namespaces: []NamespacePSS{}}
+	for _, agg := range byNamespace {
+		agg.ns.Level = pss.Level(agg.failed)
+		result.Summary.Namespaces++
+		switch agg.ns.Level {
+		case pss.LevelRestricted:
+			result.Summary.Restricted++
+			continue
+		case pss.LevelBaseline:
+			result.Summary.Baseline++
+			if level == pss.LevelBaseline {
+				continue
+			}
+		case pss.LevelPrivileged:
+			result.Summary.Privileged++
+		}
+		agg.ns.Violations = make([]PSSViolation, 0, len(agg.violations))
+		for _, v := range agg.violations {
+			if level == pss.LevelBaseline && v.Level != pss.LevelBaseline {
+				continue
+			}
+			sort.Strings(v.Resources)
+			agg.ns.Violations = append(agg.ns.Violations, *v)
+		}
+		sort.Slice(agg.ns.Violations, func(i, j int) bool {
+			a, b := agg.ns.Violations[i], agg.ns.Violations[j]
+			if a.Level != b.Level {
+				return a.Level == pss.LevelBaseline
+			}
+			return a.CheckID < b.CheckID
+		})
+		result.Namespaces = append(result.Namespaces, agg.ns)
+	}
+	sort.Slice(result.Namespaces, func(i, j int) bool {
+		a, b := result.Namespaces[i], result.Namespaces[j]
+		if a.Level != b.Level {
+			return a.Level == pss.LevelPrivileged
+		}
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		return a.Namespace < b.Namespace
+	})
+	return result
+}
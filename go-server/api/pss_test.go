@@ -0,0 +1,46 @@
+package api
+
+import (
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/pss"
+)
+
+func auditReport(name, cluster, ns string, failed ...string) Report {
+	checks := make([]interface{}, len(failed))
+	for i, id := range failed {
+		checks[i] = id
+	}
+	return Report{Name: name, Cluster: cluster, Namespace: ns, Type: "configauditreports", Data: map[string]interface{}{
+		"report": map[string]interface{}{"failedChecks": checks},
+	}}
+}
+
+func TestComputePSS(t *testing.T) {
+	config.GetGlobalRegistry().Register(config.ReportKind{Name: "configauditreports", Namespaced: true})
+	cache := &stubCacheService{reports: map[string][]Report{"configauditreports": {
+		auditReport("deploy-api", "c1", "apps", "KSV001", "KSV999"),
+		auditReport("daemonset-agent", "c1", "monitoring", "KSV017", "KSV001"),
+		auditReport("deploy-clean", "c1", "secure"),
+		auditReport("deploy-web", "c1", "apps", "KSV001"),
+	}}}
+	h := &Handler{cache: cache, crdReg: config.GetGlobalRegistry()}
+
+	rollup := h.computePSS("", nil, pss.LevelRestricted)
+	if rollup.Summary != (PSSSummary{Namespaces: 3, Restricted: 1, Baseline: 1, Privileged: 1}) {
+		t.Fatalf("unexpected summary %+v", rollup.Summary)
+	}
+	if len(rollup.Namespaces) != 2 || rollup.Namespaces[0].Namespace != "monitoring" || rollup.Namespaces[0].Level != pss.LevelPrivileged {
+		t.Fatalf("expected privileged namespaces first, got %+v", rollup.Namespaces)
+	}
+	apps := rollup.Namespaces[1]
+	if len(apps.Violations) != 1 || apps.Violations[0].CheckID != "KSV001" || len(apps.Violations[0].Resources) != 2 {
+		t.Fatalf("unexpected violations %+v", apps.Violations)
+	}
+
+	rollup = h.computePSS("", nil, pss.LevelBaseline)
+	if len(rollup.Namespaces) != 1 || len(rollup.Namespaces[0].Violations) != 1 || rollup.Namespaces[0].Violations[0].CheckID != "KSV017" {
+		t.Fatalf("baseline view should only list baseline violations, got %+v", rollup.Namespaces)
+	}
+}
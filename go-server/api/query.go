@@ -2,8 +2,12 @@ package api
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"trivy-ui/filter"
 )
 
 type ReportQuery struct {
@@ -12,8 +16,17 @@ type ReportQuery struct {
 	Namespaces     []string
 	Search         string
 	OnlyVulnerable bool
-	Page           int
-	PageSize       int
+	Sort           string
+	Filter         string
+	FilterExpr     filter.Expr
+	// IgnoreUnfixable counts only findings with a fixed version in summaries and statuses
+	IgnoreUnfixable bool
+	// RunningOnly drops reports of workloads without ready pods, see reportRunning
+	RunningOnly bool
+	// Containers keeps the reports of these containers, see reportContainer
+	Containers []string
+	Page       int
+	PageSize   int
 }
 
 type QueryResult struct {
@@ -45,6 +58,9 @@ func (s *queryServiceImpl) ListReports(q ReportQuery) QueryResult {
 	}
 
 	allReports := s.cache.GetReports(q.Type, q.Cluster, q.Namespaces)
+	if q.IgnoreUnfixable {
+		allReports = reportsWithoutUnfixable(allReports)
+	}
 	if len(allReports) == 0 {
 		result := QueryResult{Items: []Report{}}
 		queryResultCache.Store(cacheKey, result)
@@ -52,19 +68,11 @@ func (s *queryServiceImpl) ListReports(q ReportQuery) QueryResult {
 	}
 
 	hasSearch := q.Search != ""
-	if !hasSearch && !q.OnlyVulnerable {
-		total := len(allReports)
-		withVuln := 0
-		for _, r := range allReports {
-			if hasVulnerabilitiesInReport(r) {
-				withVuln++
-			}
-		}
-
+	if !hasSearch && !q.OnlyVulnerable && q.FilterExpr == nil && !q.RunningOnly && len(q.Containers) == 0 {
 		result := QueryResult{
-			Total:               total,
-			WithVulnerabilities: withVuln,
-			Items:               paginateReports(allReports, q.Page, q.PageSize),
+			Total:               len(allReports),
+			WithVulnerabilities: s.countVulnerable(q, allReports),
+			Items:               paginateReports(sortReports(allReports, q.Sort), q.Page, q.PageSize),
 		}
 		queryResultCache.Store(cacheKey, result)
 		return result
@@ -85,6 +93,18 @@ func (s *queryServiceImpl) ListReports(q ReportQuery) QueryResult {
 			continue
 		}
 
+		if q.FilterExpr != nil && !q.FilterExpr.Eval(newReportRecord(r)) {
+			continue
+		}
+
+		if q.RunningOnly && !reportRunning(r) {
+			continue
+		}
+
+		if len(q.Containers) > 0 && !matchesContainers(r, q.Containers) {
+			continue
+		}
+
 		filtered = append(filtered, r)
 		if hasVuln {
 			withVulnerabilities++
@@ -94,25 +114,123 @@ func (s *queryServiceImpl) ListReports(q ReportQuery) QueryResult {
 	result := QueryResult{
 		Total:               len(filtered),
 		WithVulnerabilities: withVulnerabilities,
-		Items:               paginateReports(filtered, q.Page, q.PageSize),
+		Items:               paginateReports(sortReports(filtered, q.Sort), q.Page, q.PageSize),
 	}
 	queryResultCache.Store(cacheKey, result)
 	return result
 }
 
+// countVulnerable counts the reports with findings from the cache's rollups, scanning the
+// reports only when the rollups count others, e.g. while a write is in flight, or when
+// unfixable findings are ignored.
+func (s *queryServiceImpl) countVulnerable(q ReportQuery, reports []Report) int {
+	if !q.IgnoreUnfixable {
+		if rollup := s.cache.GetReportRollup(q.Type, q.Cluster, q.Namespaces); rollup.Reports == len(reports) {
+			return rollup.WithVulnerabilities
+		}
+	}
+	withVuln := 0
+	for _, r := range reports {
+		if hasVulnerabilitiesInReport(r) {
+			withVuln++
+		}
+	}
+	return withVuln
+}
+
 func queryResultCacheKey(q ReportQuery, version uint64) string {
-	return fmt.Sprintf("%s|%s|%s|%s|%t|%d|%d|%d",
+	// runningOnly results change with the workloads, not only with the reports
+	var states uint64
+	if q.RunningOnly {
+		states = workloadStatesGeneration.Load()
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%t|%s|%s|%t|%t|%s|%d|%d|%d|%d",
 		q.Type,
 		q.Cluster,
 		strings.Join(q.Namespaces, ","),
 		strings.ToLower(q.Search),
 		q.OnlyVulnerable,
+		q.Sort,
+		q.Filter,
+		q.IgnoreUnfixable,
+		q.RunningOnly,
+		strings.Join(q.Containers, ","),
 		q.Page,
 		q.PageSize,
 		version,
+		states,
 	)
 }
 
+// IsValidReportSort accepts "scannedAt" or "cachedAt", optionally prefixed with "-" for
+// descending order; empty keeps the default cluster/namespace/name order.
+func IsValidReportSort(sortBy string) bool {
+	switch strings.TrimPrefix(sortBy, "-") {
+	case "", "scannedAt", "cachedAt", "effectiveSeverity", "exposure":
+		return true
+	}
+	return false
+}
+
+// sortReports returns a sorted copy; the input may be a slice shared through the cache.
+// Reports without a scan time sort last in both directions.
+func sortReports(reports []Report, sortBy string) []Report {
+	field := strings.TrimPrefix(sortBy, "-")
+	if field == "" || len(reports) < 2 {
+		return reports
+	}
+	desc := strings.HasPrefix(sortBy, "-")
+
+	sorted := make([]Report, len(reports))
+	copy(sorted, reports)
+	if field == "effectiveSeverity" {
+		ranks := make(map[string]int, len(sorted))
+		for _, r := range sorted {
+			ranks[reportKey(r.Cluster, r.Namespace, r.Type, r.Name)] = severityRank[newReportRecord(r).effectiveSeverity()]
+		}
+		rankOf := func(r Report) int { return ranks[reportKey(r.Cluster, r.Namespace, r.Type, r.Name)] }
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if desc {
+				return rankOf(sorted[i]) > rankOf(sorted[j])
+			}
+			return rankOf(sorted[i]) < rankOf(sorted[j])
+		})
+		return sorted
+	}
+	if field == "exposure" {
+		ranks := make(map[string]float64, len(sorted))
+		for _, r := range sorted {
+			ranks[reportKey(r.Cluster, r.Namespace, r.Type, r.Name)] = exposureRank(r)
+		}
+		rankOf := func(r Report) float64 { return ranks[reportKey(r.Cluster, r.Namespace, r.Type, r.Name)] }
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if desc {
+				return rankOf(sorted[i]) > rankOf(sorted[j])
+			}
+			return rankOf(sorted[i]) < rankOf(sorted[j])
+		})
+		return sorted
+	}
+
+	timeOf := func(r Report) time.Time {
+		if field == "cachedAt" {
+			return r.CachedAt
+		}
+		return r.ScannedAt
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := timeOf(sorted[i]), timeOf(sorted[j])
+		if ti.IsZero() != tj.IsZero() {
+			return tj.IsZero()
+		}
+		if desc {
+			return ti.After(tj)
+		}
+		return ti.Before(tj)
+	})
+	return sorted
+}
+
 func paginateReports(reports []Report, page, pageSize int) []Report {
 	total := len(reports)
 	if total == 0 {
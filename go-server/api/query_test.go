@@ -14,11 +14,14 @@ type stubCacheService struct {
 func (s *stubCacheService) Get(key string) (interface{}, bool)        { return nil, false }
 func (s *stubCacheService) Items() map[string]interface{}             { return nil }
 func (s *stubCacheService) ItemsByType(t string) map[string]interface{} { return nil }
+func (s *stubCacheService) FindReportKeys(_, _, _, _ string) []string { return nil }
 func (s *stubCacheService) Set(key string, value interface{}, _ time.Duration) {}
 func (s *stubCacheService) Delete(key string)                         {}
 func (s *stubCacheService) DeleteReportEntry(_, _, _, _ string)       {}
 func (s *stubCacheService) GetReportCount(_, _ string) (int, int)     { return 0, 0 }
-func (s *stubCacheService) GetOverviewData(_ string) *ClusterOverview { return nil }
+func (s *stubCacheService) GetReportRollup(_, _ string, _ []string) ReportRollup { return ReportRollup{} }
+func (s *stubCacheService) GetReportRollups(_ string) []NamespaceRollup       { return nil }
+func (s *stubCacheService) GetOverviewData(_ string, _ bool, _ func(Report) bool) *ClusterOverview { return nil }
 func (s *stubCacheService) GetTrends(_ string, _ int) []TrendRecord   { return nil }
 func (s *stubCacheService) GetStats() map[string]interface{}          { return nil }
 func (s *stubCacheService) GetReports(typeName, clusterFilter string, namespaceFilters []string) []Report {
@@ -236,3 +239,76 @@ func TestQueryResultCacheKey_VersionDistinct(t *testing.T) {
 		t.Fatal("different versions should produce different cache keys")
 	}
 }
+
+func TestSortReports_ScannedAtMissingLast(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reports := []Report{
+		{Name: "unknown"},
+		{Name: "old", ScannedAt: base},
+		{Name: "new", ScannedAt: base.Add(time.Hour)},
+	}
+
+	desc := sortReports(reports, "-scannedAt")
+	if desc[0].Name != "new" || desc[1].Name != "old" || desc[2].Name != "unknown" {
+		t.Fatalf("unexpected order %v", []string{desc[0].Name, desc[1].Name, desc[2].Name})
+	}
+	asc := sortReports(reports, "scannedAt")
+	if asc[0].Name != "old" || asc[2].Name != "unknown" {
+		t.Fatalf("unexpected order %v", []string{asc[0].Name, asc[1].Name, asc[2].Name})
+	}
+	if reports[0].Name != "unknown" {
+		t.Fatal("input slice must not be reordered")
+	}
+}
+
+func TestIsValidReportSort(t *testing.T) {
+	for _, v := range []string{"", "scannedAt", "-scannedAt", "-cachedAt"} {
+		if !IsValidReportSort(v) {
+			t.Fatalf("expected %q to be valid", v)
+		}
+	}
+	if IsValidReportSort("name") {
+		t.Fatal("expected name to be rejected")
+	}
+}
+
+func TestListReports_FilterExpression(t *testing.T) {
+	fixable := makeReport("fixable", "c", "prod-payments", "vuln-filter", 2)
+	fixable.Fixable = 1
+	reports := []Report{
+		fixable,
+		makeReport("no-fix", "c", "prod-payments", "vuln-filter", 2),
+		makeReport("clean", "c", "prod-payments", "vuln-filter", 0),
+		makeReport("staging", "c", "staging", "vuln-filter", 4),
+	}
+	expr, err := parseReportFilter(`severity in (CRITICAL,HIGH) and namespace startsWith "prod-" and fixAvailable=true`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	svc := newQuerySvc(reports, "vuln-filter")
+	result := svc.ListReports(ReportQuery{Type: "vuln-filter", Filter: "f", FilterExpr: expr, Page: 1, PageSize: 50})
+	if result.Total != 1 || result.Items[0].Name != "fixable" {
+		t.Fatalf("expected only fixable, got %+v", result.Items)
+	}
+}
+
+func TestNewPaginatedResponse(t *testing.T) {
+	q := ReportQuery{Cluster: "c", Namespaces: []string{"web"}, Sort: "-scannedAt", OnlyVulnerable: true, Page: 2, PageSize: 50}
+	page := newPaginatedResponse(q, QueryResult{Total: 120}, nil)
+	if page.TotalPages != 3 || !page.HasNext || !page.HasPrev {
+		t.Fatalf("unexpected paging %+v", page)
+	}
+	if page.Sort != "-scannedAt" || page.Filters.Cluster != "c" || !page.Filters.OnlyVulnerable || len(page.Filters.Namespaces) != 1 {
+		t.Fatalf("unexpected echo %+v", page)
+	}
+
+	q.Page = 3
+	if page := newPaginatedResponse(q, QueryResult{Total: 120}, nil); page.HasNext {
+		t.Fatal("last page has a next page")
+	}
+	q.Page = 1
+	if page := newPaginatedResponse(q, QueryResult{}, nil); page.TotalPages != 0 || page.HasNext || page.HasPrev {
+		t.Fatalf("unexpected paging of an empty list %+v", page)
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/utils"
+)
+
+// rawProxyTimeout bounds a request of the raw API proxy
+const rawProxyTimeout = 30 * time.Second
+
+// rawProxyPath cleans the API path of a raw proxy request and reports whether it belongs
+// to the Trivy group, the only one the proxy serves.
+func rawProxyPath(apiPath string) (string, bool) {
+	cleaned := path.Clean("/" + apiPath)
+	group := "/apis/" + config.TrivyGroup
+	return cleaned, cleaned == group || strings.HasPrefix(cleaned, group+"/")
+}
+
+// ProxyRawAPI forwards a GET to the API server of a cluster with trivy-ui's credentials,
+// so label and field selectors can be run against live reports without a kubeconfig.
+// Only paths of the Trivy group are served, and only with AUTH_MODE=mixed on top of the
+// admin API's token; the API server's status and body are returned as they are.
+func (h *Handler) ProxyRawAPI(w http.ResponseWriter, r *http.Request, cluster, apiPath string) {
+	if config.Get().AuthMode != config.AuthModeMixed {
+		writeError(w, http.StatusForbidden, "The raw API proxy needs AUTH_MODE=mixed")
+		return
+	}
+	target, ok := rawProxyPath(apiPath)
+	if !ok {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("Only paths under /apis/%s can be proxied", config.TrivyGroup))
+		return
+	}
+	query := r.URL.Query()
+	if watch, _ := strconv.ParseBool(query.Get("watch")); watch {
+		writeError(w, http.StatusBadRequest, "Watch requests are not supported")
+		return
+	}
+
+	cc := h.clusterReg.Get(cluster)
+	if cc == nil {
+		writeError(w, http.StatusNotFound, "Cluster not found")
+		return
+	}
+	if cc.Client == nil {
+		writeError(w, http.StatusBadRequest, "Cluster has no API server connection")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), rawProxyTimeout)
+	defer cancel()
+	resp, err := cc.Client.GetRaw(ctx, target, query)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Sprintf("Failed to reach the API server: %v", err))
+		return
+	}
+	utils.LogInfo("Raw API request", map[string]interface{}{
+		"cluster": cc.Name,
+		"path":    target,
+		"query":   query.Encode(),
+		"status":  resp.StatusCode,
+	})
+
+	contentType := resp.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(resp.StatusCode)
+	w.Write(resp.Body)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+func TestProxyRawAPI(t *testing.T) {
+	var requested string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/apis/aquasecurity.github.io/v1alpha1/namespaces/missing/vulnerabilityreports" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","code":404}`)
+			return
+		}
+		fmt.Fprint(w, `{"kind":"VulnerabilityReportList","items":[]}`)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := kubernetes.NewClientFromKubeconfig([]byte(testKubeconfig(srv.URL)), kubernetes.DefaultClientConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := NewClusterRegistry(&stubCacheService{})
+	reg.clients["prod"] = &ClusterClient{Name: "prod", Client: client}
+	reg.RegisterPushed("edge", "v1.30.0", nil)
+	h := &Handler{clusterReg: reg}
+
+	cfg := config.Get()
+	prevMode := cfg.AuthMode
+	t.Cleanup(func() { cfg.AuthMode = prevMode })
+	proxy := func(cluster, apiPath, query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ProxyRawAPI(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/raw/"+cluster+"/"+apiPath+"?"+query, nil), cluster, apiPath)
+		return rec
+	}
+
+	cfg.AuthMode = config.AuthModeNone
+	if rec := proxy("prod", "apis/aquasecurity.github.io/v1alpha1/vulnerabilityreports", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected the proxy to be off without authentication, got %d", rec.Code)
+	}
+
+	cfg.AuthMode = config.AuthModeMixed
+	rec := proxy("prod", "apis/aquasecurity.github.io/v1alpha1/vulnerabilityreports", "labelSelector=app%3Dweb&fieldSelector=metadata.namespace%3Ddefault")
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"kind":"VulnerabilityReportList","items":[]}` {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body)
+	}
+	if requested != "/apis/aquasecurity.github.io/v1alpha1/vulnerabilityreports?fieldSelector=metadata.namespace%3Ddefault&labelSelector=app%3Dweb" {
+		t.Errorf("selectors not forwarded: %s", requested)
+	}
+	if rec := proxy("prod", "apis/aquasecurity.github.io/v1alpha1/namespaces/missing/vulnerabilityreports", ""); rec.Code != http.StatusNotFound || rec.Body.String() != `{"kind":"Status","code":404}` {
+		t.Errorf("expected the API server's error passed on, got %d: %s", rec.Code, rec.Body)
+	}
+
+	for _, tc := range []struct {
+		cluster, apiPath, query string
+		code                    int
+	}{
+		{"prod", "api/v1/secrets", "", http.StatusForbidden},
+		{"prod", "apis/aquasecurity.github.io/../../api/v1/secrets", "", http.StatusForbidden},
+		{"prod", "apis/aquasecurity.github.io.evil/v1", "", http.StatusForbidden},
+		{"prod", "apis/aquasecurity.github.io/v1alpha1/vulnerabilityreports", "watch=true", http.StatusBadRequest},
+		{"unknown", "apis/aquasecurity.github.io/v1alpha1/vulnerabilityreports", "", http.StatusNotFound},
+		{"edge", "apis/aquasecurity.github.io/v1alpha1/vulnerabilityreports", "", http.StatusBadRequest},
+	} {
+		requested = ""
+		if rec := proxy(tc.cluster, tc.apiPath, tc.query); rec.Code != tc.code || requested != "" {
+			t.Errorf("%s %s?%s: expected %d without reaching the API server, got %d (%s)", tc.cluster, tc.apiPath, tc.query, tc.code, rec.Code, requested)
+		}
+	}
+}
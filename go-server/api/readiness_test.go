@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/store"
+)
+
+func TestIngestReadiness(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{IngestMode: config.IngestModeFile}
+	if err := ingestReadiness(ctx, cfg, nil); err == nil {
+		t.Fatal("expected not ready without a storage backend")
+	}
+
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if err := ingestReadiness(ctx, cfg, st); err != nil {
+		t.Fatalf("file ingest should be ready with zero clusters: %v", err)
+	}
+
+	cfg.IngestMode = config.IngestModeAPI
+	if err := ingestReadiness(ctx, cfg, st); err == nil {
+		t.Fatal("expected API ingest without agent credentials to be not ready")
+	}
+	cfg.AgentTokens = map[string]string{"edge-1": "token"}
+	if err := ingestReadiness(ctx, cfg, st); err != nil {
+		t.Fatalf("API ingest with agent tokens should be ready: %v", err)
+	}
+}
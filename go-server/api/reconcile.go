@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"trivy-ui/kubernetes"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// reconcileLogSample bounds how many drifted keys are logged per cluster and run.
+const reconcileLogSample = 10
+
+// StartReconciler periodically compares every cluster's informer stores with the cache
+// and the store, repairing missing, orphaned and stale reports. Repairs are limited to
+// repairsPerSecond so a large drift cannot flood the cache or the database.
+func StartReconciler(ctx context.Context, reg *ClusterRegistry, cache CacheService, interval time.Duration, repairsPerSecond float64) {
+	if interval <= 0 {
+		return
+	}
+	limiter := rate.NewLimiter(rate.Limit(repairsPerSecond), 1)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			reconcileAll(ctx, reg, cache, limiter)
+		}
+	}()
+}
+
+func reconcileAll(ctx context.Context, reg *ClusterRegistry, cache CacheService, limiter *rate.Limiter) {
+	cached := cachedReportIDs(cache)
+	inCache := func(cluster string) func(kubernetes.ReportID) (kubernetes.CachedReport, bool) {
+		return func(id kubernetes.ReportID) (kubernetes.CachedReport, bool) {
+			value, ok := cache.Get(reportKey(cluster, id.Namespace, id.Type, id.Name))
+			if !ok {
+				return kubernetes.CachedReport{}, false
+			}
+			report, _ := convertCacheValue[Report](value)
+			return kubernetes.CachedReport{ResourceVersion: report.ResourceVersion}, true
+		}
+	}
+
+	for name, cc := range reg.All() {
+		if cc.Client == nil {
+			continue
+		}
+		informer := cc.Client.GetInformer()
+		if informer == nil {
+			continue
+		}
+
+		result, err := informer.Reconcile(ctx, cached[name], inCache(name), limiter.Wait)
+		if err != nil {
+			return
+		}
+		resolved := resolveOrphanedFindings(ctx, name, informer)
+
+		if len(result.Missing) == 0 && len(result.Orphaned) == 0 && len(result.Stale) == 0 && resolved == 0 {
+			utils.LogDebug("Reconcile found no drift", map[string]interface{}{"cluster": name})
+			continue
+		}
+		utils.LogWarning("Reconcile repaired cache drift", map[string]interface{}{
+			"cluster":          name,
+			"missing":          len(result.Missing),
+			"orphaned":         len(result.Orphaned),
+			"stale":            len(result.Stale),
+			"resolvedFindings": resolved,
+			"missingSample":    sampleReportIDs(result.Missing),
+			"orphanedSample":   sampleReportIDs(result.Orphaned),
+			"staleSample":      sampleReportIDs(result.Stale),
+		})
+	}
+}
+
+// cachedReportIDs groups the cached report keys by cluster.
+func cachedReportIDs(cache CacheService) map[string][]kubernetes.ReportID {
+	result := make(map[string][]kubernetes.ReportID)
+	for key := range cache.Items() {
+		cluster, namespace, reportType, name, ok := parseReportCacheKey(key)
+		if !ok {
+			continue
+		}
+		result[cluster] = append(result[cluster], kubernetes.ReportID{Type: reportType, Namespace: namespace, Name: name})
+	}
+	return result
+}
+
+// resolveOrphanedFindings resolves the open findings of reports that no longer exist,
+// e.g. deleted while trivy-ui was down, so they stop counting against the SLA.
+func resolveOrphanedFindings(ctx context.Context, cluster string, informer *kubernetes.ReportInformerManager) int {
+	st := store.Get()
+	if st == nil {
+		return 0
+	}
+	listCtx, cancel := storeCallContext(ctx)
+	refs, err := st.OpenFindingReports(listCtx, cluster)
+	cancel()
+	if err != nil {
+		utils.LogWarning("Failed to load open findings for reconcile", map[string]interface{}{"cluster": cluster, "error": err.Error()})
+		return 0
+	}
+	resolved := 0
+	now := time.Now()
+	for _, ref := range refs {
+		id := kubernetes.ReportID{Type: ref.ReportType, Namespace: ref.Namespace, Name: ref.ReportName}
+		if held, known := informer.Holds(id); held || !known {
+			continue
+		}
+		syncCtx, cancel := storeCallContext(ctx)
+		err := st.SyncFindings(syncCtx, ref, nil, now)
+		cancel()
+		if err != nil {
+			utils.LogWarning("Failed to resolve orphaned findings", map[string]interface{}{"cluster": cluster, "report": ref.ReportName, "error": err.Error()})
+			continue
+		}
+		resolved++
+	}
+	return resolved
+}
+
+func sampleReportIDs(ids []kubernetes.ReportID) []string {
+	n := len(ids)
+	if n > reconcileLogSample {
+		n = reconcileLogSample
+	}
+	sample := make([]string, 0, n)
+	for _, id := range ids[:n] {
+		sample = append(sample, id.Type+"/"+id.Namespace+"/"+id.Name)
+	}
+	return sample
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/cvss"
+	"trivy-ui/signatures"
+	"trivy-ui/utils"
+)
+
+var reloadMu sync.Mutex
+
+// ReloadResult is the outcome of re-reading the configuration files. A reload applies all
+// of them or, when one is invalid, none, so Errors and Reloaded are never both set.
+type ReloadResult struct {
+	Reloaded []string `json:"reloaded"`
+	// Errors maps the environment variable naming an invalid file to why it was rejected
+	Errors map[string]string `json:"errors,omitempty"`
+	Time   time.Time         `json:"time"`
+}
+
+// reloadConfig re-reads SEVERITY_RULES_FILE, NOTIFICATION_ROUTES_FILE,
+// SIGNATURE_POLICY_FILE and UI_CONFIG_FILE.
+// Settings from environment variables, the listener among them, need a restart; informers
+// and the cache are left alone, and reports are re-rated with new severity rules when they
+// are next updated.
+func reloadConfig() ReloadResult {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cfg := config.Get()
+	result := ReloadResult{Reloaded: []string{}, Errors: map[string]string{}, Time: time.Now()}
+	var apply []func()
+
+	if path := cfg.SeverityRulesFile; path != "" {
+		if rules, err := cvss.LoadRules(path); err != nil {
+			result.Errors["SEVERITY_RULES_FILE"] = err.Error()
+		} else {
+			apply = append(apply, func() { setSeverityRules(rules) })
+			result.Reloaded = append(result.Reloaded, "SEVERITY_RULES_FILE")
+		}
+	}
+	if path := cfg.NotificationRoutesFile; path != "" {
+		if routes, err := loadNotificationRoutes(path); err != nil {
+			result.Errors["NOTIFICATION_ROUTES_FILE"] = err.Error()
+		} else {
+			apply = append(apply, func() { setNotificationRoutes(routes, nil) })
+			result.Reloaded = append(result.Reloaded, "NOTIFICATION_ROUTES_FILE")
+		}
+	}
+	if path := cfg.SignaturePolicyFile; path != "" {
+		if policy, err := signatures.LoadPolicy(path); err != nil {
+			result.Errors["SIGNATURE_POLICY_FILE"] = err.Error()
+		} else {
+			apply = append(apply, func() { setSignaturePolicy(policy) })
+			result.Reloaded = append(result.Reloaded, "SIGNATURE_POLICY_FILE")
+		}
+	}
+	if path := cfg.UIConfigFile; path != "" {
+		info, err := os.Stat(path)
+		var ui UIConfig
+		if err == nil {
+			var data []byte
+			if data, err = os.ReadFile(path); err == nil {
+				ui, err = parseUIConfig(data)
+			}
+		}
+		if err != nil {
+			result.Errors["UI_CONFIG_FILE"] = err.Error()
+		} else {
+			apply = append(apply, func() {
+				uiConfigFile.mu.Lock()
+				uiConfigFile.modTime, uiConfigFile.config = info.ModTime(), ui
+				uiConfigFile.mu.Unlock()
+			})
+			result.Reloaded = append(result.Reloaded, "UI_CONFIG_FILE")
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		result.Reloaded = []string{}
+		invalid := make([]string, 0, len(result.Errors))
+		for name := range result.Errors {
+			invalid = append(invalid, name)
+		}
+		sort.Strings(invalid)
+		utils.LogWarning("Config reload rejected, keeping the current configuration", map[string]interface{}{"invalid": invalid})
+		return result
+	}
+	result.Errors = nil
+	for _, fn := range apply {
+		fn()
+	}
+	utils.LogInfo("Config reloaded", map[string]interface{}{"files": result.Reloaded})
+	return result
+}
+
+// StartReloadOnSignal reloads the configuration files on SIGHUP until ctx is done.
+func StartReloadOnSignal(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				reloadConfig()
+			}
+		}
+	}()
+}
+
+// ReloadConfig handles POST /api/v1/admin/reload: it re-reads the configuration files
+// like SIGHUP and answers 422 with the errors when one is invalid and nothing was applied.
+func (h *Handler) ReloadConfig(w http.ResponseWriter, r *http.Request) {
+	result := reloadConfig()
+	if len(result.Errors) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, Response{
+			Code:    CodeError,
+			Message: "Invalid configuration, nothing was reloaded",
+			Data:    result,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    result,
+	})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"trivy-ui/config"
+)
+
+func TestReloadConfig(t *testing.T) {
+	cfg := config.Get()
+	prevRoutes, prevUI, prevRules := cfg.NotificationRoutesFile, cfg.UIConfigFile, cfg.SeverityRulesFile
+	t.Cleanup(func() {
+		cfg.NotificationRoutesFile, cfg.UIConfigFile, cfg.SeverityRulesFile = prevRoutes, prevUI, prevRules
+	})
+	oldRoutes, oldErr := loadedNotificationRoutes()
+	t.Cleanup(func() { setNotificationRoutes(oldRoutes, oldErr) })
+
+	dir := t.TempDir()
+	cfg.NotificationRoutesFile = filepath.Join(dir, "routes.yaml")
+	cfg.UIConfigFile = filepath.Join(dir, "ui.yaml")
+	cfg.SeverityRulesFile = ""
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("routes.yaml", "routes: [{name: payments, namespaceSelector: team=payments, destination: webhook, target: https://alerts.example.com/payments}]")
+	write("ui.yaml", "title: Acme Security")
+
+	h := &Handler{}
+	rec := httptest.NewRecorder()
+	h.ReloadConfig(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/reload", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reload: %d %s", rec.Code, rec.Body)
+	}
+	if routes := getNotificationRoutes(); len(routes.Routes) != 1 || routes.Routes[0].Name != "payments" {
+		t.Fatalf("routes not reloaded: %+v", routes)
+	}
+	if got := getUIConfig().Title; got != "Acme Security" {
+		t.Fatalf("title = %q", got)
+	}
+
+	// one invalid file rejects the whole reload
+	write("routes.yaml", "routes: [{name: payments, destination: webhook, target: not-a-url}]")
+	write("ui.yaml", "title: Globex Security")
+	result := reloadConfig()
+	if len(result.Reloaded) != 0 || result.Errors["NOTIFICATION_ROUTES_FILE"] == "" || len(result.Errors) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	if routes := getNotificationRoutes(); len(routes.Routes) != 1 {
+		t.Fatalf("routes should be kept: %+v", routes)
+	}
+	uiConfigFile.mu.Lock()
+	title := uiConfigFile.config.Title
+	uiConfigFile.mu.Unlock()
+	if title != "Acme Security" {
+		t.Fatalf("ui config applied from a rejected reload: %q", title)
+	}
+}
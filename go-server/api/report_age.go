@@ -0,0 +1,27 @@
+package api
+
+import (
+	"time"
+
+	"trivy-ui/kubernetes"
+)
+
+// withReportAge sets a report's creation time and its age at now. The timestamp is UTC so
+// clients format it in their own time zone.
+func withReportAge(r Report, now time.Time) Report {
+	if created := reportCreatedAt(r); !created.IsZero() {
+		r.CreatedAt = created.UTC()
+		r.Age = kubernetes.HumanizeAge(now.Sub(created))
+	}
+	return r
+}
+
+// withReportAges returns copies of the reports with their creation time and age set.
+// Items may be shared with the query cache, so they are never modified in place.
+func withReportAges(reports []Report, now time.Time) []Report {
+	result := make([]Report, len(reports))
+	for i, r := range reports {
+		result[i] = withReportAge(r, now)
+	}
+	return result
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithReportAges(t *testing.T) {
+	now := time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC)
+	created := Report{Name: "a", Data: map[string]interface{}{
+		"metadata": map[string]interface{}{"creationTimestamp": "2026-03-01T14:30:00+02:00"},
+	}}
+	unknown := Report{Name: "b", Data: map[string]interface{}{}}
+
+	reports := []Report{created, unknown}
+	result := withReportAges(reports, now)
+	if got := result[0]; got.Age != "1d2h" || !got.CreatedAt.Equal(time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)) || got.CreatedAt.Location() != time.UTC {
+		t.Fatalf("report with a creation time = %q %v", got.Age, got.CreatedAt)
+	}
+	if got := result[1]; got.Age != "" || !got.CreatedAt.IsZero() {
+		t.Fatalf("report without a creation time = %q %v", got.Age, got.CreatedAt)
+	}
+	if reports[0].Age != "" {
+		t.Fatal("the listed reports were modified")
+	}
+}
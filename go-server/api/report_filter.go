@@ -0,0 +1,133 @@
+package api
+
+import (
+	"trivy-ui/filter"
+)
+
+// reportFilterFields are the fields available to the filter= parameter of report lists.
+// effectiveSeverity is severity after SEVERITY_RULES_FILE recalibration.
+var reportFilterFields = filter.Fields{
+	"cluster":           filter.String,
+	"namespace":         filter.String,
+	"name":              filter.String,
+	"type":              filter.String,
+	"status":            filter.String,
+	"severity":          filter.String,
+	"effectiveSeverity": filter.String,
+	"repository":        filter.String,
+	"image":             filter.String,
+	"tag":               filter.String,
+	"container":         filter.String,
+	"workload":          filter.String,
+	"osFamily":          filter.String,
+	"osType":            filter.String,
+	"critical":          filter.Number,
+	"high":              filter.Number,
+	"medium":            filter.Number,
+	"low":               filter.Number,
+	"fixable":           filter.Number,
+	"fixAvailable":      filter.Bool,
+	"vulnerable":        filter.Bool,
+	"exposed":           filter.Bool,
+	"privileged":        filter.Bool,
+	"runAsRoot":         filter.Bool,
+	"hostNetwork":       filter.Bool,
+}
+
+func parseReportFilter(expr string) (filter.Expr, error) {
+	return filter.Parse(expr, reportFilterFields)
+}
+
+// reportRecord exposes a cached report summary to filter expressions.
+type reportRecord struct {
+	report                 Report
+	critical, high, medium int
+	low                    int
+}
+
+func newReportRecord(r Report) reportRecord {
+	c, h, m, l := extractSummaryCounts(r)
+	return reportRecord{report: r, critical: c, high: h, medium: m, low: l}
+}
+
+// highestSeverity returns the most severe level with a non-zero count, or NONE.
+func (rr reportRecord) highestSeverity() string {
+	switch {
+	case rr.critical > 0:
+		return "CRITICAL"
+	case rr.high > 0:
+		return "HIGH"
+	case rr.medium > 0:
+		return "MEDIUM"
+	case rr.low > 0:
+		return "LOW"
+	}
+	return "NONE"
+}
+
+// effectiveSeverity falls back to the original severity when no rules apply.
+func (rr reportRecord) effectiveSeverity() string {
+	if rr.report.EffectiveSeverity != "" {
+		return rr.report.EffectiveSeverity
+	}
+	return rr.highestSeverity()
+}
+
+func (rr reportRecord) Field(name string) interface{} {
+	switch name {
+	case "cluster":
+		return rr.report.Cluster
+	case "namespace":
+		return rr.report.Namespace
+	case "name":
+		return rr.report.Name
+	case "type":
+		return rr.report.Type
+	case "status":
+		return rr.report.Status
+	case "severity":
+		return rr.highestSeverity()
+	case "effectiveSeverity":
+		return rr.effectiveSeverity()
+	case "repository":
+		repo, _ := reportRepository(rr.report)
+		return repo
+	case "image":
+		return reportImageRef(rr.report)
+	case "tag":
+		return reportTag(rr.report)
+	case "container":
+		return reportContainer(rr.report)
+	case "workload":
+		return reportWorkload(rr.report)
+	case "osFamily":
+		_, family := reportOS(rr.report)
+		return family
+	case "osType":
+		osType, _ := reportOS(rr.report)
+		return osType
+	case "critical":
+		return float64(rr.critical)
+	case "high":
+		return float64(rr.high)
+	case "medium":
+		return float64(rr.medium)
+	case "low":
+		return float64(rr.low)
+	case "fixable":
+		return float64(rr.report.Fixable)
+	case "fixAvailable":
+		return rr.report.Fixable > 0
+	case "vulnerable":
+		return rr.critical+rr.high+rr.medium+rr.low > 0
+	case "exposed":
+		return rr.report.Exposure != nil && rr.report.Exposure.Exposed
+	case "privileged":
+		return rr.report.Exposure != nil && rr.report.Exposure.Privileged
+	case "runAsRoot":
+		return rr.report.Exposure != nil && rr.report.Exposure.RunAsRoot
+	case "hostNetwork":
+		return rr.report.Exposure != nil && rr.report.Exposure.HostNetwork
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"trivy-ui/config"
+	"trivy-ui/utils"
+)
+
+// ReportKindStatus is whether a report kind is watched, globally and on each cluster.
+type ReportKindStatus struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+	// Enabled is the global setting, which clusters without their own setting follow
+	Enabled  bool                               `json:"enabled"`
+	Clusters map[string]ReportKindClusterStatus `json:"clusters"`
+}
+
+// ReportKindClusterStatus is whether a cluster watches a report kind.
+type ReportKindClusterStatus struct {
+	Enabled bool `json:"enabled"`
+	// Override is set when the cluster has its own setting
+	Override bool `json:"override,omitempty"`
+	// Watching is whether an informer runs; pushed clusters have none
+	Watching bool `json:"watching"`
+	// Reports is how many reports of the kind are cached for the cluster
+	Reports int `json:"reports"`
+}
+
+// ReportKindUpdate enables or disables a report kind, on one cluster or, without a
+// cluster, globally. A null enabled removes a cluster's own setting.
+type ReportKindUpdate struct {
+	Kind    string `json:"kind"`
+	Cluster string `json:"cluster,omitempty"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// clusterReportKeys returns the keys of the cached reports of a type on one cluster.
+func (c *Cache) clusterReportKeys(cluster, reportType string) []string {
+	prefix := "report:" + cluster + ":"
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []string
+	for key := range c.typeIndex[reportType] {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// purgeReportKind drops the cached reports of a kind a cluster no longer watches. They
+// are not archived and their findings stay open: the reports were not deleted, and
+// enabling the kind again loads them back.
+func purgeReportKind(cluster, reportType string) int {
+	cache := getCache()
+	if cache == nil {
+		return 0
+	}
+	keys := cache.clusterReportKeys(cluster, reportType)
+	for _, key := range keys {
+		_, namespace, _, name, _ := parseReportCacheKey(key)
+		cache.deleteReportEntryByKey(key)
+		deleteReportBlob(cluster, namespace, reportType, name)
+		changeEvents.publish(ChangeEvent{
+			Type:       EventReportDeleted,
+			Cluster:    cluster,
+			Namespace:  namespace,
+			ReportType: reportType,
+			Name:       name,
+		})
+	}
+	return len(keys)
+}
+
+// applyReportKindToggles starts and stops the informers of a cluster after its settings
+// changed, dropping the reports of the kinds stopped.
+func applyReportKindToggles(cc *ClusterClient) {
+	if cc.Client == nil {
+		return
+	}
+	informer := cc.Client.GetInformer()
+	if informer == nil {
+		return
+	}
+	started, stopped := informer.ApplyKindToggles()
+	for _, reportType := range stopped {
+		purged := purgeReportKind(cc.Name, reportType)
+		utils.LogInfo("Dropped cached reports of disabled report kind", map[string]interface{}{
+			"cluster":    cc.Name,
+			"reportType": reportType,
+			"reports":    purged,
+		})
+	}
+	if len(started) > 0 {
+		utils.LogInfo("Started informers for enabled report kinds", map[string]interface{}{
+			"cluster":     cc.Name,
+			"reportTypes": started,
+		})
+	}
+}
+
+func (h *Handler) reportKindStatuses() []ReportKindStatus {
+	toggles := config.Get().ReportKinds
+	settings := toggles.Settings()
+	clusters := h.clusterReg.All()
+	cache := getCache()
+
+	kinds := h.crdReg.GetAllReports()
+	result := make([]ReportKindStatus, 0, len(kinds))
+	for _, kind := range kinds {
+		status := ReportKindStatus{Name: kind.Name, Kind: kind.Kind, Enabled: toggles.Enabled("", kind.Name), Clusters: make(map[string]ReportKindClusterStatus)}
+		for name, cc := range clusters {
+			_, override := settings.Clusters[name][kind.Name]
+			cs := ReportKindClusterStatus{Enabled: toggles.Enabled(name, kind.Name), Override: override}
+			if cc.Client != nil {
+				if informer := cc.Client.GetInformer(); informer != nil {
+					cs.Watching = informer.GetInformer(kind.Name) != nil
+				}
+			}
+			if cache != nil {
+				cs.Reports = len(cache.clusterReportKeys(name, kind.Name))
+			}
+			status.Clusters[name] = cs
+		}
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// ReportKinds handles GET and PUT /api/v1/admin/report-kinds.
+func (h *Handler) ReportKinds(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPut {
+		var u ReportKindUpdate
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		kind := h.crdReg.ResolveReport(u.Kind)
+		if kind == nil {
+			writeError(w, http.StatusBadRequest, "Unknown report kind: "+u.Kind)
+			return
+		}
+		if u.Cluster == "" && u.Enabled == nil {
+			writeError(w, http.StatusBadRequest, "enabled is required for the global setting")
+			return
+		}
+		var targets []*ClusterClient
+		if u.Cluster != "" {
+			cc := h.clusterReg.Get(u.Cluster)
+			if cc == nil {
+				writeError(w, http.StatusNotFound, "Cluster not found")
+				return
+			}
+			u.Cluster = cc.Name
+			targets = []*ClusterClient{cc}
+		} else {
+			for _, cc := range h.clusterReg.All() {
+				targets = append(targets, cc)
+			}
+		}
+
+		config.Get().ReportKinds.Set(u.Cluster, kind.Name, u.Enabled)
+		fields := map[string]interface{}{"reportType": kind.Name, "cluster": u.Cluster}
+		if u.Enabled != nil {
+			fields["enabled"] = *u.Enabled
+		}
+		utils.LogInfo("Report kind setting changed", fields)
+		for _, cc := range targets {
+			applyReportKindToggles(cc)
+		}
+	}
+	writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: h.reportKindStatuses()})
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+)
+
+func TestReportKinds(t *testing.T) {
+	cfg := config.Get()
+	prev := cfg.ReportKinds
+	cfg.ReportKinds = config.NewKindToggles()
+	t.Cleanup(func() { cfg.ReportKinds = prev })
+
+	c := useTestCache(t)
+	crdReg := config.GetGlobalRegistry()
+	crdReg.Register(config.ReportKind{Name: "sbomreports", Namespaced: true})
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	reg.RegisterPushed("edge", "v1.30.2", []string{"default"})
+	reg.RegisterPushed("prod", "v1.30.2", []string{"default"})
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), crdReg)
+	for _, name := range []string{"a", "b"} {
+		c.Set(reportKey("edge", "default", "sbomreports", name), makeReport(name, "edge", "default", "sbomreports", 0), time.Hour)
+	}
+
+	put := func(body string) (int, map[string]ReportKindStatus) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ReportKinds(rec, httptest.NewRequest(http.MethodPut, "/api/v1/admin/report-kinds", strings.NewReader(body)))
+		var resp struct {
+			Data []ReportKindStatus `json:"data"`
+		}
+		json.NewDecoder(rec.Body).Decode(&resp)
+		kinds := make(map[string]ReportKindStatus)
+		for _, k := range resp.Data {
+			kinds[k.Name] = k
+		}
+		return rec.Code, kinds
+	}
+
+	code, kinds := put(`{"kind": "sbom", "enabled": false}`)
+	if code != http.StatusOK {
+		t.Fatalf("got %d", code)
+	}
+	sbom := kinds["sbomreports"]
+	if sbom.Enabled || sbom.Clusters["edge"].Enabled || sbom.Clusters["edge"].Reports != 2 || sbom.Clusters["edge"].Watching {
+		t.Fatalf("global disable: got %+v", sbom)
+	}
+
+	_, kinds = put(`{"kind": "sbomreports", "cluster": "prod", "enabled": true}`)
+	if s := kinds["sbomreports"]; !s.Clusters["prod"].Enabled || !s.Clusters["prod"].Override || s.Clusters["edge"].Enabled {
+		t.Fatalf("cluster override: got %+v", s)
+	}
+	_, kinds = put(`{"kind": "sbomreports", "cluster": "prod", "enabled": null}`)
+	if s := kinds["sbomreports"]; s.Clusters["prod"].Enabled || s.Clusters["prod"].Override {
+		t.Fatalf("override removed: got %+v", s)
+	}
+
+	for body, want := range map[string]int{
+		`{"kind": "nosuchreports", "enabled": false}`:                 http.StatusBadRequest,
+		`{"kind": "sbomreports"}`:                                     http.StatusBadRequest,
+		`{"kind": "sbomreports", "cluster": "gone", "enabled": true}`: http.StatusNotFound,
+	} {
+		if code, _ := put(body); code != want {
+			t.Errorf("%s: got %d, want %d", body, code, want)
+		}
+	}
+
+	if purged := purgeReportKind("edge", "sbomreports"); purged != 2 {
+		t.Errorf("purged %d reports", purged)
+	}
+	if keys := c.clusterReportKeys("edge", "sbomreports"); len(keys) != 0 {
+		t.Errorf("reports left: %v", keys)
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// retentionInterval is how often the janitor prunes history and archived reports.
+const retentionInterval = time.Hour
+
+// RetentionPolicy is how long the history and archived reports of one kind are kept.
+type RetentionPolicy struct {
+	Kind string `json:"kind"`
+	// Retention is a duration such as 90d, or forever
+	Retention string `json:"retention"`
+	// Configured is false for kinds falling back to REPORT_RETENTION_DEFAULT
+	Configured bool `json:"configured"`
+}
+
+// RetentionRun is the outcome of the last janitor pass.
+type RetentionRun struct {
+	At     time.Time                    `json:"at"`
+	Pruned map[string]store.PruneResult `json:"pruned"`
+	Error  string                       `json:"error,omitempty"`
+}
+
+type RetentionStatus struct {
+	Default  string            `json:"default"`
+	Policies []RetentionPolicy `json:"policies"`
+	LastRun  *RetentionRun     `json:"lastRun,omitempty"`
+}
+
+var (
+	lastRetentionMu  sync.Mutex
+	lastRetentionRun *RetentionRun
+)
+
+// formatRetention writes whole days with a d suffix, as REPORT_RETENTION accepts them.
+func formatRetention(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "forever"
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+// resolvedRetention returns REPORT_RETENTION keyed by canonical kind name, so policies
+// may name kinds by short name or kind as well.
+func resolvedRetention(cfg *config.Config, reg *config.CRDRegistry) map[string]time.Duration {
+	resolved := make(map[string]time.Duration, len(cfg.ReportRetention))
+	for name, d := range cfg.ReportRetention {
+		if kind := reg.ResolveReport(name); kind != nil {
+			name = kind.Name
+		}
+		resolved[name] = d
+	}
+	return resolved
+}
+
+// reportRetention returns the retention of a report kind and whether it was configured
+// for the kind.
+func reportRetention(resolved map[string]time.Duration, fallback time.Duration, kind string) (time.Duration, bool) {
+	if d, ok := resolved[kind]; ok {
+		return d, true
+	}
+	return fallback, false
+}
+
+// StartRetentionJanitor prunes, every hour, the history snapshots and archived reports
+// older than the retention of their kind.
+func StartRetentionJanitor(ctx context.Context) {
+	st := store.Get()
+	if st == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(retentionInterval)
+		defer ticker.Stop()
+		for {
+			pruneReportData(ctx, st, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func pruneReportData(ctx context.Context, st *store.Store, now time.Time) {
+	cfg := config.Get()
+	resolved := resolvedRetention(cfg, config.GetGlobalRegistry())
+	run := &RetentionRun{At: now, Pruned: make(map[string]store.PruneResult)}
+	defer func() {
+		lastRetentionMu.Lock()
+		lastRetentionRun = run
+		lastRetentionMu.Unlock()
+	}()
+
+	dbCtx, cancel := storeCallContext(ctx)
+	types, err := st.RetainedReportTypes(dbCtx)
+	cancel()
+	if err != nil {
+		run.Error = err.Error()
+		utils.LogWarning("Failed to list retained report types", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	for _, kind := range types {
+		retention, _ := reportRetention(resolved, cfg.DefaultReportRetention, kind)
+		if retention <= 0 {
+			continue
+		}
+		dbCtx, cancel := storeCallContext(ctx)
+		result, err := st.PruneReportData(dbCtx, kind, now.Add(-retention))
+		cancel()
+		if err != nil {
+			run.Error = err.Error()
+			utils.LogWarning("Failed to prune report data", map[string]interface{}{"reportType": kind, "error": err.Error()})
+			continue
+		}
+		run.Pruned[kind] = result
+		if result.History > 0 || result.Archived > 0 {
+			utils.LogInfo("Pruned report data past retention", map[string]interface{}{
+				"reportType": kind,
+				"retention":  formatRetention(retention),
+				"history":    result.History,
+				"archived":   result.Archived,
+			})
+		}
+	}
+}
+
+// retentionStatus lists the policy of every known report kind and of kinds only named in
+// REPORT_RETENTION.
+func (h *Handler) retentionStatus(cfg *config.Config) RetentionStatus {
+	resolved := resolvedRetention(cfg, h.crdReg)
+	status := RetentionStatus{Default: formatRetention(cfg.DefaultReportRetention), Policies: []RetentionPolicy{}}
+	seen := make(map[string]bool)
+	add := func(kind string) {
+		if seen[kind] {
+			return
+		}
+		seen[kind] = true
+		d, configured := reportRetention(resolved, cfg.DefaultReportRetention, kind)
+		status.Policies = append(status.Policies, RetentionPolicy{Kind: kind, Retention: formatRetention(d), Configured: configured})
+	}
+	for _, kind := range h.crdReg.GetAllReports() {
+		add(kind.Name)
+	}
+	for kind := range resolved {
+		add(kind)
+	}
+	sort.Slice(status.Policies, func(i, j int) bool { return status.Policies[i].Kind < status.Policies[j].Kind })
+
+	lastRetentionMu.Lock()
+	status.LastRun = lastRetentionRun
+	lastRetentionMu.Unlock()
+	return status
+}
+
+// GetRetention handles GET /api/v1/retention, the retention of each report kind.
+func (h *Handler) GetRetention(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    h.retentionStatus(config.Get()),
+	})
+}
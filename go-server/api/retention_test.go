@@ -0,0 +1,40 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+)
+
+func TestRetentionStatus(t *testing.T) {
+	reg := config.GetGlobalRegistry()
+	reg.Register(config.ReportKind{Name: "retentiontestreports", ShortName: "rtr", Namespaced: true})
+	reg.Register(config.ReportKind{Name: "retentiontestsboms", Namespaced: true})
+	h := &Handler{crdReg: reg}
+	cfg := &config.Config{
+		ReportRetention:        map[string]time.Duration{"rtr": 90 * 24 * time.Hour, "retiredreports": 36 * time.Hour},
+		DefaultReportRetention: 30 * 24 * time.Hour,
+	}
+
+	policies := make(map[string]RetentionPolicy)
+	status := h.retentionStatus(cfg)
+	for _, p := range status.Policies {
+		policies[p.Kind] = p
+	}
+	if status.Default != "30d" {
+		t.Errorf("default = %q", status.Default)
+	}
+	if p := policies["retentiontestreports"]; p.Retention != "90d" || !p.Configured {
+		t.Errorf("policy by short name not applied: %+v", p)
+	}
+	if p := policies["retentiontestsboms"]; p.Retention != "30d" || p.Configured {
+		t.Errorf("unlisted kind should use the default: %+v", p)
+	}
+	if p := policies["retiredreports"]; p.Retention != "36h0m0s" || !p.Configured {
+		t.Errorf("policy of unknown kind not listed: %+v", p)
+	}
+	if got := formatRetention(0); got != "forever" {
+		t.Errorf("formatRetention(0) = %q", got)
+	}
+}
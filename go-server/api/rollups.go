@@ -0,0 +1,258 @@
+package api
+
+import (
+	"sync"
+)
+
+// ReportRollup counts the reports of one type, cluster and namespace, or of several of
+// them added up.
+type ReportRollup struct {
+	Reports int `json:"reports"`
+	// WithVulnerabilities counts the reports with findings, as report lists do
+	WithVulnerabilities int `json:"withVulnerabilities"`
+	// Failed counts the reports with findings of a known severity, as the overview does
+	Failed   int            `json:"failed"`
+	Severity SeverityTotals `json:"severity"`
+}
+
+func (r *ReportRollup) add(c rollupContribution, sign int) {
+	r.Reports += sign
+	if c.vulnerable {
+		r.WithVulnerabilities += sign
+	}
+	if c.failed {
+		r.Failed += sign
+	}
+	r.Severity.Critical += sign * c.severity.Critical
+	r.Severity.High += sign * c.severity.High
+	r.Severity.Medium += sign * c.severity.Medium
+	r.Severity.Low += sign * c.severity.Low
+}
+
+func (r *ReportRollup) merge(o ReportRollup) {
+	r.Reports += o.Reports
+	r.WithVulnerabilities += o.WithVulnerabilities
+	r.Failed += o.Failed
+	r.Severity.Critical += o.Severity.Critical
+	r.Severity.High += o.Severity.High
+	r.Severity.Medium += o.Severity.Medium
+	r.Severity.Low += o.Severity.Low
+}
+
+type rollupKey struct {
+	reportType string
+	cluster    string
+	namespace  string
+}
+
+type rollupContribution struct {
+	rollupKey
+	name       string
+	severity   SeverityTotals
+	vulnerable bool
+	failed     bool
+}
+
+// reportRollups keeps report counts per type, cluster and namespace up to date as the
+// cache writes and removes reports, so report lists and the overview read them instead of
+// scanning every report. Each report is counted under its key, so writing a report again
+// replaces what it counted before.
+type reportRollups struct {
+	mu      sync.RWMutex
+	reports map[string]rollupContribution
+	rollups map[rollupKey]*ReportRollup
+	// severe holds the reports with critical or high findings, which the overview ranks
+	severe map[string]rollupContribution
+}
+
+func newReportRollups() *reportRollups {
+	return &reportRollups{
+		reports: make(map[string]rollupContribution),
+		rollups: make(map[rollupKey]*ReportRollup),
+		severe:  make(map[string]rollupContribution),
+	}
+}
+
+func (r *reportRollups) set(key string, value interface{}) {
+	cluster, namespace, reportType, name, ok := parseReportCacheKey(key)
+	if !ok {
+		return
+	}
+	report, ok := convertCacheValue[Report](value)
+	if !ok {
+		r.remove(key)
+		return
+	}
+	c, h, m, l := extractSummaryCounts(report)
+	contrib := rollupContribution{
+		rollupKey:  rollupKey{reportType: reportType, cluster: cluster, namespace: namespace},
+		name:       name,
+		severity:   SeverityTotals{Critical: c, High: h, Medium: m, Low: l},
+		vulnerable: hasVulnerabilitiesInReport(report),
+		failed:     c+h+m+l > 0,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.reports[key]; ok {
+		r.apply(key, old, -1)
+	}
+	r.reports[key] = contrib
+	r.apply(key, contrib, 1)
+}
+
+func (r *reportRollups) remove(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.reports[key]; ok {
+		r.apply(key, old, -1)
+		delete(r.reports, key)
+	}
+}
+
+func (r *reportRollups) apply(key string, c rollupContribution, sign int) {
+	rollup := r.rollups[c.rollupKey]
+	if rollup == nil {
+		rollup = &ReportRollup{}
+		r.rollups[c.rollupKey] = rollup
+	}
+	rollup.add(c, sign)
+	if rollup.Reports == 0 {
+		delete(r.rollups, c.rollupKey)
+	}
+	if sign < 0 {
+		delete(r.severe, key)
+	} else if c.severity.Critical > 0 || c.severity.High > 0 {
+		r.severe[key] = c
+	}
+}
+
+// sum adds up the rollups of a report type, all types when it is empty, selected as
+// GetReports selects reports: by a comma-separated cluster filter and by namespaces, which
+// never exclude cluster-scoped reports.
+func (r *reportRollups) sum(typeName, clusterFilter string, namespaces []string) ReportRollup {
+	clusters := clusterSet(clusterFilter)
+	var selected map[string]bool
+	for _, ns := range namespaces {
+		if ns == "all" {
+			selected = nil
+			break
+		}
+		if selected == nil {
+			selected = make(map[string]bool)
+		}
+		selected[ns] = true
+	}
+
+	var total ReportRollup
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for key, rollup := range r.rollups {
+		if typeName != "" && key.reportType != typeName {
+			continue
+		}
+		if clusters != nil && !clusters[key.cluster] {
+			continue
+		}
+		if selected != nil && key.namespace != "" && !selected[key.namespace] {
+			continue
+		}
+		total.merge(*rollup)
+	}
+	return total
+}
+
+// overview builds the overview of the reports of the clusters in a comma-separated filter,
+// of all clusters when it is empty, from the rollups.
+func (r *reportRollups) overview(clusterFilter string) *ClusterOverview {
+	overview := &ClusterOverview{
+		ScanTypesBreakdown:     make(map[string]TypeBreakdown),
+		TopVulnerableWorkloads: make([]WorkloadSummary, 0),
+		VulnerableClusters:     make([]ClusterSummary, 0),
+		VulnerableNamespaces:   make([]NamespaceSummary, 0),
+	}
+	clusters := clusterSet(clusterFilter)
+	workloadScores := make(map[string]*WorkloadSummary)
+	nsScores := make(map[string]*NamespaceSummary)
+	clusterScores := make(map[string]*ClusterSummary)
+
+	r.mu.RLock()
+	for key, rollup := range r.rollups {
+		if clusters != nil && !clusters[key.cluster] {
+			continue
+		}
+		overview.TotalReports += rollup.Reports
+		overview.SeverityTotals.Critical += rollup.Severity.Critical
+		overview.SeverityTotals.High += rollup.Severity.High
+		overview.SeverityTotals.Medium += rollup.Severity.Medium
+		overview.SeverityTotals.Low += rollup.Severity.Low
+
+		tb := overview.ScanTypesBreakdown[key.reportType]
+		tb.Scanned += rollup.Reports
+		tb.Failed += rollup.Failed
+		tb.Critical += rollup.Severity.Critical
+		overview.ScanTypesBreakdown[key.reportType] = tb
+
+		if rollup.Severity.Critical == 0 && rollup.Severity.High == 0 {
+			continue
+		}
+		nsKey := key.cluster + ":" + key.namespace
+		if nsScores[nsKey] == nil {
+			nsScores[nsKey] = &NamespaceSummary{Name: key.namespace}
+		}
+		nsScores[nsKey].Critical += rollup.Severity.Critical
+		nsScores[nsKey].High += rollup.Severity.High
+		if clusterScores[key.cluster] == nil {
+			clusterScores[key.cluster] = &ClusterSummary{Name: key.cluster}
+		}
+		clusterScores[key.cluster].Critical += rollup.Severity.Critical
+		clusterScores[key.cluster].High += rollup.Severity.High
+	}
+	for key, c := range r.severe {
+		if clusters != nil && !clusters[c.cluster] {
+			continue
+		}
+		workloadScores[key] = &WorkloadSummary{
+			Cluster:   c.cluster,
+			Namespace: c.namespace,
+			Name:      c.name,
+			Type:      c.reportType,
+			Critical:  c.severity.Critical,
+			High:      c.severity.High,
+		}
+	}
+	r.mu.RUnlock()
+
+	rankOverview(overview, workloadScores, nsScores, clusterScores, len(clusters) == 1)
+	return overview
+}
+
+// NamespaceRollup is the rollup of one report type in one namespace of a cluster; the
+// namespace is empty for cluster-scoped reports.
+type NamespaceRollup struct {
+	Type      string `json:"type"`
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	ReportRollup
+}
+
+// list returns the rollups of the clusters in a comma-separated filter, of all clusters
+// when it is empty, in no particular order.
+func (r *reportRollups) list(clusterFilter string) []NamespaceRollup {
+	clusters := clusterSet(clusterFilter)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]NamespaceRollup, 0, len(r.rollups))
+	for key, rollup := range r.rollups {
+		if clusters != nil && !clusters[key.cluster] {
+			continue
+		}
+		result = append(result, NamespaceRollup{
+			Type:         key.reportType,
+			Cluster:      key.cluster,
+			Namespace:    key.namespace,
+			ReportRollup: *rollup,
+		})
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReportRollups_FollowCacheWrites(t *testing.T) {
+	c := useTestCache(t)
+	t.Cleanup(func() { queryResultCache.Clear() })
+	const typ = "rollupreports"
+	c.Set(reportKey("a", "web", typ, "r1"), makeReport("r1", "a", "web", typ, 3), 0)
+	c.Set(reportKey("a", "web", typ, "r2"), makeReport("r2", "a", "web", typ, 0), 0)
+	c.Set(reportKey("a", "db", typ, "r3"), makeReport("r3", "a", "db", typ, 1), 0)
+	c.Set(reportKey("a", "", typ, "r4"), makeReport("r4", "a", "", typ, 2), 0)
+	c.Set(reportKey("b", "web", typ, "r5"), makeReport("r5", "b", "web", typ, 5), 0)
+
+	// writing a report again replaces its counts, deleting it removes them
+	c.Set(reportKey("a", "web", typ, "r1"), makeReport("r1", "a", "web", typ, 4), 0)
+	c.Delete(reportKey("b", "web", typ, "r5"))
+
+	if got := c.GetReportRollup(typ, "", nil); got.Reports != 4 || got.WithVulnerabilities != 3 || got.Severity.Critical != 7 {
+		t.Fatalf("rollup of all clusters = %+v", got)
+	}
+	if got := c.GetReportRollup(typ, "a", []string{"web"}); got.Reports != 3 || got.WithVulnerabilities != 2 || got.Severity.Critical != 6 {
+		t.Errorf("rollup of namespace web = %+v, want the cluster-scoped report counted too", got)
+	}
+	if got := c.GetReportRollup(typ, "b", nil); got.Reports != 0 {
+		t.Errorf("rollup of a cluster without reports = %+v", got)
+	}
+	if total, withVuln := c.GetReportCount(typ, "a"); total != 4 || withVuln != 3 {
+		t.Errorf("report count = %d, %d", total, withVuln)
+	}
+
+	result := NewQueryService(&CacheServiceImpl{cache: c}).ListReports(ReportQuery{Type: typ, Cluster: "a", Page: 1, PageSize: 1})
+	if result.Total != 4 || result.WithVulnerabilities != 3 || len(result.Items) != 1 {
+		t.Errorf("list = %d reports, %d with vulnerabilities, %d items", result.Total, result.WithVulnerabilities, len(result.Items))
+	}
+}
+
+func TestReportRollups_OverviewMatchesScan(t *testing.T) {
+	c := useTestCache(t)
+	const typ = "rollupoverviewreports"
+	for i, cluster := range []string{"a", "b"} {
+		for j, ns := range []string{"web", "db", ""} {
+			name := "r" + ns
+			c.Set(reportKey(cluster, ns, typ, name), makeReport(name, cluster, ns, typ, float64(i*3+j)), 0)
+		}
+	}
+	c.Delete(reportKey("b", "db", typ, "rdb"))
+
+	scanAll := func(Report) bool { return true }
+	for _, filter := range []string{"", "a", "a,b"} {
+		got := c.GetOverviewData(filter, false, nil)
+		want := c.GetOverviewData(filter, false, scanAll)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("overview of %q from the rollups = %+v, scanning = %+v", filter, got, want)
+		}
+	}
+}
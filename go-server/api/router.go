@@ -7,6 +7,7 @@ import (
 
 	"trivy-ui/config"
 	"trivy-ui/kubernetes"
+	"trivy-ui/metrics"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -26,6 +27,14 @@ func NewRouter(k8sClient *kubernetes.Client, staticPath string, cache CacheServi
 }
 
 func (r *Router) Setup(staticPath string) {
+	r.mux.HandleFunc("/api", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetAPIVersions(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	r.mux.HandleFunc("/api/v1/type", func(w http.ResponseWriter, req *http.Request) {
 		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
 			r.handler.GetTypesV1(w, req)
@@ -45,6 +54,8 @@ func (r *Router) Setup(staticPath string) {
 			} else {
 				http.NotFound(w, req)
 			}
+		} else if req.Method == http.MethodPost && len(parts) == 2 && parts[1] == "details" {
+			r.handler.GetReportDetailsBulk(w, req, parts[0])
 		} else {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -58,6 +69,14 @@ func (r *Router) Setup(staticPath string) {
 		}
 	})
 
+	r.mux.HandleFunc("/api/v1/summary", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetSummary(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	r.mux.HandleFunc("/api/v1/overview/trends", func(w http.ResponseWriter, req *http.Request) {
 		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
 			r.handler.GetOverviewTrends(w, req)
@@ -119,6 +138,448 @@ func (r *Router) Setup(staticPath string) {
 		}
 	})
 
+	r.mux.HandleFunc("/api/v1/triage", func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodOptions:
+			r.handler.ListTriage(w, req)
+		case http.MethodPatch:
+			r.handler.PatchTriage(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/triage/", func(w http.ResponseWriter, req *http.Request) {
+		id := strings.TrimPrefix(req.URL.Path, "/api/v1/triage/")
+		if id == "" || strings.Contains(id, "/") {
+			http.NotFound(w, req)
+			return
+		}
+		if req.Method == http.MethodPatch && id == "batch" {
+			r.handler.PatchTriageBatch(w, req)
+		} else if req.Method == http.MethodPatch {
+			r.handler.PatchTriageByID(w, req, id)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/sla/overdue", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetSLAOverdue(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/base-images", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetBaseImages(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/os-types", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetOSTypes(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/sbom/stats", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetSbomStats(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/export/sbom/", func(w http.ResponseWriter, req *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/v1/export/sbom/"), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			http.NotFound(w, req)
+			return
+		}
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.ExportSbom(w, req, parts[0], parts[1])
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/workloads/", func(w http.ResponseWriter, req *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/v1/workloads/"), "/")
+		if len(parts) != 4 || (parts[3] != "timeline" && parts[3] != "score") {
+			http.NotFound(w, req)
+			return
+		}
+		if req.Method != http.MethodGet && req.Method != http.MethodOptions {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		cluster, err1 := url.PathUnescape(parts[0])
+		namespace, err2 := url.PathUnescape(parts[1])
+		name, err3 := url.PathUnescape(parts[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			http.NotFound(w, req)
+			return
+		}
+		if namespace == "_" {
+			namespace = ""
+		}
+		if parts[3] == "score" {
+			r.handler.GetWorkloadScore(w, req, cluster, namespace, name)
+			return
+		}
+		r.handler.GetWorkloadTimeline(w, req, cluster, namespace, name)
+	})
+
+	r.mux.HandleFunc("/api/v1/archive", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.ListArchivedReports(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/issues", func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodOptions:
+			r.handler.ListIssues(w, req)
+		case http.MethodPost:
+			r.handler.CreateIssue(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/agent/events", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			r.handler.PushAgentEvents(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/integrations/alertmanager", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			r.handler.ReceiveAlertmanagerWebhook(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/integrations/defectdojo", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			r.handler.PushToDefectDojo(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/alerts", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetAlerts(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/events", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.StreamEvents(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/startup", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetStartup(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/ui-config", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetUIConfig(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/telemetry/preview", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetTelemetryPreview(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/fleet/summary", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetFleetSummary(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/images/compare", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetImageCompare(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/images/hygiene", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetImageHygiene(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/images/", func(w http.ResponseWriter, req *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/v1/images/"), "/")
+		if len(parts) != 2 || parts[1] != "attestations" {
+			http.NotFound(w, req)
+			return
+		}
+		if req.Method != http.MethodGet && req.Method != http.MethodOptions {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		digest, err := url.PathUnescape(parts[0])
+		if err != nil || digest == "" {
+			http.NotFound(w, req)
+			return
+		}
+		r.handler.GetImageAttestations(w, req, digest)
+	})
+
+	r.mux.HandleFunc("/api/v1/export-schedules", func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodOptions:
+			r.handler.ListExportSchedules(w, req)
+		case http.MethodPost:
+			r.handler.CreateExportSchedule(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/export-schedules/", func(w http.ResponseWriter, req *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/v1/export-schedules/"), "/")
+		switch {
+		case parts[0] == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "run"):
+			http.NotFound(w, req)
+		case len(parts) == 2 && req.Method == http.MethodPost:
+			r.handler.ExportScheduleByID(w, req, parts[0], "run")
+		case len(parts) == 1 && (req.Method == http.MethodGet || req.Method == http.MethodOptions ||
+			req.Method == http.MethodPut || req.Method == http.MethodDelete):
+			r.handler.ExportScheduleByID(w, req, parts[0], "")
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/retention", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetRetention(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/share", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			r.handler.CreateShareLink(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/scan", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			r.handler.ScanImage(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/share/", func(w http.ResponseWriter, req *http.Request) {
+		token := strings.TrimPrefix(req.URL.Path, "/share/")
+		if token == "" || strings.Contains(token, "/") {
+			http.NotFound(w, req)
+			return
+		}
+		if req.Method == http.MethodGet || req.Method == http.MethodHead {
+			r.handler.GetSharedView(w, req, token)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/jobs", func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodOptions:
+			r.handler.ListJobs(w, req)
+		case http.MethodPost:
+			r.handler.CreateJob(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/jobs/", func(w http.ResponseWriter, req *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/v1/jobs/"), "/")
+		switch {
+		case parts[0] == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "result" && parts[1] != "cancel"):
+			http.NotFound(w, req)
+		case len(parts) == 2 && parts[1] == "cancel" && req.Method == http.MethodPost:
+			r.handler.JobByID(w, req, parts[0], "cancel")
+		case len(parts) == 2 && parts[1] == "result" && (req.Method == http.MethodGet || req.Method == http.MethodOptions):
+			r.handler.JobByID(w, req, parts[0], "result")
+		case len(parts) == 1 && (req.Method == http.MethodGet || req.Method == http.MethodOptions):
+			r.handler.JobByID(w, req, parts[0], "")
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/notifications/status", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetNotificationStatus(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/notifications/routes", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.ListNotificationRoutes(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/notifications/routes/test", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.TestNotificationRoute(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/webhooks/events", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetWebhookEvents(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/webhooks/test", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost || req.Method == http.MethodOptions {
+			r.handler.TestWebhook(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/onboarding/manifests", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetOnboardingManifests(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/onboarding/validate", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost || req.Method == http.MethodOptions {
+			r.handler.ValidateOnboardingKubeconfig(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/notifications/mutes", func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodOptions:
+			r.handler.ListMuteWindows(w, req)
+		case http.MethodPost:
+			r.handler.CreateMuteWindow(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/notifications/mutes/", func(w http.ResponseWriter, req *http.Request) {
+		id := strings.TrimPrefix(req.URL.Path, "/api/v1/notifications/mutes/")
+		switch {
+		case id == "" || strings.Contains(id, "/"):
+			http.NotFound(w, req)
+		case req.Method == http.MethodGet || req.Method == http.MethodOptions || req.Method == http.MethodDelete:
+			r.handler.MuteWindowByID(w, req, id)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/severity-overrides", func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodOptions:
+			r.handler.ListSeverityOverrides(w, req)
+		case http.MethodPost:
+			r.handler.SetSeverityOverride(w, req, "")
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/severity-overrides/", func(w http.ResponseWriter, req *http.Request) {
+		cve := strings.TrimPrefix(req.URL.Path, "/api/v1/severity-overrides/")
+		switch {
+		case cve == "" || strings.Contains(cve, "/"):
+			http.NotFound(w, req)
+		case req.Method == http.MethodPut:
+			r.handler.SetSeverityOverride(w, req, cve)
+		case req.Method == http.MethodDelete:
+			r.handler.DeleteSeverityOverride(w, req, cve)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/grafana/", func(w http.ResponseWriter, req *http.Request) {
+		switch strings.TrimPrefix(req.URL.Path, "/api/grafana/") {
+		case "":
+			if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+				r.handler.GrafanaTest(w, req)
+			} else {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		case "search":
+			if req.Method == http.MethodPost || req.Method == http.MethodOptions {
+				r.handler.GrafanaSearch(w, req)
+			} else {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		case "query":
+			if req.Method == http.MethodPost || req.Method == http.MethodOptions {
+				r.handler.GrafanaQuery(w, req)
+			} else {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		default:
+			http.NotFound(w, req)
+		}
+	})
+
 	r.mux.HandleFunc("/api/report-types", r.handler.GetReportTypes)
 
 	r.mux.HandleFunc("/api/clusters", func(w http.ResponseWriter, req *http.Request) {
@@ -137,9 +598,160 @@ func (r *Router) Setup(staticPath string) {
 			r.handler.GetNamespacesByCluster(w, req, cluster)
 			return
 		}
+		if len(parts) == 2 && parts[1] == "operator-scope" && (req.Method == http.MethodGet || req.Method == http.MethodOptions) {
+			r.handler.GetOperatorScope(w, req, parts[0])
+			return
+		}
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	})
 
+	r.mux.HandleFunc("/api/v1/namespaces/suggest", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.SuggestNamespaces(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/pss", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetPSS(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/pss/controls", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetPSSControls(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/compliance/controls", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetControlCoverage(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/compliance/mapping", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetControlMapping(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/suggest", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.Suggest(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/clusters/", func(w http.ResponseWriter, req *http.Request) {
+		path := strings.TrimPrefix(req.URL.Path, "/api/v1/clusters/")
+		parts := strings.Split(path, "/")
+		if len(parts) == 2 && parts[1] == "trivy-db" && (req.Method == http.MethodGet || req.Method == http.MethodOptions) {
+			r.handler.GetTrivyDB(w, req, parts[0])
+			return
+		}
+		if len(parts) == 2 && parts[1] == "connectivity" && (req.Method == http.MethodGet || req.Method == http.MethodOptions) {
+			r.handler.GetClusterConnectivity(w, req, parts[0])
+			return
+		}
+		if len(parts) == 2 && parts[1] == "restore" && req.Method == http.MethodPost {
+			r.handler.RestoreCluster(w, req, parts[0])
+			return
+		}
+		if len(parts) == 2 && parts[1] == "tags" && (req.Method == http.MethodGet || req.Method == http.MethodOptions || req.Method == http.MethodPut) {
+			r.handler.ClusterTags(w, req, parts[0])
+			return
+		}
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	r.mux.HandleFunc("/api/v1/admin/cache/stats", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetCacheStats(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/admin/cache/dump", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.DumpCache(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/admin/runtime", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetRuntime(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/admin/informers", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetInformerStats(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/admin/logging", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions || req.Method == http.MethodPut {
+			r.handler.Logging(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/admin/report-kinds", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions || req.Method == http.MethodPut {
+			r.handler.ReportKinds(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/admin/reload", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost || req.Method == http.MethodOptions {
+			r.handler.ReloadConfig(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/admin/raw/", func(w http.ResponseWriter, req *http.Request) {
+		cluster, apiPath, ok := strings.Cut(strings.TrimPrefix(req.URL.Path, "/api/v1/admin/raw/"), "/")
+		if !ok || cluster == "" {
+			http.NotFound(w, req)
+			return
+		}
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.ProxyRawAPI(w, req, cluster, apiPath)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	r.mux.HandleFunc("/api/v1/admin/selftest", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodOptions {
+			r.handler.GetSelftest(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	r.mux.Handle("/swagger/", httpSwagger.WrapHandler)
 
 	// 健康检查端点
@@ -151,13 +763,45 @@ func (r *Router) Setup(staticPath string) {
 	// 就绪检查端点
 	r.mux.HandleFunc("/readyz", r.handler.ReadinessCheck)
 
-	// 缓存统计端点
-	r.mux.HandleFunc("/api/cache/stats", r.handler.GetCacheStats)
+	// Cache snapshots for replicas bootstrapping from this one
+	r.mux.HandleFunc(SnapshotPath, func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			r.handler.ServeSnapshot(w, req)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Prometheus metrics
+	r.mux.Handle("/metrics", metrics.Handler())
 
 	r.mux.HandleFunc("/", SpaHandler(staticPath))
 
 }
 
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if config.Get().DemoMode {
+		switch {
+		case strings.HasPrefix(req.URL.Path, "/api/"), strings.HasPrefix(req.URL.Path, "/share/"):
+			r.serveDemo(w, req, r.serve)
+			return
+		case req.URL.Path == "/metrics":
+			// metric labels carry the real cluster names
+			http.NotFound(w, req)
+			return
+		}
+	}
+	r.serve(w, req)
+}
+
+func (r *Router) serve(w http.ResponseWriter, req *http.Request) {
+	if strings.HasPrefix(req.URL.Path, "/api/v2/") {
+		r.serveV2(w, req)
+		return
+	}
+	if isV1Path(req.URL.Path) {
+		w.Header().Set("API-Version", APIVersionV1)
+		setDeprecationHeaders(w.Header(), req.URL.Path, config.Get().APIV1Sunset)
+	}
 	r.mux.ServeHTTP(w, req)
 }
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"runtime"
+	"sort"
+	"time"
+
+	"trivy-ui/kubernetes"
+)
+
+// processStart is when the server started, for the uptime in runtime stats.
+var processStart = time.Now()
+
+// maxReportedGCPauses bounds the recent GC pauses listed in runtime stats.
+const maxReportedGCPauses = 16
+
+type MemoryStats struct {
+	HeapAllocBytes  uint64 `json:"heapAllocBytes"`
+	HeapInuseBytes  uint64 `json:"heapInuseBytes"`
+	HeapSysBytes    uint64 `json:"heapSysBytes"`
+	HeapObjects     uint64 `json:"heapObjects"`
+	StackInuseBytes uint64 `json:"stackInuseBytes"`
+	// SysBytes is all memory obtained from the OS, the closest to the container's usage
+	SysBytes uint64 `json:"sysBytes"`
+}
+
+type GCStats struct {
+	NumGC         uint32    `json:"numGC"`
+	PauseTotalMs  float64   `json:"pauseTotalMs"`
+	LastGC        time.Time `json:"lastGC,omitzero"`
+	CPUFraction   float64   `json:"cpuFraction"`
+	NextGCBytes   uint64    `json:"nextGCBytes"`
+	RecentPauseMs []float64 `json:"recentPauseMs"`
+}
+
+// ClusterInformerStats counts the objects held in a cluster's informer stores.
+type ClusterInformerStats struct {
+	Cluster string                        `json:"cluster"`
+	Objects int                           `json:"objects"`
+	Kinds   []kubernetes.KindSyncProgress `json:"kinds"`
+}
+
+type RuntimeStats struct {
+	GoVersion     string                 `json:"goVersion"`
+	UptimeSeconds int64                  `json:"uptimeSeconds"`
+	Goroutines    int                    `json:"goroutines"`
+	GOMAXPROCS    int                    `json:"gomaxprocs"`
+	NumCPU        int                    `json:"numCPU"`
+	Memory        MemoryStats            `json:"memory"`
+	GC            GCStats                `json:"gc"`
+	Cache         map[string]interface{} `json:"cache"`
+	Informers     []ClusterInformerStats `json:"informers"`
+}
+
+// recentGCPauses returns the latest pauses, newest first, from the runtime's ring buffer.
+func recentGCPauses(ms *runtime.MemStats) []float64 {
+	n := min(int(ms.NumGC), maxReportedGCPauses, len(ms.PauseNs))
+	pauses := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		idx := (int(ms.NumGC) - 1 - i + len(ms.PauseNs)) % len(ms.PauseNs)
+		pauses = append(pauses, float64(ms.PauseNs[idx])/float64(time.Millisecond))
+	}
+	return pauses
+}
+
+func (h *Handler) runtimeStats() RuntimeStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	stats := RuntimeStats{
+		GoVersion:     runtime.Version(),
+		UptimeSeconds: int64(time.Since(processStart).Seconds()),
+		Goroutines:    runtime.NumGoroutine(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		NumCPU:        runtime.NumCPU(),
+		Memory: MemoryStats{
+			HeapAllocBytes:  ms.HeapAlloc,
+			HeapInuseBytes:  ms.HeapInuse,
+			HeapSysBytes:    ms.HeapSys,
+			HeapObjects:     ms.HeapObjects,
+			StackInuseBytes: ms.StackInuse,
+			SysBytes:        ms.Sys,
+		},
+		GC: GCStats{
+			NumGC:         ms.NumGC,
+			PauseTotalMs:  float64(ms.PauseTotalNs) / float64(time.Millisecond),
+			CPUFraction:   ms.GCCPUFraction,
+			NextGCBytes:   ms.NextGC,
+			RecentPauseMs: recentGCPauses(&ms),
+		},
+		Cache:     h.cache.GetStats(),
+		Informers: []ClusterInformerStats{},
+	}
+	if ms.LastGC > 0 {
+		stats.GC.LastGC = time.Unix(0, int64(ms.LastGC)).UTC()
+	}
+
+	for name, cc := range h.clusterReg.All() {
+		if cc.Client == nil || cc.Client.GetInformer() == nil {
+			continue
+		}
+		informer := ClusterInformerStats{Cluster: name, Kinds: cc.Client.GetInformer().SyncProgress()}
+		for _, k := range informer.Kinds {
+			informer.Objects += k.Objects
+		}
+		stats.Informers = append(stats.Informers, informer)
+	}
+	sort.Slice(stats.Informers, func(i, j int) bool { return stats.Informers[i].Cluster < stats.Informers[j].Cluster })
+	return stats
+}
+
+// GetRuntime reports the server's own memory, goroutines, GC pauses, cache size and
+// informer store sizes, for right-sizing the deployment and spotting leaks.
+func (h *Handler) GetRuntime(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    h.runtimeStats(),
+	})
+}
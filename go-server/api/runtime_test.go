@@ -0,0 +1,55 @@
+package api
+
+import (
+	"runtime"
+	"testing"
+
+	"trivy-ui/config"
+)
+
+func TestRecentGCPauses(t *testing.T) {
+	var ms runtime.MemStats
+	ms.NumGC = 3
+	ms.PauseNs[0], ms.PauseNs[1], ms.PauseNs[2] = 1e6, 2e6, 3e6
+	pauses := recentGCPauses(&ms)
+	if len(pauses) != 3 || pauses[0] != 3 || pauses[2] != 1 {
+		t.Fatalf("pauses = %v", pauses)
+	}
+
+	ms.NumGC = 300
+	if pauses := recentGCPauses(&ms); len(pauses) != maxReportedGCPauses {
+		t.Fatalf("listed %d pauses", len(pauses))
+	}
+}
+
+func TestRuntimeStats(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	h := NewHandler(nil, svc, NewClusterRegistry(svc), NewQueryService(svc), config.GetGlobalRegistry())
+	c.Set(reportKey("c", "web", "vulnerabilityreports", "replicaset-web"), makeReport("replicaset-web", "c", "web", "vulnerabilityreports", 1), 0)
+
+	stats := h.runtimeStats()
+	if stats.Goroutines == 0 || stats.Memory.HeapAllocBytes == 0 || stats.GoVersion == "" {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if stats.Cache["report_items"] != 1 {
+		t.Errorf("cache = %v", stats.Cache)
+	}
+}
+
+func TestInformerStats(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	reg.RegisterPushed("edge", "v1.30.0", nil)
+	reg.RegisterPushed("lab", "v1.30.0", nil)
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), config.GetGlobalRegistry())
+
+	stats := h.informerStats(nil)
+	if len(stats) != 2 || stats[0].Cluster != "edge" || stats[0].Informer || stats[0].Kinds == nil {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if stats := h.informerStats(clusterSet("lab")); len(stats) != 1 || stats[0].Cluster != "lab" {
+		t.Errorf("filtered stats = %+v", stats)
+	}
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"trivy-ui/kubernetes"
+	"trivy-ui/sbom"
+)
+
+// PackageTypeStats counts SBOM packages per ecosystem (npm, pip, gomod, jar, os-pkgs, ...).
+type PackageTypeStats struct {
+	PackageTypes map[string]int `json:"packageTypes"`
+	Total        int            `json:"total"`
+}
+
+type ImagePackageStats struct {
+	Image      string   `json:"image"`
+	Clusters   []string `json:"clusters"`
+	Namespaces []string `json:"namespaces"`
+	PackageTypeStats
+}
+
+type NamespacePackageStats struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Images    int    `json:"images"`
+	PackageTypeStats
+}
+
+type SbomStats struct {
+	Images     []ImagePackageStats     `json:"images"`
+	Namespaces []NamespacePackageStats `json:"namespaces"`
+	// Total counts the packages of every distinct image once
+	Total PackageTypeStats `json:"total"`
+}
+
+func (s *PackageTypeStats) add(counts map[string]int) {
+	if s.PackageTypes == nil {
+		s.PackageTypes = make(map[string]int)
+	}
+	for t, n := range counts {
+		s.PackageTypes[t] += n
+		s.Total += n
+	}
+}
+
+// GetSbomStats handles GET /api/v1/sbom/stats.
+func (h *Handler) GetSbomStats(w http.ResponseWriter, r *http.Request) {
+	clusterFilter, namespaceFilters, _, _ := h.parseQueryParams(r)
+
+	params := strings.Join([]string{clusterFilter, strings.Join(namespaceFilters, ",")}, "|")
+	result := aggregates.getOrCompute("sbom-stats", aggregateScope(clusterFilter), params, func() interface{} {
+		return h.computeSbomStats(clusterFilter, namespaceFilters)
+	})
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    result,
+	})
+}
+
+// computeSbomStats aggregates the package counts of SBOM reports per image and namespace.
+// An image deployed by several workloads is counted once per namespace and once overall.
+func (h *Handler) computeSbomStats(clusterFilter string, namespaceFilters []string) SbomStats {
+	type imageAggregate struct {
+		stats      ImagePackageStats
+		clusters   map[string]bool
+		namespaces map[string]bool
+	}
+	type namespaceAggregate struct {
+		stats  NamespacePackageStats
+		images map[string]bool
+	}
+	byImage := make(map[string]*imageAggregate)
+	byNamespace := make(map[string]*namespaceAggregate)
+
+	for _, kind := range h.crdReg.GetAllReports() {
+		for _, report := range h.cache.GetReports(kind.Name, clusterFilter, namespaceFilters) {
+			data, ok := report.Data.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			counts := kubernetes.PackageTypeCounts(data)
+			if counts == nil {
+				continue
+			}
+			image := reportImageRef(report)
+			if image == "" {
+				image = report.Name
+			}
+
+			img, ok := byImage[image]
+			if !ok {
+				img = &imageAggregate{
+					stats:      ImagePackageStats{Image: image, PackageTypeStats: PackageTypeStats{PackageTypes: make(map[string]int)}},
+					clusters:   make(map[string]bool),
+					namespaces: make(map[string]bool),
+				}
+				img.stats.add(counts)
+				byImage[image] = img
+			}
+			img.clusters[report.Cluster] = true
+			if report.Namespace != "" {
+				img.namespaces[report.Namespace] = true
+			}
+
+			nsKey := report.Cluster + "/" + report.Namespace
+			ns, ok := byNamespace[nsKey]
+			if !ok {
+				ns = &namespaceAggregate{
+					stats:  NamespacePackageStats{Cluster: report.Cluster, Namespace: report.Namespace, PackageTypeStats: PackageTypeStats{PackageTypes: make(map[string]int)}},
+					images: make(map[string]bool),
+				}
+				byNamespace[nsKey] = ns
+			}
+			if !ns.images[image] {
+				ns.images[image] = true
+				ns.stats.add(counts)
+			}
+		}
+	}
+
+	result := SbomStats{
+		Images:     make([]ImagePackageStats, 0, len(byImage)),
+		Namespaces: make([]NamespacePackageStats, 0, len(byNamespace)),
+		Total:      PackageTypeStats{PackageTypes: make(map[string]int)},
+	}
+	for _, img := range byImage {
+		img.stats.Clusters = sortedKeys(img.clusters)
+		img.stats.Namespaces = sortedKeys(img.namespaces)
+		result.Total.add(img.stats.PackageTypes)
+		result.Images = append(result.Images, img.stats)
+	}
+	for _, ns := range byNamespace {
+		ns.stats.Images = len(ns.images)
+		result.Namespaces = append(result.Namespaces, ns.stats)
+	}
+	sort.Slice(result.Images, func(i, j int) bool {
+		if result.Images[i].Total != result.Images[j].Total {
+			return result.Images[i].Total > result.Images[j].Total
+		}
+		return result.Images[i].Image < result.Images[j].Image
+	})
+	sort.Slice(result.Namespaces, func(i, j int) bool {
+		if result.Namespaces[i].Total != result.Namespaces[j].Total {
+			return result.Namespaces[i].Total > result.Namespaces[j].Total
+		}
+		if result.Namespaces[i].Cluster != result.Namespaces[j].Cluster {
+			return result.Namespaces[i].Cluster < result.Namespaces[j].Cluster
+		}
+		return result.Namespaces[i].Namespace < result.Namespaces[j].Namespace
+	})
+	return result
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ExportSbom handles GET /api/v1/export/sbom/{namespace}/{name}: the SBOM of an
+// sbomreport, from the detail cache or the cluster, as a CycloneDX (the default) or SPDX
+// JSON download. cluster picks the report when several clusters have one of that name.
+func (h *Handler) ExportSbom(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = sbom.FormatCycloneDX
+	}
+	if !slices.Contains(sbom.Formats, format) {
+		writeError(w, http.StatusBadRequest, "Invalid format, expected "+strings.Join(sbom.Formats, " or "))
+		return
+	}
+	reportKind := h.crdReg.ResolveReport("sbomreports")
+	if reportKind == nil {
+		writeError(w, http.StatusNotFound, "SBOM reports are not available")
+		return
+	}
+
+	cluster := r.URL.Query().Get("cluster")
+	keys := h.cache.FindReportKeys(reportKind.Name, cluster, namespace, name)
+	if len(keys) > 1 {
+		writeAmbiguousReport(w, keys)
+		return
+	}
+	if len(keys) == 1 {
+		cluster, _, _, _, _ = parseReportCacheKey(keys[0])
+	}
+	if cluster == "" {
+		writeError(w, http.StatusNotFound, "Report not found")
+		return
+	}
+
+	report, err := h.loadReportDetail(r.Context(), *reportKind, cluster, namespace, name)
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		if errors.Is(err, errClusterClientNotFound) {
+			writeError(w, http.StatusNotFound, "Cluster client not found")
+			return
+		}
+		if apierrors.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "Report not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to fetch report details")
+		return
+	}
+	data, _ := report.Data.(map[string]interface{})
+	reportObj, _ := data["report"].(map[string]interface{})
+	bom, ok := reportObj["components"].(map[string]interface{})
+	if !ok {
+		// pushed clusters send summaries without components
+		writeError(w, http.StatusNotFound, "Report has no SBOM components")
+		return
+	}
+
+	var doc interface{}
+	var filename, contentType string
+	switch format {
+	case sbom.FormatCycloneDX:
+		doc = sbom.CycloneDX(bom)
+		filename, contentType = name+".cdx.json", "application/vnd.cyclonedx+json"
+	case sbom.FormatSPDX:
+		image := reportImageRef(report)
+		if image == "" {
+			image = name
+		}
+		created := report.ScannedAt
+		if created.IsZero() {
+			created = time.Now()
+		}
+		doc = sbom.SPDX(bom, sbom.DocumentInfo{
+			Name:      image,
+			Namespace: sbomDocumentNamespace(cluster, namespace, name, report.ResourceVersion, created),
+			Creator:   "trivy-ui",
+			Created:   created,
+		})
+		filename, contentType = name+".spdx.json", "application/spdx+json"
+	}
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to encode SBOM")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// sbomDocumentNamespace is the SPDX document namespace of a report's SBOM, unique per
+// version of the report: its resourceVersion, or its scan time when that is unknown.
+func sbomDocumentNamespace(cluster, namespace, name, version string, created time.Time) string {
+	if version == "" {
+		version = fmt.Sprint(created.Unix())
+	}
+	return "https://trivy-ui/spdx/" + url.PathEscape(cluster) + "/" + url.PathEscape(namespace) + "/" +
+		url.PathEscape(name) + "-" + url.PathEscape(version)
+}
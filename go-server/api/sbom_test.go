@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+	"trivy-ui/sbom"
+)
+
+func sbomReport(name, cluster, ns, repository string, types map[string]interface{}) Report {
+	return Report{Name: name, Cluster: cluster, Namespace: ns, Type: "sbomreports", Data: map[string]interface{}{
+		"report": map[string]interface{}{
+			"artifact":     map[string]interface{}{"repository": repository, "tag": "1.0"},
+			"packageTypes": types,
+		},
+	}}
+}
+
+func TestComputeSbomStats(t *testing.T) {
+	config.GetGlobalRegistry().Register(config.ReportKind{Name: "sbomreports", Namespaced: true})
+	cache := &stubCacheService{reports: map[string][]Report{"sbomreports": {
+		sbomReport("api-1", "c1", "apps", "org/api", map[string]interface{}{"npm": 10, "os-pkgs": 5}),
+		sbomReport("api-2", "c1", "apps", "org/api", map[string]interface{}{"npm": 10, "os-pkgs": 5}),
+		sbomReport("worker", "c1", "jobs", "org/worker", map[string]interface{}{"pip": 3, "os-pkgs": 4}),
+		sbomReport("api-3", "c2", "apps", "org/api", map[string]interface{}{"npm": 10, "os-pkgs": 5}),
+	}}}
+	h := &Handler{cache: cache, crdReg: config.GetGlobalRegistry()}
+
+	stats := h.computeSbomStats("", nil)
+	if len(stats.Images) != 2 || stats.Images[0].Image != "org/api:1.0" || len(stats.Images[0].Clusters) != 2 {
+		t.Fatalf("unexpected images %+v", stats.Images)
+	}
+	if stats.Total.PackageTypes["os-pkgs"] != 9 || stats.Total.Total != 22 {
+		t.Fatalf("images must be counted once in the total: %+v", stats.Total)
+	}
+	if len(stats.Namespaces) != 3 {
+		t.Fatalf("unexpected namespaces %+v", stats.Namespaces)
+	}
+	for _, ns := range stats.Namespaces {
+		if ns.Cluster == "c1" && ns.Namespace == "apps" && (ns.Images != 1 || ns.PackageTypes["npm"] != 10) {
+			t.Fatalf("workloads sharing an image must count once: %+v", ns)
+		}
+	}
+}
+
+func TestExportSbom(t *testing.T) {
+	c := useTestCache(t)
+	config.GetGlobalRegistry().Register(config.ReportKind{Name: "sbomreports", Namespaced: true})
+	detail := sbomReport("replicaset-api", "c1", "apps", "org/api", nil)
+	detail.ResourceVersion = "42"
+	detail.Data.(map[string]interface{})["report"].(map[string]interface{})["components"] = map[string]interface{}{
+		"bomFormat":   "CycloneDX",
+		"specVersion": "1.5",
+		"metadata":    map[string]interface{}{"component": map[string]interface{}{"bom-ref": "root", "type": "container", "name": "org/api"}},
+		"components": []interface{}{
+			map[string]interface{}{"bom-ref": "pkg:npm/lodash@4.17.21", "type": "library", "name": "lodash", "version": "4.17.21", "purl": "pkg:npm/lodash@4.17.21"},
+		},
+	}
+	c.Set(reportKey("c1", "apps", "sbomreports", "replicaset-api"), sbomReport("replicaset-api", "c1", "apps", "org/api", nil), 0)
+	SetReportDetail(detail)
+	h := &Handler{cache: &CacheServiceImpl{cache: c}, crdReg: config.GetGlobalRegistry(), clusterReg: NewClusterRegistry(nil)}
+
+	export := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ExportSbom(rec, httptest.NewRequest(http.MethodGet, "/api/v1/export/sbom/apps/replicaset-api?"+query, nil), "apps", "replicaset-api")
+		return rec
+	}
+
+	rec := export("")
+	var bom map[string]interface{}
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/vnd.cyclonedx+json" || json.Unmarshal(rec.Body.Bytes(), &bom) != nil {
+		t.Fatalf("unexpected CycloneDX response %d %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	if components, _ := bom["components"].([]interface{}); len(components) != 1 || !strings.Contains(rec.Header().Get("Content-Disposition"), "replicaset-api.cdx.json") {
+		t.Fatalf("unexpected CycloneDX document %v", bom)
+	}
+
+	rec = export("format=spdx")
+	var doc sbom.Document
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/spdx+json" || json.Unmarshal(rec.Body.Bytes(), &doc) != nil {
+		t.Fatalf("unexpected SPDX response %d: %s", rec.Code, rec.Body)
+	}
+	if doc.Name != "org/api:1.0" || doc.DocumentNamespace != "https://trivy-ui/spdx/c1/apps/replicaset-api-42" || len(doc.Packages) != 2 {
+		t.Fatalf("unexpected SPDX document %+v", doc)
+	}
+
+	if rec := export("format=xml"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown format rejected, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.ExportSbom(rec, httptest.NewRequest(http.MethodGet, "/api/v1/export/sbom/apps/missing", nil), "apps", "missing")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown report, got %d", rec.Code)
+	}
+	if rec := export("cluster=gone"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown cluster, got %d", rec.Code)
+	}
+	if err := h.clusterReg.SetSource("files", kubernetes.NewMemorySource()); err != nil {
+		t.Fatal(err)
+	}
+	if rec := export("cluster=files"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a report missing from the cluster, got %d: %s", rec.Code, rec.Body)
+	}
+}
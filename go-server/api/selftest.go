@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/export"
+	"trivy-ui/issues"
+	"trivy-ui/store"
+)
+
+// Self-test check statuses.
+const (
+	SelftestPass = "pass"
+	SelftestFail = "fail"
+	SelftestSkip = "skip"
+	// SelftestWarn reports a degraded subsystem that does not fail the run
+	SelftestWarn = "warn"
+)
+
+// selftestTimeout bounds every single check, so one unreachable endpoint cannot stall the run.
+const selftestTimeout = 5 * time.Second
+
+type SelftestCheck struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+type SelftestReport struct {
+	Passed bool            `json:"passed"`
+	Checks []SelftestCheck `json:"checks"`
+}
+
+// errSkipped marks a check that does not apply to this configuration.
+type errSkipped string
+
+func (e errSkipped) Error() string { return string(e) }
+
+// errWarning marks a check that passed with a problem worth fixing.
+type errWarning string
+
+func (e errWarning) Error() string { return string(e) }
+
+type selftestRunner struct {
+	cache      CacheService
+	clusterReg *ClusterRegistry
+	crdReg     *config.CRDRegistry
+	cfg        *config.Config
+	st         *store.Store
+	httpClient *http.Client
+	report     SelftestReport
+}
+
+// RunSelftest exercises every configured subsystem (cluster connectivity, CRD discovery,
+// database schema, cache round trip, webhook and issue tracker reachability) and reports
+// each outcome. Skipped checks and warnings do not fail the run.
+func RunSelftest(ctx context.Context, cache CacheService, clusterReg *ClusterRegistry, crdReg *config.CRDRegistry) SelftestReport {
+	r := &selftestRunner{
+		cache:      cache,
+		clusterReg: clusterReg,
+		crdReg:     crdReg,
+		cfg:        config.Get(),
+		st:         store.Get(),
+		httpClient: &http.Client{Timeout: selftestTimeout},
+	}
+	return r.run(ctx)
+}
+
+func (r *selftestRunner) run(ctx context.Context) SelftestReport {
+	r.report = SelftestReport{Passed: true, Checks: []SelftestCheck{}}
+
+	r.check(ctx, "database", r.checkDatabase)
+	r.check(ctx, "cache", r.checkCache)
+	r.check(ctx, "crd-discovery", r.checkCRDs)
+	r.checkClusters(ctx)
+	r.checkWebhooks(ctx)
+	r.check(ctx, "issue-tracker", r.checkIssueTracker)
+	return r.report
+}
+
+func (r *selftestRunner) check(ctx context.Context, name string, fn func(context.Context) (string, error)) {
+	checkCtx, cancel := context.WithTimeout(ctx, selftestTimeout)
+	defer cancel()
+	start := time.Now()
+	detail, err := fn(checkCtx)
+	result := SelftestCheck{Name: name, Status: SelftestPass, Detail: detail, DurationMs: time.Since(start).Milliseconds()}
+	var skipped errSkipped
+	var warning errWarning
+	switch {
+	case errors.As(err, &skipped):
+		result.Status, result.Detail = SelftestSkip, skipped.Error()
+	case errors.As(err, &warning):
+		result.Status, result.Detail = SelftestWarn, warning.Error()
+	case err != nil:
+		result.Status, result.Detail = SelftestFail, err.Error()
+		r.report.Passed = false
+	}
+	r.report.Checks = append(r.report.Checks, result)
+}
+
+func (r *selftestRunner) checkDatabase(ctx context.Context) (string, error) {
+	if r.st == nil {
+		return "", fmt.Errorf("database %s could not be opened, persistence features are disabled", r.cfg.DBPath)
+	}
+	if err := r.st.Ping(ctx); err != nil {
+		return "", fmt.Errorf("database not reachable: %v", err)
+	}
+	current, latest, err := r.st.SchemaVersion(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read schema version: %v", err)
+	}
+	if current != latest {
+		return "", fmt.Errorf("schema version %d, expected %d", current, latest)
+	}
+	return fmt.Sprintf("%s, schema version %d", r.st.Path(), current), nil
+}
+
+func (r *selftestRunner) checkCache(ctx context.Context) (string, error) {
+	if r.cache == nil {
+		return "", fmt.Errorf("cache not initialised")
+	}
+	key := fmt.Sprintf("selftest:%d", time.Now().UnixNano())
+	r.cache.Set(key, key, time.Minute)
+	defer r.cache.Delete(key)
+	value, ok := r.cache.Get(key)
+	if !ok {
+		return "", fmt.Errorf("written entry not found")
+	}
+	if value != key {
+		return "", fmt.Errorf("written entry read back as %v", value)
+	}
+	return fmt.Sprintf("%d entries", len(r.cache.Items())), nil
+}
+
+func (r *selftestRunner) checkCRDs(ctx context.Context) (string, error) {
+	if r.cfg.IngestMode != config.IngestModeKubernetes {
+		return "", errSkipped("ingest mode " + r.cfg.IngestMode + " does not discover CRDs")
+	}
+	if !r.crdReg.IsDiscovered() {
+		return "", fmt.Errorf("trivy-operator CRDs not discovered; is trivy-operator installed?")
+	}
+	kinds := r.crdReg.GetAllReports()
+	if len(kinds) == 0 {
+		return "", fmt.Errorf("no report kinds discovered")
+	}
+	return fmt.Sprintf("%d report kinds", len(kinds)), nil
+}
+
+func (r *selftestRunner) checkClusters(ctx context.Context) {
+	var clients map[string]*ClusterClient
+	if r.clusterReg != nil {
+		clients = r.clusterReg.All()
+	}
+	if len(clients) == 0 {
+		r.check(ctx, "clusters", func(context.Context) (string, error) {
+			if r.cfg.IngestMode != config.IngestModeKubernetes || r.cfg.AgentPushEnabled() {
+				return "", errSkipped("no clusters registered yet")
+			}
+			return "", fmt.Errorf("no cluster clients; check KUBECONFIG_DIR and the service account")
+		})
+		return
+	}
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cc := clients[name]
+		r.check(ctx, "cluster:"+name, func(ctx context.Context) (string, error) {
+			if cc.Source == nil {
+				return "", errSkipped("reports pushed by an agent")
+			}
+			namespaces, err := cc.Source.GetNamespaces(ctx)
+			if err != nil {
+				return "", fmt.Errorf("%s: %v", cc.APIServerURL, err)
+			}
+			return fmt.Sprintf("%s, %d namespaces", cc.APIServerURL, len(namespaces)), nil
+		})
+		if cc.Client != nil {
+			r.check(ctx, "trivy-db:"+name, func(context.Context) (string, error) {
+				return checkTrivyDB(trivyDBStatus(name, trivyDBFor(name), r.cfg.TrivyDBMaxAge, time.Now()))
+			})
+		}
+	}
+}
+
+func checkTrivyDB(status TrivyDBStatus) (string, error) {
+	switch {
+	case !status.Found:
+		return "", errSkipped("no Trivy DB version or update time published by the operator")
+	case status.Stale:
+		return "", errWarning(status.Warning)
+	case status.UpdatedAt != nil:
+		return fmt.Sprintf("version %s, updated %s", status.Version, status.UpdatedAt.Format(time.RFC3339)), nil
+	default:
+		return "version " + status.Version, nil
+	}
+}
+
+// checkWebhooks checks that the target of every enabled webhook export answers. Any HTTP
+// response counts; the endpoint may well reject an empty HEAD request.
+func (r *selftestRunner) checkWebhooks(ctx context.Context) {
+	if r.st == nil {
+		return
+	}
+	listCtx, cancel := storeCallContext(ctx)
+	schedules, err := r.st.ListExportSchedules(listCtx)
+	cancel()
+	if err != nil {
+		r.check(ctx, "webhooks", func(context.Context) (string, error) {
+			return "", fmt.Errorf("failed to list export schedules: %v", err)
+		})
+		return
+	}
+	for _, sched := range schedules {
+		if !sched.Enabled || sched.Destination != export.DestinationWebhook {
+			continue
+		}
+		target := sched.Target
+		r.check(ctx, "webhook:"+sched.Name, func(ctx context.Context) (string, error) {
+			return r.probe(ctx, target)
+		})
+	}
+}
+
+func (r *selftestRunner) checkIssueTracker(ctx context.Context) (string, error) {
+	if r.cfg.IssueProvider == "" {
+		return "", errSkipped("ISSUE_PROVIDER not set")
+	}
+	apiURL, err := issues.APIURL(r.cfg.IssueProvider, r.cfg.IssueAPIURL)
+	if err != nil {
+		return "", err
+	}
+	return r.probe(ctx, apiURL)
+}
+
+func (r *selftestRunner) probe(ctx context.Context, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return fmt.Sprintf("%s answered %d", target, resp.StatusCode), nil
+}
+
+// GetSelftest handles GET /api/v1/admin/selftest.
+func (h *Handler) GetSelftest(w http.ResponseWriter, r *http.Request) {
+	report := RunSelftest(r.Context(), h.cache, h.clusterReg, h.crdReg)
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    report,
+	})
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/export"
+	"trivy-ui/store"
+)
+
+type mapCacheService struct {
+	stubCacheService
+	items map[string]interface{}
+}
+
+func (s *mapCacheService) Get(key string) (interface{}, bool) {
+	v, ok := s.items[key]
+	return v, ok
+}
+func (s *mapCacheService) Items() map[string]interface{} { return s.items }
+func (s *mapCacheService) Set(key string, value interface{}, _ time.Duration) {
+	s.items[key] = value
+}
+func (s *mapCacheService) Delete(key string) { delete(s.items, key) }
+
+func TestSelftest(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer hook.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	for name, target := range map[string]string{"up": hook.URL, "down": down.URL} {
+		if _, err := st.CreateExportSchedule(t.Context(), store.ExportSchedule{
+			Name: name, Cron: "@daily", Format: "json", Destination: export.DestinationWebhook, Target: target, Enabled: true,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := &mapCacheService{items: map[string]interface{}{}}
+	clusterReg := NewClusterRegistry(cache)
+	clusterReg.RegisterPushed("edge", "v1.30.0", []string{"default"})
+	crdReg := config.GetGlobalRegistry()
+	crdReg.Register(config.ReportKind{Name: "vulnerabilityreports", Kind: "VulnerabilityReport", Namespaced: true})
+
+	r := &selftestRunner{
+		cache:      cache,
+		clusterReg: clusterReg,
+		crdReg:     crdReg,
+		cfg:        &config.Config{IngestMode: config.IngestModeKubernetes},
+		st:         st,
+		httpClient: &http.Client{Timeout: time.Second},
+	}
+	report := r.run(context.Background())
+
+	statuses := make(map[string]string)
+	for _, c := range report.Checks {
+		statuses[c.Name] = c.Status
+	}
+	want := map[string]string{
+		"database":      SelftestPass,
+		"cache":         SelftestPass,
+		"crd-discovery": SelftestPass,
+		"cluster:edge":  SelftestSkip,
+		"webhook:up":    SelftestPass,
+		"webhook:down":  SelftestFail,
+		"issue-tracker": SelftestSkip,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("check %s = %q, want %q (report %+v)", name, statuses[name], status, report.Checks)
+		}
+	}
+	if report.Passed {
+		t.Error("expected the unreachable webhook to fail the self-test")
+	}
+	for key := range cache.items {
+		if strings.HasPrefix(key, "selftest:") {
+			t.Errorf("cache check left %s behind", key)
+		}
+	}
+}
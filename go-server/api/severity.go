@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/cvss"
+	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
+)
+
+const namespaceLabelsTTL = 5 * time.Minute
+
+var (
+	severityRulesOnce sync.Once
+	severityRulesMu   sync.RWMutex
+	severityRules     cvss.Rules
+
+	nsLabelsMu sync.Mutex
+	nsLabels   = make(map[string]*namespaceLabels)
+)
+
+type namespaceLabels struct {
+	mu        sync.Mutex
+	labels    map[string]map[string]string
+	fetchedAt time.Time
+}
+
+var severityRank = map[string]int{"NONE": 0, "UNKNOWN": 0, "LOW": 1, "MEDIUM": 2, "HIGH": 3, "CRITICAL": 4}
+
+// getSeverityRules loads SEVERITY_RULES_FILE once, and again on a config reload; an
+// invalid file disables recalibration.
+func getSeverityRules() cvss.Rules {
+	severityRulesOnce.Do(func() {
+		path := config.Get().SeverityRulesFile
+		if path == "" {
+			return
+		}
+		rules, err := cvss.LoadRules(path)
+		if err != nil {
+			utils.LogWarning("Failed to load severity rules, effective severity disabled", map[string]interface{}{"path": path, "error": err.Error()})
+			return
+		}
+		setSeverityRules(rules)
+	})
+	severityRulesMu.RLock()
+	defer severityRulesMu.RUnlock()
+	return severityRules
+}
+
+func setSeverityRules(rules cvss.Rules) {
+	severityRulesOnce.Do(func() {})
+	severityRulesMu.Lock()
+	severityRules = rules
+	severityRulesMu.Unlock()
+}
+
+// namespaceLabelsFor returns a namespace's labels from a per-cluster cache refreshed every
+// few minutes. Pushed clusters have no client and therefore no labels.
+func namespaceLabelsFor(cluster, namespace string) map[string]string {
+	cc := GetClusterClient(cluster)
+	if cc == nil || cc.Client == nil || namespace == "" {
+		return nil
+	}
+
+	nsLabelsMu.Lock()
+	entry := nsLabels[cluster]
+	if entry == nil {
+		entry = &namespaceLabels{}
+		nsLabels[cluster] = entry
+	}
+	nsLabelsMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if time.Since(entry.fetchedAt) > namespaceLabelsTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		labels, err := cc.Client.GetNamespaceLabels(ctx)
+		cancel()
+		if err != nil {
+			utils.LogWarning("Failed to fetch namespace labels", map[string]interface{}{"cluster": cluster, "error": err.Error()})
+		} else {
+			entry.labels = labels
+		}
+		// also back off after failures
+		entry.fetchedAt = time.Now()
+	}
+	return entry.labels[namespace]
+}
+
+// severityModifiers returns the environmental CVSS modifiers for a namespace, or nil.
+func severityModifiers(cluster, namespace string) map[string]string {
+	rules := getSeverityRules()
+	if len(rules) == 0 {
+		return nil
+	}
+	return rules.ModifiersFor(namespaceLabelsFor(cluster, namespace))
+}
+
+// effectiveSeverity re-rates findings with the namespace's modifiers and returns the
+// highest effective severity and per-severity totals.
+func effectiveSeverity(findings []kubernetes.Finding, mods map[string]string) (string, SeverityTotals) {
+	highest := "NONE"
+	var totals SeverityTotals
+	for _, f := range findings {
+		_, sev := cvss.Effective(f.CVSSVector, strings.ToUpper(f.Severity), mods)
+		switch sev {
+		case "CRITICAL":
+			totals.Critical++
+		case "HIGH":
+			totals.High++
+		case "MEDIUM":
+			totals.Medium++
+		case "LOW":
+			totals.Low++
+		}
+		if severityRank[sev] > severityRank[highest] {
+			highest = sev
+		}
+	}
+	return highest, totals
+}
+
+// annotateEffectiveSeverity adds effectiveScore and effectiveSeverity to every
+// vulnerability of a detail report whose namespace has modifiers.
+func annotateEffectiveSeverity(report Report) {
+	mods := severityModifiers(report.Cluster, report.Namespace)
+	if len(mods) == 0 {
+		return
+	}
+	data, ok := report.Data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	section, ok := data["report"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	vulns, ok := section["vulnerabilities"].([]interface{})
+	if !ok {
+		return
+	}
+	for _, v := range vulns {
+		vm, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		severity, _ := vm["severity"].(string)
+		if _, overridden := vm["originalSeverity"]; overridden {
+			// severity overrides are not re-rated
+			vm["effectiveSeverity"] = severity
+			continue
+		}
+		score, effective := cvss.Effective(kubernetes.CVSSVector(vm), strings.ToUpper(severity), mods)
+		if score > 0 {
+			vm["effectiveScore"] = score
+		}
+		vm["effectiveSeverity"] = effective
+	}
+}
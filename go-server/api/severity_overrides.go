@@ -0,0 +1,367 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"trivy-ui/kubernetes"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// overridableSeverities are the severities an override can set.
+var overridableSeverities = map[string]bool{"CRITICAL": true, "HIGH": true, "MEDIUM": true, "LOW": true, "UNKNOWN": true}
+
+// SeverityOverrideHit is a finding of a report whose severity an override replaced.
+type SeverityOverrideHit struct {
+	CVE      string `json:"cve"`
+	Resource string `json:"resource,omitempty"`
+	Original string `json:"originalSeverity"`
+	Severity string `json:"severity"`
+}
+
+// severityOverrideCache holds the overrides by CVE, read from the store on first use.
+type severityOverrideCache struct {
+	mu        sync.Mutex
+	loaded    bool
+	overrides map[string]string
+}
+
+var severityOverrides = &severityOverrideCache{}
+
+func (c *severityOverrideCache) get() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		st := store.Get()
+		if st == nil {
+			return nil
+		}
+		ctx, cancel := storeCallContext(context.Background())
+		list, err := st.ListSeverityOverrides(ctx)
+		cancel()
+		if err != nil {
+			utils.LogWarning("Failed to load severity overrides", map[string]interface{}{"error": err.Error()})
+			return nil
+		}
+		c.overrides = make(map[string]string, len(list))
+		for _, o := range list {
+			c.overrides[o.CVE] = o.Severity
+		}
+		c.loaded = true
+	}
+	return c.overrides
+}
+
+// set records a changed override; an empty severity removes it. The map is replaced, not
+// changed, so callers can keep reading the one they got.
+func (c *severityOverrideCache) set(cve, severity string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		// loaded in full on the next read
+		return
+	}
+	next := make(map[string]string, len(c.overrides)+1)
+	for k, v := range c.overrides {
+		next[k] = v
+	}
+	if severity == "" {
+		delete(next, cve)
+	} else {
+		next[cve] = severity
+	}
+	c.overrides = next
+}
+
+// severityCountKey is the report summary key counting a severity.
+func severityCountKey(severity string) string {
+	return strings.ToLower(severity) + "Count"
+}
+
+func summaryCount(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
+
+// overriddenFindings returns the findings with overridden severities, and the overrides
+// that changed one. Overridden findings lose their CVSS vector, so SEVERITY_RULES_FILE
+// does not re-rate them away from the override.
+func overriddenFindings(findings []kubernetes.Finding, overrides map[string]string) ([]kubernetes.Finding, []SeverityOverrideHit) {
+	var hits []SeverityOverrideHit
+	var result []kubernetes.Finding
+	for i, f := range findings {
+		severity, ok := overrides[f.VulnerabilityID]
+		original := strings.ToUpper(f.Severity)
+		if !ok || severity == original {
+			continue
+		}
+		if result == nil {
+			result = append([]kubernetes.Finding(nil), findings...)
+		}
+		result[i].Severity, result[i].CVSSVector = severity, ""
+		hits = append(hits, SeverityOverrideHit{CVE: f.VulnerabilityID, Resource: f.Resource, Original: original, Severity: severity})
+	}
+	if result == nil {
+		return findings, nil
+	}
+	return result, hits
+}
+
+// overrideSummary returns a copy of a report summary with the counts of overridden
+// findings moved to their new severity.
+func overrideSummary(summary map[string]interface{}, hits []SeverityOverrideHit) map[string]interface{} {
+	result := make(map[string]interface{}, len(summary))
+	for k, v := range summary {
+		result[k] = v
+	}
+	for _, hit := range hits {
+		from, to := severityCountKey(hit.Original), severityCountKey(hit.Severity)
+		if summaryCount(result[from]) > 0 {
+			result[from] = summaryCount(result[from]) - 1
+		}
+		result[to] = summaryCount(result[to]) + 1
+	}
+	return result
+}
+
+// applySeverityOverrides rewrites the summary of a cached report for the overrides that
+// match its findings, keeping the scanner's counts as report.originalSummary, and returns
+// the findings with their overridden severities. Summaries are recomputed from the
+// original counts, so applying again after an override changed is safe. The report's data
+// is copied, not changed, as it is shared with the informer's store.
+func applySeverityOverrides(report *Report, findings []kubernetes.Finding, overrides map[string]string) []kubernetes.Finding {
+	findings, hits := overriddenFindings(findings, overrides)
+	data, ok := report.Data.(map[string]interface{})
+	if !ok {
+		return findings
+	}
+	section, ok := data["report"].(map[string]interface{})
+	if !ok {
+		return findings
+	}
+	original, overridden := section["originalSummary"].(map[string]interface{})
+	if !overridden {
+		original, _ = section["summary"].(map[string]interface{})
+	}
+	if original == nil || (len(hits) == 0 && !overridden) {
+		return findings
+	}
+
+	dataCopy := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		dataCopy[k] = v
+	}
+	sectionCopy := make(map[string]interface{}, len(section)+1)
+	for k, v := range section {
+		sectionCopy[k] = v
+	}
+	dataCopy["report"] = sectionCopy
+	if len(hits) == 0 {
+		sectionCopy["summary"] = original
+		delete(sectionCopy, "originalSummary")
+	} else {
+		sectionCopy["summary"] = overrideSummary(original, hits)
+		sectionCopy["originalSummary"] = original
+	}
+	report.Data = dataCopy
+	report.SeverityOverrides = hits
+	report.Status = kubernetes.ExtractSummary(report.Type, dataCopy).Status
+	return findings
+}
+
+// annotateSeverityOverrides applies the overrides to a detail report in place: overridden
+// vulnerabilities get their new severity and keep the scanner's as originalSeverity, and
+// the summary is recounted with the original counts kept as report.originalSummary.
+func annotateSeverityOverrides(report *Report) {
+	overrides := severityOverrides.get()
+	if len(overrides) == 0 {
+		return
+	}
+	data, ok := report.Data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	section, ok := data["report"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	vulns, _ := section["vulnerabilities"].([]interface{})
+	var hits []SeverityOverrideHit
+	for _, v := range vulns {
+		vm, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := vm["vulnerabilityID"].(string)
+		original, _ := vm["severity"].(string)
+		original = strings.ToUpper(original)
+		severity, ok := overrides[id]
+		if !ok || severity == original {
+			continue
+		}
+		resource, _ := vm["resource"].(string)
+		vm["originalSeverity"] = original
+		vm["severity"] = severity
+		hits = append(hits, SeverityOverrideHit{CVE: id, Resource: resource, Original: original, Severity: severity})
+	}
+	if summary, ok := section["summary"].(map[string]interface{}); ok && len(hits) > 0 {
+		section["originalSummary"] = summary
+		section["summary"] = overrideSummary(summary, hits)
+		report.Status = kubernetes.ExtractSummary(report.Type, data).Status
+	}
+	report.SeverityOverrides = hits
+}
+
+// reapplySeverityOverrides recounts the cached reports in which a CVE is open after its
+// override changed, from the findings the store keeps, and returns how many changed.
+// Effective severities from SEVERITY_RULES_FILE follow at the report's next update, as
+// the store does not keep CVSS vectors.
+func reapplySeverityOverrides(ctx context.Context, st *store.Store, cve string) (int, error) {
+	cache := getCache()
+	if cache == nil {
+		return 0, nil
+	}
+	refs, err := st.ListAffectedReports(ctx, cve, "")
+	if err != nil {
+		return 0, err
+	}
+	overrides := severityOverrides.get()
+	updated := 0
+	for _, ref := range refs {
+		key := reportKey(ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName)
+		v, ok := cache.Get(key)
+		if !ok {
+			continue
+		}
+		report, ok := convertCacheValue[Report](v)
+		if !ok {
+			continue
+		}
+		stored, err := st.ReportFindings(ctx, ref.Cluster, ref.Namespace, []string{ref.ReportName})
+		if err != nil {
+			return updated, err
+		}
+		var findings []kubernetes.Finding
+		for _, f := range stored {
+			if f.ReportType == ref.ReportType && f.ResolvedAt == nil {
+				findings = append(findings, kubernetes.Finding{VulnerabilityID: f.FindingID, Severity: f.Severity, Resource: f.Resource})
+			}
+		}
+		applySeverityOverrides(&report, findings, overrides)
+		cache.Set(key, report, 7*24*time.Hour)
+		cache.Delete(reportDetailKey(ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName))
+		aggregates.invalidate(ref.Cluster)
+		updated++
+	}
+	return updated, nil
+}
+
+// SeverityOverrideResult is a changed override and the cached reports recounted for it.
+type SeverityOverrideResult struct {
+	store.SeverityOverride
+	ReportsUpdated int `json:"reportsUpdated"`
+}
+
+func (h *Handler) ListSeverityOverrides(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	overrides, err := st.ListSeverityOverrides(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    overrides,
+	})
+}
+
+// SetSeverityOverride handles POST /api/v1/severity-overrides and
+// PUT /api/v1/severity-overrides/{cve}, creating or replacing the override of a CVE.
+func (h *Handler) SetSeverityOverride(w http.ResponseWriter, r *http.Request, cve string) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	var o store.SeverityOverride
+	if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if cve != "" {
+		o.CVE = cve
+	}
+	o.CVE = strings.TrimSpace(o.CVE)
+	o.Severity = strings.ToUpper(strings.TrimSpace(o.Severity))
+	if o.CVE == "" || strings.ContainsAny(o.CVE, " /") {
+		writeError(w, http.StatusBadRequest, "Invalid CVE id")
+		return
+	}
+	if !overridableSeverities[o.Severity] {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid severity %q, expected CRITICAL, HIGH, MEDIUM, LOW or UNKNOWN", o.Severity))
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	saved, err := st.SetSeverityOverride(ctx, o)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	severityOverrides.set(saved.CVE, saved.Severity)
+	updated, err := reapplySeverityOverrides(ctx, st, saved.CVE)
+	if err != nil {
+		utils.LogWarning("Failed to recount reports for severity override", map[string]interface{}{"cve": saved.CVE, "error": err.Error()})
+	}
+	utils.LogInfo("Severity override set", map[string]interface{}{"cve": saved.CVE, "severity": saved.Severity, "reports": updated})
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    SeverityOverrideResult{SeverityOverride: saved, ReportsUpdated: updated},
+	})
+}
+
+// DeleteSeverityOverride handles DELETE /api/v1/severity-overrides/{cve}, restoring the
+// scanner's severity.
+func (h *Handler) DeleteSeverityOverride(w http.ResponseWriter, r *http.Request, cve string) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	err := st.DeleteSeverityOverride(ctx, cve)
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "Severity override not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	severityOverrides.set(cve, "")
+	if _, err := reapplySeverityOverrides(ctx, st, cve); err != nil {
+		utils.LogWarning("Failed to recount reports for severity override", map[string]interface{}{"cve": cve, "error": err.Error()})
+	}
+	utils.LogInfo("Severity override removed", map[string]interface{}{"cve": cve})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"trivy-ui/store"
+)
+
+func useSeverityOverrides(t *testing.T, overrides map[string]string) {
+	prev := severityOverrides
+	severityOverrides = &severityOverrideCache{loaded: true, overrides: overrides}
+	t.Cleanup(func() { severityOverrides = prev })
+}
+
+func TestSeverityOverridesRecountReports(t *testing.T) {
+	c := useTestCache(t)
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	ref := store.ReportRef{Cluster: "prod", Namespace: "shop", ReportType: "vulnerabilityreports", ReportName: "replicaset-app"}
+	err = st.SyncFindings(t.Context(), ref, []store.Finding{
+		{FindingID: "CVE-2024-0001", Resource: "openssl", Severity: "MEDIUM"},
+		{FindingID: "CVE-2024-0002", Resource: "curl", Severity: "HIGH"},
+	}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := map[string]interface{}{"criticalCount": float64(0), "highCount": float64(1), "mediumCount": float64(1)}
+	report := makeReport(ref.ReportName, ref.Cluster, ref.Namespace, ref.ReportType, 0)
+	report.Data.(map[string]interface{})["report"].(map[string]interface{})["summary"] = summary
+	report.Status = "High"
+	key := reportKey(ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName)
+	c.Set(key, report, 0)
+
+	useSeverityOverrides(t, map[string]string{"CVE-2024-0001": "CRITICAL"})
+	if n, err := reapplySeverityOverrides(t.Context(), st, "CVE-2024-0001"); err != nil || n != 1 {
+		t.Fatalf("reapply: %d %v", n, err)
+	}
+	v, _ := c.Get(key)
+	got, _ := convertCacheValue[Report](v)
+	if crit, high, medium, _ := extractSummaryCounts(got); crit != 1 || high != 1 || medium != 0 || got.Status != "Critical" {
+		t.Errorf("overridden report: %d/%d/%d %s", crit, high, medium, got.Status)
+	}
+	if len(got.SeverityOverrides) != 1 || got.SeverityOverrides[0].Original != "MEDIUM" || got.SeverityOverrides[0].Resource != "openssl" {
+		t.Errorf("hits = %+v", got.SeverityOverrides)
+	}
+	section := got.Data.(map[string]interface{})["report"].(map[string]interface{})
+	if section["originalSummary"].(map[string]interface{})["mediumCount"] != float64(1) || summary["criticalCount"] != float64(0) {
+		t.Errorf("original summary not kept: %v", section)
+	}
+
+	// removing the override restores the scanner's counts
+	useSeverityOverrides(t, map[string]string{})
+	reapplySeverityOverrides(t.Context(), st, "CVE-2024-0001")
+	v, _ = c.Get(key)
+	got, _ = convertCacheValue[Report](v)
+	section = got.Data.(map[string]interface{})["report"].(map[string]interface{})
+	if crit, _, medium, _ := extractSummaryCounts(got); crit != 0 || medium != 1 || got.Status != "High" || got.SeverityOverrides != nil || section["originalSummary"] != nil {
+		t.Errorf("restored report = %+v", got)
+	}
+}
+
+func TestAnnotateSeverityOverrides(t *testing.T) {
+	useSeverityOverrides(t, map[string]string{"CVE-2024-0001": "LOW"})
+	vuln := map[string]interface{}{"vulnerabilityID": "CVE-2024-0001", "severity": "HIGH", "resource": "openssl"}
+	report := Report{Type: "vulnerabilityreports", Data: map[string]interface{}{"report": map[string]interface{}{
+		"summary":         map[string]interface{}{"highCount": float64(1)},
+		"vulnerabilities": []interface{}{vuln},
+	}}}
+	annotateSeverityOverrides(&report)
+	if vuln["severity"] != "LOW" || vuln["originalSeverity"] != "HIGH" || report.Status != "Low" || len(report.SeverityOverrides) != 1 {
+		t.Errorf("annotated %v, status %s", vuln, report.Status)
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"testing"
+
+	"trivy-ui/kubernetes"
+)
+
+func TestEffectiveSeverity(t *testing.T) {
+	findings := []kubernetes.Finding{
+		{VulnerabilityID: "CVE-1", Severity: "CRITICAL", CVSSVector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		{VulnerabilityID: "CVE-2", Severity: "HIGH"},
+		{VulnerabilityID: "CVE-3", Severity: "LOW", CVSSVector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:R/S:U/C:L/I:N/A:N"},
+	}
+
+	highest, totals := effectiveSeverity(findings, nil)
+	if highest != "CRITICAL" || totals.Critical != 1 || totals.High != 1 || totals.Low != 1 {
+		t.Errorf("without modifiers got %s %+v", highest, totals)
+	}
+
+	highest, totals = effectiveSeverity(findings, map[string]string{"MAV": "A"})
+	if highest != "HIGH" || totals.Critical != 0 || totals.High != 2 || totals.Low != 1 {
+		t.Errorf("with MAV:A got %s %+v", highest, totals)
+	}
+}
+
+func TestSortReportsByEffectiveSeverity(t *testing.T) {
+	reports := []Report{
+		{Name: "a", EffectiveSeverity: "LOW"},
+		{Name: "b", EffectiveSeverity: "CRITICAL"},
+		{Name: "c"},
+		{Name: "d", EffectiveSeverity: "MEDIUM"},
+	}
+	sorted := sortReports(reports, "-effectiveSeverity")
+	got := []string{sorted[0].Name, sorted[1].Name, sorted[2].Name, sorted[3].Name}
+	want := []string{"b", "d", "a", "c"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got order %v, want %v", got, want)
+		}
+	}
+	if reports[0].Name != "a" {
+		t.Error("expected input slice to be left untouched")
+	}
+}
+
+func TestReportFilterEffectiveSeverity(t *testing.T) {
+	expr, err := parseReportFilter(`effectiveSeverity in ("CRITICAL", "HIGH")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expr.Eval(newReportRecord(Report{EffectiveSeverity: "HIGH"})) {
+		t.Error("expected HIGH to match")
+	}
+	if expr.Eval(newReportRecord(Report{EffectiveSeverity: "MEDIUM"})) {
+		t.Error("expected MEDIUM not to match")
+	}
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// Share link views: the matching reports, or only their severity totals.
+const (
+	ShareViewReports = "reports"
+	ShareViewSummary = "summary"
+)
+
+// shareDefaultTTL is how long a share link stays valid when the request gives no ttl.
+const shareDefaultTTL = 7 * 24 * time.Hour
+
+var (
+	errShareInvalid = errors.New("invalid share link")
+	errShareExpired = errors.New("share link expired")
+)
+
+// ShareScope is what a share link exposes. It is carried in the link's token, signed with
+// SHARE_LINK_SECRET, so the server keeps no state and the scope cannot be widened by
+// editing the link.
+type ShareScope struct {
+	View  string            `json:"view"`
+	Query store.ExportQuery `json:"query"`
+	// Name narrows the link to one report of the query's type
+	Name      string `json:"name,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+	// Nonce makes every link unique, even for the same scope and expiry
+	Nonce string `json:"nonce"`
+}
+
+// ShareRequest creates a share link for the reports matching its query.
+type ShareRequest struct {
+	store.ExportQuery
+	View string `json:"view"`
+	Name string `json:"name"`
+	// TTL is how long the link is valid, e.g. "72h" or "7d"; at most SHARE_LINK_MAX_TTL
+	TTL string `json:"ttl"`
+}
+
+// ShareLink is a created share link.
+type ShareLink struct {
+	Token     string     `json:"token"`
+	URL       string     `json:"url"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	Scope     ShareScope `json:"scope"`
+}
+
+// SharedView is what a share link shows.
+type SharedView struct {
+	View      string            `json:"view"`
+	Query     store.ExportQuery `json:"query"`
+	Name      string            `json:"name,omitempty"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+	Reports   []Report          `json:"reports,omitempty"`
+	Summary   *ShareSummary     `json:"summary,omitempty"`
+}
+
+// ShareSummary is the summary view of a share link: severity totals overall and per namespace.
+type ShareSummary struct {
+	Reports    int                       `json:"reports"`
+	Severity   SeverityTotals            `json:"severity"`
+	Namespaces map[string]SeverityTotals `json:"namespaces"`
+}
+
+func signShareScope(secret string, scope ShareScope) (string, error) {
+	payload, err := json.Marshal(scope)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + shareSignature(secret, encoded), nil
+}
+
+func shareSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyShareToken checks a token's signature and expiry and returns its scope.
+func verifyShareToken(secret, token string, now time.Time) (ShareScope, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(shareSignature(secret, payload))) {
+		return ShareScope{}, errShareInvalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ShareScope{}, errShareInvalid
+	}
+	var scope ShareScope
+	if err := json.Unmarshal(raw, &scope); err != nil {
+		return ShareScope{}, errShareInvalid
+	}
+	if now.Unix() >= scope.ExpiresAt {
+		return scope, errShareExpired
+	}
+	return scope, nil
+}
+
+// newShareScope validates a share request into the scope its link will carry.
+func (h *Handler) newShareScope(req ShareRequest, maxTTL time.Duration, now time.Time) (ShareScope, error) {
+	scope := ShareScope{View: req.View, Query: req.ExportQuery, Name: req.Name}
+	if scope.View == "" {
+		scope.View = ShareViewReports
+	}
+	if scope.View != ShareViewReports && scope.View != ShareViewSummary {
+		return scope, fmt.Errorf("view must be %s or %s", ShareViewReports, ShareViewSummary)
+	}
+	kind := h.crdReg.ResolveReport(scope.Query.Type)
+	if kind == nil {
+		return scope, fmt.Errorf("unknown report type %q", scope.Query.Type)
+	}
+	scope.Query.Type = kind.Name
+	if _, err := parseReportFilter(scope.Query.Filter); err != nil {
+		return scope, err
+	}
+
+	ttl := shareDefaultTTL
+	if req.TTL != "" {
+		parsed, err := config.ParseDuration(req.TTL)
+		if err != nil || parsed <= 0 {
+			return scope, fmt.Errorf("invalid ttl %q", req.TTL)
+		}
+		ttl = parsed
+	}
+	if ttl > maxTTL {
+		return scope, fmt.Errorf("ttl exceeds the maximum of %s", maxTTL)
+	}
+	scope.ExpiresAt = now.Add(ttl).Unix()
+
+	nonce := make([]byte, 9)
+	if _, err := rand.Read(nonce); err != nil {
+		return scope, err
+	}
+	scope.Nonce = base64.RawURLEncoding.EncodeToString(nonce)
+	return scope, nil
+}
+
+// sharedView builds what a share link shows from the current reports in its scope.
+func (h *Handler) sharedView(scope ShareScope) (SharedView, error) {
+	view := SharedView{View: scope.View, Query: scope.Query, Name: scope.Name, ExpiresAt: time.Unix(scope.ExpiresAt, 0).UTC()}
+	reports, err := exportReports(h.querySvc, scope.Query)
+	if err != nil {
+		return view, err
+	}
+	if scope.Name != "" {
+		kept := reports[:0]
+		for _, r := range reports {
+			if r.Name == scope.Name {
+				kept = append(kept, r)
+			}
+		}
+		reports = kept
+	}
+	if scope.View == ShareViewReports {
+		view.Reports = reports
+		return view, nil
+	}
+	summary := &ShareSummary{Reports: len(reports), Namespaces: make(map[string]SeverityTotals)}
+	for _, r := range reports {
+		c, hi, m, l := extractSummaryCounts(r)
+		summary.Severity.Critical += c
+		summary.Severity.High += hi
+		summary.Severity.Medium += m
+		summary.Severity.Low += l
+		ns := summary.Namespaces[r.Namespace]
+		ns.Critical += c
+		ns.High += hi
+		ns.Medium += m
+		ns.Low += l
+		summary.Namespaces[r.Namespace] = ns
+	}
+	view.Summary = summary
+	return view, nil
+}
+
+// CreateShareLink handles POST /api/v1/share: it signs a read-only link to the reports
+// matching a query, or to their summary, for people without an account.
+func (h *Handler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	cfg := config.Get()
+	if cfg.ShareLinkSecret == "" {
+		writeError(w, http.StatusNotFound, "Share links are disabled, set SHARE_LINK_SECRET")
+		return
+	}
+	var req ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	scope, err := h.newShareScope(req, cfg.ShareLinkMaxTTL, time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	token, err := signShareScope(cfg.ShareLinkSecret, h.demoShareScope(scope, false))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.LogInfo("Share link created", map[string]interface{}{
+		"view":      scope.View,
+		"type":      scope.Query.Type,
+		"cluster":   scope.Query.Cluster,
+		"expiresAt": scope.ExpiresAt,
+	})
+	writeJSON(w, http.StatusCreated, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    ShareLink{Token: token, URL: "/share/" + token, ExpiresAt: time.Unix(scope.ExpiresAt, 0).UTC(), Scope: scope},
+	})
+}
+
+// GetSharedView handles GET /share/{token}. The token is the only credential, so the
+// response is not cached and the token not passed on as a referrer. ?format=csv or json
+// downloads the reports instead.
+func (h *Handler) GetSharedView(w http.ResponseWriter, r *http.Request, token string) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	secret := config.Get().ShareLinkSecret
+	if secret == "" {
+		writeError(w, http.StatusNotFound, "Share link not found")
+		return
+	}
+	now := time.Now()
+	scope, err := verifyShareToken(secret, token, now)
+	switch {
+	case errors.Is(err, errShareExpired):
+		writeError(w, http.StatusGone, "Share link expired")
+		return
+	case err != nil:
+		writeError(w, http.StatusNotFound, "Share link not found")
+		return
+	}
+	view, err := h.sharedView(h.demoShareScope(scope, true))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		writeJSON(w, http.StatusOK, Response{
+			Code:    CodeSuccess,
+			Message: "Success",
+			Data:    view,
+		})
+		return
+	}
+	if scope.View != ShareViewReports || (format != ExportFormatCSV && format != ExportFormatJSON) {
+		writeError(w, http.StatusBadRequest, "format must be csv or json, for report links")
+		return
+	}
+	file, err := exportFile(view.Reports, scope.Query.Type, format, now)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	w.Write(file.Data)
+}
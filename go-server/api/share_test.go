@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/store"
+)
+
+func TestShareLinks(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := config.GetGlobalRegistry()
+	reg.Register(config.ReportKind{Name: "sharetestreports", ShortName: "shr", Namespaced: true})
+	h := NewHandler(nil, svc, NewClusterRegistry(svc), NewQueryService(svc), reg)
+	c.Set(reportKey("prod", "shop", "sharetestreports", "app"), makeReport("app", "prod", "shop", "sharetestreports", 2), 0)
+	c.Set(reportKey("prod", "shop", "sharetestreports", "db"), makeReport("db", "prod", "shop", "sharetestreports", 1), 0)
+	c.Set(reportKey("prod", "billing", "sharetestreports", "api"), makeReport("api", "prod", "billing", "sharetestreports", 5), 0)
+
+	now := time.Now()
+	req := ShareRequest{ExportQuery: store.ExportQuery{Type: "shr", Cluster: "prod", Namespaces: []string{"shop"}}, TTL: "2d"}
+	scope, err := h.newShareScope(req, 7*24*time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scope.View != ShareViewReports || scope.Query.Type != "sharetestreports" || scope.ExpiresAt != now.Add(48*time.Hour).Unix() {
+		t.Fatalf("scope = %+v", scope)
+	}
+	for _, bad := range []ShareRequest{
+		{ExportQuery: store.ExportQuery{Type: "unknown"}},
+		{ExportQuery: store.ExportQuery{Type: "shr"}, View: "raw"},
+		{ExportQuery: store.ExportQuery{Type: "shr"}, TTL: "30d"},
+		{ExportQuery: store.ExportQuery{Type: "shr", Filter: "critical >"}},
+	} {
+		if _, err := h.newShareScope(bad, 7*24*time.Hour, now); err == nil {
+			t.Errorf("request %+v accepted", bad)
+		}
+	}
+
+	token, err := signShareScope("secret", scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, err := verifyShareToken("secret", token, now)
+	if err != nil || verified.Nonce != scope.Nonce {
+		t.Fatalf("verify: %+v %v", verified, err)
+	}
+	if _, err := verifyShareToken("rotated", token, now); !errors.Is(err, errShareInvalid) {
+		t.Errorf("token verified with another secret: %v", err)
+	}
+	// widening the scope breaks the signature
+	payload, signature, _ := strings.Cut(token, ".")
+	widened, _ := signShareScope("other", ShareScope{View: ShareViewReports, Query: store.ExportQuery{Type: "sharetestreports"}, ExpiresAt: scope.ExpiresAt})
+	if _, err := verifyShareToken("secret", strings.Split(widened, ".")[0]+"."+signature, now); !errors.Is(err, errShareInvalid) {
+		t.Errorf("tampered token: %v", err)
+	}
+	if _, err := verifyShareToken("secret", payload+"."+signature, now.Add(49*time.Hour)); !errors.Is(err, errShareExpired) {
+		t.Errorf("expired token: %v", err)
+	}
+
+	view, err := h.sharedView(verified)
+	if err != nil || len(view.Reports) != 2 || view.Summary != nil {
+		t.Fatalf("reports view = %+v, %v", view, err)
+	}
+	verified.View, verified.Name = ShareViewSummary, "app"
+	view, err = h.sharedView(verified)
+	if err != nil || view.Reports != nil || view.Summary.Reports != 1 || view.Summary.Severity.Critical != 2 || view.Summary.Namespaces["shop"].Critical != 2 {
+		t.Fatalf("summary view = %+v, %v", view.Summary, err)
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// recordFindings persists the finding set of a vulnerability report so first-seen
+// timestamps survive report re-creation and restarts.
+func recordFindings(cluster, namespace, reportType, name string, findings []kubernetes.Finding) {
+	st := store.Get()
+	if st == nil {
+		return
+	}
+	ref := store.ReportRef{Cluster: cluster, Namespace: namespace, ReportType: reportType, ReportName: name}
+	stored := make([]store.Finding, 0, len(findings))
+	for _, f := range findings {
+		stored = append(stored, store.Finding{
+			ReportRef:        ref,
+			FindingID:        f.VulnerabilityID,
+			Resource:         f.Resource,
+			Severity:         strings.ToUpper(f.Severity),
+			InstalledVersion: f.InstalledVersion,
+			FixedVersion:     f.FixedVersion,
+		})
+	}
+	ctx, cancel := storeCallContext(context.Background())
+	defer cancel()
+	if err := st.SyncFindings(ctx, ref, stored, time.Now()); err != nil {
+		utils.LogWarning("Failed to record findings", map[string]interface{}{
+			"cluster":   cluster,
+			"namespace": namespace,
+			"type":      reportType,
+			"name":      name,
+			"error":     err.Error(),
+		})
+	}
+}
+
+// purgeNamespaceFindings resolves the open findings of a deleted namespace, including
+// those of reports that were no longer cached, and drops its report blobs.
+func purgeNamespaceFindings(cluster, namespace string) {
+	st := store.Get()
+	if st == nil {
+		return
+	}
+	ctx, cancel := storeCallContext(context.Background())
+	defer cancel()
+	if _, err := st.PurgeNamespace(ctx, cluster, namespace, time.Now()); err != nil {
+		utils.LogWarning("Failed to purge findings of deleted namespace", map[string]interface{}{
+			"cluster":   cluster,
+			"namespace": namespace,
+			"error":     err.Error(),
+		})
+	}
+}
+
+func countFixable(findings []kubernetes.Finding) int {
+	n := 0
+	for _, f := range findings {
+		if f.FixedVersion != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func (h *Handler) GetSLAOverdue(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := store.FindingFilter{Namespace: q.Get("namespace")}
+	if cluster := clusterParam(r); cluster != "" {
+		filter.Clusters = strings.Split(cluster, ",")
+	}
+	if sev := q.Get("severity"); sev != "" {
+		for _, s := range strings.Split(sev, ",") {
+			filter.Severities = append(filter.Severities, strings.ToUpper(strings.TrimSpace(s)))
+		}
+	}
+
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	overdue, err := st.ListOverdue(ctx, config.Get().SLAWindows, filter, time.Now())
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		utils.LogWarning("Failed to list overdue findings", map[string]interface{}{"error": err.Error()})
+		writeError(w, http.StatusInternalServerError, "Failed to list overdue findings")
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    overdue,
+	})
+}
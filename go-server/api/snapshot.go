@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"trivy-ui/credentials"
+	"trivy-ui/utils"
+)
+
+// SnapshotPath serves the cache to replicas bootstrapping from it.
+const SnapshotPath = "/internal/snapshot"
+
+// snapshotTimeout bounds downloading a snapshot at startup; a replica that cannot get
+// one in time lists its clusters instead
+const snapshotTimeout = 2 * time.Minute
+
+// snapshot returns the live cache entries in cache.json's format and how many there are.
+// The entries are copied under the lock and marshaled outside it, so writers are not held
+// up by a large cache.
+func (c *Cache) snapshot() ([]byte, int, error) {
+	c.mu.RLock()
+	now := time.Now().Unix()
+	items := make(map[string]CacheItem, len(c.items))
+	for k, item := range c.items {
+		if item.Expiration > now {
+			items[k] = item
+		}
+	}
+	c.mu.RUnlock()
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal cache data: %w", err)
+	}
+	return data, len(items), nil
+}
+
+// LoadSnapshot fills the cache with a snapshot of another replica's cache. Unlike entries
+// loaded from cache.json, they are saved at the next periodic save.
+func (c *Cache) LoadSnapshot(r io.Reader) (int, error) {
+	var items map[string]CacheItem
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return 0, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadItemsLocked(items)
+	return len(items), nil
+}
+
+// ServeSnapshot handles GET /internal/snapshot: the cache of this replica, for a new one
+// to start from. It needs SNAPSHOT_TOKEN as bearer token and is off without one.
+func (h *Handler) ServeSnapshot(w http.ResponseWriter, r *http.Request) {
+	token := credentials.Get(credentials.SnapshotToken)
+	if token == "" {
+		writeError(w, http.StatusNotFound, "Snapshots are not enabled")
+		return
+	}
+	if _, ok := (tokenAuthenticator{tokens: map[string]string{"replica": token}}).Authenticate(r); !ok {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	c := getCache()
+	if c == nil {
+		writeError(w, http.StatusServiceUnavailable, "Cache not initialized")
+		return
+	}
+	data, entries, err := c.snapshot()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.LogInfo("Serving cache snapshot", map[string]interface{}{
+		"entries": entries,
+		"bytes":   len(data),
+		"remote":  r.RemoteAddr,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// bootstrapCache loads the cache of peer, logging a failure: the replica then lists its
+// clusters as it would without a peer.
+func bootstrapCache(c *Cache, peer string) {
+	if err := c.bootstrapFromPeer(context.Background(), http.DefaultClient, peer, credentials.Get(credentials.SnapshotToken)); err != nil {
+		utils.LogWarning("Failed to load cache snapshot from peer", map[string]interface{}{"peer": peer, "error": err.Error()})
+	}
+}
+
+// bootstrapFromPeer loads the cache of the replica at peer, so a replica starting without
+// a cache.json serves right away. Its informers still list every cluster in the
+// background; only the wait for the first response is saved.
+func (c *Cache) bootstrapFromPeer(ctx context.Context, client *http.Client, peer, token string) error {
+	if token == "" {
+		return fmt.Errorf("SNAPSHOT_PEER needs SNAPSHOT_TOKEN")
+	}
+	ctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(peer, "/")+SnapshotPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("peer returned %s", resp.Status)
+	}
+
+	start := time.Now()
+	entries, err := c.LoadSnapshot(resp.Body)
+	if err != nil {
+		return err
+	}
+	utils.LogInfo("Loaded cache snapshot from peer", map[string]interface{}{
+		"peer":     peer,
+		"entries":  entries,
+		"duration": time.Since(start).String(),
+	})
+	return nil
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSnapshotBootstrap(t *testing.T) {
+	src := useTestCache(t)
+	src.Set(reportKey("a", "web", "vulnerabilityreports", "r1"), makeReport("r1", "a", "web", "vulnerabilityreports", 2), 0)
+	src.Set(reportKey("a", "db", "vulnerabilityreports", "r2"), makeReport("r2", "a", "db", "vulnerabilityreports", 1), 0)
+	h := &Handler{}
+	srv := httptest.NewServer(http.HandlerFunc(h.ServeSnapshot))
+	t.Cleanup(srv.Close)
+
+	newReplica := func() *Cache {
+		c, err := newCache(filepath.Join(t.TempDir(), "cache.json"), cacheMaxCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if err := newReplica().bootstrapFromPeer(context.Background(), srv.Client(), srv.URL, "secret"); err == nil {
+		t.Error("expected snapshots to be off without SNAPSHOT_TOKEN")
+	}
+	t.Setenv("SNAPSHOT_TOKEN", "secret")
+	if err := newReplica().bootstrapFromPeer(context.Background(), srv.Client(), srv.URL, "wrong"); err == nil {
+		t.Error("expected a wrong token rejected")
+	}
+
+	dst := newReplica()
+	if err := dst.bootstrapFromPeer(context.Background(), srv.Client(), srv.URL+"/", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if reports := dst.GetReports("vulnerabilityreports", "a", nil); len(reports) != 2 {
+		t.Fatalf("expected both reports, got %d", len(reports))
+	}
+	if rollup := dst.GetReportRollup("vulnerabilityreports", "a", []string{"web"}); rollup.Reports != 1 || rollup.Severity.Critical != 2 {
+		t.Errorf("expected the rollups rebuilt, got %+v", rollup)
+	}
+	if saved, err := dst.SaveIfChanged(); err != nil || !saved {
+		t.Errorf("expected the snapshot saved to cache.json, saved=%t err=%v", saved, err)
+	}
+
+	// snapshots are marshaled outside the lock while writers go on
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			name := fmt.Sprintf("w%d", i)
+			src.Set(reportKey("a", "web", "vulnerabilityreports", name), makeReport(name, "a", "web", "vulnerabilityreports", 1), 0)
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		if _, _, err := src.snapshot(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+var expectedClusters struct {
+	mu    sync.Mutex
+	names []string
+}
+
+// ExpectClusters records the clusters startup is connecting, so the startup progress can
+// list those whose client is not registered yet.
+func ExpectClusters(names []string) {
+	expectedClusters.mu.Lock()
+	defer expectedClusters.mu.Unlock()
+	expectedClusters.names = append([]string(nil), names...)
+}
+
+// ClusterStartup is the initial sync progress of one cluster. Clusters fed by an agent
+// or report files have no informers and are either synced or not.
+type ClusterStartup struct {
+	Name        string                        `json:"name"`
+	SyncState   string                        `json:"syncState"`
+	KindsTotal  int                           `json:"kindsTotal"`
+	KindsSynced int                           `json:"kindsSynced"`
+	Percent     int                           `json:"percent"`
+	Kinds       []kubernetes.KindSyncProgress `json:"kinds,omitempty"`
+}
+
+type StartupStatus struct {
+	WarmupCompleted bool             `json:"warmupCompleted"`
+	CRDsDiscovered  bool             `json:"crdsDiscovered"`
+	ReportKinds     int              `json:"reportKinds"`
+	ClustersTotal   int              `json:"clustersTotal"`
+	ClustersSynced  int              `json:"clustersSynced"`
+	Percent         int              `json:"percent"`
+	Clusters        []ClusterStartup `json:"clusters"`
+}
+
+func clusterStartup(cc *ClusterClient, reportKinds int) ClusterStartup {
+	info := cc.info()
+	status := ClusterStartup{Name: info.Name, SyncState: info.SyncState}
+	var informer *kubernetes.ReportInformerManager
+	if cc.Client != nil {
+		informer = cc.Client.GetInformer()
+	}
+	if informer == nil {
+		if cc.Client == nil && status.SyncState == "FullySynced" {
+			status.Percent = 100
+		}
+		return status
+	}
+
+	status.Kinds = informer.SyncProgress()
+	status.KindsTotal = max(reportKinds, len(status.Kinds))
+	sum := 0
+	for _, k := range status.Kinds {
+		if k.Synced {
+			status.KindsSynced++
+		}
+		sum += k.Percent
+	}
+	if status.KindsTotal > 0 {
+		status.Percent = sum / status.KindsTotal
+	}
+	return status
+}
+
+func (h *Handler) startupStatus() StartupStatus {
+	kinds := h.crdReg.GetAllReports()
+	reportKinds := len(kinds)
+	status := StartupStatus{
+		WarmupCompleted: IsWarmupCompleted(),
+		CRDsDiscovered:  h.crdReg.IsDiscovered(),
+		ReportKinds:     reportKinds,
+		Clusters:        []ClusterStartup{},
+	}
+	clients := h.clusterReg.All()
+	for _, cc := range clients {
+		// kinds disabled on a cluster are not waited for
+		disabled := config.Get().ReportKinds.DisabledKinds(cc.Name, kinds)
+		status.Clusters = append(status.Clusters, clusterStartup(cc, reportKinds-len(disabled)))
+	}
+	expectedClusters.mu.Lock()
+	for _, name := range expectedClusters.names {
+		// a name merged into another cluster resolves to it and is not pending
+		if h.clusterReg.Get(name) == nil {
+			status.Clusters = append(status.Clusters, ClusterStartup{Name: name, SyncState: "Pending"})
+		}
+	}
+	expectedClusters.mu.Unlock()
+	sort.Slice(status.Clusters, func(i, j int) bool { return status.Clusters[i].Name < status.Clusters[j].Name })
+
+	status.ClustersTotal = len(status.Clusters)
+	sum := 0
+	for _, c := range status.Clusters {
+		if c.Percent == 100 {
+			status.ClustersSynced++
+		}
+		sum += c.Percent
+	}
+	switch {
+	case status.ClustersTotal > 0:
+		status.Percent = sum / status.ClustersTotal
+	case status.WarmupCompleted:
+		status.Percent = 100
+	}
+	return status
+}
+
+// GetStartup reports the initial sync per cluster and report kind, so the UI can show a
+// progress indicator instead of empty tables while a large fleet syncs.
+func (h *Handler) GetStartup(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    h.startupStatus(),
+	})
+}
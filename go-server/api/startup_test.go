@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+func TestStartupStatus(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	src := kubernetes.NewMemorySource()
+	if err := reg.SetSource("files", src); err != nil {
+		t.Fatal(err)
+	}
+	updater := NewCacheUpdater(reg)
+	if err := src.Watch("files", updater); err != nil {
+		t.Fatal(err)
+	}
+	reg.RegisterPushed("edge", "v1.30.2", nil)
+	ExpectClusters([]string{"files", "slow"})
+	t.Cleanup(func() { ExpectClusters(nil) })
+
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), config.GetGlobalRegistry())
+	status := h.startupStatus()
+	if status.ClustersTotal != 3 || status.ClustersSynced != 1 || status.Percent != 33 {
+		t.Fatalf("status = %+v", status)
+	}
+	byName := make(map[string]ClusterStartup)
+	for _, cl := range status.Clusters {
+		byName[cl.Name] = cl
+	}
+	if byName["files"].Percent != 100 || byName["slow"].SyncState != "Pending" || byName["edge"].Percent != 0 {
+		t.Fatalf("clusters = %+v", status.Clusters)
+	}
+
+	updater.UpdateSyncState("edge", "FullySynced")
+	if status := h.startupStatus(); status.ClustersSynced != 2 || status.Percent != 66 {
+		t.Fatalf("status after edge synced = %+v", status)
+	}
+}
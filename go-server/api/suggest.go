@@ -0,0 +1,348 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"trivy-ui/kubernetes"
+)
+
+// Suggestion types of /api/v1/suggest, in the order they rank among equal matches.
+const (
+	SuggestNamespace = "namespace"
+	SuggestImage     = "image"
+	SuggestCVE       = "cve"
+	SuggestCheck     = "check"
+	SuggestReport    = "report"
+)
+
+var suggestTypeOrder = map[string]int{SuggestNamespace: 0, SuggestImage: 1, SuggestCVE: 2, SuggestCheck: 3, SuggestReport: 4}
+
+// Suggestion is an entry of the global search box.
+type Suggestion struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	// ReportType is the kind of a report suggestion
+	ReportType string `json:"reportType,omitempty"`
+	// Reports counts the cached reports the value appears in
+	Reports int `json:"reports"`
+	// Clusters are the clusters of those reports
+	Clusters []string `json:"clusters"`
+	match    int
+}
+
+// suggestTerm is one indexed value with the reports it appears in, counted per cluster.
+type suggestTerm struct {
+	typ        string
+	value      string
+	reportType string
+	clusters   map[string]int
+	reports    int
+}
+
+// suggestIndex is an inverted index from the tokens of report names, images, CVEs,
+// namespaces and failed checks to those values. It is updated as reports are cached and
+// removed, like the fleet totals, so searching never scans the reports.
+type suggestIndex struct {
+	mu sync.Mutex
+	// terms by type, report type and value
+	terms map[string]*suggestTerm
+	// reports holds the terms of each report key, split so CVEs, which only ingest knows,
+	// survive updates that carry no findings
+	reports map[string]*indexedReport
+	// tokens maps the lower-cased tokens of a value, and the whole value, to its terms
+	tokens map[string]map[string]bool
+	// sorted lists the tokens for prefix lookups; nil after tokens changed
+	sorted []string
+}
+
+type indexedReport struct {
+	cluster string
+	terms   []string
+	cves    []string
+}
+
+var suggestions = newSuggestIndex()
+
+func newSuggestIndex() *suggestIndex {
+	return &suggestIndex{
+		terms:   make(map[string]*suggestTerm),
+		reports: make(map[string]*indexedReport),
+		tokens:  make(map[string]map[string]bool),
+	}
+}
+
+func suggestTermKey(typ, reportType, value string) string {
+	return typ + "\x00" + reportType + "\x00" + value
+}
+
+// set indexes a report entry written to the cache.
+func (s *suggestIndex) set(key string, value interface{}) {
+	report, ok := convertCacheValue[Report](value)
+	if !ok {
+		return
+	}
+	cluster, namespace, reportType, name, ok := parseReportCacheKey(key)
+	if !ok {
+		return
+	}
+	terms := []string{
+		suggestTermKey(SuggestReport, reportType, name),
+	}
+	if namespace != "" {
+		terms = append(terms, suggestTermKey(SuggestNamespace, "", namespace))
+	}
+	if image := reportImageRef(report); image != "" {
+		terms = append(terms, suggestTermKey(SuggestImage, "", image))
+	}
+	if data, ok := report.Data.(map[string]interface{}); ok {
+		for _, id := range kubernetes.FailedCheckIDs(data) {
+			terms = append(terms, suggestTermKey(SuggestCheck, "", id))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var cves []string
+	if old, ok := s.reports[key]; ok {
+		cves = old.cves
+		s.releaseLocked(old)
+	}
+	indexed := &indexedReport{cluster: cluster, terms: dedupeStrings(terms), cves: cves}
+	s.reports[key] = indexed
+	s.retainLocked(indexed)
+}
+
+// setCVEs indexes the vulnerability IDs of a cached report's findings.
+func (s *suggestIndex) setCVEs(key string, findings []kubernetes.Finding) {
+	ids := make([]string, 0, len(findings))
+	for _, f := range findings {
+		if f.VulnerabilityID != "" {
+			ids = append(ids, suggestTermKey(SuggestCVE, "", f.VulnerabilityID))
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	indexed, ok := s.reports[key]
+	if !ok {
+		return
+	}
+	s.releaseLocked(indexed)
+	indexed.cves = dedupeStrings(ids)
+	s.retainLocked(indexed)
+}
+
+// remove drops a report entry removed from the cache.
+func (s *suggestIndex) remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if indexed, ok := s.reports[key]; ok {
+		s.releaseLocked(indexed)
+		delete(s.reports, key)
+	}
+}
+
+func (s *suggestIndex) retainLocked(r *indexedReport) {
+	for _, list := range [][]string{r.terms, r.cves} {
+		for _, key := range list {
+			term := s.terms[key]
+			if term == nil {
+				parts := strings.SplitN(key, "\x00", 3)
+				term = &suggestTerm{typ: parts[0], reportType: parts[1], value: parts[2], clusters: make(map[string]int)}
+				s.terms[key] = term
+				for _, token := range suggestTokens(term.value) {
+					if s.tokens[token] == nil {
+						s.tokens[token] = make(map[string]bool)
+						s.sorted = nil
+					}
+					s.tokens[token][key] = true
+				}
+			}
+			term.reports++
+			term.clusters[r.cluster]++
+		}
+	}
+}
+
+func (s *suggestIndex) releaseLocked(r *indexedReport) {
+	for _, list := range [][]string{r.terms, r.cves} {
+		for _, key := range list {
+			term := s.terms[key]
+			if term == nil {
+				continue
+			}
+			term.reports--
+			if term.clusters[r.cluster]--; term.clusters[r.cluster] <= 0 {
+				delete(term.clusters, r.cluster)
+			}
+			if term.reports > 0 {
+				continue
+			}
+			delete(s.terms, key)
+			for _, token := range suggestTokens(term.value) {
+				delete(s.tokens[token], key)
+				if len(s.tokens[token]) == 0 {
+					delete(s.tokens, token)
+					s.sorted = nil
+				}
+			}
+		}
+	}
+}
+
+// suggestTokens returns the lower-cased value and its parts split at separators, so
+// "ghcr.io/acme/api" is found by "acme" and "CVE-2024-3094" by "3094".
+func suggestTokens(value string) []string {
+	lower := strings.ToLower(value)
+	tokens := []string{lower}
+	for _, part := range strings.FieldsFunc(lower, func(r rune) bool {
+		return strings.ContainsRune("-./:_@ ", r)
+	}) {
+		if part != lower {
+			tokens = append(tokens, part)
+		}
+	}
+	return dedupeStrings(tokens)
+}
+
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// values returns the indexed values of a type.
+func (s *suggestIndex) values(typ string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var values []string
+	for _, term := range s.terms {
+		if term.typ == typ {
+			values = append(values, term.value)
+		}
+	}
+	return values
+}
+
+// clusters returns the clusters of the indexed reports.
+func (s *suggestIndex) clusters() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool)
+	var clusters []string
+	for _, r := range s.reports {
+		if !seen[r.cluster] {
+			seen[r.cluster] = true
+			clusters = append(clusters, r.cluster)
+		}
+	}
+	return clusters
+}
+
+// search returns the terms with a token starting with q, ranked by how they match, then
+// by how many reports they appear in. A non-nil types or clusters narrows the result.
+func (s *suggestIndex) search(q string, types, clusters map[string]bool, limit int) []Suggestion {
+	q = strings.ToLower(q)
+	s.mu.Lock()
+	if s.sorted == nil {
+		s.sorted = make([]string, 0, len(s.tokens))
+		for token := range s.tokens {
+			s.sorted = append(s.sorted, token)
+		}
+		sort.Strings(s.sorted)
+	}
+	matched := make(map[string]bool)
+	for i := sort.SearchStrings(s.sorted, q); i < len(s.sorted) && strings.HasPrefix(s.sorted[i], q); i++ {
+		for key := range s.tokens[s.sorted[i]] {
+			matched[key] = true
+		}
+	}
+
+	result := []Suggestion{}
+	for key := range matched {
+		term := s.terms[key]
+		if types != nil && !types[term.typ] {
+			continue
+		}
+		sg := Suggestion{Type: term.typ, Value: term.value, ReportType: term.reportType, Clusters: []string{}}
+		for cluster, n := range term.clusters {
+			if clusters == nil || clusters[cluster] {
+				sg.Reports += n
+				sg.Clusters = append(sg.Clusters, cluster)
+			}
+		}
+		if sg.Reports == 0 {
+			continue
+		}
+		sg.match, _ = suggestMatch(strings.ToLower(term.value), q)
+		result = append(result, sg)
+	}
+	s.mu.Unlock()
+
+	for i := range result {
+		sort.Strings(result[i].Clusters)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.match != b.match {
+			return a.match < b.match
+		}
+		if a.Reports != b.Reports {
+			return a.Reports > b.Reports
+		}
+		if a.Type != b.Type {
+			return suggestTypeOrder[a.Type] < suggestTypeOrder[b.Type]
+		}
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		return a.ReportType < b.ReportType
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// Suggest handles GET /api/v1/suggest.
+func (h *Handler) Suggest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	limit := defaultSuggestLimit
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxSuggestLimit)
+	}
+	var types map[string]bool
+	for _, t := range strings.Split(query.Get("types"), ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		if _, ok := suggestTypeOrder[t]; !ok {
+			writeError(w, http.StatusBadRequest, "Unknown suggestion type: "+t)
+			return
+		}
+		if types == nil {
+			types = make(map[string]bool)
+		}
+		types[t] = true
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    suggestions.search(q, types, clusterSet(clusterParam(r)), limit),
+	})
+}
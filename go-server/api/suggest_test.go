@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+func imageReport(name, cluster, ns, repository, tag string) Report {
+	r := makeReport(name, cluster, ns, "vulnerabilityreports", 1)
+	r.Data.(map[string]interface{})["report"].(map[string]interface{})["artifact"] = map[string]interface{}{"repository": repository, "tag": tag}
+	return r
+}
+
+func TestSuggestIndex(t *testing.T) {
+	s := newSuggestIndex()
+	keyAPI := reportKey("prod", "payments", "vulnerabilityreports", "replicaset-api")
+	keyWeb := reportKey("dev", "payments-web", "vulnerabilityreports", "replicaset-web")
+	s.set(keyAPI, imageReport("replicaset-api", "prod", "payments", "acme/api", "1.2"))
+	s.set(keyWeb, imageReport("replicaset-web", "dev", "payments-web", "acme/web", "2.0"))
+	s.setCVEs(keyAPI, []kubernetes.Finding{{VulnerabilityID: "CVE-2024-3094"}, {VulnerabilityID: "CVE-2024-3094"}})
+	s.setCVEs(keyWeb, []kubernetes.Finding{{VulnerabilityID: "CVE-2024-3094"}})
+	// findings of a report that was never cached are not indexed
+	s.setCVEs(reportKey("prod", "x", "vulnerabilityreports", "gone"), []kubernetes.Finding{{VulnerabilityID: "CVE-2023-1"}})
+
+	got := s.search("payments", nil, nil, 10)
+	if len(got) != 2 || got[0].Value != "payments" || got[0].Type != SuggestNamespace || got[1].Value != "payments-web" {
+		t.Fatalf("namespaces: got %+v", got)
+	}
+	got = s.search("3094", nil, nil, 10)
+	if len(got) != 1 || got[0].Type != SuggestCVE || got[0].Reports != 2 || len(got[0].Clusters) != 2 {
+		t.Fatalf("CVE by token: got %+v", got)
+	}
+	if got := s.search("cve-2023", nil, nil, 10); len(got) != 0 {
+		t.Errorf("uncached report indexed: %+v", got)
+	}
+	got = s.search("acme", map[string]bool{SuggestImage: true}, map[string]bool{"prod": true}, 10)
+	if len(got) != 1 || got[0].Value != "acme/api:1.2" {
+		t.Fatalf("images on prod: got %+v", got)
+	}
+
+	// an update without findings keeps the CVEs; removing the report drops its terms
+	s.set(keyAPI, imageReport("replicaset-api", "prod", "payments", "acme/api", "1.3"))
+	if got := s.search("CVE-2024-3094", nil, nil, 10); len(got) != 1 || got[0].Reports != 2 {
+		t.Errorf("after update: got %+v", got)
+	}
+	if got := s.search("acme/api", nil, nil, 10); len(got) != 1 || got[0].Value != "acme/api:1.3" {
+		t.Errorf("old image still indexed: %+v", got)
+	}
+	s.remove(keyWeb)
+	if got := s.search("web", nil, nil, 10); len(got) != 0 {
+		t.Errorf("removed report still suggested: %+v", got)
+	}
+	if got := s.search("cve", nil, nil, 10); len(got) != 1 || got[0].Reports != 1 {
+		t.Errorf("after remove: got %+v", got)
+	}
+}
+
+func TestSuggestHandler(t *testing.T) {
+	c := useTestCache(t)
+	prev := suggestions
+	suggestions = newSuggestIndex()
+	t.Cleanup(func() { suggestions = prev })
+	c.Set(reportKey("prod", "payments", "suggestreports", "replicaset-api"), makeReport("replicaset-api", "prod", "payments", "suggestreports", 1), time.Hour)
+
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), config.GetGlobalRegistry())
+	for url, want := range map[string]int{
+		"/api/v1/suggest":                    http.StatusBadRequest,
+		"/api/v1/suggest?q=a&types=secrets":  http.StatusBadRequest,
+		"/api/v1/suggest?q=api&types=report": http.StatusOK,
+	} {
+		rec := httptest.NewRecorder()
+		h.Suggest(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != want {
+			t.Errorf("%s: got %d, want %d", url, rec.Code, want)
+		}
+	}
+	if got := suggestions.search("api", nil, nil, 10); len(got) != 1 || got[0].ReportType != "suggestreports" {
+		t.Errorf("cached report not indexed: %+v", got)
+	}
+	c.Delete(reportKey("prod", "payments", "suggestreports", "replicaset-api"))
+	if got := suggestions.search("api", nil, nil, 10); len(got) != 0 {
+		t.Errorf("deleted report still indexed: %+v", got)
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SummaryNamespace is the report counts of one namespace of a cluster.
+type SummaryNamespace struct {
+	Name string `json:"name"`
+	ReportRollup
+}
+
+// SummaryCluster is the report counts of one cluster, with its namespaces by name.
+type SummaryCluster struct {
+	Name string `json:"name"`
+	ReportRollup
+	// ClusterScoped counts the reports of cluster-scoped resources, e.g. nodes and
+	// compliance; unset when a namespace filter is applied
+	ClusterScoped *ReportRollup           `json:"clusterScoped,omitempty"`
+	Namespaces    []SummaryNamespace      `json:"namespaces"`
+	Kinds         map[string]ReportRollup `json:"kinds"`
+}
+
+// ReportSummary is the report counts of all cached reports, by cluster and namespace and
+// by report kind.
+type ReportSummary struct {
+	ReportRollup
+	Kinds    map[string]ReportRollup `json:"kinds"`
+	Clusters []SummaryCluster        `json:"clusters"`
+}
+
+// buildReportSummary groups rollups by cluster and namespace. With namespaces, only the
+// reports of those namespaces are counted.
+func buildReportSummary(rollups []NamespaceRollup, namespaces []string) ReportSummary {
+	var selected map[string]bool
+	if len(namespaces) > 0 {
+		selected = make(map[string]bool, len(namespaces))
+		for _, ns := range namespaces {
+			selected[ns] = true
+		}
+	}
+
+	summary := ReportSummary{Kinds: make(map[string]ReportRollup), Clusters: []SummaryCluster{}}
+	clusters := make(map[string]*SummaryCluster)
+	clusterNamespaces := make(map[string]map[string]*SummaryNamespace)
+	for _, r := range rollups {
+		if selected != nil && !selected[r.Namespace] {
+			continue
+		}
+		summary.merge(r.ReportRollup)
+		kind := summary.Kinds[r.Type]
+		kind.merge(r.ReportRollup)
+		summary.Kinds[r.Type] = kind
+
+		c := clusters[r.Cluster]
+		if c == nil {
+			c = &SummaryCluster{Name: r.Cluster, Kinds: make(map[string]ReportRollup)}
+			clusters[r.Cluster] = c
+			clusterNamespaces[r.Cluster] = make(map[string]*SummaryNamespace)
+		}
+		c.merge(r.ReportRollup)
+		kind = c.Kinds[r.Type]
+		kind.merge(r.ReportRollup)
+		c.Kinds[r.Type] = kind
+		if r.Namespace == "" {
+			if c.ClusterScoped == nil {
+				c.ClusterScoped = &ReportRollup{}
+			}
+			c.ClusterScoped.merge(r.ReportRollup)
+			continue
+		}
+		ns := clusterNamespaces[r.Cluster][r.Namespace]
+		if ns == nil {
+			ns = &SummaryNamespace{Name: r.Namespace}
+			clusterNamespaces[r.Cluster][r.Namespace] = ns
+		}
+		ns.merge(r.ReportRollup)
+	}
+
+	for name, c := range clusters {
+		c.Namespaces = make([]SummaryNamespace, 0, len(clusterNamespaces[name]))
+		for _, ns := range clusterNamespaces[name] {
+			c.Namespaces = append(c.Namespaces, *ns)
+		}
+		sort.Slice(c.Namespaces, func(i, j int) bool { return c.Namespaces[i].Name < c.Namespaces[j].Name })
+		summary.Clusters = append(summary.Clusters, *c)
+	}
+	sort.Slice(summary.Clusters, func(i, j int) bool { return summary.Clusters[i].Name < summary.Clusters[j].Name })
+	return summary
+}
+
+// GetSummary returns severity totals of all cached reports by cluster and namespace,
+// read from the rollups the cache keeps, filtered with cluster (and tag) and a
+// comma-separated namespace list.
+func (h *Handler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	var namespaces []string
+	for _, ns := range strings.Split(r.URL.Query().Get("namespace"), ",") {
+		if ns = strings.TrimSpace(ns); ns != "" && ns != "all" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    buildReportSummary(h.cache.GetReportRollups(clusterParam(r)), namespaces),
+	})
+}
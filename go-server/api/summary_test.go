@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetSummary(t *testing.T) {
+	c := useTestCache(t)
+	c.Set(reportKey("a", "web", "vulnerabilityreports", "r1"), makeReport("r1", "a", "web", "vulnerabilityreports", 2), 0)
+	c.Set(reportKey("a", "web", "configauditreports", "r2"), makeReport("r2", "a", "web", "configauditreports", 1), 0)
+	c.Set(reportKey("a", "db", "vulnerabilityreports", "r3"), makeReport("r3", "a", "db", "vulnerabilityreports", 0), 0)
+	c.Set(reportKey("a", "", "clustercompliancereports", "cis"), makeReport("cis", "a", "", "clustercompliancereports", 4), 0)
+	c.Set(reportKey("b", "web", "vulnerabilityreports", "r4"), makeReport("r4", "b", "web", "vulnerabilityreports", 3), 0)
+	h := &Handler{cache: &CacheServiceImpl{cache: c}}
+
+	get := func(query string) ReportSummary {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.GetSummary(rec, httptest.NewRequest(http.MethodGet, "/api/v1/summary?"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+		var resp struct {
+			Data ReportSummary `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Data
+	}
+
+	all := get("")
+	if all.Reports != 5 || all.Severity.Critical != 10 || len(all.Clusters) != 2 {
+		t.Fatalf("summary = %+v", all)
+	}
+	if k := all.Kinds["vulnerabilityreports"]; k.Reports != 3 || k.WithVulnerabilities != 2 {
+		t.Errorf("vulnerability reports = %+v", k)
+	}
+	a := all.Clusters[0]
+	if a.Name != "a" || a.Reports != 4 || a.Severity.Critical != 7 || a.ClusterScoped == nil || a.ClusterScoped.Severity.Critical != 4 {
+		t.Fatalf("cluster a = %+v", a)
+	}
+	if len(a.Namespaces) != 2 || a.Namespaces[0].Name != "db" || a.Namespaces[1].Name != "web" || a.Namespaces[1].Reports != 2 || a.Namespaces[1].Severity.Critical != 3 {
+		t.Errorf("namespaces of a = %+v", a.Namespaces)
+	}
+
+	web := get("cluster=a&namespace=web")
+	if web.Reports != 2 || len(web.Clusters) != 1 || web.Clusters[0].ClusterScoped != nil || len(web.Clusters[0].Namespaces) != 1 {
+		t.Errorf("summary of a/web = %+v", web)
+	}
+
+	// the summary follows the cache
+	c.Delete(reportKey("b", "web", "vulnerabilityreports", "r4"))
+	if after := get(""); after.Reports != 4 || len(after.Clusters) != 1 {
+		t.Errorf("summary after a delete = %+v", after)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"trivy-ui/config"
+	"trivy-ui/store"
+	"trivy-ui/telemetry"
+	"trivy-ui/utils"
+)
+
+// serverVersion is reported by telemetry; main sets it at startup.
+var serverVersion = "0.0.0-dev"
+
+// enabledFeatures names the optional features that are configured, without their values.
+func enabledFeatures(cfg *config.Config) []string {
+	features := map[string]bool{
+		"database":            store.Get() != nil,
+		"auth":                cfg.AuthMode == config.AuthModeMixed,
+		"agent-push":          cfg.AgentPushEnabled(),
+		"report-dirs":         len(cfg.ReportDirs) > 0,
+		"kubeconfig-secrets":  cfg.KubeconfigSecrets,
+		"issues":              cfg.IssueProvider != "",
+		"link-templates":      len(cfg.LinkTemplates) > 0,
+		"ignore-unfixable":    cfg.IgnoreUnfixable,
+		"severity-rules":      cfg.SeverityRulesFile != "",
+		"archive":             cfg.ArchiveDeleted,
+		"tls":                 cfg.TLSCertFile != "",
+		"trivy-server":        cfg.TrivyServerURL != "",
+		"notification-routes": cfg.NotificationRoutesFile != "",
+		"signature-policy":    cfg.SignaturePolicyFile != "",
+	}
+	var names []string
+	for name, enabled := range features {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func telemetryPayload(reg *ClusterRegistry, cache CacheService) telemetry.Payload {
+	reports, _ := cache.GetStats()["report_items"].(int)
+	return telemetry.NewPayload(serverVersion, len(reg.All()), reports, enabledFeatures(config.Get()))
+}
+
+// StartTelemetry records the server version and, when TELEMETRY is on, starts sending
+// usage statistics to TELEMETRY_ENDPOINT.
+func StartTelemetry(ctx context.Context, version string, reg *ClusterRegistry, cache CacheService) {
+	serverVersion = version
+	cfg := config.Get()
+	if !cfg.Telemetry {
+		return
+	}
+	if cfg.TelemetryEndpoint == "" || cfg.TelemetryInterval <= 0 {
+		utils.LogWarning("TELEMETRY=on needs TELEMETRY_ENDPOINT and a positive TELEMETRY_INTERVAL, not sending", nil)
+		return
+	}
+	utils.LogInfo("Sending anonymous usage statistics", map[string]interface{}{
+		"endpoint": cfg.TelemetryEndpoint,
+		"interval": cfg.TelemetryInterval.String(),
+	})
+	go telemetry.Run(ctx, cfg.TelemetryEndpoint, cfg.TelemetryInterval, func() telemetry.Payload {
+		return telemetryPayload(reg, cache)
+	})
+}
+
+// GetTelemetryPreview shows the exact payload telemetry sends, whether or not it is on.
+func (h *Handler) GetTelemetryPreview(w http.ResponseWriter, r *http.Request) {
+	cfg := config.Get()
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data: map[string]interface{}{
+			"enabled":  cfg.Telemetry && cfg.TelemetryEndpoint != "",
+			"endpoint": cfg.TelemetryEndpoint,
+			"interval": cfg.TelemetryInterval.String(),
+			"payload":  telemetryPayload(h.clusterReg, h.cache),
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/telemetry"
+)
+
+func TestTelemetryPreview(t *testing.T) {
+	c := useTestCache(t)
+	svc := &CacheServiceImpl{cache: c}
+	reg := NewClusterRegistry(svc)
+	reg.RegisterPushed("edge", "v1.29.0", nil)
+	h := NewHandler(nil, svc, reg, NewQueryService(svc), config.GetGlobalRegistry())
+
+	rec := httptest.NewRecorder()
+	h.GetTelemetryPreview(rec, httptest.NewRequest(http.MethodGet, "/api/v1/telemetry/preview", nil))
+	var resp struct {
+		Data struct {
+			Enabled bool              `json:"enabled"`
+			Payload telemetry.Payload `json:"payload"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.Enabled {
+		t.Error("telemetry enabled by default")
+	}
+	if p := resp.Data.Payload; p.Clusters != "1" || p.Reports != "0" || p.Version == "" {
+		t.Errorf("payload = %+v", p)
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// Timeline event types.
+const (
+	TimelineReportCreated   = "reportCreated"
+	TimelineImageChanged    = "imageChanged"
+	TimelineSeverityChanged = "severityChanged"
+	TimelineCVEDetected     = "cveDetected"
+	TimelineCVEFixed        = "cveFixed"
+)
+
+type TimelineEvent struct {
+	Time       time.Time `json:"time"`
+	Type       string    `json:"type"`
+	ReportType string    `json:"reportType"`
+	ReportName string    `json:"reportName"`
+	From       string    `json:"from,omitempty"`
+	To         string    `json:"to,omitempty"`
+	FindingID  string    `json:"findingId,omitempty"`
+	Resource   string    `json:"resource,omitempty"`
+	Severity   string    `json:"severity,omitempty"`
+}
+
+type WorkloadTimeline struct {
+	Cluster   string          `json:"cluster"`
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name"`
+	Events    []TimelineEvent `json:"events"`
+}
+
+// reportWorkload names the workload a report belongs to from the operator's labels.
+// ReplicaSets are replaced on every rollout, so their reports are attributed to the
+// Deployment to keep image changes on one timeline.
+func reportWorkload(r Report) string {
+	kind, name := reportResource(r)
+	if name == "" {
+		return r.Name
+	}
+	if kind == "ReplicaSet" {
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			return name[:i]
+		}
+	}
+	return name
+}
+
+// reportResource returns the kind and name of the resource a report was created for.
+func reportResource(r Report) (kind, name string) {
+	data, _ := r.Data.(map[string]interface{})
+	metadata, _ := data["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	kind, _ = labels["trivy-operator.resource.kind"].(string)
+	name, _ = labels["trivy-operator.resource.name"].(string)
+	return kind, name
+}
+
+// reportCreatedAt is the report's creation time from its metadata, or zero.
+func reportCreatedAt(r Report) time.Time {
+	data, _ := r.Data.(map[string]interface{})
+	metadata, _ := data["metadata"].(map[string]interface{})
+	created, _ := metadata["creationTimestamp"].(string)
+	t, _ := time.Parse(time.RFC3339, created)
+	return t
+}
+
+// recordReportHistory snapshots a cached report for the workload timeline.
+func recordReportHistory(report Report) {
+	st := store.Get()
+	if st == nil {
+		return
+	}
+	rec := newReportRecord(report)
+	snap := store.ReportSnapshot{
+		ReportRef:  reportRef(report),
+		Workload:   reportWorkload(report),
+		Image:      reportImageRef(report),
+		Severity:   rec.highestSeverity(),
+		Critical:   rec.critical,
+		High:       rec.high,
+		Medium:     rec.medium,
+		Low:        rec.low,
+		RecordedAt: time.Now(),
+	}
+	ctx, cancel := storeCallContext(context.Background())
+	defer cancel()
+	if _, err := st.RecordReportSnapshot(ctx, snap, reportCreatedAt(report)); err != nil {
+		utils.LogWarning("Failed to record report history", map[string]interface{}{
+			"cluster": report.Cluster,
+			"type":    report.Type,
+			"name":    report.Name,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// buildTimeline turns snapshots and findings into events, oldest first. CVEs found by the
+// first scan are not reported as detected, CVEs carried over to a new report (e.g. after
+// a rollout) are detected only once, and a CVE is fixed once no report still has it open.
+func buildTimeline(history []store.ReportSnapshot, findings []store.Finding) []TimelineEvent {
+	events := []TimelineEvent{}
+	last := make(map[string]store.ReportSnapshot)
+	created := make(map[string]bool)
+	for _, snap := range history {
+		base := TimelineEvent{Time: snap.RecordedAt, ReportType: snap.ReportType, ReportName: snap.ReportName}
+		prev, seen := last[snap.ReportType]
+		if !created[snap.ReportName] {
+			created[snap.ReportName] = true
+			e := base
+			e.Type = TimelineReportCreated
+			e.Severity = snap.Severity
+			events = append(events, e)
+		}
+		if seen && prev.Image != snap.Image && snap.Image != "" {
+			e := base
+			e.Type, e.From, e.To = TimelineImageChanged, prev.Image, snap.Image
+			events = append(events, e)
+		}
+		if seen && prev.Severity != snap.Severity {
+			e := base
+			e.Type, e.From, e.To = TimelineSeverityChanged, prev.Severity, snap.Severity
+			events = append(events, e)
+		}
+		last[snap.ReportType] = snap
+	}
+
+	// findings come oldest first; those of the first scan are the baseline, not detections
+	var baseline time.Time
+	if len(findings) > 0 {
+		baseline = findings[0].FirstSeen.Add(time.Minute)
+	}
+	open := make(map[string]bool)
+	fixed := make(map[string]store.Finding)
+	detected := make(map[string]bool)
+	for _, f := range findings {
+		key := f.FindingID + "|" + f.Resource
+		if f.ResolvedAt == nil {
+			open[key] = true
+		} else if prev, ok := fixed[key]; !ok || f.ResolvedAt.After(*prev.ResolvedAt) {
+			fixed[key] = f
+		}
+		if detected[key] {
+			continue
+		}
+		detected[key] = true
+		if f.FirstSeen.After(baseline) {
+			events = append(events, findingEvent(f, f.FirstSeen, TimelineCVEDetected))
+		}
+	}
+	for key, f := range fixed {
+		if !open[key] {
+			events = append(events, findingEvent(f, *f.ResolvedAt, TimelineCVEFixed))
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Time.Equal(events[j].Time) {
+			return events[i].Time.Before(events[j].Time)
+		}
+		return events[i].FindingID < events[j].FindingID
+	})
+	return events
+}
+
+func findingEvent(f store.Finding, at time.Time, eventType string) TimelineEvent {
+	return TimelineEvent{
+		Time:       at,
+		Type:       eventType,
+		ReportType: f.ReportType,
+		ReportName: f.ReportName,
+		FindingID:  f.FindingID,
+		Resource:   f.Resource,
+		Severity:   f.Severity,
+	}
+}
+
+// GetWorkloadTimeline handles GET /api/v1/workloads/{cluster}/{namespace}/{name}/timeline.
+func (h *Handler) GetWorkloadTimeline(w http.ResponseWriter, r *http.Request, cluster, namespace, name string) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	history, err := st.WorkloadHistory(ctx, cluster, namespace, name)
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to load workload history")
+		return
+	}
+	if len(history) == 0 {
+		writeError(w, http.StatusNotFound, "Workload not found")
+		return
+	}
+
+	names := make([]string, 0, len(history))
+	seen := make(map[string]bool)
+	for _, snap := range history {
+		if !seen[snap.ReportName] {
+			seen[snap.ReportName] = true
+			names = append(names, snap.ReportName)
+		}
+	}
+	findings, err := st.ReportFindings(ctx, cluster, namespace, names)
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to load workload findings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data: WorkloadTimeline{
+			Cluster:   cluster,
+			Namespace: namespace,
+			Name:      name,
+			Events:    buildTimeline(history, findings),
+		},
+	})
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"trivy-ui/store"
+)
+
+func TestReportWorkload(t *testing.T) {
+	labeled := func(kind, name string) Report {
+		return Report{Name: "report", Data: map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{
+			"trivy-operator.resource.kind": kind,
+			"trivy-operator.resource.name": name,
+		}}}}
+	}
+	if got := reportWorkload(labeled("ReplicaSet", "api-7d9f8c")); got != "api" {
+		t.Fatalf("expected deployment name, got %q", got)
+	}
+	if got := reportWorkload(labeled("StatefulSet", "db")); got != "db" {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := reportWorkload(Report{Name: "report"}); got != "report" {
+		t.Fatalf("expected report name fallback, got %q", got)
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ref1 := store.ReportRef{Cluster: "c1", Namespace: "apps", ReportType: "vulnerabilityreports", ReportName: "replicaset-api-1"}
+	ref2 := ref1
+	ref2.ReportName = "replicaset-api-2"
+	history := []store.ReportSnapshot{
+		{ReportRef: ref1, Workload: "api", Image: "api:1", Severity: "HIGH", RecordedAt: t0},
+		{ReportRef: ref1, Workload: "api", Image: "api:1", Severity: "CRITICAL", RecordedAt: t0.Add(time.Hour)},
+		{ReportRef: ref2, Workload: "api", Image: "api:2", Severity: "HIGH", RecordedAt: t0.Add(2 * time.Hour)},
+	}
+	resolved := t0.Add(2 * time.Hour)
+	findings := []store.Finding{
+		{ReportRef: ref1, FindingID: "CVE-1", FirstSeen: t0, ResolvedAt: &resolved},
+		{ReportRef: ref1, FindingID: "CVE-2", FirstSeen: t0, ResolvedAt: &resolved},
+		{ReportRef: ref1, FindingID: "CVE-3", FirstSeen: t0.Add(time.Hour), ResolvedAt: &resolved},
+		{ReportRef: ref2, FindingID: "CVE-2", FirstSeen: t0.Add(2 * time.Hour)},
+	}
+
+	var types []string
+	for _, e := range buildTimeline(history, findings) {
+		types = append(types, e.Type+":"+e.FindingID)
+	}
+	want := []string{
+		"reportCreated:", "severityChanged:", "cveDetected:CVE-3",
+		"reportCreated:", "imageChanged:", "severityChanged:", "cveFixed:CVE-1", "cveFixed:CVE-3",
+	}
+	if len(types) != len(want) {
+		t.Fatalf("expected %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, types)
+		}
+	}
+}
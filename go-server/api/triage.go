@@ -0,0 +1,324 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"trivy-ui/store"
+	"trivy-ui/utils"
+)
+
+// Triage scopes: a record applies to the finding in one report, or in every report of
+// the image, in any cluster or namespace.
+const (
+	TriageScopeReport = "report"
+	TriageScopeImage  = "image"
+)
+
+type TriageRequest struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	FindingID string `json:"findingId"`
+	Resource  string `json:"resource"`
+	State     string `json:"state"`
+	// Assignee and Note are kept when left out and cleared when empty
+	Assignee *string `json:"assignee"`
+	Note     *string `json:"note"`
+	// Scope is report (default) or image; image keys the record by ImageDigest, read
+	// from the cached report when not given
+	Scope       string `json:"scope"`
+	ImageDigest string `json:"imageDigest"`
+}
+
+// reportImageDigest returns the digest of the image a cached report scanned, or "".
+func (h *Handler) reportImageDigest(cluster, namespace, typeName, name string) string {
+	if h.crdReg != nil {
+		if kind := h.crdReg.ResolveReport(typeName); kind != nil {
+			typeName = kind.Name
+		}
+	}
+	value, found := h.cache.Get(reportKey(cluster, namespace, typeName, name))
+	if !found {
+		return ""
+	}
+	report, ok := convertCacheValue[Report](value)
+	if !ok {
+		return ""
+	}
+	digest, _ := reportSection(report, "artifact")["digest"].(string)
+	return digest
+}
+
+// applyFields sets the assignee and note of a request on the record it changes.
+func (req TriageRequest) applyFields(rec *store.TriageRecord) {
+	if req.Assignee != nil {
+		rec.Assignee = *req.Assignee
+		rec.ClearAssignee = *req.Assignee == ""
+	}
+	if req.Note != nil {
+		rec.Note = *req.Note
+		rec.ClearNote = *req.Note == ""
+	}
+}
+
+// triageRecord returns the record a request upserts.
+func (h *Handler) triageRecord(req TriageRequest) (store.TriageRecord, error) {
+	rec := store.TriageRecord{
+		Cluster:    req.Cluster,
+		Namespace:  req.Namespace,
+		ReportType: req.Type,
+		ReportName: req.Name,
+		FindingID:  req.FindingID,
+		Resource:   req.Resource,
+		State:      req.State,
+	}
+	req.applyFields(&rec)
+	switch req.Scope {
+	case "", TriageScopeReport:
+		if req.ImageDigest != "" && req.Scope == "" {
+			rec.ImageDigest = req.ImageDigest
+		}
+	case TriageScopeImage:
+		rec.ImageDigest = req.ImageDigest
+		if rec.ImageDigest == "" {
+			rec.ImageDigest = h.reportImageDigest(req.Cluster, req.Namespace, req.Type, req.Name)
+		}
+		if rec.ImageDigest == "" {
+			return rec, errors.New("the report has no image digest; give imageDigest or use the report scope")
+		}
+	default:
+		return rec, fmt.Errorf("invalid scope %q, expected %s or %s", req.Scope, TriageScopeReport, TriageScopeImage)
+	}
+	return rec, nil
+}
+
+// withImageTriage adds the records of a report's image to its own records; for a finding
+// triaged both ways the report's record wins.
+func withImageTriage(records, imageRecords []store.TriageRecord) []store.TriageRecord {
+	own := make(map[string]bool, len(records))
+	for _, rec := range records {
+		own[rec.FindingID+"\x00"+rec.Resource] = true
+	}
+	for _, rec := range imageRecords {
+		if !own[rec.FindingID+"\x00"+rec.Resource] {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+// requireStore writes a 503 and returns nil when persistence is disabled.
+func requireStore(w http.ResponseWriter) *store.Store {
+	st := store.Get()
+	if st == nil {
+		writeError(w, http.StatusServiceUnavailable, "Persistent store not available")
+	}
+	return st
+}
+
+func (h *Handler) ListTriage(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := store.TriageFilter{
+		Cluster:     q.Get("cluster"),
+		Namespace:   q.Get("namespace"),
+		ReportType:  q.Get("type"),
+		ReportName:  q.Get("name"),
+		FindingID:   q.Get("findingId"),
+		Assignee:    q.Get("assignee"),
+		ImageDigest: q.Get("imageDigest"),
+	}
+	switch scope := q.Get("scope"); scope {
+	case "":
+	case TriageScopeReport:
+		filter.ReportOnly = true
+	case TriageScopeImage:
+		filter.DigestOnly = true
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid scope: "+scope)
+		return
+	}
+	// the triage of one report includes that of its image, as it applies to the report
+	var digest string
+	if filter.Cluster != "" && filter.ReportType != "" && filter.ReportName != "" && filter.ImageDigest == "" && q.Get("scope") == "" {
+		if digest = h.reportImageDigest(filter.Cluster, filter.Namespace, filter.ReportType, filter.ReportName); digest != "" {
+			filter.ReportOnly = true
+		}
+	}
+	if states := q.Get("state"); states != "" {
+		for _, s := range strings.Split(states, ",") {
+			s = strings.TrimSpace(s)
+			if !store.IsValidTriageState(s) {
+				writeError(w, http.StatusBadRequest, "Invalid state: "+s)
+				return
+			}
+			filter.States = append(filter.States, s)
+		}
+	}
+
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	records, err := st.ListTriage(ctx, filter)
+	if err == nil && digest != "" {
+		var imageRecords []store.TriageRecord
+		imageRecords, err = st.ListTriage(ctx, store.TriageFilter{
+			ImageDigest: digest,
+			FindingID:   filter.FindingID,
+			States:      filter.States,
+			Assignee:    filter.Assignee,
+		})
+		records = withImageTriage(records, imageRecords)
+	}
+	if err != nil {
+		if clientGone(r.Context()) {
+			return
+		}
+		utils.LogWarning("Failed to list triage records", map[string]interface{}{"error": err.Error()})
+		writeError(w, http.StatusInternalServerError, "Failed to list triage records")
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    records,
+	})
+}
+
+// PatchTriage upserts the triage record identified by the finding fields in the body.
+func (h *Handler) PatchTriage(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+
+	var req TriageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rec, err := h.triageRecord(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	rec, err = st.UpsertTriage(ctx, rec)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	aggregates.invalidateAll()
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    rec,
+	})
+}
+
+type TriageBatchResult struct {
+	BatchItemStatus
+	Record *store.TriageRecord `json:"record,omitempty"`
+}
+
+// PatchTriageBatch upserts up to maxBatchItems triage records, e.g. to acknowledge many
+// findings at once. Invalid items are reported per item and do not stop the others.
+func (h *Handler) PatchTriageBatch(w http.ResponseWriter, r *http.Request) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+
+	var reqs []TriageRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(reqs) == 0 {
+		writeError(w, http.StatusBadRequest, "No triage records requested")
+		return
+	}
+	if len(reqs) > maxBatchItems {
+		writeError(w, http.StatusBadRequest, "Too many triage records requested")
+		return
+	}
+
+	results := make([]TriageBatchResult, len(reqs))
+	failed := 0
+	for i, req := range reqs {
+		if clientGone(r.Context()) {
+			// records stored so far stay; nobody is left to read the results
+			if i > 0 {
+				aggregates.invalidateAll()
+			}
+			return
+		}
+		rec, err := h.triageRecord(req)
+		if err == nil {
+			ctx, cancel := storeCallContext(r.Context())
+			rec, err = st.UpsertTriage(ctx, rec)
+			cancel()
+		}
+		if err != nil {
+			results[i].BatchItemStatus = batchError(http.StatusBadRequest, err.Error())
+			failed++
+			continue
+		}
+		results[i].BatchItemStatus = batchOK()
+		results[i].Record = &rec
+	}
+	if failed < len(reqs) {
+		aggregates.invalidateAll()
+	}
+	writeBatch(w, results, failed, len(reqs))
+}
+
+func (h *Handler) PatchTriageByID(w http.ResponseWriter, r *http.Request, idStr string) {
+	st := requireStore(w)
+	if st == nil {
+		return
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid triage id")
+		return
+	}
+
+	var req TriageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ctx, cancel := storeCallContext(r.Context())
+	defer cancel()
+	changes := store.TriageRecord{State: req.State}
+	req.applyFields(&changes)
+	rec, err := st.UpdateTriage(ctx, id, changes)
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "Triage record not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	aggregates.invalidateAll()
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    rec,
+	})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"trivy-ui/config"
+	"trivy-ui/store"
+)
+
+func TestTriageRecordScope(t *testing.T) {
+	c := useTestCache(t)
+	report := makeReport("replicaset-api", "c1", "apps", "vulnerabilityreports", 1)
+	report.Data.(map[string]interface{})["report"].(map[string]interface{})["artifact"] = map[string]interface{}{"repository": "library/api", "digest": "sha256:abc"}
+	c.Set(reportKey("c1", "apps", "vulnerabilityreports", "replicaset-api"), report, 0)
+	h := &Handler{cache: &CacheServiceImpl{cache: c}, crdReg: config.GetGlobalRegistry()}
+
+	req := TriageRequest{Cluster: "c1", Namespace: "apps", Type: "vulnerabilityreports", Name: "replicaset-api", FindingID: "CVE-2024-0001", State: "accepted"}
+	if rec, err := h.triageRecord(req); err != nil || rec.ImageDigest != "" {
+		t.Fatalf("expected a report record by default, got %+v %v", rec, err)
+	}
+	req.Scope = TriageScopeImage
+	if rec, err := h.triageRecord(req); err != nil || rec.ImageDigest != "sha256:abc" {
+		t.Fatalf("expected the digest read from the report, got %+v %v", rec, err)
+	}
+	req.Name = "replicaset-gone"
+	if _, err := h.triageRecord(req); err == nil {
+		t.Fatal("expected an error without a digest")
+	}
+	req.Scope = "namespace"
+	if _, err := h.triageRecord(req); err == nil {
+		t.Fatal("expected an unknown scope to be rejected")
+	}
+}
+
+func TestWithImageTriage(t *testing.T) {
+	own := []store.TriageRecord{{ID: 1, FindingID: "CVE-1", Resource: "openssl", State: store.TriageInProgress}}
+	image := []store.TriageRecord{
+		{ID: 2, FindingID: "CVE-1", Resource: "openssl", State: store.TriageAccepted, ImageDigest: "sha256:abc"},
+		{ID: 3, FindingID: "CVE-2", Resource: "zlib", State: store.TriageAccepted, ImageDigest: "sha256:abc"},
+	}
+	records := withImageTriage(own, image)
+	if len(records) != 2 || records[0].ID != 1 || records[1].ID != 3 {
+		t.Fatalf("expected the report's own record to win, got %+v", records)
+	}
+}
+
+func TestTriageRequestApplyFields(t *testing.T) {
+	var req TriageRequest
+	if err := json.Unmarshal([]byte(`{"assignee":"","note":"false positive"}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	var rec store.TriageRecord
+	req.applyFields(&rec)
+	if !rec.ClearAssignee || rec.Note != "false positive" || rec.ClearNote {
+		t.Fatalf("expected the assignee cleared and the note set, got %+v", rec)
+	}
+	rec = store.TriageRecord{}
+	(TriageRequest{}).applyFields(&rec)
+	if rec.ClearAssignee || rec.ClearNote {
+		t.Fatalf("expected fields left out to be kept, got %+v", rec)
+	}
+}
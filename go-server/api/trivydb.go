@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
+)
+
+const trivyDBTTL = 5 * time.Minute
+
+var (
+	trivyDBsMu sync.Mutex
+	trivyDBs   = make(map[string]*cachedTrivyDB)
+)
+
+type cachedTrivyDB struct {
+	mu        sync.Mutex
+	db        kubernetes.TrivyDB
+	fetchedAt time.Time
+}
+
+type TrivyDBStatus struct {
+	Cluster string `json:"cluster"`
+	kubernetes.TrivyDB
+	// Stale is set once the DB is older than TRIVY_DB_MAX_AGE
+	Stale   bool   `json:"stale"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// trivyDBFor returns the vulnerability DB metadata of a cluster's operator from a cache
+// refreshed every few minutes. Pushed clusters have no client and unknown metadata.
+func trivyDBFor(cluster string) kubernetes.TrivyDB {
+	cc := GetClusterClient(cluster)
+	if cc == nil || cc.Client == nil {
+		return kubernetes.TrivyDB{}
+	}
+	scope := operatorScopeFor(cluster)
+	if !scope.Found {
+		return kubernetes.TrivyDB{}
+	}
+
+	trivyDBsMu.Lock()
+	entry := trivyDBs[cluster]
+	if entry == nil {
+		entry = &cachedTrivyDB{}
+		trivyDBs[cluster] = entry
+	}
+	trivyDBsMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if time.Since(entry.fetchedAt) > trivyDBTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		db, err := cc.Client.GetTrivyDB(ctx, scope.Namespace)
+		cancel()
+		if err != nil {
+			utils.LogWarning("Failed to read Trivy DB metadata", map[string]interface{}{"cluster": cluster, "error": err.Error()})
+			db = kubernetes.TrivyDB{}
+		}
+		entry.db = db
+		// also back off after failures
+		entry.fetchedAt = time.Now()
+	}
+	return entry.db
+}
+
+// trivyDBStatus flags a DB older than maxAge; an unknown update time is not stale.
+func trivyDBStatus(cluster string, db kubernetes.TrivyDB, maxAge time.Duration, now time.Time) TrivyDBStatus {
+	status := TrivyDBStatus{Cluster: cluster, TrivyDB: db}
+	if db.UpdatedAt == nil || maxAge <= 0 {
+		return status
+	}
+	if age := now.Sub(*db.UpdatedAt); age > maxAge {
+		status.Stale = true
+		status.Warning = fmt.Sprintf("Trivy DB is %s old, older than TRIVY_DB_MAX_AGE (%s)", formatAge(age), formatAge(maxAge))
+	}
+	return status
+}
+
+func formatAge(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	return d.Round(time.Minute).String()
+}
+
+// GetTrivyDB handles GET /api/v1/clusters/{cluster}/trivy-db.
+func (h *Handler) GetTrivyDB(w http.ResponseWriter, r *http.Request, cluster string) {
+	if h.clusterReg.Get(cluster) == nil {
+		writeError(w, http.StatusNotFound, "Cluster not found")
+		return
+	}
+	status := trivyDBStatus(cluster, trivyDBFor(cluster), config.Get().TrivyDBMaxAge, time.Now())
+	writeJSON(w, http.StatusOK, Response{Code: CodeSuccess, Message: "Success", Data: status})
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"trivy-ui/kubernetes"
+)
+
+func TestTrivyDBStatus(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	updated := now.Add(-9 * 24 * time.Hour)
+	db := kubernetes.TrivyDB{Found: true, Source: kubernetes.TrivyDBSourceScanJob, Version: "2", UpdatedAt: &updated}
+
+	status := trivyDBStatus("prod", db, 7*24*time.Hour, now)
+	if !status.Stale || status.Warning != "Trivy DB is 9d old, older than TRIVY_DB_MAX_AGE (7d)" {
+		t.Fatalf("expected a stale warning, got %+v", status)
+	}
+	if status := trivyDBStatus("prod", db, 10*24*time.Hour, now); status.Stale || status.Warning != "" {
+		t.Fatalf("expected a fresh DB, got %+v", status)
+	}
+	if status := trivyDBStatus("prod", kubernetes.TrivyDB{}, time.Hour, now); status.Stale {
+		t.Fatal("an unknown update time should not be stale")
+	}
+
+	var warning errWarning
+	if _, err := checkTrivyDB(trivyDBStatus("prod", db, 7*24*time.Hour, now)); !errors.As(err, &warning) {
+		t.Fatalf("expected the self-test to warn about a stale DB, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"trivy-ui/config"
+	"trivy-ui/utils"
+)
+
+// UIConfig lets the frontend be themed without rebuilding it. UI_CONFIG_FILE (YAML or
+// JSON) overrides the defaults field by field; severityColors are merged.
+type UIConfig struct {
+	Title          string            `json:"title"`
+	LogoURL        string            `json:"logoUrl,omitempty"`
+	SeverityOrder  []string          `json:"severityOrder"`
+	SeverityColors map[string]string `json:"severityColors"`
+	DefaultFilters UIDefaultFilters  `json:"defaultFilters"`
+	// DemoMode tells the frontend names are pseudonyms, see DEMO_MODE
+	DemoMode bool `json:"demoMode,omitempty"`
+}
+
+// UIDefaultFilters preselect the report list filters; they use the list endpoint's
+// query parameters.
+type UIDefaultFilters struct {
+	Cluster        string   `json:"cluster,omitempty"`
+	Namespaces     []string `json:"namespaces,omitempty"`
+	ReportType     string   `json:"reportType,omitempty"`
+	OnlyVulnerable bool     `json:"onlyVulnerable,omitempty"`
+	Sort           string   `json:"sort,omitempty"`
+	Filter         string   `json:"filter,omitempty"`
+	PageSize       int      `json:"pageSize,omitempty"`
+}
+
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+func defaultUIConfig() UIConfig {
+	return UIConfig{
+		Title:         "Trivy UI",
+		SeverityOrder: []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"},
+		SeverityColors: map[string]string{
+			"CRITICAL": "#ef4444",
+			"HIGH":     "#f97316",
+			"MEDIUM":   "#eab308",
+			"LOW":      "#3b82f6",
+			"UNKNOWN":  "#6b7280",
+		},
+	}
+}
+
+// parseUIConfig reads a UI config file over the defaults.
+func parseUIConfig(data []byte) (UIConfig, error) {
+	cfg := defaultUIConfig()
+	colors := cfg.SeverityColors
+	cfg.SeverityColors = nil
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return UIConfig{}, fmt.Errorf("failed to parse UI config: %w", err)
+	}
+	seen := make(map[string]bool)
+	for i, s := range cfg.SeverityOrder {
+		s = strings.ToUpper(s)
+		if _, ok := severityRank[s]; !ok || s == "NONE" {
+			return UIConfig{}, fmt.Errorf("unknown severity %q in severityOrder", cfg.SeverityOrder[i])
+		}
+		if seen[s] {
+			return UIConfig{}, fmt.Errorf("severity %s listed twice in severityOrder", s)
+		}
+		seen[s] = true
+		cfg.SeverityOrder[i] = s
+	}
+	for s, color := range cfg.SeverityColors {
+		s = strings.ToUpper(s)
+		if _, ok := severityRank[s]; !ok {
+			return UIConfig{}, fmt.Errorf("unknown severity %q in severityColors", s)
+		}
+		if !colorPattern.MatchString(color) {
+			return UIConfig{}, fmt.Errorf("severity color %q is not a hex color", color)
+		}
+		colors[s] = color
+	}
+	cfg.SeverityColors = colors
+	if cfg.LogoURL != "" && !strings.HasPrefix(cfg.LogoURL, "https://") &&
+		!strings.HasPrefix(cfg.LogoURL, "http://") && !strings.HasPrefix(cfg.LogoURL, "/") {
+		return UIConfig{}, fmt.Errorf("logoUrl must be an http(s) URL or an absolute path")
+	}
+	if cfg.DefaultFilters.Sort != "" && !IsValidReportSort(cfg.DefaultFilters.Sort) {
+		return UIConfig{}, fmt.Errorf("invalid default sort %q", cfg.DefaultFilters.Sort)
+	}
+	if cfg.DefaultFilters.Filter != "" {
+		if _, err := parseReportFilter(cfg.DefaultFilters.Filter); err != nil {
+			return UIConfig{}, fmt.Errorf("invalid default filter: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// uiConfigFile caches UI_CONFIG_FILE and re-reads it when it changes, so a mounted
+// ConfigMap can be edited without a restart.
+var uiConfigFile struct {
+	mu      sync.Mutex
+	modTime time.Time
+	config  UIConfig
+}
+
+func getUIConfig() UIConfig {
+	path := config.Get().UIConfigFile
+	if path == "" {
+		return defaultUIConfig()
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		utils.LogWarning("Failed to read UI config, using defaults", map[string]interface{}{"path": path, "error": err.Error()})
+		return defaultUIConfig()
+	}
+
+	uiConfigFile.mu.Lock()
+	defer uiConfigFile.mu.Unlock()
+	if !uiConfigFile.modTime.IsZero() && info.ModTime().Equal(uiConfigFile.modTime) {
+		return uiConfigFile.config
+	}
+	cfg := defaultUIConfig()
+	data, err := os.ReadFile(path)
+	if err == nil {
+		cfg, err = parseUIConfig(data)
+	}
+	if err != nil {
+		utils.LogWarning("Invalid UI config, using defaults", map[string]interface{}{"path": path, "error": err.Error()})
+		cfg = defaultUIConfig()
+	}
+	uiConfigFile.modTime, uiConfigFile.config = info.ModTime(), cfg
+	return cfg
+}
+
+// GetUIConfig serves the branding, severity display settings and default filters.
+func (h *Handler) GetUIConfig(w http.ResponseWriter, r *http.Request) {
+	cfg := getUIConfig()
+	cfg.DemoMode = config.Get().DemoMode
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    cfg,
+	})
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseUIConfig(t *testing.T) {
+	cfg, err := parseUIConfig([]byte(`
+title: Acme Security
+logoUrl: https://acme.example/logo.svg
+severityOrder: [critical, high, medium, low]
+severityColors:
+  high: "#ff8800"
+defaultFilters:
+  onlyVulnerable: true
+  sort: -scannedAt
+  filter: severity in (CRITICAL,HIGH)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Title != "Acme Security" || len(cfg.SeverityOrder) != 4 || cfg.SeverityOrder[0] != "CRITICAL" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if cfg.SeverityColors["HIGH"] != "#ff8800" || cfg.SeverityColors["CRITICAL"] != "#ef4444" {
+		t.Errorf("colors not merged with defaults: %v", cfg.SeverityColors)
+	}
+	if !cfg.DefaultFilters.OnlyVulnerable || cfg.DefaultFilters.Sort != "-scannedAt" {
+		t.Errorf("default filters = %+v", cfg.DefaultFilters)
+	}
+
+	for _, bad := range []string{
+		"severityOrder: [CRITICAL, SEVERE]",
+		"severityOrder: [HIGH, HIGH]",
+		`severityColors: {HIGH: "red; background: url(x)"}`,
+		"logoUrl: javascript:alert(1)",
+		"defaultFilters: {sort: name}",
+	} {
+		if _, err := parseUIConfig([]byte(bad)); err == nil {
+			t.Errorf("accepted %q", bad)
+		}
+	}
+	if cfg, err := parseUIConfig(nil); err != nil || !strings.EqualFold(cfg.Title, "Trivy UI") {
+		t.Errorf("empty file: %+v %v", cfg, err)
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+// ignoreUnfixable reads the ignoreUnfixable query parameter, defaulting to IGNORE_UNFIXABLE.
+func ignoreUnfixable(r *http.Request) bool {
+	if v := r.URL.Query().Get("ignoreUnfixable"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return config.Get().IgnoreUnfixable
+}
+
+// withoutUnfixable returns a copy of a vulnerability report whose summary, status and
+// effective severity count only findings with a fixed version. The full summary is kept
+// as report.fullSummary and the findings themselves stay untouched, so unfixable
+// vulnerabilities remain retrievable. Reports without findings are returned as they are.
+func withoutUnfixable(r Report) Report {
+	data, ok := r.Data.(map[string]interface{})
+	if !ok {
+		return r
+	}
+	body, ok := data["report"].(map[string]interface{})
+	if !ok {
+		return r
+	}
+	findings := kubernetes.ExtractFindings(data)
+	if findings == nil {
+		return r
+	}
+
+	fixable := make([]kubernetes.Finding, 0, len(findings))
+	var totals SeverityTotals
+	for _, f := range findings {
+		if f.FixedVersion == "" {
+			continue
+		}
+		fixable = append(fixable, f)
+		switch strings.ToUpper(f.Severity) {
+		case "CRITICAL":
+			totals.Critical++
+		case "HIGH":
+			totals.High++
+		case "MEDIUM":
+			totals.Medium++
+		case "LOW":
+			totals.Low++
+		}
+	}
+
+	summary := make(map[string]interface{})
+	if full, ok := body["summary"].(map[string]interface{}); ok {
+		for k, v := range full {
+			summary[k] = v
+		}
+	}
+	summary["criticalCount"] = float64(totals.Critical)
+	summary["highCount"] = float64(totals.High)
+	summary["mediumCount"] = float64(totals.Medium)
+	summary["lowCount"] = float64(totals.Low)
+	summary["unknownCount"] = float64(0)
+
+	newBody := make(map[string]interface{}, len(body)+1)
+	for k, v := range body {
+		newBody[k] = v
+	}
+	newBody["fullSummary"] = body["summary"]
+	newBody["summary"] = summary
+	newData := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		newData[k] = v
+	}
+	newData["report"] = newBody
+	r.Data = newData
+
+	switch {
+	case totals.Critical > 0:
+		r.Status = "Critical"
+	case totals.High > 0:
+		r.Status = "High"
+	case totals.Medium > 0:
+		r.Status = "Medium"
+	case totals.Low > 0:
+		r.Status = "Low"
+	default:
+		r.Status = "None"
+	}
+	if r.EffectiveSummary != nil {
+		sev, effective := effectiveSeverity(fixable, severityModifiers(r.Cluster, r.Namespace))
+		r.EffectiveSeverity = sev
+		r.EffectiveSummary = &effective
+	}
+	return r
+}
+
+// reportsWithoutUnfixable applies withoutUnfixable to copies; the input may be shared
+// with the cache.
+func reportsWithoutUnfixable(reports []Report) []Report {
+	result := make([]Report, len(reports))
+	for i, r := range reports {
+		result[i] = withoutUnfixable(r)
+	}
+	return result
+}
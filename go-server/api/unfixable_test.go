@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func vulnReport(name string, findings ...map[string]interface{}) Report {
+	items := make([]interface{}, len(findings))
+	critical, high := 0.0, 0.0
+	for i, f := range findings {
+		items[i] = f
+		switch f["severity"] {
+		case "CRITICAL":
+			critical++
+		case "HIGH":
+			high++
+		}
+	}
+	return Report{Name: name, Cluster: "c1", Namespace: "apps", Type: "vulnerabilityreports", Status: "Critical", Data: map[string]interface{}{
+		"report": map[string]interface{}{
+			"summary":  map[string]interface{}{"criticalCount": critical, "highCount": high},
+			"findings": items,
+		},
+	}}
+}
+
+func TestWithoutUnfixable(t *testing.T) {
+	r := vulnReport("api",
+		map[string]interface{}{"vulnerabilityID": "CVE-1", "severity": "CRITICAL", "resource": "glibc"},
+		map[string]interface{}{"vulnerabilityID": "CVE-2", "severity": "HIGH", "resource": "openssl", "fixedVersion": "3.0.2"},
+	)
+	got := withoutUnfixable(r)
+	if c, h, _, _ := extractSummaryCounts(got); c != 0 || h != 1 {
+		t.Fatalf("expected only the fixable HIGH to count, got critical=%d high=%d", c, h)
+	}
+	if got.Status != "High" {
+		t.Fatalf("expected status High, got %s", got.Status)
+	}
+	body := got.Data.(map[string]interface{})["report"].(map[string]interface{})
+	if full := body["fullSummary"].(map[string]interface{}); full["criticalCount"] != 1.0 {
+		t.Fatalf("full summary should be kept, got %v", full)
+	}
+	if len(body["findings"].([]interface{})) != 2 {
+		t.Fatal("unfixable findings must stay retrievable")
+	}
+	if c, _, _, _ := extractSummaryCounts(r); c != 1 || r.Status != "Critical" {
+		t.Fatal("the cached report must not be modified")
+	}
+
+	noFindings := makeReport("audit", "c1", "apps", "configauditreports", 2)
+	if c, _, _, _ := extractSummaryCounts(withoutUnfixable(noFindings)); c != 2 {
+		t.Fatal("reports without findings keep their summary")
+	}
+}
+
+func TestListReports_IgnoreUnfixable(t *testing.T) {
+	cache := &stubCacheService{reports: map[string][]Report{"vulnerabilityreports": {
+		vulnReport("unfixable", map[string]interface{}{"vulnerabilityID": "CVE-1", "severity": "CRITICAL", "resource": "glibc"}),
+		vulnReport("fixable", map[string]interface{}{"vulnerabilityID": "CVE-2", "severity": "HIGH", "resource": "openssl", "fixedVersion": "3.0.2"}),
+	}}}
+	svc := NewQueryService(cache)
+
+	all := svc.ListReports(ReportQuery{Type: "vulnerabilityreports", Cluster: "ignore-unfixable-test", OnlyVulnerable: true, Page: 1, PageSize: 10})
+	ignored := svc.ListReports(ReportQuery{Type: "vulnerabilityreports", Cluster: "ignore-unfixable-test", OnlyVulnerable: true, IgnoreUnfixable: true, Page: 1, PageSize: 10})
+	if all.WithVulnerabilities != 2 || ignored.WithVulnerabilities != 1 || ignored.Items[0].Name != "fixable" {
+		t.Fatalf("unexpected results: all=%+v ignored=%+v", all, ignored)
+	}
+}
+
+func TestIgnoreUnfixableParam(t *testing.T) {
+	if !ignoreUnfixable(httptest.NewRequest("GET", "/api/v1/reports?ignoreUnfixable=true", nil)) {
+		t.Fatal("expected the query parameter to enable it")
+	}
+	if ignoreUnfixable(httptest.NewRequest("GET", "/api/v1/reports?ignoreUnfixable=false", nil)) {
+		t.Fatal("expected the query parameter to disable it")
+	}
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"trivy-ui/config"
+)
+
+// API versions. v1 wraps every payload in Response; v2 serves the same resources with
+// typed bodies and HTTP status codes only.
+const (
+	APIVersionV1 = "v1"
+	APIVersionV2 = "v2"
+)
+
+// v1DeprecatedAt is when /api/v2 was introduced and /api/v1 deprecated.
+var v1DeprecatedAt = time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+// unversionedV1Paths predate /api/v1 and are part of the v1 surface.
+var unversionedV1Paths = []string{"/api/clusters", "/api/report-types"}
+
+type APIVersionInfo struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	Status  string `json:"status"`
+	// Deprecated and Sunset are set for versions being phased out
+	Deprecated *time.Time `json:"deprecated,omitempty"`
+	Sunset     *time.Time `json:"sunset,omitempty"`
+}
+
+type APIVersions struct {
+	Current  string           `json:"current"`
+	Versions []APIVersionInfo `json:"versions"`
+}
+
+// isV1Path reports whether a request path belongs to the deprecated v1 API.
+func isV1Path(path string) bool {
+	if strings.HasPrefix(path, "/api/v1/") || path == "/api/v1" {
+		return true
+	}
+	for _, p := range unversionedV1Paths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// v1PathFor maps an /api/v2 path to the v1 route serving it. Cluster listing and the
+// cluster namespace routes lived outside /api/v1 and keep their v1 location.
+func v1PathFor(v2Path string) string {
+	rest := strings.TrimPrefix(v2Path, "/api/v2")
+	switch {
+	case rest == "/clusters", rest == "/report-types":
+		return "/api" + rest
+	case strings.HasPrefix(rest, "/clusters/"):
+		parts := strings.Split(strings.TrimPrefix(rest, "/clusters/"), "/")
+		if len(parts) == 2 && (parts[1] == "namespaces" || parts[1] == "operator-scope") {
+			return "/api" + rest
+		}
+	}
+	return "/api/v1" + rest
+}
+
+// setDeprecationHeaders marks a v1 response as deprecated (RFC 9745) with its sunset date
+// (RFC 8594) and links the v2 successor.
+func setDeprecationHeaders(h http.Header, path string, sunset time.Time) {
+	h.Set("Deprecation", fmt.Sprintf("@%d", v1DeprecatedAt.Unix()))
+	if !sunset.IsZero() {
+		h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+	successor := "/api/v2" + strings.TrimPrefix(strings.TrimPrefix(path, "/api/v1"), "/api")
+	h.Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+	h.Add("Link", `</api>; rel="deprecation"; type="application/json"`)
+}
+
+// apiVersions is the version negotiation document served at /api.
+func apiVersions(sunset time.Time) APIVersions {
+	deprecated := v1DeprecatedAt
+	v1 := APIVersionInfo{Version: APIVersionV1, Path: "/api/v1", Status: "deprecated", Deprecated: &deprecated}
+	if !sunset.IsZero() {
+		v1.Sunset = &sunset
+	}
+	return APIVersions{
+		Current: APIVersionV2,
+		Versions: []APIVersionInfo{
+			v1,
+			{Version: APIVersionV2, Path: "/api/v2", Status: "current"},
+		},
+	}
+}
+
+// GetAPIVersions handles GET /api.
+func (h *Handler) GetAPIVersions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(apiVersions(config.Get().APIV1Sunset))
+}
+
+// v2Error is the body of every failed v2 request.
+type v2Error struct {
+	Error struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// v2Page is the v2 form of PaginatedResponse; items replaces the ambiguous nested data.
+type v2Page struct {
+	Items               json.RawMessage `json:"items"`
+	Total               int             `json:"total"`
+	WithVulnerabilities int             `json:"withVulnerabilities,omitempty"`
+	Page                int             `json:"page"`
+	PageSize            int             `json:"pageSize"`
+	TotalPages          int             `json:"totalPages"`
+	HasNext             bool            `json:"hasNext"`
+	HasPrev             bool            `json:"hasPrev"`
+	Sort                string          `json:"sort,omitempty"`
+	Filters             json.RawMessage `json:"filters,omitempty"`
+}
+
+// v2ResponseWriter buffers JSON responses of v1 handlers so serveV2 can unwrap them.
+// Anything else, such as CSV exports, is streamed through untouched.
+type v2ResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	buffering bool
+	buf       bytes.Buffer
+	wrote     bool
+}
+
+func (w *v2ResponseWriter) WriteHeader(status int) {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	w.status = status
+	w.buffering = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *v2ResponseWriter) Write(b []byte) (int, error) {
+	if !w.wrote {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *v2ResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.buffering {
+		f.Flush()
+	}
+}
+
+// finish rewrites a buffered v1 envelope into its v2 form.
+func (w *v2ResponseWriter) finish() {
+	if !w.buffering {
+		return
+	}
+	body := w.buf.Bytes()
+	var envelope struct {
+		Code    *int            `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Code == nil {
+		// not an envelope (e.g. already typed); pass it on as is
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.Write(body)
+		return
+	}
+
+	var out interface{}
+	status := w.status
+	switch {
+	case *envelope.Code != CodeSuccess || status >= http.StatusBadRequest:
+		if status < http.StatusBadRequest {
+			status = http.StatusInternalServerError
+		}
+		var e v2Error
+		e.Error.Status = status
+		e.Error.Message = envelope.Message
+		out = e
+	case len(envelope.Data) == 0:
+		out = map[string]string{"message": envelope.Message}
+	default:
+		out = v2Data(envelope.Data)
+	}
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(status)
+	json.NewEncoder(w.ResponseWriter).Encode(out)
+}
+
+// v2Data renames the data array of paginated payloads to items.
+func v2Data(data json.RawMessage) interface{} {
+	var page struct {
+		Data                json.RawMessage `json:"data"`
+		Total               *int            `json:"total"`
+		WithVulnerabilities int             `json:"withVulnerabilities"`
+		Page                *int            `json:"page"`
+		PageSize            int             `json:"pageSize"`
+		TotalPages          int             `json:"totalPages"`
+		HasNext             bool            `json:"hasNext"`
+		HasPrev             bool            `json:"hasPrev"`
+		Sort                string          `json:"sort"`
+		Filters             json.RawMessage `json:"filters"`
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) && json.Unmarshal(data, &page) == nil &&
+		page.Data != nil && page.Total != nil && page.Page != nil {
+		return v2Page{
+			Items:               page.Data,
+			Total:               *page.Total,
+			WithVulnerabilities: page.WithVulnerabilities,
+			Page:                *page.Page,
+			PageSize:            page.PageSize,
+			TotalPages:          page.TotalPages,
+			HasNext:             page.HasNext,
+			HasPrev:             page.HasPrev,
+			Sort:                page.Sort,
+			Filters:             page.Filters,
+		}
+	}
+	return data
+}
+
+// serveV2 serves an /api/v2 request with the v1 route for the same resource and converts
+// the response to the v2 format.
+func (r *Router) serveV2(w http.ResponseWriter, req *http.Request) {
+	v1Req := req.Clone(req.Context())
+	v1Req.URL.Path = v1PathFor(req.URL.Path)
+	if req.URL.RawPath != "" {
+		v1Req.URL.RawPath = v1PathFor(req.URL.RawPath)
+	}
+	w.Header().Set("API-Version", APIVersionV2)
+	rw := &v2ResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	r.mux.ServeHTTP(rw, v1Req)
+	rw.finish()
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"trivy-ui/config"
+)
+
+func TestV1PathFor(t *testing.T) {
+	cases := map[string]string{
+		"/api/v2/reports":                    "/api/v1/reports",
+		"/api/v2/pss/controls":               "/api/v1/pss/controls",
+		"/api/v2/clusters":                   "/api/clusters",
+		"/api/v2/report-types":               "/api/report-types",
+		"/api/v2/clusters/prod/namespaces":   "/api/clusters/prod/namespaces",
+		"/api/v2/clusters/prod/trivy-db":     "/api/v1/clusters/prod/trivy-db",
+		"/api/v2/clusters/prod/namespaces/x": "/api/v1/clusters/prod/namespaces/x",
+	}
+	for in, want := range cases {
+		if got := v1PathFor(in); got != want {
+			t.Errorf("v1PathFor(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func newVersioningRouter(t *testing.T) *Router {
+	t.Helper()
+	cache := &stubCacheService{}
+	crdReg := config.GetGlobalRegistry()
+	crdReg.Register(config.ReportKind{Name: "vulnerabilityreports", Kind: "VulnerabilityReport", Namespaced: true})
+	return NewRouter(nil, t.TempDir(), cache, NewClusterRegistry(cache), crdReg)
+}
+
+func TestV1DeprecationHeaders(t *testing.T) {
+	router := newVersioningRouter(t)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/pss/controls", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if rec.Header().Get("Deprecation") == "" {
+		t.Error("Deprecation header missing")
+	}
+	if rec.Header().Get("API-Version") != APIVersionV1 {
+		t.Errorf("API-Version = %q", rec.Header().Get("API-Version"))
+	}
+	links := strings.Join(rec.Header().Values("Link"), ",")
+	if !strings.Contains(links, `</api/v2/pss/controls>; rel="successor-version"`) {
+		t.Errorf("Link = %q", links)
+	}
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Code != CodeSuccess {
+		t.Errorf("v1 body not wrapped: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Header().Get("Deprecation") != "" {
+		t.Error("unversioned route marked deprecated")
+	}
+}
+
+func TestV2Responses(t *testing.T) {
+	router := newVersioningRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/reports?type=vulnerabilityreports", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Deprecation") != "" || rec.Header().Get("API-Version") != APIVersionV2 {
+		t.Errorf("unexpected version headers: %v", rec.Header())
+	}
+	var page map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := page["items"]; !ok {
+		t.Errorf("page has no items: %s", rec.Body.String())
+	}
+	if _, ok := page["totalPages"]; !ok {
+		t.Errorf("page has no totalPages: %s", rec.Body.String())
+	}
+	if _, ok := page["code"]; ok {
+		t.Errorf("page still wrapped: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/reports", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var e v2Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil || e.Error.Status != http.StatusBadRequest || e.Error.Message == "" {
+		t.Errorf("error body = %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/pss/controls", nil))
+	var controls []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &controls); err != nil || len(controls) == 0 {
+		t.Errorf("controls body = %s", rec.Body.String())
+	}
+}
+
+func TestAPIVersionsDocument(t *testing.T) {
+	router := newVersioningRouter(t)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api", nil))
+
+	var doc APIVersions
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Current != APIVersionV2 || len(doc.Versions) != 2 {
+		t.Fatalf("doc = %+v", doc)
+	}
+	if v1 := doc.Versions[0]; v1.Status != "deprecated" || v1.Deprecated == nil {
+		t.Errorf("v1 = %+v", v1)
+	}
+}
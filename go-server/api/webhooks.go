@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/credentials"
+	"trivy-ui/kubernetes"
+	"trivy-ui/metrics"
+	"trivy-ui/pss"
+	"trivy-ui/utils"
+	"trivy-ui/webhooks"
+)
+
+const (
+	// webhookQueueSize bounds the events waiting for delivery; events beyond it are
+	// dropped so a slow receiver never holds up the informers
+	webhookQueueSize = 1000
+	webhookAttempts  = 3
+	webhookTimeout   = 10 * time.Second
+)
+
+var webhookEventDescriptions = map[string]string{
+	webhooks.EventReportCreated:   "A report appeared that trivy-ui had not seen before.",
+	webhooks.EventSeverityChanged: "A rescan changed the highest severity of a report.",
+	webhooks.EventPolicyViolated:  "A config audit report started to violate a Pod Security Standard, or a stricter one than before.",
+	webhooks.EventTest:            "Sent by POST /api/v1/webhooks/test.",
+}
+
+// WebhookEventType is an entry of the event catalog.
+type WebhookEventType struct {
+	Type        string         `json:"type"`
+	Description string         `json:"description"`
+	Sample      webhooks.Event `json:"sample"`
+}
+
+// WebhookDelivery is the result of sending an event to one URL.
+type WebhookDelivery struct {
+	URL   string `json:"url"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// webhookDispatcher sends events to WEBHOOK_URLS from a single worker, in the order they
+// happened.
+type webhookDispatcher struct {
+	queue  chan webhooks.Event
+	client *http.Client
+	// backoff is the wait before the second attempt, doubled for each one after
+	backoff time.Duration
+}
+
+var outboundWebhooks = newWebhookDispatcher()
+
+func newWebhookDispatcher() *webhookDispatcher {
+	d := &webhookDispatcher{
+		queue:   make(chan webhooks.Event, webhookQueueSize),
+		client:  &http.Client{Timeout: webhookTimeout},
+		backoff: time.Second,
+	}
+	go d.run()
+	return d
+}
+
+func (d *webhookDispatcher) run() {
+	for e := range d.queue {
+		cfg := config.Get()
+		for _, url := range cfg.WebhookURLs {
+			d.deliver(context.Background(), url, e)
+		}
+	}
+}
+
+// enqueue queues an event for the configured URLs if they subscribed to its type.
+func (d *webhookDispatcher) enqueue(e webhooks.Event) {
+	cfg := config.Get()
+	if len(cfg.WebhookURLs) == 0 {
+		return
+	}
+	if len(cfg.WebhookEvents) > 0 && !slices.Contains(cfg.WebhookEvents, e.Type) {
+		return
+	}
+	select {
+	case d.queue <- e:
+	default:
+		metrics.WebhookDeliveries.WithLabelValues(e.Type, "dropped").Inc()
+		utils.LogWarning("Webhook queue full, dropping event", map[string]interface{}{"event": e.Type, "id": e.ID})
+	}
+}
+
+// deliver sends an event to one URL, retrying failed attempts. Retries carry the same
+// delivery ID so receivers can drop duplicates.
+func (d *webhookDispatcher) deliver(ctx context.Context, url string, e webhooks.Event) error {
+	var err error
+	wait := d.backoff
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+		if err = webhooks.Send(ctx, d.client, url, credentials.Get(credentials.WebhookSecret), e); err == nil {
+			metrics.WebhookDeliveries.WithLabelValues(e.Type, "delivered").Inc()
+			return nil
+		}
+	}
+	metrics.WebhookDeliveries.WithLabelValues(e.Type, "failed").Inc()
+	utils.LogWarning("Webhook delivery failed", map[string]interface{}{
+		"event":  e.Type,
+		"id":     e.ID,
+		"target": redactTarget(url),
+		"error":  err.Error(),
+	})
+	return err
+}
+
+// webhookSeverities are the statuses severity_changed is sent for; compliance reports
+// pass or fail instead.
+var webhookSeverities = []string{"Critical", "High", "Medium", "Low", kubernetes.StatusNone, kubernetes.StatusUnknown}
+
+// publishWebhookEvents queues the outbound events of a cached report, given the entry it
+// replaced. Reports loaded during warmup are not new, so nothing is sent before it ends.
+func publishWebhookEvents(prev interface{}, report Report) {
+	if !IsWarmupCompleted() || len(config.Get().WebhookURLs) == 0 {
+		return
+	}
+	now := time.Now()
+	ref := webhooks.ReportRef{Cluster: report.Cluster, Namespace: report.Namespace, ReportType: report.Type, Name: report.Name}
+	c, h, m, l := extractSummaryCounts(report)
+	summary := webhooks.SeverityCounts{Critical: c, High: h, Medium: m, Low: l}
+
+	old, existed := convertCacheValue[Report](prev)
+	if !existed {
+		outboundWebhooks.enqueue(webhooks.NewEvent(webhooks.EventReportCreated, webhooks.ReportCreated{
+			Report:   ref,
+			Severity: report.Status,
+			Summary:  summary,
+			Image:    reportImageRef(report),
+		}, now))
+	} else if old.Status != report.Status && slices.Contains(webhookSeverities, old.Status) && slices.Contains(webhookSeverities, report.Status) {
+		outboundWebhooks.enqueue(webhooks.NewEvent(webhooks.EventSeverityChanged, webhooks.SeverityChanged{
+			Report:           ref,
+			PreviousSeverity: old.Status,
+			Severity:         report.Status,
+			Summary:          summary,
+			Image:            reportImageRef(report),
+		}, now))
+	}
+
+	if violated := policyViolation(old, existed, report); violated != nil {
+		violated.Report = ref
+		outboundWebhooks.enqueue(webhooks.NewEvent(webhooks.EventPolicyViolated, *violated, now))
+	}
+}
+
+// pssViolationRank orders the results of pss.Level by how badly a workload violates the
+// standards.
+var pssViolationRank = map[string]int{pss.LevelRestricted: 0, pss.LevelBaseline: 1, pss.LevelPrivileged: 2}
+
+// policyViolation returns the policy.violated data of a config audit report whose failed
+// checks violate a stricter standard than its previous version did, or nil.
+func policyViolation(old Report, existed bool, report Report) *webhooks.PolicyViolated {
+	data, ok := report.Data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	failed := kubernetes.FailedCheckIDs(data)
+	level := pss.Level(failed)
+	if level == pss.LevelRestricted {
+		return nil
+	}
+	if existed {
+		if oldData, ok := old.Data.(map[string]interface{}); ok && pssViolationRank[pss.Level(kubernetes.FailedCheckIDs(oldData))] >= pssViolationRank[level] {
+			return nil
+		}
+	}
+	violated := &webhooks.PolicyViolated{Policy: webhooks.PolicyPodSecurity, Level: pss.LevelRestricted, Controls: []webhooks.ViolatedControl{}}
+	if level == pss.LevelPrivileged {
+		violated.Level = pss.LevelBaseline
+	}
+	for _, id := range failed {
+		if control, ok := pss.Lookup(id); ok {
+			violated.Controls = append(violated.Controls, webhooks.ViolatedControl{CheckID: id, Control: control.Control, Level: control.Level})
+		}
+	}
+	return violated
+}
+
+// GetWebhookEvents handles GET /api/v1/webhooks/events: the event catalog with a sample of
+// each type, and the JSON Schema of the events.
+func (h *Handler) GetWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	cfg := config.Get()
+	now := time.Now()
+	types := make([]WebhookEventType, 0, len(webhooks.EventTypes))
+	for _, t := range webhooks.EventTypes {
+		sample, _ := webhooks.Sample(t, now)
+		types = append(types, WebhookEventType{Type: t, Description: webhookEventDescriptions[t], Sample: sample})
+	}
+	targets := make([]string, 0, len(cfg.WebhookURLs))
+	for _, url := range cfg.WebhookURLs {
+		targets = append(targets, redactTarget(url))
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data: map[string]interface{}{
+			"version":    webhooks.SchemaVersion,
+			"events":     types,
+			"schema":     json.RawMessage(webhooks.Schema),
+			"targets":    targets,
+			"subscribed": cfg.WebhookEvents,
+			"signed":     credentials.Get(credentials.WebhookSecret) != "",
+		},
+	})
+}
+
+// TestWebhook handles POST /api/v1/webhooks/test: sends a sample event, webhook.test unless
+// {"type": ...} asks for another, to every WEBHOOK_URLS target right away, whatever types
+// they subscribed to, and returns how each delivery went.
+func (h *Handler) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Type string `json:"type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if body.Type == "" {
+		body.Type = webhooks.EventTest
+	}
+	e, err := webhooks.Sample(body.Type, time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	urls := config.Get().WebhookURLs
+	if len(urls) == 0 {
+		writeError(w, http.StatusBadRequest, "No webhook configured, set WEBHOOK_URLS")
+		return
+	}
+
+	results := make([]WebhookDelivery, 0, len(urls))
+	for _, url := range urls {
+		result := WebhookDelivery{URL: redactTarget(url), OK: true}
+		if err := webhooks.Send(r.Context(), outboundWebhooks.client, url, credentials.Get(credentials.WebhookSecret), e); err != nil {
+			result.OK, result.Error = false, err.Error()
+		}
+		results = append(results, result)
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Code:    CodeSuccess,
+		Message: "Success",
+		Data:    map[string]interface{}{"event": e, "deliveries": results},
+	})
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/config"
+	"trivy-ui/pss"
+	"trivy-ui/webhooks"
+)
+
+func useTestWebhook(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	cfg := config.Get()
+	prevURLs, prevEvents := cfg.WebhookURLs, cfg.WebhookEvents
+	prevWarmup := warmupCompleted.Load()
+	cfg.WebhookURLs, cfg.WebhookEvents = []string{srv.URL}, nil
+	warmupCompleted.Store(true)
+	t.Setenv("WEBHOOK_SECRET", "s3cret")
+	t.Cleanup(func() {
+		srv.Close()
+		cfg.WebhookURLs, cfg.WebhookEvents = prevURLs, prevEvents
+		warmupCompleted.Store(prevWarmup)
+	})
+}
+
+func TestPolicyViolation(t *testing.T) {
+	clean := auditReport("deploy-api", "c1", "apps")
+	restricted := auditReport("deploy-api", "c1", "apps", "KSV012")
+	baseline := auditReport("deploy-api", "c1", "apps", "KSV012", "KSV017")
+
+	if v := policyViolation(Report{}, false, clean); v != nil {
+		t.Fatalf("expected no violation for a clean report, got %+v", v)
+	}
+	v := policyViolation(clean, true, restricted)
+	if v == nil || v.Level != pss.LevelRestricted || len(v.Controls) != 1 {
+		t.Fatalf("expected a restricted violation, got %+v", v)
+	}
+	v = policyViolation(restricted, true, baseline)
+	if v == nil || v.Level != pss.LevelBaseline || len(v.Controls) != 2 || v.Policy != webhooks.PolicyPodSecurity {
+		t.Fatalf("expected a baseline violation, got %+v", v)
+	}
+	if v := policyViolation(baseline, true, restricted); v != nil {
+		t.Fatalf("expected no event when the report improves, got %+v", v)
+	}
+	if v := policyViolation(baseline, true, baseline); v != nil {
+		t.Fatalf("expected no event when the level is unchanged, got %+v", v)
+	}
+}
+
+func TestPublishWebhookEvents(t *testing.T) {
+	received := make(chan webhooks.Event, 10)
+	useTestWebhook(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhooks.Verify(r.Header, "s3cret", body, time.Minute, time.Now()); err != nil {
+			t.Errorf("unsigned delivery: %v", err)
+		}
+		var e webhooks.Event
+		json.Unmarshal(body, &e)
+		received <- e
+	})
+	next := func() webhooks.Event {
+		t.Helper()
+		select {
+		case e := <-received:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event delivered")
+			return webhooks.Event{}
+		}
+	}
+
+	report := makeReport("replicaset-web", "c1", "default", "vulnerabilityreports", 0)
+	report.Status = "High"
+	publishWebhookEvents(nil, report)
+	if e := next(); e.Type != webhooks.EventReportCreated || e.Version != webhooks.SchemaVersion {
+		t.Fatalf("expected report.created, got %+v", e)
+	}
+
+	rescanned := makeReport("replicaset-web", "c1", "default", "vulnerabilityreports", 2)
+	rescanned.Status = "Critical"
+	publishWebhookEvents(report, rescanned)
+	e := next()
+	data, _ := e.Data.(map[string]interface{})
+	if e.Type != webhooks.EventSeverityChanged || data["previousSeverity"] != "High" || data["severity"] != "Critical" {
+		t.Fatalf("expected report.severity_changed, got %+v", e)
+	}
+
+	config.Get().WebhookEvents = []string{webhooks.EventPolicyViolated}
+	publishWebhookEvents(nil, report)
+	publishWebhookEvents(auditReport("deploy-api", "c1", "apps"), auditReport("deploy-api", "c1", "apps", "KSV017"))
+	if e := next(); e.Type != webhooks.EventPolicyViolated {
+		t.Fatalf("expected only subscribed events, got %+v", e)
+	}
+}
+
+func TestTestWebhook(t *testing.T) {
+	var event string
+	useTestWebhook(t, func(w http.ResponseWriter, r *http.Request) {
+		event = r.Header.Get(webhooks.HeaderEvent)
+	})
+	h := &Handler{}
+
+	rec := httptest.NewRecorder()
+	h.TestWebhook(rec, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/test", strings.NewReader(`{"type":"policy.violated"}`)))
+	var resp struct {
+		Data struct {
+			Deliveries []WebhookDelivery `json:"deliveries"`
+		} `json:"data"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusOK || len(resp.Data.Deliveries) != 1 || !resp.Data.Deliveries[0].OK || event != webhooks.EventPolicyViolated {
+		t.Fatalf("unexpected response %d %+v, event %q", rec.Code, resp.Data, event)
+	}
+
+	rec = httptest.NewRecorder()
+	h.TestWebhook(rec, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/test", nil))
+	if rec.Code != http.StatusOK || event != webhooks.EventTest {
+		t.Fatalf("expected webhook.test by default, got %d %q", rec.Code, event)
+	}
+
+	rec = httptest.NewRecorder()
+	h.TestWebhook(rec, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/test", strings.NewReader(`{"type":"report.deleted"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown types to be rejected, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"trivy-ui/kubernetes"
+)
+
+// runningOnly reads the runningOnly query parameter.
+func runningOnly(r *http.Request) bool {
+	b, _ := strconv.ParseBool(r.URL.Query().Get("runningOnly"))
+	return b
+}
+
+// warmWorkloadStates refreshes the workloads of the clusters a filter selects, so that
+// aggregations holding the cache lock only read them.
+func warmWorkloadStates(clusterFilter string) {
+	selected := clusterSet(clusterFilter)
+	for cluster := range GetAllClusterClients() {
+		if selected == nil || selected[cluster] {
+			clusterWorkloads.get(cluster)
+		}
+	}
+}
+
+// reportWorkloadState returns the run state of the workload a report belongs to, or nil
+// when the report is not about a workload or the workload is unknown.
+func reportWorkloadState(r Report) *kubernetes.WorkloadState {
+	kind, name := reportResource(r)
+	w, ok := workloadFor(r.Cluster, kind, r.Namespace, name)
+	if !ok {
+		return nil
+	}
+	return &w.State
+}
+
+// reportRunning reports whether a report's workload is active. Reports whose workload
+// state is unknown (pushed clusters, cluster-scoped reports, workloads deleted since the
+// scan) count as running, so runningOnly never hides what it cannot check.
+func reportRunning(r Report) bool {
+	state := reportWorkloadState(r)
+	return state == nil || state.Active()
+}
+
+// withWorkloadStates returns copies of the reports with the run state of their workload
+// set. Items may be shared with the query cache, so they are never modified in place.
+func withWorkloadStates(reports []Report) []Report {
+	result := make([]Report, len(reports))
+	for i, r := range reports {
+		r.WorkloadState = reportWorkloadState(r)
+		result[i] = r
+	}
+	return result
+}
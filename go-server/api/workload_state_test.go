@@ -0,0 +1,124 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"trivy-ui/kubernetes"
+)
+
+// useWorkloads seeds fresh workloads for a cluster.
+func useWorkloads(t *testing.T, cluster string, workloads map[string]kubernetes.Workload) {
+	t.Helper()
+	entry := clusterWorkloads.entry(cluster)
+	entry.mu.Lock()
+	entry.value, entry.fetchedAt = workloads, time.Now()
+	entry.mu.Unlock()
+	workloadStatesGeneration.Add(1)
+	t.Cleanup(func() { clusterWorkloads.forget(cluster) })
+}
+
+// useWorkloadStates seeds fresh workload states for a cluster.
+func useWorkloadStates(t *testing.T, cluster string, states map[string]kubernetes.WorkloadState) {
+	t.Helper()
+	workloads := make(map[string]kubernetes.Workload, len(states))
+	for key, state := range states {
+		workloads[key] = kubernetes.Workload{State: state}
+	}
+	useWorkloads(t, cluster, workloads)
+}
+
+func workloadReport(name, cluster, kind, workload string, critical float64) Report {
+	r := makeReport(name, cluster, "apps", "vulnerabilityreports", critical)
+	r.Data.(map[string]interface{})["metadata"] = map[string]interface{}{"labels": map[string]interface{}{
+		"trivy-operator.resource.kind": kind,
+		"trivy-operator.resource.name": workload,
+	}}
+	return r
+}
+
+func TestRunningOnly(t *testing.T) {
+	useWorkloadStates(t, "states", map[string]kubernetes.WorkloadState{
+		kubernetes.WorkloadKey("ReplicaSet", "apps", "web-1"): {State: kubernetes.WorkloadRunning, ReadyPods: 2},
+		kubernetes.WorkloadKey("ReplicaSet", "apps", "web-0"): {State: kubernetes.WorkloadScaledToZero},
+		kubernetes.WorkloadKey("CronJob", "apps", "backup"):   {State: kubernetes.WorkloadSuspended},
+		kubernetes.WorkloadKey("CronJob", "apps", "report"):   {State: kubernetes.WorkloadScheduled},
+	})
+	reports := []Report{
+		workloadReport("replicaset-web-1", "states", "ReplicaSet", "web-1", 1),
+		workloadReport("replicaset-web-0", "states", "ReplicaSet", "web-0", 5),
+		workloadReport("cronjob-backup", "states", "CronJob", "backup", 3),
+		workloadReport("cronjob-report", "states", "CronJob", "report", 1),
+		// deleted since the scan: kept, its state cannot be checked
+		workloadReport("replicaset-gone", "states", "ReplicaSet", "gone", 1),
+	}
+
+	svc := NewQueryService(&stubCacheService{reports: map[string][]Report{"runningonlyreports": reports}})
+	all := svc.ListReports(ReportQuery{Type: "runningonlyreports", Page: 1, PageSize: 10})
+	running := svc.ListReports(ReportQuery{Type: "runningonlyreports", RunningOnly: true, Page: 1, PageSize: 10})
+	if all.Total != 5 || running.Total != 3 {
+		t.Fatalf("got %d reports, %d running", all.Total, running.Total)
+	}
+	for _, r := range running.Items {
+		if r.Name == "replicaset-web-0" || r.Name == "cronjob-backup" {
+			t.Errorf("dormant workload %s listed", r.Name)
+		}
+	}
+
+	items := withWorkloadStates(all.Items)
+	for _, r := range items {
+		switch r.Name {
+		case "replicaset-gone":
+			if r.WorkloadState != nil {
+				t.Errorf("unknown workload has state %+v", r.WorkloadState)
+			}
+		case "replicaset-web-1":
+			if r.WorkloadState == nil || r.WorkloadState.ReadyPods != 2 {
+				t.Errorf("got %+v", r.WorkloadState)
+			}
+		}
+	}
+	if all.Items[0].WorkloadState != nil {
+		t.Error("query cache items were modified")
+	}
+
+	c := useTestCache(t)
+	for _, r := range reports {
+		c.Set(reportKey(r.Cluster, r.Namespace, r.Type, r.Name), r, time.Hour)
+	}
+	if o := c.GetOverviewData("states", false, reportRunning); o.TotalReports != 3 || o.SeverityTotals.Critical != 3 {
+		t.Errorf("running overview: %d reports, %d critical", o.TotalReports, o.SeverityTotals.Critical)
+	}
+}
+
+func TestWorkloadsFeedStatesExposureAndAnnotations(t *testing.T) {
+	key := kubernetes.WorkloadKey("ReplicaSet", "apps", "web-1")
+	useWorkloads(t, "workloads", map[string]kubernetes.Workload{key: {
+		Annotations: map[string]string{"team": "shop"},
+		Exposure:    kubernetes.Exposure{Privileged: true},
+		State:       kubernetes.WorkloadState{State: kubernetes.WorkloadRunning, ReadyPods: 1},
+	}})
+	r := workloadReport("replicaset-web-1", "workloads", "ReplicaSet", "web-1", 1)
+
+	if s := reportWorkloadState(r); s == nil || s.State != kubernetes.WorkloadRunning {
+		t.Errorf("state %+v", s)
+	}
+	if e := reportExposure(r); e == nil || !e.Privileged || e.Multiplier != 1+exposureWeightPrivileged {
+		t.Errorf("exposure %+v", e)
+	}
+	if a := workloadAnnotationsFor("workloads", "ReplicaSet", "apps", "web-1"); a["team"] != "shop" {
+		t.Errorf("annotations %v", a)
+	}
+
+	// only state changes invalidate runningOnly results
+	before := workloadStatesGeneration.Load()
+	old := map[string]kubernetes.Workload{key: {State: kubernetes.WorkloadState{State: kubernetes.WorkloadRunning}}}
+	clusterWorkloads.refreshed(old, map[string]kubernetes.Workload{key: {State: old[key].State, Annotations: map[string]string{"team": "ops"}}})
+	if workloadStatesGeneration.Load() != before {
+		t.Error("an annotation change bumped the states generation")
+	}
+	clusterWorkloads.refreshed(old, map[string]kubernetes.Workload{key: {State: kubernetes.WorkloadState{State: kubernetes.WorkloadScaledToZero}}})
+	if workloadStatesGeneration.Load() == before {
+		t.Error("a scale down kept the states generation")
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
+)
+
+// workloadsTTL is short because workloads scale far more often than their pod templates or
+// annotations change; all three come from the same lists.
+const workloadsTTL = 2 * time.Minute
+
+var (
+	// clusterWorkloads backs run states, exposure and owner annotations, so the API server
+	// sees one list per workload kind and TTL
+	clusterWorkloads = &perClusterCache[map[string]kubernetes.Workload]{
+		ttl:  workloadsTTL,
+		what: "workloads",
+		fetch: func(ctx context.Context, client *kubernetes.Client) (map[string]kubernetes.Workload, error) {
+			return client.GetWorkloads(ctx)
+		},
+		refreshed: func(old, fresh map[string]kubernetes.Workload) {
+			if !maps.EqualFunc(old, fresh, func(a, b kubernetes.Workload) bool { return a.State == b.State }) {
+				workloadStatesGeneration.Add(1)
+			}
+		},
+	}
+	// workloadStatesGeneration changes whenever a cluster's states do, so cached
+	// runningOnly results are not served past a scale up or down
+	workloadStatesGeneration atomic.Uint64
+)
+
+// perClusterCache keeps a value read from each cluster's client, refreshed at most once per
+// ttl. Pushed clusters have no client and get the zero value.
+type perClusterCache[T any] struct {
+	ttl time.Duration
+	// what names the value in warnings
+	what  string
+	fetch func(ctx context.Context, client *kubernetes.Client) (T, error)
+	// refreshed, when set, is called with the previous and the new value of every fetch
+	refreshed func(old, fresh T)
+
+	mu      sync.Mutex
+	entries map[string]*perClusterEntry[T]
+}
+
+type perClusterEntry[T any] struct {
+	mu        sync.Mutex
+	value     T
+	fetchedAt time.Time
+}
+
+func (c *perClusterCache[T]) entry(cluster string) *perClusterEntry[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*perClusterEntry[T])
+	}
+	entry := c.entries[cluster]
+	if entry == nil {
+		entry = &perClusterEntry[T]{}
+		c.entries[cluster] = entry
+	}
+	return entry
+}
+
+// get returns a cluster's value, fetching it first when it is older than the ttl.
+func (c *perClusterCache[T]) get(cluster string) T {
+	entry := c.entry(cluster)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if time.Since(entry.fetchedAt) > c.ttl {
+		cc := GetClusterClient(cluster)
+		if cc == nil || cc.Client == nil {
+			return entry.value
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		value, err := c.fetch(ctx, cc.Client)
+		cancel()
+		if err != nil {
+			utils.LogWarning("Failed to read "+c.what, map[string]interface{}{"cluster": cluster, "error": err.Error()})
+		}
+		if c.refreshed != nil {
+			c.refreshed(entry.value, value)
+		}
+		// keep what could be listed; also back off after failures
+		entry.value = value
+		entry.fetchedAt = time.Now()
+	}
+	return entry.value
+}
+
+// forget drops the value of a removed cluster.
+func (c *perClusterCache[T]) forget(cluster string) {
+	c.mu.Lock()
+	delete(c.entries, cluster)
+	c.mu.Unlock()
+}
+
+// workloadFor returns a workload from its cluster's cache, or false when the workload is
+// unknown.
+func workloadFor(cluster, kind, namespace, name string) (kubernetes.Workload, bool) {
+	if kind == "" || name == "" {
+		return kubernetes.Workload{}, false
+	}
+	w, ok := clusterWorkloads.get(cluster)[kubernetes.WorkloadKey(kind, namespace, name)]
+	return w, ok
+}
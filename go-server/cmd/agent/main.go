@@ -0,0 +1,103 @@
+// Push agent - runs inside a cluster that trivy-ui cannot reach and pushes report events to it
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"trivy-ui/agent"
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+	"trivy-ui/utils"
+)
+
+func main() {
+	serverURL := os.Getenv("TRIVY_UI_URL")
+	cluster := os.Getenv("AGENT_CLUSTER_NAME")
+	if serverURL == "" || cluster == "" {
+		utils.LogError("TRIVY_UI_URL and AGENT_CLUSTER_NAME are required", nil)
+		os.Exit(1)
+	}
+
+	tlsConfig, err := agentTLSConfig()
+	if err != nil {
+		utils.LogError("Invalid TLS configuration", map[string]interface{}{"error": err.Error()})
+		os.Exit(1)
+	}
+
+	client, err := kubernetes.NewClient(os.Getenv("KUBECONFIG"))
+	if err != nil {
+		utils.LogError("Failed to create Kubernetes client", map[string]interface{}{"error": err.Error()})
+		os.Exit(1)
+	}
+
+	registry := config.GetGlobalRegistry()
+	for attempt := 1; ; attempt++ {
+		if err := registry.DiscoverCRDs(context.Background(), client.Config()); err == nil {
+			break
+		} else if attempt >= 20 {
+			utils.LogError("Failed to discover Trivy Operator CRDs", map[string]interface{}{"error": err.Error()})
+			os.Exit(1)
+		}
+		time.Sleep(30 * time.Second)
+	}
+
+	pusher := agent.NewPusher(agent.PusherOptions{
+		ServerURL: serverURL,
+		Cluster:   cluster,
+		Token:     os.Getenv("AGENT_TOKEN"),
+		TLSConfig: tlsConfig,
+		ClusterInfo: func(ctx context.Context) (string, []string) {
+			version := ""
+			if info, err := client.Clientset().Discovery().ServerVersion(); err == nil {
+				version = info.GitVersion
+			}
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			namespaces, _ := client.GetNamespaces(ctx)
+			return version, namespaces
+		},
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go pusher.Run(ctx)
+
+	if err := client.StartInformer(cluster, pusher); err != nil {
+		utils.LogWarning("Failed to start informer", map[string]interface{}{"cluster": cluster, "error": err.Error()})
+	}
+	utils.LogInfo("Agent started", map[string]interface{}{"cluster": cluster, "server": serverURL})
+
+	<-ctx.Done()
+	client.StopInformer()
+}
+
+// agentTLSConfig loads the client certificate for mTLS and an optional CA for the server certificate.
+func agentTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile, keyFile := os.Getenv("AGENT_CERT_FILE"), os.Getenv("AGENT_KEY_FILE"); certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if caFile := os.Getenv("AGENT_SERVER_CA_FILE"); caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read server CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,235 @@
+// Load generator - pushes synthetic clusters and reports to trivy-ui through the agent
+// endpoint and measures the latency of the list and summary endpoints under load
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"trivy-ui/agent"
+	"trivy-ui/loadgen"
+)
+
+// queryPaths are the endpoints timed after the push, as the dashboard requests them.
+var queryPaths = []string{
+	"/api/v1/reports?type=" + loadgen.ReportType + "&page=1&pageSize=50",
+	"/api/v1/reports?type=" + loadgen.ReportType + "&page=1&pageSize=50&onlyVulnerable=true&sort=-effectiveSeverity",
+	"/api/v1/reports?type=" + loadgen.ReportType + "&page=1&pageSize=50&search=app-000",
+	"/api/v1/overview",
+	"/api/v1/fleet/summary",
+	"/api/clusters",
+}
+
+func main() {
+	var opts loadgen.Options
+	server := flag.String("url", envOr("TRIVY_UI_URL", "http://localhost:8080"), "trivy-ui base URL")
+	token := flag.String("token", envOr("LOADGEN_TOKEN", "loadgen"), "agent token secret; cluster <name> authenticates with <secret>-<name>")
+	apiToken := flag.String("api-token", os.Getenv("TRIVY_UI_TOKEN"), "bearer token for the query endpoints when AUTH_MODE is set")
+	flag.IntVar(&opts.Clusters, "clusters", loadgen.DefaultClusters, "number of synthetic clusters")
+	flag.IntVar(&opts.ReportsPerCluster, "reports", loadgen.DefaultReportsPerCluster, "reports per cluster")
+	flag.IntVar(&opts.Namespaces, "namespaces", loadgen.DefaultNamespaces, "namespaces per cluster")
+	flag.IntVar(&opts.FindingsPerReport, "findings", loadgen.DefaultFindingsPerReport, "maximum findings per report")
+	flag.Int64Var(&opts.Seed, "seed", 1, "random seed; the same seed generates the same data")
+	batchSize := flag.Int("batch", 500, "events per push request")
+	printTokens := flag.Bool("print-tokens", false, "print the AGENT_TOKENS value the server needs and exit")
+	skipPush := flag.Bool("skip-push", false, "only run queries against data pushed earlier")
+	rounds := flag.Int("queries", 20, "query rounds per endpoint after pushing; 0 skips querying")
+	concurrency := flag.Int("concurrency", 4, "concurrent requests per endpoint")
+	flag.Parse()
+
+	if *printTokens {
+		fmt.Println("AGENT_TOKENS=" + agentTokens(opts.Clusters, *token))
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	client := &http.Client{Timeout: time.Minute}
+	base := strings.TrimSuffix(*server, "/")
+
+	if !*skipPush {
+		start := time.Now()
+		events, err := push(ctx, client, base, *token, opts, *batchSize)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "push failed:", err)
+			os.Exit(1)
+		}
+		elapsed := time.Since(start)
+		fmt.Printf("pushed %d events for %d clusters x %d reports in %s (%.0f events/s)\n",
+			events, opts.Clusters, opts.ReportsPerCluster, elapsed.Round(time.Millisecond), float64(events)/elapsed.Seconds())
+	}
+
+	if *rounds > 0 {
+		fmt.Printf("%-90s %8s %8s %8s %8s %6s\n", "endpoint", "p50", "p95", "p99", "max", "errors")
+		for _, path := range queryPaths {
+			if ctx.Err() != nil {
+				return
+			}
+			s := measure(ctx, client, base+path, *apiToken, *rounds, *concurrency)
+			fmt.Printf("%-90s %8s %8s %8s %8s %6d\n", path, s.percentile(50), s.percentile(95), s.percentile(99), s.percentile(100), s.errors)
+		}
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func clusterToken(secret, cluster string) string {
+	return secret + "-" + cluster
+}
+
+func agentTokens(clusters int, secret string) string {
+	if clusters <= 0 {
+		clusters = loadgen.DefaultClusters
+	}
+	entries := make([]string, clusters)
+	for i := range entries {
+		name := loadgen.ClusterName(i)
+		entries[i] = name + "=" + clusterToken(secret, name)
+	}
+	return strings.Join(entries, ",")
+}
+
+// push sends every cluster's batches, one goroutine per cluster like real agents.
+func push(ctx context.Context, client *http.Client, base, secret string, opts loadgen.Options, batchSize int) (int, error) {
+	if opts.Clusters <= 0 {
+		opts.Clusters = loadgen.DefaultClusters
+	}
+	var (
+		mu       sync.Mutex
+		total    int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < opts.Clusters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := loadgen.ClusterName(i)
+			for _, batch := range loadgen.Batches(opts, i, batchSize) {
+				if err := postBatch(ctx, client, base, clusterToken(secret, name), batch); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("cluster %s: %w", name, err)
+					}
+					mu.Unlock()
+					return
+				}
+				mu.Lock()
+				total += len(batch.Events)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return total, firstErr
+}
+
+func postBatch(ctx context.Context, client *http.Client, base, token string, batch agent.Batch) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+agent.PushPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+type latencies struct {
+	durations []time.Duration
+	errors    int
+}
+
+func (l latencies) percentile(p int) time.Duration {
+	if len(l.durations) == 0 {
+		return 0
+	}
+	idx := (len(l.durations)*p + 99) / 100
+	return l.durations[max(idx-1, 0)].Round(10 * time.Microsecond)
+}
+
+// measure requests url rounds times from concurrency workers and returns the sorted
+// latencies of successful requests.
+func measure(ctx context.Context, client *http.Client, url, token string, rounds, concurrency int) latencies {
+	var (
+		mu     sync.Mutex
+		result latencies
+		wg     sync.WaitGroup
+	)
+	jobs := make(chan struct{})
+	for w := 0; w < max(concurrency, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				d, err := get(ctx, client, url, token)
+				mu.Lock()
+				if err != nil {
+					result.errors++
+				} else {
+					result.durations = append(result.durations, d)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < rounds && ctx.Err() == nil; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+	wg.Wait()
+	sort.Slice(result.durations, func(i, j int) bool { return result.durations[i] < result.durations[j] })
+	return result
+}
+
+func get(ctx context.Context, client *http.Client, url, token string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s", resp.Status)
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	"trivy-ui/api"
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+	"trivy-ui/store"
+)
+
+// verifyKubeconfigTimeout bounds how long verify-kubeconfigs waits for each API server.
+const verifyKubeconfigTimeout = 10 * time.Second
+
+// subcommand is a maintenance task run instead of the server, e.g. from an initContainer.
+// It returns the process exit code.
+type subcommand struct {
+	usage string
+	run   func(args []string) int
+}
+
+var subcommands = map[string]subcommand{
+	"migrate":            {"apply database migrations to DB_PATH and exit", runMigrate},
+	"compact-cache":      {"rewrite the cache file without expired and excluded entries", runCompactCache},
+	"export":             {"export cached reports as csv or json (--cluster, --type, --namespace, --filter, --format, --output)", runExport},
+	"verify-kubeconfigs": {"check every kubeconfig in KUBECONFIG_DIR reaches its API server (--dir)", runVerifyKubeconfigs},
+}
+
+// runSubcommand runs the subcommand named by the first argument, if there is one, and
+// reports whether it did along with its exit code.
+func runSubcommand(args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	if args[0] == "help" {
+		printSubcommands(os.Stdout)
+		return 0, true
+	}
+	cmd, ok := subcommands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	return cmd.run(args[1:]), true
+}
+
+func printSubcommands(w io.Writer) {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(w, "Usage: trivy-ui [--selftest] | trivy-ui <command> [flags]")
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-20s %s\n", name, subcommands[name].usage)
+	}
+}
+
+// printResult writes a command's result as indented JSON, like --selftest.
+func printResult(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+func commandError(command string, err error) int {
+	fmt.Fprintf(os.Stderr, "trivy-ui %s: %v\n", command, err)
+	return 1
+}
+
+func runMigrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	path := config.Get().DBPath
+	st, err := store.Open(path)
+	if err != nil {
+		return commandError("migrate", err)
+	}
+	defer st.Close()
+	current, latest, err := st.SchemaVersion(context.Background())
+	if err != nil {
+		return commandError("migrate", err)
+	}
+	printResult(map[string]interface{}{"path": st.Path(), "schemaVersion": current, "latestVersion": latest})
+	if current != latest {
+		return commandError("migrate", fmt.Errorf("schema version %d, expected %d", current, latest))
+	}
+	return 0
+}
+
+func runCompactCache(args []string) int {
+	fs := flag.NewFlagSet("compact-cache", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	result, err := api.CompactCache()
+	if err != nil {
+		return commandError("compact-cache", err)
+	}
+	printResult(result)
+	return 0
+}
+
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var q store.ExportQuery
+	fs.StringVar(&q.Cluster, "cluster", "", "cluster to export; all clusters when empty")
+	fs.StringVar(&q.Type, "type", "vulnerabilityreports", "report type, by name, kind or short name")
+	namespaces := fs.String("namespace", "", "comma-separated namespaces")
+	fs.StringVar(&q.Filter, "filter", "", "filter expression, e.g. \"critical > 0\"")
+	format := fs.String("format", api.ExportFormatCSV, "csv or json")
+	output := fs.String("output", "", "file to write; stdout when empty, a directory keeps the generated name")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	for _, ns := range strings.Split(*namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			q.Namespaces = append(q.Namespaces, ns)
+		}
+	}
+
+	file, err := api.ExportCachedReports(q, *format, time.Now())
+	if err != nil {
+		return commandError("export", err)
+	}
+	if *output == "" {
+		os.Stdout.Write(file.Data)
+		return 0
+	}
+	path := *output
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, file.Name)
+	}
+	if err := os.WriteFile(path, file.Data, 0644); err != nil {
+		return commandError("export", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s (%d bytes)\n", path, len(file.Data))
+	return 0
+}
+
+// KubeconfigCheck is the outcome of verifying one kubeconfig file.
+type KubeconfigCheck struct {
+	File       string `json:"file"`
+	Cluster    string `json:"cluster,omitempty"`
+	Server     string `json:"server,omitempty"`
+	Namespaces int    `json:"namespaces,omitempty"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+}
+
+func runVerifyKubeconfigs(args []string) int {
+	fs := flag.NewFlagSet("verify-kubeconfigs", flag.ContinueOnError)
+	dir := fs.String("dir", kubeconfigDir(), "directory of kubeconfig files")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	files, err := kubeconfigFiles(*dir)
+	if err != nil {
+		return commandError("verify-kubeconfigs", err)
+	}
+	checks := make([]KubeconfigCheck, 0, len(files))
+	failed := false
+	for _, path := range files {
+		check := verifyKubeconfig(path)
+		failed = failed || !check.OK
+		checks = append(checks, check)
+	}
+	printResult(checks)
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func verifyKubeconfig(path string) KubeconfigCheck {
+	check := KubeconfigCheck{File: filepath.Base(path)}
+	raw, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	if ctx := raw.Contexts[raw.CurrentContext]; ctx != nil {
+		check.Cluster = ctx.Cluster
+		if c := raw.Clusters[ctx.Cluster]; c != nil {
+			check.Server = c.Server
+		}
+	}
+	client, err := kubernetes.NewClient(path)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), verifyKubeconfigTimeout)
+	defer cancel()
+	namespaces, err := client.GetNamespaces(ctx)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	check.Namespaces, check.OK = len(namespaces), true
+	return check
+}
+
+// kubeconfigDir is the directory kubeconfig files are loaded from, KUBECONFIG_DIR or one
+// of its older spellings.
+func kubeconfigDir() string {
+	for _, key := range []string{"KUBECONFIG_DIR", "KUBECONFIGDIR", "KUBE_CONFIG_DIR"} {
+		if dir := os.Getenv(key); dir != "" {
+			return dir
+		}
+	}
+	return "/kubeconfigs"
+}
+
+// kubeconfigFiles lists the files of a kubeconfig directory, skipping hidden ones such as
+// the ..data links of mounted Secrets.
+func kubeconfigFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	return files, nil
+}
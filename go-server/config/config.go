@@ -1,9 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
+
+	"trivy-ui/utils"
 )
 
 var config *Config
@@ -13,8 +18,183 @@ type Config struct {
 	Port       int
 	DataPath   string
 	StaticPath string
+	DBPath     string
+	SLAWindows map[string]time.Duration
+	// ArchiveDeleted keeps deleted reports (e.g. removed by operator TTL) in the store
+	ArchiveDeleted bool
+	// ReportRetention is how long the history and archived reports of each report kind are
+	// kept, by kind name; kinds not listed keep them for DefaultReportRetention. 0 keeps
+	// them indefinitely
+	ReportRetention        map[string]time.Duration
+	DefaultReportRetention time.Duration
+
+	IssueProvider string // "github" or "gitlab"; empty disables issue creation
+	IssueAPIURL   string
+	// IssueRepos maps team names to repositories; the "default" entry is used for unmapped teams
+	IssueRepos    map[string]string
+	IssueTemplate string
+	IssueLabels   []string
+
+	// AgentTokens maps cluster names to the bootstrap tokens their push agents present
+	AgentTokens map[string]string
+	// ReportDirs maps cluster names to directories of exported report files served instead
+	// of a live cluster
+	ReportDirs map[string]string
+	// AgentCAFile enables mTLS agent authentication; the client certificate CN is the cluster name
+	AgentCAFile string
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// AuthMode is "none" (everything public) or "mixed" (reads public, writes need a token)
+	AuthMode string
+	// AuthTokens maps user names to the bearer tokens accepted for mutating requests
+	AuthTokens map[string]string
+	// ShareLinkSecret signs the read-only share links of /api/v1/share; they are disabled
+	// without it. Changing it revokes every link issued
+	ShareLinkSecret string
+	// ShareLinkMaxTTL is the longest a share link can stay valid
+	ShareLinkMaxTTL time.Duration
+
+	// ExcludeNamespaces hides matching namespaces from ingest, listings and aggregations
+	ExcludeNamespaces *NamespaceMatcher
+	// ReportKinds are the report kinds informers watch, starting from DISABLED_REPORT_KINDS
+	// and changed at runtime through /api/v1/admin/report-kinds
+	ReportKinds *KindToggles
+	// SeverityRulesFile holds CVSS environmental modifiers per namespace label selector
+	SeverityRulesFile string
+	// NotificationRoutesFile holds the routes of "routed" scheduled exports: destinations by
+	// namespace label and workload owner annotation selectors, and a default route
+	NotificationRoutesFile string
+	// SignaturePolicyFile holds the public keys and keyless identities image signatures
+	// and attestations are verified against
+	SignaturePolicyFile string
+	// UIConfigFile holds the branding, severity colors and order and default filters
+	// served to the frontend by /api/v1/ui-config
+	UIConfigFile string
+
+	// SMTP and S3 settings used by scheduled export destinations; their credentials
+	// are resolved through the credentials package
+	SMTPHost   string
+	SMTPPort   int
+	SMTPFrom   string
+	S3Endpoint string
+	S3Region   string
+
+	// JobWorkers run queued jobs such as large exports; JobRetention is how long finished
+	// jobs and their results are kept
+	JobWorkers   int
+	JobRetention time.Duration
+
+	// DefectDojoURL enables pushing findings to DefectDojo; its API key is resolved
+	// through the credentials package
+	DefectDojoURL         string
+	DefectDojoProductType string
+	// DefectDojoProduct and DefectDojoEngagement are templates with {cluster} and
+	// {namespace} placeholders
+	DefectDojoProduct    string
+	DefectDojoEngagement string
+	// DefectDojoProducts maps a cluster or cluster/namespace to a fixed product name
+	DefectDojoProducts map[string]string
+
+	// TrivyServerURL enables on-demand image scans with POST /api/v1/scan, analysed by the
+	// trivy CLI in client mode against this trivy server
+	TrivyServerURL string
+	// TrivyBinary is the trivy executable the scans run
+	TrivyBinary string
+	// TrivyScanTimeout bounds one on-demand scan, image pull included
+	TrivyScanTimeout time.Duration
+
+	// CredentialsDir holds one file per integration credential, e.g. a mounted Secret
+	CredentialsDir string
+	// CredentialsSecret is a "namespace/name" or "name" Secret read through the API
+	CredentialsSecret string
+	// CredentialsRefresh is how often CredentialsSecret is re-read
+	CredentialsRefresh time.Duration
+
+	// KubeconfigSecrets enables in-cluster discovery of clusters from labeled Secrets
+	KubeconfigSecrets bool
+	// KubeconfigSecretNamespace is watched for kubeconfig Secrets; defaults to the pod's namespace
+	KubeconfigSecretNamespace string
+	// ClusterRetention is how long the cached reports of a removed cluster are kept for
+	// restoring it; 0 purges them on removal
+	ClusterRetention time.Duration
+	// ClusterTags are key/value tags of clusters, e.g. region or tier, which reports can be
+	// filtered by; tags set through the API take precedence
+	ClusterTags map[string]map[string]string
+
+	// IngestMode is "kubernetes" (informers), or "file"/"api" when reports arrive without
+	// cluster clients; it decides what readiness waits for
+	IngestMode string
+
+	// OversizedReportBytes is the serialized size above which report details keep their
+	// large fields in the database instead of memory; 0 disables
+	OversizedReportBytes int
+
+	// InformerWorkers apply informer events to the cache concurrently; 0 applies them on the
+	// informer's goroutine
+	InformerWorkers int
+	// InformerQueueSize is how many events wait per worker before informers block
+	InformerQueueSize int
+
+	// SaveInterval is how often the cache is saved to cache.json when it changed; 0 saves
+	// on shutdown only
+	SaveInterval time.Duration
+	// SnapshotPeer is the URL of a replica whose cache a replica starting without one
+	// downloads, so it serves right away instead of after listing every cluster
+	SnapshotPeer string
+
+	// ReconcileInterval is how often informer stores are compared with the cache; 0 disables
+	ReconcileInterval time.Duration
+	// ReconcileRate caps the reports repaired per second by a reconcile run
+	ReconcileRate float64
+
+	// LinkTemplates maps link names to URL templates with {{variable}} placeholders
+	LinkTemplates map[string]string
+
+	// IgnoreUnfixable drops findings without a fixed version from counts, summaries and statuses
+	IgnoreUnfixable bool
+
+	// APIV1Sunset is announced in the Sunset header of /api/v1 responses; zero omits it
+	APIV1Sunset time.Time
+
+	// TrivyDBMaxAge is the age after which a cluster's vulnerability DB is reported as stale
+	TrivyDBMaxAge time.Duration
+	// ImageStaleAge is the image age from which /api/v1/images/hygiene flags images as stale
+	ImageStaleAge time.Duration
+
+	// AlertClusterLabels are the Alertmanager alert labels naming a cluster, tried in order
+	AlertClusterLabels []string
+
+	// WebhookURLs receive the signed events of the webhooks package, see
+	// /api/v1/webhooks/events
+	WebhookURLs []string
+	// WebhookEvents are the event types sent to WebhookURLs; empty sends all of them
+	WebhookEvents []string
+
+	// DemoMode replaces cluster names, namespaces and image repositories in API traffic with
+	// pseudonyms derived from DemoSeed
+	DemoMode bool
+	DemoSeed string
+
+	// Telemetry enables the opt-in anonymous usage statistics, see /api/v1/telemetry/preview
+	Telemetry         bool
+	TelemetryEndpoint string
+	TelemetryInterval time.Duration
 }
 
+const (
+	AuthModeNone  = "none"
+	AuthModeMixed = "mixed"
+)
+
+const (
+	IngestModeKubernetes = "kubernetes"
+	IngestModeFile       = "file"
+	IngestModeAPI        = "api"
+)
+
+const defaultSLAWindows = "CRITICAL=7d,HIGH=30d,MEDIUM=90d,LOW=180d"
+
 func Get() *Config {
 	if config == nil {
 		config = &Config{
@@ -23,10 +203,153 @@ func Get() *Config {
 			DataPath:   getEnv("DATA_PATH", "."),
 			StaticPath: getEnv("STATIC_PATH", "static"),
 		}
+		config.DBPath = getEnv("DB_PATH", filepath.Join(config.DataPath, "trivy-ui.db"))
+		windows, err := ParseSLAWindows(getEnv("SLA_WINDOWS", defaultSLAWindows))
+		if err != nil {
+			utils.LogWarning("Invalid SLA_WINDOWS, using defaults", map[string]interface{}{"error": err.Error()})
+			windows, _ = ParseSLAWindows(defaultSLAWindows)
+		}
+		config.SLAWindows = windows
+		config.ArchiveDeleted = getEnvBool("ARCHIVE_DELETED_REPORTS", false)
+		retention, err := ParseReportRetention(getEnv("REPORT_RETENTION", ""))
+		if err != nil {
+			utils.LogWarning("Invalid REPORT_RETENTION entry ignored", map[string]interface{}{"error": err.Error()})
+		}
+		config.ReportRetention = retention
+		config.DefaultReportRetention = getEnvDuration("REPORT_RETENTION_DEFAULT", 0)
+		config.IssueProvider = strings.ToLower(getEnv("ISSUE_PROVIDER", ""))
+		config.IssueAPIURL = getEnv("ISSUE_API_URL", "")
+		repos, err := ParseKeyValues(getEnv("ISSUE_REPOS", ""))
+		if err != nil {
+			utils.LogWarning("Invalid ISSUE_REPOS, issue creation needs a repository mapping", map[string]interface{}{"error": err.Error()})
+		}
+		config.IssueRepos = repos
+		config.IssueTemplate = getEnv("ISSUE_TEMPLATE", "")
+		config.IssueLabels = splitList(getEnv("ISSUE_LABELS", "security"))
+		tokens, err := ParseKeyValues(getEnv("AGENT_TOKENS", ""))
+		if err != nil {
+			utils.LogWarning("Invalid AGENT_TOKENS entry ignored", map[string]interface{}{"error": err.Error()})
+		}
+		config.AgentTokens = tokens
+		reportDirs, err := ParseKeyValues(getEnv("REPORT_DIRS", ""))
+		if err != nil {
+			utils.LogWarning("Invalid REPORT_DIRS entry ignored", map[string]interface{}{"error": err.Error()})
+		}
+		config.ReportDirs = reportDirs
+		config.AgentCAFile = getEnv("AGENT_CA_FILE", "")
+		config.TLSCertFile = getEnv("TLS_CERT_FILE", "")
+		config.TLSKeyFile = getEnv("TLS_KEY_FILE", "")
+		config.AuthMode = strings.ToLower(getEnv("AUTH_MODE", AuthModeNone))
+		if config.AuthMode != AuthModeNone && config.AuthMode != AuthModeMixed {
+			// fail closed: a typo must not silently leave writes open
+			utils.LogWarning("Unknown AUTH_MODE, using mixed", map[string]interface{}{"value": config.AuthMode})
+			config.AuthMode = AuthModeMixed
+		}
+		authTokens, err := ParseKeyValues(getEnv("AUTH_TOKENS", ""))
+		if err != nil {
+			utils.LogWarning("Invalid AUTH_TOKENS entry ignored", map[string]interface{}{"error": err.Error()})
+		}
+		config.AuthTokens = authTokens
+		config.ShareLinkSecret = getEnv("SHARE_LINK_SECRET", "")
+		config.ShareLinkMaxTTL = getEnvDuration("SHARE_LINK_MAX_TTL", 30*24*time.Hour)
+		excluded, err := ParseNamespaceMatcher(getEnv("EXCLUDE_NAMESPACES", ""))
+		if err != nil {
+			utils.LogWarning("Invalid EXCLUDE_NAMESPACES entry ignored", map[string]interface{}{"error": err.Error()})
+		}
+		config.ExcludeNamespaces = excluded
+		reportKinds, err := ParseKindToggles(getEnv("DISABLED_REPORT_KINDS", ""))
+		if err != nil {
+			utils.LogWarning("Invalid DISABLED_REPORT_KINDS entry ignored", map[string]interface{}{"error": err.Error()})
+		}
+		config.ReportKinds = reportKinds
+		config.SeverityRulesFile = getEnv("SEVERITY_RULES_FILE", "")
+		config.NotificationRoutesFile = getEnv("NOTIFICATION_ROUTES_FILE", "")
+		config.SignaturePolicyFile = getEnv("SIGNATURE_POLICY_FILE", "")
+		config.UIConfigFile = getEnv("UI_CONFIG_FILE", "")
+		config.SMTPHost = getEnv("SMTP_HOST", "")
+		config.SMTPPort = getEnvInt("SMTP_PORT", 587)
+		config.SMTPFrom = getEnv("SMTP_FROM", "")
+		config.S3Endpoint = getEnv("S3_ENDPOINT", "")
+		config.S3Region = getEnv("AWS_REGION", "us-east-1")
+		config.JobWorkers = getEnvInt("JOB_WORKERS", 2)
+		config.JobRetention = getEnvDuration("JOB_RETENTION", 24*time.Hour)
+		config.DefectDojoURL = getEnv("DEFECTDOJO_URL", "")
+		config.DefectDojoProductType = getEnv("DEFECTDOJO_PRODUCT_TYPE", "Kubernetes")
+		config.DefectDojoProduct = getEnv("DEFECTDOJO_PRODUCT", "{cluster}")
+		config.DefectDojoEngagement = getEnv("DEFECTDOJO_ENGAGEMENT", "{namespace}")
+		products, err := ParseKeyValues(getEnv("DEFECTDOJO_PRODUCTS", ""))
+		if err != nil {
+			utils.LogWarning("Invalid DEFECTDOJO_PRODUCTS entry ignored", map[string]interface{}{"error": err.Error()})
+		}
+		config.DefectDojoProducts = products
+		config.TrivyServerURL = getEnv("TRIVY_SERVER_URL", "")
+		config.TrivyBinary = getEnv("TRIVY_BINARY", "trivy")
+		config.TrivyScanTimeout = getEnvDuration("TRIVY_SCAN_TIMEOUT", 5*time.Minute)
+		config.CredentialsDir = getEnv("CREDENTIALS_DIR", "")
+		config.CredentialsSecret = getEnv("CREDENTIALS_SECRET", "")
+		config.CredentialsRefresh = getEnvDuration("CREDENTIALS_REFRESH", time.Minute)
+		config.KubeconfigSecrets = getEnvBool("KUBECONFIG_SECRETS", true)
+		config.KubeconfigSecretNamespace = getEnv("KUBECONFIG_SECRET_NAMESPACE", podNamespace())
+		config.ClusterRetention = getEnvDuration("CLUSTER_RETENTION", 30*24*time.Hour)
+		clusterTags, err := ParseClusterTags(getEnv("CLUSTER_TAGS", ""))
+		if err != nil {
+			utils.LogWarning("Invalid CLUSTER_TAGS entry ignored", map[string]interface{}{"error": err.Error()})
+		}
+		config.ClusterTags = clusterTags
+		config.IngestMode = strings.ToLower(getEnv("INGEST_MODE", IngestModeKubernetes))
+		switch config.IngestMode {
+		case IngestModeKubernetes, IngestModeFile, IngestModeAPI:
+		default:
+			utils.LogWarning("Unknown INGEST_MODE, using kubernetes", map[string]interface{}{"value": config.IngestMode})
+			config.IngestMode = IngestModeKubernetes
+		}
+		config.OversizedReportBytes = getEnvInt("OVERSIZED_REPORT_BYTES", 5<<20)
+		config.InformerWorkers = getEnvInt("INFORMER_WORKERS", 4)
+		config.InformerQueueSize = getEnvInt("INFORMER_QUEUE_SIZE", 512)
+		config.SaveInterval = getEnvDuration("SAVE_INTERVAL", 60*time.Second)
+		config.SnapshotPeer = getEnv("SNAPSHOT_PEER", "")
+		config.ReconcileInterval = getEnvDuration("RECONCILE_INTERVAL", 30*time.Minute)
+		config.ReconcileRate = getEnvFloat("RECONCILE_RATE", 20)
+		links, err := ParseKeyValues(getEnv("LINK_TEMPLATES", ""))
+		if err != nil {
+			utils.LogWarning("Invalid LINK_TEMPLATES entry ignored", map[string]interface{}{"error": err.Error()})
+		}
+		config.LinkTemplates = links
+		config.IgnoreUnfixable = getEnvBool("IGNORE_UNFIXABLE", false)
+		config.APIV1Sunset = getEnvDate("API_V1_SUNSET", time.Date(2027, 10, 16, 0, 0, 0, 0, time.UTC))
+		config.TrivyDBMaxAge = getEnvDuration("TRIVY_DB_MAX_AGE", 7*24*time.Hour)
+		config.ImageStaleAge = getEnvDuration("IMAGE_STALE_AGE", 90*24*time.Hour)
+		config.AlertClusterLabels = splitList(getEnv("ALERT_CLUSTER_LABELS", "cluster"))
+		config.WebhookURLs = splitList(getEnv("WEBHOOK_URLS", ""))
+		config.WebhookEvents = splitList(getEnv("WEBHOOK_EVENTS", ""))
+		config.DemoMode = getEnvBool("DEMO_MODE", false)
+		config.DemoSeed = getEnv("DEMO_SEED", "trivy-ui")
+		switch telemetry := strings.ToLower(getEnv("TELEMETRY", "off")); telemetry {
+		case "on", "true":
+			config.Telemetry = true
+		case "off", "false":
+		default:
+			utils.LogWarning("Unknown TELEMETRY, leaving it off", map[string]interface{}{"value": telemetry})
+		}
+		config.TelemetryEndpoint = getEnv("TELEMETRY_ENDPOINT", "")
+		config.TelemetryInterval = getEnvDuration("TELEMETRY_INTERVAL", 24*time.Hour)
 	}
 	return config
 }
 
+// CredentialsSecretRef splits CredentialsSecret; a bare name is looked up in the pod's namespace.
+func (c *Config) CredentialsSecretRef() (namespace, name string) {
+	if ns, n, ok := strings.Cut(c.CredentialsSecret, "/"); ok {
+		return ns, n
+	}
+	return podNamespace(), c.CredentialsSecret
+}
+
+// AgentPushEnabled reports whether clusters may push reports through agents.
+func (c *Config) AgentPushEnabled() bool {
+	return len(c.AgentTokens) > 0 || c.AgentCAFile != ""
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -43,6 +366,159 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// ParseSLAWindows parses "SEVERITY=duration" pairs, e.g. "CRITICAL=7d,HIGH=720h".
+// Durations accept a "d" suffix for days in addition to time.ParseDuration units.
+func ParseSLAWindows(value string) (map[string]time.Duration, error) {
+	windows := make(map[string]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid SLA window %q", pair)
+		}
+		d, err := ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid SLA duration %q", parts[1])
+		}
+		windows[strings.ToUpper(strings.TrimSpace(parts[0]))] = d
+	}
+	return windows, nil
+}
+
+// ParseReportRetention parses "kind=duration" pairs, e.g.
+// "vulnerabilityreports=90d,configauditreports=30d,sbomreports=forever". A duration of 0
+// or "forever" keeps the data of the kind indefinitely.
+func ParseReportRetention(value string) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration)
+	pairs, err := ParseKeyValues(value)
+	for kind, v := range pairs {
+		var d time.Duration
+		if v != "forever" {
+			parsed, parseErr := ParseDuration(v)
+			if parseErr != nil || parsed < 0 {
+				return result, fmt.Errorf("invalid retention %q for %s", v, kind)
+			}
+			d = parsed
+		}
+		result[strings.ToLower(kind)] = d
+	}
+	return result, err
+}
+
+// ParseKeyValues parses "key=value" pairs separated by commas, e.g. "team-a=org/repo-a,default=org/security".
+func ParseKeyValues(value string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, pair := range splitList(value) {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return result, fmt.Errorf("invalid entry %q", pair)
+		}
+		result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return result, nil
+}
+
+// ParseClusterTags parses "cluster/key=value" pairs separated by commas, e.g.
+// "prod-eu/region=eu-west-1,prod-eu/tier=prod".
+func ParseClusterTags(value string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string)
+	pairs, err := ParseKeyValues(value)
+	for name, v := range pairs {
+		cluster, key, ok := strings.Cut(name, "/")
+		cluster, key = strings.TrimSpace(cluster), strings.TrimSpace(key)
+		if !ok || cluster == "" || key == "" {
+			return result, fmt.Errorf("invalid entry %q, expected cluster/key=value", name+"="+v)
+		}
+		if result[cluster] == nil {
+			result[cluster] = make(map[string]string)
+		}
+		result[cluster][key] = v
+	}
+	return result, err
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func ParseDuration(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(value, "d"), 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days * 24 * float64(time.Hour)), nil
+	}
+	return time.ParseDuration(value)
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := ParseDuration(value); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDate parses a YYYY-MM-DD date; "none" yields the zero time.
+func getEnvDate(key string, defaultValue time.Time) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if value == "none" {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		utils.LogWarning("Invalid date, using default", map[string]interface{}{"key": key, "value": value})
+		return defaultValue
+	}
+	return t
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// podNamespace is the namespace trivy-ui is deployed in: POD_NAMESPACE (downward API)
+// or the mounted service account namespace, empty outside a cluster.
+func podNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	return ""
+}
+
 func KubeConfigPath() string {
 	if path := os.Getenv("KUBECONFIG"); path != "" {
 		return path
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSLAWindows_Defaults(t *testing.T) {
+	windows, err := ParseSLAWindows(defaultSLAWindows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if windows["CRITICAL"] != 7*24*time.Hour || windows["LOW"] != 180*24*time.Hour {
+		t.Fatalf("unexpected windows: %v", windows)
+	}
+}
+
+func TestParseSLAWindows_MixedUnitsAndCase(t *testing.T) {
+	windows, err := ParseSLAWindows("critical=36h, high = 2d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if windows["CRITICAL"] != 36*time.Hour || windows["HIGH"] != 48*time.Hour {
+		t.Fatalf("unexpected windows: %v", windows)
+	}
+}
+
+func TestParseSLAWindows_Invalid(t *testing.T) {
+	for _, v := range []string{"CRITICAL", "CRITICAL=abc", "HIGH=-1d"} {
+		if _, err := ParseSLAWindows(v); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+func TestParseKeyValues(t *testing.T) {
+	repos, err := ParseKeyValues("team-a=org/repo-a, default = org/security")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repos["team-a"] != "org/repo-a" || repos["default"] != "org/security" {
+		t.Fatalf("unexpected mapping: %v", repos)
+	}
+	if _, err := ParseKeyValues("team-a"); err == nil {
+		t.Fatal("expected error for entry without value")
+	}
+}
+
+func TestParseReportRetention(t *testing.T) {
+	retention, err := ParseReportRetention("vulnerabilityreports=90d, ConfigAuditReports=720h,sbomreports=forever")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if retention["vulnerabilityreports"] != 90*24*time.Hour || retention["configauditreports"] != 30*24*time.Hour {
+		t.Fatalf("unexpected retention: %v", retention)
+	}
+	if d, ok := retention["sbomreports"]; !ok || d != 0 {
+		t.Fatalf("forever parsed as %v", d)
+	}
+	if _, err := ParseReportRetention("vulnerabilityreports=soon"); err == nil {
+		t.Fatal("expected error for invalid duration")
+	}
+}
+
+func TestParseClusterTags(t *testing.T) {
+	tags, err := ParseClusterTags("prod-eu/region=eu-west-1, prod-eu/tier=prod,dev/tier=dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tags["prod-eu"]["region"] != "eu-west-1" || tags["prod-eu"]["tier"] != "prod" || tags["dev"]["tier"] != "dev" {
+		t.Fatalf("unexpected tags: %v", tags)
+	}
+	if _, err := ParseClusterTags("tier=prod"); err == nil {
+		t.Fatal("expected error for entry without cluster")
+	}
+}
@@ -42,16 +42,27 @@ func GetGlobalRegistry() *CRDRegistry {
 	return globalRegistry
 }
 
-func (r *CRDRegistry) DiscoverCRDs(config *rest.Config) error {
+func (r *CRDRegistry) DiscoverCRDs(ctx context.Context, config *rest.Config) error {
 
-	if err := r.DiscoverCRDsFromAPIResources(config); err == nil {
+	if err := r.DiscoverCRDsFromAPIResources(ctx, config); err == nil {
 		return nil
+	} else if ctx.Err() != nil {
+		return err
 	}
 
-	return r.DiscoverCRDsFromCRDList(config)
+	return r.DiscoverCRDsFromCRDList(ctx, config)
 }
 
-func (r *CRDRegistry) DiscoverCRDsFromAPIResources(config *rest.Config) error {
+// DiscoverCRDsFromAPIResources reads the Trivy resources from the discovery API. The
+// discovery client takes no context, so ctx's deadline becomes the client timeout.
+func (r *CRDRegistry) DiscoverCRDsFromAPIResources(ctx context.Context, config *rest.Config) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		config = rest.CopyConfig(config)
+		config.Timeout = time.Until(deadline)
+	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -90,6 +101,7 @@ func (r *CRDRegistry) DiscoverCRDsFromAPIResources(config *rest.Config) error {
 				APIVersion: groupVersion,
 				Namespaced: apiResource.Namespaced,
 				Kind:       apiResource.Kind,
+				ShortNames: apiResource.ShortNames,
 			}
 
 			reports = append(reports, reportKind)
@@ -111,14 +123,14 @@ func (r *CRDRegistry) DiscoverCRDsFromAPIResources(config *rest.Config) error {
 	return nil
 }
 
-func (r *CRDRegistry) DiscoverCRDsFromCRDList(config *rest.Config) error {
+func (r *CRDRegistry) DiscoverCRDsFromCRDList(ctx context.Context, config *rest.Config) error {
 
 	clientset, err := apiextensionsclientset.NewForConfig(config)
 	if err != nil {
 		return fmt.Errorf("failed to create API extensions client: %w", err)
 	}
 
-	crdList, err := clientset.ApiextensionsV1().CustomResourceDefinitions().List(context.Background(), metav1.ListOptions{})
+	crdList, err := clientset.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to list CRDs: %w", err)
 	}
@@ -126,57 +138,106 @@ func (r *CRDRegistry) DiscoverCRDsFromCRDList(config *rest.Config) error {
 	var reports []ReportKind
 	reportsByName := make(map[string]*ReportKind)
 
-	for _, crd := range crdList.Items {
-
-		if crd.Spec.Group != TrivyGroup {
-			continue
+	for i := range crdList.Items {
+		if reportKind, ok := ReportKindFromCRD(&crdList.Items[i]); ok {
+			reports = append(reports, reportKind)
 		}
+	}
 
-		version := DefaultAPIVersion
-		if len(crd.Spec.Versions) > 0 {
+	// Build pointer map after slice is fully constructed to avoid dangling pointers
+	for i := range reports {
+		reportsByName[reports[i].Name] = &reports[i]
+	}
 
-			for _, v := range crd.Spec.Versions {
-				if v.Served && v.Storage {
-					version = v.Name
-					break
-				}
-			}
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-			if version == DefaultAPIVersion && len(crd.Spec.Versions) > 0 {
-				version = crd.Spec.Versions[0].Name
-			}
-		}
+	r.reports = reports
+	r.reportsByName = reportsByName
+	r.lastRefresh = time.Now()
 
-		namespaced := crd.Spec.Scope == apiextensionsv1.NamespaceScoped
+	return nil
+}
 
-		resourceName := crd.Spec.Names.Plural
+// ReportKindFromCRD converts a Trivy Operator CRD into a report kind; ok is false for
+// CRDs of other groups.
+func ReportKindFromCRD(crd *apiextensionsv1.CustomResourceDefinition) (ReportKind, bool) {
+	if crd.Spec.Group != TrivyGroup {
+		return ReportKind{}, false
+	}
 
-		kind := crd.Spec.Names.Kind
+	version := DefaultAPIVersion
+	if len(crd.Spec.Versions) > 0 {
 
-		reportKind := ReportKind{
-			Name:       resourceName,
-			ShortName:  strings.ToLower(kind),
-			APIVersion: fmt.Sprintf("%s/%s", crd.Spec.Group, version),
-			Namespaced: namespaced,
-			Kind:       kind,
+		for _, v := range crd.Spec.Versions {
+			if v.Served && v.Storage {
+				version = v.Name
+				break
+			}
 		}
 
-		reports = append(reports, reportKind)
+		if version == DefaultAPIVersion && len(crd.Spec.Versions) > 0 {
+			version = crd.Spec.Versions[0].Name
+		}
 	}
 
-	// Build pointer map after slice is fully constructed to avoid dangling pointers
-	for i := range reports {
-		reportsByName[reports[i].Name] = &reports[i]
+	return ReportKind{
+		Name:       crd.Spec.Names.Plural,
+		ShortName:  strings.ToLower(crd.Spec.Names.Kind),
+		APIVersion: fmt.Sprintf("%s/%s", crd.Spec.Group, version),
+		Namespaced: crd.Spec.Scope == apiextensionsv1.NamespaceScoped,
+		Kind:       crd.Spec.Names.Kind,
+		ShortNames: crd.Spec.Names.ShortNames,
+	}, true
+}
+
+// Register adds report kinds that were not discovered locally, e.g. kinds announced
+// by push agents when trivy-ui has no direct access to any cluster.
+func (r *CRDRegistry) Register(kinds ...ReportKind) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := false
+	for _, kind := range kinds {
+		if kind.Name == "" {
+			continue
+		}
+		if _, ok := r.reportsByName[kind.Name]; ok {
+			continue
+		}
+		r.reports = append(r.reports, kind)
+		r.reportsByName[kind.Name] = &kind
+		added = true
 	}
+	if added {
+		// append may have moved the slice; rebuild pointers like discovery does
+		for i := range r.reports {
+			r.reportsByName[r.reports[i].Name] = &r.reports[i]
+		}
+	}
+}
 
+// Unregister removes a report kind, e.g. after its CRD was deleted.
+func (r *CRDRegistry) Unregister(name string) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if _, ok := r.reportsByName[name]; !ok {
+		return false
+	}
+	reports := make([]ReportKind, 0, len(r.reports))
+	for _, kind := range r.reports {
+		if kind.Name != name {
+			reports = append(reports, kind)
+		}
+	}
+	reportsByName := make(map[string]*ReportKind, len(reports))
+	for i := range reports {
+		reportsByName[reports[i].Name] = &reports[i]
+	}
 	r.reports = reports
 	r.reportsByName = reportsByName
-	r.lastRefresh = time.Now()
-
-	return nil
+	return true
 }
 
 func (r *CRDRegistry) GetAllReports() []ReportKind {
@@ -200,13 +261,30 @@ func (r *CRDRegistry) GetReportByName(name string) *ReportKind {
 	return nil
 }
 
-func (r *CRDRegistry) RefreshIfNeeded(config *rest.Config) error {
+// ResolveReport finds a report kind by its resource name, kind (VulnerabilityReport),
+// singular or short name (vuln), ignoring case.
+func (r *CRDRegistry) ResolveReport(name string) *ReportKind {
+	if report := r.GetReportByName(name); report != nil {
+		return report
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := range r.reports {
+		if r.reports[i].matches(name) {
+			reportCopy := r.reports[i]
+			return &reportCopy
+		}
+	}
+	return nil
+}
+
+func (r *CRDRegistry) RefreshIfNeeded(ctx context.Context, config *rest.Config) error {
 	r.mu.RLock()
 	needsRefresh := time.Since(r.lastRefresh) > r.refreshTTL || len(r.reports) == 0
 	r.mu.RUnlock()
 
 	if needsRefresh {
-		return r.DiscoverCRDs(config)
+		return r.DiscoverCRDs(ctx, config)
 	}
 	return nil
 }
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"reflect"
 	"testing"
 	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
 func newPopulatedRegistry() *CRDRegistry {
@@ -76,6 +79,32 @@ func TestCRDRegistry_GetReportByName_ReturnsCopy(t *testing.T) {
 	}
 }
 
+func TestCRDRegistry_ResolveReport(t *testing.T) {
+	reg := newPopulatedRegistry()
+	reg.reports[0].ShortName = "vulnerabilityreport"
+	reg.reports[1].ShortNames = []string{"ccr"}
+	tests := map[string]string{
+		"vulnerabilityreports":    "vulnerabilityreports",
+		"VulnerabilityReports":    "vulnerabilityreports",
+		"VulnerabilityReport":     "vulnerabilityreports",
+		"vulnerabilityreport":     "vulnerabilityreports",
+		"vuln":                    "vulnerabilityreports",
+		"VULNS":                   "vulnerabilityreports",
+		"ccr":                     "clustercompliancereports",
+		"compliance":              "clustercompliancereports",
+		"ClusterComplianceReport": "clustercompliancereports",
+	}
+	for name, want := range tests {
+		rk := reg.ResolveReport(name)
+		if rk == nil || rk.Name != want {
+			t.Errorf("ResolveReport(%q) = %v, want %s", name, rk, want)
+		}
+	}
+	if rk := reg.ResolveReport("configaudit"); rk != nil {
+		t.Errorf("unregistered alias resolved to %s", rk.Name)
+	}
+}
+
 func TestCRDRegistry_IsDiscovered_Empty(t *testing.T) {
 	reg := &CRDRegistry{reportsByName: make(map[string]*ReportKind)}
 	if reg.IsDiscovered() {
@@ -105,7 +134,7 @@ func TestCRDRegistry_RefreshIfNeeded_SkipsWhenFresh(t *testing.T) {
 	reg.lastRefresh = time.Now()
 	reg.refreshTTL = time.Hour
 
-	err := reg.RefreshIfNeeded(nil)
+	err := reg.RefreshIfNeeded(t.Context(), nil)
 	if err != nil {
 		t.Fatalf("expected no error when skipping refresh, got %v", err)
 	}
@@ -135,3 +164,63 @@ func TestCRDRegistry_Namespaced(t *testing.T) {
 		t.Fatal("clustercompliancereports should not be namespaced")
 	}
 }
+
+func TestCRDRegistry_Register_SkipsKnownKinds(t *testing.T) {
+	r := newPopulatedRegistry()
+	before := len(r.GetAllReports())
+	known := r.GetAllReports()[0]
+
+	r.Register(known, ReportKind{Name: "pushedreports", Kind: "PushedReport"}, ReportKind{})
+
+	if got := len(r.GetAllReports()); got != before+1 {
+		t.Fatalf("expected %d kinds got %d", before+1, got)
+	}
+	if r.GetReportByName("pushedreports") == nil || r.GetReportByName(known.Name) == nil {
+		t.Fatal("expected both kinds to be resolvable")
+	}
+}
+
+func TestCRDRegistry_Unregister(t *testing.T) {
+	r := newPopulatedRegistry()
+
+	if !r.Unregister("vulnerabilityreports") {
+		t.Fatal("expected known kind to be removed")
+	}
+	if r.Unregister("vulnerabilityreports") {
+		t.Fatal("expected second removal to report false")
+	}
+	if r.GetReportByName("vulnerabilityreports") != nil {
+		t.Fatal("expected removed kind to be unresolvable")
+	}
+	if r.GetReportByName("clustercompliancereports") == nil || len(r.GetAllReports()) != 1 {
+		t.Fatal("expected other kinds to remain")
+	}
+}
+
+func TestReportKindFromCRD(t *testing.T) {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: TrivyGroup,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "sbomreports", Kind: "SbomReport", ShortNames: []string{"sbom"}},
+			Scope: apiextensionsv1.NamespaceScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true},
+				{Name: "v1beta1", Served: true, Storage: true},
+			},
+		},
+	}
+
+	kind, ok := ReportKindFromCRD(crd)
+	if !ok {
+		t.Fatal("expected Trivy CRD to convert")
+	}
+	want := ReportKind{Name: "sbomreports", ShortName: "sbomreport", APIVersion: TrivyGroup + "/v1beta1", Namespaced: true, Kind: "SbomReport", ShortNames: []string{"sbom"}}
+	if !reflect.DeepEqual(kind, want) {
+		t.Errorf("got %+v, want %+v", kind, want)
+	}
+
+	crd.Spec.Group = "example.com"
+	if _, ok := ReportKindFromCRD(crd); ok {
+		t.Error("expected CRDs of other groups to be ignored")
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KindToggles decides which report kinds informers watch and cache, globally and per
+// cluster. A cluster's setting wins over the global one; kinds are enabled by default.
+type KindToggles struct {
+	mu       sync.RWMutex
+	global   map[string]bool
+	clusters map[string]map[string]bool
+}
+
+// KindToggleSettings is a snapshot of the settings made, by canonical kind name.
+type KindToggleSettings struct {
+	Global   map[string]bool            `json:"global"`
+	Clusters map[string]map[string]bool `json:"clusters"`
+}
+
+func NewKindToggles() *KindToggles {
+	return &KindToggles{global: make(map[string]bool), clusters: make(map[string]map[string]bool)}
+}
+
+// ParseKindToggles parses the kinds to disable, separated by commas: "sbomreports"
+// disables a kind everywhere, "prod/clustersbomreports" on one cluster. Kinds may be
+// given by any of their names, e.g. sbom.
+func ParseKindToggles(value string) (*KindToggles, error) {
+	t := NewKindToggles()
+	var err error
+	for _, entry := range splitList(value) {
+		cluster, kind, ok := strings.Cut(entry, "/")
+		if !ok {
+			cluster, kind = "", entry
+		}
+		cluster, kind = strings.TrimSpace(cluster), strings.TrimSpace(kind)
+		if kind == "" || (ok && cluster == "") {
+			err = fmt.Errorf("invalid entry %q, expected kind or cluster/kind", entry)
+			continue
+		}
+		disabled := false
+		t.Set(cluster, kind, &disabled)
+	}
+	return t, err
+}
+
+// CanonicalKindName resolves any name of a report kind to its resource name, also for
+// kinds not discovered yet.
+func CanonicalKindName(name string) string {
+	if kind := GetGlobalRegistry().ResolveReport(name); kind != nil {
+		return kind.Name
+	}
+	for resource, aliases := range reportAliases {
+		if strings.EqualFold(resource, name) {
+			return resource
+		}
+		for _, alias := range aliases {
+			if strings.EqualFold(alias, name) {
+				return resource
+			}
+		}
+	}
+	return strings.ToLower(name)
+}
+
+// Enabled reports whether a cluster watches a report kind.
+func (t *KindToggles) Enabled(cluster, kind string) bool {
+	if t == nil {
+		return true
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if enabled, ok := t.clusters[cluster][kind]; ok {
+		return enabled
+	}
+	if enabled, ok := t.global[kind]; ok {
+		return enabled
+	}
+	return true
+}
+
+// Set enables or disables a kind for a cluster, or globally when cluster is empty. A nil
+// enabled removes the setting, so the cluster follows the global one again.
+func (t *KindToggles) Set(cluster, kind string, enabled *bool) {
+	kind = CanonicalKindName(kind)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	settings := t.global
+	if cluster != "" {
+		settings = t.clusters[cluster]
+		if settings == nil {
+			settings = make(map[string]bool)
+			t.clusters[cluster] = settings
+		}
+	}
+	if enabled == nil {
+		delete(settings, kind)
+	} else {
+		settings[kind] = *enabled
+	}
+	if cluster != "" && len(settings) == 0 {
+		delete(t.clusters, cluster)
+	}
+}
+
+// Settings returns a copy of the settings made.
+func (t *KindToggles) Settings() KindToggleSettings {
+	s := KindToggleSettings{Global: make(map[string]bool), Clusters: make(map[string]map[string]bool)}
+	if t == nil {
+		return s
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for kind, enabled := range t.global {
+		s.Global[kind] = enabled
+	}
+	for cluster, settings := range t.clusters {
+		s.Clusters[cluster] = make(map[string]bool, len(settings))
+		for kind, enabled := range settings {
+			s.Clusters[cluster][kind] = enabled
+		}
+	}
+	return s
+}
+
+// DisabledKinds lists the kinds a cluster does not watch among those given.
+func (t *KindToggles) DisabledKinds(cluster string, kinds []ReportKind) []string {
+	var disabled []string
+	for _, kind := range kinds {
+		if !t.Enabled(cluster, kind.Name) {
+			disabled = append(disabled, kind.Name)
+		}
+	}
+	sort.Strings(disabled)
+	return disabled
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKindToggles(t *testing.T) {
+	toggles, err := ParseKindToggles("sbom, prod/ClusterSbomReports, /x")
+	if err == nil {
+		t.Error("expected an error for the entry without a cluster")
+	}
+	want := KindToggleSettings{
+		Global:   map[string]bool{"sbomreports": false},
+		Clusters: map[string]map[string]bool{"prod": {"clustersbomreports": false}},
+	}
+	if got := toggles.Settings(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v", got)
+	}
+	if toggles.Enabled("dev", "sbomreports") || toggles.Enabled("prod", "clustersbomreports") || !toggles.Enabled("dev", "clustersbomreports") {
+		t.Error("kinds disabled from DISABLED_REPORT_KINDS are enabled")
+	}
+
+	// a cluster's own setting wins over the global one until it is removed
+	enabled := true
+	toggles.Set("prod", "sbomreports", &enabled)
+	if !toggles.Enabled("prod", "sbomreports") || toggles.Enabled("dev", "sbomreports") {
+		t.Error("cluster setting not applied")
+	}
+	toggles.Set("prod", "sbomreports", nil)
+	toggles.Set("prod", "clustersbomreports", nil)
+	if toggles.Enabled("prod", "sbomreports") || len(toggles.Settings().Clusters) != 0 {
+		t.Errorf("cluster settings not removed: %+v", toggles.Settings())
+	}
+
+	var none *KindToggles
+	if !none.Enabled("prod", "sbomreports") {
+		t.Error("kinds are enabled without settings")
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// NamespaceMatcher matches namespace names against globs (kube-*, *-temp-*) and
+// regular expressions written between slashes (/^ci-[0-9]+$/).
+type NamespaceMatcher struct {
+	globs   []string
+	regexps []*regexp.Regexp
+}
+
+func ParseNamespaceMatcher(value string) (*NamespaceMatcher, error) {
+	m := &NamespaceMatcher{}
+	for _, pattern := range splitList(value) {
+		if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				return m, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			m.regexps = append(m.regexps, re)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return m, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		m.globs = append(m.globs, pattern)
+	}
+	return m, nil
+}
+
+func (m *NamespaceMatcher) Match(namespace string) bool {
+	if m == nil {
+		return false
+	}
+	for _, glob := range m.globs {
+		if ok, _ := path.Match(glob, namespace); ok {
+			return true
+		}
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// NamespaceExcluded reports whether reports of a namespace are ignored. Cluster-scoped
+// reports (empty namespace) are never excluded.
+func (c *Config) NamespaceExcluded(namespace string) bool {
+	return namespace != "" && c.ExcludeNamespaces.Match(namespace)
+}
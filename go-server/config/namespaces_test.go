@@ -0,0 +1,46 @@
+package config
+
+import "testing"
+
+func TestNamespaceMatcher(t *testing.T) {
+	m, err := ParseNamespaceMatcher("kube-system, *-temp-*, /^ci-[0-9]+$/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := map[string]bool{
+		"kube-system":     true,
+		"build-temp-1234": true,
+		"ci-42":           true,
+		"ci-main":         false,
+		"kube-public":     false,
+		"default":         false,
+	}
+	for ns, want := range tests {
+		if got := m.Match(ns); got != want {
+			t.Errorf("Match(%q) = %v, want %v", ns, got, want)
+		}
+	}
+}
+
+func TestNamespaceMatcherInvalidPattern(t *testing.T) {
+	if _, err := ParseNamespaceMatcher("/ci-[/"); err == nil {
+		t.Error("expected invalid regex to fail")
+	}
+	if _, err := ParseNamespaceMatcher("ci-["); err == nil {
+		t.Error("expected invalid glob to fail")
+	}
+}
+
+func TestNamespaceExcludedKeepsClusterScoped(t *testing.T) {
+	m, _ := ParseNamespaceMatcher("*")
+	cfg := &Config{ExcludeNamespaces: m}
+	if cfg.NamespaceExcluded("") {
+		t.Error("cluster-scoped reports must never be excluded")
+	}
+	if !cfg.NamespaceExcluded("default") {
+		t.Error("expected namespace to be excluded")
+	}
+	if (&Config{}).NamespaceExcluded("default") {
+		t.Error("expected nothing excluded without patterns")
+	}
+}
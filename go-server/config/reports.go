@@ -1,11 +1,53 @@
 package config
 
+import "strings"
+
 type ReportKind struct {
 	Name       string `json:"name"`
 	ShortName  string `json:"shortName"`
 	APIVersion string `json:"apiVersion"`
 	Namespaced bool   `json:"namespaced"`
 	Kind       string `json:"kind"`
+	// ShortNames are the CRD's short names, e.g. vuln for vulnerabilityreports
+	ShortNames []string `json:"shortNames,omitempty"`
+}
+
+// reportAliases are the short names trivy-operator gives its CRDs, for kinds registered
+// without them by push agents or report files.
+var reportAliases = map[string][]string{
+	"vulnerabilityreports":          {"vuln", "vulns"},
+	"clustervulnerabilityreports":   {"clustervuln", "clustervulns"},
+	"configauditreports":            {"configaudit", "configaudits"},
+	"clusterconfigauditreports":     {"clusterconfigaudit", "clusterconfigaudits"},
+	"exposedsecretreports":          {"exposedsecret", "exposedsecrets"},
+	"rbacassessmentreports":         {"rbacassessment", "rbacassessments"},
+	"clusterrbacassessmentreports":  {"clusterrbacassessment", "clusterrbacassessments"},
+	"infraassessmentreports":        {"infraassessment", "infraassessments"},
+	"clusterinfraassessmentreports": {"clusterinfraassessment", "clusterinfraassessments"},
+	"clustercompliancereports":      {"compliance"},
+	"sbomreports":                   {"sbom", "sboms"},
+	"clustersbomreports":            {"clustersbom", "clustersboms"},
+}
+
+// Aliases returns the names the kind is known by besides its resource name: the kind,
+// its singular and its short names.
+func (k ReportKind) Aliases() []string {
+	aliases := []string{k.Kind, k.ShortName}
+	aliases = append(aliases, k.ShortNames...)
+	return append(aliases, reportAliases[k.Name]...)
+}
+
+// matches tells whether name refers to the kind, ignoring case.
+func (k ReportKind) matches(name string) bool {
+	if strings.EqualFold(k.Name, name) {
+		return true
+	}
+	for _, alias := range k.Aliases() {
+		if alias != "" && strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
 }
 
 func AllReports() []ReportKind {
@@ -17,3 +59,8 @@ func GetReportByName(name string) *ReportKind {
 	registry := GetGlobalRegistry()
 	return registry.GetReportByName(name)
 }
+
+func ResolveReport(name string) *ReportKind {
+	registry := GetGlobalRegistry()
+	return registry.ResolveReport(name)
+}
@@ -0,0 +1,217 @@
+// Package credentials resolves the secrets of integrations (issue trackers, SMTP, S3)
+// from files, mounted Secret directories, Kubernetes Secrets read through the API, and
+// environment variables. Values are looked up on every use, so rotated secrets take
+// effect without a restart.
+package credentials
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"trivy-ui/utils"
+)
+
+// Credential names; they double as environment variable names.
+const (
+	IssueToken         = "ISSUE_TOKEN"
+	SMTPUsername       = "SMTP_USERNAME"
+	SMTPPassword       = "SMTP_PASSWORD"
+	AWSAccessKeyID     = "AWS_ACCESS_KEY_ID"
+	AWSSecretAccessKey = "AWS_SECRET_ACCESS_KEY"
+	AWSSessionToken    = "AWS_SESSION_TOKEN"
+	DefectDojoAPIKey   = "DEFECTDOJO_API_KEY"
+	TrivyServerToken   = "TRIVY_SERVER_TOKEN"
+	RegistryUsername   = "REGISTRY_USERNAME"
+	RegistryPassword   = "REGISTRY_PASSWORD"
+	WebhookSecret      = "WEBHOOK_SECRET"
+	SnapshotToken      = "SNAPSHOT_TOKEN"
+)
+
+// Source looks up a credential by name; ok is false when the source does not have it.
+type Source interface {
+	Lookup(name string) (value string, ok bool)
+}
+
+// Provider asks its sources in order and returns the first value found.
+type Provider struct {
+	mu      sync.RWMutex
+	sources []Source
+}
+
+func NewProvider(sources ...Source) *Provider {
+	return &Provider{sources: sources}
+}
+
+// Add appends a source with the lowest precedence before the environment, which always
+// comes last when it is the final source.
+func (p *Provider) Add(s Source) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n := len(p.sources); n > 0 {
+		if _, ok := p.sources[n-1].(EnvSource); ok {
+			p.sources = append(p.sources[:n-1], s, p.sources[n-1])
+			return
+		}
+	}
+	p.sources = append(p.sources, s)
+}
+
+// Get returns the credential or "" when no source has it.
+func (p *Provider) Get(name string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, s := range p.sources {
+		if v, ok := s.Lookup(name); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// EnvSource reads environment variables.
+type EnvSource struct{}
+
+func (EnvSource) Lookup(name string) (string, bool) {
+	v, ok := os.LookupEnv(name)
+	return v, ok && v != ""
+}
+
+// fileCache re-reads a file only when its modification time or size changes. Kubelet
+// rotates mounted Secrets by swapping a symlink, which os.Stat follows.
+type fileCache struct {
+	mu      sync.Mutex
+	entries map[string]fileEntry
+}
+
+type fileEntry struct {
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+func (c *fileCache) read(path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[path]; ok && e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+		return e.value, true
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if c.entries == nil {
+		c.entries = make(map[string]fileEntry)
+	}
+	c.entries[path] = fileEntry{modTime: info.ModTime(), size: info.Size(), value: value}
+	return value, true
+}
+
+// FileEnvSource reads the file named by the <NAME>_FILE environment variable, the
+// convention for Docker and Kubernetes secrets mounted as files.
+type FileEnvSource struct {
+	cache fileCache
+}
+
+func (s *FileEnvSource) Lookup(name string) (string, bool) {
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", false
+	}
+	return s.cache.read(path)
+}
+
+// DirSource reads credentials from a directory holding one file per credential, such as
+// a mounted Secret. Files are named after the credential (SMTP_PASSWORD) or its
+// lower-case dashed form (smtp-password).
+type DirSource struct {
+	Dir   string
+	cache fileCache
+}
+
+func (s *DirSource) Lookup(name string) (string, bool) {
+	for _, file := range []string{name, secretKey(name)} {
+		if v, ok := s.cache.read(filepath.Join(s.Dir, file)); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// SecretSource reads a Kubernetes Secret through the API and re-reads it after Refresh,
+// so rotation works without mounting the Secret.
+type SecretSource struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+	Refresh   time.Duration
+
+	mu        sync.Mutex
+	data      map[string][]byte
+	fetchedAt time.Time
+}
+
+func (s *SecretSource) Lookup(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fetchedAt.IsZero() || time.Since(s.fetchedAt) > s.Refresh {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		secret, err := s.Client.CoreV1().Secrets(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+		cancel()
+		if err != nil {
+			// keep serving the last known values; also back off after failures
+			utils.LogWarning("Failed to read credentials Secret", map[string]interface{}{"namespace": s.Namespace, "name": s.Name, "error": err.Error()})
+		} else {
+			s.data = secret.Data
+		}
+		s.fetchedAt = time.Now()
+	}
+	for _, key := range []string{name, secretKey(name)} {
+		if v, ok := s.data[key]; ok && len(v) > 0 {
+			return strings.TrimRight(string(v), "\r\n"), true
+		}
+	}
+	return "", false
+}
+
+// secretKey is the lower-case dashed form of a credential name, e.g. smtp-password.
+func secretKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+var global = NewProvider(&FileEnvSource{}, EnvSource{})
+
+// Init configures the global provider: <NAME>_FILE variables, then dir when set, then
+// the environment.
+func Init(dir string) {
+	sources := []Source{&FileEnvSource{}}
+	if dir != "" {
+		sources = append(sources, &DirSource{Dir: dir})
+	}
+	sources = append(sources, EnvSource{})
+	global.mu.Lock()
+	global.sources = sources
+	global.mu.Unlock()
+}
+
+// AddSecret adds a Kubernetes Secret read through the API to the global provider, ahead
+// of the environment.
+func AddSecret(client kubernetes.Interface, namespace, name string, refresh time.Duration) {
+	global.Add(&SecretSource{Client: client, Namespace: namespace, Name: name, Refresh: refresh})
+}
+
+// Get resolves a credential with the global provider.
+func Get(name string) string {
+	return global.Get(name)
+}
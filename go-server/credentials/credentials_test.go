@@ -0,0 +1,77 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestProvider_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "smtp-password"), []byte("from-dir\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SMTP_PASSWORD", "from-env")
+	t.Setenv("ISSUE_TOKEN", "env-token")
+
+	p := NewProvider(&FileEnvSource{}, &DirSource{Dir: dir}, EnvSource{})
+	if got := p.Get(SMTPPassword); got != "from-dir" {
+		t.Fatalf("directory should win over env, got %q", got)
+	}
+	if got := p.Get(IssueToken); got != "env-token" {
+		t.Fatalf("expected env fallback, got %q", got)
+	}
+
+	file := filepath.Join(dir, "password.txt")
+	if err := os.WriteFile(file, []byte("from-file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SMTP_PASSWORD_FILE", file)
+	if got := p.Get(SMTPPassword); got != "from-file" {
+		t.Fatalf("_FILE should win, got %q", got)
+	}
+}
+
+func TestDirSource_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "AWS_SECRET_ACCESS_KEY")
+	if err := os.WriteFile(path, []byte("v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := &DirSource{Dir: dir}
+	if v, _ := s.Lookup(AWSSecretAccessKey); v != "v1" {
+		t.Fatalf("unexpected %q", v)
+	}
+	if err := os.WriteFile(path, []byte("v2-rotated"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.Lookup(AWSSecretAccessKey); v != "v2-rotated" {
+		t.Fatalf("rotated value not picked up, got %q", v)
+	}
+}
+
+func TestSecretSource(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "trivy-system", Name: "integrations"},
+		Data:       map[string][]byte{"issue-token": []byte("t1")},
+	})
+	s := &SecretSource{Client: client, Namespace: "trivy-system", Name: "integrations", Refresh: time.Hour}
+	if v, ok := s.Lookup(IssueToken); !ok || v != "t1" {
+		t.Fatalf("unexpected %q %v", v, ok)
+	}
+	if _, ok := s.Lookup(SMTPPassword); ok {
+		t.Fatal("missing key must not resolve")
+	}
+
+	p := NewProvider(EnvSource{})
+	p.Add(s)
+	t.Setenv("ISSUE_TOKEN", "env")
+	if got := p.Get(IssueToken); got != "t1" {
+		t.Fatalf("added Secret should precede the environment, got %q", got)
+	}
+}
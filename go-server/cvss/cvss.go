@@ -0,0 +1,181 @@
+// Package cvss computes CVSS v3.x base and environmental scores so findings can be
+// re-rated for the environment they run in.
+package cvss
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Vector holds the metrics of a CVSS v3.x vector string, keyed by metric abbreviation.
+type Vector map[string]string
+
+var baseMetrics = []string{"AV", "AC", "PR", "UI", "S", "C", "I", "A"}
+
+var allowedValues = map[string]string{
+	"AV": "NALP", "AC": "LH", "PR": "NLH", "UI": "NR", "S": "UC",
+	"C": "HLN", "I": "HLN", "A": "HLN",
+	"MAV": "XNALP", "MAC": "XLH", "MPR": "XNLH", "MUI": "XNR", "MS": "XUC",
+	"MC": "XHLN", "MI": "XHLN", "MA": "XHLN",
+	"CR": "XHML", "IR": "XHML", "AR": "XHML",
+	"E": "XUPFH", "RL": "XOTWU", "RC": "XURC",
+}
+
+// ParseVector parses a "CVSS:3.0/..." or "CVSS:3.1/..." vector.
+func ParseVector(s string) (Vector, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || (parts[0] != "CVSS:3.0" && parts[0] != "CVSS:3.1") {
+		return nil, fmt.Errorf("unsupported CVSS vector %q", s)
+	}
+	v := make(Vector, len(parts)-1)
+	for _, part := range parts[1:] {
+		metric, value, ok := strings.Cut(part, ":")
+		if !ok || len(value) != 1 {
+			return nil, fmt.Errorf("invalid metric %q", part)
+		}
+		if err := validMetric(metric, value); err != nil {
+			return nil, err
+		}
+		v[metric] = value
+	}
+	for _, m := range baseMetrics {
+		if _, ok := v[m]; !ok {
+			return nil, fmt.Errorf("missing base metric %s", m)
+		}
+	}
+	return v, nil
+}
+
+func validMetric(metric, value string) error {
+	allowed, ok := allowedValues[metric]
+	if !ok {
+		return fmt.Errorf("unknown metric %q", metric)
+	}
+	if len(value) != 1 || !strings.Contains(allowed, value) {
+		return fmt.Errorf("invalid value %q for metric %s", value, metric)
+	}
+	return nil
+}
+
+// ValidateModifiers checks environmental modifiers such as {"MAV": "A", "CR": "L"}.
+func ValidateModifiers(mods map[string]string) error {
+	for metric, value := range mods {
+		if !strings.HasPrefix(metric, "M") && metric != "CR" && metric != "IR" && metric != "AR" {
+			return fmt.Errorf("%s is not an environmental metric", metric)
+		}
+		if err := validMetric(metric, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func weight(metric, value string, scopeChanged bool) float64 {
+	switch metric {
+	case "AV":
+		return map[string]float64{"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2}[value]
+	case "AC":
+		return map[string]float64{"L": 0.77, "H": 0.44}[value]
+	case "PR":
+		if scopeChanged {
+			return map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}[value]
+		}
+		return map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}[value]
+	case "UI":
+		return map[string]float64{"N": 0.85, "R": 0.62}[value]
+	case "C", "I", "A":
+		return map[string]float64{"H": 0.56, "L": 0.22, "N": 0}[value]
+	case "CR", "IR", "AR":
+		return map[string]float64{"H": 1.5, "M": 1.0, "L": 0.5, "X": 1.0, "": 1.0}[value]
+	}
+	return 0
+}
+
+// roundUp is the CVSS v3.1 Roundup function, which avoids floating point artefacts.
+func roundUp(x float64) float64 {
+	i := int64(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
+
+// BaseScore returns the CVSS v3.1 base score.
+func (v Vector) BaseScore() float64 {
+	changed := v["S"] == "C"
+	iss := 1 - (1-weight("C", v["C"], false))*(1-weight("I", v["I"], false))*(1-weight("A", v["A"], false))
+	var impact float64
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	exploitability := 8.22 * weight("AV", v["AV"], false) * weight("AC", v["AC"], false) *
+		weight("PR", v["PR"], changed) * weight("UI", v["UI"], false)
+	if impact <= 0 {
+		return 0
+	}
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10))
+	}
+	return roundUp(math.Min(impact+exploitability, 10))
+}
+
+// modified returns the environmental override of a base metric, falling back to the
+// vector's own modifier and then its base value.
+func (v Vector) modified(mods map[string]string, metric string) string {
+	if m := mods["M"+metric]; m != "" && m != "X" {
+		return m
+	}
+	if m := v["M"+metric]; m != "" && m != "X" {
+		return m
+	}
+	return v[metric]
+}
+
+// EnvironmentalScore returns the CVSS v3.1 environmental score after applying mods on top
+// of any environmental metrics already in the vector. Temporal metrics are ignored.
+func (v Vector) EnvironmentalScore(mods map[string]string) float64 {
+	requirement := func(metric string) float64 {
+		if m := mods[metric]; m != "" {
+			return weight(metric, m, false)
+		}
+		return weight(metric, v[metric], false)
+	}
+	changed := v.modified(mods, "S") == "C"
+	miss := math.Min(1-
+		(1-requirement("CR")*weight("C", v.modified(mods, "C"), false))*
+			(1-requirement("IR")*weight("I", v.modified(mods, "I"), false))*
+			(1-requirement("AR")*weight("A", v.modified(mods, "A"), false)), 0.915)
+	var impact float64
+	if changed {
+		impact = 7.52*(miss-0.029) - 3.25*math.Pow(miss*0.9731-0.02, 13)
+	} else {
+		impact = 6.42 * miss
+	}
+	exploitability := 8.22 * weight("AV", v.modified(mods, "AV"), false) * weight("AC", v.modified(mods, "AC"), false) *
+		weight("PR", v.modified(mods, "PR"), changed) * weight("UI", v.modified(mods, "UI"), false)
+	if impact <= 0 {
+		return 0
+	}
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10))
+	}
+	return roundUp(math.Min(impact+exploitability, 10))
+}
+
+// Severity maps a score to the qualitative CVSS v3 rating in Trivy's upper-case form.
+func Severity(score float64) string {
+	switch {
+	case score >= 9:
+		return "CRITICAL"
+	case score >= 7:
+		return "HIGH"
+	case score >= 4:
+		return "MEDIUM"
+	case score > 0:
+		return "LOW"
+	}
+	return "NONE"
+}
@@ -0,0 +1,114 @@
+package cvss
+
+import "testing"
+
+func TestBaseScore(t *testing.T) {
+	tests := map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H": 10.0,
+		"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N": 5.5,
+		"CVSS:3.0/AV:N/AC:H/PR:N/UI:R/S:U/C:L/I:N/A:N": 3.1,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+	}
+	for vector, want := range tests {
+		v, err := ParseVector(vector)
+		if err != nil {
+			t.Fatalf("ParseVector(%q): %v", vector, err)
+		}
+		if got := v.BaseScore(); got != want {
+			t.Errorf("BaseScore(%q) = %v, want %v", vector, got, want)
+		}
+	}
+}
+
+func TestEnvironmentalScore(t *testing.T) {
+	v, err := ParseVector("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.EnvironmentalScore(nil); got != 9.8 {
+		t.Errorf("without modifiers got %v, want base score 9.8", got)
+	}
+	// internal-only workload: network vector becomes adjacent
+	if got := v.EnvironmentalScore(map[string]string{"MAV": "A"}); got != 8.8 {
+		t.Errorf("MAV:A got %v, want 8.8", got)
+	}
+	if got := v.EnvironmentalScore(map[string]string{"MAV": "L", "CR": "L", "IR": "L", "AR": "L"}); got != 6.6 {
+		t.Errorf("MAV:L with low requirements got %v, want 6.6", got)
+	}
+}
+
+func TestParseVectorErrors(t *testing.T) {
+	for _, vector := range []string{
+		"",
+		"CVSS:2.0/AV:N/AC:L/Au:N/C:P/I:P/A:P",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H",
+		"CVSS:3.1/AV:Q/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+	} {
+		if _, err := ParseVector(vector); err == nil {
+			t.Errorf("expected error for %q", vector)
+		}
+	}
+}
+
+func TestValidateModifiers(t *testing.T) {
+	if err := ValidateModifiers(map[string]string{"MAV": "A", "CR": "L"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateModifiers(map[string]string{"AV": "A"}); err == nil {
+		t.Error("expected base metrics to be rejected")
+	}
+	if err := ValidateModifiers(map[string]string{"MAV": "Z"}); err == nil {
+		t.Error("expected invalid values to be rejected")
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tests := map[float64]string{0: "NONE", 0.1: "LOW", 3.9: "LOW", 4: "MEDIUM", 7: "HIGH", 8.9: "HIGH", 9: "CRITICAL"}
+	for score, want := range tests {
+		if got := Severity(score); got != want {
+			t.Errorf("Severity(%v) = %q, want %q", score, got, want)
+		}
+	}
+}
+
+func TestRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+rules:
+  - name: internal
+    namespaceSelector: exposure=internal
+    modifiers: {MAV: A}
+  - name: low-value
+    namespaceSelector: tier in (dev, test)
+    modifiers: {CR: L, MAV: L}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mods := rules.ModifiersFor(map[string]string{"exposure": "public"}); mods != nil {
+		t.Errorf("expected no modifiers, got %v", mods)
+	}
+	mods := rules.ModifiersFor(map[string]string{"exposure": "internal", "tier": "dev"})
+	if mods["MAV"] != "L" || mods["CR"] != "L" {
+		t.Errorf("expected later rule to override, got %v", mods)
+	}
+
+	score, severity := Effective("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "CRITICAL", map[string]string{"MAV": "A"})
+	if score != 8.8 || severity != "HIGH" {
+		t.Errorf("got %v %s, want 8.8 HIGH", score, severity)
+	}
+	if _, severity := Effective("", "CRITICAL", map[string]string{"MAV": "A"}); severity != "CRITICAL" {
+		t.Errorf("expected original severity without a vector, got %s", severity)
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	for _, data := range []string{
+		"rules: [{namespaceSelector: 'a in (', modifiers: {MAV: A}}]",
+		"rules: [{modifiers: {AV: A}}]",
+	} {
+		if _, err := ParseRules([]byte(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cvss
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// Rule applies environmental modifiers to findings in namespaces matching a label selector.
+// An empty selector matches every namespace.
+type Rule struct {
+	Name              string            `json:"name"`
+	NamespaceSelector string            `json:"namespaceSelector"`
+	Modifiers         map[string]string `json:"modifiers"`
+
+	selector labels.Selector
+}
+
+type Rules []Rule
+
+// LoadRules reads a YAML or JSON file of the form {"rules": [...]}.
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read severity rules: %w", err)
+	}
+	return ParseRules(data)
+}
+
+func ParseRules(data []byte) (Rules, error) {
+	var file struct {
+		Rules Rules `json:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse severity rules: %w", err)
+	}
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		selector, err := labels.Parse(rule.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: invalid namespaceSelector: %w", i+1, err)
+		}
+		if err := ValidateModifiers(rule.Modifiers); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		rule.selector = selector
+	}
+	return file.Rules, nil
+}
+
+// ModifiersFor merges the modifiers of all rules matching the namespace labels; later
+// rules override earlier ones. It returns nil when no rule matches.
+func (rs Rules) ModifiersFor(namespaceLabels map[string]string) map[string]string {
+	var mods map[string]string
+	for _, rule := range rs {
+		if rule.selector == nil || !rule.selector.Matches(labels.Set(namespaceLabels)) {
+			continue
+		}
+		if mods == nil {
+			mods = make(map[string]string, len(rule.Modifiers))
+		}
+		for metric, value := range rule.Modifiers {
+			mods[metric] = value
+		}
+	}
+	return mods
+}
+
+// Effective re-rates a finding. Findings without a usable vector, or without modifiers,
+// keep their original severity and a zero score.
+func Effective(vector, severity string, mods map[string]string) (float64, string) {
+	if len(mods) == 0 || vector == "" {
+		return 0, severity
+	}
+	v, err := ParseVector(vector)
+	if err != nil {
+		return 0, severity
+	}
+	score := v.EnvironmentalScore(mods)
+	return score, Severity(score)
+}
@@ -0,0 +1,200 @@
+// Package defectdojo imports findings into DefectDojo through its reimport API, one test
+// per product and engagement.
+package defectdojo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ScanType is the DefectDojo parser the findings are written for.
+const ScanType = "Generic Findings Import"
+
+// Finding is one finding in DefectDojo's generic findings format.
+type Finding struct {
+	Title            string   `json:"title"`
+	Severity         string   `json:"severity"`
+	Description      string   `json:"description"`
+	Mitigation       string   `json:"mitigation,omitempty"`
+	ComponentName    string   `json:"component_name,omitempty"`
+	ComponentVersion string   `json:"component_version,omitempty"`
+	FilePath         string   `json:"file_path,omitempty"`
+	VulnerabilityIDs []string `json:"vulnerability_ids,omitempty"`
+	CVSSv3           string   `json:"cvssv3,omitempty"`
+	CVSSv3Score      float64  `json:"cvssv3_score,omitempty"`
+	// UniqueID lets DefectDojo match the finding across reimports
+	UniqueID       string `json:"unique_id_from_tool"`
+	StaticFinding  bool   `json:"static_finding"`
+	DynamicFinding bool   `json:"dynamic_finding"`
+}
+
+// Scan is the findings of one test. Reimporting the same product, engagement and test
+// title updates the test: new findings are added and those no longer reported are closed.
+type Scan struct {
+	ProductType string
+	Product     string
+	Engagement  string
+	Test        string
+	Findings    []Finding
+}
+
+// Client talks to one DefectDojo instance.
+type Client struct {
+	url    string
+	apiKey func() string
+	client *http.Client
+}
+
+// New returns a client for the DefectDojo at baseURL, with an API key looked up on every
+// request so rotated keys are picked up.
+func New(baseURL string, apiKey func() string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("DefectDojo URL must be an http(s) URL")
+	}
+	return &Client{
+		url:    strings.TrimSuffix(baseURL, "/"),
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 2 * time.Minute},
+	}, nil
+}
+
+// Reimport uploads a scan, creating the product, engagement and test when they do not
+// exist yet, and returns the id of the test.
+func (c *Client) Reimport(ctx context.Context, s Scan) (int, error) {
+	file, err := json.Marshal(map[string]interface{}{"findings": nonNil(s.Findings)})
+	if err != nil {
+		return 0, err
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fields := [][2]string{
+		{"scan_type", ScanType},
+		{"product_type_name", s.ProductType},
+		{"product_name", s.Product},
+		{"engagement_name", s.Engagement},
+		{"test_title", s.Test},
+		{"auto_create_context", "true"},
+		{"close_old_findings", "true"},
+		{"active", "true"},
+		{"verified", "false"},
+		{"minimum_severity", "Info"},
+		{"scan_date", time.Now().UTC().Format("2006-01-02")},
+	}
+	for _, f := range fields {
+		if err := w.WriteField(f[0], f[1]); err != nil {
+			return 0, err
+		}
+	}
+	part, err := w.CreateFormFile("file", "trivy-ui.json")
+	if err != nil {
+		return 0, err
+	}
+	part.Write(file)
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/api/v2/reimport-scan/", &body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	if key := c.apiKey(); key != "" {
+		req.Header.Set("Authorization", "Token "+key)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, fmt.Errorf("DefectDojo returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	var result struct {
+		Test int `json:"test"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("invalid DefectDojo response: %w", err)
+	}
+	return result.Test, nil
+}
+
+// nonNil keeps a scan without findings an empty list, which closes the findings of the
+// previous import.
+func nonNil(findings []Finding) []Finding {
+	if findings == nil {
+		return []Finding{}
+	}
+	return findings
+}
+
+// Mapping picks the product and engagement of a report's findings. Product and
+// Engagement are templates with {cluster} and {namespace} placeholders; Products maps a
+// cluster, or a cluster/namespace, to a fixed product name instead.
+type Mapping struct {
+	ProductType string
+	Product     string
+	Engagement  string
+	Products    map[string]string
+}
+
+// clusterScoped stands in for the namespace of cluster-scoped reports.
+const clusterScoped = "cluster-scoped"
+
+// Resolve returns the product and engagement of a report.
+func (m Mapping) Resolve(cluster, namespace string) (product, engagement string) {
+	if namespace == "" {
+		namespace = clusterScoped
+	}
+	r := strings.NewReplacer("{cluster}", cluster, "{namespace}", namespace)
+	product = r.Replace(m.Product)
+	if p, ok := m.Products[cluster+"/"+namespace]; ok {
+		product = p
+	} else if p, ok := m.Products[cluster]; ok {
+		product = p
+	}
+	return product, r.Replace(m.Engagement)
+}
+
+// WithTarget overrides the templates with a target of the form product/engagement, as
+// saved with an export schedule; either part may be left empty to keep the default.
+func (m Mapping) WithTarget(target string) (Mapping, error) {
+	product, engagement, err := ParseTarget(target)
+	if err != nil {
+		return m, err
+	}
+	if product != "" {
+		m.Product = product
+		// a product given for this push wins over the configured mapping
+		m.Products = nil
+	}
+	if engagement != "" {
+		m.Engagement = engagement
+	}
+	return m, nil
+}
+
+// ParseTarget splits a product/engagement target; an empty target keeps both defaults.
+func ParseTarget(target string) (product, engagement string, err error) {
+	if target == "" {
+		return "", "", nil
+	}
+	product, engagement, ok := strings.Cut(target, "/")
+	if !ok || strings.Contains(engagement, "/") {
+		return "", "", fmt.Errorf("DefectDojo target must look like product/engagement, e.g. {cluster}/{namespace}")
+	}
+	return strings.TrimSpace(product), strings.TrimSpace(engagement), nil
+}
@@ -0,0 +1,102 @@
+package defectdojo
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReimport(t *testing.T) {
+	var fields map[string]string
+	var file struct {
+		Findings []Finding `json:"findings"`
+	}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/reimport-scan/" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		fields = map[string]string{}
+		for k, v := range r.MultipartForm.Value {
+			fields[k] = v[0]
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(f)
+		json.Unmarshal(data, &file)
+		w.Write([]byte(`{"test": 42, "scan_type": "Generic Findings Import"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL+"/", func() string { return "key" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := c.Reimport(t.Context(), Scan{
+		ProductType: "Kubernetes", Product: "prod", Engagement: "web", Test: "trivy-ui vulnerabilityreports",
+		Findings: []Finding{{Title: "CVE-1 in openssl", Severity: "High", UniqueID: "u1"}},
+	})
+	if err != nil || id != 42 {
+		t.Fatalf("id=%d err=%v", id, err)
+	}
+	if auth != "Token key" {
+		t.Errorf("auth = %q", auth)
+	}
+	if fields["scan_type"] != ScanType || fields["product_name"] != "prod" || fields["engagement_name"] != "web" ||
+		fields["auto_create_context"] != "true" || fields["close_old_findings"] != "true" {
+		t.Errorf("fields = %v", fields)
+	}
+	if len(file.Findings) != 1 || file.Findings[0].UniqueID != "u1" {
+		t.Errorf("findings = %+v", file.Findings)
+	}
+}
+
+func TestReimport_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"detail":"Invalid token."}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	c, _ := New(srv.URL, func() string { return "" })
+	if _, err := c.Reimport(t.Context(), Scan{Product: "p"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMappingResolve(t *testing.T) {
+	m := Mapping{
+		Product:    "k8s-{cluster}",
+		Engagement: "{namespace}",
+		Products:   map[string]string{"prod": "Payments", "prod/monitoring": "Platform"},
+	}
+	tests := []struct{ cluster, namespace, product, engagement string }{
+		{"prod", "web", "Payments", "web"},
+		{"prod", "monitoring", "Platform", "monitoring"},
+		{"dev", "web", "k8s-dev", "web"},
+		{"dev", "", "k8s-dev", "cluster-scoped"},
+	}
+	for _, tt := range tests {
+		p, e := m.Resolve(tt.cluster, tt.namespace)
+		if p != tt.product || e != tt.engagement {
+			t.Errorf("Resolve(%s, %s) = %s, %s", tt.cluster, tt.namespace, p, e)
+		}
+	}
+
+	m, err := m.WithTarget("Fleet/{cluster}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, e := m.Resolve("prod", "web"); p != "Fleet" || e != "prod" {
+		t.Errorf("with target: %s, %s", p, e)
+	}
+	if _, err := m.WithTarget("no-slash"); err == nil {
+		t.Error("expected invalid target error")
+	}
+}
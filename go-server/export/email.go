@@ -0,0 +1,82 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+type emailDeliverer struct {
+	settings Settings
+}
+
+func (e *emailDeliverer) Deliver(ctx context.Context, target string, f File) error {
+	addrs, err := mail.ParseAddressList(target)
+	if err != nil {
+		return fmt.Errorf("invalid email recipients: %w", err)
+	}
+	to := make([]string, len(addrs))
+	for i, a := range addrs {
+		to[i] = a.Address
+	}
+	msg, err := buildMessage(e.settings.SMTPFrom, to, "Trivy UI export: "+f.Name, f, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if e.settings.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", e.settings.SMTPUsername, e.settings.SMTPPassword, e.settings.SMTPHost)
+	}
+	addr := fmt.Sprintf("%s:%d", e.settings.SMTPHost, e.settings.SMTPPort)
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(addr, auth, e.settings.SMTPFrom, to, msg) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// buildMessage renders a MIME message with the export as a base64 attachment.
+func buildMessage(from string, to []string, subject string, f File, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	header := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n",
+		from, strings.Join(to, ", "), subject, now.Format(time.RFC1123Z), writer.Boundary())
+
+	text, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(text, "The scheduled export %s is attached.\r\n", f.Name)
+
+	attachment, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {f.ContentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", f.Name)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(f.Data)
+	for len(encoded) > 76 {
+		fmt.Fprintf(attachment, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(attachment, "%s\r\n", encoded)
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return append([]byte(header), buf.Bytes()...), nil
+}
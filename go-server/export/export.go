@@ -0,0 +1,122 @@
+// Package export delivers generated export files to email, S3, webhook and Slack destinations.
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"trivy-ui/defectdojo"
+)
+
+const (
+	DestinationEmail   = "email"
+	DestinationS3      = "s3"
+	DestinationWebhook = "webhook"
+	// DestinationSlack posts the file's summary to a Slack incoming webhook
+	DestinationSlack = "slack"
+	// DestinationDefectDojo imports the findings of the selected reports instead of
+	// delivering a file; its target is a product/engagement template
+	DestinationDefectDojo = "defectdojo"
+	// DestinationRouted delivers each selected report to the destination of its
+	// notification route; it has no target of its own
+	DestinationRouted = "routed"
+)
+
+// File is a generated export.
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+	// Summary is a short text rendering for destinations that post messages, not files
+	Summary string
+}
+
+// Deliverer sends a file to a destination target: comma-separated addresses for email,
+// s3://bucket/prefix for S3, and a URL for webhooks and Slack.
+type Deliverer interface {
+	Deliver(ctx context.Context, target string, f File) error
+}
+
+// Settings holds the credentials destinations need; they come from the server config,
+// never from API requests.
+type Settings struct {
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	S3Endpoint     string
+	S3Region       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3SessionToken string
+
+	// WebhookSecret signs webhook deliveries like outbound events, see webhooks.Sign
+	WebhookSecret string
+}
+
+func New(destination string, s Settings) (Deliverer, error) {
+	client := &http.Client{Timeout: 60 * time.Second}
+	switch destination {
+	case DestinationEmail:
+		if s.SMTPHost == "" || s.SMTPFrom == "" {
+			return nil, fmt.Errorf("email exports need SMTP_HOST and SMTP_FROM")
+		}
+		return &emailDeliverer{settings: s}, nil
+	case DestinationS3:
+		if s.S3AccessKey == "" || s.S3SecretKey == "" {
+			return nil, fmt.Errorf("S3 exports need AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		return &s3Deliverer{settings: s, client: client}, nil
+	case DestinationWebhook:
+		return &webhookDeliverer{client: client, secret: s.WebhookSecret}, nil
+	case DestinationSlack:
+		return &slackDeliverer{client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported destination %q", destination)
+	}
+}
+
+// ValidateTarget checks that a target is well formed for its destination.
+func ValidateTarget(destination, target string) error {
+	switch destination {
+	case DestinationEmail:
+		if _, err := mail.ParseAddressList(target); err != nil {
+			return fmt.Errorf("invalid email recipients: %w", err)
+		}
+	case DestinationS3:
+		if _, _, err := parseS3Target(target); err != nil {
+			return err
+		}
+	case DestinationDefectDojo:
+		if _, _, err := defectdojo.ParseTarget(target); err != nil {
+			return err
+		}
+	case DestinationWebhook, DestinationSlack:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s target must be an http(s) URL", destination)
+		}
+	default:
+		return fmt.Errorf("unsupported destination %q", destination)
+	}
+	return nil
+}
+
+func parseS3Target(target string) (bucket, prefix string, err error) {
+	rest, ok := strings.CutPrefix(target, "s3://")
+	if !ok {
+		return "", "", fmt.Errorf("S3 target must look like s3://bucket/prefix")
+	}
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("S3 target is missing a bucket")
+	}
+	return bucket, prefix, nil
+}
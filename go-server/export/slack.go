@@ -0,0 +1,31 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+type slackDeliverer struct {
+	client *http.Client
+}
+
+// Deliver posts the file's summary, or its name when it has none, to a Slack incoming
+// webhook; Slack webhooks take messages, not attachments.
+func (s *slackDeliverer) Deliver(ctx context.Context, target string, f File) error {
+	text := f.Summary
+	if text == "" {
+		text = "Trivy UI export: " + f.Name
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doRequest(s.client, req, "slack")
+}
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"trivy-ui/webhooks"
+)
+
+type webhookDeliverer struct {
+	client *http.Client
+	secret string
+}
+
+func (w *webhookDeliverer) Deliver(ctx context.Context, target string, f File) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(f.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", f.ContentType)
+	req.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", f.Name))
+	webhooks.Sign(req, w.secret, f.Data, time.Now())
+	return doRequest(w.client, req, "webhook")
+}
+
+func doRequest(client *http.Client, req *http.Request, what string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", what, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
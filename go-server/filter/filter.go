@@ -0,0 +1,314 @@
+// Package filter implements the filter= expression language shared by list endpoints, e.g.
+//
+//	severity in (CRITICAL,HIGH) and namespace startsWith "prod-" and fixAvailable=true
+//
+// Expressions combine comparisons with and/or/not and parentheses; and binds tighter than or.
+// Operators: = != > >= < <= in contains startsWith endsWith. String comparisons ignore case.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type is the value type of a filterable field.
+type Type int
+
+const (
+	String Type = iota
+	Number
+	Bool
+)
+
+// Fields declares the fields an expression may reference.
+type Fields map[string]Type
+
+// Record supplies field values during evaluation: string, float64 or bool matching the declared Type.
+type Record interface {
+	Field(name string) interface{}
+}
+
+type Expr interface {
+	Eval(r Record) bool
+}
+
+// Parse compiles an expression, checking field names and value types against fields.
+// An empty expression parses to nil, which callers treat as "match everything".
+func Parse(input string, fields Fields) (Expr, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fields: fields}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	fields Fields
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	if t.kind == tokIdent && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.keyword("not") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner}, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, fmt.Errorf("expected ')' but found %s", t)
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokIdent {
+		return nil, fmt.Errorf("expected field name but found %s", fieldTok)
+	}
+	field, typ, ok := p.lookupField(fieldTok.text)
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", fieldTok.text)
+	}
+
+	opTok := p.next()
+	op := opTok.text
+	switch {
+	case opTok.kind == tokOp:
+	case opTok.kind == tokIdent && (strings.EqualFold(op, "in") || strings.EqualFold(op, "contains") ||
+		strings.EqualFold(op, "startsWith") || strings.EqualFold(op, "endsWith")):
+		op = strings.ToLower(op)
+	default:
+		return nil, fmt.Errorf("expected operator after %q but found %s", fieldTok.text, opTok)
+	}
+
+	if op == "in" {
+		if t := p.next(); t.kind != tokLParen {
+			return nil, fmt.Errorf("expected '(' after in but found %s", t)
+		}
+		var values []interface{}
+		for {
+			v, err := p.parseValue(field, typ)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			t := p.next()
+			if t.kind == tokRParen {
+				break
+			}
+			if t.kind != tokComma {
+				return nil, fmt.Errorf("expected ',' or ')' but found %s", t)
+			}
+		}
+		return inExpr{field: field, values: values}, nil
+	}
+
+	if err := checkOperator(field, typ, op); err != nil {
+		return nil, err
+	}
+	value, err := p.parseValue(field, typ)
+	if err != nil {
+		return nil, err
+	}
+	return compareExpr{field: field, op: op, value: value}, nil
+}
+
+func (p *parser) lookupField(name string) (string, Type, bool) {
+	if typ, ok := p.fields[name]; ok {
+		return name, typ, true
+	}
+	for field, typ := range p.fields {
+		if strings.EqualFold(field, name) {
+			return field, typ, true
+		}
+	}
+	return "", 0, false
+}
+
+func checkOperator(field string, typ Type, op string) error {
+	switch op {
+	case "=", "!=":
+		return nil
+	case ">", ">=", "<", "<=":
+		if typ == Number {
+			return nil
+		}
+	case "contains", "startswith", "endswith":
+		if typ == String {
+			return nil
+		}
+	}
+	return fmt.Errorf("operator %s is not supported for field %q", op, field)
+}
+
+func (p *parser) parseValue(field string, typ Type) (interface{}, error) {
+	t := p.next()
+	if t.kind != tokString && t.kind != tokIdent && t.kind != tokNumber {
+		return nil, fmt.Errorf("expected value for %q but found %s", field, t)
+	}
+	switch typ {
+	case Number:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %q expects a number, got %q", field, t.text)
+		}
+		return f, nil
+	case Bool:
+		b, err := strconv.ParseBool(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("field %q expects true or false, got %q", field, t.text)
+		}
+		return b, nil
+	default:
+		return t.text, nil
+	}
+}
+
+type andExpr struct{ left, right Expr }
+
+func (e andExpr) Eval(r Record) bool { return e.left.Eval(r) && e.right.Eval(r) }
+
+type orExpr struct{ left, right Expr }
+
+func (e orExpr) Eval(r Record) bool { return e.left.Eval(r) || e.right.Eval(r) }
+
+type notExpr struct{ inner Expr }
+
+func (e notExpr) Eval(r Record) bool { return !e.inner.Eval(r) }
+
+type inExpr struct {
+	field  string
+	values []interface{}
+}
+
+func (e inExpr) Eval(r Record) bool {
+	actual := r.Field(e.field)
+	for _, v := range e.values {
+		if equal(actual, v) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareExpr struct {
+	field string
+	op    string
+	value interface{}
+}
+
+func (e compareExpr) Eval(r Record) bool {
+	actual := r.Field(e.field)
+	switch e.op {
+	case "=":
+		return equal(actual, e.value)
+	case "!=":
+		return !equal(actual, e.value)
+	case "contains", "startswith", "endswith":
+		a, _ := actual.(string)
+		a, v := strings.ToLower(a), strings.ToLower(e.value.(string))
+		switch e.op {
+		case "contains":
+			return strings.Contains(a, v)
+		case "startswith":
+			return strings.HasPrefix(a, v)
+		default:
+			return strings.HasSuffix(a, v)
+		}
+	}
+	a, ok := actual.(float64)
+	if !ok {
+		return false
+	}
+	v := e.value.(float64)
+	switch e.op {
+	case ">":
+		return a > v
+	case ">=":
+		return a >= v
+	case "<":
+		return a < v
+	case "<=":
+		return a <= v
+	}
+	return false
+}
+
+func equal(actual, expected interface{}) bool {
+	if s, ok := expected.(string); ok {
+		a, _ := actual.(string)
+		return strings.EqualFold(a, s)
+	}
+	return actual == expected
+}
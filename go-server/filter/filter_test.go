@@ -0,0 +1,84 @@
+package filter
+
+import "testing"
+
+type mapRecord map[string]interface{}
+
+func (m mapRecord) Field(name string) interface{} { return m[name] }
+
+var testFields = Fields{
+	"severity":     String,
+	"namespace":    String,
+	"critical":     Number,
+	"fixAvailable": Bool,
+}
+
+func mustParse(t *testing.T, input string) Expr {
+	t.Helper()
+	expr, err := Parse(input, testFields)
+	if err != nil {
+		t.Fatalf("parse %q: %v", input, err)
+	}
+	return expr
+}
+
+func TestParse_RequestExample(t *testing.T) {
+	expr := mustParse(t, `severity in (CRITICAL,HIGH) and namespace startsWith "prod-" and fixAvailable=true`)
+
+	match := mapRecord{"severity": "Critical", "namespace": "prod-payments", "fixAvailable": true}
+	if !expr.Eval(match) {
+		t.Fatal("expected match")
+	}
+	for _, r := range []mapRecord{
+		{"severity": "Low", "namespace": "prod-payments", "fixAvailable": true},
+		{"severity": "High", "namespace": "staging", "fixAvailable": true},
+		{"severity": "High", "namespace": "prod-a", "fixAvailable": false},
+	} {
+		if expr.Eval(r) {
+			t.Fatalf("expected no match for %v", r)
+		}
+	}
+}
+
+func TestParse_PrecedenceAndNot(t *testing.T) {
+	// and binds tighter than or
+	expr := mustParse(t, `critical > 5 or namespace = dev and not fixAvailable = true`)
+	if !expr.Eval(mapRecord{"critical": 6.0, "namespace": "prod", "fixAvailable": true}) {
+		t.Fatal("left side of or should match")
+	}
+	if !expr.Eval(mapRecord{"critical": 0.0, "namespace": "dev", "fixAvailable": false}) {
+		t.Fatal("right side of or should match")
+	}
+	if expr.Eval(mapRecord{"critical": 0.0, "namespace": "dev", "fixAvailable": true}) {
+		t.Fatal("not should negate")
+	}
+
+	grouped := mustParse(t, `(critical > 5 or namespace = dev) and fixAvailable = false`)
+	if grouped.Eval(mapRecord{"critical": 6.0, "namespace": "prod", "fixAvailable": true}) {
+		t.Fatal("parentheses should group the or")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, input := range []string{
+		`unknown = 1`,
+		`critical = high`,
+		`critical contains 1`,
+		`fixAvailable > true`,
+		`severity in (HIGH`,
+		`namespace = "unterminated`,
+		`severity = HIGH and`,
+		`severity HIGH`,
+	} {
+		if _, err := Parse(input, testFields); err == nil {
+			t.Fatalf("expected error for %q", input)
+		}
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	expr, err := Parse("  ", testFields)
+	if err != nil || expr != nil {
+		t.Fatalf("expected nil expression, got %v %v", expr, err)
+	}
+}
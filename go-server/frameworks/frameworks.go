@@ -0,0 +1,144 @@
+// Package frameworks maps Trivy config audit, RBAC and infra assessment check IDs to the
+// NIST SP 800-53 and ISO/IEC 27001:2022 Annex A controls they give evidence for.
+package frameworks
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported frameworks.
+const (
+	NIST80053 = "nist-800-53"
+	ISO27001  = "iso-27001"
+)
+
+// Check is the mapping of one check ID.
+type Check struct {
+	CheckID  string   `json:"checkID"`
+	Title    string   `json:"title"`
+	NIST     []string `json:"nist"`
+	ISO27001 []string `json:"iso27001"`
+}
+
+// Control is a framework control with the checks mapped to it.
+type Control struct {
+	Framework string   `json:"framework"`
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Checks    []string `json:"checks"`
+}
+
+type mapping struct {
+	Controls map[string]map[string]string `json:"controls"`
+	Checks   []Check                      `json:"checks"`
+}
+
+//go:embed mapping.json
+var mappingJSON []byte
+
+var checks, controls = mustLoad(mappingJSON)
+
+// mustLoad indexes the table by check ID and by framework, and rejects checks mapped to
+// controls the table does not name.
+func mustLoad(data []byte) (map[string]Check, map[string][]Control) {
+	var m mapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		panic("frameworks: invalid mapping table: " + err.Error())
+	}
+	byCheck := make(map[string]Check, len(m.Checks))
+	byControl := make(map[string]map[string]*Control)
+	for framework, titles := range m.Controls {
+		byControl[framework] = make(map[string]*Control, len(titles))
+		for id, title := range titles {
+			byControl[framework][id] = &Control{Framework: framework, ID: id, Title: title}
+		}
+	}
+	for _, c := range m.Checks {
+		byCheck[c.CheckID] = c
+		for framework, ids := range map[string][]string{NIST80053: c.NIST, ISO27001: c.ISO27001} {
+			for _, id := range ids {
+				control, ok := byControl[framework][id]
+				if !ok {
+					panic("frameworks: " + c.CheckID + " maps to unknown control " + framework + " " + id)
+				}
+				control.Checks = append(control.Checks, c.CheckID)
+			}
+		}
+	}
+
+	result := make(map[string][]Control, len(byControl))
+	for framework, list := range byControl {
+		for _, c := range list {
+			if len(c.Checks) == 0 {
+				continue
+			}
+			sort.Strings(c.Checks)
+			result[framework] = append(result[framework], *c)
+		}
+		sort.Slice(result[framework], func(i, j int) bool {
+			return controlLess(result[framework][i].ID, result[framework][j].ID)
+		})
+	}
+	return byCheck, result
+}
+
+// controlLess orders control IDs by family, then numerically: AC-3 before AC-12 and
+// A.8.9 before A.8.15.
+func controlLess(a, b string) bool {
+	pa := strings.FieldsFunc(a, func(r rune) bool { return r == '-' || r == '.' })
+	pb := strings.FieldsFunc(b, func(r rune) bool { return r == '-' || r == '.' })
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if pa[i] == pb[i] {
+			continue
+		}
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		if errA == nil && errB == nil {
+			return na < nb
+		}
+		return pa[i] < pb[i]
+	}
+	return len(pa) < len(pb)
+}
+
+// Supported reports whether framework has a mapping.
+func Supported(framework string) bool {
+	_, ok := controls[framework]
+	return ok
+}
+
+// Lookup returns the mapping of a check ID.
+func Lookup(checkID string) (Check, bool) {
+	c, ok := checks[checkID]
+	return c, ok
+}
+
+// ControlIDs returns the controls of framework a check maps to.
+func (c Check) ControlIDs(framework string) []string {
+	switch framework {
+	case NIST80053:
+		return c.NIST
+	case ISO27001:
+		return c.ISO27001
+	}
+	return nil
+}
+
+// Controls returns the controls of framework that checks map to, in control order.
+func Controls(framework string) []Control {
+	return append([]Control(nil), controls[framework]...)
+}
+
+// Checks returns the mapping table ordered by check ID.
+func Checks() []Check {
+	result := make([]Check, 0, len(checks))
+	for _, c := range checks {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CheckID < result[j].CheckID })
+	return result
+}
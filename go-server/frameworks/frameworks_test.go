@@ -0,0 +1,43 @@
+package frameworks
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	c, ok := Lookup("KSV017")
+	if !ok || len(c.ControlIDs(NIST80053)) == 0 || len(c.ControlIDs(ISO27001)) == 0 {
+		t.Fatalf("unexpected check %+v", c)
+	}
+	if _, ok := Lookup("KSV999"); ok {
+		t.Fatal("unknown checks should not map")
+	}
+	if !Supported(NIST80053) || !Supported(ISO27001) || Supported("soc2") {
+		t.Fatal("unexpected supported frameworks")
+	}
+}
+
+func TestControlsOrder(t *testing.T) {
+	nist := Controls(NIST80053)
+	var ids []string
+	for _, c := range nist {
+		if len(c.Checks) == 0 || c.Title == "" {
+			t.Errorf("control %+v has no checks or title", c)
+		}
+		ids = append(ids, c.ID)
+	}
+	index := func(id string) int {
+		for i, got := range ids {
+			if got == id {
+				return i
+			}
+		}
+		t.Fatalf("control %s missing from %v", id, ids)
+		return -1
+	}
+	if index("AU-2") > index("AU-12") || index("SC-7") > index("SC-39") {
+		t.Errorf("controls not in numeric order: %v", ids)
+	}
+	iso := Controls(ISO27001)
+	if len(iso) == 0 || iso[0].ID != "A.5.15" || iso[len(iso)-1].ID != "A.8.22" {
+		t.Errorf("unexpected ISO 27001 controls %+v", iso)
+	}
+}
@@ -0,0 +1,11 @@
+// Package integration holds end-to-end tests that run trivy-ui's informers, cache and
+// HTTP handlers against a real API server with the Trivy Operator CRDs installed. They
+// are built with the integration tag and use the cluster of KUBECONFIG, e.g. a kind
+// cluster:
+//
+//	kind create cluster --name trivy-ui-it
+//	go test -tags integration ./integration/...
+//
+// The CRDs are installed when missing, and the reports are created in a namespace of
+// their own that is deleted afterwards, so a cluster running the Trivy Operator works too.
+package integration
@@ -0,0 +1,132 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type listedReport struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Status    string                 `json:"status"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// listedStatus returns the status of a report in the cached list of its type, or "" when
+// it is not listed.
+func listedStatus(resource, name string) (string, error) {
+	var page struct {
+		Data []listedReport `json:"data"`
+	}
+	query := url.Values{"cluster": {clusterName}, "namespace": {env.namespace}, "pageSize": {"100"}}
+	if code, err := get("/api/v1/type/"+resource, query, &page); err != nil || code != http.StatusOK {
+		return "", fmt.Errorf("list returned %d: %v", code, err)
+	}
+	for _, r := range page.Data {
+		if r.Name == name && r.Namespace == env.namespace {
+			return r.Status, nil
+		}
+	}
+	return "", nil
+}
+
+// vulnerabilityReport builds the report field of a VulnerabilityReport. Numbers are
+// int64, the only integer type unstructured objects can be copied with.
+func vulnerabilityReport(critical, high int, ids ...string) map[string]interface{} {
+	vulns := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		vulns = append(vulns, map[string]interface{}{
+			"vulnerabilityID":  id,
+			"resource":         "openssl",
+			"installedVersion": "3.0.1",
+			"fixedVersion":     "3.0.2",
+			"severity":         "CRITICAL",
+		})
+	}
+	return map[string]interface{}{
+		"artifact":        map[string]interface{}{"repository": "library/nginx", "tag": "1.25"},
+		"registry":        map[string]interface{}{"server": "docker.io"},
+		"summary":         map[string]interface{}{"criticalCount": int64(critical), "highCount": int64(high), "mediumCount": int64(0), "lowCount": int64(0)},
+		"vulnerabilities": vulns,
+	}
+}
+
+// TestVulnerabilityReportLifecycle follows a report from creation through a rescan to
+// deletion, through the informer, the cache and the list and detail handlers.
+func TestVulnerabilityReportLifecycle(t *testing.T) {
+	const name = "replicaset-web-6d4cf56db6-nginx"
+	createReport(t, vulnerabilityReports, "VulnerabilityReport", name, vulnerabilityReport(1, 0, "CVE-2024-0001"))
+	waitFor(t, "created report listed as Critical", func() error {
+		status, err := listedStatus(vulnerabilityReports.Resource, name)
+		if err == nil && status != "Critical" {
+			err = fmt.Errorf("status %q", status)
+		}
+		return err
+	})
+
+	var detail listedReport
+	query := url.Values{"type": {vulnerabilityReports.Resource}, "name": {name}, "cluster": {clusterName}, "namespace": {env.namespace}}
+	if code, err := get("/api/v1/reports/detail", query, &detail); err != nil || code != http.StatusOK {
+		t.Fatalf("detail returned %d: %v", code, err)
+	}
+	report, _ := detail.Data["report"].(map[string]interface{})
+	if vulns, _ := report["vulnerabilities"].([]interface{}); len(vulns) != 1 {
+		t.Fatalf("expected the detail to carry the vulnerability, got %v", detail.Data)
+	}
+
+	updateReport(t, vulnerabilityReports, name, vulnerabilityReport(0, 2))
+	waitFor(t, "rescanned report listed as High", func() error {
+		status, err := listedStatus(vulnerabilityReports.Resource, name)
+		if err == nil && status != "High" {
+			err = fmt.Errorf("status %q", status)
+		}
+		return err
+	})
+
+	deleteReport(t, vulnerabilityReports, name)
+	waitFor(t, "deleted report unlisted", func() error {
+		status, err := listedStatus(vulnerabilityReports.Resource, name)
+		if err == nil && status != "" {
+			err = fmt.Errorf("still listed as %q", status)
+		}
+		return err
+	})
+}
+
+// TestConfigAuditPSS checks that failed checks of a config audit report reach the Pod
+// Security Standards rollup.
+func TestConfigAuditPSS(t *testing.T) {
+	const name = "replicaset-agent-7c9d8f5b4"
+	createReport(t, configAuditReports, "ConfigAuditReport", name, map[string]interface{}{
+		"summary": map[string]interface{}{"criticalCount": int64(0), "highCount": int64(1), "mediumCount": int64(0), "lowCount": int64(0)},
+		"checks": []interface{}{
+			map[string]interface{}{"checkID": "KSV017", "title": "Privileged container", "severity": "HIGH", "success": false},
+			map[string]interface{}{"checkID": "KSV012", "title": "Runs as root user", "severity": "MEDIUM", "success": true},
+		},
+	})
+
+	waitFor(t, "namespace privileged in the PSS rollup", func() error {
+		var rollup struct {
+			Namespaces []struct {
+				Namespace string `json:"namespace"`
+				Level     string `json:"level"`
+			} `json:"namespaces"`
+		}
+		if code, err := get("/api/v1/pss", url.Values{"cluster": {clusterName}}, &rollup); err != nil || code != http.StatusOK {
+			return fmt.Errorf("pss returned %d: %v", code, err)
+		}
+		for _, ns := range rollup.Namespaces {
+			if ns.Namespace == env.namespace {
+				if ns.Level != "privileged" {
+					return fmt.Errorf("level %q", ns.Level)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("namespace not in %+v", rollup.Namespaces)
+	})
+}
@@ -0,0 +1,271 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"trivy-ui/api"
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+const (
+	clusterName = "it"
+	trivyGroup  = "aquasecurity.github.io"
+	// eventually is how long a change in the cluster may take to reach the handlers
+	eventually = 30 * time.Second
+)
+
+var (
+	vulnerabilityReports = schema.GroupVersionResource{Group: trivyGroup, Version: "v1alpha1", Resource: "vulnerabilityreports"}
+	configAuditReports   = schema.GroupVersionResource{Group: trivyGroup, Version: "v1alpha1", Resource: "configauditreports"}
+)
+
+// env is the cluster and trivy-ui instance the tests share.
+var env struct {
+	dynamic   dynamic.Interface
+	namespace string
+	server    *httptest.Server
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	kubeconfig := os.Getenv("KUBECONFIG")
+	if kubeconfig == "" {
+		kubeconfig = filepath.Join(os.Getenv("HOME"), ".kube", "config")
+	}
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "integration tests need a cluster in KUBECONFIG:", err)
+		return 1
+	}
+	dataPath, err := os.MkdirTemp("", "trivy-ui-it")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer os.RemoveAll(dataPath)
+	os.Setenv("DATA_PATH", dataPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	installed, err := installCRDs(ctx, restConfig)
+	defer removeCRDs(restConfig, installed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to install the Trivy CRDs:", err)
+		return 1
+	}
+	clientset, err := k8s.NewForConfig(restConfig)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	ns, err := clientset.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "trivy-ui-it-"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create the test namespace:", err)
+		return 1
+	}
+	defer clientset.CoreV1().Namespaces().Delete(context.Background(), ns.Name, metav1.DeleteOptions{})
+	env.namespace = ns.Name
+	if env.dynamic, err = dynamic.NewForConfig(restConfig); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// the same wiring as main, for one cluster
+	if err := config.GetGlobalRegistry().DiscoverCRDs(ctx, restConfig); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to discover the Trivy CRDs:", err)
+		return 1
+	}
+	if err := api.LoadCache(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	cacheSvc := api.NewCacheServiceImpl()
+	registry := api.InitDefaultRegistry(cacheSvc)
+	client, err := kubernetes.NewClient(kubeconfig)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := api.SetClusterClient(clusterName, client); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := client.StartInformer(clusterName, api.NewCacheUpdater(registry)); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to start the informers:", err)
+		return 1
+	}
+	defer client.StopInformer()
+	api.SetWarmupCompleted()
+	env.server = httptest.NewServer(api.NewRouter(client, "", cacheSvc, registry, config.GetGlobalRegistry()))
+	defer env.server.Close()
+
+	return m.Run()
+}
+
+// installCRDs creates the CRDs of the report kinds the tests use, with a schema that
+// keeps every field, unless the cluster has them. It returns the names of those created.
+func installCRDs(ctx context.Context, restConfig *rest.Config) ([]string, error) {
+	client, err := apiextensionsclientset.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	preserve := true
+	var created []string
+	for _, kind := range []struct{ plural, kind, short string }{
+		{vulnerabilityReports.Resource, "VulnerabilityReport", "vuln"},
+		{configAuditReports.Resource, "ConfigAuditReport", "configaudit"},
+	} {
+		crd := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: kind.plural + "." + trivyGroup},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: trivyGroup,
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural:     kind.plural,
+					Kind:       kind.kind,
+					ShortNames: []string{kind.short},
+				},
+				Scope: apiextensionsv1.NamespaceScoped,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+					Name:    "v1alpha1",
+					Served:  true,
+					Storage: true,
+					Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+						Type:                   "object",
+						XPreserveUnknownFields: &preserve,
+					}},
+				}},
+			},
+		}
+		_, err := client.ApiextensionsV1().CustomResourceDefinitions().Create(ctx, crd, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			continue
+		}
+		if err != nil {
+			return created, err
+		}
+		created = append(created, crd.Name)
+		err = wait.PollUntilContextCancel(ctx, 500*time.Millisecond, true, func(ctx context.Context) (bool, error) {
+			got, err := client.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, crd.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			for _, c := range got.Status.Conditions {
+				if c.Type == apiextensionsv1.Established && c.Status == apiextensionsv1.ConditionTrue {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+		if err != nil {
+			return created, fmt.Errorf("CRD %s not established: %w", crd.Name, err)
+		}
+	}
+	return created, nil
+}
+
+func removeCRDs(restConfig *rest.Config, names []string) {
+	client, err := apiextensionsclientset.NewForConfig(restConfig)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		client.ApiextensionsV1().CustomResourceDefinitions().Delete(context.Background(), name, metav1.DeleteOptions{})
+	}
+}
+
+// createReport creates a report in the test namespace.
+func createReport(t *testing.T, gvr schema.GroupVersionResource, kind, name string, report map[string]interface{}) {
+	t.Helper()
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvr.GroupVersion().String(),
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": env.namespace},
+		"report":     report,
+	}}
+	if _, err := env.dynamic.Resource(gvr).Namespace(env.namespace).Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create %s %s: %v", gvr.Resource, name, err)
+	}
+}
+
+// updateReport replaces the report field of a report in the test namespace.
+func updateReport(t *testing.T, gvr schema.GroupVersionResource, name string, report map[string]interface{}) {
+	t.Helper()
+	reports := env.dynamic.Resource(gvr).Namespace(env.namespace)
+	obj, err := reports.Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj.Object["report"] = report
+	if _, err := reports.Update(context.Background(), obj, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update %s %s: %v", gvr.Resource, name, err)
+	}
+}
+
+func deleteReport(t *testing.T, gvr schema.GroupVersionResource, name string) {
+	t.Helper()
+	if err := env.dynamic.Resource(gvr).Namespace(env.namespace).Delete(context.Background(), name, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete %s %s: %v", gvr.Resource, name, err)
+	}
+}
+
+// get decodes the data of a trivy-ui API response.
+func get(path string, query url.Values, data interface{}) (int, error) {
+	resp, err := http.Get(env.server.URL + path + "?" + query.Encode())
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(&struct {
+		Data interface{} `json:"data"`
+	}{Data: data})
+}
+
+// waitFor polls check until it returns nil, failing the test with its last error after
+// eventually.
+func waitFor(t *testing.T, what string, check func() error) {
+	t.Helper()
+	deadline := time.Now().Add(eventually)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: %v", what, err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
@@ -0,0 +1,36 @@
+package issues
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type gitHub struct {
+	apiURL string
+	token  func() string
+	client *http.Client
+}
+
+func (g *gitHub) Provider() string { return ProviderGitHub }
+
+func (g *gitHub) CreateIssue(ctx context.Context, repo, title, body string, labels []string) (Issue, error) {
+	payload := map[string]interface{}{"title": title, "body": body}
+	if len(labels) > 0 {
+		payload["labels"] = labels
+	}
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if token := g.token(); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	var resp struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	url := strings.TrimSuffix(g.apiURL, "/") + "/repos/" + repo + "/issues"
+	if err := postJSON(ctx, g.client, url, headers, payload, &resp); err != nil {
+		return Issue{}, err
+	}
+	return Issue{Number: resp.Number, URL: resp.HTMLURL}, nil
+}
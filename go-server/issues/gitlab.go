@@ -0,0 +1,37 @@
+package issues
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type gitLab struct {
+	apiURL string
+	token  func() string
+	client *http.Client
+}
+
+func (g *gitLab) Provider() string { return ProviderGitLab }
+
+func (g *gitLab) CreateIssue(ctx context.Context, repo, title, body string, labels []string) (Issue, error) {
+	payload := map[string]interface{}{"title": title, "description": body}
+	if len(labels) > 0 {
+		payload["labels"] = strings.Join(labels, ",")
+	}
+	headers := map[string]string{}
+	if token := g.token(); token != "" {
+		headers["PRIVATE-TOKEN"] = token
+	}
+
+	var resp struct {
+		IID    int    `json:"iid"`
+		WebURL string `json:"web_url"`
+	}
+	endpoint := strings.TrimSuffix(g.apiURL, "/") + "/projects/" + url.PathEscape(repo) + "/issues"
+	if err := postJSON(ctx, g.client, endpoint, headers, payload, &resp); err != nil {
+		return Issue{}, err
+	}
+	return Issue{Number: resp.IID, URL: resp.WebURL}, nil
+}
@@ -0,0 +1,93 @@
+// Package issues creates tracker issues (GitHub, GitLab) for findings.
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+type Issue struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+}
+
+// Tracker creates an issue in a repository (GitHub "owner/repo", GitLab project path).
+type Tracker interface {
+	Provider() string
+	CreateIssue(ctx context.Context, repo, title, body string, labels []string) (Issue, error)
+}
+
+// New returns the tracker for provider. An empty apiURL selects the public SaaS endpoint.
+func New(provider, apiURL, token string) (Tracker, error) {
+	return NewWithTokenSource(provider, apiURL, func() string { return token })
+}
+
+// NewWithTokenSource is New with a token looked up on every request, so rotated tokens
+// are picked up without recreating the tracker.
+func NewWithTokenSource(provider, apiURL string, token func() string) (Tracker, error) {
+	apiURL, err := APIURL(provider, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 15 * time.Second}
+	switch provider {
+	case ProviderGitHub:
+		return &gitHub{apiURL: apiURL, token: token, client: client}, nil
+	default:
+		return &gitLab{apiURL: apiURL, token: token, client: client}, nil
+	}
+}
+
+// APIURL returns apiURL, or the public SaaS endpoint of provider when it is empty.
+func APIURL(provider, apiURL string) (string, error) {
+	switch provider {
+	case ProviderGitHub:
+		if apiURL == "" {
+			apiURL = "https://api.github.com"
+		}
+	case ProviderGitLab:
+		if apiURL == "" {
+			apiURL = "https://gitlab.com/api/v4"
+		}
+	default:
+		return "", fmt.Errorf("unsupported issue provider %q", provider)
+	}
+	return apiURL, nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("tracker returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
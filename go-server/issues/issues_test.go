@@ -0,0 +1,95 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGitHubCreateIssue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/payments/issues" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("missing token")
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["title"] != "t" {
+			t.Errorf("unexpected body %v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number":42,"html_url":"https://github.com/acme/payments/issues/42"}`))
+	}))
+	defer srv.Close()
+
+	tracker, _ := New(ProviderGitHub, srv.URL, "secret")
+	issue, err := tracker.CreateIssue(context.Background(), "acme/payments", "t", "b", []string{"security"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if issue.Number != 42 || !strings.HasSuffix(issue.URL, "/42") {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+}
+
+func TestGitLabCreateIssue_EscapesProjectPath(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/projects/group%2Fsub%2Fproject/issues" {
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			t.Errorf("missing token")
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"iid":7,"web_url":"https://gitlab.com/group/sub/project/-/issues/7"}`))
+	}))
+	defer srv.Close()
+
+	tracker, _ := New(ProviderGitLab, srv.URL, "secret")
+	issue, err := tracker.CreateIssue(context.Background(), "group/sub/project", "t", "b", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if issue.Number != 7 {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+}
+
+func TestCreateIssue_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	tracker, _ := New(ProviderGitHub, srv.URL, "")
+	if _, err := tracker.CreateIssue(context.Background(), "a/b", "t", "b", nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestRenderDefaultTemplates(t *testing.T) {
+	tmpl, err := LoadTemplates("")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	title, body, err := tmpl.Render(TemplateData{
+		FindingID: "CVE-2024-0001",
+		Resource:  "openssl",
+		Severity:  "CRITICAL",
+		Workloads: []Workload{{Cluster: "prod", Namespace: "payments", Name: "replicaset-api"}},
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if title != "[CRITICAL] CVE-2024-0001 in openssl" {
+		t.Fatalf("unexpected title %q", title)
+	}
+	if !strings.Contains(body, "- prod / payments / replicaset-api") || !strings.Contains(body, "not available") {
+		t.Fatalf("unexpected body:\n%s", body)
+	}
+}
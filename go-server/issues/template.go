@@ -0,0 +1,93 @@
+package issues
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+type Workload struct {
+	Cluster   string
+	Namespace string
+	Name      string
+}
+
+// TemplateData is the input of the issue title and body templates.
+type TemplateData struct {
+	FindingID        string
+	Resource         string
+	Severity         string
+	Score            float64
+	Title            string
+	Description      string
+	InstalledVersion string
+	FixedVersion     string
+	PrimaryLink      string
+	Team             string
+	Workloads        []Workload
+}
+
+const DefaultTitleTemplate = `[{{.Severity}}] {{.FindingID}}{{if .Resource}} in {{.Resource}}{{end}}`
+
+const DefaultBodyTemplate = `## {{.FindingID}}{{if .Title}}: {{.Title}}{{end}}
+
+| Field | Value |
+|-------|-------|
+| Severity | {{.Severity}}{{if .Score}} ({{.Score}}){{end}} |
+{{- if .Resource}}
+| Package | {{.Resource}} |{{end}}
+{{- if .InstalledVersion}}
+| Installed version | {{.InstalledVersion}} |{{end}}
+| Fixed version | {{if .FixedVersion}}{{.FixedVersion}}{{else}}not available{{end}} |
+{{- if .PrimaryLink}}
+| Reference | {{.PrimaryLink}} |{{end}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+### Affected workloads ({{len .Workloads}})
+{{range .Workloads}}
+- {{.Cluster}} / {{.Namespace}} / {{.Name}}
+{{- end}}
+`
+
+type Templates struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// LoadTemplates parses the default title template and the body template from bodyPath,
+// or the default body template when bodyPath is empty.
+func LoadTemplates(bodyPath string) (*Templates, error) {
+	body := DefaultBodyTemplate
+	if bodyPath != "" {
+		data, err := os.ReadFile(bodyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read issue template: %w", err)
+		}
+		body = string(data)
+	}
+
+	t := &Templates{}
+	var err error
+	if t.title, err = template.New("title").Parse(DefaultTitleTemplate); err != nil {
+		return nil, err
+	}
+	if t.body, err = template.New("body").Parse(body); err != nil {
+		return nil, fmt.Errorf("invalid issue template: %w", err)
+	}
+	return t, nil
+}
+
+func (t *Templates) Render(data TemplateData) (title, body string, err error) {
+	var sb strings.Builder
+	if err := t.title.Execute(&sb, data); err != nil {
+		return "", "", err
+	}
+	title = sb.String()
+	sb.Reset()
+	if err := t.body.Execute(&sb, data); err != nil {
+		return "", "", err
+	}
+	return title, sb.String(), nil
+}
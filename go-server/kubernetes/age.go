@@ -0,0 +1,30 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+)
+
+// HumanizeAge formats an age with its two largest units, e.g. 1d2h, 3h5m, 12m or 40s,
+// leaving out a zero second unit. Negative ages, from clock skew, are 0s.
+func HumanizeAge(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%ds", int(d/time.Second))
+}
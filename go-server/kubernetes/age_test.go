@@ -0,0 +1,26 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanizeAge(t *testing.T) {
+	for _, tc := range []struct {
+		age  time.Duration
+		want string
+	}{
+		{26 * time.Hour, "1d2h"},
+		{72 * time.Hour, "3d"},
+		{400*24*time.Hour + 30*time.Minute, "400d"},
+		{3*time.Hour + 5*time.Minute + 20*time.Second, "3h5m"},
+		{2 * time.Hour, "2h"},
+		{12*time.Minute + 59*time.Second, "12m"},
+		{40 * time.Second, "40s"},
+		{-time.Minute, "0s"},
+	} {
+		if got := HumanizeAge(tc.age); got != tc.want {
+			t.Errorf("HumanizeAge(%s) = %q, want %q", tc.age, got, tc.want)
+		}
+	}
+}
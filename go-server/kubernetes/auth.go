@@ -0,0 +1,195 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"trivy-ui/metrics"
+)
+
+// Authentication methods of a cluster connection, see AuthMethod.
+const (
+	AuthInCluster         = "in-cluster"
+	AuthToken             = "token"
+	AuthClientCertificate = "client-certificate"
+	AuthBasic             = "basic"
+	AuthNone              = "none"
+	// authExecPrefix is followed by the plugin command, e.g. exec:aws
+	authExecPrefix         = "exec:"
+	authProviderPrefix     = "auth-provider:"
+	inClusterTokenFilePath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// AuthMethod names how a config authenticates, e.g. exec:aws for an EKS kubeconfig
+// running aws eks get-token.
+func AuthMethod(cfg *rest.Config) string {
+	switch {
+	case cfg.ExecProvider != nil:
+		return authExecPrefix + filepath.Base(cfg.ExecProvider.Command)
+	case cfg.AuthProvider != nil:
+		return authProviderPrefix + cfg.AuthProvider.Name
+	case cfg.BearerTokenFile == inClusterTokenFilePath:
+		return AuthInCluster
+	case cfg.BearerToken != "" || cfg.BearerTokenFile != "":
+		return AuthToken
+	case cfg.CertData != nil || cfg.CertFile != "":
+		return AuthClientCertificate
+	case cfg.Username != "":
+		return AuthBasic
+	}
+	return AuthNone
+}
+
+// ValidateAuth checks what can be checked of a config's credentials before connecting,
+// so a kubeconfig that cannot work fails when the cluster is added rather than with a
+// transport error on every request. For exec plugins that is the command being found
+// and not requiring a terminal, which a server has none of.
+func ValidateAuth(cfg *rest.Config) error {
+	if cfg.ExecProvider == nil {
+		return nil
+	}
+	command := cfg.ExecProvider.Command
+	if command == "" {
+		return errors.New("exec credential plugin has no command")
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return fmt.Errorf("exec credential plugin %q not found, install it in the trivy-ui image or use a token kubeconfig: %w", command, err)
+	}
+	if cfg.ExecProvider.InteractiveMode == clientcmdapi.AlwaysExecInteractiveMode {
+		return fmt.Errorf("exec credential plugin %q requires an interactive terminal", command)
+	}
+	return nil
+}
+
+// AuthHealth is the state of a cluster connection's credentials as seen by its requests,
+// informer watches included.
+type AuthHealth struct {
+	Method  string `json:"method"`
+	Healthy bool   `json:"healthy"`
+	// LastSuccess is the last request the API server authenticated
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	// LastError is the last authentication failure: a 401 from the API server or an exec
+	// plugin that failed to produce credentials
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	// ConsecutiveFailures counts the failures since the last success
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+}
+
+// authTracker records the outcome of a client's requests. It wraps the transport outside
+// client-go's credential handling, so it sees both plugin failures and the responses.
+type authTracker struct {
+	method  string
+	cluster string
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastError   string
+	lastErrorAt time.Time
+	failures    int
+}
+
+func newAuthTracker(cfg *rest.Config) *authTracker {
+	return &authTracker{method: AuthMethod(cfg)}
+}
+
+func (t *authTracker) wrap(rt http.RoundTripper) http.RoundTripper {
+	return authRoundTripper{tracker: t, next: rt}
+}
+
+type authRoundTripper struct {
+	tracker *authTracker
+	next    http.RoundTripper
+}
+
+func (rt authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		// client-go reports plugin failures as "getting credentials: ..."
+		if isCredentialError(err) {
+			rt.tracker.failure(err.Error())
+		}
+	case resp.StatusCode == http.StatusUnauthorized:
+		rt.tracker.failure("API server rejected the credentials (401 Unauthorized)")
+	default:
+		rt.tracker.success()
+	}
+	return resp, err
+}
+
+func isCredentialError(err error) bool {
+	var execErr *exec.Error
+	var exitErr *exec.ExitError
+	return errors.As(err, &execErr) || errors.As(err, &exitErr) ||
+		strings.Contains(err.Error(), "getting credentials:")
+}
+
+func (t *authTracker) success() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastSuccess = time.Now()
+	t.failures = 0
+}
+
+func (t *authTracker) failure(msg string) {
+	t.mu.Lock()
+	t.lastError = msg
+	t.lastErrorAt = time.Now()
+	t.failures++
+	cluster := t.cluster
+	t.mu.Unlock()
+	if cluster != "" {
+		metrics.ClusterAuthFailures.WithLabelValues(cluster).Inc()
+	}
+}
+
+// setCluster names the cluster in the failure metric once the client is registered.
+func (t *authTracker) setCluster(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cluster = name
+}
+
+func (t *authTracker) health() AuthHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := AuthHealth{
+		Method:              t.method,
+		Healthy:             t.failures == 0,
+		LastError:           t.lastError,
+		ConsecutiveFailures: t.failures,
+	}
+	if !t.lastSuccess.IsZero() {
+		last := t.lastSuccess
+		h.LastSuccess = &last
+	}
+	if !t.lastErrorAt.IsZero() {
+		at := t.lastErrorAt
+		h.LastErrorAt = &at
+	}
+	return h
+}
+
+// AuthHealth returns the state of the client's credentials.
+func (c *Client) AuthHealth() AuthHealth {
+	if c == nil || c.auth == nil {
+		return AuthHealth{Method: AuthNone, Healthy: true}
+	}
+	return c.auth.health()
+}
+
+// isAuthError tells watch errors caused by credentials from those of the connection.
+func isAuthError(err error) bool {
+	return apierrors.IsUnauthorized(err) || isCredentialError(err)
+}
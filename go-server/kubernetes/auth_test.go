@@ -0,0 +1,126 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// execKubeconfig returns a kubeconfig for server authenticating with an exec plugin;
+// client-go only sends credentials over TLS.
+func execKubeconfig(server, command string) []byte {
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: %s
+      interactiveMode: Never
+`, server, command))
+}
+
+// TestExecPluginTokenRefresh checks a 401 makes client-go run the plugin again, as an
+// expired EKS token does, and that the auth health follows.
+func TestExecPluginTokenRefresh(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec plugin is a shell script")
+	}
+	dir := t.TempDir()
+	// the first run hands out an expired token, later runs a fresh one
+	plugin := filepath.Join(dir, "get-token")
+	script := fmt.Sprintf(`#!/bin/sh
+token=fresh
+if [ ! -f %[1]s/ran ]; then token=expired; touch %[1]s/ran; fi
+echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"'$token'"}}'
+`, dir)
+	if err := os.WriteFile(plugin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Unauthorized","code":401}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"NamespaceList","apiVersion":"v1","items":[{"metadata":{"name":"default"}}]}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromKubeconfig(execKubeconfig(server.URL, plugin), DefaultClientConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := client.AuthHealth().Method; got != "exec:get-token" {
+		t.Errorf("method = %q", got)
+	}
+
+	if _, err := client.GetNamespaces(t.Context()); err == nil {
+		t.Fatal("expected the expired token to be rejected")
+	}
+	health := client.AuthHealth()
+	if health.Healthy || health.ConsecutiveFailures != 1 || health.LastErrorAt == nil {
+		t.Fatalf("health after 401 = %+v", health)
+	}
+
+	namespaces, err := client.GetNamespaces(t.Context())
+	if err != nil {
+		t.Fatalf("refreshed token: %v", err)
+	}
+	if len(namespaces) != 1 {
+		t.Errorf("namespaces = %v", namespaces)
+	}
+	health = client.AuthHealth()
+	if !health.Healthy || health.LastSuccess == nil || health.LastError == "" {
+		t.Errorf("health after refresh = %+v", health)
+	}
+}
+
+func TestExecPluginNotFound(t *testing.T) {
+	_, err := NewClientFromKubeconfig(execKubeconfig("https://127.0.0.1:6443", "trivy-ui-no-such-plugin"), DefaultClientConfig())
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestAuthMethod(t *testing.T) {
+	tests := []struct {
+		cfg  rest.Config
+		want string
+	}{
+		{rest.Config{ExecProvider: &clientcmdapi.ExecConfig{Command: "/usr/local/bin/aws"}}, "exec:aws"},
+		{rest.Config{AuthProvider: &clientcmdapi.AuthProviderConfig{Name: "oidc"}}, "auth-provider:oidc"},
+		{rest.Config{BearerTokenFile: inClusterTokenFilePath}, AuthInCluster},
+		{rest.Config{BearerToken: "t"}, AuthToken},
+		{rest.Config{TLSClientConfig: rest.TLSClientConfig{CertData: []byte("c")}}, AuthClientCertificate},
+		{rest.Config{}, AuthNone},
+	}
+	for _, tt := range tests {
+		if got := AuthMethod(&tt.cfg); got != tt.want {
+			t.Errorf("AuthMethod() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,7 +26,12 @@ type Client struct {
 	clientset *kubernetes.Clientset
 	dynamic   dynamic.Interface
 	config    *rest.Config
-	informer  *ReportInformerManager
+	// httpClient is shared by the clientsets, so they share connections and auth tracking
+	httpClient *http.Client
+	auth       *authTracker
+	// connectivity tracks whether the API server answers, see Connectivity
+	connectivity *connectivityTracker
+	informer     *ReportInformerManager
 }
 
 // ClientConfig holds configuration for K8s client
@@ -86,6 +92,21 @@ func NewClientWithConfig(kubeconfig string, clientConfig ClientConfig) (*Client,
 		return nil, err
 	}
 
+	return newClientForRESTConfig(config, clientConfig)
+}
+
+// NewClientFromKubeconfig builds a client from raw kubeconfig bytes, e.g. the contents
+// of a discovered Secret. Unlike NewClientWithConfig it never falls back to the
+// in-cluster config.
+func NewClientFromKubeconfig(data []byte, clientConfig ClientConfig) (*Client, error) {
+	config, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	return newClientForRESTConfig(config, clientConfig)
+}
+
+func newClientForRESTConfig(config *rest.Config, clientConfig ClientConfig) (*Client, error) {
 	// Apply rate limiting settings
 	// Note: We don't set config.Timeout globally as it breaks Watch (long-running connections)
 	// Individual requests should use context.WithTimeout instead
@@ -94,20 +115,38 @@ func NewClientWithConfig(kubeconfig string, clientConfig ClientConfig) (*Client,
 	if clientConfig.Timeout > 0 {
 		config.Timeout = clientConfig.Timeout
 	}
+	if err := ValidateAuth(config); err != nil {
+		return nil, err
+	}
+
+	// client-go runs exec plugins (aws eks get-token, gke-gcloud-auth-plugin) itself,
+	// caching the token until it expires and running the plugin again when the API server
+	// answers 401, which informers rely on to outlive short-lived tokens. The tracker
+	// wraps that handling to tell when refreshing stops working.
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, err
+	}
+	auth := newAuthTracker(config)
+	connectivity := newConnectivityTracker()
+	httpClient := &http.Client{Transport: connectivity.wrap(auth.wrap(transport)), Timeout: config.Timeout}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	clientset, err := kubernetes.NewForConfigAndClient(config, httpClient)
 	if err != nil {
 		return nil, err
 	}
-	dynamicClient, err := dynamic.NewForConfig(config)
+	dynamicClient, err := dynamic.NewForConfigAndClient(config, httpClient)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Client{
-		clientset: clientset,
-		dynamic:   dynamicClient,
-		config:    config,
+		clientset:    clientset,
+		dynamic:      dynamicClient,
+		config:       config,
+		httpClient:   httpClient,
+		auth:         auth,
+		connectivity: connectivity,
 	}, nil
 }
 
@@ -117,13 +156,30 @@ func (c *Client) GetNamespaces(ctx context.Context) ([]string, error) {
 		return nil, err
 	}
 
+	cfg := config.Get()
 	var names []string
 	for _, ns := range namespaces.Items {
+		if cfg.NamespaceExcluded(ns.Name) {
+			continue
+		}
 		names = append(names, ns.Name)
 	}
 	return names, nil
 }
 
+// GetNamespaceLabels returns the labels of every namespace, keyed by namespace name.
+func (c *Client) GetNamespaceLabels(ctx context.Context) (map[string]map[string]string, error) {
+	namespaces, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]map[string]string, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		result[ns.Name] = ns.Labels
+	}
+	return result, nil
+}
+
 func parseAPIVersion(apiVersion string) (group, version string) {
 	group = "aquasecurity.github.io"
 	version = "v1alpha1"
@@ -180,7 +236,12 @@ func (c *Client) ListReports(ctx context.Context, reportType config.ReportKind,
 			return nil, fmt.Errorf("failed to list %s: %w", reportType.Kind, err)
 		}
 
-		allItems = append(allItems, list.Items...)
+		cfg := config.Get()
+		for _, item := range list.Items {
+			if !cfg.NamespaceExcluded(item.GetNamespace()) {
+				allItems = append(allItems, item)
+			}
+		}
 
 		// Check if there are more pages
 		if list.GetContinue() == "" {
@@ -199,6 +260,12 @@ type Report struct {
 	Name      string      `json:"name"`
 	Status    string      `json:"status,omitempty"`
 	Data      interface{} `json:"data"`
+	Findings  []Finding   `json:"-"`
+	// ScannedAt is when the operator produced the report, zero if the CR does not say
+	ScannedAt time.Time `json:"-"`
+	// UID and ResourceVersion are the CR's, empty for reports that are not read from a cluster
+	UID             string `json:"-"`
+	ResourceVersion string `json:"-"`
 }
 
 func (c *Client) GetReportsByType(ctx context.Context, reportType config.ReportKind, namespace string) ([]Report, error) {
@@ -240,17 +307,11 @@ func (c *Client) GetReportsByType(ctx context.Context, reportType config.ReportK
 					scanner = s
 				}
 			}
-			if created, found, _ := unstructured.NestedString(item.Object, "metadata", "creationTimestamp"); found {
-				if t, err := time.Parse(time.RFC3339, created); err == nil {
-					dur := time.Since(t)
-					if dur.Hours() >= 24 {
-						age = fmt.Sprintf("%dh", int(dur.Hours()))
-					} else if dur.Hours() >= 1 {
-						age = fmt.Sprintf("%dh", int(dur.Hours()))
-					} else {
-						age = fmt.Sprintf("%dm", int(dur.Minutes()))
-					}
-				}
+		}
+		if ts, found, _ := unstructured.NestedString(item.Object, "metadata", "creationTimestamp"); found {
+			if t, err := time.Parse(time.RFC3339, ts); err == nil {
+				meta["creationTimestamp"] = ts
+				age = HumanizeAge(time.Since(t))
 			}
 		}
 		dataMap := map[string]interface{}{
@@ -288,30 +349,17 @@ func (c *Client) GetReportDetails(ctx context.Context, reportType config.ReportK
 		return nil, fmt.Errorf("failed to get report from Kubernetes: %v", err)
 	}
 
-	status := "Unknown"
-	if summary, ok := report.Object["report"].(map[string]interface{}); ok {
-		if summaryData, ok := summary["summary"].(map[string]interface{}); ok {
-			if criticalCount, ok := summaryData["criticalCount"].(float64); ok && criticalCount > 0 {
-				status = "Critical"
-			} else if highCount, ok := summaryData["highCount"].(float64); ok && highCount > 0 {
-				status = "High"
-			} else if mediumCount, ok := summaryData["mediumCount"].(float64); ok && mediumCount > 0 {
-				status = "Medium"
-			} else if lowCount, ok := summaryData["lowCount"].(float64); ok && lowCount > 0 {
-				status = "Low"
-			} else if noneCount, ok := summaryData["noneCount"].(float64); ok && noneCount > 0 {
-				status = "None"
-			}
-		}
-	}
-
 	return &Report{
 		Type:      reportType.Name,
 		Cluster:   "",
 		Namespace: namespace,
 		Name:      name,
-		Status:    status,
+		Status:    ExtractSummary(reportType.Name, report.Object).Status,
 		Data:      report.Object,
+		ScannedAt: extractScannedAt(report.Object),
+
+		UID:             string(report.GetUID()),
+		ResourceVersion: report.GetResourceVersion(),
 	}, nil
 }
 
@@ -342,6 +390,9 @@ func (c *Client) StartInformer(clusterName string, cacheUpdater CacheUpdater) er
 	if c.informer != nil {
 		return nil
 	}
+	if c.auth != nil {
+		c.auth.setCluster(clusterName)
+	}
 	c.informer = NewReportInformerManager(c, clusterName, cacheUpdater)
 	return c.informer.Start()
 }
@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Managed Kubernetes platforms and distributions recognised from node labels, provider IDs
+// and the server version.
+const (
+	PlatformEKS       = "EKS"
+	PlatformAKS       = "AKS"
+	PlatformGKE       = "GKE"
+	PlatformOpenShift = "OpenShift"
+	PlatformK3s       = "k3s"
+	PlatformKind      = "kind"
+)
+
+// platformLabels are node labels only the respective platform sets.
+var platformLabels = []struct {
+	label    string
+	platform string
+}{
+	{"eks.amazonaws.com/nodegroup", PlatformEKS},
+	{"eks.amazonaws.com/compute-type", PlatformEKS},
+	{"kubernetes.azure.com/cluster", PlatformAKS},
+	{"cloud.google.com/gke-nodepool", PlatformGKE},
+	{"node.openshift.io/os_id", PlatformOpenShift},
+	{"node.kubernetes.io/instance-type=k3s", PlatformK3s},
+}
+
+// ClusterInfo holds cluster facts beyond the API server address and version.
+type ClusterInfo struct {
+	NodeCount int
+	// Platform is empty when the cluster is not a recognised managed platform
+	Platform string
+}
+
+// DetectPlatform guesses the platform a cluster runs on from its server version and nodes.
+func DetectPlatform(version string, nodes []corev1.Node) string {
+	for _, n := range nodes {
+		for _, pl := range platformLabels {
+			key, value, hasValue := strings.Cut(pl.label, "=")
+			if v, ok := n.Labels[key]; ok && (!hasValue || v == value) {
+				return pl.platform
+			}
+		}
+		switch provider := n.Spec.ProviderID; {
+		case strings.HasPrefix(provider, "kind://"):
+			return PlatformKind
+		case strings.HasPrefix(provider, "k3s://"):
+			return PlatformK3s
+		}
+	}
+	switch {
+	case strings.Contains(version, "-eks-"):
+		return PlatformEKS
+	case strings.Contains(version, "-gke."):
+		return PlatformGKE
+	case strings.Contains(version, "+k3s"):
+		return PlatformK3s
+	}
+	return ""
+}
+
+// GetClusterInfo counts the cluster's nodes and detects its platform.
+func (c *Client) GetClusterInfo(ctx context.Context, version string) (ClusterInfo, error) {
+	nodes, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return ClusterInfo{Platform: DetectPlatform(version, nil)}, err
+	}
+	return ClusterInfo{NodeCount: len(nodes.Items), Platform: DetectPlatform(version, nodes.Items)}, nil
+}
+
+// GetClusterUID returns the UID of the kube-system namespace, which is created with the
+// cluster and never changes, so it identifies a cluster whatever kubeconfig reaches it.
+func (c *Client) GetClusterUID(ctx context.Context) (string, error) {
+	ns, err := c.clientset.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return string(ns.UID), nil
+}
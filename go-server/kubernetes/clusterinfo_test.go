@@ -0,0 +1,36 @@
+package kubernetes
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDetectPlatform(t *testing.T) {
+	node := func(labels map[string]string, providerID string) []corev1.Node {
+		return []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Labels: labels}, Spec: corev1.NodeSpec{ProviderID: providerID}}}
+	}
+	cases := []struct {
+		name    string
+		version string
+		nodes   []corev1.Node
+		want    string
+	}{
+		{"eks label", "v1.30.0", node(map[string]string{"eks.amazonaws.com/nodegroup": "ng"}, ""), PlatformEKS},
+		{"aks label", "v1.30.0", node(map[string]string{"kubernetes.azure.com/cluster": "rg"}, ""), PlatformAKS},
+		{"gke label", "v1.30.0", node(map[string]string{"cloud.google.com/gke-nodepool": "pool"}, ""), PlatformGKE},
+		{"openshift label", "v1.30.0", node(map[string]string{"node.openshift.io/os_id": "rhcos"}, ""), PlatformOpenShift},
+		{"k3s instance type", "v1.30.0", node(map[string]string{"node.kubernetes.io/instance-type": "k3s"}, ""), PlatformK3s},
+		{"other instance type", "v1.30.0", node(map[string]string{"node.kubernetes.io/instance-type": "m5.large"}, ""), ""},
+		{"kind provider", "v1.30.0", node(nil, "kind://docker/kind/kind-control-plane"), PlatformKind},
+		{"eks version without nodes", "v1.30.2-eks-1552ad0", nil, PlatformEKS},
+		{"gke version", "v1.29.4-gke.1043002", node(nil, ""), PlatformGKE},
+		{"unknown", "v1.30.0", node(nil, ""), ""},
+	}
+	for _, c := range cases {
+		if got := DetectPlatform(c.version, c.nodes); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+}
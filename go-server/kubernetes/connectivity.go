@@ -0,0 +1,203 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"trivy-ui/metrics"
+)
+
+// Connectivity states of a cluster's API server.
+const (
+	// ConnectivityUnknown: no request has completed yet
+	ConnectivityUnknown     = "unknown"
+	ConnectivityReachable   = "reachable"
+	ConnectivityUnreachable = "unreachable"
+	// ConnectivityUnauthorized: the API server answers but rejects the credentials
+	ConnectivityUnauthorized = "unauthorized"
+)
+
+const (
+	// ConnectivityWindow is how long connectivity transitions are kept
+	ConnectivityWindow = 24 * time.Hour
+	// connectivityMaxTransitions bounds the history of a flapping connection
+	connectivityMaxTransitions = 500
+	// connectivityFailureThreshold is how many requests in a row must fail before a
+	// cluster counts as unreachable, so one dropped watch does not flip it
+	connectivityFailureThreshold = 3
+)
+
+// ConnectivityTransition is a change of a cluster's connectivity state.
+type ConnectivityTransition struct {
+	State string    `json:"state"`
+	At    time.Time `json:"at"`
+	// Error is the failure that led to an unreachable or unauthorized state
+	Error string `json:"error,omitempty"`
+}
+
+// Connectivity is the state of a cluster connection and how it changed.
+type Connectivity struct {
+	State string    `json:"state"`
+	Since time.Time `json:"since"`
+	// LastSuccess is the last request the API server answered; the cached reports of an
+	// unreachable cluster are as old as it
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	// Transitions are the changes of the last ConnectivityWindow, oldest first
+	Transitions []ConnectivityTransition `json:"transitions"`
+}
+
+// connectivityTracker turns the outcome of a client's requests into connectivity
+// transitions. Failures are dated from the first of a streak, the moment the data
+// started to go stale.
+type connectivityTracker struct {
+	mu          sync.Mutex
+	cluster     string
+	state       string
+	since       time.Time
+	lastSuccess time.Time
+	failures    int
+	streakStart time.Time
+	transitions []ConnectivityTransition
+	onChange    func(ConnectivityTransition)
+	now         func() time.Time
+}
+
+func newConnectivityTracker() *connectivityTracker {
+	t := &connectivityTracker{state: ConnectivityUnknown, now: time.Now}
+	t.since = t.now()
+	return t
+}
+
+func (t *connectivityTracker) wrap(rt http.RoundTripper) http.RoundTripper {
+	return connectivityRoundTripper{tracker: t, next: rt}
+}
+
+type connectivityRoundTripper struct {
+	tracker *connectivityTracker
+	next    http.RoundTripper
+}
+
+func (rt connectivityRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		// requests we cancelled say nothing about the server
+		if errors.Is(err, context.Canceled) {
+			break
+		}
+		if isCredentialError(err) {
+			rt.tracker.observe(ConnectivityUnauthorized, err.Error())
+		} else {
+			rt.tracker.observe(ConnectivityUnreachable, err.Error())
+		}
+	case resp.StatusCode == http.StatusUnauthorized:
+		rt.tracker.observe(ConnectivityUnauthorized, "API server rejected the credentials (401 Unauthorized)")
+	case resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout:
+		// a load balancer in front of an API server that is down
+		rt.tracker.observe(ConnectivityUnreachable, resp.Status)
+	default:
+		rt.tracker.observe(ConnectivityReachable, "")
+	}
+	return resp, err
+}
+
+// observe records the outcome of one request.
+func (t *connectivityTracker) observe(state, errMsg string) {
+	t.mu.Lock()
+	now := t.now()
+	if state == ConnectivityReachable {
+		t.lastSuccess = now
+		t.failures = 0
+	} else {
+		if t.failures == 0 {
+			t.streakStart = now
+		}
+		t.failures++
+		if t.failures < connectivityFailureThreshold && t.state != ConnectivityUnknown {
+			t.mu.Unlock()
+			return
+		}
+	}
+	if state == t.state {
+		t.mu.Unlock()
+		return
+	}
+
+	at := now
+	if state != ConnectivityReachable {
+		at = t.streakStart
+	}
+	transition := ConnectivityTransition{State: state, At: at, Error: errMsg}
+	t.state, t.since = state, at
+	t.transitions = append(t.transitions, transition)
+	t.pruneLocked(now)
+	cluster, onChange := t.cluster, t.onChange
+	t.mu.Unlock()
+
+	setReachableMetric(cluster, state)
+	if onChange != nil {
+		onChange(transition)
+	}
+}
+
+func setReachableMetric(cluster, state string) {
+	if cluster == "" || state == ConnectivityUnknown {
+		return
+	}
+	reachable := 0.0
+	if state == ConnectivityReachable {
+		reachable = 1
+	}
+	metrics.ClusterReachable.WithLabelValues(cluster).Set(reachable)
+}
+
+func (t *connectivityTracker) pruneLocked(now time.Time) {
+	cutoff := now.Add(-ConnectivityWindow)
+	drop := 0
+	for drop < len(t.transitions) && t.transitions[drop].At.Before(cutoff) {
+		drop++
+	}
+	if over := len(t.transitions) - drop - connectivityMaxTransitions; over > 0 {
+		drop += over
+	}
+	if drop > 0 {
+		t.transitions = append([]ConnectivityTransition(nil), t.transitions[drop:]...)
+	}
+}
+
+func (t *connectivityTracker) connectivity() Connectivity {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pruneLocked(t.now())
+	c := Connectivity{State: t.state, Since: t.since, Transitions: append([]ConnectivityTransition{}, t.transitions...)}
+	if !t.lastSuccess.IsZero() {
+		last := t.lastSuccess
+		c.LastSuccess = &last
+	}
+	return c
+}
+
+// Connectivity returns the state of the connection to the cluster's API server and its
+// transitions over the last ConnectivityWindow.
+func (c *Client) Connectivity() Connectivity {
+	if c == nil || c.connectivity == nil {
+		return Connectivity{State: ConnectivityUnknown, Transitions: []ConnectivityTransition{}}
+	}
+	return c.connectivity.connectivity()
+}
+
+// OnConnectivityChange names the client's cluster in the reachability metric and
+// registers fn to be called after each of its connectivity transitions.
+func (c *Client) OnConnectivityChange(cluster string, fn func(ConnectivityTransition)) {
+	if c == nil || c.connectivity == nil {
+		return
+	}
+	c.connectivity.mu.Lock()
+	c.connectivity.cluster, c.connectivity.onChange = cluster, fn
+	state := c.connectivity.state
+	c.connectivity.mu.Unlock()
+	setReachableMetric(cluster, state)
+}
@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestConnectivityTransitions(t *testing.T) {
+	start := time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	now := start
+	tracker := newConnectivityTracker()
+	tracker.now = func() time.Time { return now }
+	var changes []ConnectivityTransition
+	tracker.onChange = func(tr ConnectivityTransition) { changes = append(changes, tr) }
+
+	var status int
+	var fail error
+	client := &http.Client{Transport: tracker.wrap(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		if fail != nil {
+			return nil, fail
+		}
+		return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: http.NoBody}, nil
+	}))}
+	call := func() {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "https://api.test/version", nil)
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+		}
+		now = now.Add(time.Minute)
+	}
+
+	status = http.StatusOK
+	call()
+	if c := tracker.connectivity(); c.State != ConnectivityReachable || c.LastSuccess == nil || !c.LastSuccess.Equal(start) {
+		t.Fatalf("got %+v", c)
+	}
+
+	// the outage is dated from its first failed request, once enough failed in a row
+	fail = errors.New("dial tcp 10.0.0.1:443: connect: connection refused")
+	call()
+	call()
+	if c := tracker.connectivity(); c.State != ConnectivityReachable {
+		t.Fatalf("unreachable after two failures: %+v", c)
+	}
+	call()
+	c := tracker.connectivity()
+	if c.State != ConnectivityUnreachable || !c.Since.Equal(start.Add(time.Minute)) || !c.LastSuccess.Equal(start) {
+		t.Fatalf("got %+v", c)
+	}
+
+	// cancelled requests do not count either way
+	fail = context.Canceled
+	call()
+	fail = nil
+	status = http.StatusServiceUnavailable
+	call()
+	if c := tracker.connectivity(); c.State != ConnectivityUnreachable || len(c.Transitions) != 2 {
+		t.Fatalf("got %+v", c)
+	}
+
+	status = http.StatusOK
+	call()
+	status = http.StatusUnauthorized
+	for range connectivityFailureThreshold {
+		call()
+	}
+	if len(changes) != 4 || changes[2].State != ConnectivityReachable || changes[3].State != ConnectivityUnauthorized || changes[3].Error == "" {
+		t.Fatalf("got %+v", changes)
+	}
+
+	// transitions older than a day are dropped
+	now = changes[3].At.Add(ConnectivityWindow - time.Second)
+	if c := tracker.connectivity(); len(c.Transitions) != 1 || c.State != ConnectivityUnauthorized {
+		t.Errorf("got %+v", c.Transitions)
+	}
+}
+
+func TestConnectivityWithoutTracker(t *testing.T) {
+	var c *Client
+	if got := c.Connectivity(); got.State != ConnectivityUnknown || got.Transitions == nil {
+		t.Errorf("got %+v", got)
+	}
+}
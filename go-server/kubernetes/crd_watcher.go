@@ -0,0 +1,124 @@
+package kubernetes
+
+import (
+	"sync"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
+	"k8s.io/client-go/tools/cache"
+
+	"trivy-ui/config"
+	"trivy-ui/utils"
+)
+
+var (
+	watchingMu sync.Mutex
+	// watching holds the managers with a running CRD watcher; the global registry only
+	// drops a kind when no cluster serves it anymore
+	watching = make(map[*ReportInformerManager]bool)
+)
+
+// apiextensions builds a CRD clientset on the client's transport, so its requests count
+// in the auth health like the others.
+func (c *Client) apiextensions() (*apiextensionsclientset.Clientset, error) {
+	if c.httpClient == nil {
+		return apiextensionsclientset.NewForConfig(c.config)
+	}
+	return apiextensionsclientset.NewForConfigAndClient(c.config, c.httpClient)
+}
+
+// watchCRDs keeps report informers in line with the Trivy CRDs installed in the cluster,
+// so kinds installed after startup (e.g. sbomreports, or the operator itself) are watched
+// without a restart and deleted kinds are dropped.
+func (m *ReportInformerManager) watchCRDs() {
+	watchingMu.Lock()
+	if watching[m] {
+		watchingMu.Unlock()
+		return
+	}
+	watching[m] = true
+	watchingMu.Unlock()
+
+	clientset, err := m.client.apiextensions()
+	if err != nil {
+		utils.LogWarning("Failed to create CRD watcher, new report kinds need a restart", map[string]interface{}{
+			"cluster": m.clusterName,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	factory := apiextensionsinformers.NewSharedInformerFactory(clientset, informerResyncPeriod)
+	informer := factory.Apiextensions().V1().CustomResourceDefinitions().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.onCRDChange(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			m.onCRDChange(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+			if !ok {
+				return
+			}
+			if kind, ok := config.ReportKindFromCRD(crd); ok {
+				m.RemoveReportKind(kind)
+				if !kindWatchedByAnyCluster(kind.Name) {
+					config.GetGlobalRegistry().Unregister(kind.Name)
+				}
+			}
+		},
+	})
+	factory.Start(m.ctx.Done())
+
+	go func() {
+		<-m.ctx.Done()
+		watchingMu.Lock()
+		delete(watching, m)
+		watchingMu.Unlock()
+	}()
+}
+
+// onCRDChange starts watching a Trivy CRD once the API server serves it.
+func (m *ReportInformerManager) onCRDChange(obj interface{}) {
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok || !crdEstablished(crd) {
+		return
+	}
+	kind, ok := config.ReportKindFromCRD(crd)
+	if !ok {
+		return
+	}
+	config.GetGlobalRegistry().Register(kind)
+	m.AddReportKind(kind)
+}
+
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1.Established {
+			return cond.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func kindWatchedByAnyCluster(name string) bool {
+	watchingMu.Lock()
+	managers := make([]*ReportInformerManager, 0, len(watching))
+	for m := range watching {
+		managers = append(managers, m)
+	}
+	watchingMu.Unlock()
+
+	for _, m := range managers {
+		if m.GetInformer(name) != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package kubernetes
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// eventRateWindow is the trailing window LastMinute counts events over, in one-second
+// buckets.
+const eventRateWindow = 60
+
+// EventCounts counts the informer events of one report kind.
+type EventCounts struct {
+	Adds    int64 `json:"adds"`
+	Updates int64 `json:"updates"`
+	Deletes int64 `json:"deletes"`
+	// NoOpUpdates are updates that did not change the report's resourceVersion, i.e. the
+	// informer's periodic resync; they are included in Updates
+	NoOpUpdates int64 `json:"noOpUpdates"`
+	// Dropped events were not applied: events of excluded namespaces, which are not counted
+	// as an operation, and events still waiting for a worker when the informer stopped
+	Dropped int64 `json:"dropped"`
+}
+
+func (c *EventCounts) add(o EventCounts) {
+	c.Adds += o.Adds
+	c.Updates += o.Updates
+	c.Deletes += o.Deletes
+	c.NoOpUpdates += o.NoOpUpdates
+	c.Dropped += o.Dropped
+}
+
+// KindEventStats are the informer events received for one report kind since the informer
+// started, and over the last minute.
+type KindEventStats struct {
+	Kind string `json:"kind"`
+	// Watching is false for kinds whose informer was stopped, e.g. when their CRD was removed
+	Watching   bool        `json:"watching"`
+	Total      EventCounts `json:"total"`
+	LastMinute EventCounts `json:"lastMinute"`
+	// the last event of any kind and of each operation; nil when none arrived
+	LastEventAt  *time.Time `json:"lastEventAt,omitempty"`
+	LastAddAt    *time.Time `json:"lastAddAt,omitempty"`
+	LastUpdateAt *time.Time `json:"lastUpdateAt,omitempty"`
+	LastDeleteAt *time.Time `json:"lastDeleteAt,omitempty"`
+}
+
+type eventBucket struct {
+	second int64
+	counts EventCounts
+}
+
+type kindEvents struct {
+	total     EventCounts
+	buckets   [eventRateWindow]eventBucket
+	lastEvent time.Time
+	lastOp    [3]time.Time
+}
+
+// eventStats counts the events the informers of a cluster deliver, so a kind that stopped
+// receiving events can be told apart from events that arrive but are not applied.
+type eventStats struct {
+	mu    sync.Mutex
+	kinds map[string]*kindEvents
+}
+
+// record counts an event under its operation or, when dropped, as dropped.
+func (s *eventStats) record(kind string, op eventOp, obj, oldObj interface{}, dropped bool, now time.Time) {
+	var c EventCounts
+	switch {
+	case dropped:
+		c.Dropped = 1
+	case op == eventAdd:
+		c.Adds = 1
+	case op == eventUpdate:
+		c.Updates = 1
+		if sameResourceVersion(oldObj, obj) {
+			c.NoOpUpdates = 1
+		}
+	default:
+		c.Deletes = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.kinds == nil {
+		s.kinds = make(map[string]*kindEvents)
+	}
+	k := s.kinds[kind]
+	if k == nil {
+		k = &kindEvents{}
+		s.kinds[kind] = k
+	}
+	k.total.add(c)
+	second := now.Unix()
+	b := &k.buckets[second%eventRateWindow]
+	if b.second != second {
+		*b = eventBucket{second: second}
+	}
+	b.counts.add(c)
+	if !dropped {
+		k.lastEvent = now
+		k.lastOp[op] = now
+	}
+}
+
+func sameResourceVersion(oldObj, newObj interface{}) bool {
+	o, ok1 := oldObj.(*unstructured.Unstructured)
+	n, ok2 := newObj.(*unstructured.Unstructured)
+	return ok1 && ok2 && o.GetResourceVersion() != "" && o.GetResourceVersion() == n.GetResourceVersion()
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// snapshot lists the kinds that received events and the watched ones that did not.
+func (s *eventStats) snapshot(watched map[string]bool, now time.Time) []KindEventStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]KindEventStats, 0, len(s.kinds))
+	for kind := range watched {
+		if s.kinds[kind] == nil {
+			result = append(result, KindEventStats{Kind: kind, Watching: true})
+		}
+	}
+	since := now.Unix() - eventRateWindow
+	for kind, k := range s.kinds {
+		ks := KindEventStats{
+			Kind:         kind,
+			Watching:     watched[kind],
+			Total:        k.total,
+			LastEventAt:  optionalTime(k.lastEvent),
+			LastAddAt:    optionalTime(k.lastOp[eventAdd]),
+			LastUpdateAt: optionalTime(k.lastOp[eventUpdate]),
+			LastDeleteAt: optionalTime(k.lastOp[eventDelete]),
+		}
+		for _, b := range k.buckets {
+			if b.second > since {
+				ks.LastMinute.add(b.counts)
+			}
+		}
+		result = append(result, ks)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Kind < result[j].Kind })
+	return result
+}
+
+// EventStats reports the informer events received per report kind. Like SyncProgress it
+// does not wait for Start.
+func (m *ReportInformerManager) EventStats() []KindEventStats {
+	m.progressMu.Lock()
+	watched := make(map[string]bool, len(m.progress))
+	for kind := range m.progress {
+		watched[kind] = true
+	}
+	m.progressMu.Unlock()
+	return m.stats.snapshot(watched, time.Now())
+}
+
+// receive records an informer event and reports whether to apply it, dropping events of
+// excluded namespaces. The startup replay of listed reports does not go through it, so
+// listed reports are counted once, as the informer's adds.
+func (m *ReportInformerManager) receive(kind string, op eventOp, obj, oldObj interface{}) bool {
+	included := includedNamespace(obj)
+	m.stats.record(kind, op, obj, oldObj, !included, time.Now())
+	return included
+}
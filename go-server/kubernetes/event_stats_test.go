@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventStats(t *testing.T) {
+	var s eventStats
+	now := time.Now()
+	old := vulnReport("ns", "a", 1)
+	old.SetResourceVersion("1")
+	resynced := old.DeepCopy()
+	changed := old.DeepCopy()
+	changed.SetResourceVersion("2")
+
+	s.record("vulnerabilityreports", eventAdd, old, nil, false, now.Add(-2*time.Minute))
+	s.record("vulnerabilityreports", eventUpdate, resynced, old, false, now)
+	s.record("vulnerabilityreports", eventUpdate, changed, old, false, now)
+	s.record("vulnerabilityreports", eventDelete, changed, nil, true, now)
+
+	stats := s.snapshot(map[string]bool{"vulnerabilityreports": true, "configauditreports": true}, now)
+	if len(stats) != 2 || stats[0].Kind != "configauditreports" || !stats[0].Watching || stats[0].LastEventAt != nil {
+		t.Fatalf("stats = %+v", stats)
+	}
+	vuln := stats[1]
+	want := EventCounts{Adds: 1, Updates: 2, NoOpUpdates: 1, Dropped: 1}
+	if vuln.Total != want {
+		t.Errorf("total = %+v", vuln.Total)
+	}
+	// the add is older than a minute
+	if vuln.LastMinute != (EventCounts{Updates: 2, NoOpUpdates: 1, Dropped: 1}) {
+		t.Errorf("last minute = %+v", vuln.LastMinute)
+	}
+	if vuln.LastDeleteAt != nil || !vuln.LastUpdateAt.Equal(now) || !vuln.LastAddAt.Equal(now.Add(-2*time.Minute)) {
+		t.Errorf("last events = %+v", vuln)
+	}
+
+	// kinds no longer watched keep their counts
+	if stats := s.snapshot(nil, now); len(stats) != 1 || stats[0].Watching {
+		t.Errorf("unwatched stats = %+v", stats)
+	}
+}
@@ -0,0 +1,186 @@
+package kubernetes
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/tools/cache"
+
+	"trivy-ui/config"
+	"trivy-ui/metrics"
+	"trivy-ui/utils"
+)
+
+// maxEventBatch bounds the reports a worker writes to the cache at once.
+const maxEventBatch = 128
+
+type eventOp int
+
+const (
+	eventAdd eventOp = iota
+	eventUpdate
+	eventDelete
+)
+
+func (op eventOp) String() string {
+	switch op {
+	case eventAdd:
+		return "add"
+	case eventUpdate:
+		return "update"
+	}
+	return "delete"
+}
+
+type reportEvent struct {
+	op     eventOp
+	kind   config.ReportKind
+	obj    interface{}
+	oldObj interface{}
+	// done is called once the event is applied
+	done func()
+}
+
+// eventWorkers apply informer events on a fixed pool of goroutines, so a burst like the
+// initial list of a large cluster is not applied one report at a time on the informer's
+// goroutine. Events are sharded by object, which keeps the events of one report in
+// order. A worker takes whatever has queued up, up to maxEventBatch, and writes the adds
+// and updates as one batch. When a worker's queue is full the informer delivering to it
+// waits, which is counted in the informer queue metrics.
+type eventWorkers struct {
+	m      *ReportInformerManager
+	queues []chan reportEvent
+	depth  prometheus.Gauge
+}
+
+// startEventWorkers starts n workers with room for queueSize events each; it returns nil
+// when n is not positive, leaving events to be applied synchronously.
+func startEventWorkers(m *ReportInformerManager, n, queueSize int) *eventWorkers {
+	if n <= 0 {
+		return nil
+	}
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+	w := &eventWorkers{
+		m:      m,
+		queues: make([]chan reportEvent, n),
+		depth:  metrics.InformerQueueDepth.WithLabelValues(m.clusterName),
+	}
+	for i := range w.queues {
+		w.queues[i] = make(chan reportEvent, queueSize)
+		go w.run(w.queues[i])
+	}
+	return w
+}
+
+// dispatch hands an event to the workers, or applies it right away without them.
+func (m *ReportInformerManager) dispatch(e reportEvent) {
+	key, _ := cache.DeletionHandlingMetaNamespaceKeyFunc(e.obj)
+	utils.LogModuleDebug(utils.ModuleInformer, "Informer event", map[string]interface{}{
+		"cluster":    m.clusterName,
+		"reportType": e.kind.Name,
+		"op":         e.op.String(),
+		"key":        key,
+	})
+	if m.workers == nil {
+		m.applyEvents([]reportEvent{e})
+		return
+	}
+	m.workers.submit(e)
+}
+
+func (w *eventWorkers) submit(e reportEvent) {
+	queue := w.queues[w.shard(e.obj)]
+	w.depth.Inc()
+	select {
+	case queue <- e:
+		return
+	default:
+	}
+
+	cluster := w.m.clusterName
+	metrics.InformerQueueFull.WithLabelValues(cluster).Inc()
+	start := time.Now()
+	select {
+	case queue <- e:
+	case <-w.m.ctx.Done():
+		w.depth.Dec()
+		w.m.stats.record(e.kind.Name, e.op, e.obj, e.oldObj, true, time.Now())
+		if e.done != nil {
+			e.done()
+		}
+	}
+	metrics.InformerQueueWait.WithLabelValues(cluster).Observe(time.Since(start).Seconds())
+}
+
+// shard picks the queue of an object by its namespace and name.
+func (w *eventWorkers) shard(obj interface{}) int {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(w.queues)))
+}
+
+func (w *eventWorkers) run(queue chan reportEvent) {
+	events := make([]reportEvent, 0, maxEventBatch)
+	for {
+		select {
+		case <-w.m.ctx.Done():
+			return
+		case e := <-queue:
+			events = append(events[:0], e)
+		}
+	drain:
+		for len(events) < maxEventBatch {
+			select {
+			case e := <-queue:
+				events = append(events, e)
+			default:
+				break drain
+			}
+		}
+		w.depth.Sub(float64(len(events)))
+		w.m.applyEvents(events)
+	}
+}
+
+// applyEvents applies events in order. Consecutive adds and updates are written as one
+// batch; a delete first flushes the batch before it.
+func (m *ReportInformerManager) applyEvents(events []reportEvent) {
+	changes := make([]reportChange, 0, len(events))
+	var done []func()
+	flush := func() {
+		if len(changes) > 1 {
+			metrics.InformerBatchSize.Observe(float64(len(changes)))
+		}
+		m.applyChanges(changes)
+		for _, d := range done {
+			d()
+		}
+		changes, done = changes[:0], done[:0]
+	}
+	for _, e := range events {
+		switch e.op {
+		case eventAdd:
+			if change, ok := m.addChange(e.kind, e.obj); ok {
+				changes = append(changes, change)
+			}
+		case eventUpdate:
+			if change, ok := m.updateChange(e.kind, e.oldObj, e.obj); ok {
+				changes = append(changes, change)
+			}
+		case eventDelete:
+			flush()
+			m.onDelete(e.kind, e.obj)
+		}
+		if e.done != nil {
+			done = append(done, e.done)
+		}
+	}
+	flush()
+}
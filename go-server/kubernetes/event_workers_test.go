@@ -0,0 +1,150 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"trivy-ui/metrics"
+)
+
+// batchRecorder records cache writes from concurrent workers; while hold is set,
+// SetReport signals entered and waits for gate to close.
+type batchRecorder struct {
+	mu      sync.Mutex
+	events  map[string][]string
+	batches []int
+	hold    atomic.Bool
+	gate    chan struct{}
+	entered chan struct{}
+}
+
+func newBatchRecorder() *batchRecorder {
+	return &batchRecorder{
+		events:  make(map[string][]string),
+		gate:    make(chan struct{}),
+		entered: make(chan struct{}, 1),
+	}
+}
+
+func (r *batchRecorder) record(name, op string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[name] = append(r.events[name], op)
+}
+
+func (r *batchRecorder) SetReport(cluster, namespace, reportType, name string, report *Report) {
+	if r.hold.CompareAndSwap(true, false) {
+		r.entered <- struct{}{}
+		<-r.gate
+	}
+	r.record(name, "set")
+}
+
+func (r *batchRecorder) SetReports(cluster string, reports []*Report) {
+	r.mu.Lock()
+	r.batches = append(r.batches, len(reports))
+	r.mu.Unlock()
+	for _, report := range reports {
+		r.record(report.Name, "set")
+	}
+}
+
+func (r *batchRecorder) DeleteReport(cluster, namespace, reportType, name string) {
+	r.record(name, "delete")
+}
+
+func (r *batchRecorder) DeleteNamespace(cluster, namespace string) {}
+
+func (r *batchRecorder) InvalidateReportDetail(cluster, namespace, reportType, name string) {}
+
+func (r *batchRecorder) UpdateSyncState(clusterName string, state string) {}
+
+func newWorkerManager(t *testing.T, cluster string, updater CacheUpdater, workers, queueSize int) *ReportInformerManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	m := &ReportInformerManager{clusterName: cluster, cacheUpdater: updater, ctx: ctx, cancel: cancel}
+	m.workers = startEventWorkers(m, workers, queueSize)
+	return m
+}
+
+func TestEventWorkersKeepOrderPerReport(t *testing.T) {
+	rec := newBatchRecorder()
+	m := newWorkerManager(t, "order", rec, 3, 8)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		name := fmt.Sprintf("replicaset-%d", i)
+		v1, v2 := vulnReport("web", name, 0), vulnReport("web", name, 1)
+		for _, e := range []reportEvent{
+			{op: eventAdd, kind: vulnKind, obj: v1},
+			{op: eventUpdate, kind: vulnKind, obj: v2, oldObj: v1},
+			{op: eventDelete, kind: vulnKind, obj: v2},
+		} {
+			wg.Add(1)
+			e.done = wg.Done
+			m.dispatch(e)
+		}
+	}
+	wg.Wait()
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for name, ops := range rec.events {
+		if got := fmt.Sprint(ops); got != "[set set delete]" {
+			t.Fatalf("%s applied as %s", name, got)
+		}
+	}
+	if len(rec.events) != 30 {
+		t.Fatalf("applied %d reports, want 30", len(rec.events))
+	}
+}
+
+func TestEventWorkersBatchUnderBackpressure(t *testing.T) {
+	rec := newBatchRecorder()
+	rec.hold.Store(true)
+	m := newWorkerManager(t, "backpressure", rec, 1, 2)
+	full := metrics.InformerQueueFull.WithLabelValues("backpressure")
+
+	var wg sync.WaitGroup
+	add := func(name string) {
+		wg.Add(1)
+		m.dispatch(reportEvent{op: eventAdd, kind: vulnKind, obj: vulnReport("web", name, 1), done: wg.Done})
+	}
+	add("first")
+	<-rec.entered // the worker is busy with the first report
+	add("second")
+	add("third")
+	blocked := make(chan struct{})
+	go func() {
+		add("fourth")
+		close(blocked)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.ToFloat64(full) < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("a full queue did not make the informer wait")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case <-blocked:
+		t.Fatal("dispatch to a full queue returned before the worker made room")
+	default:
+	}
+
+	close(rec.gate)
+	wg.Wait()
+	<-blocked
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.events) != 4 || len(rec.batches) == 0 || rec.batches[0] < 2 {
+		t.Fatalf("events %v, batches %v", rec.events, rec.batches)
+	}
+}
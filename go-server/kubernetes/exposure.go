@@ -0,0 +1,62 @@
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Exposure is the part of a workload's security context that makes a vulnerability in it
+// more dangerous: a compromised container escapes more easily or sees the node's network.
+type Exposure struct {
+	Privileged bool `json:"privileged"`
+	// RunAsRoot is set unless the pod enforces a non-root user
+	RunAsRoot   bool `json:"runAsRoot"`
+	HostNetwork bool `json:"hostNetwork"`
+	HostPID     bool `json:"hostPID"`
+	HostIPC     bool `json:"hostIPC"`
+}
+
+// Exposed reports whether any risky setting is present.
+func (e Exposure) Exposed() bool {
+	return e.Privileged || e.RunAsRoot || e.HostNetwork || e.HostPID || e.HostIPC
+}
+
+// WorkloadKey identifies a workload the way trivy-operator labels its reports.
+func WorkloadKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// PodSpecExposure reads the exposure of a pod spec; a container counts when any of its
+// containers (init containers included) is privileged or may run as root.
+func PodSpecExposure(spec corev1.PodSpec) Exposure {
+	e := Exposure{HostNetwork: spec.HostNetwork, HostPID: spec.HostPID, HostIPC: spec.HostIPC}
+	var podUser *int64
+	var podNonRoot *bool
+	if sc := spec.SecurityContext; sc != nil {
+		podUser, podNonRoot = sc.RunAsUser, sc.RunAsNonRoot
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		user, nonRoot := podUser, podNonRoot
+		if sc := c.SecurityContext; sc != nil {
+			if sc.Privileged != nil && *sc.Privileged {
+				e.Privileged = true
+			}
+			if sc.RunAsUser != nil {
+				user = sc.RunAsUser
+			}
+			if sc.RunAsNonRoot != nil {
+				nonRoot = sc.RunAsNonRoot
+			}
+		}
+		switch {
+		case user != nil:
+			if *user == 0 {
+				e.RunAsRoot = true
+			}
+		case nonRoot == nil || !*nonRoot:
+			// without a user the image decides, and most images default to root
+			e.RunAsRoot = true
+		}
+	}
+	return e
+}
@@ -0,0 +1,48 @@
+package kubernetes
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodSpecExposure(t *testing.T) {
+	yes, no := true, false
+	root, user := int64(0), int64(1000)
+
+	e := PodSpecExposure(corev1.PodSpec{
+		HostNetwork:     true,
+		SecurityContext: &corev1.PodSecurityContext{RunAsNonRoot: &yes},
+		Containers: []corev1.Container{
+			{Name: "app"},
+			{Name: "agent", SecurityContext: &corev1.SecurityContext{Privileged: &yes}},
+		},
+	})
+	if !e.Privileged || !e.HostNetwork || e.RunAsRoot || e.HostPID {
+		t.Fatalf("unexpected exposure %+v", e)
+	}
+
+	cases := []struct {
+		name string
+		spec corev1.PodSpec
+		root bool
+	}{
+		{"no user set", corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}, true},
+		{"pod user", corev1.PodSpec{SecurityContext: &corev1.PodSecurityContext{RunAsUser: &user}, Containers: []corev1.Container{{Name: "app"}}}, false},
+		{"container overrides pod", corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{RunAsUser: &user},
+			Containers:      []corev1.Container{{Name: "app", SecurityContext: &corev1.SecurityContext{RunAsUser: &root}}},
+		}, true},
+		{"nonRoot disabled", corev1.PodSpec{SecurityContext: &corev1.PodSecurityContext{RunAsNonRoot: &no}, Containers: []corev1.Container{{Name: "app"}}}, true},
+		{"init container as root", corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{RunAsUser: &user},
+			InitContainers:  []corev1.Container{{Name: "init", SecurityContext: &corev1.SecurityContext{RunAsUser: &root}}},
+			Containers:      []corev1.Container{{Name: "app"}},
+		}, true},
+	}
+	for _, c := range cases {
+		if got := PodSpecExposure(c.spec); got.RunAsRoot != c.root || got.Exposed() != c.root {
+			t.Errorf("%s: expected runAsRoot=%v, got %+v", c.name, c.root, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"trivy-ui/config"
+)
+
+// LoadReportFiles reads Trivy Operator reports exported with kubectl get -o json or
+// -o yaml, as single objects, lists or multi-document YAML, from the .json, .yaml and
+// .yml files in dir. It returns them as a MemorySource with the report kinds found;
+// objects of other API groups are skipped.
+func LoadReportFiles(dir string) (*MemorySource, []config.ReportKind, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	src := NewMemorySource()
+	kinds := make(map[string]config.ReportKind)
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		objects, err := readReportFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, obj := range objects {
+			kind, ok := reportKindOf(obj)
+			if !ok {
+				continue
+			}
+			kinds[kind.Name] = kind
+			src.Put(kind, obj)
+		}
+	}
+
+	result := make([]config.ReportKind, 0, len(kinds))
+	for _, k := range kinds {
+		result = append(result, k)
+	}
+	return src, result, nil
+}
+
+func readReportFile(path string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objects []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: doc}
+		if !u.IsList() {
+			objects = append(objects, u)
+			continue
+		}
+		if err := u.EachListItem(func(item runtime.Object) error {
+			if obj, ok := item.(*unstructured.Unstructured); ok {
+				objects = append(objects, obj)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// reportKindOf derives the report kind of a Trivy Operator object; the resource name is
+// the lower-cased plural of its kind, as in the operator's CRDs.
+func reportKindOf(obj *unstructured.Unstructured) (config.ReportKind, bool) {
+	apiVersion, kind := obj.GetAPIVersion(), obj.GetKind()
+	if !strings.HasPrefix(apiVersion, config.TrivyGroup+"/") || kind == "" || obj.GetName() == "" {
+		return config.ReportKind{}, false
+	}
+	return config.ReportKind{
+		Name:       strings.ToLower(kind) + "s",
+		ShortName:  strings.ToLower(kind),
+		APIVersion: apiVersion,
+		Namespaced: obj.GetNamespace() != "",
+		Kind:       kind,
+	}, true
+}
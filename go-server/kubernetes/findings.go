@@ -0,0 +1,157 @@
+package kubernetes
+
+import "sort"
+
+// Finding is the compact form of a single vulnerability kept in the informer store.
+type Finding struct {
+	VulnerabilityID  string  `json:"vulnerabilityID"`
+	Severity         string  `json:"severity"`
+	Resource         string  `json:"resource"`
+	InstalledVersion string  `json:"installedVersion"`
+	FixedVersion     string  `json:"fixedVersion,omitempty"`
+	Score            float64 `json:"score,omitempty"`
+	Target           string  `json:"target,omitempty"`
+	CVSSVector       string  `json:"cvssVector,omitempty"`
+}
+
+var findingStringFields = []string{"vulnerabilityID", "severity", "resource", "installedVersion", "fixedVersion", "target"}
+
+// CVSSVector returns the CVSS v3 vector of a Trivy vulnerability entry, preferring NVD
+// over vendor sources, or "" if none is present.
+func CVSSVector(vuln map[string]interface{}) string {
+	if v, ok := vuln["cvssVector"].(string); ok {
+		return v
+	}
+	sources, ok := vuln["cvss"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	vectorOf := func(source interface{}) string {
+		m, _ := source.(map[string]interface{})
+		v, _ := m["V3Vector"].(string)
+		return v
+	}
+	if v := vectorOf(sources["nvd"]); v != "" {
+		return v
+	}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if v := vectorOf(sources[name]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// compactFindings reduces report.vulnerabilities to the fields needed for finding-level tracking.
+func compactFindings(vulns []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(vulns))
+	for _, v := range vulns {
+		vm, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := vm["vulnerabilityID"].(string)
+		if id == "" {
+			continue
+		}
+		compact := make(map[string]interface{}, len(findingStringFields)+1)
+		for _, f := range findingStringFields {
+			if s, ok := vm[f].(string); ok && s != "" {
+				compact[f] = s
+			}
+		}
+		if score, ok := vm["score"].(float64); ok {
+			compact["score"] = score
+		}
+		if vector := CVSSVector(vm); vector != "" {
+			compact["cvssVector"] = vector
+		}
+		result = append(result, compact)
+	}
+	return result
+}
+
+// ExtractFindings reads the compact index written by stripLargeFields, falling back to
+// the full vulnerabilities array for objects that bypassed the transform. It returns nil
+// for report kinds without vulnerabilities and an empty slice for clean vulnerability reports.
+func ExtractFindings(obj map[string]interface{}) []Finding {
+	reportObj, ok := obj["report"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	items, ok := reportObj["findings"].([]interface{})
+	if !ok {
+		vulns, ok := reportObj["vulnerabilities"].([]interface{})
+		if !ok {
+			return nil
+		}
+		items = compactFindings(vulns)
+	}
+
+	findings := make([]Finding, 0, len(items))
+	for _, item := range items {
+		vm, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		f := Finding{}
+		f.VulnerabilityID, _ = vm["vulnerabilityID"].(string)
+		f.Severity, _ = vm["severity"].(string)
+		f.Resource, _ = vm["resource"].(string)
+		f.InstalledVersion, _ = vm["installedVersion"].(string)
+		f.FixedVersion, _ = vm["fixedVersion"].(string)
+		f.Target, _ = vm["target"].(string)
+		f.Score, _ = vm["score"].(float64)
+		f.CVSSVector, _ = vm["cvssVector"].(string)
+		if f.VulnerabilityID != "" {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// compactFailedChecks reduces report.checks to the IDs of the checks that failed.
+func compactFailedChecks(checks []interface{}) []interface{} {
+	result := make([]interface{}, 0)
+	for _, c := range checks {
+		cm, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := cm["checkID"].(string)
+		if success, _ := cm["success"].(bool); id != "" && !success {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// FailedCheckIDs reads the failed check IDs of a config audit report object from the
+// compact index written by stripLargeFields, or from the full checks. It returns nil for
+// report kinds without checks.
+func FailedCheckIDs(obj map[string]interface{}) []string {
+	reportObj, ok := obj["report"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	items, ok := reportObj["failedChecks"].([]interface{})
+	if !ok {
+		checks, ok := reportObj["checks"].([]interface{})
+		if !ok {
+			return nil
+		}
+		items = compactFailedChecks(checks)
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if id, ok := item.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -13,19 +14,28 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	"trivy-ui/config"
+	"trivy-ui/metrics"
 	"trivy-ui/utils"
 )
 
+// CacheUpdater is how informers (and push agents) write into the api package cache
+// without importing it.
 type CacheUpdater interface {
 	SetReport(cluster, namespace, reportType, name string, report *Report)
 	DeleteReport(cluster, namespace, reportType, name string)
+	// DeleteNamespace removes every report of a deleted namespace
+	DeleteNamespace(cluster, namespace string)
 	InvalidateReportDetail(cluster, namespace, reportType, name string)
-	IncrementCount(cluster, namespace, reportType string, hasVuln bool)
-	DecrementCount(cluster, namespace, reportType string, hasVuln bool)
-	AdjustVulnCount(cluster, namespace, reportType string, delta int)
 	UpdateSyncState(clusterName string, state string)
 }
 
+// BatchCacheUpdater is a CacheUpdater that can write several reports at once, taking the
+// cache lock once per batch instead of once per report.
+type BatchCacheUpdater interface {
+	CacheUpdater
+	SetReports(cluster string, reports []*Report)
+}
+
 type ReportInformerManager struct {
 	mu           sync.RWMutex
 	client       *Client
@@ -34,26 +44,53 @@ type ReportInformerManager struct {
 	ctx          context.Context
 	cancel       context.CancelFunc
 	cacheUpdater CacheUpdater
+	stops        map[string]context.CancelFunc
+	// workers apply informer events; nil applies them on the informer's goroutine
+	workers *eventWorkers
+	// namespaceWatch starts the namespace informer once
+	namespaceWatch sync.Once
+
+	// progress is kept under its own lock: Start holds mu for the whole initial sync
+	progressMu sync.Mutex
+	progress   map[string]*kindProgress
+	// stats counts the events the informers deliver
+	stats eventStats
 }
 
 func NewReportInformerManager(client *Client, clusterName string, cacheUpdater CacheUpdater) *ReportInformerManager {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &ReportInformerManager{
+	m := &ReportInformerManager{
 		client:       client,
 		informers:    make(map[string]cache.SharedInformer),
 		clusterName:  clusterName,
 		ctx:          ctx,
 		cancel:       cancel,
 		cacheUpdater: cacheUpdater,
+		stops:        make(map[string]context.CancelFunc),
+		progress:     make(map[string]*kindProgress),
 	}
+	cfg := config.Get()
+	m.workers = startEventWorkers(m, cfg.InformerWorkers, cfg.InformerQueueSize)
+	return m
 }
 
+// informerResyncPeriod recovers from missed events; 10 minutes is a good balance
+// between freshness and API load.
+const informerResyncPeriod = 10 * time.Minute
+
+// reportSizeSampleEvery is how many ingested reports make one observation of the report
+// size metrics: serializing every report only to measure it would double ingest CPU.
+const reportSizeSampleEvery = 16
+
 func (m *ReportInformerManager) Start() error {
 	registry := config.GetGlobalRegistry()
 	reports := registry.GetAllReports()
 
 	if len(reports) == 0 {
-		return fmt.Errorf("no report types discovered")
+		// the operator may be installed later; the CRD watcher starts informers then
+		m.watchCRDs()
+		m.watchNamespaces()
+		return fmt.Errorf("no report types discovered yet, watching CRDs")
 	}
 
 	m.mu.Lock()
@@ -63,61 +100,12 @@ func (m *ReportInformerManager) Start() error {
 		m.cacheUpdater.UpdateSyncState(m.clusterName, "Syncing")
 	}
 
-	// Use a reasonable resync period to recover from missed events
-	// 10 minutes is a good balance between freshness and API load
-	resyncPeriod := 10 * time.Minute
-
-	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
-		m.client.dynamic,
-		resyncPeriod,
-		metav1.NamespaceAll,
-		nil,
-	)
-
 	for _, reportType := range reports {
-		reportType := reportType // Create local copy to avoid closure capture issue
-		group, version := parseAPIVersion(reportType.APIVersion)
-		gvr := schema.GroupVersionResource{
-			Group:    group,
-			Version:  version,
-			Resource: reportType.Name,
+		if m.kindEnabled(reportType) {
+			m.startInformerLocked(reportType)
 		}
-
-		informer := factory.ForResource(gvr).Informer()
-
-		if err := informer.SetTransform(stripLargeFields); err != nil {
-			utils.LogWarning("Failed to set transform on informer", map[string]interface{}{
-				"reportType": reportType.Name,
-				"error":      err.Error(),
-			})
-		}
-
-		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-			AddFunc: func(obj interface{}) {
-				m.onAdd(reportType, obj)
-			},
-			UpdateFunc: func(oldObj, newObj interface{}) {
-				m.onUpdate(reportType, oldObj, newObj)
-			},
-			DeleteFunc: func(obj interface{}) {
-				m.onDelete(reportType, obj)
-			},
-		})
-
-		// Set error handler to log watch errors (helps debug stream errors)
-		informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
-			utils.LogWarning("Informer watch error, will retry", map[string]interface{}{
-				"cluster":    m.clusterName,
-				"reportType": reportType.Name,
-				"error":      err.Error(),
-			})
-		})
-
-		m.informers[reportType.Name] = informer
 	}
 
-	factory.Start(m.ctx.Done())
-
 	syncTimeout := 2 * time.Minute
 	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
 	defer cancel()
@@ -143,6 +131,7 @@ func (m *ReportInformerManager) Start() error {
 					"cluster":    m.clusterName,
 					"reportType": n,
 				})
+				m.kindProgress(n).timedOut.Store(true)
 			}
 			resultCh <- syncResult{name: n, synced: ok}
 		}(name, informer)
@@ -183,11 +172,20 @@ func (m *ReportInformerManager) Start() error {
 			"reportType": reportType.Name,
 			"count":      len(items),
 		})
-		loadWg.Add(1)
+		progress := m.kindProgress(reportType.Name)
+		progress.total.Store(int64(len(items)))
+		loadWg.Add(len(items))
 		go func(rt config.ReportKind, items []interface{}) {
-			defer loadWg.Done()
+			loaded := func() {
+				progress.loaded.Add(1)
+				loadWg.Done()
+			}
 			for _, item := range items {
-				m.onAdd(rt, item)
+				if includedNamespace(item) {
+					m.dispatch(reportEvent{op: eventAdd, kind: rt, obj: item, done: loaded})
+				} else {
+					loaded()
+				}
 			}
 		}(reportType, items)
 	}
@@ -198,14 +196,201 @@ func (m *ReportInformerManager) Start() error {
 		"total":   len(m.informers),
 		"synced":  syncedCount,
 	})
+	m.watchCRDs()
+	m.watchNamespaces()
 	return nil
 }
 
+// startInformerLocked creates and runs the informer for one report kind. Each informer
+// has its own stop function so kinds can come and go with their CRDs.
+func (m *ReportInformerManager) startInformerLocked(reportType config.ReportKind) cache.SharedIndexInformer {
+	group, version := parseAPIVersion(reportType.APIVersion)
+	gvr := schema.GroupVersionResource{
+		Group:    group,
+		Version:  version,
+		Resource: reportType.Name,
+	}
+
+	informer := dynamicinformer.NewFilteredDynamicInformer(
+		m.client.dynamic,
+		gvr,
+		metav1.NamespaceAll,
+		informerResyncPeriod,
+		cache.Indexers{},
+		nil,
+	).Informer()
+
+	var ingested atomic.Uint64
+	transform := func(obj interface{}) (interface{}, error) {
+		// the first report of a kind is always measured, so small clusters have samples
+		if u, ok := obj.(*unstructured.Unstructured); ok && ingested.Add(1)%reportSizeSampleEvery == 1 {
+			metrics.ObserveReportSize(reportType.Name, metrics.SerializedSize(u.Object), config.Get().OversizedReportBytes)
+		}
+		return stripLargeFields(obj)
+	}
+	if err := informer.SetTransform(transform); err != nil {
+		utils.LogWarning("Failed to set transform on informer", map[string]interface{}{
+			"reportType": reportType.Name,
+			"error":      err.Error(),
+		})
+	}
+
+	// excluded namespaces are dropped in receive rather than by a filtering handler, so they
+	// are counted; a report's namespace never changes, so its updates need no filter on the
+	// old object
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if m.receive(reportType.Name, eventAdd, obj, nil) {
+				m.dispatch(reportEvent{op: eventAdd, kind: reportType, obj: obj})
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if m.receive(reportType.Name, eventUpdate, newObj, oldObj) {
+				m.dispatch(reportEvent{op: eventUpdate, kind: reportType, obj: newObj, oldObj: oldObj})
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if m.receive(reportType.Name, eventDelete, obj, nil) {
+				m.dispatch(reportEvent{op: eventDelete, kind: reportType, obj: obj})
+			}
+		},
+	})
+
+	// Set error handler to log watch errors (helps debug stream errors)
+	informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		if isAuthError(err) {
+			// the reflector keeps retrying, and client-go refreshes exec credentials on
+			// the 401, so this only persists when the plugin or its credentials are broken
+			utils.LogError("Informer watch failed to authenticate, reports go stale until it recovers", map[string]interface{}{
+				"cluster":    m.clusterName,
+				"reportType": reportType.Name,
+				"auth":       m.client.AuthHealth().Method,
+				"error":      err.Error(),
+			})
+			return
+		}
+		utils.LogWarning("Informer watch error, will retry", map[string]interface{}{
+			"cluster":    m.clusterName,
+			"reportType": reportType.Name,
+			"error":      err.Error(),
+		})
+	})
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.informers[reportType.Name] = informer
+	m.stops[reportType.Name] = cancel
+	m.trackProgress(reportType.Name, informer)
+	go informer.Run(ctx.Done())
+	return informer
+}
+
+// AddReportKind starts watching a report kind whose CRD appeared after startup.
+// Existing resources are loaded into the cache once the informer has synced.
+func (m *ReportInformerManager) AddReportKind(reportType config.ReportKind) {
+	if !m.kindEnabled(reportType) {
+		return
+	}
+	m.mu.Lock()
+	if _, ok := m.informers[reportType.Name]; ok || m.ctx.Err() != nil {
+		m.mu.Unlock()
+		return
+	}
+	informer := m.startInformerLocked(reportType)
+	m.mu.Unlock()
+
+	utils.LogInfo("Started informer for new report kind", map[string]interface{}{
+		"cluster":    m.clusterName,
+		"reportType": reportType.Name,
+	})
+	go func() {
+		if !cache.WaitForCacheSync(m.ctx.Done(), informer.HasSynced) {
+			return
+		}
+		// the informer's own add handler already delivered the initial list
+		utils.LogInfo("Informer synced", map[string]interface{}{
+			"cluster":    m.clusterName,
+			"reportType": reportType.Name,
+			"count":      len(informer.GetStore().List()),
+		})
+	}()
+}
+
+// RemoveReportKind stops watching a report kind whose CRD was deleted and drops its
+// reports from the cache.
+func (m *ReportInformerManager) RemoveReportKind(reportType config.ReportKind) {
+	informer := m.stopInformer(reportType.Name)
+	if informer == nil {
+		return
+	}
+
+	items := informer.GetStore().List()
+	for _, item := range items {
+		if includedNamespace(item) {
+			m.onDelete(reportType, item)
+		}
+	}
+	utils.LogInfo("Stopped informer for removed report kind", map[string]interface{}{
+		"cluster":    m.clusterName,
+		"reportType": reportType.Name,
+		"removed":    len(items),
+	})
+}
+
+// stopInformer stops watching a report kind and returns its informer, nil when the kind
+// was not watched.
+func (m *ReportInformerManager) stopInformer(name string) cache.SharedInformer {
+	m.mu.Lock()
+	informer, ok := m.informers[name]
+	if !ok {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stops[name]()
+	delete(m.informers, name)
+	delete(m.stops, name)
+	m.mu.Unlock()
+	m.untrackProgress(name)
+	return informer
+}
+
+func (m *ReportInformerManager) kindEnabled(reportType config.ReportKind) bool {
+	return config.Get().ReportKinds.Enabled(m.clusterName, reportType.Name)
+}
+
+// ApplyKindToggles starts the informers of report kinds enabled since the last call and
+// stops those of kinds disabled, returning the kinds stopped. Their reports are left in
+// the cache for the caller to drop, so disabling a kind does not look like its reports
+// were deleted.
+func (m *ReportInformerManager) ApplyKindToggles() (started, stopped []string) {
+	for _, reportType := range config.GetGlobalRegistry().GetAllReports() {
+		watched := m.GetInformer(reportType.Name) != nil
+		switch enabled := m.kindEnabled(reportType); {
+		case enabled && !watched && m.ctx.Err() == nil:
+			m.AddReportKind(reportType)
+			started = append(started, reportType.Name)
+		case !enabled && watched:
+			if m.stopInformer(reportType.Name) != nil {
+				utils.LogInfo("Stopped informer for disabled report kind", map[string]interface{}{
+					"cluster":    m.clusterName,
+					"reportType": reportType.Name,
+				})
+				stopped = append(stopped, reportType.Name)
+			}
+		}
+	}
+	return started, stopped
+}
+
 func (m *ReportInformerManager) Stop() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.cancel()
+	metrics.InformerQueueDepth.DeleteLabelValues(m.clusterName)
 	m.informers = make(map[string]cache.SharedInformer)
+	m.stops = make(map[string]context.CancelFunc)
+	m.progressMu.Lock()
+	m.progress = make(map[string]*kindProgress)
+	m.progressMu.Unlock()
 }
 
 func (m *ReportInformerManager) GetInformer(reportType string) cache.SharedInformer {
@@ -232,58 +417,112 @@ func stripLargeFields(obj interface{}) (interface{}, error) {
 
 	if reportObj, hasReport := u.Object["report"].(map[string]interface{}); hasReport {
 		stripped := make(map[string]interface{})
-		for _, key := range []string{"summary", "artifact", "scanner", "registry", "updateTimestamp"} {
+		for _, key := range []string{"summary", "artifact", "scanner", "registry", "os", "updateTimestamp", "generatedAt"} {
 			if v, exists := reportObj[key]; exists {
 				stripped[key] = v
 			}
 		}
+		// Keep a compact per-finding index so finding-level tracking (first seen, SLA)
+		// works without holding the full vulnerability payload in memory
+		if vulns, ok := reportObj["vulnerabilities"].([]interface{}); ok {
+			stripped["findings"] = compactFindings(vulns)
+		}
+		// config audit reports keep their failed check IDs for the /api/v1/pss rollup
+		if checks, ok := reportObj["checks"].([]interface{}); ok {
+			stripped["failedChecks"] = compactFailedChecks(checks)
+		}
+		// sbomreports keep package counts per ecosystem for /api/v1/sbom/stats
+		if bom, ok := reportObj["components"].(map[string]interface{}); ok {
+			stripped["packageTypes"] = compactPackageTypes(bom)
+		}
 		u.Object["report"] = stripped
 	}
 
 	return u, nil
 }
 
-func (m *ReportInformerManager) onAdd(reportType config.ReportKind, obj interface{}) {
-	unstructuredObj, ok := obj.(*unstructured.Unstructured)
-	if !ok {
-		return
+// includedNamespace drops events for namespaces matched by EXCLUDE_NAMESPACES.
+func includedNamespace(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
 	}
-	report := m.convertToReport(reportType, unstructuredObj)
-	if report != nil && m.cacheUpdater != nil {
-		m.cacheUpdater.SetReport(m.clusterName, report.Namespace, report.Type, report.Name, report)
-		// Update counters
-		hasVuln := m.hasVulnerabilities(unstructuredObj.Object)
-		m.cacheUpdater.IncrementCount(m.clusterName, report.Namespace, report.Type, hasVuln)
+	u, ok := obj.(*unstructured.Unstructured)
+	return !ok || !config.Get().NamespaceExcluded(u.GetNamespace())
+}
+
+func (m *ReportInformerManager) onAdd(reportType config.ReportKind, obj interface{}) {
+	if change, ok := m.addChange(reportType, obj); ok {
+		m.applyChanges([]reportChange{change})
 	}
 }
 
 func (m *ReportInformerManager) onUpdate(reportType config.ReportKind, oldObj, newObj interface{}) {
-	oldUnstructured, oldOk := oldObj.(*unstructured.Unstructured)
+	if change, ok := m.updateChange(reportType, oldObj, newObj); ok {
+		m.applyChanges([]reportChange{change})
+	}
+}
+
+// reportChange is the cache write of an add or update event, and the bookkeeping done
+// once the report is cached.
+type reportChange struct {
+	report *Report
+	after  func()
+}
+
+func (m *ReportInformerManager) addChange(reportType config.ReportKind, obj interface{}) (reportChange, bool) {
+	unstructuredObj, ok := obj.(*unstructured.Unstructured)
+	if !ok || m.cacheUpdater == nil {
+		return reportChange{}, false
+	}
+	return reportChange{report: m.convertToReport(reportType, unstructuredObj)}, true
+}
+
+func (m *ReportInformerManager) updateChange(reportType config.ReportKind, oldObj, newObj interface{}) (reportChange, bool) {
+	_, oldOk := oldObj.(*unstructured.Unstructured)
 	newUnstructured, newOk := newObj.(*unstructured.Unstructured)
-	if !oldOk || !newOk {
-		return
+	if !oldOk || !newOk || m.cacheUpdater == nil {
+		return reportChange{}, false
 	}
-	report := m.convertToReport(reportType, newUnstructured)
-	if report != nil && m.cacheUpdater != nil {
-		m.cacheUpdater.SetReport(m.clusterName, report.Namespace, report.Type, report.Name, report)
+	return m.refreshChange(reportType, newUnstructured), true
+}
+
+// refreshChange re-caches a changed report and drops its cached detail.
+func (m *ReportInformerManager) refreshChange(reportType config.ReportKind, obj *unstructured.Unstructured) reportChange {
+	report := m.convertToReport(reportType, obj)
+	return reportChange{report: report, after: func() {
 		m.cacheUpdater.InvalidateReportDetail(m.clusterName, report.Namespace, report.Type, report.Name)
+	}}
+}
 
-		// Check if vulnerability status changed and adjust counters
-		oldHasVuln := m.hasVulnerabilities(oldUnstructured.Object)
-		newHasVuln := m.hasVulnerabilities(newUnstructured.Object)
-		if oldHasVuln != newHasVuln {
-			if newHasVuln {
-				// Changed from no vulnerabilities to has vulnerabilities
-				m.cacheUpdater.AdjustVulnCount(m.clusterName, report.Namespace, report.Type, 1)
-			} else {
-				// Changed from has vulnerabilities to no vulnerabilities
-				m.cacheUpdater.AdjustVulnCount(m.clusterName, report.Namespace, report.Type, -1)
-			}
+// applyChanges writes the reports to the cache, under one cache lock when the updater
+// takes batches, then does their bookkeeping.
+func (m *ReportInformerManager) applyChanges(changes []reportChange) {
+	if len(changes) == 0 {
+		return
+	}
+	if batcher, ok := m.cacheUpdater.(BatchCacheUpdater); ok && len(changes) > 1 {
+		reports := make([]*Report, len(changes))
+		for i, c := range changes {
+			reports[i] = c.report
+		}
+		batcher.SetReports(m.clusterName, reports)
+	} else {
+		for _, c := range changes {
+			m.cacheUpdater.SetReport(m.clusterName, c.report.Namespace, c.report.Type, c.report.Name, c.report)
+		}
+	}
+	for _, c := range changes {
+		if c.after != nil {
+			c.after()
 		}
 	}
 }
 
 func (m *ReportInformerManager) onDelete(reportType config.ReportKind, obj interface{}) {
+	// deletes missed while the watch was down arrive as tombstones after the relist
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
 	unstructuredObj, ok := obj.(*unstructured.Unstructured)
 	if !ok {
 		return
@@ -296,19 +535,35 @@ func (m *ReportInformerManager) onDelete(reportType config.ReportKind, obj inter
 }
 
 func (m *ReportInformerManager) convertToReport(reportType config.ReportKind, obj *unstructured.Unstructured) *Report {
-	status := m.extractStatus(obj.Object)
+	summary := ExtractSummary(reportType.Name, obj.Object)
 
 	// Extract only summary data for cache, not full details (vulnerabilities, components, etc.)
 	// This significantly reduces memory usage and avoids stream errors for large reports like SBOM
 	summaryData := m.extractSummaryData(obj.Object)
+	if summary.Counts != nil {
+		// kinds without report.summary, e.g. compliance reports, get the counts there too
+		reportCopy, ok := summaryData["report"].(map[string]interface{})
+		if !ok {
+			reportCopy = make(map[string]interface{})
+			summaryData["report"] = reportCopy
+		}
+		if _, ok := reportCopy["summary"]; !ok {
+			reportCopy["summary"] = summary.Counts
+		}
+	}
 
 	return &Report{
 		Type:      reportType.Name,
 		Cluster:   m.clusterName,
 		Namespace: obj.GetNamespace(),
 		Name:      obj.GetName(),
-		Status:    status,
+		Status:    summary.Status,
 		Data:      summaryData,
+		Findings:  ExtractFindings(obj.Object),
+		ScannedAt: extractScannedAt(obj.Object),
+
+		UID:             string(obj.GetUID()),
+		ResourceVersion: obj.GetResourceVersion(),
 	}
 }
 
@@ -359,6 +614,11 @@ func (m *ReportInformerManager) extractSummaryData(obj map[string]interface{}) m
 			reportCopy["registry"] = registry
 		}
 
+		// Copy base OS info (family/name/eosl)
+		if osInfo, ok := reportObj["os"].(map[string]interface{}); ok {
+			reportCopy["os"] = osInfo
+		}
+
 		// Copy updateTimestamp
 		if updateTimestamp, ok := reportObj["updateTimestamp"]; ok {
 			reportCopy["updateTimestamp"] = updateTimestamp
@@ -373,37 +633,19 @@ func (m *ReportInformerManager) extractSummaryData(obj map[string]interface{}) m
 	return result
 }
 
-func (m *ReportInformerManager) extractStatus(obj map[string]interface{}) string {
-	status := "Unknown"
-	if reportObj, ok := obj["report"].(map[string]interface{}); ok {
-		if summaryData, ok := reportObj["summary"].(map[string]interface{}); ok {
-			if criticalCount, ok := summaryData["criticalCount"].(float64); ok && criticalCount > 0 {
-				status = "Critical"
-			} else if highCount, ok := summaryData["highCount"].(float64); ok && highCount > 0 {
-				status = "High"
-			} else if mediumCount, ok := summaryData["mediumCount"].(float64); ok && mediumCount > 0 {
-				status = "Medium"
-			} else if lowCount, ok := summaryData["lowCount"].(float64); ok && lowCount > 0 {
-				status = "Low"
-			} else if noneCount, ok := summaryData["noneCount"].(float64); ok && noneCount > 0 {
-				status = "None"
-			}
-		}
+// extractScannedAt reads the scan time written by the operator: report.updateTimestamp,
+// falling back to report.generatedAt used by some report kinds.
+func extractScannedAt(obj map[string]interface{}) time.Time {
+	reportObj, ok := obj["report"].(map[string]interface{})
+	if !ok {
+		return time.Time{}
 	}
-	return status
-}
-
-// hasVulnerabilities checks if a report has any vulnerabilities based on summary counts
-func (m *ReportInformerManager) hasVulnerabilities(obj map[string]interface{}) bool {
-	if reportObj, ok := obj["report"].(map[string]interface{}); ok {
-		if summaryData, ok := reportObj["summary"].(map[string]interface{}); ok {
-			severities := []string{"criticalCount", "highCount", "mediumCount", "lowCount"}
-			for _, key := range severities {
-				if count, ok := summaryData[key].(float64); ok && count > 0 {
-					return true
-				}
+	for _, key := range []string{"updateTimestamp", "generatedAt"} {
+		if value, ok := reportObj[key].(string); ok && value != "" {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				return t
 			}
 		}
 	}
-	return false
+	return time.Time{}
 }
@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -26,78 +27,68 @@ func newManager() *ReportInformerManager {
 
 func TestExtractStatus_Critical(t *testing.T) {
 	obj := makeObj(map[string]interface{}{"summary": makeSummary(2, 0, 0, 0, 0)})
-	m := newManager()
-	if got := m.extractStatus(obj); got != "Critical" {
+	if got := ExtractSummary("vulnerabilityreports", obj).Status; got != "Critical" {
 		t.Fatalf("expected Critical got %s", got)
 	}
 }
 
 func TestExtractStatus_High(t *testing.T) {
 	obj := makeObj(map[string]interface{}{"summary": makeSummary(0, 5, 0, 0, 0)})
-	m := newManager()
-	if got := m.extractStatus(obj); got != "High" {
+	if got := ExtractSummary("vulnerabilityreports", obj).Status; got != "High" {
 		t.Fatalf("expected High got %s", got)
 	}
 }
 
 func TestExtractStatus_Medium(t *testing.T) {
 	obj := makeObj(map[string]interface{}{"summary": makeSummary(0, 0, 3, 0, 0)})
-	m := newManager()
-	if got := m.extractStatus(obj); got != "Medium" {
+	if got := ExtractSummary("vulnerabilityreports", obj).Status; got != "Medium" {
 		t.Fatalf("expected Medium got %s", got)
 	}
 }
 
 func TestExtractStatus_Low(t *testing.T) {
 	obj := makeObj(map[string]interface{}{"summary": makeSummary(0, 0, 0, 1, 0)})
-	m := newManager()
-	if got := m.extractStatus(obj); got != "Low" {
+	if got := ExtractSummary("vulnerabilityreports", obj).Status; got != "Low" {
 		t.Fatalf("expected Low got %s", got)
 	}
 }
 
 func TestExtractStatus_None(t *testing.T) {
 	obj := makeObj(map[string]interface{}{"summary": makeSummary(0, 0, 0, 0, 1)})
-	m := newManager()
-	if got := m.extractStatus(obj); got != "None" {
+	if got := ExtractSummary("vulnerabilityreports", obj).Status; got != "None" {
 		t.Fatalf("expected None got %s", got)
 	}
 }
 
 func TestExtractStatus_Unknown(t *testing.T) {
-	m := newManager()
-	if got := m.extractStatus(map[string]interface{}{}); got != "Unknown" {
+	if got := ExtractSummary("vulnerabilityreports", map[string]interface{}{}).Status; got != "Unknown" {
 		t.Fatalf("expected Unknown got %s", got)
 	}
 }
 
 func TestHasVulnerabilities_True(t *testing.T) {
 	obj := makeObj(map[string]interface{}{"summary": makeSummary(1, 0, 0, 0, 0)})
-	m := newManager()
-	if !m.hasVulnerabilities(obj) {
+	if !ExtractSummary("vulnerabilityreports", obj).HasFindings {
 		t.Fatal("expected true")
 	}
 }
 
 func TestHasVulnerabilities_OnlyHigh(t *testing.T) {
 	obj := makeObj(map[string]interface{}{"summary": makeSummary(0, 2, 0, 0, 0)})
-	m := newManager()
-	if !m.hasVulnerabilities(obj) {
+	if !ExtractSummary("vulnerabilityreports", obj).HasFindings {
 		t.Fatal("expected true")
 	}
 }
 
 func TestHasVulnerabilities_False(t *testing.T) {
 	obj := makeObj(map[string]interface{}{"summary": makeSummary(0, 0, 0, 0, 0)})
-	m := newManager()
-	if m.hasVulnerabilities(obj) {
+	if ExtractSummary("vulnerabilityreports", obj).HasFindings {
 		t.Fatal("expected false")
 	}
 }
 
 func TestHasVulnerabilities_NoReport(t *testing.T) {
-	m := newManager()
-	if m.hasVulnerabilities(map[string]interface{}{}) {
+	if ExtractSummary("vulnerabilityreports", map[string]interface{}{}).HasFindings {
 		t.Fatal("expected false for missing report field")
 	}
 }
@@ -241,3 +232,76 @@ func TestParseAPIVersion_InvalidFormat(t *testing.T) {
 		t.Fatalf("no-slash should return defaults, got group=%s version=%s", group, version)
 	}
 }
+
+func TestStripLargeFields_KeepsCompactFindings(t *testing.T) {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"report": map[string]interface{}{
+				"vulnerabilities": []interface{}{
+					map[string]interface{}{
+						"vulnerabilityID":  "CVE-2024-0001",
+						"severity":         "HIGH",
+						"resource":         "openssl",
+						"installedVersion": "3.0.1",
+						"fixedVersion":     "3.0.2",
+						"title":            "long description that should be dropped",
+					},
+				},
+			},
+		},
+	}
+
+	result, _ := stripLargeFields(u)
+	findings := ExtractFindings(result.(*unstructured.Unstructured).Object)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding got %d", len(findings))
+	}
+	if findings[0].VulnerabilityID != "CVE-2024-0001" || findings[0].FixedVersion != "3.0.2" {
+		t.Fatalf("unexpected finding: %+v", findings[0])
+	}
+}
+
+func TestStripLargeFields_KeepsFailedChecks(t *testing.T) {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"report": map[string]interface{}{
+				"checks": []interface{}{
+					map[string]interface{}{"checkID": "KSV017", "success": false, "messages": []interface{}{"privileged"}},
+					map[string]interface{}{"checkID": "KSV001", "success": true},
+				},
+			},
+		},
+	}
+
+	result, _ := stripLargeFields(u)
+	ids := FailedCheckIDs(result.(*unstructured.Unstructured).Object)
+	if len(ids) != 1 || ids[0] != "KSV017" {
+		t.Fatalf("expected only the failed check, got %v", ids)
+	}
+	if ids := FailedCheckIDs(makeObj(map[string]interface{}{"summary": makeSummary(1, 0, 0, 0, 0)})); ids != nil {
+		t.Fatalf("expected nil for a report without checks, got %v", ids)
+	}
+}
+
+func TestExtractFindings_NonVulnerabilityReport(t *testing.T) {
+	obj := makeObj(map[string]interface{}{"summary": makeSummary(1, 0, 0, 0, 0)})
+	if findings := ExtractFindings(obj); findings != nil {
+		t.Fatalf("expected nil findings for report without vulnerabilities, got %v", findings)
+	}
+}
+
+func TestExtractScannedAt(t *testing.T) {
+	obj := makeObj(map[string]interface{}{"updateTimestamp": "2024-05-01T10:00:00Z"})
+	if got := extractScannedAt(obj); !got.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected scan time %v", got)
+	}
+
+	obj = makeObj(map[string]interface{}{"generatedAt": "2024-05-02T10:00:00Z"})
+	if got := extractScannedAt(obj); got.Day() != 2 {
+		t.Fatalf("expected generatedAt fallback, got %v", got)
+	}
+
+	if got := extractScannedAt(makeObj(map[string]interface{}{})); !got.IsZero() {
+		t.Fatalf("expected zero time, got %v", got)
+	}
+}
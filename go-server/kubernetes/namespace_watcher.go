@@ -0,0 +1,41 @@
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"trivy-ui/utils"
+)
+
+// watchNamespaces purges the reports of namespaces as they are deleted. The reports go
+// with their namespace, but their delete events are lost when a report watch is
+// reconnecting at the time, leaving them cached until the next reconcile.
+func (m *ReportInformerManager) watchNamespaces() {
+	if m.client == nil || m.client.clientset == nil {
+		return
+	}
+	m.namespaceWatch.Do(func() {
+		factory := informers.NewSharedInformerFactory(m.client.clientset, informerResyncPeriod)
+		informer := factory.Core().V1().Namespaces().Informer()
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			DeleteFunc: m.onNamespaceDelete,
+		})
+		factory.Start(m.ctx.Done())
+	})
+}
+
+func (m *ReportInformerManager) onNamespaceDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok || m.cacheUpdater == nil {
+		return
+	}
+	utils.LogInfo("Namespace deleted, purging its reports", map[string]interface{}{
+		"cluster":   m.clusterName,
+		"namespace": ns.Name,
+	})
+	m.cacheUpdater.DeleteNamespace(m.clusterName, ns.Name)
+}
@@ -0,0 +1,35 @@
+package kubernetes
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNamespaceDeletePurgesReports(t *testing.T) {
+	rec := &eventRecorder{}
+	m := &ReportInformerManager{clusterName: "prod", cacheUpdater: rec}
+
+	m.onNamespaceDelete(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}})
+	// a namespace deleted while the watch was down arrives as a tombstone
+	m.onNamespaceDelete(cache.DeletedFinalStateUnknown{Key: "staging", Obj: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "staging"}}})
+	m.onNamespaceDelete("not a namespace")
+
+	want := "[delete namespace prod payments delete namespace prod staging]"
+	if got := fmt.Sprint(rec.events); got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+}
+
+func TestReportDeleteTombstone(t *testing.T) {
+	rec := &eventRecorder{}
+	m := &ReportInformerManager{clusterName: "prod", cacheUpdater: rec}
+
+	m.onDelete(vulnKind, cache.DeletedFinalStateUnknown{Key: "web/replicaset-web", Obj: vulnReport("web", "replicaset-web", 1)})
+	if got := fmt.Sprint(rec.events); got != "[delete prod web/replicaset-web]" {
+		t.Fatalf("events = %s", got)
+	}
+}
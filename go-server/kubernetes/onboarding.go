@@ -0,0 +1,184 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"trivy-ui/config"
+)
+
+// Onboarding features: the report kinds are always needed, the others light up parts of
+// the UI and degrade gracefully without their permissions.
+const (
+	// FeatureReports reads namespaces, the Trivy CRDs and their reports
+	FeatureReports = "reports"
+	// FeatureWorkloads reads workloads and pods for run state, exposure and operator scope
+	FeatureWorkloads = "workloads"
+	// FeatureNodes reads nodes for the node count and platform
+	FeatureNodes = "nodes"
+	// FeatureTrivyDB reads the operator's ConfigMap and scan Jobs for DB freshness
+	FeatureTrivyDB = "trivy-db"
+)
+
+// OnboardingFeatures lists the features in the order manifests list their rules.
+var OnboardingFeatures = []string{FeatureReports, FeatureWorkloads, FeatureNodes, FeatureTrivyDB}
+
+// Permission is a rule trivy-ui needs on a cluster and the feature that needs it. Rules
+// of FeatureTrivyDB apply to the operator's namespace; the others are cluster-wide.
+type Permission struct {
+	Feature string            `json:"feature"`
+	Rule    rbacv1.PolicyRule `json:"rule"`
+}
+
+// Namespaced reports whether the permission belongs in a Role of the operator's namespace.
+func (p Permission) Namespaced() bool {
+	return p.Feature == FeatureTrivyDB
+}
+
+// RequiredPermissions returns the rules trivy-ui needs to read the given report kinds
+// with the given features. Without kinds, all resources of the Trivy group are allowed.
+func RequiredPermissions(kinds []string, features []string) []Permission {
+	reportResources := append([]string(nil), kinds...)
+	sort.Strings(reportResources)
+	if len(reportResources) == 0 {
+		reportResources = []string{"*"}
+	}
+	readOnly := []string{"get", "list", "watch"}
+	enabled := map[string]bool{FeatureReports: true}
+	for _, f := range features {
+		enabled[f] = true
+	}
+
+	var perms []Permission
+	add := func(feature string, groups, resources, verbs []string) {
+		if enabled[feature] {
+			perms = append(perms, Permission{Feature: feature, Rule: rbacv1.PolicyRule{APIGroups: groups, Resources: resources, Verbs: verbs}})
+		}
+	}
+	add(FeatureReports, []string{""}, []string{"namespaces"}, readOnly)
+	add(FeatureReports, []string{config.TrivyGroup}, reportResources, readOnly)
+	add(FeatureReports, []string{"apiextensions.k8s.io"}, []string{"customresourcedefinitions"}, readOnly)
+	add(FeatureWorkloads, []string{""}, []string{"pods"}, []string{"get", "list"})
+	add(FeatureWorkloads, []string{"apps"}, []string{"deployments", "replicasets", "statefulsets", "daemonsets"}, []string{"get", "list"})
+	add(FeatureWorkloads, []string{"batch"}, []string{"jobs", "cronjobs"}, []string{"list"})
+	add(FeatureNodes, []string{""}, []string{"nodes"}, []string{"list"})
+	add(FeatureTrivyDB, []string{""}, []string{"configmaps"}, []string{"get"})
+	add(FeatureTrivyDB, []string{"batch"}, []string{"jobs"}, []string{"list"})
+	return perms
+}
+
+// AccessCheck is whether a kubeconfig's identity may use one verb on one resource.
+type AccessCheck struct {
+	Feature   string `json:"feature"`
+	Group     string `json:"group,omitempty"`
+	Resource  string `json:"resource"`
+	Verb      string `json:"verb"`
+	Namespace string `json:"namespace,omitempty"`
+	Allowed   bool   `json:"allowed"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// CheckAccess asks the API server, with SelfSubjectAccessReviews, whether the client may
+// use each verb of each permission. Namespaced permissions are checked in namespace.
+func (c *Client) CheckAccess(ctx context.Context, perms []Permission, namespace string) ([]AccessCheck, error) {
+	var checks []AccessCheck
+	for _, p := range perms {
+		ns := ""
+		if p.Namespaced() {
+			ns = namespace
+		}
+		for _, group := range p.Rule.APIGroups {
+			for _, resource := range p.Rule.Resources {
+				for _, verb := range p.Rule.Verbs {
+					review, err := c.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+						Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &authorizationv1.ResourceAttributes{
+							Namespace: ns,
+							Verb:      verb,
+							Group:     group,
+							Resource:  resource,
+						}},
+					}, metav1.CreateOptions{})
+					if err != nil {
+						return checks, fmt.Errorf("access review of %s %s failed: %w", verb, resource, err)
+					}
+					checks = append(checks, AccessCheck{
+						Feature:   p.Feature,
+						Group:     group,
+						Resource:  resource,
+						Verb:      verb,
+						Namespace: ns,
+						Allowed:   review.Status.Allowed,
+						Reason:    review.Status.Reason,
+					})
+				}
+			}
+		}
+	}
+	return checks, nil
+}
+
+// MissingPermissions returns the rules of the denied checks, one per resource and
+// feature, so they can be granted as they are.
+func MissingPermissions(checks []AccessCheck) []Permission {
+	var perms []Permission
+	index := make(map[string]int)
+	for _, c := range checks {
+		if c.Allowed {
+			continue
+		}
+		key := c.Feature + "\x00" + c.Group + "\x00" + c.Resource
+		i, ok := index[key]
+		if !ok {
+			i = len(perms)
+			index[key] = i
+			perms = append(perms, Permission{Feature: c.Feature, Rule: rbacv1.PolicyRule{APIGroups: []string{c.Group}, Resources: []string{c.Resource}}})
+		}
+		perms[i].Rule.Verbs = append(perms[i].Rule.Verbs, c.Verb)
+	}
+	return perms
+}
+
+// DiscoverReportKinds returns the resource names of the Trivy report kinds the cluster
+// serves, empty when the operator's CRDs are not installed.
+func (c *Client) DiscoverReportKinds(ctx context.Context) ([]string, error) {
+	groups, err := c.clientset.Discovery().ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	var kinds []string
+	for _, g := range groups.Groups {
+		if g.Name != config.TrivyGroup {
+			continue
+		}
+		resources, err := c.clientset.Discovery().ServerResourcesForGroupVersion(g.PreferredVersion.GroupVersion)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, r := range resources.APIResources {
+			if !strings.Contains(r.Name, "/") {
+				kinds = append(kinds, r.Name)
+			}
+		}
+	}
+	sort.Strings(kinds)
+	return kinds, nil
+}
+
+// ServerVersion returns the API server's version, e.g. v1.30.2.
+func (c *Client) ServerVersion() (string, error) {
+	info, err := c.clientset.Discovery().ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	return info.GitVersion, nil
+}
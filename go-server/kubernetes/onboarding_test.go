@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequiredPermissions(t *testing.T) {
+	perms := RequiredPermissions([]string{"vulnerabilityreports", "configauditreports"}, nil)
+	if len(perms) != 3 {
+		t.Fatalf("expected only the report rules without features, got %+v", perms)
+	}
+	if got := perms[1].Rule.Resources; !reflect.DeepEqual(got, []string{"configauditreports", "vulnerabilityreports"}) {
+		t.Fatalf("expected the sorted kinds, got %v", got)
+	}
+
+	perms = RequiredPermissions(nil, OnboardingFeatures)
+	if got := perms[1].Rule.Resources; !reflect.DeepEqual(got, []string{"*"}) {
+		t.Fatalf("expected every resource of the group without kinds, got %v", got)
+	}
+	namespaced := 0
+	for _, p := range perms {
+		if p.Namespaced() {
+			namespaced++
+			if p.Feature != FeatureTrivyDB {
+				t.Fatalf("unexpected namespaced permission %+v", p)
+			}
+		}
+	}
+	if namespaced != 2 {
+		t.Fatalf("expected the operator ConfigMap and Jobs to be namespaced, got %d", namespaced)
+	}
+}
+
+func TestMissingPermissions(t *testing.T) {
+	checks := []AccessCheck{
+		{Feature: FeatureReports, Resource: "namespaces", Verb: "get", Allowed: true},
+		{Feature: FeatureReports, Resource: "namespaces", Verb: "list"},
+		{Feature: FeatureReports, Resource: "namespaces", Verb: "watch"},
+		{Feature: FeatureNodes, Resource: "nodes", Verb: "list"},
+		{Feature: FeatureTrivyDB, Group: "batch", Resource: "jobs", Verb: "list", Allowed: true},
+	}
+	missing := MissingPermissions(checks)
+	if len(missing) != 2 {
+		t.Fatalf("expected one rule per denied resource, got %+v", missing)
+	}
+	if got := missing[0].Rule.Verbs; !reflect.DeepEqual(got, []string{"list", "watch"}) {
+		t.Fatalf("expected only the denied verbs, got %v", got)
+	}
+	if missing[1].Feature != FeatureNodes || missing[1].Rule.APIGroups[0] != "" {
+		t.Fatalf("unexpected rule %+v", missing[1])
+	}
+	if MissingPermissions(checks[:1]) != nil {
+		t.Fatal("expected nothing missing when everything is allowed")
+	}
+}
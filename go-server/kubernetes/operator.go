@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// operatorSelector matches the Deployment installed by the trivy-operator Helm chart.
+const operatorSelector = "app.kubernetes.io/name=trivy-operator"
+
+// Namespace scan statuses derived from the operator's namespace settings.
+const (
+	ScanStatusScanned     = "scanned"
+	ScanStatusExcluded    = "excluded"
+	ScanStatusNotTargeted = "notTargeted"
+	ScanStatusUnknown     = "unknown"
+)
+
+// OperatorScope is the namespace filter trivy-operator runs with in a cluster.
+type OperatorScope struct {
+	// Found is false when no operator Deployment was visible; every namespace is then unknown
+	Found bool `json:"found"`
+	// Namespace is where the operator runs
+	Namespace string `json:"namespace,omitempty"`
+	// TargetNamespaces restricts scanning to these namespaces; empty means all
+	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
+	// ExcludeNamespaces are glob patterns of namespaces never scanned
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+}
+
+// ScanStatus reports whether the operator scans a namespace. A namespace without reports
+// but with status scanned is clean; excluded and notTargeted ones are never scanned.
+func (s OperatorScope) ScanStatus(namespace string) string {
+	if !s.Found {
+		return ScanStatusUnknown
+	}
+	for _, pattern := range s.ExcludeNamespaces {
+		if matched, err := path.Match(pattern, namespace); err == nil && matched {
+			return ScanStatusExcluded
+		}
+	}
+	if len(s.TargetNamespaces) == 0 {
+		return ScanStatusScanned
+	}
+	for _, target := range s.TargetNamespaces {
+		if target == namespace {
+			return ScanStatusScanned
+		}
+	}
+	return ScanStatusNotTargeted
+}
+
+// OperatorScopeFromEnv builds the scope from the operator container's environment.
+// Variables sourced from ConfigMaps or Secrets are not resolved and count as unset.
+func OperatorScopeFromEnv(namespace string, env []corev1.EnvVar) OperatorScope {
+	scope := OperatorScope{Found: true, Namespace: namespace}
+	for _, e := range env {
+		switch e.Name {
+		case "OPERATOR_TARGET_NAMESPACES":
+			scope.TargetNamespaces = splitNamespaceList(e.Value)
+		case "OPERATOR_EXCLUDE_NAMESPACES":
+			scope.ExcludeNamespaces = splitNamespaceList(e.Value)
+		}
+	}
+	return scope
+}
+
+// GetOperatorScope finds the trivy-operator Deployment and reads its namespace settings.
+func (c *Client) GetOperatorScope(ctx context.Context) (OperatorScope, error) {
+	deployments, err := c.clientset.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: operatorSelector})
+	if err != nil {
+		return OperatorScope{}, err
+	}
+	for _, d := range deployments.Items {
+		containers := d.Spec.Template.Spec.Containers
+		if len(containers) == 0 {
+			continue
+		}
+		// sidecars may be injected; prefer the container the chart names after the operator
+		env := containers[0].Env
+		for _, container := range containers {
+			if container.Name == "trivy-operator" {
+				env = container.Env
+			}
+		}
+		return OperatorScopeFromEnv(d.Namespace, env), nil
+	}
+	return OperatorScope{}, nil
+}
+
+func splitNamespaceList(value string) []string {
+	var result []string
+	for _, ns := range strings.Split(value, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			result = append(result, ns)
+		}
+	}
+	return result
+}
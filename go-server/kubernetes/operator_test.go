@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestOperatorScopeFromEnv(t *testing.T) {
+	scope := OperatorScopeFromEnv("trivy-system", []corev1.EnvVar{
+		{Name: "OPERATOR_TARGET_NAMESPACES", Value: "apps, payments"},
+		{Name: "OPERATOR_EXCLUDE_NAMESPACES", Value: "kube-*,payments"},
+		{Name: "OPERATOR_LOG_DEV_MODE", Value: "false"},
+	})
+	if !scope.Found || scope.Namespace != "trivy-system" {
+		t.Fatalf("unexpected scope %+v", scope)
+	}
+	if len(scope.TargetNamespaces) != 2 || scope.TargetNamespaces[1] != "payments" {
+		t.Fatalf("unexpected targets %v", scope.TargetNamespaces)
+	}
+
+	cases := map[string]string{
+		"apps":        ScanStatusScanned,
+		"payments":    ScanStatusExcluded,
+		"kube-system": ScanStatusExcluded,
+		"default":     ScanStatusNotTargeted,
+	}
+	for ns, want := range cases {
+		if got := scope.ScanStatus(ns); got != want {
+			t.Errorf("%s: expected %s, got %s", ns, want, got)
+		}
+	}
+}
+
+func TestOperatorScope_AllNamespaces(t *testing.T) {
+	scope := OperatorScopeFromEnv("trivy-system", []corev1.EnvVar{{Name: "OPERATOR_TARGET_NAMESPACES", Value: ""}})
+	if got := scope.ScanStatus("default"); got != ScanStatusScanned {
+		t.Fatalf("empty target list should scan everything, got %s", got)
+	}
+	if got := (OperatorScope{}).ScanStatus("default"); got != ScanStatusUnknown {
+		t.Fatalf("missing operator should be unknown, got %s", got)
+	}
+}
@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// KindSyncProgress is how far the initial sync of one report kind has got. A kind is
+// listed once its informer has synced; reports listed at startup are then loaded into
+// the cache, Loaded of Total.
+type KindSyncProgress struct {
+	Kind    string `json:"kind"`
+	Synced  bool   `json:"synced"`
+	Objects int    `json:"objects"`
+	Loaded  int    `json:"loaded"`
+	Total   int    `json:"total"`
+	// TimedOut is set when the kind missed the startup sync deadline; it keeps syncing
+	// in the background
+	TimedOut bool `json:"timedOut,omitempty"`
+	Percent  int  `json:"percent"`
+}
+
+type kindProgress struct {
+	informer cache.SharedInformer
+	loaded   atomic.Int64
+	// total is the number of reports replayed into the cache at startup, -1 until the
+	// replay starts; kinds added later are loaded by their informer's add handler
+	total    atomic.Int64
+	timedOut atomic.Bool
+}
+
+func (m *ReportInformerManager) trackProgress(kind string, informer cache.SharedInformer) {
+	p := &kindProgress{informer: informer}
+	p.total.Store(-1)
+	m.progressMu.Lock()
+	m.progress[kind] = p
+	m.progressMu.Unlock()
+}
+
+func (m *ReportInformerManager) untrackProgress(kind string) {
+	m.progressMu.Lock()
+	delete(m.progress, kind)
+	m.progressMu.Unlock()
+}
+
+// kindProgress returns the tracker of a started kind; untracked kinds get a detached one.
+func (m *ReportInformerManager) kindProgress(kind string) *kindProgress {
+	m.progressMu.Lock()
+	defer m.progressMu.Unlock()
+	if p, ok := m.progress[kind]; ok {
+		return p
+	}
+	p := &kindProgress{}
+	p.total.Store(-1)
+	return p
+}
+
+// SyncProgress reports the initial sync of every watched report kind. It does not wait
+// for Start, which holds the manager's lock until all informers synced or timed out.
+func (m *ReportInformerManager) SyncProgress() []KindSyncProgress {
+	m.progressMu.Lock()
+	entries := make(map[string]*kindProgress, len(m.progress))
+	for kind, p := range m.progress {
+		entries[kind] = p
+	}
+	m.progressMu.Unlock()
+
+	result := make([]KindSyncProgress, 0, len(entries))
+	for kind, p := range entries {
+		kp := KindSyncProgress{
+			Kind:     kind,
+			Synced:   p.informer.HasSynced(),
+			Objects:  len(p.informer.GetStore().ListKeys()),
+			TimedOut: p.timedOut.Load(),
+		}
+		if total := p.total.Load(); total >= 0 {
+			kp.Total, kp.Loaded = int(total), int(min(p.loaded.Load(), total))
+		} else if kp.Synced {
+			kp.Total, kp.Loaded = kp.Objects, kp.Objects
+		}
+		kp.Percent = kp.percent()
+		result = append(result, kp)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Kind < result[j].Kind })
+	return result
+}
+
+// percent counts listing as the first half of a kind's sync and loading as the second.
+func (p KindSyncProgress) percent() int {
+	switch {
+	case !p.Synced:
+		return 0
+	case p.Total == 0:
+		return 100
+	default:
+		return 50 + 50*p.Loaded/p.Total
+	}
+}
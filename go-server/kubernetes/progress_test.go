@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func listedInformer(objects ...*unstructured.Unstructured) cache.SharedIndexInformer {
+	lw := &cache.ListWatch{
+		ListWithContextFunc: func(context.Context, metav1.ListOptions) (runtime.Object, error) {
+			list := &unstructured.UnstructuredList{}
+			list.SetResourceVersion("1")
+			for _, obj := range objects {
+				list.Items = append(list.Items, *obj)
+			}
+			return list, nil
+		},
+		WatchFuncWithContext: func(context.Context, metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), nil
+		},
+	}
+	return cache.NewSharedIndexInformer(lw, &unstructured.Unstructured{}, 0, cache.Indexers{})
+}
+
+func TestSyncProgress(t *testing.T) {
+	m := NewReportInformerManager(nil, "lab", nil)
+	synced := listedInformer(vulnReport("ns", "a", 1), vulnReport("ns", "b", 0))
+	pending := listedInformer()
+	m.trackProgress("vulnerabilityreports", synced)
+	m.trackProgress("configauditreports", pending)
+
+	go synced.Run(t.Context().Done())
+	if !cache.WaitForCacheSync(t.Context().Done(), synced.HasSynced) {
+		t.Fatal("informer did not sync")
+	}
+
+	progress := m.SyncProgress()
+	if len(progress) != 2 || progress[0].Kind != "configauditreports" {
+		t.Fatalf("progress = %+v", progress)
+	}
+	if p := progress[0]; p.Synced || p.Percent != 0 {
+		t.Fatalf("unsynced kind = %+v", p)
+	}
+	if p := progress[1]; !p.Synced || p.Objects != 2 || p.Loaded != 2 || p.Percent != 100 {
+		t.Fatalf("synced kind = %+v", p)
+	}
+
+	// a startup replay halfway through
+	kp := m.kindProgress("vulnerabilityreports")
+	kp.total.Store(4)
+	kp.loaded.Store(1)
+	if p := m.SyncProgress()[1]; p.Loaded != 1 || p.Total != 4 || p.Percent != 62 {
+		t.Fatalf("replaying kind = %+v", p)
+	}
+
+	m.untrackProgress("configauditreports")
+	if progress := m.SyncProgress(); len(progress) != 1 {
+		t.Fatalf("progress after untrack = %+v", progress)
+	}
+}
@@ -0,0 +1,33 @@
+package kubernetes
+
+import (
+	"context"
+	"net/url"
+)
+
+// RawResponse is the API server's answer to a raw request, error statuses included.
+type RawResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// GetRaw sends a GET for an absolute API path, e.g.
+// /apis/aquasecurity.github.io/v1alpha1/vulnerabilityreports, with the client's
+// credentials. Only failures to reach the API server are returned as errors.
+func (c *Client) GetRaw(ctx context.Context, path string, query url.Values) (*RawResponse, error) {
+	req := c.clientset.Discovery().RESTClient().Get().AbsPath(path).SetHeader("Accept", "application/json")
+	for name, values := range query {
+		for _, v := range values {
+			req = req.Param(name, v)
+		}
+	}
+	resp := &RawResponse{}
+	result := req.Do(ctx).StatusCode(&resp.StatusCode).ContentType(&resp.ContentType)
+	body, err := result.Raw()
+	if resp.StatusCode == 0 {
+		return nil, err
+	}
+	resp.Body = body
+	return resp, nil
+}
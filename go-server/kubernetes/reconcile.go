@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"trivy-ui/config"
+)
+
+// ReportID identifies one report of a cluster.
+type ReportID struct {
+	Type      string
+	Namespace string
+	Name      string
+}
+
+func (id ReportID) storeKey() string {
+	if id.Namespace == "" {
+		return id.Name
+	}
+	return id.Namespace + "/" + id.Name
+}
+
+// ReconcileResult lists the drift repaired by Reconcile.
+type ReconcileResult struct {
+	// Missing reports exist in the cluster but were absent from the cache; they are re-added
+	Missing []ReportID
+	// Orphaned reports were cached but no longer exist in the cluster; they are deleted
+	Orphaned []ReportID
+	// Stale reports were cached at an older resourceVersion than the cluster's, e.g. after
+	// an update lost while a watch reconnected; they are re-read from the informer store
+	Stale []ReportID
+}
+
+// CachedReport is what Reconcile compares a cached report on.
+type CachedReport struct {
+	// ResourceVersion is empty for reports cached before it was recorded; they are not
+	// checked for staleness
+	ResourceVersion string
+}
+
+// Holds reports whether the informer store contains a report. known is false when the
+// kind has no synced informer, in which case the answer must not be trusted.
+func (m *ReportInformerManager) Holds(id ReportID) (held, known bool) {
+	informer := m.GetInformer(id.Type)
+	if informer == nil || !informer.HasSynced() {
+		return false, false
+	}
+	_, held, err := informer.GetStore().GetByKey(id.storeKey())
+	return held && err == nil, true
+}
+
+// Reconcile compares the informer stores with the cache. cached lists the reports the
+// cache held when the caller took its snapshot; inCache looks a report up in the live
+// cache right before a repair so reports written in between are left alone. wait is
+// called before every repair, letting callers rate-limit; its error aborts the run.
+func (m *ReportInformerManager) Reconcile(ctx context.Context, cached []ReportID, inCache func(ReportID) (CachedReport, bool), wait func(context.Context) error) (ReconcileResult, error) {
+	var result ReconcileResult
+	if m.cacheUpdater == nil {
+		return result, nil
+	}
+
+	for name, informer := range m.GetAllInformers() {
+		if !informer.HasSynced() {
+			continue
+		}
+		kind := config.ReportKind{Name: name}
+		if registered := config.GetGlobalRegistry().GetReportByName(name); registered != nil {
+			kind = *registered
+		}
+		for _, item := range informer.GetStore().List() {
+			obj, ok := item.(*unstructured.Unstructured)
+			if !ok || !includedNamespace(obj) {
+				continue
+			}
+			id := ReportID{Type: name, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+			if c, ok := inCache(id); ok {
+				if !isStale(c, obj) {
+					continue
+				}
+				if err := wait(ctx); err != nil {
+					return result, err
+				}
+				// the informer may have delivered the update since the check above
+				if c, ok = inCache(id); !ok || !isStale(c, obj) {
+					continue
+				}
+				m.applyChanges([]reportChange{m.refreshChange(kind, obj)})
+				result.Stale = append(result.Stale, id)
+				continue
+			}
+			if err := wait(ctx); err != nil {
+				return result, err
+			}
+			m.onAdd(kind, obj)
+			result.Missing = append(result.Missing, id)
+		}
+	}
+
+	for _, id := range cached {
+		if held, known := m.Holds(id); held || !known {
+			continue
+		}
+		if err := wait(ctx); err != nil {
+			return result, err
+		}
+		// the informer may have delivered it since the check above
+		if held, _ := m.Holds(id); held {
+			continue
+		}
+		m.cacheUpdater.DeleteReport(m.clusterName, id.Namespace, id.Type, id.Name)
+		result.Orphaned = append(result.Orphaned, id)
+	}
+	return result, nil
+}
+
+func isStale(c CachedReport, obj *unstructured.Unstructured) bool {
+	return c.ResourceVersion != "" && c.ResourceVersion != obj.GetResourceVersion()
+}
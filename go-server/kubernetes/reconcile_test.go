@@ -0,0 +1,102 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+type syncedInformer struct {
+	cache.SharedInformer
+	store cache.Store
+}
+
+func (i *syncedInformer) HasSynced() bool       { return true }
+func (i *syncedInformer) GetStore() cache.Store { return i.store }
+
+type recordingCacheUpdater struct {
+	CacheUpdater
+	set, deleted []string
+}
+
+func (u *recordingCacheUpdater) SetReport(cluster, namespace, reportType, name string, report *Report) {
+	u.set = append(u.set, namespace+"/"+name)
+}
+
+func (u *recordingCacheUpdater) DeleteReport(cluster, namespace, reportType, name string) {
+	u.deleted = append(u.deleted, namespace+"/"+name)
+}
+
+func (u *recordingCacheUpdater) InvalidateReportDetail(cluster, namespace, reportType, name string) {}
+
+func TestReconcile_RepairsMissingAndOrphaned(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, name := range []string{"a", "b"} {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetNamespace("ns")
+		obj.SetName(name)
+		if err := store.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updater := &recordingCacheUpdater{}
+	m := NewReportInformerManager(nil, "c1", updater)
+	m.informers["vulnerabilityreports"] = &syncedInformer{store: store}
+
+	cached := []ReportID{
+		{Type: "vulnerabilityreports", Namespace: "ns", Name: "a"},
+		{Type: "vulnerabilityreports", Namespace: "ns", Name: "gone"},
+		{Type: "configauditreports", Namespace: "ns", Name: "unsynced"},
+	}
+	inCache := func(id ReportID) (CachedReport, bool) { return CachedReport{}, id.Name == "a" }
+	waits := 0
+	wait := func(context.Context) error { waits++; return nil }
+
+	result, err := m.Reconcile(context.Background(), cached, inCache, wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Missing) != 1 || result.Missing[0].Name != "b" || len(updater.set) != 1 {
+		t.Fatalf("expected b re-added, got %+v %v", result.Missing, updater.set)
+	}
+	if len(result.Orphaned) != 1 || result.Orphaned[0].Name != "gone" || len(updater.deleted) != 1 {
+		t.Fatalf("expected only gone deleted, got %+v %v", result.Orphaned, updater.deleted)
+	}
+	if waits != 2 {
+		t.Fatalf("expected one wait per repair, got %d", waits)
+	}
+}
+
+func TestReconcile_RefreshesStaleReports(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for name, version := range map[string]string{"current": "7", "stale": "9", "unversioned": "3"} {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetNamespace("ns")
+		obj.SetName(name)
+		obj.SetResourceVersion(version)
+		if err := store.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updater := &recordingCacheUpdater{}
+	m := NewReportInformerManager(nil, "c1", updater)
+	m.informers["vulnerabilityreports"] = &syncedInformer{store: store}
+
+	cachedVersions := map[string]string{"current": "7", "stale": "8", "unversioned": ""}
+	inCache := func(id ReportID) (CachedReport, bool) {
+		version, ok := cachedVersions[id.Name]
+		return CachedReport{ResourceVersion: version}, ok
+	}
+	result, err := m.Reconcile(context.Background(), nil, inCache, func(context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Stale) != 1 || result.Stale[0].Name != "stale" || len(updater.set) != 1 || updater.set[0] != "ns/stale" {
+		t.Fatalf("expected only stale refreshed, got %+v %v", result.Stale, updater.set)
+	}
+	if len(result.Missing) != 0 {
+		t.Fatalf("nothing is missing, got %+v", result.Missing)
+	}
+}
@@ -0,0 +1,117 @@
+package kubernetes
+
+import "strings"
+
+// PackageTypeOS groups the packages of every OS package manager (dpkg, rpm, apk, ...).
+const PackageTypeOS = "os-pkgs"
+
+// trivyPkgTypeProperty is the CycloneDX property Trivy records the package type in.
+const trivyPkgTypeProperty = "aquasecurity:trivy:PkgType"
+
+// osPkgTypes are the Trivy package types of OS packages; Trivy names them after the distro.
+var osPkgTypes = map[string]bool{
+	"alpine": true, "alma": true, "amazon": true, "azurelinux": true, "bottlerocket": true,
+	"cbl-mariner": true, "centos": true, "chainguard": true, "debian": true, "echo": true,
+	"fedora": true, "minimos": true, "opensuse": true, "opensuse.leap": true, "opensuse.tumbleweed": true,
+	"oracle": true, "photon": true, "redhat": true, "rocky": true, "sles": true, "suse": true,
+	"ubuntu": true, "wolfi": true,
+}
+
+// purlPkgTypes maps package URL types to the ecosystem names Trivy uses, for SBOMs
+// without Trivy's package type property.
+var purlPkgTypes = map[string]string{
+	"npm":       "npm",
+	"pypi":      "pip",
+	"golang":    "gomod",
+	"maven":     "jar",
+	"gem":       "gemspec",
+	"cargo":     "cargo",
+	"nuget":     "nuget",
+	"composer":  "composer",
+	"conan":     "conan",
+	"hex":       "hex",
+	"pub":       "pub",
+	"swift":     "swift",
+	"cocoapods": "cocoapods",
+	"deb":       PackageTypeOS,
+	"rpm":       PackageTypeOS,
+	"apk":       PackageTypeOS,
+}
+
+// componentPackageType returns the ecosystem of a CycloneDX component, or "" for
+// components that are not packages (the OS and application nodes Trivy adds).
+func componentPackageType(component map[string]interface{}) string {
+	if properties, ok := component["properties"].([]interface{}); ok {
+		for _, p := range properties {
+			pm, _ := p.(map[string]interface{})
+			if name, _ := pm["name"].(string); name != trivyPkgTypeProperty {
+				continue
+			}
+			if value, _ := pm["value"].(string); value != "" {
+				if osPkgTypes[value] {
+					return PackageTypeOS
+				}
+				return value
+			}
+		}
+	}
+	purl, _ := component["purl"].(string)
+	if !strings.HasPrefix(purl, "pkg:") {
+		return ""
+	}
+	purlType, _, _ := strings.Cut(strings.TrimPrefix(purl, "pkg:"), "/")
+	if t, ok := purlPkgTypes[purlType]; ok {
+		return t
+	}
+	return purlType
+}
+
+// compactPackageTypes counts the packages of an sbomreport's CycloneDX document per
+// ecosystem, so statistics survive stripping the components from the informer store.
+func compactPackageTypes(bom map[string]interface{}) map[string]interface{} {
+	counts := make(map[string]interface{})
+	components, _ := bom["components"].([]interface{})
+	for _, c := range components {
+		cm, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t := componentPackageType(cm); t != "" {
+			n, _ := counts[t].(int)
+			counts[t] = n + 1
+		}
+	}
+	return counts
+}
+
+// PackageTypeCounts reads the per-ecosystem package counts of an sbomreport object (its
+// data with a "report" key), from the compact index written by stripLargeFields or from
+// the full components. It returns nil for reports without an SBOM.
+func PackageTypeCounts(obj map[string]interface{}) map[string]int {
+	reportObj, ok := obj["report"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	index, ok := reportObj["packageTypes"].(map[string]interface{})
+	if !ok {
+		bom, ok := reportObj["components"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		index = compactPackageTypes(bom)
+	}
+
+	counts := make(map[string]int, len(index))
+	for t, v := range index {
+		// int in memory, float64 after a JSON round trip through the snapshot or an agent
+		switch n := v.(type) {
+		case int:
+			counts[t] = n
+		case int64:
+			counts[t] = int(n)
+		case float64:
+			counts[t] = int(n)
+		}
+	}
+	return counts
+}
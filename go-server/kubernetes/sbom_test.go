@@ -0,0 +1,50 @@
+package kubernetes
+
+import "testing"
+
+func TestComponentPackageType(t *testing.T) {
+	cases := []struct {
+		component map[string]interface{}
+		want      string
+	}{
+		{map[string]interface{}{"purl": "pkg:npm/lodash@4.17.21"}, "npm"},
+		{map[string]interface{}{"purl": "pkg:pypi/requests@2.31.0"}, "pip"},
+		{map[string]interface{}{"purl": "pkg:deb/debian/openssl@3.0.11"}, PackageTypeOS},
+		{map[string]interface{}{
+			"purl":       "pkg:golang/golang.org/x/net@v0.17.0",
+			"properties": []interface{}{map[string]interface{}{"name": "aquasecurity:trivy:PkgType", "value": "gobinary"}},
+		}, "gobinary"},
+		{map[string]interface{}{
+			"properties": []interface{}{map[string]interface{}{"name": "aquasecurity:trivy:PkgType", "value": "alpine"}},
+		}, PackageTypeOS},
+		// the OS node Trivy adds is not a package
+		{map[string]interface{}{"type": "operating-system", "name": "debian"}, ""},
+	}
+	for _, c := range cases {
+		if got := componentPackageType(c.component); got != c.want {
+			t.Errorf("%v: expected %q, got %q", c.component, c.want, got)
+		}
+	}
+}
+
+func TestPackageTypeCounts(t *testing.T) {
+	bom := map[string]interface{}{"components": []interface{}{
+		map[string]interface{}{"purl": "pkg:npm/a@1"},
+		map[string]interface{}{"purl": "pkg:npm/b@1"},
+		map[string]interface{}{"purl": "pkg:maven/org/c@1"},
+	}}
+	full := map[string]interface{}{"report": map[string]interface{}{"components": bom}}
+	counts := PackageTypeCounts(full)
+	if counts["npm"] != 2 || counts["jar"] != 1 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+
+	// the compact index survives a JSON round trip as float64
+	compact := map[string]interface{}{"report": map[string]interface{}{"packageTypes": map[string]interface{}{"npm": float64(2)}}}
+	if counts := PackageTypeCounts(compact); counts["npm"] != 2 {
+		t.Fatalf("unexpected compact counts %v", counts)
+	}
+	if PackageTypeCounts(map[string]interface{}{"report": map[string]interface{}{}}) != nil {
+		t.Fatal("expected nil for reports without SBOM")
+	}
+}
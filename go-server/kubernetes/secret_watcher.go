@@ -0,0 +1,160 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"trivy-ui/utils"
+)
+
+const (
+	// KubeconfigSecretLabel marks Secrets that register an additional cluster
+	KubeconfigSecretLabel = "trivy-ui.io/kubeconfig"
+	// ClusterNameAnnotation overrides the cluster name, which defaults to the Secret name
+	ClusterNameAnnotation = "trivy-ui.io/cluster-name"
+	kubeconfigSecretKey   = "kubeconfig"
+)
+
+// ClusterSecretHandler registers and removes the clusters discovered from Secrets.
+type ClusterSecretHandler interface {
+	AddCluster(name string, client *Client) error
+	RemoveCluster(name string)
+}
+
+type secretCluster struct {
+	name     string
+	checksum string
+}
+
+type kubeconfigSecretWatcher struct {
+	handler      ClusterSecretHandler
+	clientConfig ClientConfig
+
+	mu sync.Mutex
+	// clusters maps namespace/name of each Secret to the cluster it registered
+	clusters map[string]secretCluster
+}
+
+// WatchKubeconfigSecrets registers a cluster for every Secret labeled
+// trivy-ui.io/kubeconfig=true in namespace, so clusters can be added with GitOps instead
+// of mounting files. Changed kubeconfigs re-register the cluster; deleted or unlabeled
+// Secrets remove it. Watching stops when ctx is done.
+func WatchKubeconfigSecrets(ctx context.Context, c *Client, namespace string, handler ClusterSecretHandler) {
+	w := &kubeconfigSecretWatcher{
+		handler:      handler,
+		clientConfig: DefaultClientConfig(),
+		clusters:     make(map[string]secretCluster),
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(c.clientset, informerResyncPeriod,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = KubeconfigSecretLabel + "=true"
+		}),
+	)
+	informer := factory.Core().V1().Secrets().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.onSecret(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			w.onSecret(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if secret, ok := obj.(*corev1.Secret); ok {
+				w.remove(secret.Namespace + "/" + secret.Name)
+			}
+		},
+	})
+	factory.Start(ctx.Done())
+
+	utils.LogInfo("Watching kubeconfig Secrets", map[string]interface{}{
+		"namespace": namespace,
+		"selector":  KubeconfigSecretLabel + "=true",
+	})
+}
+
+func (w *kubeconfigSecretWatcher) onSecret(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	key := secret.Namespace + "/" + secret.Name
+	name, data, err := ClusterFromSecret(secret)
+	if err != nil {
+		utils.LogWarning("Ignoring kubeconfig Secret", map[string]interface{}{"secret": key, "error": err.Error()})
+		w.remove(key)
+		return
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if prev, ok := w.clusters[key]; ok {
+		if prev.name == name && prev.checksum == checksum {
+			return
+		}
+		w.handler.RemoveCluster(prev.name)
+		delete(w.clusters, key)
+	}
+
+	client, err := NewClientFromKubeconfig(data, w.clientConfig)
+	if err != nil {
+		utils.LogWarning("Invalid kubeconfig in Secret", map[string]interface{}{"secret": key, "error": err.Error()})
+		return
+	}
+	if err := w.handler.AddCluster(name, client); err != nil {
+		utils.LogWarning("Failed to register cluster from Secret", map[string]interface{}{
+			"secret":  key,
+			"cluster": name,
+			"error":   err.Error(),
+		})
+		return
+	}
+	w.clusters[key] = secretCluster{name: name, checksum: checksum}
+	utils.LogInfo("Registered cluster from Secret", map[string]interface{}{"secret": key, "cluster": name})
+}
+
+func (w *kubeconfigSecretWatcher) remove(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prev, ok := w.clusters[key]
+	if !ok {
+		return
+	}
+	delete(w.clusters, key)
+	w.handler.RemoveCluster(prev.name)
+	utils.LogInfo("Removed cluster from Secret", map[string]interface{}{"secret": key, "cluster": prev.name})
+}
+
+// ClusterFromSecret returns the cluster name and kubeconfig held by a Secret. The
+// kubeconfig is read from the "kubeconfig" key, or the only key when there is one.
+func ClusterFromSecret(secret *corev1.Secret) (string, []byte, error) {
+	name := secret.Annotations[ClusterNameAnnotation]
+	if name == "" {
+		name = secret.Name
+	}
+
+	data, ok := secret.Data[kubeconfigSecretKey]
+	if !ok && len(secret.Data) == 1 {
+		for _, v := range secret.Data {
+			data = v
+		}
+	}
+	if len(data) == 0 {
+		return "", nil, fmt.Errorf("no %q key in secret", kubeconfigSecretKey)
+	}
+	return name, data, nil
+}
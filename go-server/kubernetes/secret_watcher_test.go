@@ -0,0 +1,39 @@
+package kubernetes
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterFromSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod-eu"},
+		Data:       map[string][]byte{"kubeconfig": []byte("cfg"), "ca.crt": []byte("ca")},
+	}
+	name, data, err := ClusterFromSecret(secret)
+	if err != nil || name != "prod-eu" || string(data) != "cfg" {
+		t.Fatalf("unexpected %q %q %v", name, data, err)
+	}
+
+	secret.Annotations = map[string]string{ClusterNameAnnotation: "eu-1"}
+	if name, _, _ := ClusterFromSecret(secret); name != "eu-1" {
+		t.Fatalf("expected annotation name, got %q", name)
+	}
+}
+
+func TestClusterFromSecret_SingleKey(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "staging"},
+		Data:       map[string][]byte{"config": []byte("cfg")},
+	}
+	if _, data, err := ClusterFromSecret(secret); err != nil || string(data) != "cfg" {
+		t.Fatalf("unexpected %q %v", data, err)
+	}
+
+	secret.Data["other"] = []byte("x")
+	if _, _, err := ClusterFromSecret(secret); err == nil {
+		t.Fatal("expected error when the kubeconfig key is ambiguous")
+	}
+}
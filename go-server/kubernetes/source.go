@@ -0,0 +1,208 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"trivy-ui/config"
+)
+
+// ReportSource is where a cluster's reports come from. Client reads them from the
+// Kubernetes API; MemorySource holds them in memory, for tests and for sources that load
+// reports from elsewhere such as report files.
+type ReportSource interface {
+	ListReports(ctx context.Context, reportType config.ReportKind, namespace string) ([]unstructured.Unstructured, error)
+	GetReportDetails(ctx context.Context, reportType config.ReportKind, namespace, name string) (*Report, error)
+	GetNamespaces(ctx context.Context) ([]string, error)
+	// Watch sends every report to updater, then keeps it current until StopWatch.
+	Watch(clusterName string, updater CacheUpdater) error
+	StopWatch()
+}
+
+var (
+	_ ReportSource = (*Client)(nil)
+	_ ReportSource = (*MemorySource)(nil)
+)
+
+// Watch starts the report informers.
+func (c *Client) Watch(clusterName string, updater CacheUpdater) error {
+	return c.StartInformer(clusterName, updater)
+}
+
+func (c *Client) StopWatch() {
+	c.StopInformer()
+}
+
+type sourceReport struct {
+	kind config.ReportKind
+	obj  *unstructured.Unstructured
+}
+
+// MemorySource serves reports held in memory. Changes made with Put and Remove reach
+// watchers the way informer events do.
+type MemorySource struct {
+	mu         sync.RWMutex
+	reports    map[string]sourceReport
+	namespaces map[string]bool
+	watchers   []*ReportInformerManager
+}
+
+func NewMemorySource() *MemorySource {
+	return &MemorySource{
+		reports:    make(map[string]sourceReport),
+		namespaces: make(map[string]bool),
+	}
+}
+
+func sourceKey(reportType, namespace, name string) string {
+	return reportType + "/" + namespace + "/" + name
+}
+
+// Put adds or replaces a report; its namespace is added to the source's namespaces.
+func (s *MemorySource) Put(reportType config.ReportKind, obj *unstructured.Unstructured) {
+	key := sourceKey(reportType.Name, obj.GetNamespace(), obj.GetName())
+	s.mu.Lock()
+	old, existed := s.reports[key]
+	s.reports[key] = sourceReport{kind: reportType, obj: obj}
+	if ns := obj.GetNamespace(); ns != "" {
+		s.namespaces[ns] = true
+	}
+	watchers := append([]*ReportInformerManager(nil), s.watchers...)
+	s.mu.Unlock()
+
+	if !includedNamespace(obj) {
+		return
+	}
+	for _, w := range watchers {
+		if existed {
+			w.onUpdate(reportType, old.obj, obj)
+		} else {
+			w.onAdd(reportType, obj)
+		}
+	}
+}
+
+// Remove deletes a report and reports whether it existed.
+func (s *MemorySource) Remove(reportType config.ReportKind, namespace, name string) bool {
+	key := sourceKey(reportType.Name, namespace, name)
+	s.mu.Lock()
+	old, existed := s.reports[key]
+	delete(s.reports, key)
+	watchers := append([]*ReportInformerManager(nil), s.watchers...)
+	s.mu.Unlock()
+
+	if !existed || !includedNamespace(old.obj) {
+		return existed
+	}
+	for _, w := range watchers {
+		w.onDelete(reportType, old.obj)
+	}
+	return true
+}
+
+// AddNamespace records a namespace that has no reports yet.
+func (s *MemorySource) AddNamespace(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.namespaces[namespace] = true
+}
+
+func (s *MemorySource) ListReports(ctx context.Context, reportType config.ReportKind, namespace string) ([]unstructured.Unstructured, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if namespace != "" && !reportType.Namespaced {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cfg := config.Get()
+	var items []unstructured.Unstructured
+	for _, r := range s.reports {
+		if r.kind.Name != reportType.Name || (namespace != "" && r.obj.GetNamespace() != namespace) {
+			continue
+		}
+		if cfg.NamespaceExcluded(r.obj.GetNamespace()) {
+			continue
+		}
+		items = append(items, *r.obj.DeepCopy())
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+	return items, nil
+}
+
+func (s *MemorySource) GetReportDetails(ctx context.Context, reportType config.ReportKind, namespace, name string) (*Report, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	r, ok := s.reports[sourceKey(reportType.Name, namespace, name)]
+	s.mu.RUnlock()
+	if !ok {
+		// the Kubernetes API's error, so callers tell a missing report from a failure alike
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: reportType.Name}, name)
+	}
+	obj := r.obj.DeepCopy().Object
+	return &Report{
+		Type:      reportType.Name,
+		Namespace: namespace,
+		Name:      name,
+		Status:    ExtractSummary(reportType.Name, obj).Status,
+		Data:      obj,
+		ScannedAt: extractScannedAt(obj),
+
+		UID:             string(r.obj.GetUID()),
+		ResourceVersion: r.obj.GetResourceVersion(),
+	}, nil
+}
+
+func (s *MemorySource) GetNamespaces(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	namespaces := make([]string, 0, len(s.namespaces))
+	for ns := range s.namespaces {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// Watch replays the current reports to updater as adds and forwards later changes.
+func (s *MemorySource) Watch(clusterName string, updater CacheUpdater) error {
+	w := &ReportInformerManager{clusterName: clusterName, cacheUpdater: updater}
+	s.mu.Lock()
+	s.watchers = append(s.watchers, w)
+	current := make([]sourceReport, 0, len(s.reports))
+	for _, r := range s.reports {
+		current = append(current, r)
+	}
+	s.mu.Unlock()
+
+	updater.UpdateSyncState(clusterName, "Syncing")
+	for _, r := range current {
+		if includedNamespace(r.obj) {
+			w.onAdd(r.kind, r.obj)
+		}
+	}
+	updater.UpdateSyncState(clusterName, "FullySynced")
+	return nil
+}
+
+func (s *MemorySource) StopWatch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchers = nil
+}
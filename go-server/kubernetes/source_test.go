@@ -0,0 +1,164 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"trivy-ui/config"
+)
+
+// eventRecorder records the CacheUpdater calls a source makes.
+type eventRecorder struct {
+	events []string
+}
+
+func (r *eventRecorder) SetReport(cluster, namespace, reportType, name string, report *Report) {
+	r.events = append(r.events, "set "+cluster+" "+namespace+"/"+name+" "+report.Status)
+}
+
+func (r *eventRecorder) DeleteReport(cluster, namespace, reportType, name string) {
+	r.events = append(r.events, "delete "+cluster+" "+namespace+"/"+name)
+}
+
+func (r *eventRecorder) DeleteNamespace(cluster, namespace string) {
+	r.events = append(r.events, "delete namespace "+cluster+" "+namespace)
+}
+
+func (r *eventRecorder) InvalidateReportDetail(cluster, namespace, reportType, name string) {}
+
+func (r *eventRecorder) UpdateSyncState(clusterName string, state string) {
+	r.events = append(r.events, "state "+state)
+}
+
+var vulnKind = config.ReportKind{Name: "vulnerabilityreports", Kind: "VulnerabilityReport", APIVersion: "aquasecurity.github.io/v1alpha1", Namespaced: true}
+
+func vulnReport(namespace, name string, critical float64) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "aquasecurity.github.io/v1alpha1",
+		"kind":       "VulnerabilityReport",
+		"report":     map[string]interface{}{"summary": makeSummary(critical, 1, 0, 0, 0)},
+	}}
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestMemorySourceWatch(t *testing.T) {
+	src := NewMemorySource()
+	src.Put(vulnKind, vulnReport("ns", "a", 1))
+
+	rec := &eventRecorder{}
+	if err := src.Watch("lab", rec); err != nil {
+		t.Fatal(err)
+	}
+	src.Put(vulnKind, vulnReport("ns", "a", 0))
+	src.Put(vulnKind, vulnReport("other", "b", 0))
+	if !src.Remove(vulnKind, "ns", "a") {
+		t.Fatal("Remove of an existing report returned false")
+	}
+	src.StopWatch()
+	src.Put(vulnKind, vulnReport("ns", "c", 0))
+
+	want := []string{
+		"state Syncing",
+		"set lab ns/a Critical",
+		"state FullySynced",
+		"set lab ns/a High",
+		"set lab other/b High",
+		"delete lab ns/a",
+	}
+	if !reflect.DeepEqual(rec.events, want) {
+		t.Fatalf("events = %q\nwant %q", rec.events, want)
+	}
+}
+
+func TestMemorySourceReads(t *testing.T) {
+	ctx := t.Context()
+	src := NewMemorySource()
+	src.Put(vulnKind, vulnReport("ns", "b", 0))
+	src.Put(vulnKind, vulnReport("ns", "a", 2))
+	src.AddNamespace("empty")
+
+	items, err := src.ListReports(ctx, vulnKind, "ns")
+	if err != nil || len(items) != 2 || items[0].GetName() != "a" {
+		t.Fatalf("ListReports = %v, %v", items, err)
+	}
+	report, err := src.GetReportDetails(ctx, vulnKind, "ns", "a")
+	if err != nil || report.Status != "Critical" {
+		t.Fatalf("GetReportDetails = %+v, %v", report, err)
+	}
+	if _, err := src.GetReportDetails(ctx, vulnKind, "ns", "missing"); err == nil {
+		t.Fatal("expected an error for a missing report")
+	}
+	namespaces, err := src.GetNamespaces(ctx)
+	if err != nil || !reflect.DeepEqual(namespaces, []string{"empty", "ns"}) {
+		t.Fatalf("GetNamespaces = %v, %v", namespaces, err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := src.ListReports(canceled, vulnKind, ""); err == nil {
+		t.Fatal("expected a canceled context to fail")
+	}
+}
+
+func TestLoadReportFiles(t *testing.T) {
+	dir := t.TempDir()
+	list := `{"apiVersion": "v1", "kind": "List", "items": [
+		{"apiVersion": "aquasecurity.github.io/v1alpha1", "kind": "VulnerabilityReport",
+		 "metadata": {"name": "a", "namespace": "web"}, "report": {"summary": {"criticalCount": 1}}},
+		{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "ignored", "namespace": "web"}}
+	]}`
+	docs := `apiVersion: aquasecurity.github.io/v1alpha1
+kind: ClusterComplianceReport
+metadata:
+  name: cis
+---
+apiVersion: aquasecurity.github.io/v1alpha1
+kind: VulnerabilityReport
+metadata:
+  name: b
+  namespace: db
+report:
+  summary:
+    highCount: 2
+`
+	for name, content := range map[string]string{"vulns.json": list, "more.yaml": docs, "notes.txt": "skipped"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src, kinds, err := LoadReportFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]config.ReportKind)
+	for _, k := range kinds {
+		byName[k.Name] = k
+	}
+	if len(byName) != 2 || !byName["vulnerabilityreports"].Namespaced || byName["clustercompliancereports"].Namespaced {
+		t.Fatalf("kinds = %+v", kinds)
+	}
+
+	namespaces, _ := src.GetNamespaces(t.Context())
+	if !reflect.DeepEqual(namespaces, []string{"db", "web"}) {
+		t.Fatalf("namespaces = %v", namespaces)
+	}
+	report, err := src.GetReportDetails(t.Context(), byName["vulnerabilityreports"], "db", "b")
+	if err != nil || report.Status != "High" {
+		t.Fatalf("report b = %+v, %v", report, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadReportFiles(dir); err == nil {
+		t.Fatal("expected an error for a malformed file")
+	}
+}
@@ -0,0 +1,244 @@
+package kubernetes
+
+import (
+	"strings"
+	"sync"
+)
+
+// Report statuses beyond the severities, for kinds whose summary is not a severity count.
+const (
+	StatusUnknown = "Unknown"
+	StatusNone    = "None"
+	StatusPass    = "Pass"
+	StatusFail    = "Fail"
+)
+
+// severityLevels are the summary count keys in the order they decide a report's status.
+var severityLevels = []struct{ key, status string }{
+	{"criticalCount", "Critical"},
+	{"highCount", "High"},
+	{"mediumCount", "Medium"},
+	{"lowCount", "Low"},
+}
+
+// ReportSummary is what the list views need of a report besides its metadata.
+type ReportSummary struct {
+	// Status is the worst severity found, Pass or Fail for compliance reports, or Unknown
+	// when the report carries no summary
+	Status string
+	// HasFindings counts the report among those "with vulnerabilities"
+	HasFindings bool
+	// Counts is kept as report.summary in the cached data
+	Counts map[string]interface{}
+}
+
+// SummaryExtractor reads the summary of one family of report kinds. It is given the
+// report object as served by the API server, or as cached, whose summary is under
+// report.summary for every kind.
+type SummaryExtractor interface {
+	Extract(obj map[string]interface{}) ReportSummary
+}
+
+// SummaryExtractorFunc adapts a function to SummaryExtractor.
+type SummaryExtractorFunc func(obj map[string]interface{}) ReportSummary
+
+func (f SummaryExtractorFunc) Extract(obj map[string]interface{}) ReportSummary {
+	return f(obj)
+}
+
+var (
+	extractorsMu sync.RWMutex
+	// extractors maps report kind names (the CRD plural) to their extractor; kinds not
+	// listed are read as severity counts
+	extractors = map[string]SummaryExtractor{
+		"vulnerabilityreports":          SummaryExtractorFunc(extractVulnerabilitySummary),
+		"clustervulnerabilityreports":   SummaryExtractorFunc(extractVulnerabilitySummary),
+		"configauditreports":            SummaryExtractorFunc(extractCheckSummary),
+		"clusterconfigauditreports":     SummaryExtractorFunc(extractCheckSummary),
+		"rbacassessmentreports":         SummaryExtractorFunc(extractCheckSummary),
+		"clusterrbacassessmentreports":  SummaryExtractorFunc(extractCheckSummary),
+		"infraassessmentreports":        SummaryExtractorFunc(extractCheckSummary),
+		"clusterinfraassessmentreports": SummaryExtractorFunc(extractCheckSummary),
+		"exposedsecretreports":          SummaryExtractorFunc(extractSecretSummary),
+		"clustercompliancereports":      SummaryExtractorFunc(extractComplianceSummary),
+		"sbomreports":                   SummaryExtractorFunc(extractSbomSummary),
+		"clustersbomreports":            SummaryExtractorFunc(extractSbomSummary),
+	}
+)
+
+// RegisterSummaryExtractor sets the extractor of a report kind, replacing the default.
+func RegisterSummaryExtractor(kind string, e SummaryExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors[kind] = e
+}
+
+// ExtractSummary reads the summary of a report of the given kind.
+func ExtractSummary(kind string, obj map[string]interface{}) ReportSummary {
+	extractorsMu.RLock()
+	e, ok := extractors[kind]
+	extractorsMu.RUnlock()
+	if !ok {
+		e = SummaryExtractorFunc(extractSeveritySummary)
+	}
+	return e.Extract(obj)
+}
+
+// extractSeveritySummary reads report.summary as severity counts, the layout most Trivy
+// reports share.
+func extractSeveritySummary(obj map[string]interface{}) ReportSummary {
+	summary, ok := reportSummary(obj)
+	if !ok {
+		return ReportSummary{Status: StatusUnknown}
+	}
+	return severitySummary(summary)
+}
+
+// extractVulnerabilitySummary also counts the vulnerabilities themselves when the
+// summary is missing, as in reports written by older operators.
+func extractVulnerabilitySummary(obj map[string]interface{}) ReportSummary {
+	if summary, ok := reportSummary(obj); ok {
+		result := severitySummary(summary)
+		// vulnerabilities of unknown severity only do not make a report clean
+		if result.Status == StatusNone && count(summary["noneCount"]) == 0 && count(summary["unknownCount"]) > 0 {
+			result.Status = StatusUnknown
+		}
+		return result
+	}
+	reportObj, _ := obj["report"].(map[string]interface{})
+	items, ok := reportObj["findings"].([]interface{})
+	if !ok {
+		items, ok = reportObj["vulnerabilities"].([]interface{})
+	}
+	if !ok {
+		return ReportSummary{Status: StatusUnknown}
+	}
+	return severitySummary(countSeverities(items, nil))
+}
+
+// extractCheckSummary reads config audit, RBAC and infra assessments, counting failed
+// checks when there is no summary.
+func extractCheckSummary(obj map[string]interface{}) ReportSummary {
+	if summary, ok := reportSummary(obj); ok {
+		return severitySummary(summary)
+	}
+	reportObj, _ := obj["report"].(map[string]interface{})
+	checks, ok := reportObj["checks"].([]interface{})
+	if !ok {
+		return ReportSummary{Status: StatusUnknown}
+	}
+	return severitySummary(countSeverities(checks, func(check map[string]interface{}) bool {
+		success, _ := check["success"].(bool)
+		return !success
+	}))
+}
+
+// extractSecretSummary reads exposed secret reports, counting the secrets when there is
+// no summary.
+func extractSecretSummary(obj map[string]interface{}) ReportSummary {
+	if summary, ok := reportSummary(obj); ok {
+		return severitySummary(summary)
+	}
+	reportObj, _ := obj["report"].(map[string]interface{})
+	secrets, ok := reportObj["secrets"].([]interface{})
+	if !ok {
+		return ReportSummary{Status: StatusUnknown}
+	}
+	return severitySummary(countSeverities(secrets, nil))
+}
+
+// extractComplianceSummary reads the pass and fail counts of a cluster compliance report,
+// which the operator writes to status.summary.
+func extractComplianceSummary(obj map[string]interface{}) ReportSummary {
+	summary, ok := nestedMap(obj, "status", "summary")
+	if !ok {
+		if summary, ok = reportSummary(obj); !ok {
+			return ReportSummary{Status: StatusUnknown}
+		}
+	}
+	fail, pass := count(summary["failCount"]), count(summary["passCount"])
+	result := ReportSummary{
+		Status:      StatusUnknown,
+		HasFindings: fail > 0,
+		Counts:      map[string]interface{}{"failCount": fail, "passCount": pass},
+	}
+	switch {
+	case fail > 0:
+		result.Status = StatusFail
+	case pass > 0:
+		result.Status = StatusPass
+	}
+	return result
+}
+
+// extractSbomSummary reads the component counts of an SBOM, which has no findings.
+func extractSbomSummary(obj map[string]interface{}) ReportSummary {
+	summary, ok := reportSummary(obj)
+	if !ok {
+		return ReportSummary{Status: StatusUnknown}
+	}
+	return ReportSummary{Status: StatusNone, Counts: summary}
+}
+
+// severitySummary derives the status from severity counts: the worst severity with a
+// non-zero count, or None when the report found nothing.
+func severitySummary(summary map[string]interface{}) ReportSummary {
+	result := ReportSummary{Status: StatusNone, Counts: summary}
+	for _, level := range severityLevels {
+		if count(summary[level.key]) > 0 {
+			if !result.HasFindings {
+				result.Status = level.status
+			}
+			result.HasFindings = true
+		}
+	}
+	return result
+}
+
+// countSeverities builds severity counts from report entries with a severity field,
+// skipping those keep rejects.
+func countSeverities(items []interface{}, keep func(map[string]interface{}) bool) map[string]interface{} {
+	counts := map[string]float64{}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok || (keep != nil && !keep(m)) {
+			continue
+		}
+		severity, _ := m["severity"].(string)
+		counts[strings.ToLower(severity)+"Count"]++
+	}
+	summary := make(map[string]interface{}, len(severityLevels))
+	for _, level := range severityLevels {
+		summary[level.key] = counts[level.key]
+	}
+	return summary
+}
+
+func reportSummary(obj map[string]interface{}) (map[string]interface{}, bool) {
+	return nestedMap(obj, "report", "summary")
+}
+
+func nestedMap(obj map[string]interface{}, keys ...string) (map[string]interface{}, bool) {
+	m := obj
+	for _, key := range keys {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		m = next
+	}
+	return m, true
+}
+
+// count reads a summary count, which is a float64 once decoded from JSON.
+func count(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
@@ -0,0 +1,137 @@
+package kubernetes
+
+import (
+	"testing"
+)
+
+func TestExtractSummaryByKind(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        string
+		obj         map[string]interface{}
+		status      string
+		hasFindings bool
+	}{
+		{
+			name:   "clean vulnerability report",
+			kind:   "vulnerabilityreports",
+			obj:    makeObj(map[string]interface{}{"summary": makeSummary(0, 0, 0, 0, 0)}),
+			status: StatusNone,
+		},
+		{
+			name:   "vulnerabilities of unknown severity only",
+			kind:   "vulnerabilityreports",
+			obj:    makeObj(map[string]interface{}{"summary": map[string]interface{}{"criticalCount": float64(0), "unknownCount": float64(2)}}),
+			status: StatusUnknown,
+		},
+		{
+			name: "vulnerability report without summary",
+			kind: "clustervulnerabilityreports",
+			obj: makeObj(map[string]interface{}{"vulnerabilities": []interface{}{
+				map[string]interface{}{"vulnerabilityID": "CVE-1", "severity": "MEDIUM"},
+				map[string]interface{}{"vulnerabilityID": "CVE-2", "severity": "HIGH"},
+			}}),
+			status:      "High",
+			hasFindings: true,
+		},
+		{
+			name:        "config audit counts",
+			kind:        "configauditreports",
+			obj:         makeObj(map[string]interface{}{"summary": map[string]interface{}{"criticalCount": float64(0), "highCount": float64(0), "mediumCount": float64(3), "lowCount": float64(1)}}),
+			status:      "Medium",
+			hasFindings: true,
+		},
+		{
+			name: "rbac assessment counted from failed checks",
+			kind: "clusterrbacassessmentreports",
+			obj: makeObj(map[string]interface{}{"checks": []interface{}{
+				map[string]interface{}{"checkID": "KSV041", "severity": "CRITICAL", "success": true},
+				map[string]interface{}{"checkID": "KSV044", "severity": "LOW", "success": false},
+			}}),
+			status:      "Low",
+			hasFindings: true,
+		},
+		{
+			name:   "passing infra assessment",
+			kind:   "infraassessmentreports",
+			obj:    makeObj(map[string]interface{}{"summary": map[string]interface{}{"criticalCount": float64(0), "highCount": float64(0), "mediumCount": float64(0), "lowCount": float64(0)}}),
+			status: StatusNone,
+		},
+		{
+			name: "exposed secrets counted from secrets",
+			kind: "exposedsecretreports",
+			obj: makeObj(map[string]interface{}{"secrets": []interface{}{
+				map[string]interface{}{"ruleID": "aws-access-key-id", "severity": "CRITICAL"},
+			}}),
+			status:      "Critical",
+			hasFindings: true,
+		},
+		{
+			name:        "failing compliance report",
+			kind:        "clustercompliancereports",
+			obj:         map[string]interface{}{"status": map[string]interface{}{"summary": map[string]interface{}{"failCount": float64(4), "passCount": float64(20)}}},
+			status:      StatusFail,
+			hasFindings: true,
+		},
+		{
+			name:   "passing compliance report as cached",
+			kind:   "clustercompliancereports",
+			obj:    makeObj(map[string]interface{}{"summary": map[string]interface{}{"failCount": float64(0), "passCount": float64(20)}}),
+			status: StatusPass,
+		},
+		{
+			name:   "sbom has no findings",
+			kind:   "sbomreports",
+			obj:    makeObj(map[string]interface{}{"summary": map[string]interface{}{"componentsCount": float64(120), "dependenciesCount": float64(80)}}),
+			status: StatusNone,
+		},
+		{
+			name:        "unregistered kind reads severity counts",
+			kind:        "customreports",
+			obj:         makeObj(map[string]interface{}{"summary": map[string]interface{}{"highCount": 2}}),
+			status:      "High",
+			hasFindings: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractSummary(tt.kind, tt.obj)
+			if got.Status != tt.status || got.HasFindings != tt.hasFindings {
+				t.Fatalf("got status %s hasFindings %v, want %s %v", got.Status, got.HasFindings, tt.status, tt.hasFindings)
+			}
+		})
+	}
+}
+
+func TestRegisterSummaryExtractor(t *testing.T) {
+	kind := "testreports"
+	t.Cleanup(func() {
+		extractorsMu.Lock()
+		delete(extractors, kind)
+		extractorsMu.Unlock()
+	})
+	RegisterSummaryExtractor(kind, SummaryExtractorFunc(func(obj map[string]interface{}) ReportSummary {
+		return ReportSummary{Status: StatusPass}
+	}))
+	if got := ExtractSummary(kind, nil).Status; got != StatusPass {
+		t.Fatalf("registered extractor not used, got %s", got)
+	}
+}
+
+func TestComplianceSummaryIsCached(t *testing.T) {
+	kind := vulnKind
+	kind.Name, kind.Kind = "clustercompliancereports", "ClusterComplianceReport"
+	obj := vulnReport("", "cis", 0)
+	obj.Object["status"] = map[string]interface{}{"summary": map[string]interface{}{"failCount": float64(3), "passCount": float64(7)}}
+	delete(obj.Object, "report")
+
+	report := newManager().convertToReport(kind, obj)
+	if report.Status != StatusFail {
+		t.Fatalf("status = %s", report.Status)
+	}
+	data := report.Data.(map[string]interface{})
+	summary, _ := nestedMap(data, "report", "summary")
+	if summary["failCount"] != float64(3) || summary["passCount"] != float64(7) {
+		t.Fatalf("cached summary = %v", summary)
+	}
+}
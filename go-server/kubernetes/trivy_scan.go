@@ -0,0 +1,242 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"trivy-ui/config"
+)
+
+// TrivyScanner scans images on demand with the trivy CLI in client mode: the CLI pulls
+// the image and lists its packages, the trivy server at ServerURL matches them against
+// its vulnerability DB, so the server needs no access to the image's registry.
+type TrivyScanner struct {
+	// Binary is the trivy executable, looked up in PATH when it has no directory
+	Binary    string
+	ServerURL string
+	// Token is the server's --token; it is passed through the environment, not the
+	// command line
+	Token string
+}
+
+// trivyScanResult is the part of trivy's JSON output a VulnerabilityReport is built from.
+type trivyScanResult struct {
+	ArtifactName string    `json:"ArtifactName"`
+	CreatedAt    time.Time `json:"CreatedAt"`
+	Trivy        struct {
+		Version string `json:"Version"`
+	} `json:"Trivy"`
+	Metadata struct {
+		OS *struct {
+			Family string `json:"Family"`
+			Name   string `json:"Name"`
+			EOSL   bool   `json:"EOSL"`
+		} `json:"OS"`
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []struct {
+		Target          string                   `json:"Target"`
+		Class           string                   `json:"Class"`
+		Type            string                   `json:"Type"`
+		Vulnerabilities []map[string]interface{} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// ScanImage scans an image and returns the result as a VulnerabilityReport object named
+// name in namespace, shaped like the operator's so it is cached and shown like one.
+func (s TrivyScanner) ScanImage(ctx context.Context, image, namespace, name string) (*unstructured.Unstructured, error) {
+	if s.ServerURL == "" {
+		return nil, errors.New("no trivy server configured")
+	}
+	binary := s.Binary
+	if binary == "" {
+		binary = "trivy"
+	}
+	cmd := exec.CommandContext(ctx, binary, "image",
+		"--server", s.ServerURL,
+		"--scanners", "vuln",
+		"--format", "json",
+		"--quiet",
+		"--", image)
+	cmd.Env = os.Environ()
+	if s.Token != "" {
+		cmd.Env = append(cmd.Env, "TRIVY_TOKEN="+s.Token)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("scan of %s: %w", image, ctx.Err())
+		}
+		return nil, fmt.Errorf("scan of %s: %w: %s", image, err, lastLine(stderr.String()))
+	}
+
+	var result trivyScanResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("scan of %s: invalid trivy output: %w", image, err)
+	}
+	if result.ArtifactName == "" {
+		result.ArtifactName = image
+	}
+	return vulnerabilityReportFromScan(result, namespace, name, time.Now()), nil
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// vulnerabilityReportFromScan converts trivy's output to the operator's VulnerabilityReport
+// layout: lower-camel vulnerability fields, the package as resource and the artifact
+// split into registry, repository, tag and digest.
+func vulnerabilityReportFromScan(result trivyScanResult, namespace, name string, now time.Time) *unstructured.Unstructured {
+	severities := map[string]int64{}
+	vulns := make([]interface{}, 0)
+	for _, r := range result.Results {
+		for _, v := range r.Vulnerabilities {
+			vuln := map[string]interface{}{
+				"vulnerabilityID":  v["VulnerabilityID"],
+				"resource":         v["PkgName"],
+				"installedVersion": v["InstalledVersion"],
+				"fixedVersion":     stringOr(v["FixedVersion"], ""),
+				"severity":         stringOr(v["Severity"], "UNKNOWN"),
+				"title":            stringOr(v["Title"], ""),
+				"primaryLink":      stringOr(v["PrimaryURL"], ""),
+				"target":           r.Target,
+				"class":            r.Class,
+				"packageType":      r.Type,
+			}
+			for from, to := range map[string]string{
+				"PkgPath":          "pkgPath",
+				"Status":           "status",
+				"PublishedDate":    "publishedDate",
+				"LastModifiedDate": "lastModifiedDate",
+				"Description":      "description",
+				"References":       "links",
+			} {
+				if value, ok := v[from]; ok {
+					vuln[to] = value
+				}
+			}
+			if cvss, ok := v["CVSS"].(map[string]interface{}); ok {
+				vuln["cvss"] = cvss
+				if score, ok := cvssScore(cvss); ok {
+					vuln["score"] = score
+				}
+			}
+			severities[vuln["severity"].(string)]++
+			vulns = append(vulns, vuln)
+		}
+	}
+
+	registry, repository, tag, digest := parseImageRef(result.ArtifactName)
+	if digest == "" && len(result.Metadata.RepoDigests) > 0 {
+		_, digest, _ = strings.Cut(result.Metadata.RepoDigests[0], "@")
+	}
+	scannedAt := result.CreatedAt
+	if scannedAt.IsZero() {
+		scannedAt = now
+	}
+	report := map[string]interface{}{
+		"updateTimestamp": scannedAt.UTC().Format(time.RFC3339),
+		"scanner": map[string]interface{}{
+			"name":    "Trivy",
+			"vendor":  "Aqua Security",
+			"version": result.Trivy.Version,
+		},
+		"registry": map[string]interface{}{"server": registry},
+		"artifact": map[string]interface{}{
+			"repository": repository,
+			"tag":        tag,
+			"digest":     digest,
+		},
+		"summary": map[string]interface{}{
+			"criticalCount": severities["CRITICAL"],
+			"highCount":     severities["HIGH"],
+			"mediumCount":   severities["MEDIUM"],
+			"lowCount":      severities["LOW"],
+			"unknownCount":  severities["UNKNOWN"],
+			"noneCount":     int64(0),
+		},
+		"vulnerabilities": vulns,
+	}
+	if osInfo := result.Metadata.OS; osInfo != nil {
+		report["os"] = map[string]interface{}{"family": osInfo.Family, "name": osInfo.Name, "eosl": osInfo.EOSL}
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"report": report}}
+	obj.SetAPIVersion(config.TrivyGroup + "/" + config.DefaultAPIVersion)
+	obj.SetKind("VulnerabilityReport")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetCreationTimestamp(metav1.NewTime(now))
+	obj.SetAnnotations(map[string]string{"trivy-ui/image": result.ArtifactName})
+	// round-trip through JSON so numbers are float64, as in objects read from the API
+	raw, _ := json.Marshal(obj.Object)
+	var normalized map[string]interface{}
+	json.Unmarshal(raw, &normalized)
+	obj.Object = normalized
+	return obj
+}
+
+func stringOr(v interface{}, fallback string) string {
+	if s, ok := v.(string); ok && s != "" {
+		return s
+	}
+	return fallback
+}
+
+// cvssScore is the V3 score of the NVD entry, or of the first vendor that has one.
+func cvssScore(sources map[string]interface{}) (float64, bool) {
+	scoreOf := func(source interface{}) (float64, bool) {
+		m, _ := source.(map[string]interface{})
+		score, ok := m["V3Score"].(float64)
+		return score, ok
+	}
+	if score, ok := scoreOf(sources["nvd"]); ok {
+		return score, true
+	}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if score, ok := scoreOf(sources[name]); ok {
+			return score, true
+		}
+	}
+	return 0, false
+}
+
+// parseImageRef splits an image reference like the operator does, defaulting to Docker
+// Hub and its library/ namespace.
+func parseImageRef(image string) (registry, repository, tag, digest string) {
+	ref, digest, _ := strings.Cut(image, "@")
+	if i := strings.LastIndexByte(ref, ':'); i > strings.LastIndexByte(ref, '/') {
+		ref, tag = ref[:i], ref[i+1:]
+	}
+	registry = "index.docker.io"
+	if first, rest, ok := strings.Cut(ref, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry, ref = first, rest
+	} else if !strings.Contains(ref, "/") {
+		ref = "library/" + ref
+	}
+	if tag == "" && digest == "" {
+		tag = "latest"
+	}
+	return registry, ref, tag, digest
+}
@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const trivyScanOutput = `{
+  "ArtifactName": "nginx:1.27",
+  "CreatedAt": "2026-10-01T12:00:00Z",
+  "Trivy": {"Version": "0.56.2"},
+  "Metadata": {
+    "OS": {"Family": "debian", "Name": "12.7"},
+    "RepoDigests": ["nginx@sha256:abc"]
+  },
+  "Results": [{
+    "Target": "nginx:1.27 (debian 12.7)",
+    "Class": "os-pkgs",
+    "Type": "debian",
+    "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-1", "PkgName": "openssl", "InstalledVersion": "3.0.1", "FixedVersion": "3.0.2",
+       "Severity": "CRITICAL", "CVSS": {"ghsa": {"V3Score": 7.5}, "nvd": {"V3Score": 9.8, "V3Vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}},
+      {"VulnerabilityID": "CVE-2024-2", "PkgName": "zlib", "InstalledVersion": "1.2", "Severity": "LOW"}
+    ]
+  }]
+}`
+
+// fakeTrivy writes a trivy stand-in that records its arguments and token and prints output.
+func fakeTrivy(t *testing.T, output string) (binary, argsFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	os.WriteFile(filepath.Join(dir, "out.json"), []byte(output), 0644)
+	script := "#!/bin/sh\necho \"$* token=$TRIVY_TOKEN\" > " + argsFile + "\ncat " + filepath.Join(dir, "out.json") + "\n"
+	binary = filepath.Join(dir, "trivy")
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return binary, argsFile
+}
+
+func TestTrivyScannerScanImage(t *testing.T) {
+	binary, argsFile := fakeTrivy(t, trivyScanOutput)
+	s := TrivyScanner{Binary: binary, ServerURL: "http://trivy:4954", Token: "secret"}
+	obj, err := s.ScanImage(context.Background(), "nginx:1.27", "default", "nginx-1.27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if got := string(args); !strings.Contains(got, "image --server http://trivy:4954") || !strings.HasSuffix(strings.TrimSpace(got), "-- nginx:1.27 token=secret") {
+		t.Errorf("unexpected trivy invocation %q", got)
+	}
+
+	if obj.GetKind() != "VulnerabilityReport" || obj.GetNamespace() != "default" || obj.GetName() != "nginx-1.27" {
+		t.Errorf("unexpected object %s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+	summary := ExtractSummary("vulnerabilityreports", obj.Object)
+	if summary.Counts["criticalCount"] != 1.0 || summary.Counts["lowCount"] != 1.0 || !summary.HasFindings {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	findings := ExtractFindings(obj.Object)
+	if len(findings) != 2 || findings[0].Resource != "openssl" || findings[0].Score != 9.8 || findings[0].CVSSVector == "" {
+		t.Errorf("unexpected findings %+v", findings)
+	}
+	artifact := obj.Object["report"].(map[string]interface{})["artifact"].(map[string]interface{})
+	if artifact["repository"] != "library/nginx" || artifact["tag"] != "1.27" || artifact["digest"] != "sha256:abc" {
+		t.Errorf("unexpected artifact %v", artifact)
+	}
+	if got := extractScannedAt(obj.Object); got.Format("2006-01-02") != "2026-10-01" {
+		t.Errorf("unexpected scan time %v", got)
+	}
+}
+
+func TestTrivyScannerFailure(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "trivy")
+	os.WriteFile(binary, []byte("#!/bin/sh\necho 'pulling' >&2\necho 'FATAL unable to find the image' >&2\nexit 1\n"), 0755)
+	_, err := TrivyScanner{Binary: binary, ServerURL: "http://trivy:4954"}.ScanImage(context.Background(), "missing:1", "default", "missing-1")
+	if err == nil || !strings.HasSuffix(err.Error(), "FATAL unable to find the image") {
+		t.Errorf("expected the last stderr line in the error, got %v", err)
+	}
+}
+
+func TestParseImageRef(t *testing.T) {
+	cases := []struct {
+		image                             string
+		registry, repository, tag, digest string
+	}{
+		{"nginx", "index.docker.io", "library/nginx", "latest", ""},
+		{"bitnami/redis:7", "index.docker.io", "bitnami/redis", "7", ""},
+		{"ghcr.io/org/app:v1", "ghcr.io", "org/app", "v1", ""},
+		{"localhost:5000/app@sha256:abc", "localhost:5000", "app", "", "sha256:abc"},
+	}
+	for _, c := range cases {
+		registry, repository, tag, digest := parseImageRef(c.image)
+		if registry != c.registry || repository != c.repository || tag != c.tag || digest != c.digest {
+			t.Errorf("%s: got %s %s %s %s", c.image, registry, repository, tag, digest)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// trivyConfigMapName is the ConfigMap the trivy-operator chart keeps Trivy's settings in.
+const trivyConfigMapName = "trivy-operator-trivy-config"
+
+// scanJobSelector matches the scan Jobs trivy-operator creates.
+const scanJobSelector = "app.kubernetes.io/managed-by=trivy-operator"
+
+// Keys describing the vulnerability DB. trivy.dbRepository is a standard operator setting;
+// the version and update time are read from the same keys as scan Job annotations (set
+// through the operator's scanJob.annotations) or ConfigMap entries written by a DB mirror.
+const (
+	TrivyDBRepositoryKey = "trivy.dbRepository"
+	TrivyDBVersionKey    = "trivy.dbVersion"
+	TrivyDBUpdatedAtKey  = "trivy.dbUpdatedAt"
+)
+
+// Where the DB metadata was read from.
+const (
+	TrivyDBSourceScanJob   = "scanJob"
+	TrivyDBSourceConfigMap = "configMap"
+)
+
+// TrivyDB describes the vulnerability DB trivy-operator scans with.
+type TrivyDB struct {
+	// Found is false when neither scan Jobs nor the ConfigMap carry a version or update time
+	Found      bool       `json:"found"`
+	Source     string     `json:"source,omitempty"`
+	Repository string     `json:"repository,omitempty"`
+	Version    string     `json:"version,omitempty"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
+
+// TrivyDBFromValues reads DB metadata from ConfigMap data or Job annotations.
+func TrivyDBFromValues(source string, values map[string]string) TrivyDB {
+	db := TrivyDB{Repository: values[TrivyDBRepositoryKey], Version: values[TrivyDBVersionKey]}
+	if raw := values[TrivyDBUpdatedAtKey]; raw != "" {
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			db.UpdatedAt = &t
+		}
+	}
+	if db.Version != "" || db.UpdatedAt != nil {
+		db.Found = true
+		db.Source = source
+	}
+	return db
+}
+
+// newestAnnotatedJob returns the most recently created Job carrying DB metadata.
+func newestAnnotatedJob(jobs []batchv1.Job) (TrivyDB, bool) {
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreationTimestamp.After(jobs[j].CreationTimestamp.Time)
+	})
+	for _, job := range jobs {
+		if db := TrivyDBFromValues(TrivyDBSourceScanJob, job.Annotations); db.Found {
+			return db, true
+		}
+	}
+	return TrivyDB{}, false
+}
+
+// GetTrivyDB reads the DB metadata in the operator's namespace. Scan Jobs reflect the DB a
+// scan actually used and win over the ConfigMap, which still supplies the repository.
+func (c *Client) GetTrivyDB(ctx context.Context, namespace string) (TrivyDB, error) {
+	var fromConfig TrivyDB
+	cm, err := c.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, trivyConfigMapName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return TrivyDB{}, err
+	}
+	if err == nil {
+		fromConfig = TrivyDBFromValues(TrivyDBSourceConfigMap, cm.Data)
+	}
+
+	jobs, err := c.clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: scanJobSelector})
+	if err != nil {
+		return TrivyDB{}, err
+	}
+	if db, ok := newestAnnotatedJob(jobs.Items); ok {
+		if db.Repository == "" {
+			db.Repository = fromConfig.Repository
+		}
+		return db, nil
+	}
+	return fromConfig, nil
+}
@@ -0,0 +1,52 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTrivyDBFromValues(t *testing.T) {
+	db := TrivyDBFromValues(TrivyDBSourceConfigMap, map[string]string{
+		TrivyDBRepositoryKey: "mirror.gcr.io/aquasec/trivy-db:2",
+		TrivyDBVersionKey:    "2",
+		TrivyDBUpdatedAtKey:  "2026-10-10T06:12:00Z",
+	})
+	if !db.Found || db.Source != TrivyDBSourceConfigMap || db.Version != "2" {
+		t.Fatalf("unexpected db %+v", db)
+	}
+	if db.UpdatedAt == nil || !db.UpdatedAt.Equal(time.Date(2026, 10, 10, 6, 12, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected updatedAt %v", db.UpdatedAt)
+	}
+
+	// the repository alone says nothing about the DB's age
+	db = TrivyDBFromValues(TrivyDBSourceConfigMap, map[string]string{TrivyDBRepositoryKey: "ghcr.io/aquasecurity/trivy-db:2"})
+	if db.Found || db.Repository == "" {
+		t.Fatalf("unexpected db %+v", db)
+	}
+}
+
+func TestNewestAnnotatedJob(t *testing.T) {
+	job := func(name string, created time.Time, updatedAt string) batchv1.Job {
+		j := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)}}
+		if updatedAt != "" {
+			j.Annotations = map[string]string{TrivyDBUpdatedAtKey: updatedAt}
+		}
+		return j
+	}
+	now := time.Now()
+	jobs := []batchv1.Job{
+		job("old", now.Add(-2*time.Hour), "2026-10-01T00:00:00Z"),
+		job("newest-unannotated", now, ""),
+		job("recent", now.Add(-time.Hour), "2026-10-09T00:00:00Z"),
+	}
+	db, ok := newestAnnotatedJob(jobs)
+	if !ok || db.Source != TrivyDBSourceScanJob || db.UpdatedAt.Day() != 9 {
+		t.Fatalf("expected the recent job's metadata, got %+v", db)
+	}
+	if _, ok := newestAnnotatedJob([]batchv1.Job{job("plain", now, "")}); ok {
+		t.Fatal("expected no metadata without annotations")
+	}
+}
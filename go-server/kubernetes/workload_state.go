@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Workload run states.
+const (
+	// WorkloadRunning: the workload has ready pods
+	WorkloadRunning = "running"
+	// WorkloadScheduled: a CronJob that is not suspended, between two runs
+	WorkloadScheduled    = "scheduled"
+	WorkloadScaledToZero = "scaled-to-zero"
+	WorkloadSuspended    = "suspended"
+	// WorkloadNotReady: pods are wanted but none is ready, e.g. crash looping or pending
+	WorkloadNotReady  = "not-ready"
+	WorkloadCompleted = "completed"
+)
+
+// WorkloadState is whether a workload currently runs pods. The ReplicaSets a Deployment
+// rolled away from are scaled to zero, so their reports are dormant too.
+type WorkloadState struct {
+	State     string `json:"state"`
+	ReadyPods int32  `json:"readyPods"`
+}
+
+// Active reports whether the workload runs code now or on its next schedule.
+func (s WorkloadState) Active() bool {
+	return s.State == WorkloadRunning || s.State == WorkloadScheduled
+}
+
+func replicasState(desired *int32, ready int32) WorkloadState {
+	switch {
+	case ready > 0:
+		return WorkloadState{State: WorkloadRunning, ReadyPods: ready}
+	case desired != nil && *desired == 0:
+		return WorkloadState{State: WorkloadScaledToZero}
+	}
+	return WorkloadState{State: WorkloadNotReady}
+}
+
+// ReplicaSetState is the run state of a ReplicaSet.
+func ReplicaSetState(rs appsv1.ReplicaSet) WorkloadState {
+	return replicasState(rs.Spec.Replicas, rs.Status.ReadyReplicas)
+}
+
+// StatefulSetState is the run state of a StatefulSet.
+func StatefulSetState(sts appsv1.StatefulSet) WorkloadState {
+	return replicasState(sts.Spec.Replicas, sts.Status.ReadyReplicas)
+}
+
+// DaemonSetState is the run state of a DaemonSet; one scheduled on no node counts as
+// scaled to zero.
+func DaemonSetState(ds appsv1.DaemonSet) WorkloadState {
+	desired := ds.Status.DesiredNumberScheduled
+	return replicasState(&desired, ds.Status.NumberReady)
+}
+
+// CronJobState is the run state of a CronJob: running while a job is active, otherwise
+// scheduled unless suspended.
+func CronJobState(cj batchv1.CronJob) WorkloadState {
+	switch {
+	case len(cj.Status.Active) > 0:
+		return WorkloadState{State: WorkloadRunning}
+	case cj.Spec.Suspend != nil && *cj.Spec.Suspend:
+		return WorkloadState{State: WorkloadSuspended}
+	}
+	return WorkloadState{State: WorkloadScheduled}
+}
+
+// JobState is the run state of a Job.
+func JobState(job batchv1.Job) WorkloadState {
+	switch {
+	case job.Status.Ready != nil && *job.Status.Ready > 0:
+		return WorkloadState{State: WorkloadRunning, ReadyPods: *job.Status.Ready}
+	case job.Spec.Suspend != nil && *job.Spec.Suspend:
+		return WorkloadState{State: WorkloadSuspended}
+	case job.Status.CompletionTime != nil || (job.Status.Failed > 0 && job.Status.Active == 0):
+		return WorkloadState{State: WorkloadCompleted}
+	}
+	return WorkloadState{State: WorkloadNotReady}
+}
+
+// PodState is the run state of a bare pod.
+func PodState(pod corev1.Pod) WorkloadState {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded, corev1.PodFailed:
+		return WorkloadState{State: WorkloadCompleted}
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+			return WorkloadState{State: WorkloadRunning, ReadyPods: 1}
+		}
+	}
+	return WorkloadState{State: WorkloadNotReady}
+}
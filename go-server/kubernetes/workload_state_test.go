@@ -0,0 +1,41 @@
+package kubernetes
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkloadStates(t *testing.T) {
+	zero, two, yes := int32(0), int32(2), true
+	now := metav1.Now()
+
+	cases := []struct {
+		name  string
+		state WorkloadState
+		want  string
+	}{
+		{"replicaset ready", ReplicaSetState(appsv1.ReplicaSet{Spec: appsv1.ReplicaSetSpec{Replicas: &two}, Status: appsv1.ReplicaSetStatus{ReadyReplicas: 2}}), WorkloadRunning},
+		{"old replicaset", ReplicaSetState(appsv1.ReplicaSet{Spec: appsv1.ReplicaSetSpec{Replicas: &zero}}), WorkloadScaledToZero},
+		{"crash looping", StatefulSetState(appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Replicas: &two}}), WorkloadNotReady},
+		{"daemonset on no node", DaemonSetState(appsv1.DaemonSet{}), WorkloadScaledToZero},
+		{"cronjob", CronJobState(batchv1.CronJob{}), WorkloadScheduled},
+		{"suspended cronjob", CronJobState(batchv1.CronJob{Spec: batchv1.CronJobSpec{Suspend: &yes}}), WorkloadSuspended},
+		{"cronjob mid-run", CronJobState(batchv1.CronJob{Spec: batchv1.CronJobSpec{Suspend: &yes}, Status: batchv1.CronJobStatus{Active: []corev1.ObjectReference{{Name: "run-1"}}}}), WorkloadRunning},
+		{"finished job", JobState(batchv1.Job{Status: batchv1.JobStatus{CompletionTime: &now}}), WorkloadCompleted},
+		{"running job", JobState(batchv1.Job{Status: batchv1.JobStatus{Ready: &two}}), WorkloadRunning},
+		{"ready pod", PodState(corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}}), WorkloadRunning},
+		{"succeeded pod", PodState(corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}}), WorkloadCompleted},
+	}
+	for _, c := range cases {
+		if c.state.State != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, c.state.State, c.want)
+		}
+	}
+	if !(WorkloadState{State: WorkloadScheduled}).Active() || (WorkloadState{State: WorkloadScaledToZero}).Active() {
+		t.Error("only running and scheduled workloads are active")
+	}
+}
@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Workload is what the UI reads from a workload trivy-operator scans.
+type Workload struct {
+	Annotations map[string]string
+	Exposure    Exposure
+	State       WorkloadState
+}
+
+// forEachWorkload calls fn with every workload trivy-operator scans: the kinds it names in
+// its reports, and bare pods. Kinds that cannot be listed are skipped and reported in the
+// error.
+func (c *Client) forEachWorkload(ctx context.Context, fn func(kind string, meta metav1.ObjectMeta, spec corev1.PodSpec, state WorkloadState)) error {
+	var errs []error
+	opts := metav1.ListOptions{}
+	all := metav1.NamespaceAll
+
+	if list, err := c.clientset.AppsV1().ReplicaSets(all).List(ctx, opts); err != nil {
+		errs = append(errs, err)
+	} else {
+		for _, w := range list.Items {
+			fn("ReplicaSet", w.ObjectMeta, w.Spec.Template.Spec, ReplicaSetState(w))
+		}
+	}
+	if list, err := c.clientset.AppsV1().StatefulSets(all).List(ctx, opts); err != nil {
+		errs = append(errs, err)
+	} else {
+		for _, w := range list.Items {
+			fn("StatefulSet", w.ObjectMeta, w.Spec.Template.Spec, StatefulSetState(w))
+		}
+	}
+	if list, err := c.clientset.AppsV1().DaemonSets(all).List(ctx, opts); err != nil {
+		errs = append(errs, err)
+	} else {
+		for _, w := range list.Items {
+			fn("DaemonSet", w.ObjectMeta, w.Spec.Template.Spec, DaemonSetState(w))
+		}
+	}
+	if list, err := c.clientset.BatchV1().CronJobs(all).List(ctx, opts); err != nil {
+		errs = append(errs, err)
+	} else {
+		for _, w := range list.Items {
+			fn("CronJob", w.ObjectMeta, w.Spec.JobTemplate.Spec.Template.Spec, CronJobState(w))
+		}
+	}
+	if list, err := c.clientset.BatchV1().Jobs(all).List(ctx, opts); err != nil {
+		errs = append(errs, err)
+	} else {
+		for _, w := range list.Items {
+			fn("Job", w.ObjectMeta, w.Spec.Template.Spec, JobState(w))
+		}
+	}
+	if list, err := c.clientset.CoreV1().Pods(all).List(ctx, opts); err != nil {
+		errs = append(errs, err)
+	} else {
+		for _, w := range list.Items {
+			// the operator reports on a pod's controller; only bare pods have their own reports
+			if metav1.GetControllerOf(&w) == nil {
+				fn("Pod", w.ObjectMeta, w.Spec, PodState(w))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetWorkloads reads every workload trivy-operator scans with one list per kind, keyed by
+// WorkloadKey. The ReplicaSets of a Deployment carry the Deployment's annotations, which
+// the deployment controller copies to them.
+func (c *Client) GetWorkloads(ctx context.Context) (map[string]Workload, error) {
+	result := make(map[string]Workload)
+	err := c.forEachWorkload(ctx, func(kind string, meta metav1.ObjectMeta, spec corev1.PodSpec, state WorkloadState) {
+		result[WorkloadKey(kind, meta.Namespace, meta.Name)] = Workload{
+			Annotations: meta.Annotations,
+			Exposure:    PodSpecExposure(spec),
+			State:       state,
+		}
+	})
+	return result, err
+}
@@ -0,0 +1,270 @@
+// Package loadgen generates synthetic clusters and vulnerability reports shaped like the
+// ones the informers produce, so load tests and benchmarks can fill the cache without
+// real clusters. Output is deterministic for a given Options value.
+package loadgen
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"trivy-ui/agent"
+	"trivy-ui/config"
+	"trivy-ui/kubernetes"
+)
+
+// ReportType is the only report kind generated.
+const ReportType = "vulnerabilityreports"
+
+// Default sizes, small enough for a laptop and large enough to show index costs.
+const (
+	DefaultClusters          = 10
+	DefaultReportsPerCluster = 500
+	DefaultNamespaces        = 20
+	DefaultFindingsPerReport = 40
+)
+
+// cvePoolSize is the number of distinct vulnerabilities reports draw from; sharing them
+// across reports and clusters gives the dedupe and finding indexes realistic overlap.
+const cvePoolSize = 2000
+
+var (
+	severities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"}
+	statuses   = []string{"Critical", "High", "Medium", "Low"}
+	// severityWeights skews findings towards the lower severities, as in real scans
+	severityWeights = []int{5, 20, 40, 35}
+	packages        = []string{"openssl", "libc6", "zlib1g", "curl", "libxml2", "busybox", "glibc", "ncurses", "golang.org/x/net", "github.com/gin-gonic/gin"}
+	containers      = []string{"app", "sidecar", "init"}
+	workloadKinds   = []string{"ReplicaSet", "StatefulSet", "DaemonSet", "Job"}
+)
+
+type Options struct {
+	Clusters          int
+	ReportsPerCluster int
+	// Namespaces is the number of namespaces per cluster reports are spread over
+	Namespaces int
+	// FindingsPerReport is the maximum; each report gets between none and this many
+	FindingsPerReport int
+	Seed              int64
+	// Now anchors scan and creation timestamps; zero means time.Now()
+	Now time.Time
+}
+
+func (o Options) withDefaults() Options {
+	if o.Clusters <= 0 {
+		o.Clusters = DefaultClusters
+	}
+	if o.ReportsPerCluster <= 0 {
+		o.ReportsPerCluster = DefaultReportsPerCluster
+	}
+	if o.Namespaces <= 0 {
+		o.Namespaces = DefaultNamespaces
+	}
+	if o.FindingsPerReport < 0 {
+		o.FindingsPerReport = 0
+	} else if o.FindingsPerReport == 0 {
+		o.FindingsPerReport = DefaultFindingsPerReport
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+	return o
+}
+
+// ClusterName is the name of the i-th synthetic cluster.
+func ClusterName(i int) string {
+	return fmt.Sprintf("loadgen-%03d", i)
+}
+
+// Namespace is the name of the i-th synthetic namespace of every cluster.
+func Namespace(i int) string {
+	return fmt.Sprintf("ns-%03d", i)
+}
+
+// Kinds are the report kinds generated reports belong to.
+func Kinds() []config.ReportKind {
+	return []config.ReportKind{{
+		Name:       ReportType,
+		ShortName:  "vulnerabilityreport",
+		APIVersion: config.TrivyGroup + "/" + config.DefaultAPIVersion,
+		Namespaced: true,
+		Kind:       "VulnerabilityReport",
+	}}
+}
+
+// Namespaces lists the namespaces of every synthetic cluster.
+func Namespaces(opts Options) []string {
+	opts = opts.withDefaults()
+	names := make([]string, opts.Namespaces)
+	for i := range names {
+		names[i] = Namespace(i)
+	}
+	return names
+}
+
+// Reports generates the reports of the i-th cluster in the form the informer converts
+// them to: summary data plus compact findings.
+func Reports(opts Options, cluster int) []*kubernetes.Report {
+	opts = opts.withDefaults()
+	rng := rand.New(rand.NewSource(opts.Seed*1_000_003 + int64(cluster)))
+	name := ClusterName(cluster)
+
+	reports := make([]*kubernetes.Report, opts.ReportsPerCluster)
+	for i := range reports {
+		reports[i] = generateReport(rng, opts, name, i)
+	}
+	return reports
+}
+
+func generateReport(rng *rand.Rand, opts Options, cluster string, i int) *kubernetes.Report {
+	namespace := Namespace(i % opts.Namespaces)
+	kind := workloadKinds[rng.Intn(len(workloadKinds))]
+	workload := fmt.Sprintf("app-%05d", i)
+	container := containers[rng.Intn(len(containers))]
+	name := fmt.Sprintf("%s-%s-%s", strings.ToLower(kind), workload, container)
+	scannedAt := opts.Now.Add(-time.Duration(rng.Int63n(int64(7 * 24 * time.Hour)))).UTC().Truncate(time.Second)
+	createdAt := scannedAt.Add(-time.Duration(rng.Int63n(int64(30 * 24 * time.Hour))))
+
+	findings := make([]kubernetes.Finding, 0, opts.FindingsPerReport)
+	seen := make(map[int]bool)
+	var counts [4]int
+	for n := rng.Intn(opts.FindingsPerReport + 1); n > 0; n-- {
+		cve := rng.Intn(cvePoolSize)
+		if seen[cve] {
+			continue
+		}
+		seen[cve] = true
+		sev := pickSeverity(cve)
+		counts[sev]++
+		f := kubernetes.Finding{
+			VulnerabilityID:  fmt.Sprintf("CVE-%d-%05d", 2018+cve%8, cve),
+			Severity:         severities[sev],
+			Resource:         packages[cve%len(packages)],
+			InstalledVersion: fmt.Sprintf("1.%d.%d", cve%10, cve%7),
+			Score:            float64(9-2*sev) + float64(cve%10)/10,
+			Target:           fmt.Sprintf("docker.io/library/%s (debian 12.5)", workload),
+		}
+		// most, not all, vulnerabilities have a fix
+		if cve%5 != 0 {
+			f.FixedVersion = fmt.Sprintf("1.%d.%d", cve%10, cve%7+1)
+		}
+		findings = append(findings, f)
+	}
+
+	status := "None"
+	for sev, c := range counts {
+		if c > 0 {
+			status = statuses[sev]
+			break
+		}
+	}
+
+	data := map[string]interface{}{
+		"apiVersion": config.TrivyGroup + "/" + config.DefaultAPIVersion,
+		"kind":       "VulnerabilityReport",
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"namespace":         namespace,
+			"uid":               fmt.Sprintf("%08x-0000-4000-8000-%012x", rng.Uint32(), i),
+			"creationTimestamp": createdAt.Format(time.RFC3339),
+			"labels": map[string]interface{}{
+				"trivy-operator.resource.kind":      kind,
+				"trivy-operator.resource.name":      workload,
+				"trivy-operator.resource.namespace": namespace,
+				"trivy-operator.container.name":     container,
+			},
+		},
+		"report": map[string]interface{}{
+			"summary": map[string]interface{}{
+				"criticalCount": float64(counts[0]),
+				"highCount":     float64(counts[1]),
+				"mediumCount":   float64(counts[2]),
+				"lowCount":      float64(counts[3]),
+				"unknownCount":  float64(0),
+			},
+			"artifact": map[string]interface{}{
+				"repository": "library/" + workload,
+				"tag":        fmt.Sprintf("1.%d.%d", rng.Intn(5), rng.Intn(20)),
+				"digest":     fmt.Sprintf("sha256:%064x", rng.Uint64()),
+			},
+			"registry":        map[string]interface{}{"server": "docker.io"},
+			"os":              map[string]interface{}{"family": "debian", "name": "12.5"},
+			"scanner":         map[string]interface{}{"name": "Trivy", "vendor": "Aqua Security", "version": "0.50.1"},
+			"updateTimestamp": scannedAt.Format(time.RFC3339),
+		},
+	}
+
+	return &kubernetes.Report{
+		Type:      ReportType,
+		Cluster:   cluster,
+		Namespace: namespace,
+		Name:      name,
+		Status:    status,
+		Data:      data,
+		Findings:  findings,
+		ScannedAt: scannedAt,
+	}
+}
+
+// pickSeverity gives a vulnerability the same severity wherever it appears.
+func pickSeverity(cve int) int {
+	total := 0
+	for _, w := range severityWeights {
+		total += w
+	}
+	n := cve * 7919 % total
+	for sev, w := range severityWeights {
+		if n < w {
+			return sev
+		}
+		n -= w
+	}
+	return len(severityWeights) - 1
+}
+
+// Events converts reports to the agent events an informer adding them would produce.
+func Events(reports []*kubernetes.Report) []agent.Event {
+	events := make([]agent.Event, 0, len(reports))
+	for _, r := range reports {
+		events = append(events, agent.Event{
+			Op:        agent.OpSet,
+			Namespace: r.Namespace,
+			Type:      r.Type,
+			Name:      r.Name,
+			Status:    r.Status,
+			Data:      r.Data,
+			Findings:  r.Findings,
+			ScannedAt: r.ScannedAt,
+		})
+	}
+	return events
+}
+
+// Batches splits the i-th cluster's events into push batches of at most maxEvents each,
+// followed by a batch marking the cluster synced.
+func Batches(opts Options, cluster, maxEvents int) []agent.Batch {
+	opts = opts.withDefaults()
+	if maxEvents <= 0 {
+		maxEvents = 500
+	}
+	name := ClusterName(cluster)
+	newBatch := func(events []agent.Event) agent.Batch {
+		return agent.Batch{
+			Cluster:    name,
+			Version:    "v1.30.2",
+			Namespaces: Namespaces(opts),
+			Kinds:      Kinds(),
+			Events:     events,
+		}
+	}
+
+	events := Events(Reports(opts, cluster))
+	var batches []agent.Batch
+	for len(events) > 0 {
+		n := min(maxEvents, len(events))
+		batches = append(batches, newBatch(events[:n]))
+		events = events[n:]
+	}
+	return append(batches, newBatch([]agent.Event{{Op: agent.OpSyncState, State: "FullySynced"}}))
+}
@@ -0,0 +1,79 @@
+package loadgen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"trivy-ui/agent"
+)
+
+var testNow = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func TestReportsAreDeterministic(t *testing.T) {
+	opts := Options{ReportsPerCluster: 50, Seed: 7, Now: testNow}
+	if !reflect.DeepEqual(Reports(opts, 3), Reports(opts, 3)) {
+		t.Fatal("same options and cluster produced different reports")
+	}
+	if reflect.DeepEqual(Reports(opts, 3), Reports(opts, 4)) {
+		t.Fatal("different clusters produced identical reports")
+	}
+}
+
+func TestReportSummaryMatchesFindings(t *testing.T) {
+	reports := Reports(Options{ReportsPerCluster: 200, Namespaces: 5, Now: testNow}, 0)
+	names := make(map[string]bool)
+	for _, r := range reports {
+		key := r.Namespace + "/" + r.Name
+		if names[key] {
+			t.Fatalf("duplicate report %s", key)
+		}
+		names[key] = true
+
+		counts := make(map[string]float64)
+		for _, f := range r.Findings {
+			counts[strings.ToLower(f.Severity)+"Count"]++
+		}
+		summary := r.Data.(map[string]interface{})["report"].(map[string]interface{})["summary"].(map[string]interface{})
+		for _, key := range []string{"criticalCount", "highCount", "mediumCount", "lowCount"} {
+			if summary[key] != counts[key] {
+				t.Fatalf("%s: %s = %v, findings have %v", r.Name, key, summary[key], counts[key])
+			}
+		}
+		if len(r.Findings) == 0 && r.Status != "None" {
+			t.Fatalf("%s: status %s without findings", r.Name, r.Status)
+		}
+	}
+}
+
+func TestBatches(t *testing.T) {
+	opts := Options{ReportsPerCluster: 120, Now: testNow}
+	batches := Batches(opts, 1, 100)
+
+	// 120 set events in batches of 100, then the sync batch
+	if len(batches) != 3 {
+		t.Fatalf("got %d batches, want 3", len(batches))
+	}
+	sets := 0
+	for _, b := range batches {
+		if b.Cluster != ClusterName(1) {
+			t.Fatalf("batch for cluster %q", b.Cluster)
+		}
+		if len(b.Events) > 100 {
+			t.Fatalf("batch with %d events", len(b.Events))
+		}
+		for _, e := range b.Events {
+			if e.Op == agent.OpSet {
+				sets++
+			}
+		}
+	}
+	if sets != 120 {
+		t.Fatalf("got %d set events, want 120", sets)
+	}
+	last := batches[len(batches)-1].Events
+	if len(last) != 1 || last[0].Op != agent.OpSyncState {
+		t.Fatalf("last batch = %+v, want a sync event", last)
+	}
+}
@@ -3,6 +3,11 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -17,14 +22,22 @@ import (
 
 	"trivy-ui/api"
 	"trivy-ui/config"
+	"trivy-ui/credentials"
 	_ "trivy-ui/docs"
 	"trivy-ui/kubernetes"
+	"trivy-ui/store"
 	"trivy-ui/utils"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 func main() {
+	if code, ok := runSubcommand(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+	selftest := flag.Bool("selftest", false, "check every configured subsystem, print a JSON report and exit (non-zero on failure)")
+	flag.Parse()
+
 	cfg := config.Get()
 	utils.LogInfo("Server starting", map[string]interface{}{
 		"version":    GetVersion(),
@@ -34,12 +47,23 @@ func main() {
 		"log_level":  os.Getenv("LOG_LEVEL"),
 	})
 
+	credentials.Init(cfg.CredentialsDir)
+
 	if err := api.LoadCache(); err != nil {
 		utils.LogWarning("Failed to load cache", map[string]interface{}{"error": err.Error()})
 	}
 
+	if err := store.Init(cfg.DBPath); err != nil {
+		utils.LogWarning("Failed to open database, persistence features disabled", map[string]interface{}{"path": cfg.DBPath, "error": err.Error()})
+	}
+
+	if cfg.AgentCAFile != "" && cfg.TLSCertFile == "" {
+		utils.LogWarning("AGENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE, agent client certificates will not be verified", nil)
+	}
+
 	cacheSvc := api.NewCacheServiceImpl()
 	clusterRegistry := api.InitDefaultRegistry(cacheSvc)
+	api.LoadReportDirs(clusterRegistry, config.GetGlobalRegistry(), cfg.ReportDirs)
 
 	hasCache := api.HasCacheData()
 	if hasCache {
@@ -52,16 +76,7 @@ func main() {
 	clients := make(map[string]*kubernetes.Client)
 
 	// 支持通过目录批量加载 kubeconfig
-	kubeconfigDir := os.Getenv("KUBECONFIG_DIR")
-	if kubeconfigDir == "" {
-		kubeconfigDir = os.Getenv("KUBECONFIGDIR") // 兼容另一种写法
-	}
-	if kubeconfigDir == "" {
-		kubeconfigDir = os.Getenv("KUBE_CONFIG_DIR") // 兼容另一种写法
-	}
-	if kubeconfigDir == "" {
-		kubeconfigDir = "/kubeconfigs"
-	}
+	kubeconfigDir := kubeconfigDir()
 
 	type clusterInfo struct{ Name, Kubeconfig string }
 	var clustersToInit []clusterInfo
@@ -141,7 +156,9 @@ func main() {
 			return nil
 		}
 
-		if err := api.SetClusterClient(c.Name, k8sClient); err != nil {
+		if err := api.SetClusterClient(c.Name, k8sClient); errors.Is(err, api.ErrDuplicateCluster) {
+			return nil
+		} else if err != nil {
 			utils.LogWarning("Failed to set cluster client", map[string]interface{}{"cluster": c.Name, "error": err.Error()})
 		}
 
@@ -159,9 +176,17 @@ func main() {
 		registry := config.GetGlobalRegistry()
 
 		if len(clustersToInit) == 0 {
+			// file and API ingest run without clusters; readiness checks their pipeline
+			api.SetWarmupCompleted()
 			return
 		}
 
+		expected := make([]string, len(clustersToInit))
+		for i, c := range clustersToInit {
+			expected[i] = c.Name
+		}
+		api.ExpectClusters(expected)
+
 		first := clustersToInit[0]
 		firstClient, err := kubernetes.NewClient(first.Kubeconfig)
 		if err != nil {
@@ -171,7 +196,7 @@ func main() {
 			restConfig, _ := clientcmd.BuildConfigFromFlags("", first.Kubeconfig)
 			if restConfig != nil {
 				utils.LogInfo("Discovering Trivy Operator CRDs")
-				if err := registry.DiscoverCRDs(restConfig); err != nil {
+				if err := registry.DiscoverCRDs(context.Background(), restConfig); err != nil {
 					utils.LogWarning("Failed to discover CRDs", map[string]interface{}{
 						"error":   err.Error(),
 						"message": "Will retry in background. Make sure Trivy Operator is installed.",
@@ -183,7 +208,7 @@ func main() {
 						for range ticker.C {
 							retryCount++
 							utils.LogDebug("Retrying CRD discovery", map[string]interface{}{"attempt": retryCount})
-							if err := registry.DiscoverCRDs(cfg); err == nil {
+							if err := registry.DiscoverCRDs(context.Background(), cfg); err == nil {
 								reports := registry.GetAllReports()
 								utils.LogInfo("Successfully discovered CRDs on retry", map[string]interface{}{
 									"attempt": retryCount,
@@ -241,6 +266,16 @@ func main() {
 			wg.Wait()
 		}
 
+		// GitOps-managed clusters: kubeconfig Secrets in our own namespace
+		if inCluster, ok := clients["incluster"]; ok && cfg.KubeconfigSecrets && cfg.KubeconfigSecretNamespace != "" {
+			kubernetes.WatchKubeconfigSecrets(context.Background(), inCluster, cfg.KubeconfigSecretNamespace,
+				api.NewSecretClusterHandler(api.GetDefaultRegistry()))
+		}
+		if inCluster, ok := clients["incluster"]; ok && cfg.CredentialsSecret != "" {
+			namespace, name := cfg.CredentialsSecretRef()
+			credentials.AddSecret(inCluster.Clientset(), namespace, name, cfg.CredentialsRefresh)
+		}
+
 		api.SetWarmupCompleted()
 
 		if hasCache {
@@ -280,6 +315,17 @@ func main() {
 	}
 	utils.LogInfo("Using static files", map[string]interface{}{"path": staticPath})
 
+	if *selftest {
+		initK8s()
+		report := api.RunSelftest(context.Background(), cacheSvc, clusterRegistry, config.GetGlobalRegistry())
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+		if !report.Passed {
+			os.Exit(1)
+		}
+		return
+	}
+
 	var firstClient *kubernetes.Client
 	if hasCache {
 		// When cache exists, start K8s initialization in background
@@ -296,12 +342,21 @@ func main() {
 			}
 		}
 		if firstClient == nil {
-			utils.LogError("No Kubernetes client initialized, exiting", nil)
-			os.Exit(1)
+			if !cfg.AgentPushEnabled() && len(cfg.ReportDirs) == 0 && cfg.IngestMode == config.IngestModeKubernetes {
+				utils.LogError("No Kubernetes client initialized, exiting", nil)
+				os.Exit(1)
+			}
+			utils.LogInfo("No Kubernetes client initialized, waiting for ingested reports", map[string]interface{}{"ingestMode": cfg.IngestMode})
 		}
 	}
 	router := api.NewRouter(firstClient, staticPath, cacheSvc, clusterRegistry, config.GetGlobalRegistry())
 	utils.LogInfo("Router created")
+	api.StartExportScheduler(context.Background(), cacheSvc)
+	api.StartJobWorkers(context.Background(), cacheSvc, cfg.JobWorkers, cfg.JobRetention)
+	api.StartRetentionJanitor(context.Background())
+	api.StartReconciler(context.Background(), clusterRegistry, cacheSvc, cfg.ReconcileInterval, cfg.ReconcileRate)
+	api.StartTelemetry(context.Background(), GetVersion(), clusterRegistry, cacheSvc)
+	api.StartReloadOnSignal(context.Background())
 
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
@@ -309,6 +364,7 @@ func main() {
 			http.MethodGet,
 			http.MethodPost,
 			http.MethodPut,
+			http.MethodPatch,
 			http.MethodDelete,
 			http.MethodOptions,
 			http.MethodHead,
@@ -320,7 +376,7 @@ func main() {
 			"X-CSRF-Token",
 			"Cache-Control",
 		},
-		ExposedHeaders:     []string{"Link"},
+		ExposedHeaders:     []string{"Link", "Deprecation", "Sunset", "API-Version"},
 		AllowCredentials:   false,
 		MaxAge:             300,
 		OptionsPassthrough: false,
@@ -330,12 +386,47 @@ func main() {
 
 	http.Handle("/swagger/", http.StripPrefix("/swagger/", httpSwagger.WrapHandler))
 
-	accessLogHandler := api.AccessLogHandler(corsHandler.Handler(router))
+	accessLogHandler := api.AccessLogHandler(corsHandler.Handler(api.AuthHandler(router, cfg)))
 
 	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	utils.LogInfo("Listening", map[string]interface{}{"address": addr})
-	if err := http.ListenAndServe(addr, accessLogHandler); err != nil {
+	utils.LogInfo("Listening", map[string]interface{}{"address": addr, "tls": cfg.TLSCertFile != ""})
+	server := &http.Server{Addr: addr, Handler: accessLogHandler}
+	shutdown := shutdownOnSignal(server)
+	var err error
+	if cfg.TLSCertFile != "" {
+		server.TLSConfig, err = serverTLSConfig(cfg)
+		if err == nil {
+			err = server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		}
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		<-shutdown
+		return
+	}
+	if err != nil {
 		utils.LogError("Server failed to start", map[string]interface{}{"error": err.Error()})
 		os.Exit(1)
 	}
 }
+
+// serverTLSConfig requests client certificates from push agents when AGENT_CA_FILE is set.
+// Certificates are optional so browsers can still reach the UI on the same listener.
+func serverTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.AgentCAFile == "" {
+		return tlsConfig, nil
+	}
+	caPEM, err := os.ReadFile(cfg.AgentCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.AgentCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	return tlsConfig, nil
+}
@@ -0,0 +1,114 @@
+// Package metrics holds the Prometheus collectors served on /metrics.
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// ReportSizeBytes is the serialized size of reports as ingested, before large
+	// fields are stripped, observed for one in 16 reports; buckets run from 1KiB to 256MiB
+	ReportSizeBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "trivy_ui_report_size_bytes",
+		Help:    "Serialized size of a sample of ingested reports.",
+		Buckets: prometheus.ExponentialBuckets(1024, 4, 10),
+	}, []string{"type"})
+
+	// OversizedReports counts the sampled reports of ReportSizeBytes above
+	// OVERSIZED_REPORT_BYTES
+	OversizedReports = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "trivy_ui_oversized_reports_total",
+		Help: "Sampled ingested reports larger than the oversized report threshold.",
+	}, []string{"type"})
+
+	// ExternalizedReports counts report details whose large fields were moved to the database
+	ExternalizedReports = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "trivy_ui_externalized_reports_total",
+		Help: "Report details stored outside memory because they exceeded the threshold.",
+	}, []string{"type"})
+
+	// InformerQueueDepth is the number of informer events waiting for a worker
+	InformerQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "trivy_ui_informer_queue_depth",
+		Help: "Informer events waiting to be applied to the cache.",
+	}, []string{"cluster"})
+
+	// InformerQueueFull counts events an informer had to wait with because the queue of
+	// their worker was full
+	InformerQueueFull = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "trivy_ui_informer_queue_full_total",
+		Help: "Informer events that waited for room in a full queue.",
+	}, []string{"cluster"})
+
+	// InformerQueueWait is how long informers were blocked by full queues
+	InformerQueueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "trivy_ui_informer_queue_wait_seconds",
+		Help:    "Time informers waited for room in a full event queue.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+	}, []string{"cluster"})
+
+	// ClusterAuthFailures counts requests to a cluster that failed to authenticate, from
+	// a 401 or an exec credential plugin that failed
+	ClusterAuthFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "trivy_ui_cluster_auth_failures_total",
+		Help: "Requests to a cluster that failed to authenticate.",
+	}, []string{"cluster"})
+
+	// ClusterReachable is 1 while a cluster's API server answers and 0 while it is
+	// unreachable or rejects the credentials
+	ClusterReachable = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "trivy_ui_cluster_reachable",
+		Help: "Whether the cluster's API server answers.",
+	}, []string{"cluster"})
+
+	// WebhookDeliveries counts outbound webhook events by type and result: delivered,
+	// failed after the retries, or dropped because the queue was full
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "trivy_ui_webhook_deliveries_total",
+		Help: "Outbound webhook events by result.",
+	}, []string{"event", "result"})
+
+	// InformerBatchSize is the number of reports written to the cache at once
+	InformerBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "trivy_ui_informer_batch_size",
+		Help:    "Reports written to the cache per batch.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 8),
+	})
+)
+
+// Handler serves the registered collectors in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// SerializedSize returns the JSON size of v without buffering the encoding.
+func SerializedSize(v interface{}) int {
+	var c byteCounter
+	if err := json.NewEncoder(&c).Encode(v); err != nil {
+		return 0
+	}
+	// Encode appends a newline
+	return int(c) - 1
+}
+
+// ObserveReportSize records one ingested report and reports whether it is oversized.
+func ObserveReportSize(reportType string, size, threshold int) bool {
+	ReportSizeBytes.WithLabelValues(reportType).Observe(float64(size))
+	if threshold > 0 && size > threshold {
+		OversizedReports.WithLabelValues(reportType).Inc()
+		return true
+	}
+	return false
+}
+
+type byteCounter int
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSerializedSize(t *testing.T) {
+	v := map[string]interface{}{"report": map[string]interface{}{"summary": []int{1, 2, 3}}}
+	b, _ := json.Marshal(v)
+	if got := SerializedSize(v); got != len(b) {
+		t.Fatalf("expected %d got %d", len(b), got)
+	}
+}
+
+func TestObserveReportSize(t *testing.T) {
+	if ObserveReportSize("vulnerabilityreports", 10, 0) {
+		t.Fatal("threshold 0 disables oversized detection")
+	}
+	if !ObserveReportSize("vulnerabilityreports", 10, 5) {
+		t.Fatal("expected size above threshold to be oversized")
+	}
+}
@@ -0,0 +1,79 @@
+// Package pss maps Trivy config audit check IDs to the Pod Security Standards controls
+// and CIS Kubernetes Benchmark sections they enforce.
+package pss
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sort"
+)
+
+// Pod Security Standards levels, from least to most restrictive. Restricted includes every
+// baseline control, so a baseline violation also violates restricted.
+const (
+	LevelPrivileged = "privileged"
+	LevelBaseline   = "baseline"
+	LevelRestricted = "restricted"
+)
+
+// Control is the mapping of one check ID.
+type Control struct {
+	CheckID string   `json:"checkID"`
+	Level   string   `json:"level"`
+	Control string   `json:"control"`
+	CIS     []string `json:"cis"`
+}
+
+//go:embed mapping.json
+var mappingJSON []byte
+
+var controls = mustLoad(mappingJSON)
+
+func mustLoad(data []byte) map[string]Control {
+	var list []Control
+	if err := json.Unmarshal(data, &list); err != nil {
+		panic("pss: invalid mapping table: " + err.Error())
+	}
+	result := make(map[string]Control, len(list))
+	for _, c := range list {
+		result[c.CheckID] = c
+	}
+	return result
+}
+
+// Lookup returns the control a check ID maps to.
+func Lookup(checkID string) (Control, bool) {
+	c, ok := controls[checkID]
+	return c, ok
+}
+
+// Controls returns the mapping table ordered by level, then check ID.
+func Controls() []Control {
+	result := make([]Control, 0, len(controls))
+	for _, c := range controls {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Level != result[j].Level {
+			return result[i].Level == LevelBaseline
+		}
+		return result[i].CheckID < result[j].CheckID
+	})
+	return result
+}
+
+// Level returns the most restrictive level that the failed checks still satisfy.
+func Level(failedCheckIDs []string) string {
+	level := LevelRestricted
+	for _, id := range failedCheckIDs {
+		c, ok := controls[id]
+		if !ok {
+			continue
+		}
+		if c.Level == LevelBaseline {
+			return LevelPrivileged
+		}
+		level = LevelBaseline
+	}
+	return level
+}
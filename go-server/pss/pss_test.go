@@ -0,0 +1,34 @@
+package pss
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	c, ok := Lookup("KSV017")
+	if !ok || c.Level != LevelBaseline || c.Control != "Privileged Containers" || len(c.CIS) == 0 {
+		t.Fatalf("unexpected control %+v", c)
+	}
+	if _, ok := Lookup("KSV999"); ok {
+		t.Fatal("unknown checks should not map")
+	}
+	all := Controls()
+	if len(all) == 0 || all[0].Level != LevelBaseline || all[len(all)-1].Level != LevelRestricted {
+		t.Fatalf("expected baseline controls first, got %+v", all)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	cases := []struct {
+		failed []string
+		want   string
+	}{
+		{nil, LevelRestricted},
+		{[]string{"KSV999"}, LevelRestricted},
+		{[]string{"KSV001"}, LevelBaseline},
+		{[]string{"KSV001", "KSV009"}, LevelPrivileged},
+	}
+	for _, c := range cases {
+		if got := Level(c.failed); got != c.want {
+			t.Errorf("Level(%v) = %s, want %s", c.failed, got, c.want)
+		}
+	}
+}
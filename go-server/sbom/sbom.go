@@ -0,0 +1,234 @@
+// Package sbom turns the CycloneDX documents of Trivy sbomreports into standalone
+// CycloneDX and SPDX documents.
+package sbom
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+// Formats lists the supported export formats.
+var Formats = []string{FormatCycloneDX, FormatSPDX}
+
+// noAssertion is SPDX's value for information the document does not provide.
+const noAssertion = "NOASSERTION"
+
+// spdxLicenseID matches license names that are plain SPDX license identifiers.
+var spdxLicenseID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+-]*$`)
+
+// CycloneDX returns a copy of the BOM of an sbomreport with the fields a standalone
+// document needs, which the operator leaves out, filled in.
+func CycloneDX(bom map[string]interface{}) map[string]interface{} {
+	doc := make(map[string]interface{}, len(bom)+2)
+	for k, v := range bom {
+		doc[k] = v
+	}
+	if _, ok := doc["bomFormat"]; !ok {
+		doc["bomFormat"] = "CycloneDX"
+	}
+	if _, ok := doc["specVersion"]; !ok {
+		doc["specVersion"] = "1.5"
+	}
+	if _, ok := doc["version"]; !ok {
+		doc["version"] = 1
+	}
+	return doc
+}
+
+// Document is an SPDX 2.3 document in its JSON form.
+type Document struct {
+	SPDXVersion       string         `json:"spdxVersion"`
+	DataLicense       string         `json:"dataLicense"`
+	SPDXID            string         `json:"SPDXID"`
+	Name              string         `json:"name"`
+	DocumentNamespace string         `json:"documentNamespace"`
+	CreationInfo      CreationInfo   `json:"creationInfo"`
+	Packages          []Package      `json:"packages"`
+	Relationships     []Relationship `json:"relationships"`
+}
+
+type CreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type Package struct {
+	SPDXID           string        `json:"SPDXID"`
+	Name             string        `json:"name"`
+	VersionInfo      string        `json:"versionInfo,omitempty"`
+	Supplier         string        `json:"supplier,omitempty"`
+	DownloadLocation string        `json:"downloadLocation"`
+	FilesAnalyzed    bool          `json:"filesAnalyzed"`
+	LicenseConcluded string        `json:"licenseConcluded"`
+	LicenseDeclared  string        `json:"licenseDeclared"`
+	CopyrightText    string        `json:"copyrightText"`
+	PrimaryPurpose   string        `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs     []ExternalRef `json:"externalRefs,omitempty"`
+}
+
+type ExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type Relationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// DocumentInfo names an SPDX document: Namespace is the unique URI SPDX requires and
+// Creator the tool that wrote it.
+type DocumentInfo struct {
+	Name      string
+	Namespace string
+	Creator   string
+	Created   time.Time
+}
+
+// SPDX converts the CycloneDX BOM of an sbomreport into an SPDX document. The BOM's
+// metadata component is the package the document describes, components become packages
+// and dependencies DEPENDS_ON relationships. Licenses that are not SPDX identifiers or
+// expressions are left as NOASSERTION.
+func SPDX(bom map[string]interface{}, info DocumentInfo) Document {
+	doc := Document{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              info.Name,
+		DocumentNamespace: info.Namespace,
+		CreationInfo: CreationInfo{
+			Created:  info.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + info.Creator},
+		},
+		Packages:      []Package{},
+		Relationships: []Relationship{},
+	}
+
+	ids := make(map[string]string)
+	add := func(component map[string]interface{}) string {
+		id := "SPDXRef-Package-" + strconv.Itoa(len(doc.Packages)+1)
+		if ref, _ := component["bom-ref"].(string); ref != "" {
+			if existing, ok := ids[ref]; ok {
+				return existing
+			}
+			ids[ref] = id
+		}
+		doc.Packages = append(doc.Packages, spdxPackage(id, component))
+		return id
+	}
+
+	metadata, _ := bom["metadata"].(map[string]interface{})
+	if root, ok := metadata["component"].(map[string]interface{}); ok {
+		doc.Relationships = append(doc.Relationships, Relationship{
+			SPDXElementID:      doc.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: add(root),
+		})
+	}
+	components, _ := bom["components"].([]interface{})
+	for _, c := range components {
+		if cm, ok := c.(map[string]interface{}); ok {
+			add(cm)
+		}
+	}
+	dependencies, _ := bom["dependencies"].([]interface{})
+	for _, d := range dependencies {
+		dm, _ := d.(map[string]interface{})
+		ref, _ := dm["ref"].(string)
+		from, ok := ids[ref]
+		if !ok {
+			continue
+		}
+		dependsOn, _ := dm["dependsOn"].([]interface{})
+		for _, dep := range dependsOn {
+			depRef, _ := dep.(string)
+			if to, ok := ids[depRef]; ok {
+				doc.Relationships = append(doc.Relationships, Relationship{
+					SPDXElementID:      from,
+					RelationshipType:   "DEPENDS_ON",
+					RelatedSPDXElement: to,
+				})
+			}
+		}
+	}
+	return doc
+}
+
+func spdxPackage(id string, component map[string]interface{}) Package {
+	name, _ := component["name"].(string)
+	if group, _ := component["group"].(string); group != "" {
+		name = group + "/" + name
+	}
+	version, _ := component["version"].(string)
+	pkg := Package{
+		SPDXID:           id,
+		Name:             name,
+		VersionInfo:      version,
+		DownloadLocation: noAssertion,
+		LicenseConcluded: noAssertion,
+		LicenseDeclared:  spdxLicense(component["licenses"]),
+		CopyrightText:    noAssertion,
+	}
+	if supplier, ok := component["supplier"].(map[string]interface{}); ok {
+		if n, _ := supplier["name"].(string); n != "" {
+			pkg.Supplier = "Organization: " + n
+		}
+	}
+	switch t, _ := component["type"].(string); t {
+	case "application", "container", "device", "file", "firmware", "framework", "library", "operating-system":
+		pkg.PrimaryPurpose = strings.ToUpper(strings.ReplaceAll(t, "-", "_"))
+	}
+	if purl, _ := component["purl"].(string); purl != "" {
+		pkg.ExternalRefs = append(pkg.ExternalRefs, ExternalRef{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl,
+		})
+	}
+	return pkg
+}
+
+// spdxLicense returns the SPDX license expression of CycloneDX licenses, joining several
+// with AND.
+func spdxLicense(licenses interface{}) string {
+	list, _ := licenses.([]interface{})
+	var terms []string
+	for _, l := range list {
+		lm, _ := l.(map[string]interface{})
+		if expr, _ := lm["expression"].(string); expr != "" {
+			terms = append(terms, expr)
+			continue
+		}
+		license, _ := lm["license"].(map[string]interface{})
+		id, _ := license["id"].(string)
+		if id == "" {
+			id, _ = license["name"].(string)
+		}
+		if !spdxLicenseID.MatchString(id) {
+			return noAssertion
+		}
+		terms = append(terms, id)
+	}
+	switch len(terms) {
+	case 0:
+		return noAssertion
+	case 1:
+		return terms[0]
+	}
+	for i, t := range terms {
+		if strings.Contains(t, " ") {
+			terms[i] = fmt.Sprintf("(%s)", t)
+		}
+	}
+	return strings.Join(terms, " AND ")
+}
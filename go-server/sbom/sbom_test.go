@@ -0,0 +1,89 @@
+package sbom
+
+import (
+	"testing"
+	"time"
+)
+
+func testBOM() map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"component": map[string]interface{}{
+				"bom-ref": "root", "type": "container", "name": "ghcr.io/acme/api",
+			},
+		},
+		"components": []interface{}{
+			map[string]interface{}{
+				"bom-ref": "pkg:npm/lodash@4.17.21", "type": "library", "name": "lodash", "version": "4.17.21",
+				"purl":     "pkg:npm/lodash@4.17.21",
+				"licenses": []interface{}{map[string]interface{}{"license": map[string]interface{}{"name": "MIT"}}},
+			},
+			map[string]interface{}{
+				"bom-ref": "pkg:deb/debian/libc6@2.36", "type": "library", "group": "debian", "name": "libc6", "version": "2.36",
+				"licenses": []interface{}{
+					map[string]interface{}{"license": map[string]interface{}{"id": "GPL-2.0-only"}},
+					map[string]interface{}{"expression": "LGPL-2.1-or-later OR MIT"},
+				},
+			},
+			map[string]interface{}{
+				"bom-ref": "other", "type": "library", "name": "custom",
+				"licenses": []interface{}{map[string]interface{}{"license": map[string]interface{}{"name": "Some custom license"}}},
+			},
+		},
+		"dependencies": []interface{}{
+			map[string]interface{}{"ref": "root", "dependsOn": []interface{}{"pkg:npm/lodash@4.17.21", "pkg:deb/debian/libc6@2.36", "unknown"}},
+		},
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	bom := testBOM()
+	doc := CycloneDX(bom)
+	if doc["bomFormat"] != "CycloneDX" || doc["specVersion"] != "1.5" || doc["components"] == nil {
+		t.Fatalf("unexpected document %v", doc)
+	}
+	if _, ok := bom["bomFormat"]; ok {
+		t.Fatal("the report's BOM was modified")
+	}
+	if doc := CycloneDX(map[string]interface{}{"specVersion": "1.6"}); doc["specVersion"] != "1.6" {
+		t.Fatalf("expected the BOM's spec version kept, got %v", doc["specVersion"])
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	doc := SPDX(testBOM(), DocumentInfo{Name: "api", Namespace: "https://example.com/spdx/api", Creator: "trivy-ui", Created: created})
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2026-03-01T12:00:00Z" || doc.CreationInfo.Creators[0] != "Tool: trivy-ui" {
+		t.Fatalf("unexpected document header %+v", doc)
+	}
+	if len(doc.Packages) != 4 {
+		t.Fatalf("expected the root and three packages, got %+v", doc.Packages)
+	}
+	root, lodash, libc, custom := doc.Packages[0], doc.Packages[1], doc.Packages[2], doc.Packages[3]
+	if root.PrimaryPurpose != "CONTAINER" || root.LicenseDeclared != noAssertion {
+		t.Errorf("root = %+v", root)
+	}
+	if lodash.LicenseDeclared != "MIT" || len(lodash.ExternalRefs) != 1 || lodash.ExternalRefs[0].ReferenceLocator != "pkg:npm/lodash@4.17.21" {
+		t.Errorf("lodash = %+v", lodash)
+	}
+	if libc.Name != "debian/libc6" || libc.LicenseDeclared != "GPL-2.0-only AND (LGPL-2.1-or-later OR MIT)" {
+		t.Errorf("libc6 = %+v", libc)
+	}
+	if custom.LicenseDeclared != noAssertion {
+		t.Errorf("expected a license name that is no identifier left out, got %q", custom.LicenseDeclared)
+	}
+
+	want := []Relationship{
+		{"SPDXRef-DOCUMENT", "DESCRIBES", root.SPDXID},
+		{root.SPDXID, "DEPENDS_ON", lodash.SPDXID},
+		{root.SPDXID, "DEPENDS_ON", libc.SPDXID},
+	}
+	if len(doc.Relationships) != len(want) {
+		t.Fatalf("relationships = %+v", doc.Relationships)
+	}
+	for i, r := range want {
+		if doc.Relationships[i] != r {
+			t.Errorf("relationship %d = %+v, want %+v", i, doc.Relationships[i], r)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"trivy-ui/api"
+	"trivy-ui/utils"
+)
+
+// shutdownTimeout bounds how long open requests may finish after SIGTERM; Kubernetes
+// kills the pod 30 seconds after it, which leaves time for the cache save.
+const shutdownTimeout = 10 * time.Second
+
+// shutdownOnSignal stops the server on SIGTERM or SIGINT and saves the cache, so reports
+// received since the last periodic save survive a rollout. The returned channel is
+// closed once that is done.
+func shutdownOnSignal(server *http.Server) <-chan struct{} {
+	done := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		defer close(done)
+		sig := <-signals
+		utils.LogInfo("Shutting down", map[string]interface{}{"signal": sig.String()})
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			utils.LogWarning("Server did not shut down cleanly", map[string]interface{}{"error": err.Error()})
+		}
+		if err := api.SaveCache(); err != nil {
+			utils.LogError("Failed to save cache on shutdown", map[string]interface{}{"error": err.Error()})
+		}
+	}()
+	return done
+}
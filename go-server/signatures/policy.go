@@ -0,0 +1,127 @@
+// Package signatures checks the cosign signatures and in-toto attestations that sigstore
+// tooling stores next to an image in its registry, against the public keys and keyless
+// (Fulcio certificate) identities of a policy.
+package signatures
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Policy lists who is trusted to sign images. A signature is verified when it checks out
+// against one of PublicKeys, or when it carries a Fulcio certificate issued under
+// FulcioRoots to one of Identities.
+type Policy struct {
+	PublicKeys []PublicKey `json:"publicKeys"`
+	Identities []Identity  `json:"identities"`
+	// FulcioRoots are the PEM certificates keyless signing certificates must chain to
+	FulcioRoots string `json:"fulcioRoots,omitempty"`
+	// RekorPublicKey, when set, is used to check the transparency log entry of keyless
+	// signatures; without it the entry's time is taken as is
+	RekorPublicKey string `json:"rekorPublicKey,omitempty"`
+
+	roots *x509.CertPool
+	rekor crypto.PublicKey
+}
+
+// PublicKey is a named PEM public key (ECDSA, RSA or Ed25519), as written by
+// cosign generate-key-pair.
+type PublicKey struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+
+	key crypto.PublicKey
+}
+
+// Identity is a keyless signer: the OIDC issuer of the signing certificate and its
+// subject (email or URI SAN), exactly or by regular expression.
+type Identity struct {
+	Issuer        string `json:"issuer"`
+	Subject       string `json:"subject,omitempty"`
+	SubjectRegExp string `json:"subjectRegExp,omitempty"`
+
+	subject *regexp.Regexp
+}
+
+// LoadPolicy reads a YAML or JSON policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature policy: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse signature policy: %w", err)
+	}
+	if len(p.PublicKeys) == 0 && len(p.Identities) == 0 {
+		return nil, errors.New("signature policy has neither publicKeys nor identities")
+	}
+	for i := range p.PublicKeys {
+		k := &p.PublicKeys[i]
+		if k.Name == "" {
+			return nil, fmt.Errorf("public key %d: name is required", i+1)
+		}
+		key, err := parsePublicKey(k.Key)
+		if err != nil {
+			return nil, fmt.Errorf("public key %s: %w", k.Name, err)
+		}
+		k.key = key
+	}
+	if len(p.Identities) > 0 {
+		p.roots = x509.NewCertPool()
+		if !p.roots.AppendCertsFromPEM([]byte(p.FulcioRoots)) {
+			return nil, errors.New("identities need fulcioRoots with at least one PEM certificate")
+		}
+	}
+	for i := range p.Identities {
+		id := &p.Identities[i]
+		if id.Issuer == "" || (id.Subject == "") == (id.SubjectRegExp == "") {
+			return nil, fmt.Errorf("identity %d: issuer and one of subject or subjectRegExp are required", i+1)
+		}
+		if id.SubjectRegExp != "" {
+			re, err := regexp.Compile("^(?:" + id.SubjectRegExp + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("identity %d: invalid subjectRegExp: %w", i+1, err)
+			}
+			id.subject = re
+		}
+	}
+	if p.RekorPublicKey != "" {
+		key, err := parsePublicKey(p.RekorPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("rekorPublicKey: %w", err)
+		}
+		p.rekor = key
+	}
+	return &p, nil
+}
+
+func parsePublicKey(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("not a PEM public key")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// matches reports whether a certificate's issuer and subject belong to the identity.
+func (id Identity) matches(issuer, subject string) bool {
+	if issuer != id.Issuer {
+		return false
+	}
+	if id.subject != nil {
+		return id.subject.MatchString(subject)
+	}
+	return subject == id.Subject
+}
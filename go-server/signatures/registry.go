@@ -0,0 +1,243 @@
+package signatures
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// maxBlobSize bounds the signature payloads and attestation envelopes read from a registry
+const maxBlobSize = 4 << 20
+
+var errNotFound = errors.New("not found")
+
+type manifest struct {
+	Layers []descriptor `json:"layers"`
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// Registry reads manifests and blobs through the OCI distribution API, anonymously or
+// with the credentials Auth returns, exchanging them for bearer tokens when the registry
+// asks for one. Registries on localhost are reached over plain HTTP.
+type Registry struct {
+	HTTP *http.Client
+	Auth func(host string) (username, password string)
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// splitRepository splits an image repository into the registry host and the repository
+// path, resolving Docker Hub's short names.
+func splitRepository(repository string) (host, path string) {
+	host, path, ok := strings.Cut(repository, "/")
+	if !ok || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		host, path = "docker.io", repository
+	}
+	if host == "docker.io" || host == "index.docker.io" {
+		host = "registry-1.docker.io"
+		if !strings.Contains(path, "/") {
+			path = "library/" + path
+		}
+	}
+	return host, path
+}
+
+func registryScheme(host string) string {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if hostname == "localhost" || net.ParseIP(hostname).IsLoopback() {
+		return "http"
+	}
+	return "https"
+}
+
+func (r *Registry) manifest(ctx context.Context, repository, reference string) (*manifest, error) {
+	body, err := r.get(ctx, repository, "manifests/"+reference,
+		"application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json")
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", reference, err)
+	}
+	return &m, nil
+}
+
+// blob fetches a blob and checks it against its digest.
+func (r *Registry) blob(ctx context.Context, repository, digest string) ([]byte, error) {
+	body, err := r.get(ctx, repository, "blobs/"+digest, "")
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	if digest != "sha256:"+hex.EncodeToString(sum[:]) {
+		return nil, fmt.Errorf("blob %s does not match its digest", digest)
+	}
+	return body, nil
+}
+
+func (r *Registry) get(ctx context.Context, repository, path, accept string) ([]byte, error) {
+	host, repo := splitRepository(repository)
+	endpoint := fmt.Sprintf("%s://%s/v2/%s/%s", registryScheme(host), host, repo, path)
+	resp, err := r.do(ctx, endpoint, accept, r.token(host, repo))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		token, err := r.authenticate(ctx, host, repo, challenge)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = r.do(ctx, endpoint, accept, token); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: registry returned %s", path, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBlobSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxBlobSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", path, maxBlobSize)
+	}
+	return body, nil
+}
+
+func (r *Registry) do(ctx context.Context, endpoint, accept, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return r.client().Do(req)
+}
+
+func (r *Registry) client() *http.Client {
+	if r.HTTP != nil {
+		return r.HTTP
+	}
+	return http.DefaultClient
+}
+
+func (r *Registry) token(host, repo string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tokens[host+"/"+repo]
+}
+
+// authenticate answers a registry's WWW-Authenticate challenge: basic credentials are
+// sent as is, bearer challenges are exchanged at the token realm for a pull token.
+func (r *Registry) authenticate(ctx context.Context, host, repo, challenge string) (string, error) {
+	var username, password string
+	if r.Auth != nil {
+		username, password = r.Auth(host)
+	}
+	scheme, params := parseChallenge(challenge)
+	var authorization string
+	switch scheme {
+	case "basic":
+		if username == "" {
+			return "", fmt.Errorf("%s requires credentials", host)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(username, password)
+		authorization = req.Header.Get("Authorization")
+	case "bearer":
+		realm, err := url.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return "", fmt.Errorf("%s: invalid token realm %q", host, params["realm"])
+		}
+		q := realm.Query()
+		if params["service"] != "" {
+			q.Set("service", params["service"])
+		}
+		q.Set("scope", "repository:"+repo+":pull")
+		realm.RawQuery = q.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+		resp, err := r.client().Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%s: token endpoint returned %s", host, resp.Status)
+		}
+		var body struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+			return "", fmt.Errorf("%s: invalid token response: %w", host, err)
+		}
+		if body.Token == "" {
+			body.Token = body.AccessToken
+		}
+		authorization = "Bearer " + body.Token
+	default:
+		return "", fmt.Errorf("%s: unsupported authentication %q", host, challenge)
+	}
+	r.mu.Lock()
+	if r.tokens == nil {
+		r.tokens = make(map[string]string)
+	}
+	r.tokens[host+"/"+repo] = authorization
+	r.mu.Unlock()
+	return authorization, nil
+}
+
+// parseChallenge parses `Bearer realm="...",service="..."` into the lowercased scheme
+// and its parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := make(map[string]string)
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			params[key] = value
+		}
+	}
+	return strings.ToLower(scheme), params
+}
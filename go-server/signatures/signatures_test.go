@@ -0,0 +1,244 @@
+package signatures
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// testRegistry serves manifests by tag and blobs by digest for one repository, and asks
+// for a bearer token when token is set.
+type testRegistry struct {
+	manifests map[string]manifest
+	blobs     map[string][]byte
+	token     string
+}
+
+func newTestRegistry(t *testing.T, token string) (*testRegistry, string) {
+	reg := &testRegistry{manifests: map[string]manifest{}, blobs: map[string][]byte{}, token: token}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:acme/app:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": reg.token})
+			return
+		}
+		if reg.token != "" && r.Header.Get("Authorization") != "Bearer "+reg.token {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/v2/acme/app/")
+		if tag, ok := strings.CutPrefix(path, "manifests/"); ok {
+			if m, ok := reg.manifests[tag]; ok {
+				json.NewEncoder(w).Encode(m)
+				return
+			}
+		}
+		if digest, ok := strings.CutPrefix(path, "blobs/"); ok {
+			if b, ok := reg.blobs[digest]; ok {
+				w.Write(b)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	return reg, u.Host + "/acme/app"
+}
+
+// add stores blob as a layer of the manifest tagged tag.
+func (reg *testRegistry) add(tag string, blob []byte, annotations map[string]string) {
+	sum := sha256.Sum256(blob)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	reg.blobs[digest] = blob
+	m := reg.manifests[tag]
+	m.Layers = append(m.Layers, descriptor{Digest: digest, Annotations: annotations})
+	reg.manifests[tag] = m
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, message []byte) string {
+	sum := sha256.Sum256(message)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func simpleSigning(digest string) []byte {
+	return []byte(`{"critical":{"identity":{"docker-reference":"registry/acme/app"},"image":{"docker-manifest-digest":"` + digest + `"},"type":"cosign container image signature"},"optional":null}`)
+}
+
+func attestation(t *testing.T, key *ecdsa.PrivateKey, digest string) []byte {
+	_, hexDigest, _ := strings.Cut(digest, ":")
+	statement := []byte(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2","subject":[{"name":"app","digest":{"sha256":"` + hexDigest + `"}}],"predicate":{}}`)
+	payloadType := "application/vnd.in-toto+json"
+	pae := fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(statement), statement)
+	envelope, _ := json.Marshal(map[string]interface{}{
+		"payloadType": payloadType,
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+		"signatures":  []map[string]string{{"sig": sign(t, key, []byte(pae))}},
+	})
+	return envelope
+}
+
+func TestVerifyPublicKey(t *testing.T) {
+	trusted, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	policy, err := ParsePolicy([]byte("publicKeys:\n  - name: release\n    key: |\n" + indent(publicKeyPEM(t, trusted))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg, repository := newTestRegistry(t, "s3cret")
+	tag := strings.Replace(testDigest, ":", "-", 1)
+	payload := simpleSigning(testDigest)
+	reg.add(tag+".sig", payload, map[string]string{signatureAnnotation: sign(t, other, payload)})
+	reg.add(tag+".sig", payload, map[string]string{signatureAnnotation: sign(t, trusted, payload)})
+	reg.add(tag+".att", attestation(t, trusted, testDigest), map[string]string{predicateTypeAnnotation: "https://slsa.dev/provenance/v0.2"})
+
+	v := &Verifier{Policy: policy, Registry: &Registry{}}
+	result := v.Verify(t.Context(), repository, testDigest)
+	if result.Status != StatusVerified || result.Error != "" {
+		t.Fatalf("got %+v", result)
+	}
+	if len(result.Signatures) != 2 || result.Signatures[0].Verified || !result.Signatures[1].Verified || result.Signatures[1].Signer != "release" {
+		t.Errorf("signatures: %+v", result.Signatures)
+	}
+	if len(result.Attestations) != 1 || !result.Attestations[0].Verified || result.Attestations[0].PredicateType != "https://slsa.dev/provenance/v0.2" {
+		t.Errorf("attestations: %+v", result.Attestations)
+	}
+
+	// without a policy signatures are listed but not trusted
+	if result := (&Verifier{Registry: &Registry{}}).Verify(t.Context(), repository, testDigest); result.Status != StatusUnverified {
+		t.Errorf("no policy: got %s", result.Status)
+	}
+	// a signature copied from another image does not count
+	otherDigest := "sha256:" + strings.Repeat("f", 64)
+	reg.add(strings.Replace(otherDigest, ":", "-", 1)+".sig", payload, map[string]string{signatureAnnotation: sign(t, trusted, payload)})
+	if result := v.Verify(t.Context(), repository, otherDigest); result.Status != StatusUnverified || !strings.Contains(result.Signatures[0].Reason, "signature is for sha256:0123456789ab") {
+		t.Errorf("copied signature: got %+v", result)
+	}
+	if result := v.Verify(t.Context(), repository, "sha256:"+strings.Repeat("e", 64)); result.Status != StatusUnsigned {
+		t.Errorf("unsigned: got %+v", result)
+	}
+}
+
+func TestVerifyKeyless(t *testing.T) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "test-fulcio"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour),
+		IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign,
+	}
+	caDER, _ := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	ca, _ := x509.ParseCertificate(caDER)
+
+	signedAt := time.Now().Add(-30 * time.Minute)
+	leafKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	issuer, _ := asn1.Marshal("https://token.actions.githubusercontent.com")
+	workflow, _ := url.Parse("https://github.com/acme/app/.github/workflows/release.yml@refs/heads/main")
+	leafDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    signedAt.Add(-time.Minute), NotAfter: signedAt.Add(9 * time.Minute),
+		URIs:            []*url.URL{workflow},
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuer}},
+	}, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg, repository := newTestRegistry(t, "")
+	payload := simpleSigning(testDigest)
+	sig := sign(t, leafKey, payload)
+	bundle := fmt.Sprintf(`{"SignedEntryTimestamp":"","Payload":{"body":"","integratedTime":%d,"logIndex":1,"logID":"00"}}`, signedAt.Unix())
+	reg.add(strings.Replace(testDigest, ":", "-", 1)+".sig", payload, map[string]string{
+		signatureAnnotation:   sig,
+		certificateAnnotation: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})),
+		bundleAnnotation:      bundle,
+	})
+	roots := indent(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})))
+
+	verify := func(subject string) Signature {
+		t.Helper()
+		policy, err := ParsePolicy([]byte("identities:\n  - issuer: https://token.actions.githubusercontent.com\n    subjectRegExp: " + subject + "\nfulcioRoots: |\n" + roots))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := (&Verifier{Policy: policy, Registry: &Registry{}}).Verify(t.Context(), repository, testDigest)
+		if len(result.Signatures) != 1 {
+			t.Fatalf("got %+v", result)
+		}
+		return result.Signatures[0]
+	}
+	if s := verify(`https://github\.com/acme/.*`); !s.Verified || s.Signer != workflow.String() || s.Issuer != "https://token.actions.githubusercontent.com" {
+		t.Errorf("got %+v", s)
+	}
+	if s := verify(`https://github\.com/other/.*`); s.Verified || s.Reason != "signer is not an identity of the policy" {
+		t.Errorf("other identity: got %+v", s)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, bad := range []string{
+		`{}`,
+		`publicKeys: [{name: a, key: "not pem"}]`,
+		`identities: [{issuer: "https://issuer", subject: "a"}]`,
+		`identities: [{issuer: "https://issuer"}], fulcioRoots: ""`,
+	} {
+		if _, err := ParsePolicy([]byte(bad)); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestSplitRepository(t *testing.T) {
+	cases := map[string][2]string{
+		"nginx":                    {"registry-1.docker.io", "library/nginx"},
+		"index.docker.io/acme/app": {"registry-1.docker.io", "acme/app"},
+		"ghcr.io/acme/app":         {"ghcr.io", "acme/app"},
+		"localhost:5000/app":       {"localhost:5000", "app"},
+	}
+	for repository, want := range cases {
+		if host, path := splitRepository(repository); host != want[0] || path != want[1] {
+			t.Errorf("%s: got %s %s", repository, host, path)
+		}
+	}
+}
+
+func indent(s string) string {
+	return "      " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n      ") + "\n"
+}
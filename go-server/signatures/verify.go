@@ -0,0 +1,388 @@
+package signatures
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Signature statuses of an image.
+const (
+	// StatusVerified: at least one signature checks out against the policy
+	StatusVerified = "verified"
+	// StatusUnverified: signatures exist but none is trusted by the policy
+	StatusUnverified = "unverified"
+	StatusUnsigned   = "unsigned"
+	// StatusError: the registry could not be read
+	StatusError = "error"
+)
+
+// Annotations cosign puts on the layers of signature and attestation manifests.
+const (
+	signatureAnnotation     = "dev.cosignproject.cosign/signature"
+	certificateAnnotation   = "dev.sigstore.cosign/certificate"
+	chainAnnotation         = "dev.sigstore.cosign/chain"
+	bundleAnnotation        = "dev.sigstore.cosign/bundle"
+	predicateTypeAnnotation = "predicateType"
+)
+
+// Fulcio certificate extensions holding the OIDC issuer: the raw string of the first
+// releases and the DER-encoded string of later ones.
+var (
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// Signature is one signature of an image or attestation.
+type Signature struct {
+	Verified bool `json:"verified"`
+	// Signer is the name of the policy key that verified it, or the subject of the
+	// signing certificate of keyless signatures
+	Signer string `json:"signer,omitempty"`
+	Issuer string `json:"issuer,omitempty"`
+	// Reason says why an unverified signature was not trusted
+	Reason string `json:"reason,omitempty"`
+}
+
+// Attestation is a signed in-toto statement about the image, an SBOM, SLSA provenance
+// or vulnerability scan for instance.
+type Attestation struct {
+	PredicateType string `json:"predicateType"`
+	Signature
+}
+
+// Result is the signature and attestation status of an image digest in a repository.
+type Result struct {
+	Repository   string        `json:"repository"`
+	Digest       string        `json:"digest"`
+	Status       string        `json:"status"`
+	Signatures   []Signature   `json:"signatures"`
+	Attestations []Attestation `json:"attestations"`
+	Error        string        `json:"error,omitempty"`
+	CheckedAt    time.Time     `json:"checkedAt"`
+}
+
+// Verifier checks images against a policy. Without a policy, signatures and
+// attestations are listed but none is verified.
+type Verifier struct {
+	Policy   *Policy
+	Registry *Registry
+}
+
+// Verify reads the signatures (the sha256-<hex>.sig tag) and attestations (the
+// sha256-<hex>.att tag) cosign stored for digest in repository and checks them.
+func (v *Verifier) Verify(ctx context.Context, repository, digest string) Result {
+	result := Result{Repository: repository, Digest: digest, Status: StatusUnsigned, Signatures: []Signature{}, Attestations: []Attestation{}, CheckedAt: time.Now()}
+	if !strings.HasPrefix(digest, "sha256:") {
+		result.Status, result.Error = StatusError, "only sha256 digests are supported"
+		return result
+	}
+	tag := strings.Replace(digest, ":", "-", 1)
+
+	sigs, err := v.Registry.manifest(ctx, repository, tag+".sig")
+	if err != nil && !errors.Is(err, errNotFound) {
+		result.Status, result.Error = StatusError, err.Error()
+		return result
+	}
+	if sigs != nil {
+		for _, layer := range sigs.Layers {
+			result.Signatures = append(result.Signatures, v.checkImageSignature(ctx, repository, digest, layer))
+		}
+	}
+	atts, err := v.Registry.manifest(ctx, repository, tag+".att")
+	if err != nil && !errors.Is(err, errNotFound) {
+		result.Error = err.Error()
+	}
+	if atts != nil {
+		for _, layer := range atts.Layers {
+			result.Attestations = append(result.Attestations, v.checkAttestation(ctx, repository, digest, layer))
+		}
+	}
+
+	if len(result.Signatures) > 0 {
+		result.Status = StatusUnverified
+	}
+	for _, s := range result.Signatures {
+		if s.Verified {
+			result.Status = StatusVerified
+			break
+		}
+	}
+	if result.Status == StatusUnsigned && result.Error != "" {
+		result.Status = StatusError
+	}
+	return result
+}
+
+// checkImageSignature checks a simple signing payload: it must name digest and carry a
+// signature trusted by the policy.
+func (v *Verifier) checkImageSignature(ctx context.Context, repository, digest string, layer descriptor) Signature {
+	payload, err := v.Registry.blob(ctx, repository, layer.Digest)
+	if err != nil {
+		return Signature{Reason: err.Error()}
+	}
+	var simple struct {
+		Critical struct {
+			Image struct {
+				Digest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &simple); err != nil {
+		return Signature{Reason: "invalid signature payload: " + err.Error()}
+	}
+	if signed := simple.Critical.Image.Digest; signed != digest {
+		return Signature{Reason: fmt.Sprintf("signature is for %s", shortDigest(signed))}
+	}
+	sig, err := base64.StdEncoding.DecodeString(layer.Annotations[signatureAnnotation])
+	if err != nil || len(sig) == 0 {
+		return Signature{Reason: "missing or invalid signature annotation"}
+	}
+	return v.checkSigner(layer.Annotations, payload, sig)
+}
+
+// checkAttestation checks a DSSE envelope holding an in-toto statement about digest.
+func (v *Verifier) checkAttestation(ctx context.Context, repository, digest string, layer descriptor) Attestation {
+	att := Attestation{PredicateType: layer.Annotations[predicateTypeAnnotation]}
+	data, err := v.Registry.blob(ctx, repository, layer.Digest)
+	if err != nil {
+		att.Reason = err.Error()
+		return att
+	}
+	var envelope struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+		Signatures  []struct {
+			Sig string `json:"sig"`
+		} `json:"signatures"`
+	}
+	var statement struct {
+		PredicateType string `json:"predicateType"`
+		Subject       []struct {
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		att.Reason = "invalid envelope: " + err.Error()
+		return att
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err == nil {
+		err = json.Unmarshal(payload, &statement)
+	}
+	if err != nil {
+		att.Reason = "invalid statement: " + err.Error()
+		return att
+	}
+	if statement.PredicateType != "" {
+		att.PredicateType = statement.PredicateType
+	}
+	algorithm, hexDigest, _ := strings.Cut(digest, ":")
+	subject := false
+	for _, s := range statement.Subject {
+		subject = subject || s.Digest[algorithm] == hexDigest
+	}
+	if !subject {
+		att.Reason = "statement is not about this image"
+		return att
+	}
+
+	// DSSE signs the pre-authentication encoding of the payload, not the payload itself
+	pae := []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(envelope.PayloadType), envelope.PayloadType, len(payload), payload))
+	att.Reason = "envelope is not signed"
+	for _, s := range envelope.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if att.Signature = v.checkSigner(layer.Annotations, pae, sig); att.Verified {
+			break
+		}
+	}
+	return att
+}
+
+// checkSigner checks sig over message against the signing certificate in the
+// annotations, or against the policy's public keys when there is none.
+func (v *Verifier) checkSigner(annotations map[string]string, message, sig []byte) Signature {
+	if cert := annotations[certificateAnnotation]; cert != "" {
+		return v.checkKeyless(annotations, cert, message, sig)
+	}
+	if v.Policy == nil {
+		return Signature{Reason: "no signature policy"}
+	}
+	for _, k := range v.Policy.PublicKeys {
+		if verifySignature(k.key, message, sig) == nil {
+			return Signature{Verified: true, Signer: k.Name}
+		}
+	}
+	return Signature{Reason: "no trusted public key matches"}
+}
+
+// checkKeyless verifies a signature made with a short-lived Fulcio certificate: the
+// certificate must chain to the policy's roots at the time the transparency log recorded
+// the signature, and be issued to one of the policy's identities.
+func (v *Verifier) checkKeyless(annotations map[string]string, certPEM string, message, sig []byte) Signature {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return Signature{Reason: "invalid signing certificate: " + err.Error()}
+	}
+	s := Signature{Signer: certificateSubject(cert), Issuer: certificateIssuer(cert)}
+	if v.Policy == nil || len(v.Policy.Identities) == 0 {
+		s.Reason = "keyless signatures are not trusted by the policy"
+		return s
+	}
+	signedAt, err := v.Policy.logTime(annotations[bundleAnnotation], sig)
+	if err != nil {
+		s.Reason = err.Error()
+		return s
+	}
+
+	intermediates := x509.NewCertPool()
+	intermediates.AppendCertsFromPEM([]byte(annotations[chainAnnotation]))
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         v.Policy.roots,
+		Intermediates: intermediates,
+		CurrentTime:   signedAt,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		s.Reason = "untrusted signing certificate: " + err.Error()
+		return s
+	}
+	trusted := false
+	for _, id := range v.Policy.Identities {
+		trusted = trusted || id.matches(s.Issuer, s.Signer)
+	}
+	if !trusted {
+		s.Reason = "signer is not an identity of the policy"
+		return s
+	}
+	if err := verifySignature(cert.PublicKey, message, sig); err != nil {
+		s.Reason = err.Error()
+		return s
+	}
+	s.Verified = true
+	return s
+}
+
+// logTime returns when the transparency log recorded a keyless signature, from the
+// bundle cosign attaches. With a Rekor public key the bundle's signed entry timestamp is
+// checked, and the entry must be the one of sig when it is a hashedrekord.
+func (p *Policy) logTime(bundle string, sig []byte) (time.Time, error) {
+	if bundle == "" {
+		return time.Time{}, errors.New("no transparency log entry")
+	}
+	var b struct {
+		SignedEntryTimestamp string
+		Payload              struct {
+			Body           string `json:"body"`
+			IntegratedTime int64  `json:"integratedTime"`
+			LogID          string `json:"logID"`
+			LogIndex       int64  `json:"logIndex"`
+		}
+	}
+	if err := json.Unmarshal([]byte(bundle), &b); err != nil || b.Payload.IntegratedTime == 0 {
+		return time.Time{}, errors.New("invalid transparency log bundle")
+	}
+	if p.rekor != nil {
+		// the fields of Payload are in canonical JSON order
+		canonical, _ := json.Marshal(b.Payload)
+		set, err := base64.StdEncoding.DecodeString(b.SignedEntryTimestamp)
+		if err != nil || verifySignature(p.rekor, canonical, set) != nil {
+			return time.Time{}, errors.New("transparency log entry is not signed by the policy's Rekor key")
+		}
+		body, _ := base64.StdEncoding.DecodeString(b.Payload.Body)
+		var entry struct {
+			Kind string `json:"kind"`
+			Spec struct {
+				Signature struct {
+					Content string `json:"content"`
+				} `json:"signature"`
+			} `json:"spec"`
+		}
+		if json.Unmarshal(body, &entry) == nil && entry.Kind == "hashedrekord" && entry.Spec.Signature.Content != base64.StdEncoding.EncodeToString(sig) {
+			return time.Time{}, errors.New("transparency log entry is for another signature")
+		}
+	}
+	return time.Unix(b.Payload.IntegratedTime, 0), nil
+}
+
+func parseCertificate(data string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("not a PEM certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// certificateSubject returns the email or URI a Fulcio certificate was issued to.
+func certificateSubject(cert *x509.Certificate) string {
+	if len(cert.EmailAddresses) > 0 {
+		return cert.EmailAddresses[0]
+	}
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return ""
+}
+
+func certificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidIssuerV1):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+// verifySignature checks sig over the SHA-256 of message, or over message itself for
+// Ed25519 keys.
+func verifySignature(key crypto.PublicKey, message, sig []byte) error {
+	sum := sha256.Sum256(message)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(k, sum[:], sig) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig) == nil || rsa.VerifyPSS(k, crypto.SHA256, sum[:], sig, nil) == nil {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(k, message, sig) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return errors.New("signature does not match")
+}
+
+// shortDigest abbreviates a digest for messages.
+func shortDigest(digest string) string {
+	if _, hexDigest, ok := strings.Cut(digest, ":"); ok && len(hexDigest) > 12 {
+		if _, err := hex.DecodeString(hexDigest); err == nil {
+			return digest[:len(digest)-len(hexDigest)+12]
+		}
+	}
+	return digest
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ArchivedReport is the last known summary of a report at one of its deletions from the
+// cluster; a report deleted and recreated several times has one per deletion.
+type ArchivedReport struct {
+	ID int64 `json:"id"`
+	ReportRef
+	Status     string      `json:"status,omitempty"`
+	Data       interface{} `json:"data"`
+	Archived   bool        `json:"archived"`
+	ArchivedAt time.Time   `json:"archivedAt"`
+}
+
+type ArchiveFilter struct {
+	Cluster    string
+	Namespace  string
+	ReportType string
+	Since      time.Time
+}
+
+func (s *Store) ArchiveReport(ctx context.Context, rec ArchivedReport) error {
+	data, err := json.Marshal(rec.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report data: %w", err)
+	}
+	if rec.ArchivedAt.IsZero() {
+		rec.ArchivedAt = time.Now()
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO archived_reports (cluster, namespace, report_type, report_name, status, data, archived_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		rec.Cluster, rec.Namespace, rec.ReportType, rec.ReportName, rec.Status, string(data), rec.ArchivedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to archive report: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListArchived(ctx context.Context, f ArchiveFilter) ([]ArchivedReport, error) {
+	var where []string
+	var args []interface{}
+	if f.Cluster != "" {
+		where = append(where, "cluster = ?")
+		args = append(args, f.Cluster)
+	}
+	if f.Namespace != "" {
+		where = append(where, "namespace = ?")
+		args = append(args, f.Namespace)
+	}
+	if f.ReportType != "" {
+		where = append(where, "report_type = ?")
+		args = append(args, f.ReportType)
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "archived_at >= ?")
+		args = append(args, f.Since.Unix())
+	}
+
+	query := `SELECT id, cluster, namespace, report_type, report_name, status, data, archived_at FROM archived_reports`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY archived_at DESC, id DESC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived reports: %w", err)
+	}
+	defer rows.Close()
+
+	result := []ArchivedReport{}
+	for rows.Next() {
+		var rec ArchivedReport
+		var data string
+		var archivedAt int64
+		if err := rows.Scan(&rec.ID, &rec.Cluster, &rec.Namespace, &rec.ReportType, &rec.ReportName, &rec.Status, &data, &archivedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &rec.Data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal archived report: %w", err)
+		}
+		rec.Archived = true
+		rec.ArchivedAt = time.Unix(archivedAt, 0).UTC()
+		result = append(result, rec)
+	}
+	return result, rows.Err()
+}
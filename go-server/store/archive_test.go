@@ -0,0 +1,33 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArchiveReport_KeepsEveryDeletion(t *testing.T) {
+	s := newTestStore(t)
+	first := time.Unix(1700000000, 0)
+	for i, status := range []string{"Critical", "High"} {
+		rec := ArchivedReport{
+			ReportRef:  testRef,
+			Status:     status,
+			Data:       map[string]interface{}{"summary": map[string]interface{}{"criticalCount": float64(2 - i)}},
+			ArchivedAt: first.Add(time.Duration(i) * time.Hour),
+		}
+		if err := s.ArchiveReport(t.Context(), rec); err != nil {
+			t.Fatalf("archive: %v", err)
+		}
+	}
+	got, err := s.ListArchived(t.Context(), ArchiveFilter{Cluster: "c1"})
+	if err != nil || len(got) != 2 || !got[0].Archived {
+		t.Fatalf("unexpected archive list: %v %+v", err, got)
+	}
+	if got[0].Status != "High" || got[1].Status != "Critical" || got[0].ID == got[1].ID {
+		t.Errorf("expected both deletions, newest first, got %+v", got)
+	}
+	got, _ = s.ListArchived(t.Context(), ArchiveFilter{Since: first.Add(30 * time.Minute)})
+	if len(got) != 1 || got[0].Status != "High" {
+		t.Errorf("expected the later deletion since the filter, got %+v", got)
+	}
+}
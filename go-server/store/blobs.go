@@ -0,0 +1,43 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SaveReportBlob stores the large fields of an oversized report detail, replacing any
+// previous blob of the same report.
+func (s *Store) SaveReportBlob(ctx context.Context, ref ReportRef, data []byte) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO report_blobs (cluster, namespace, report_type, report_name, data, size, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (cluster, namespace, report_type, report_name)
+		DO UPDATE SET data = excluded.data, size = excluded.size, updated_at = excluded.updated_at`,
+		ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName, data, len(data), time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to save report blob: %w", err)
+	}
+	return nil
+}
+
+// GetReportBlob returns the stored blob of a report; found is false when there is none.
+func (s *Store) GetReportBlob(ctx context.Context, ref ReportRef) (data []byte, found bool, err error) {
+	err = s.db.QueryRowContext(ctx, `SELECT data FROM report_blobs
+		WHERE cluster = ? AND namespace = ? AND report_type = ? AND report_name = ?`,
+		ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load report blob: %w", err)
+	}
+	return data, true, nil
+}
+
+func (s *Store) DeleteReportBlob(ctx context.Context, ref ReportRef) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM report_blobs WHERE cluster = ? AND namespace = ? AND report_type = ? AND report_name = ?`,
+		ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName)
+	return err
+}
@@ -0,0 +1,28 @@
+package store
+
+import "testing"
+
+func TestReportBlobRoundTrip(t *testing.T) {
+	s := newTestStore(t)
+	if _, found, err := s.GetReportBlob(t.Context(), testRef); err != nil || found {
+		t.Fatalf("expected no blob, got %v %v", found, err)
+	}
+
+	if err := s.SaveReportBlob(t.Context(), testRef, []byte(`{"vulnerabilities":[1]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveReportBlob(t.Context(), testRef, []byte(`{"vulnerabilities":[2]}`)); err != nil {
+		t.Fatal(err)
+	}
+	data, found, err := s.GetReportBlob(t.Context(), testRef)
+	if err != nil || !found || string(data) != `{"vulnerabilities":[2]}` {
+		t.Fatalf("expected the latest blob, got %q %v %v", data, found, err)
+	}
+
+	if err := s.DeleteReportBlob(t.Context(), testRef); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := s.GetReportBlob(t.Context(), testRef); found {
+		t.Fatal("expected blob to be deleted")
+	}
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListClusterTags returns the tags set through the API, by cluster.
+func (s *Store) ListClusterTags(ctx context.Context) (map[string]map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT cluster, key, value FROM cluster_tags ORDER BY cluster, key`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[string]map[string]string)
+	for rows.Next() {
+		var cluster, key, value string
+		if err := rows.Scan(&cluster, &key, &value); err != nil {
+			return nil, err
+		}
+		if tags[cluster] == nil {
+			tags[cluster] = make(map[string]string)
+		}
+		tags[cluster][key] = value
+	}
+	return tags, rows.Err()
+}
+
+// SetClusterTags replaces the tags of a cluster; no tags removes them all.
+func (s *Store) SetClusterTags(ctx context.Context, cluster string, tags map[string]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cluster_tags WHERE cluster = ?`, cluster); err != nil {
+		return fmt.Errorf("failed to clear cluster tags: %w", err)
+	}
+	for key, value := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO cluster_tags (cluster, key, value) VALUES (?, ?, ?)`, cluster, key, value); err != nil {
+			return fmt.Errorf("failed to set cluster tag: %w", err)
+		}
+	}
+	return tx.Commit()
+}
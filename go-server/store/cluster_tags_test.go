@@ -0,0 +1,32 @@
+package store
+
+import "testing"
+
+func TestClusterTags(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.SetClusterTags(t.Context(), "prod-eu", map[string]string{"region": "eu-west-1", "tier": "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetClusterTags(t.Context(), "dev", map[string]string{"tier": "dev"}); err != nil {
+		t.Fatal(err)
+	}
+	// setting replaces the previous tags
+	if err := s.SetClusterTags(t.Context(), "prod-eu", map[string]string{"tier": "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	tags, err := s.ListClusterTags(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || len(tags["prod-eu"]) != 1 || tags["prod-eu"]["tier"] != "prod" || tags["dev"]["tier"] != "dev" {
+		t.Fatalf("tags = %v", tags)
+	}
+
+	if err := s.SetClusterTags(t.Context(), "dev", nil); err != nil {
+		t.Fatal(err)
+	}
+	tags, _ = s.ListClusterTags(t.Context())
+	if _, ok := tags["dev"]; ok {
+		t.Fatalf("dev tags not removed: %v", tags)
+	}
+}
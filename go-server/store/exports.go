@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	ExportStatusSuccess = "success"
+	ExportStatusFailed  = "failed"
+	// ExportStatusMuted marks a run skipped because a mute window was open
+	ExportStatusMuted = "muted"
+)
+
+// ExportQuery selects the reports of a scheduled export, like the list endpoint parameters.
+type ExportQuery struct {
+	Type           string   `json:"type"`
+	Cluster        string   `json:"cluster,omitempty"`
+	Namespaces     []string `json:"namespaces,omitempty"`
+	Search         string   `json:"search,omitempty"`
+	Filter         string   `json:"filter,omitempty"`
+	OnlyVulnerable bool     `json:"onlyVulnerable,omitempty"`
+}
+
+// ExportSchedule is a saved export run on a cron expression.
+type ExportSchedule struct {
+	ID          int64       `json:"id"`
+	Name        string      `json:"name"`
+	Cron        string      `json:"cron"`
+	Format      string      `json:"format"`
+	Query       ExportQuery `json:"query"`
+	Destination string      `json:"destination"`
+	Target      string      `json:"target"`
+	Enabled     bool        `json:"enabled"`
+	LastRunAt   *time.Time  `json:"lastRunAt,omitempty"`
+	LastStatus  string      `json:"lastStatus,omitempty"`
+	LastError   string      `json:"lastError,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+const exportScheduleColumns = `id, name, cron, format, query, destination, target, enabled, last_run_at, last_status, last_error, created_at, updated_at`
+
+func scanExportSchedule(row interface{ Scan(...interface{}) error }) (ExportSchedule, error) {
+	var s ExportSchedule
+	var query string
+	var lastRun sql.NullInt64
+	var created, updated int64
+	err := row.Scan(&s.ID, &s.Name, &s.Cron, &s.Format, &query, &s.Destination, &s.Target, &s.Enabled,
+		&lastRun, &s.LastStatus, &s.LastError, &created, &updated)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal([]byte(query), &s.Query); err != nil {
+		return s, fmt.Errorf("invalid stored export query: %w", err)
+	}
+	if lastRun.Valid {
+		t := time.Unix(lastRun.Int64, 0).UTC()
+		s.LastRunAt = &t
+	}
+	s.CreatedAt = time.Unix(created, 0).UTC()
+	s.UpdatedAt = time.Unix(updated, 0).UTC()
+	return s, nil
+}
+
+func (s *Store) CreateExportSchedule(ctx context.Context, sched ExportSchedule) (ExportSchedule, error) {
+	query, err := json.Marshal(sched.Query)
+	if err != nil {
+		return sched, err
+	}
+	now := time.Now()
+	res, err := s.db.ExecContext(ctx, `INSERT INTO export_schedules (name, cron, format, query, destination, target, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sched.Name, sched.Cron, sched.Format, string(query), sched.Destination, sched.Target, sched.Enabled, now.Unix(), now.Unix())
+	if err != nil {
+		return sched, fmt.Errorf("failed to create export schedule: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return s.GetExportSchedule(ctx, id)
+}
+
+func (s *Store) GetExportSchedule(ctx context.Context, id int64) (ExportSchedule, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+exportScheduleColumns+` FROM export_schedules WHERE id = ?`, id)
+	sched, err := scanExportSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sched, ErrNotFound
+	}
+	return sched, err
+}
+
+func (s *Store) ListExportSchedules(ctx context.Context) ([]ExportSchedule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+exportScheduleColumns+` FROM export_schedules ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []ExportSchedule{}
+	for rows.Next() {
+		sched, err := scanExportSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, sched)
+	}
+	return schedules, rows.Err()
+}
+
+// UpdateExportSchedule replaces the editable fields; run history is kept.
+func (s *Store) UpdateExportSchedule(ctx context.Context, sched ExportSchedule) (ExportSchedule, error) {
+	query, err := json.Marshal(sched.Query)
+	if err != nil {
+		return sched, err
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE export_schedules SET name = ?, cron = ?, format = ?, query = ?, destination = ?,
+		target = ?, enabled = ?, updated_at = ? WHERE id = ?`,
+		sched.Name, sched.Cron, sched.Format, string(query), sched.Destination, sched.Target, sched.Enabled,
+		time.Now().Unix(), sched.ID)
+	if err != nil {
+		return sched, fmt.Errorf("failed to update export schedule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sched, ErrNotFound
+	}
+	return s.GetExportSchedule(ctx, sched.ID)
+}
+
+func (s *Store) DeleteExportSchedule(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM export_schedules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete export schedule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordExportRun stores the outcome of a run; errMsg is empty on success.
+func (s *Store) RecordExportRun(ctx context.Context, id int64, at time.Time, errMsg string) error {
+	status := ExportStatusSuccess
+	if errMsg != "" {
+		status = ExportStatusFailed
+	}
+	_, err := s.db.ExecContext(ctx, `UPDATE export_schedules SET last_run_at = ?, last_status = ?, last_error = ? WHERE id = ?`,
+		at.Unix(), status, errMsg, id)
+	return err
+}
+
+// RecordExportMuted records a run that was skipped by a mute window, so the schedule moves
+// on to its next match instead of delivering once the window closes.
+func (s *Store) RecordExportMuted(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE export_schedules SET last_run_at = ?, last_status = ?, last_error = '' WHERE id = ?`,
+		at.Unix(), ExportStatusMuted, id)
+	return err
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExportSchedule_CRUD(t *testing.T) {
+	s := newTestStore(t)
+
+	created, err := s.CreateExportSchedule(t.Context(), ExportSchedule{
+		Name: "weekly", Cron: "0 6 * * mon", Format: "csv",
+		Query:       ExportQuery{Type: "vulnerabilityreports", Namespaces: []string{"payments"}, OnlyVulnerable: true},
+		Destination: "webhook", Target: "https://hooks.example.com/x", Enabled: true,
+	})
+	if err != nil || created.ID == 0 {
+		t.Fatalf("create: %v %+v", err, created)
+	}
+	if created.Query.Namespaces[0] != "payments" || !created.Enabled || created.LastRunAt != nil {
+		t.Fatalf("unexpected schedule: %+v", created)
+	}
+
+	created.Enabled = false
+	created.Cron = "@daily"
+	updated, err := s.UpdateExportSchedule(t.Context(), created)
+	if err != nil || updated.Enabled || updated.Cron != "@daily" {
+		t.Fatalf("update: %v %+v", err, updated)
+	}
+
+	at := time.Date(2026, 3, 9, 6, 0, 0, 0, time.UTC)
+	if err := s.RecordExportRun(t.Context(), created.ID, at, "webhook returned 502"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetExportSchedule(t.Context(), created.ID)
+	if err != nil || got.LastRunAt == nil || !got.LastRunAt.Equal(at) || got.LastStatus != ExportStatusFailed {
+		t.Fatalf("unexpected run record: %v %+v", err, got)
+	}
+
+	list, err := s.ListExportSchedules(t.Context())
+	if err != nil || len(list) != 1 {
+		t.Fatalf("list: %v %d", err, len(list))
+	}
+
+	if err := s.DeleteExportSchedule(t.Context(), created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetExportSchedule(t.Context(), created.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := s.DeleteExportSchedule(t.Context(), created.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for second delete, got %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ReportRef struct {
+	Cluster    string `json:"cluster"`
+	Namespace  string `json:"namespace"`
+	ReportType string `json:"type"`
+	ReportName string `json:"name"`
+}
+
+type Finding struct {
+	ReportRef
+	FindingID        string     `json:"findingId"`
+	Resource         string     `json:"resource,omitempty"`
+	Severity         string     `json:"severity"`
+	InstalledVersion string     `json:"installedVersion,omitempty"`
+	FixedVersion     string     `json:"fixedVersion,omitempty"`
+	FirstSeen        time.Time  `json:"firstSeen"`
+	LastSeen         time.Time  `json:"lastSeen"`
+	ResolvedAt       *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// FindingFilter narrows listed findings; an empty field matches every finding.
+type FindingFilter struct {
+	// Clusters matches findings in any of them
+	Clusters   []string
+	Namespace  string
+	Severities []string
+}
+
+type findingKey struct {
+	id       string
+	resource string
+}
+
+// SyncFindings reconciles the stored findings of one report with its current contents:
+// new findings get first_seen=now, disappeared ones are resolved, and reappearing ones
+// are reopened keeping their original first_seen.
+func (s *Store) SyncFindings(ctx context.Context, ref ReportRef, findings []Finding, now time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT finding_id, resource, resolved_at FROM findings
+		WHERE cluster = ? AND namespace = ? AND report_type = ? AND report_name = ?`,
+		ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName)
+	if err != nil {
+		return fmt.Errorf("failed to load findings: %w", err)
+	}
+	existing := make(map[findingKey]bool) // value: resolved
+	for rows.Next() {
+		var k findingKey
+		var resolved sql.NullInt64
+		if err := rows.Scan(&k.id, &k.resource, &resolved); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[k] = resolved.Valid
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	ts := now.Unix()
+	seen := make(map[findingKey]bool, len(findings))
+	for _, f := range findings {
+		k := findingKey{id: f.FindingID, resource: f.Resource}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		resolved, found := existing[k]
+		switch {
+		case !found:
+			_, err = tx.ExecContext(ctx, `INSERT INTO findings (cluster, namespace, report_type, report_name, finding_id, resource,
+				severity, installed_version, fixed_version, first_seen, last_seen)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName, f.FindingID, f.Resource,
+				f.Severity, f.InstalledVersion, f.FixedVersion, ts, ts)
+		case resolved:
+			_, err = tx.ExecContext(ctx, `UPDATE findings SET resolved_at = NULL, last_seen = ?, severity = ?, installed_version = ?, fixed_version = ?
+				WHERE cluster = ? AND namespace = ? AND report_type = ? AND report_name = ? AND finding_id = ? AND resource = ?`,
+				ts, f.Severity, f.InstalledVersion, f.FixedVersion,
+				ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName, f.FindingID, f.Resource)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to store finding: %w", err)
+		}
+	}
+
+	for k, resolved := range existing {
+		if resolved || seen[k] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE findings SET resolved_at = ?
+			WHERE cluster = ? AND namespace = ? AND report_type = ? AND report_name = ? AND finding_id = ? AND resource = ?`,
+			ts, ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName, k.id, k.resource); err != nil {
+			return fmt.Errorf("failed to resolve finding: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE findings SET last_seen = ?
+		WHERE cluster = ? AND namespace = ? AND report_type = ? AND report_name = ? AND resolved_at IS NULL`,
+		ts, ref.Cluster, ref.Namespace, ref.ReportType, ref.ReportName); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// OpenFindingReports lists the reports of a cluster that still have unresolved findings.
+func (s *Store) OpenFindingReports(ctx context.Context, cluster string) ([]ReportRef, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT cluster, namespace, report_type, report_name FROM findings
+		WHERE cluster = ? AND resolved_at IS NULL`, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open findings: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []ReportRef
+	for rows.Next() {
+		var ref ReportRef
+		if err := rows.Scan(&ref.Cluster, &ref.Namespace, &ref.ReportType, &ref.ReportName); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// PurgeNamespace resolves the open findings of a deleted namespace and drops its report
+// blobs. It returns how many findings were resolved.
+func (s *Store) PurgeNamespace(ctx context.Context, cluster, namespace string, now time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE findings SET resolved_at = ?
+		WHERE cluster = ? AND namespace = ? AND resolved_at IS NULL`, now.Unix(), cluster, namespace)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve namespace findings: %w", err)
+	}
+	resolved, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM report_blobs WHERE cluster = ? AND namespace = ?`, cluster, namespace); err != nil {
+		return 0, fmt.Errorf("failed to delete namespace report blobs: %w", err)
+	}
+	return resolved, tx.Commit()
+}
+
+const findingColumns = `f.cluster, f.namespace, f.report_type, f.report_name, f.finding_id, f.resource, f.severity,
+	f.installed_version, f.fixed_version, f.first_seen, f.last_seen, f.resolved_at`
+
+func scanFinding(row interface{ Scan(...interface{}) error }) (Finding, error) {
+	var f Finding
+	var first, last int64
+	var resolved sql.NullInt64
+	err := row.Scan(&f.Cluster, &f.Namespace, &f.ReportType, &f.ReportName, &f.FindingID, &f.Resource, &f.Severity,
+		&f.InstalledVersion, &f.FixedVersion, &first, &last, &resolved)
+	if err != nil {
+		return f, err
+	}
+	f.FirstSeen = time.Unix(first, 0).UTC()
+	f.LastSeen = time.Unix(last, 0).UTC()
+	if resolved.Valid {
+		t := time.Unix(resolved.Int64, 0).UTC()
+		f.ResolvedAt = &t
+	}
+	return f, nil
+}
+
+// triageJoin excludes findings whose risk has been accepted or that were marked fixed.
+const triageJoin = ` LEFT JOIN triage t ON t.cluster = f.cluster AND t.namespace = f.namespace
+	AND t.report_type = f.report_type AND t.report_name = f.report_name
+	AND t.finding_id = f.finding_id AND t.resource = f.resource`
+
+type OverdueFinding struct {
+	Finding
+	DueAt     time.Time `json:"dueAt"`
+	OverdueBy string    `json:"overdueBy"`
+}
+
+// ListOverdue returns open findings whose age exceeds the SLA window for their severity.
+func (s *Store) ListOverdue(ctx context.Context, windows map[string]time.Duration, f FindingFilter, now time.Time) ([]OverdueFinding, error) {
+	var severityClauses []string
+	var args []interface{}
+	for sev, window := range windows {
+		if len(f.Severities) > 0 && !containsFold(f.Severities, sev) {
+			continue
+		}
+		severityClauses = append(severityClauses, "(f.severity = ? AND f.first_seen < ?)")
+		args = append(args, sev, now.Add(-window).Unix())
+	}
+	if len(severityClauses) == 0 {
+		return []OverdueFinding{}, nil
+	}
+
+	query := `SELECT ` + findingColumns + ` FROM findings f` + triageJoin + `
+		WHERE f.resolved_at IS NULL AND (t.state IS NULL OR t.state NOT IN ('accepted', 'fixed'))
+		AND (` + strings.Join(severityClauses, " OR ") + `)`
+	if len(f.Clusters) > 0 {
+		query += " AND f.cluster IN (?" + strings.Repeat(", ?", len(f.Clusters)-1) + ")"
+		for _, c := range f.Clusters {
+			args = append(args, c)
+		}
+	}
+	if f.Namespace != "" {
+		query += " AND f.namespace = ?"
+		args = append(args, f.Namespace)
+	}
+	query += " ORDER BY f.first_seen ASC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue findings: %w", err)
+	}
+	defer rows.Close()
+
+	result := []OverdueFinding{}
+	for rows.Next() {
+		finding, err := scanFinding(rows)
+		if err != nil {
+			return nil, err
+		}
+		due := finding.FirstSeen.Add(windows[finding.Severity])
+		result = append(result, OverdueFinding{
+			Finding:   finding,
+			DueAt:     due,
+			OverdueBy: now.Sub(due).Truncate(time.Minute).String(),
+		})
+	}
+	return result, rows.Err()
+}
+
+type SLACompliance struct {
+	Severity          string  `json:"severity"`
+	WindowDays        float64 `json:"windowDays"`
+	Total             int     `json:"total"`
+	Overdue           int     `json:"overdue"`
+	Breached          int     `json:"breached"`
+	CompliancePercent float64 `json:"compliancePercent"`
+}
+
+// SLACompliance computes, per severity, the share of findings that were resolved or are
+// still open within their SLA window. Overdue counts open findings past due; Breached
+// additionally includes findings that were resolved late. Accepted findings are excluded.
+// Findings are counted in the given clusters, or in all clusters when none are given.
+func (s *Store) SLACompliance(ctx context.Context, windows map[string]time.Duration, clusters []string, now time.Time) ([]SLACompliance, error) {
+	result := make([]SLACompliance, 0, len(windows))
+	for sev, window := range windows {
+		query := `SELECT COUNT(*),
+			COALESCE(SUM(CASE WHEN f.resolved_at IS NULL AND f.first_seen < ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN f.resolved_at IS NOT NULL AND f.resolved_at - f.first_seen > ? THEN 1 ELSE 0 END), 0)
+			FROM findings f` + triageJoin + `
+			WHERE f.severity = ? AND (t.state IS NULL OR t.state != 'accepted')`
+		args := []interface{}{now.Add(-window).Unix(), int64(window.Seconds()), sev}
+		if len(clusters) > 0 {
+			query += " AND f.cluster IN (?" + strings.Repeat(", ?", len(clusters)-1) + ")"
+			for _, c := range clusters {
+				args = append(args, c)
+			}
+		}
+
+		var total, overdue, lateResolved int
+		if err := s.db.QueryRowContext(ctx, query, args...).Scan(&total, &overdue, &lateResolved); err != nil {
+			return nil, fmt.Errorf("failed to compute SLA compliance: %w", err)
+		}
+		c := SLACompliance{
+			Severity:          sev,
+			WindowDays:        window.Hours() / 24,
+			Total:             total,
+			Overdue:           overdue,
+			Breached:          overdue + lateResolved,
+			CompliancePercent: 100,
+		}
+		if total > 0 {
+			c.CompliancePercent = float64(total-c.Breached) * 100 / float64(total)
+		}
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].WindowDays < result[j].WindowDays })
+	return result, nil
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
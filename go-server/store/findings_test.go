@@ -0,0 +1,157 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+var testRef = ReportRef{Cluster: "c1", Namespace: "default", ReportType: "vulnerabilityreports", ReportName: "replicaset-app"}
+
+func finding(id, severity string) Finding {
+	return Finding{ReportRef: testRef, FindingID: id, Resource: "openssl", Severity: severity}
+}
+
+func TestSyncFindings_KeepsFirstSeenAcrossResolveAndReopen(t *testing.T) {
+	s := newTestStore(t)
+	t0 := time.Now().Add(-10 * 24 * time.Hour)
+
+	if err := s.SyncFindings(t.Context(), testRef, []Finding{finding("CVE-1", "CRITICAL"), finding("CVE-2", "HIGH")}, t0); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := s.SyncFindings(t.Context(), testRef, []Finding{finding("CVE-1", "CRITICAL")}, t0.Add(time.Hour)); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := s.SyncFindings(t.Context(), testRef, []Finding{finding("CVE-1", "CRITICAL"), finding("CVE-2", "HIGH")}, t0.Add(2*time.Hour)); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	windows := map[string]time.Duration{"CRITICAL": 7 * 24 * time.Hour, "HIGH": 30 * 24 * time.Hour}
+	overdue, err := s.ListOverdue(t.Context(), windows, FindingFilter{}, time.Now())
+	if err != nil {
+		t.Fatalf("overdue: %v", err)
+	}
+	if len(overdue) != 1 || overdue[0].FindingID != "CVE-1" {
+		t.Fatalf("expected only CVE-1 overdue, got %+v", overdue)
+	}
+	if overdue[0].FirstSeen.Unix() != t0.Unix() {
+		t.Fatalf("first seen should be preserved, got %v", overdue[0].FirstSeen)
+	}
+}
+
+func TestListOverdue_Clusters(t *testing.T) {
+	s := newTestStore(t)
+	t0 := time.Now().Add(-10 * 24 * time.Hour)
+	for _, cluster := range []string{"c1", "c2", "c3"} {
+		ref := testRef
+		ref.Cluster = cluster
+		if err := s.SyncFindings(t.Context(), ref, []Finding{{ReportRef: ref, FindingID: "CVE-1", Resource: "openssl", Severity: "CRITICAL"}}, t0); err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+	}
+
+	windows := map[string]time.Duration{"CRITICAL": 7 * 24 * time.Hour}
+	overdue, err := s.ListOverdue(t.Context(), windows, FindingFilter{Clusters: []string{"c1", "c3"}}, time.Now())
+	if err != nil {
+		t.Fatalf("overdue: %v", err)
+	}
+	if len(overdue) != 2 || overdue[0].Cluster == "c2" || overdue[1].Cluster == "c2" {
+		t.Fatalf("expected the findings of c1 and c3, got %+v", overdue)
+	}
+}
+
+func TestListOverdue_ExcludesAccepted(t *testing.T) {
+	s := newTestStore(t)
+	t0 := time.Now().Add(-10 * 24 * time.Hour)
+	if err := s.SyncFindings(t.Context(), testRef, []Finding{finding("CVE-1", "CRITICAL")}, t0); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	rec := baseRecord()
+	rec.ReportName = testRef.ReportName
+	rec.FindingID = "CVE-1"
+	rec.State = TriageAccepted
+	if _, err := s.UpsertTriage(t.Context(), rec); err != nil {
+		t.Fatalf("triage: %v", err)
+	}
+
+	windows := map[string]time.Duration{"CRITICAL": 7 * 24 * time.Hour}
+	overdue, err := s.ListOverdue(t.Context(), windows, FindingFilter{}, time.Now())
+	if err != nil || len(overdue) != 0 {
+		t.Fatalf("accepted finding should not be overdue: %v %+v", err, overdue)
+	}
+}
+
+func TestSLACompliance(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+	if err := s.SyncFindings(t.Context(), testRef, []Finding{finding("CVE-old", "CRITICAL"), finding("CVE-new", "CRITICAL")}, now.Add(-10*24*time.Hour)); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	// a finding first seen today on another report is still within its window
+	other := testRef
+	other.ReportName = "replicaset-other"
+	if err := s.SyncFindings(t.Context(), other, []Finding{{ReportRef: other, FindingID: "CVE-new", Severity: "CRITICAL"}}, now); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	stats, err := s.SLACompliance(t.Context(), map[string]time.Duration{"CRITICAL": 7 * 24 * time.Hour}, nil, now)
+	if err != nil {
+		t.Fatalf("compliance: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Total != 3 || stats[0].Overdue != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats[0].CompliancePercent < 33 || stats[0].CompliancePercent > 34 {
+		t.Fatalf("unexpected compliance: %v", stats[0].CompliancePercent)
+	}
+
+	stats, err = s.SLACompliance(t.Context(), map[string]time.Duration{"CRITICAL": 7 * 24 * time.Hour}, []string{"c2", "c3"}, now)
+	if err != nil || stats[0].Total != 0 {
+		t.Fatalf("findings of other clusters counted: %v %+v", err, stats)
+	}
+}
+
+func TestOpenFindingReports(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+	if err := s.SyncFindings(t.Context(), testRef, []Finding{finding("CVE-1", "HIGH")}, now); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	refs, err := s.OpenFindingReports(t.Context(), "c1")
+	if err != nil || len(refs) != 1 || refs[0] != testRef {
+		t.Fatalf("expected the report with open findings, got %v %+v", err, refs)
+	}
+
+	if err := s.SyncFindings(t.Context(), testRef, nil, now); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if refs, err := s.OpenFindingReports(t.Context(), "c1"); err != nil || len(refs) != 0 {
+		t.Fatalf("resolved report should not be listed: %v %+v", err, refs)
+	}
+}
+
+func TestPurgeNamespace(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+	other := ReportRef{Cluster: "c1", Namespace: "kept", ReportType: "vulnerabilityreports", ReportName: "replicaset-db"}
+	if err := s.SyncFindings(t.Context(), testRef, []Finding{finding("CVE-1", "HIGH"), finding("CVE-2", "LOW")}, now); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := s.SyncFindings(t.Context(), other, []Finding{{ReportRef: other, FindingID: "CVE-3", Severity: "HIGH"}}, now); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := s.SaveReportBlob(t.Context(), testRef, []byte("{}")); err != nil {
+		t.Fatalf("save blob: %v", err)
+	}
+
+	resolved, err := s.PurgeNamespace(t.Context(), "c1", "default", now)
+	if err != nil || resolved != 2 {
+		t.Fatalf("purge resolved %d findings: %v", resolved, err)
+	}
+	refs, err := s.OpenFindingReports(t.Context(), "c1")
+	if err != nil || len(refs) != 1 || refs[0] != other {
+		t.Fatalf("only the other namespace should have open findings: %v %+v", err, refs)
+	}
+	if _, found, err := s.GetReportBlob(t.Context(), testRef); err != nil || found {
+		t.Fatalf("blob should be deleted: found=%v err=%v", found, err)
+	}
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReportSnapshot is the state of a workload's report at one point in time. Snapshots are
+// only recorded when the report, its image or its highest severity changes.
+type ReportSnapshot struct {
+	ReportRef
+	Workload   string    `json:"workload"`
+	Image      string    `json:"image,omitempty"`
+	Severity   string    `json:"severity"`
+	Critical   int       `json:"critical"`
+	High       int       `json:"high"`
+	Medium     int       `json:"medium"`
+	Low        int       `json:"low"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+const snapshotColumns = `cluster, namespace, workload, report_type, report_name, image, severity, critical, high, medium, low, recorded_at`
+
+func scanSnapshot(row interface{ Scan(...interface{}) error }) (ReportSnapshot, error) {
+	var s ReportSnapshot
+	var recorded int64
+	err := row.Scan(&s.Cluster, &s.Namespace, &s.Workload, &s.ReportType, &s.ReportName, &s.Image, &s.Severity,
+		&s.Critical, &s.High, &s.Medium, &s.Low, &recorded)
+	s.RecordedAt = time.Unix(recorded, 0).UTC()
+	return s, err
+}
+
+// RecordReportSnapshot stores a snapshot unless the latest one of the same workload and
+// report type has the same report name, image and severity. The first snapshot of a
+// report is dated createdAt instead of RecordedAt, so reports that predate trivy-ui do
+// not all appear at its first start. It reports whether a row was written.
+func (s *Store) RecordReportSnapshot(ctx context.Context, snap ReportSnapshot, createdAt time.Time) (bool, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+snapshotColumns+` FROM report_history
+		WHERE cluster = ? AND namespace = ? AND workload = ? AND report_type = ?
+		ORDER BY recorded_at DESC, id DESC LIMIT 1`,
+		snap.Cluster, snap.Namespace, snap.Workload, snap.ReportType)
+	last, err := scanSnapshot(row)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return false, fmt.Errorf("failed to load report history: %w", err)
+	case last.ReportName == snap.ReportName && last.Image == snap.Image && last.Severity == snap.Severity:
+		return false, nil
+	}
+
+	if snap.RecordedAt.IsZero() {
+		snap.RecordedAt = time.Now()
+	}
+	if !createdAt.IsZero() {
+		var known int
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM report_history
+			WHERE cluster = ? AND namespace = ? AND report_type = ? AND report_name = ?`,
+			snap.Cluster, snap.Namespace, snap.ReportType, snap.ReportName).Scan(&known); err != nil {
+			return false, fmt.Errorf("failed to load report history: %w", err)
+		}
+		if known == 0 {
+			snap.RecordedAt = createdAt
+		}
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO report_history (`+snapshotColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snap.Cluster, snap.Namespace, snap.Workload, snap.ReportType, snap.ReportName, snap.Image, snap.Severity,
+		snap.Critical, snap.High, snap.Medium, snap.Low, snap.RecordedAt.Unix())
+	if err != nil {
+		return false, fmt.Errorf("failed to record report history: %w", err)
+	}
+	return true, nil
+}
+
+// WorkloadHistory returns the snapshots of a workload, oldest first.
+func (s *Store) WorkloadHistory(ctx context.Context, cluster, namespace, workload string) ([]ReportSnapshot, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+snapshotColumns+` FROM report_history
+		WHERE cluster = ? AND namespace = ? AND workload = ? ORDER BY recorded_at, id`,
+		cluster, namespace, workload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query report history: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ReportSnapshot
+	for rows.Next() {
+		snap, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, snap)
+	}
+	return result, rows.Err()
+}
+
+// ReportFindings returns every finding, open or resolved, of the named reports of a namespace.
+func (s *Store) ReportFindings(ctx context.Context, cluster, namespace string, reportNames []string) ([]Finding, error) {
+	if len(reportNames) == 0 {
+		return nil, nil
+	}
+	args := []interface{}{cluster, namespace}
+	for _, name := range reportNames {
+		args = append(args, name)
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT `+findingColumns+` FROM findings f
+		WHERE f.cluster = ? AND f.namespace = ? AND f.report_name IN (?`+strings.Repeat(", ?", len(reportNames)-1)+`)
+		ORDER BY f.first_seen`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query findings: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Finding
+	for rows.Next() {
+		f, err := scanFinding(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, f)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordReportSnapshot_OnlyOnChange(t *testing.T) {
+	s := newTestStore(t)
+	t0 := time.Now().Add(-time.Hour).Truncate(time.Second)
+	created := t0.Add(-24 * time.Hour)
+	snap := ReportSnapshot{ReportRef: testRef, Workload: "app", Image: "app:1", Severity: "HIGH", High: 2, RecordedAt: t0}
+
+	for i, want := range []bool{true, false} {
+		wrote, err := s.RecordReportSnapshot(t.Context(), snap, created)
+		if err != nil || wrote != want {
+			t.Fatalf("record %d: wrote=%v err=%v", i, wrote, err)
+		}
+	}
+	snap.Severity, snap.RecordedAt = "CRITICAL", t0.Add(time.Minute)
+	if wrote, _ := s.RecordReportSnapshot(t.Context(), snap, created); !wrote {
+		t.Fatal("severity change must be recorded")
+	}
+
+	history, err := s.WorkloadHistory(t.Context(), "c1", "default", "app")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("unexpected history %+v %v", history, err)
+	}
+	if !history[0].RecordedAt.Equal(created) || !history[1].RecordedAt.Equal(snap.RecordedAt) {
+		t.Fatalf("first snapshot should be dated at report creation: %+v", history)
+	}
+}
+
+func TestReportFindings(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.SyncFindings(t.Context(), testRef, []Finding{finding("CVE-1", "HIGH")}, time.Now()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	other := ReportRef{Cluster: "c1", Namespace: "default", ReportType: "vulnerabilityreports", ReportName: "other"}
+	if err := s.SyncFindings(t.Context(), other, []Finding{{ReportRef: other, FindingID: "CVE-2", Severity: "LOW"}}, time.Now()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	findings, err := s.ReportFindings(t.Context(), "c1", "default", []string{testRef.ReportName})
+	if err != nil || len(findings) != 1 || findings[0].FindingID != "CVE-1" {
+		t.Fatalf("unexpected findings %+v %v", findings, err)
+	}
+}
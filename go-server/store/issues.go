@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IssueLink records the tracker issue opened for a finding in a repository, so the same
+// finding is not filed twice. ReportRef is the report the issue was created from.
+type IssueLink struct {
+	ID        int64  `json:"id"`
+	Provider  string `json:"provider"`
+	Repo      string `json:"repo"`
+	FindingID string `json:"findingId"`
+	Resource  string `json:"resource,omitempty"`
+	Number    int    `json:"number"`
+	URL       string `json:"url"`
+	ReportRef
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type IssueLinkFilter struct {
+	Provider  string
+	Repo      string
+	FindingID string
+	Cluster   string
+}
+
+const issueLinkColumns = `id, provider, repo, finding_id, resource, issue_number, url, cluster, namespace, report_type, report_name, created_at`
+
+func scanIssueLink(row interface{ Scan(...interface{}) error }) (IssueLink, error) {
+	var l IssueLink
+	var created int64
+	err := row.Scan(&l.ID, &l.Provider, &l.Repo, &l.FindingID, &l.Resource, &l.Number, &l.URL,
+		&l.Cluster, &l.Namespace, &l.ReportType, &l.ReportName, &created)
+	l.CreatedAt = time.Unix(created, 0).UTC()
+	return l, err
+}
+
+func (s *Store) GetIssueLink(ctx context.Context, provider, repo, findingID, resource string) (IssueLink, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+issueLinkColumns+` FROM issue_links
+		WHERE provider = ? AND repo = ? AND finding_id = ? AND resource = ?`, provider, repo, findingID, resource)
+	l, err := scanIssueLink(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return l, ErrNotFound
+	}
+	return l, err
+}
+
+func (s *Store) SaveIssueLink(ctx context.Context, l IssueLink) (IssueLink, error) {
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+	res, err := s.db.ExecContext(ctx, `INSERT INTO issue_links (provider, repo, finding_id, resource, issue_number, url,
+		cluster, namespace, report_type, report_name, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		l.Provider, l.Repo, l.FindingID, l.Resource, l.Number, l.URL,
+		l.Cluster, l.Namespace, l.ReportType, l.ReportName, l.CreatedAt.Unix())
+	if err != nil {
+		return l, fmt.Errorf("failed to save issue link: %w", err)
+	}
+	l.ID, _ = res.LastInsertId()
+	return l, nil
+}
+
+func (s *Store) ListIssueLinks(ctx context.Context, f IssueLinkFilter) ([]IssueLink, error) {
+	query := `SELECT ` + issueLinkColumns + ` FROM issue_links WHERE 1=1`
+	var args []interface{}
+	add := func(col, v string) {
+		if v != "" {
+			query += " AND " + col + " = ?"
+			args = append(args, v)
+		}
+	}
+	add("provider", f.Provider)
+	add("repo", f.Repo)
+	add("finding_id", f.FindingID)
+	add("cluster", f.Cluster)
+	query += " ORDER BY created_at DESC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []IssueLink{}
+	for rows.Next() {
+		l, err := scanIssueLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// ListAffectedReports returns the reports in which a finding is currently open.
+// An empty resource matches the finding in any package.
+func (s *Store) ListAffectedReports(ctx context.Context, findingID, resource string) ([]ReportRef, error) {
+	query := `SELECT DISTINCT cluster, namespace, report_type, report_name FROM findings
+		WHERE finding_id = ? AND resolved_at IS NULL`
+	args := []interface{}{findingID}
+	if resource != "" {
+		query += " AND resource = ?"
+		args = append(args, resource)
+	}
+	query += " ORDER BY cluster, namespace, report_name"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list affected reports: %w", err)
+	}
+	defer rows.Close()
+
+	refs := []ReportRef{}
+	for rows.Next() {
+		var r ReportRef
+		if err := rows.Scan(&r.Cluster, &r.Namespace, &r.ReportType, &r.ReportName); err != nil {
+			return nil, err
+		}
+		refs = append(refs, r)
+	}
+	return refs, rows.Err()
+}
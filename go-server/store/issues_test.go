@@ -0,0 +1,47 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIssueLink_SaveAndLookup(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.GetIssueLink(t.Context(), "github", "acme/payments", "CVE-1", "openssl"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	saved, err := s.SaveIssueLink(t.Context(), IssueLink{
+		Provider: "github", Repo: "acme/payments", FindingID: "CVE-1", Resource: "openssl",
+		Number: 42, URL: "https://github.com/acme/payments/issues/42", ReportRef: testRef,
+	})
+	if err != nil || saved.ID == 0 {
+		t.Fatalf("save: %v %+v", err, saved)
+	}
+	if _, err := s.SaveIssueLink(t.Context(), saved); err == nil {
+		t.Fatal("expected duplicate link to be rejected")
+	}
+
+	got, err := s.GetIssueLink(t.Context(), "github", "acme/payments", "CVE-1", "openssl")
+	if err != nil || got.Number != 42 || got.Cluster != testRef.Cluster {
+		t.Fatalf("unexpected link: %v %+v", err, got)
+	}
+}
+
+func TestListAffectedReports_OnlyOpen(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+	other := ReportRef{Cluster: "c2", Namespace: "ns", ReportType: testRef.ReportType, ReportName: "r2"}
+	s.SyncFindings(t.Context(), testRef, []Finding{finding("CVE-1", "HIGH")}, now)
+	s.SyncFindings(t.Context(), other, []Finding{finding("CVE-1", "HIGH")}, now)
+	s.SyncFindings(t.Context(), other, nil, now)
+
+	refs, err := s.ListAffectedReports(t.Context(), "CVE-1", "")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(refs) != 1 || refs[0] != testRef {
+		t.Fatalf("unexpected refs %+v", refs)
+	}
+}
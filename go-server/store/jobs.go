@@ -0,0 +1,208 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// ErrJobFinished is returned when cancelling a job that already ended.
+var ErrJobFinished = errors.New("job already finished")
+
+// Job is a long-running task queued through the API and run by a worker. Params are
+// kept as given, their layout depends on Kind. The result, if any, is read separately.
+type Job struct {
+	ID         int64           `json:"id"`
+	Kind       string          `json:"kind"`
+	Status     string          `json:"status"`
+	Params     json.RawMessage `json:"params"`
+	Error      string          `json:"error,omitempty"`
+	ResultName string          `json:"resultName,omitempty"`
+	ResultType string          `json:"resultType,omitempty"`
+	ResultSize int             `json:"resultSize,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// JobResult is the output of a job, e.g. an export file.
+type JobResult struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+const jobColumns = `id, kind, status, params, error, result_name, result_type, result_size, created_at, started_at, finished_at`
+
+func scanJob(row interface{ Scan(...interface{}) error }) (Job, error) {
+	var j Job
+	var params string
+	var created int64
+	var started, finished sql.NullInt64
+	if err := row.Scan(&j.ID, &j.Kind, &j.Status, &params, &j.Error, &j.ResultName, &j.ResultType, &j.ResultSize,
+		&created, &started, &finished); err != nil {
+		return j, err
+	}
+	j.Params = json.RawMessage(params)
+	j.CreatedAt = time.Unix(created, 0).UTC()
+	if started.Valid {
+		t := time.Unix(started.Int64, 0).UTC()
+		j.StartedAt = &t
+	}
+	if finished.Valid {
+		t := time.Unix(finished.Int64, 0).UTC()
+		j.FinishedAt = &t
+	}
+	return j, nil
+}
+
+// CreateJob queues a job.
+func (s *Store) CreateJob(ctx context.Context, kind string, params json.RawMessage) (Job, error) {
+	res, err := s.db.ExecContext(ctx, `INSERT INTO jobs (kind, status, params, created_at) VALUES (?, ?, ?, ?)`,
+		kind, JobStatusQueued, string(params), time.Now().Unix())
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to create job: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return s.GetJob(ctx, id)
+}
+
+func (s *Store) GetJob(ctx context.Context, id int64) (Job, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id)
+	j, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return j, ErrNotFound
+	}
+	return j, err
+}
+
+// ListJobs returns the most recent jobs first.
+func (s *Store) ListJobs(ctx context.Context, limit int) ([]Job, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+jobColumns+` FROM jobs ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// ClaimJob marks the oldest queued job running and returns it; found is false when the
+// queue is empty.
+func (s *Store) ClaimJob(ctx context.Context) (job Job, found bool, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return job, false, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM jobs WHERE status = ? ORDER BY id LIMIT 1`, JobStatusQueued).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return job, false, nil
+	}
+	if err != nil {
+		return job, false, fmt.Errorf("failed to claim job: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE jobs SET status = ?, started_at = ? WHERE id = ?`,
+		JobStatusRunning, time.Now().Unix(), id); err != nil {
+		return job, false, fmt.Errorf("failed to claim job: %w", err)
+	}
+	job, err = scanJob(tx.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id))
+	if err != nil {
+		return job, false, err
+	}
+	return job, true, tx.Commit()
+}
+
+// FinishJob records the outcome of a running job; a job cancelled meanwhile stays
+// cancelled. errMsg is empty and result may be nil on success.
+func (s *Store) FinishJob(ctx context.Context, id int64, errMsg string, result *JobResult) error {
+	status := JobStatusSucceeded
+	if errMsg != "" {
+		status = JobStatusFailed
+	}
+	var name, contentType string
+	var data []byte
+	if result != nil {
+		name, contentType, data = result.Name, result.ContentType, result.Data
+	}
+	_, err := s.db.ExecContext(ctx, `UPDATE jobs SET status = ?, error = ?, result_name = ?, result_type = ?,
+		result_size = ?, result = ?, finished_at = ? WHERE id = ? AND status = ?`,
+		status, errMsg, name, contentType, len(data), data, time.Now().Unix(), id, JobStatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to record job outcome: %w", err)
+	}
+	return nil
+}
+
+// CancelJob cancels a queued or running job; it returns ErrJobFinished for jobs that ended.
+func (s *Store) CancelJob(ctx context.Context, id int64) (Job, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE jobs SET status = ?, finished_at = ? WHERE id = ? AND status IN (?, ?)`,
+		JobStatusCancelled, time.Now().Unix(), id, JobStatusQueued, JobStatusRunning)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	job, err := s.GetJob(ctx, id)
+	if err != nil {
+		return job, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return job, ErrJobFinished
+	}
+	return job, nil
+}
+
+// GetJobResult returns the result of a job; found is false when it has none. Failed jobs
+// may have one too, e.g. the outcome of the imports of a partly failed push.
+func (s *Store) GetJobResult(ctx context.Context, id int64) (result JobResult, found bool, err error) {
+	var data []byte
+	err = s.db.QueryRowContext(ctx, `SELECT result_name, result_type, result FROM jobs WHERE id = ?`,
+		id).Scan(&result.Name, &result.ContentType, &data)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && data == nil) {
+		return result, false, nil
+	}
+	if err != nil {
+		return result, false, fmt.Errorf("failed to load job result: %w", err)
+	}
+	result.Data = data
+	return result, true, nil
+}
+
+// RequeueRunningJobs puts jobs left running by a previous process back in the queue.
+func (s *Store) RequeueRunningJobs(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE jobs SET status = ?, started_at = NULL WHERE status = ?`,
+		JobStatusQueued, JobStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// DeleteFinishedJobs removes jobs, and their results, that ended before the given time.
+func (s *Store) DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM jobs WHERE finished_at IS NOT NULL AND finished_at < ?`, before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
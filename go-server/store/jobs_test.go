@@ -0,0 +1,68 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobLifecycle(t *testing.T) {
+	s := newTestStore(t)
+	first, err := s.CreateJob(t.Context(), "export", []byte(`{"format":"csv"}`))
+	if err != nil || first.Status != JobStatusQueued || string(first.Params) != `{"format":"csv"}` {
+		t.Fatalf("create: %v %+v", err, first)
+	}
+	second, _ := s.CreateJob(t.Context(), "export", []byte(`{}`))
+
+	claimed, found, err := s.ClaimJob(t.Context())
+	if err != nil || !found || claimed.ID != first.ID || claimed.Status != JobStatusRunning || claimed.StartedAt == nil {
+		t.Fatalf("claim: %v %v %+v", err, found, claimed)
+	}
+	result := &JobResult{Name: "export.csv", ContentType: "text/csv", Data: []byte("a,b\n")}
+	if err := s.FinishJob(t.Context(), first.ID, "", result); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.GetJob(t.Context(), first.ID)
+	if got.Status != JobStatusSucceeded || got.ResultSize != 4 || got.FinishedAt == nil {
+		t.Fatalf("finished job: %+v", got)
+	}
+	stored, found, err := s.GetJobResult(t.Context(), first.ID)
+	if err != nil || !found || stored.Name != "export.csv" || string(stored.Data) != "a,b\n" {
+		t.Fatalf("result: %v %v %+v", err, found, stored)
+	}
+	if _, err := s.CancelJob(t.Context(), first.ID); !errors.Is(err, ErrJobFinished) {
+		t.Fatalf("cancelling a finished job: %v", err)
+	}
+
+	// a job cancelled while running keeps its status when the worker finishes
+	if _, _, err := s.ClaimJob(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if cancelled, err := s.CancelJob(t.Context(), second.ID); err != nil || cancelled.Status != JobStatusCancelled {
+		t.Fatalf("cancel: %v %+v", err, cancelled)
+	}
+	s.FinishJob(t.Context(), second.ID, "", result)
+	if got, _ := s.GetJob(t.Context(), second.ID); got.Status != JobStatusCancelled {
+		t.Fatalf("cancelled job overwritten: %+v", got)
+	}
+	if _, found, _ := s.ClaimJob(t.Context()); found {
+		t.Fatal("expected an empty queue")
+	}
+
+	n, err := s.DeleteFinishedJobs(t.Context(), time.Now().Add(time.Second))
+	if err != nil || n != 2 {
+		t.Fatalf("purge: %v %d", err, n)
+	}
+}
+
+func TestRequeueRunningJobs(t *testing.T) {
+	s := newTestStore(t)
+	job, _ := s.CreateJob(t.Context(), "export", []byte(`{}`))
+	s.ClaimJob(t.Context())
+	if n, err := s.RequeueRunningJobs(t.Context()); err != nil || n != 1 {
+		t.Fatalf("requeue: %v %d", err, n)
+	}
+	if got, _ := s.GetJob(t.Context(), job.ID); got.Status != JobStatusQueued || got.StartedAt != nil {
+		t.Fatalf("requeued job: %+v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MuteWindow silences scheduled notifications for one cluster, or for all clusters when
+// Cluster is empty. Ad-hoc windows run from StartsAt to EndsAt; recurring windows open at
+// every match of Cron and stay open for DurationMinutes.
+type MuteWindow struct {
+	ID              int64      `json:"id"`
+	Cluster         string     `json:"cluster,omitempty"`
+	Reason          string     `json:"reason,omitempty"`
+	StartsAt        *time.Time `json:"startsAt,omitempty"`
+	EndsAt          *time.Time `json:"endsAt,omitempty"`
+	Cron            string     `json:"cron,omitempty"`
+	DurationMinutes int        `json:"durationMinutes,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+const muteWindowColumns = `id, cluster, reason, starts_at, ends_at, cron, duration_minutes, created_at`
+
+func scanMuteWindow(row interface{ Scan(...interface{}) error }) (MuteWindow, error) {
+	var m MuteWindow
+	var startsAt, endsAt sql.NullInt64
+	var created int64
+	if err := row.Scan(&m.ID, &m.Cluster, &m.Reason, &startsAt, &endsAt, &m.Cron, &m.DurationMinutes, &created); err != nil {
+		return m, err
+	}
+	if startsAt.Valid {
+		t := time.Unix(startsAt.Int64, 0).UTC()
+		m.StartsAt = &t
+	}
+	if endsAt.Valid {
+		t := time.Unix(endsAt.Int64, 0).UTC()
+		m.EndsAt = &t
+	}
+	m.CreatedAt = time.Unix(created, 0).UTC()
+	return m, nil
+}
+
+func nullUnix(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.Unix(), Valid: true}
+}
+
+func (s *Store) CreateMuteWindow(ctx context.Context, m MuteWindow) (MuteWindow, error) {
+	res, err := s.db.ExecContext(ctx, `INSERT INTO mute_windows (cluster, reason, starts_at, ends_at, cron, duration_minutes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		m.Cluster, m.Reason, nullUnix(m.StartsAt), nullUnix(m.EndsAt), m.Cron, m.DurationMinutes, time.Now().Unix())
+	if err != nil {
+		return m, fmt.Errorf("failed to create mute window: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return s.GetMuteWindow(ctx, id)
+}
+
+func (s *Store) GetMuteWindow(ctx context.Context, id int64) (MuteWindow, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+muteWindowColumns+` FROM mute_windows WHERE id = ?`, id)
+	m, err := scanMuteWindow(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return m, ErrNotFound
+	}
+	return m, err
+}
+
+func (s *Store) ListMuteWindows(ctx context.Context) ([]MuteWindow, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+muteWindowColumns+` FROM mute_windows ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mute windows: %w", err)
+	}
+	defer rows.Close()
+
+	windows := []MuteWindow{}
+	for rows.Next() {
+		m, err := scanMuteWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, m)
+	}
+	return windows, rows.Err()
+}
+
+func (s *Store) DeleteMuteWindow(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM mute_windows WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete mute window: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteExpiredMuteWindows removes ad-hoc windows that ended before the given time;
+// recurring windows are kept until deleted.
+func (s *Store) DeleteExpiredMuteWindows(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM mute_windows WHERE cron = '' AND ends_at IS NOT NULL AND ends_at < ?`, before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired mute windows: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMuteWindow_CRUD(t *testing.T) {
+	s := newTestStore(t)
+	ends := time.Date(2026, 3, 9, 18, 0, 0, 0, time.UTC)
+	adhoc, err := s.CreateMuteWindow(t.Context(), MuteWindow{Cluster: "prod", Reason: "1.31 upgrade", EndsAt: &ends})
+	if err != nil || adhoc.ID == 0 || adhoc.StartsAt != nil || !adhoc.EndsAt.Equal(ends) {
+		t.Fatalf("create: %v %+v", err, adhoc)
+	}
+	recurring, err := s.CreateMuteWindow(t.Context(), MuteWindow{Cron: "0 2 * * sat", DurationMinutes: 120})
+	if err != nil || recurring.Cron != "0 2 * * sat" || recurring.DurationMinutes != 120 || recurring.EndsAt != nil {
+		t.Fatalf("create recurring: %v %+v", err, recurring)
+	}
+
+	n, err := s.DeleteExpiredMuteWindows(t.Context(), ends.Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("purge: %v %d", err, n)
+	}
+	list, err := s.ListMuteWindows(t.Context())
+	if err != nil || len(list) != 1 || list[0].ID != recurring.ID {
+		t.Fatalf("list after purge: %v %+v", err, list)
+	}
+
+	if err := s.DeleteMuteWindow(t.Context(), recurring.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetMuteWindow(t.Context(), recurring.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestRecordExportMuted(t *testing.T) {
+	s := newTestStore(t)
+	sched, err := s.CreateExportSchedule(t.Context(), ExportSchedule{
+		Name: "daily", Cron: "@daily", Format: "csv", Query: ExportQuery{Type: "vulnerabilityreports"},
+		Destination: "webhook", Target: "https://hooks.example.com/x", Enabled: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordExportRun(t.Context(), sched.ID, time.Now(), "webhook returned 502"); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 9, 6, 0, 0, 0, time.UTC)
+	if err := s.RecordExportMuted(t.Context(), sched.ID, at); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.GetExportSchedule(t.Context(), sched.ID)
+	if got.LastStatus != ExportStatusMuted || got.LastError != "" || !got.LastRunAt.Equal(at) {
+		t.Fatalf("unexpected muted run record: %+v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PruneResult counts the rows a retention pass deleted for one report kind.
+type PruneResult struct {
+	History  int64 `json:"history"`
+	Archived int64 `json:"archived"`
+}
+
+// RetainedReportTypes returns the report types that have history or archived reports.
+func (s *Store) RetainedReportTypes(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT report_type FROM report_history
+		UNION SELECT report_type FROM archived_reports ORDER BY report_type`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+// PruneReportData deletes the history snapshots and archived reports of a report type
+// recorded before the given time. The latest snapshot of each workload is kept however
+// old, as it is the workload's current state.
+func (s *Store) PruneReportData(ctx context.Context, reportType string, before time.Time) (PruneResult, error) {
+	var result PruneResult
+	res, err := s.db.ExecContext(ctx, `DELETE FROM report_history WHERE id IN (
+		SELECT id FROM (
+			SELECT id, recorded_at, ROW_NUMBER() OVER (
+				PARTITION BY cluster, namespace, workload ORDER BY recorded_at DESC, id DESC) AS pos
+			FROM report_history WHERE report_type = ?)
+		WHERE pos > 1 AND recorded_at < ?)`, reportType, before.Unix())
+	if err != nil {
+		return result, fmt.Errorf("failed to prune report history: %w", err)
+	}
+	result.History, _ = res.RowsAffected()
+
+	res, err = s.db.ExecContext(ctx, `DELETE FROM archived_reports WHERE report_type = ? AND archived_at < ?`,
+		reportType, before.Unix())
+	if err != nil {
+		return result, fmt.Errorf("failed to prune archived reports: %w", err)
+	}
+	result.Archived, _ = res.RowsAffected()
+	return result, nil
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPruneReportData(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now().Truncate(time.Second)
+	old := now.Add(-60 * 24 * time.Hour)
+
+	// three snapshots of one workload, the two oldest past retention
+	snap := ReportSnapshot{ReportRef: testRef, Workload: "app", Severity: "LOW"}
+	for i, severity := range []string{"LOW", "HIGH", "CRITICAL"} {
+		snap.Severity, snap.RecordedAt = severity, old.Add(time.Duration(i)*time.Hour)
+		if i == 2 {
+			snap.RecordedAt = now
+		}
+		if _, err := s.RecordReportSnapshot(t.Context(), snap, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a workload whose only snapshot is old keeps it
+	idle := ReportSnapshot{ReportRef: ReportRef{Cluster: "c1", Namespace: "default", ReportType: testRef.ReportType, ReportName: "idle"},
+		Workload: "idle", Severity: "LOW", RecordedAt: old}
+	if _, err := s.RecordReportSnapshot(t.Context(), idle, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ArchiveReport(t.Context(), ArchivedReport{ReportRef: testRef, ArchivedAt: old}); err != nil {
+		t.Fatal(err)
+	}
+	other := ReportRef{Cluster: "c1", Namespace: "default", ReportType: "configauditreports", ReportName: "cfg"}
+	if err := s.ArchiveReport(t.Context(), ArchivedReport{ReportRef: other, ArchivedAt: old}); err != nil {
+		t.Fatal(err)
+	}
+
+	types, err := s.RetainedReportTypes(t.Context())
+	if err != nil || !reflect.DeepEqual(types, []string{"configauditreports", testRef.ReportType}) {
+		t.Fatalf("unexpected report types %v %v", types, err)
+	}
+
+	result, err := s.PruneReportData(t.Context(), testRef.ReportType, now.Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.History != 2 || result.Archived != 1 {
+		t.Fatalf("unexpected prune result %+v", result)
+	}
+	if history, _ := s.WorkloadHistory(t.Context(), "c1", "default", "app"); len(history) != 1 || history[0].Severity != "CRITICAL" {
+		t.Errorf("unexpected remaining history %+v", history)
+	}
+	if history, _ := s.WorkloadHistory(t.Context(), "c1", "default", "idle"); len(history) != 1 {
+		t.Errorf("latest snapshot of an idle workload pruned")
+	}
+	if archived, _ := s.ListArchived(t.Context(), ArchiveFilter{}); len(archived) != 1 || archived[0].ReportType != "configauditreports" {
+		t.Errorf("archive of another kind pruned: %+v", archived)
+	}
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SeverityOverride replaces the severity scanners give a vulnerability, in every report.
+type SeverityOverride struct {
+	CVE       string    `json:"cve"`
+	Severity  string    `json:"severity"`
+	Reason    string    `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (s *Store) ListSeverityOverrides(ctx context.Context) ([]SeverityOverride, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT cve_id, severity, reason, updated_at FROM severity_overrides ORDER BY cve_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list severity overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := []SeverityOverride{}
+	for rows.Next() {
+		var o SeverityOverride
+		var updated int64
+		if err := rows.Scan(&o.CVE, &o.Severity, &o.Reason, &updated); err != nil {
+			return nil, err
+		}
+		o.UpdatedAt = time.Unix(updated, 0).UTC()
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+// SetSeverityOverride creates or replaces the override of a CVE.
+func (s *Store) SetSeverityOverride(ctx context.Context, o SeverityOverride) (SeverityOverride, error) {
+	o.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	_, err := s.db.ExecContext(ctx, `INSERT INTO severity_overrides (cve_id, severity, reason, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (cve_id) DO UPDATE SET severity = excluded.severity, reason = excluded.reason, updated_at = excluded.updated_at`,
+		o.CVE, o.Severity, o.Reason, o.UpdatedAt.Unix())
+	if err != nil {
+		return o, fmt.Errorf("failed to set severity override: %w", err)
+	}
+	return o, nil
+}
+
+func (s *Store) DeleteSeverityOverride(ctx context.Context, cve string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM severity_overrides WHERE cve_id = ?`, cve)
+	if err != nil {
+		return fmt.Errorf("failed to delete severity override: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSeverityOverrides(t *testing.T) {
+	s := newTestStore(t)
+	for _, o := range []SeverityOverride{
+		{CVE: "CVE-2024-0002", Severity: "LOW"},
+		{CVE: "CVE-2024-0001", Severity: "HIGH", Reason: "exploited"},
+		// setting again replaces
+		{CVE: "CVE-2024-0001", Severity: "CRITICAL", Reason: "exploited in our stack"},
+	} {
+		if _, err := s.SetSeverityOverride(t.Context(), o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	overrides, err := s.ListSeverityOverrides(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 2 || overrides[0].CVE != "CVE-2024-0001" || overrides[0].Severity != "CRITICAL" || overrides[0].Reason != "exploited in our stack" || overrides[0].UpdatedAt.IsZero() {
+		t.Fatalf("overrides = %+v", overrides)
+	}
+
+	if err := s.DeleteSeverityOverride(t.Context(), "CVE-2024-0002"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteSeverityOverride(t.Context(), "CVE-2024-0002"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("deleting a missing override: %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"trivy-ui/utils"
+
+	_ "modernc.org/sqlite"
+)
+
+var (
+	globalStore *Store
+	storeMu     sync.RWMutex
+)
+
+// Store is the SQLite-backed persistence layer for data that must survive
+// cache evictions and restarts (triage state, history, integrations).
+type Store struct {
+	db   *sql.DB
+	path string
+}
+
+// migrations are applied in order; never edit an existing entry, append a new one.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS triage (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		cluster TEXT NOT NULL,
+		namespace TEXT NOT NULL,
+		report_type TEXT NOT NULL,
+		report_name TEXT NOT NULL,
+		finding_id TEXT NOT NULL,
+		resource TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL,
+		assignee TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		UNIQUE (cluster, namespace, report_type, report_name, finding_id, resource)
+	);
+	CREATE INDEX IF NOT EXISTS idx_triage_state ON triage (state);
+	CREATE INDEX IF NOT EXISTS idx_triage_assignee ON triage (assignee);`,
+	`CREATE TABLE IF NOT EXISTS findings (
+		cluster TEXT NOT NULL,
+		namespace TEXT NOT NULL,
+		report_type TEXT NOT NULL,
+		report_name TEXT NOT NULL,
+		finding_id TEXT NOT NULL,
+		resource TEXT NOT NULL DEFAULT '',
+		severity TEXT NOT NULL DEFAULT '',
+		installed_version TEXT NOT NULL DEFAULT '',
+		fixed_version TEXT NOT NULL DEFAULT '',
+		first_seen INTEGER NOT NULL,
+		last_seen INTEGER NOT NULL,
+		resolved_at INTEGER,
+		PRIMARY KEY (cluster, namespace, report_type, report_name, finding_id, resource)
+	);
+	CREATE INDEX IF NOT EXISTS idx_findings_open ON findings (resolved_at, severity, first_seen);`,
+	`CREATE TABLE IF NOT EXISTS archived_reports (
+		cluster TEXT NOT NULL,
+		namespace TEXT NOT NULL,
+		report_type TEXT NOT NULL,
+		report_name TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT '',
+		data TEXT NOT NULL,
+		archived_at INTEGER NOT NULL,
+		PRIMARY KEY (cluster, namespace, report_type, report_name)
+	);
+	CREATE INDEX IF NOT EXISTS idx_archived_reports_time ON archived_reports (archived_at);`,
+	`CREATE TABLE IF NOT EXISTS issue_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		provider TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		finding_id TEXT NOT NULL,
+		resource TEXT NOT NULL DEFAULT '',
+		issue_number INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		cluster TEXT NOT NULL,
+		namespace TEXT NOT NULL,
+		report_type TEXT NOT NULL,
+		report_name TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		UNIQUE (provider, repo, finding_id, resource)
+	);
+	CREATE INDEX IF NOT EXISTS idx_issue_links_finding ON issue_links (finding_id);`,
+	`CREATE TABLE IF NOT EXISTS export_schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		cron TEXT NOT NULL,
+		format TEXT NOT NULL,
+		query TEXT NOT NULL,
+		destination TEXT NOT NULL,
+		target TEXT NOT NULL,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		last_run_at INTEGER,
+		last_status TEXT NOT NULL DEFAULT '',
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS report_blobs (
+		cluster TEXT NOT NULL,
+		namespace TEXT NOT NULL,
+		report_type TEXT NOT NULL,
+		report_name TEXT NOT NULL,
+		data BLOB NOT NULL,
+		size INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (cluster, namespace, report_type, report_name)
+	);`,
+	`CREATE TABLE IF NOT EXISTS report_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		cluster TEXT NOT NULL,
+		namespace TEXT NOT NULL,
+		workload TEXT NOT NULL,
+		report_type TEXT NOT NULL,
+		report_name TEXT NOT NULL,
+		image TEXT NOT NULL DEFAULT '',
+		severity TEXT NOT NULL DEFAULT '',
+		critical INTEGER NOT NULL DEFAULT 0,
+		high INTEGER NOT NULL DEFAULT 0,
+		medium INTEGER NOT NULL DEFAULT 0,
+		low INTEGER NOT NULL DEFAULT 0,
+		recorded_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_report_history_workload ON report_history (cluster, namespace, workload, recorded_at);`,
+	`CREATE TABLE IF NOT EXISTS mute_windows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		cluster TEXT NOT NULL DEFAULT '',
+		reason TEXT NOT NULL DEFAULT '',
+		starts_at INTEGER,
+		ends_at INTEGER,
+		cron TEXT NOT NULL DEFAULT '',
+		duration_minutes INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS cluster_tags (
+		cluster TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (cluster, key)
+	);`,
+	`CREATE TABLE IF NOT EXISTS jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		status TEXT NOT NULL,
+		params TEXT NOT NULL,
+		error TEXT NOT NULL DEFAULT '',
+		result_name TEXT NOT NULL DEFAULT '',
+		result_type TEXT NOT NULL DEFAULT '',
+		result_size INTEGER NOT NULL DEFAULT 0,
+		result BLOB,
+		created_at INTEGER NOT NULL,
+		started_at INTEGER,
+		finished_at INTEGER
+	);
+	CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs (status, id);`,
+	`CREATE TABLE IF NOT EXISTS severity_overrides (
+		cve_id TEXT PRIMARY KEY,
+		severity TEXT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		updated_at INTEGER NOT NULL
+	);`,
+	// triage records keyed by image digest apply to every report of the image, so the
+	// report columns become the origin of the record and are only unique without a digest
+	`CREATE TABLE triage_new (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		cluster TEXT NOT NULL,
+		namespace TEXT NOT NULL,
+		report_type TEXT NOT NULL,
+		report_name TEXT NOT NULL,
+		finding_id TEXT NOT NULL,
+		resource TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL,
+		assignee TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		image_digest TEXT NOT NULL DEFAULT ''
+	);
+	INSERT INTO triage_new (id, cluster, namespace, report_type, report_name, finding_id, resource, state, assignee, note, created_at, updated_at)
+		SELECT id, cluster, namespace, report_type, report_name, finding_id, resource, state, assignee, note, created_at, updated_at FROM triage;
+	DROP TABLE triage;
+	ALTER TABLE triage_new RENAME TO triage;
+	CREATE UNIQUE INDEX idx_triage_report ON triage (cluster, namespace, report_type, report_name, finding_id, resource) WHERE image_digest = '';
+	CREATE UNIQUE INDEX idx_triage_digest ON triage (image_digest, finding_id, resource) WHERE image_digest != '';
+	CREATE INDEX idx_triage_state ON triage (state);
+	CREATE INDEX idx_triage_assignee ON triage (assignee);`,
+	// the operator recreates TTL-deleted reports under the same name, so every deletion of
+	// a report is archived as a row of its own
+	`CREATE TABLE archived_reports_new (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		cluster TEXT NOT NULL,
+		namespace TEXT NOT NULL,
+		report_type TEXT NOT NULL,
+		report_name TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT '',
+		data TEXT NOT NULL,
+		archived_at INTEGER NOT NULL
+	);
+	INSERT INTO archived_reports_new (cluster, namespace, report_type, report_name, status, data, archived_at)
+		SELECT cluster, namespace, report_type, report_name, status, data, archived_at FROM archived_reports ORDER BY archived_at;
+	DROP TABLE archived_reports;
+	ALTER TABLE archived_reports_new RENAME TO archived_reports;
+	CREATE INDEX idx_archived_reports_time ON archived_reports (archived_at);
+	CREATE INDEX idx_archived_reports_report ON archived_reports (cluster, namespace, report_type, report_name);`,
+}
+
+func Open(path string) (*Store, error) {
+	// transactions take the write lock when they begin, so a read-check-write in one cannot
+	// be overtaken by another writer between its read and its write
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; serializing through one connection avoids SQLITE_BUSY storms
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db, path: path}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, applied_at INTEGER NOT NULL)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := current; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, i+1, time.Now().Unix()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		utils.LogDebug("Applied database migration", map[string]interface{}{"version": i + 1})
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Ping checks that the database is still reachable.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// SchemaVersion returns the applied migration version and the version this build expects.
+func (s *Store) SchemaVersion(ctx context.Context) (current, latest int, err error) {
+	err = s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	return current, len(migrations), err
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Init opens the global store; callers treat a nil Get() as "persistence disabled".
+func Init(path string) error {
+	s, err := Open(path)
+	if err != nil {
+		return err
+	}
+	storeMu.Lock()
+	globalStore = s
+	storeMu.Unlock()
+	return nil
+}
+
+func Get() *Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return globalStore
+}
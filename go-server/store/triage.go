@@ -0,0 +1,288 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TriageNew        = "new"
+	TriageTriaged    = "triaged"
+	TriageInProgress = "in-progress"
+	TriageFixed      = "fixed"
+	TriageAccepted   = "accepted"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// triageTransitions lists the states reachable from each state.
+// fixed and accepted can be reopened back to triaged.
+var triageTransitions = map[string][]string{
+	TriageNew:        {TriageTriaged, TriageAccepted},
+	TriageTriaged:    {TriageInProgress, TriageFixed, TriageAccepted},
+	TriageInProgress: {TriageTriaged, TriageFixed, TriageAccepted},
+	TriageFixed:      {TriageTriaged},
+	TriageAccepted:   {TriageTriaged},
+}
+
+// TriageRecord is the triage state of a finding. Records with an ImageDigest apply to the
+// finding in every report of that image, in any cluster or namespace; their report
+// fields are where the finding was triaged.
+type TriageRecord struct {
+	ID         int64     `json:"id"`
+	Cluster    string    `json:"cluster"`
+	Namespace  string    `json:"namespace"`
+	ReportType string    `json:"type"`
+	ReportName string    `json:"name"`
+	FindingID  string    `json:"findingId"`
+	Resource   string    `json:"resource,omitempty"`
+	State      string    `json:"state"`
+	Assignee   string    `json:"assignee,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	// ImageDigest keys the record by image instead of by report
+	ImageDigest string `json:"imageDigest,omitempty"`
+	// ClearAssignee and ClearNote make an update clear Assignee and Note, which an update
+	// otherwise keeps when they are empty
+	ClearAssignee bool `json:"-"`
+	ClearNote     bool `json:"-"`
+}
+
+// queryer is a *sql.DB or *sql.Tx.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type TriageFilter struct {
+	Cluster    string
+	Namespace  string
+	ReportType string
+	ReportName string
+	FindingID  string
+	States     []string
+	Assignee   string
+	// ImageDigest lists the records of one image; ReportOnly and DigestOnly list only the
+	// records keyed by report or by image digest
+	ImageDigest string
+	ReportOnly  bool
+	DigestOnly  bool
+}
+
+func IsValidTriageState(state string) bool {
+	_, ok := triageTransitions[state]
+	return ok
+}
+
+func CanTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range triageTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+const triageColumns = `id, cluster, namespace, report_type, report_name, finding_id, resource, state, assignee, note, created_at, updated_at, image_digest`
+
+func scanTriage(row interface{ Scan(...interface{}) error }) (TriageRecord, error) {
+	var rec TriageRecord
+	var created, updated int64
+	err := row.Scan(&rec.ID, &rec.Cluster, &rec.Namespace, &rec.ReportType, &rec.ReportName,
+		&rec.FindingID, &rec.Resource, &rec.State, &rec.Assignee, &rec.Note, &created, &updated, &rec.ImageDigest)
+	if err != nil {
+		return rec, err
+	}
+	rec.CreatedAt = time.Unix(created, 0).UTC()
+	rec.UpdatedAt = time.Unix(updated, 0).UTC()
+	return rec, nil
+}
+
+func (s *Store) GetTriage(ctx context.Context, id int64) (TriageRecord, error) {
+	return getTriage(ctx, s.db, id)
+}
+
+func getTriage(ctx context.Context, q queryer, id int64) (TriageRecord, error) {
+	rec, err := scanTriage(q.QueryRowContext(ctx, `SELECT `+triageColumns+` FROM triage WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return rec, ErrNotFound
+	}
+	return rec, err
+}
+
+func findTriage(ctx context.Context, q queryer, rec TriageRecord) (TriageRecord, error) {
+	query := `SELECT ` + triageColumns + ` FROM triage
+		WHERE image_digest = '' AND cluster = ? AND namespace = ? AND report_type = ? AND report_name = ? AND finding_id = ? AND resource = ?`
+	args := []interface{}{rec.Cluster, rec.Namespace, rec.ReportType, rec.ReportName, rec.FindingID, rec.Resource}
+	if rec.ImageDigest != "" {
+		query = `SELECT ` + triageColumns + ` FROM triage WHERE image_digest = ? AND finding_id = ? AND resource = ?`
+		args = []interface{}{rec.ImageDigest, rec.FindingID, rec.Resource}
+	}
+	existing, err := scanTriage(q.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return existing, ErrNotFound
+	}
+	return existing, err
+}
+
+// UpsertTriage creates or updates the triage record for a finding, enforcing the
+// workflow transitions. Empty State/Assignee/Note on an update keep the stored value
+// unless ClearAssignee or ClearNote is set. With an ImageDigest the record is the
+// image's, and an update keeps the report fields it was created with. The lookup, the
+// transition check and the write run in one transaction.
+func (s *Store) UpsertTriage(ctx context.Context, rec TriageRecord) (TriageRecord, error) {
+	if rec.ImageDigest != "" {
+		if rec.FindingID == "" {
+			return rec, fmt.Errorf("findingId is required")
+		}
+	} else if rec.Cluster == "" || rec.ReportType == "" || rec.ReportName == "" || rec.FindingID == "" {
+		return rec, fmt.Errorf("cluster, type, name and findingId are required")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return rec, err
+	}
+	defer tx.Rollback()
+	existing, err := findTriage(ctx, tx, rec)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return rec, err
+	}
+	if errors.Is(err, ErrNotFound) {
+		existing = TriageRecord{State: TriageNew}
+	}
+	if rec, err = applyTriage(ctx, tx, existing, rec); err != nil {
+		return rec, err
+	}
+	return rec, tx.Commit()
+}
+
+// UpdateTriage updates an existing record by ID.
+func (s *Store) UpdateTriage(ctx context.Context, id int64, changes TriageRecord) (TriageRecord, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return changes, err
+	}
+	defer tx.Rollback()
+	existing, err := getTriage(ctx, tx, id)
+	if err != nil {
+		return existing, err
+	}
+	changes.Cluster = existing.Cluster
+	changes.Namespace = existing.Namespace
+	changes.ReportType = existing.ReportType
+	changes.ReportName = existing.ReportName
+	changes.FindingID = existing.FindingID
+	changes.Resource = existing.Resource
+	changes.ImageDigest = existing.ImageDigest
+	rec, err := applyTriage(ctx, tx, existing, changes)
+	if err != nil {
+		return rec, err
+	}
+	return rec, tx.Commit()
+}
+
+func applyTriage(ctx context.Context, q queryer, existing, changes TriageRecord) (TriageRecord, error) {
+	state := existing.State
+	if changes.State != "" {
+		if !IsValidTriageState(changes.State) {
+			return existing, fmt.Errorf("invalid state %q", changes.State)
+		}
+		if !CanTransition(existing.State, changes.State) {
+			return existing, fmt.Errorf("cannot transition from %s to %s", existing.State, changes.State)
+		}
+		state = changes.State
+	}
+	assignee := existing.Assignee
+	if changes.Assignee != "" || changes.ClearAssignee {
+		assignee = changes.Assignee
+	}
+	note := existing.Note
+	if changes.Note != "" || changes.ClearNote {
+		note = changes.Note
+	}
+
+	now := time.Now().Unix()
+	if existing.ID == 0 {
+		res, err := q.ExecContext(ctx, `INSERT INTO triage (cluster, namespace, report_type, report_name, finding_id, resource, state, assignee, note, created_at, updated_at, image_digest)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			changes.Cluster, changes.Namespace, changes.ReportType, changes.ReportName, changes.FindingID, changes.Resource,
+			state, assignee, note, now, now, changes.ImageDigest)
+		if err != nil {
+			return existing, fmt.Errorf("failed to insert triage record: %w", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return existing, err
+		}
+		return getTriage(ctx, q, id)
+	}
+
+	if _, err := q.ExecContext(ctx, `UPDATE triage SET state = ?, assignee = ?, note = ?, updated_at = ? WHERE id = ?`,
+		state, assignee, note, now, existing.ID); err != nil {
+		return existing, fmt.Errorf("failed to update triage record: %w", err)
+	}
+	return getTriage(ctx, q, existing.ID)
+}
+
+func (s *Store) ListTriage(ctx context.Context, f TriageFilter) ([]TriageRecord, error) {
+	var where []string
+	var args []interface{}
+	add := func(col, val string) {
+		if val != "" {
+			where = append(where, col+" = ?")
+			args = append(args, val)
+		}
+	}
+	add("cluster", f.Cluster)
+	add("namespace", f.Namespace)
+	add("report_type", f.ReportType)
+	add("report_name", f.ReportName)
+	add("finding_id", f.FindingID)
+	add("assignee", f.Assignee)
+	add("image_digest", f.ImageDigest)
+	if f.ReportOnly {
+		where = append(where, "image_digest = ''")
+	}
+	if f.DigestOnly {
+		where = append(where, "image_digest != ''")
+	}
+	if len(f.States) > 0 {
+		placeholders := make([]string, len(f.States))
+		for i, st := range f.States {
+			placeholders[i] = "?"
+			args = append(args, st)
+		}
+		where = append(where, "state IN ("+strings.Join(placeholders, ",")+")")
+	}
+
+	query := `SELECT ` + triageColumns + ` FROM triage`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY updated_at DESC, id DESC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list triage records: %w", err)
+	}
+	defer rows.Close()
+
+	records := []TriageRecord{}
+	for rows.Next() {
+		rec, err := scanTriage(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func baseRecord() TriageRecord {
+	return TriageRecord{
+		Cluster:    "c1",
+		Namespace:  "default",
+		ReportType: "vulnerabilityreports",
+		ReportName: "replicaset-app",
+		FindingID:  "CVE-2024-0001",
+		Resource:   "openssl",
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{TriageNew, TriageTriaged, true},
+		{TriageNew, TriageFixed, false},
+		{TriageTriaged, TriageInProgress, true},
+		{TriageInProgress, TriageFixed, true},
+		{TriageFixed, TriageTriaged, true},
+		{TriageFixed, TriageInProgress, false},
+		{TriageAccepted, TriageAccepted, true},
+	}
+	for _, tc := range cases {
+		if got := CanTransition(tc.from, tc.to); got != tc.want {
+			t.Errorf("CanTransition(%s,%s)=%v want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestUpsertTriage_CreateAndUpdate(t *testing.T) {
+	s := newTestStore(t)
+
+	rec := baseRecord()
+	rec.State = TriageTriaged
+	rec.Assignee = "team-payments"
+	created, err := s.UpsertTriage(t.Context(), rec)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ID == 0 || created.State != TriageTriaged || created.Assignee != "team-payments" {
+		t.Fatalf("unexpected record: %+v", created)
+	}
+
+	update := baseRecord()
+	update.State = TriageInProgress
+	updated, err := s.UpsertTriage(t.Context(), update)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.ID != created.ID || updated.State != TriageInProgress || updated.Assignee != "team-payments" {
+		t.Fatalf("unexpected update: %+v", updated)
+	}
+}
+
+func TestUpsertTriage_ClearsAssigneeAndNote(t *testing.T) {
+	s := newTestStore(t)
+
+	rec := baseRecord()
+	rec.State = TriageTriaged
+	rec.Assignee = "team-payments"
+	rec.Note = "waiting for upstream"
+	if _, err := s.UpsertTriage(t.Context(), rec); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	update := baseRecord()
+	update.ClearAssignee = true
+	updated, err := s.UpsertTriage(t.Context(), update)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.Assignee != "" || updated.Note != "waiting for upstream" {
+		t.Fatalf("expected only the assignee cleared, got %+v", updated)
+	}
+	updated, err = s.UpdateTriage(t.Context(), updated.ID, TriageRecord{ClearNote: true})
+	if err != nil || updated.Note != "" || updated.State != TriageTriaged {
+		t.Fatalf("expected the note cleared, got %+v %v", updated, err)
+	}
+}
+
+func TestUpsertTriage_Concurrent(t *testing.T) {
+	s := newTestStore(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := baseRecord()
+			rec.State = TriageTriaged
+			if _, err := s.UpsertTriage(t.Context(), rec); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent upsert: %v", err)
+	}
+	if got, _ := s.ListTriage(t.Context(), TriageFilter{}); len(got) != 1 {
+		t.Fatalf("expected one record, got %+v", got)
+	}
+}
+
+func TestUpsertTriage_RejectsInvalidTransition(t *testing.T) {
+	s := newTestStore(t)
+
+	rec := baseRecord()
+	rec.State = TriageFixed
+	if _, err := s.UpsertTriage(t.Context(), rec); err == nil {
+		t.Fatal("expected new -> fixed to be rejected")
+	}
+}
+
+func TestListTriage_Filters(t *testing.T) {
+	s := newTestStore(t)
+
+	a := baseRecord()
+	a.State = TriageTriaged
+	a.Assignee = "alice"
+	b := baseRecord()
+	b.FindingID = "CVE-2024-0002"
+	b.State = TriageAccepted
+	b.Assignee = "bob"
+	for _, r := range []TriageRecord{a, b} {
+		if _, err := s.UpsertTriage(t.Context(), r); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+
+	got, err := s.ListTriage(t.Context(), TriageFilter{States: []string{TriageAccepted}})
+	if err != nil || len(got) != 1 || got[0].FindingID != "CVE-2024-0002" {
+		t.Fatalf("state filter: %v %+v", err, got)
+	}
+	got, err = s.ListTriage(t.Context(), TriageFilter{Assignee: "alice"})
+	if err != nil || len(got) != 1 || got[0].FindingID != "CVE-2024-0001" {
+		t.Fatalf("assignee filter: %v %+v", err, got)
+	}
+}
+
+func TestUpdateTriage_NotFound(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.UpdateTriage(t.Context(), 42, TriageRecord{State: TriageTriaged}); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound got %v", err)
+	}
+}
+
+func TestCanceledContextAbortsQueries(t *testing.T) {
+	s := newTestStore(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	if _, err := s.UpsertTriage(ctx, baseRecord()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("upsert with canceled context: got %v, want context.Canceled", err)
+	}
+	if _, err := s.ListTriage(ctx, TriageFilter{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("list with canceled context: got %v, want context.Canceled", err)
+	}
+	records, err := s.ListTriage(t.Context(), TriageFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("canceled upsert stored %d records", len(records))
+	}
+}
+
+func TestUpsertTriage_ImageDigest(t *testing.T) {
+	s := newTestStore(t)
+
+	own := baseRecord()
+	own.State = TriageTriaged
+	if _, err := s.UpsertTriage(t.Context(), own); err != nil {
+		t.Fatalf("report record: %v", err)
+	}
+
+	image := baseRecord()
+	image.ImageDigest = "sha256:abc"
+	image.State = TriageAccepted
+	image.Note = "not reachable"
+	created, err := s.UpsertTriage(t.Context(), image)
+	if err != nil {
+		t.Fatalf("image record: %v", err)
+	}
+	if created.ImageDigest != "sha256:abc" || created.State != TriageAccepted {
+		t.Fatalf("unexpected record: %+v", created)
+	}
+
+	// the same finding of the image triaged from another cluster updates the record
+	elsewhere := TriageRecord{Cluster: "c2", Namespace: "web", ReportType: "vulnerabilityreports", ReportName: "replicaset-web",
+		FindingID: "CVE-2024-0001", Resource: "openssl", ImageDigest: "sha256:abc", Assignee: "team-web"}
+	updated, err := s.UpsertTriage(t.Context(), elsewhere)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.ID != created.ID || updated.Cluster != "c1" || updated.Assignee != "team-web" || updated.Note != "not reachable" {
+		t.Fatalf("expected the image record updated in place, got %+v", updated)
+	}
+
+	all, err := s.ListTriage(t.Context(), TriageFilter{Cluster: "c1"})
+	if err != nil || len(all) != 2 {
+		t.Fatalf("expected the report and image records, got %d: %v", len(all), err)
+	}
+	reportOnly, _ := s.ListTriage(t.Context(), TriageFilter{Cluster: "c1", ReportOnly: true})
+	byDigest, _ := s.ListTriage(t.Context(), TriageFilter{ImageDigest: "sha256:abc"})
+	if len(reportOnly) != 1 || reportOnly[0].ImageDigest != "" || len(byDigest) != 1 || byDigest[0].ID != created.ID {
+		t.Fatalf("unexpected filtered records %+v %+v", reportOnly, byDigest)
+	}
+
+	if _, err := s.UpsertTriage(t.Context(), TriageRecord{ImageDigest: "sha256:abc"}); err == nil {
+		t.Fatal("expected findingId to be required")
+	}
+}
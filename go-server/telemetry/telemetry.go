@@ -0,0 +1,105 @@
+// Package telemetry sends opt-in anonymous usage statistics. The payload holds coarse
+// buckets and feature names only: no cluster, namespace, image or user names.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	"trivy-ui/utils"
+)
+
+// Payload is everything a telemetry report contains.
+type Payload struct {
+	Version  string `json:"version"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Clusters string `json:"clusters"`
+	Reports  string `json:"reports"`
+	// Features are the names of the optional features that are configured
+	Features []string `json:"features"`
+}
+
+var (
+	clusterBuckets = []int{1, 5, 20, 100}
+	reportBuckets  = []int{100, 1000, 10000, 100000}
+)
+
+// NewPayload buckets the cluster and report counts.
+func NewPayload(version string, clusters, reports int, features []string) Payload {
+	if features == nil {
+		features = []string{}
+	}
+	return Payload{
+		Version:  version,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Clusters: Bucket(clusters, clusterBuckets),
+		Reports:  Bucket(reports, reportBuckets),
+		Features: features,
+	}
+}
+
+// Bucket names the range of bounds n falls in, e.g. "2-5" for 3 with bounds 1, 5, 20:
+// "0", "1", "2-5", "6-20" and "21+".
+func Bucket(n int, bounds []int) string {
+	if n <= 0 {
+		return "0"
+	}
+	low := 1
+	for _, high := range bounds {
+		if n <= high {
+			if low == high {
+				return fmt.Sprint(high)
+			}
+			return fmt.Sprintf("%d-%d", low, high)
+		}
+		low = high + 1
+	}
+	return fmt.Sprintf("%d+", low)
+}
+
+// Send posts a payload to endpoint.
+func Send(ctx context.Context, client *http.Client, endpoint string, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Run sends the payload built by collect to endpoint every interval, starting after
+// the first interval so a crashing pod does not report on every restart.
+func Run(ctx context.Context, endpoint string, interval time.Duration, collect func() Payload) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := Send(ctx, client, endpoint, collect()); err != nil {
+			utils.LogDebug("Failed to send telemetry", map[string]interface{}{"error": err.Error()})
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBucket(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 1: "1", 3: "2-5", 20: "6-20", 21: "21-100", 500: "101+"} {
+		if got := Bucket(n, clusterBuckets); got != want {
+			t.Errorf("Bucket(%d) = %q, want %q", n, got, want)
+		}
+	}
+	if got := Bucket(4200, reportBuckets); got != "1001-10000" {
+		t.Errorf("report bucket = %q", got)
+	}
+}
+
+func TestSend(t *testing.T) {
+	var got Payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewPayload("1.2.3", 3, 42, []string{"database"})
+	if err := Send(t.Context(), srv.Client(), srv.URL, p); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "1.2.3" || got.Clusters != "2-5" || got.Reports != "1-100" || len(got.Features) != 1 {
+		t.Fatalf("sent %+v", got)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -24,20 +26,75 @@ var levelOrder = map[LogLevel]int{
 	LevelError:   3,
 }
 
-var currentLevel LogLevel
+// Modules whose debug logs can be enabled on their own, at any log level.
+const (
+	ModuleInformer = "informer"
+	ModuleCache    = "cache"
+	ModuleHandlers = "handlers"
+)
+
+// LogModules lists the modules of LogModuleDebug.
+var LogModules = []string{ModuleInformer, ModuleCache, ModuleHandlers}
+
+// logSettings is swapped as a whole so changing it at runtime needs no lock on the log path.
+type logSettings struct {
+	level        LogLevel
+	debugModules map[string]bool
+}
+
+var settings atomic.Pointer[logSettings]
 
 func init() {
-	raw := strings.ToLower(strings.TrimSpace(os.Getenv("LOG_LEVEL")))
-	switch raw {
+	level, ok := ParseLogLevel(os.Getenv("LOG_LEVEL"))
+	if !ok {
+		level = LevelInfo
+	}
+	modules := make(map[string]bool)
+	for _, m := range strings.Split(os.Getenv("LOG_DEBUG_MODULES"), ",") {
+		if m = strings.ToLower(strings.TrimSpace(m)); m != "" {
+			modules[m] = true
+		}
+	}
+	settings.Store(&logSettings{level: level, debugModules: modules})
+}
+
+// ParseLogLevel parses a log level name, accepting warn for warning.
+func ParseLogLevel(s string) (LogLevel, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
 	case "debug":
-		currentLevel = LevelDebug
+		return LevelDebug, true
+	case "info":
+		return LevelInfo, true
 	case "warning", "warn":
-		currentLevel = LevelWarning
+		return LevelWarning, true
 	case "error":
-		currentLevel = LevelError
-	default:
-		currentLevel = LevelInfo
+		return LevelError, true
+	}
+	return "", false
+}
+
+// GetLogLevel returns the current log level.
+func GetLogLevel() LogLevel {
+	return settings.Load().level
+}
+
+// DebugModules returns the modules whose debug logs are enabled, sorted.
+func DebugModules() []string {
+	modules := []string{}
+	for m := range settings.Load().debugModules {
+		modules = append(modules, m)
 	}
+	sort.Strings(modules)
+	return modules
+}
+
+// SetLogConfig changes the log level and the modules with debug logs at runtime.
+func SetLogConfig(level LogLevel, debugModules []string) {
+	modules := make(map[string]bool, len(debugModules))
+	for _, m := range debugModules {
+		modules[m] = true
+	}
+	settings.Store(&logSettings{level: level, debugModules: modules})
 }
 
 type LogEntry struct {
@@ -48,9 +105,13 @@ type LogEntry struct {
 }
 
 func logJSON(level LogLevel, message string, fields map[string]interface{}) {
-	if levelOrder[level] < levelOrder[currentLevel] {
+	if levelOrder[level] < levelOrder[GetLogLevel()] {
 		return
 	}
+	writeLog(level, message, fields)
+}
+
+func writeLog(level LogLevel, message string, fields map[string]interface{}) {
 	entry := LogEntry{
 		Timestamp: time.Now().Format(time.RFC3339),
 		Level:     level,
@@ -84,6 +145,22 @@ func LogDebug(message string, fields ...map[string]interface{}) {
 	logJSON(LevelDebug, message, f)
 }
 
+// LogModuleDebug logs at debug level when the log level is debug or the module's debug
+// logs are enabled.
+func LogModuleDebug(module, message string, fields ...map[string]interface{}) {
+	cur := settings.Load()
+	if cur.level != LevelDebug && !cur.debugModules[module] {
+		return
+	}
+	f := map[string]interface{}{"module": module}
+	if len(fields) > 0 {
+		for k, v := range fields[0] {
+			f[k] = v
+		}
+	}
+	writeLog(LevelDebug, message, f)
+}
+
 func LogInfo(message string, fields ...map[string]interface{}) {
 	var f map[string]interface{}
 	if len(fields) > 0 {
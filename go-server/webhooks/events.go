@@ -0,0 +1,129 @@
+// Package webhooks defines the versioned events trivy-ui sends to outbound webhooks and
+// signs their deliveries with HMAC-SHA256, so receivers can check they come from
+// trivy-ui and were not replayed.
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is the version of the event envelope and data. Fields are only added
+// within a version; renaming or removing one starts a new version.
+const SchemaVersion = "v1"
+
+// Event types.
+const (
+	// EventReportCreated: a report appeared that trivy-ui had not seen before
+	EventReportCreated = "report.created"
+	// EventSeverityChanged: a rescan changed the highest severity of a report
+	EventSeverityChanged = "report.severity_changed"
+	// EventPolicyViolated: a config audit report started to violate a Pod Security Standard
+	EventPolicyViolated = "policy.violated"
+	// EventTest is sent by /api/v1/webhooks/test when no type is asked for
+	EventTest = "webhook.test"
+)
+
+// PolicyPodSecurity is the policy of policy.violated events.
+const PolicyPodSecurity = "pod-security-standards"
+
+// EventTypes lists the types in the catalog, in the order it documents them.
+var EventTypes = []string{EventReportCreated, EventSeverityChanged, EventPolicyViolated, EventTest}
+
+// Event is the envelope of every delivery.
+type Event struct {
+	Version string      `json:"version"`
+	ID      string      `json:"id"`
+	Type    string      `json:"type"`
+	Time    time.Time   `json:"time"`
+	Data    interface{} `json:"data"`
+}
+
+// ReportRef identifies the report an event is about.
+type ReportRef struct {
+	Cluster    string `json:"cluster"`
+	Namespace  string `json:"namespace,omitempty"`
+	ReportType string `json:"reportType"`
+	Name       string `json:"name"`
+}
+
+// SeverityCounts are the findings of a report by severity.
+type SeverityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+}
+
+// ReportCreated is the data of report.created.
+type ReportCreated struct {
+	Report   ReportRef      `json:"report"`
+	Severity string         `json:"severity"`
+	Summary  SeverityCounts `json:"summary"`
+	// Image is the scanned image of vulnerability reports
+	Image string `json:"image,omitempty"`
+}
+
+// SeverityChanged is the data of report.severity_changed.
+type SeverityChanged struct {
+	Report           ReportRef      `json:"report"`
+	PreviousSeverity string         `json:"previousSeverity"`
+	Severity         string         `json:"severity"`
+	Summary          SeverityCounts `json:"summary"`
+	Image            string         `json:"image,omitempty"`
+}
+
+// ViolatedControl is a failed check and the Pod Security Standards control it enforces.
+type ViolatedControl struct {
+	CheckID string `json:"checkID"`
+	Control string `json:"control"`
+	Level   string `json:"level"`
+}
+
+// PolicyViolated is the data of policy.violated.
+type PolicyViolated struct {
+	Report ReportRef `json:"report"`
+	Policy string    `json:"policy"`
+	// Level is the least restrictive standard violated: baseline, or restricted when the
+	// report still satisfies baseline
+	Level    string            `json:"level"`
+	Controls []ViolatedControl `json:"controls"`
+}
+
+// Test is the data of webhook.test.
+type Test struct {
+	Message string `json:"message"`
+}
+
+// NewEvent wraps data in an envelope with a new ID.
+func NewEvent(eventType string, data interface{}, now time.Time) Event {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return Event{Version: SchemaVersion, ID: hex.EncodeToString(id), Type: eventType, Time: now.UTC(), Data: data}
+}
+
+// Sample returns an example event of a type, as sent by /api/v1/webhooks/test.
+func Sample(eventType string, now time.Time) (Event, error) {
+	report := ReportRef{Cluster: "example", Namespace: "default", ReportType: "vulnerabilityreports", Name: "replicaset-web-6d4cf56db6-nginx"}
+	summary := SeverityCounts{Critical: 1, High: 4, Medium: 12, Low: 3}
+	var data interface{}
+	switch eventType {
+	case EventReportCreated:
+		data = ReportCreated{Report: report, Severity: "Critical", Summary: summary, Image: "docker.io/library/nginx:1.25"}
+	case EventSeverityChanged:
+		data = SeverityChanged{Report: report, PreviousSeverity: "High", Severity: "Critical", Summary: summary, Image: "docker.io/library/nginx:1.25"}
+	case EventPolicyViolated:
+		report.ReportType, report.Name = "configauditreports", "replicaset-web-6d4cf56db6"
+		data = PolicyViolated{Report: report, Policy: PolicyPodSecurity, Level: "baseline", Controls: []ViolatedControl{
+			{CheckID: "KSV017", Control: "Privileged Containers", Level: "baseline"},
+			{CheckID: "KSV012", Control: "Running as Non-root", Level: "restricted"},
+		}}
+	case EventTest:
+		data = Test{Message: "Test event from trivy-ui"}
+	default:
+		return Event{}, fmt.Errorf("unknown event type %q", eventType)
+	}
+	return NewEvent(eventType, data, now), nil
+}
@@ -0,0 +1,8 @@
+package webhooks
+
+import _ "embed"
+
+// Schema is the JSON Schema of the events of SchemaVersion.
+//
+//go:embed schema.json
+var Schema []byte
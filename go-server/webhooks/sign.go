@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery headers. The signature is "sha256=" and the hex HMAC-SHA256, keyed with the
+// shared secret, of the timestamp, a dot and the body.
+const (
+	HeaderEvent     = "X-Trivy-UI-Event"
+	HeaderDelivery  = "X-Trivy-UI-Delivery"
+	HeaderTimestamp = "X-Trivy-UI-Timestamp"
+	HeaderSignature = "X-Trivy-UI-Signature"
+)
+
+// Signature computes the signature header value of a body sent at timestamp.
+func Signature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign sets the timestamp and, when secret is set, the signature headers of a request
+// with body.
+func Sign(req *http.Request, secret string, body []byte, now time.Time) {
+	ts := now.Unix()
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Signature(secret, ts, body))
+	}
+}
+
+// Verify checks the signature headers of a delivery, rejecting timestamps further than
+// tolerance from now so a captured delivery cannot be replayed later.
+func Verify(header http.Header, secret string, body []byte, tolerance time.Duration, now time.Time) error {
+	ts, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid timestamp")
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return errors.New("timestamp outside the tolerance")
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Signature(secret, ts, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// Send posts an event to url, signed with secret.
+func Send(ctx context.Context, client *http.Client, url, secret string, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, e.Type)
+	req.Header.Set(HeaderDelivery, e.ID)
+	Sign(req, secret, body, time.Now())
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	body := []byte(`{"type":"webhook.test"}`)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	Sign(req, "secret", body, now)
+
+	if err := Verify(req.Header, "secret", body, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Fatalf("expected a valid signature, got %v", err)
+	}
+	if err := Verify(req.Header, "other", body, 5*time.Minute, now); err == nil {
+		t.Fatal("expected a wrong secret to fail")
+	}
+	if err := Verify(req.Header, "secret", []byte(`{"type":"report.created"}`), 5*time.Minute, now); err == nil {
+		t.Fatal("expected a tampered body to fail")
+	}
+	if err := Verify(req.Header, "secret", body, 5*time.Minute, now.Add(10*time.Minute)); err == nil {
+		t.Fatal("expected a replayed delivery to fail")
+	}
+
+	unsigned := httptest.NewRequest(http.MethodPost, "/", nil)
+	Sign(unsigned, "", body, now)
+	if unsigned.Header.Get(HeaderTimestamp) == "" || unsigned.Header.Get(HeaderSignature) != "" {
+		t.Fatalf("expected only a timestamp without a secret, got %v", unsigned.Header)
+	}
+}
+
+func TestSend(t *testing.T) {
+	var got Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify(r.Header, "secret", body, time.Minute, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(HeaderEvent) != EventTest {
+			http.Error(w, "wrong event header", http.StatusBadRequest)
+			return
+		}
+		json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	e, _ := Sample(EventTest, time.Now())
+	if err := Send(context.Background(), srv.Client(), srv.URL, "secret", e); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != e.ID || got.Version != SchemaVersion {
+		t.Fatalf("unexpected event received %+v", got)
+	}
+	if err := Send(context.Background(), srv.Client(), srv.URL, "wrong", e); err == nil {
+		t.Fatal("expected the rejected delivery to fail")
+	}
+}
+
+// TestSamplesMatchSchema checks that each sample carries the fields the schema requires.
+func TestSamplesMatchSchema(t *testing.T) {
+	var schema struct {
+		Required   []string `json:"required"`
+		Properties struct {
+			Type struct {
+				Enum []string `json:"enum"`
+			} `json:"type"`
+		} `json:"properties"`
+		Defs map[string]struct {
+			Required []string `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Properties.Type.Enum) != len(EventTypes) {
+		t.Fatalf("schema types %v do not match %v", schema.Properties.Type.Enum, EventTypes)
+	}
+	defs := map[string]string{
+		EventReportCreated:   "reportCreated",
+		EventSeverityChanged: "severityChanged",
+		EventPolicyViolated:  "policyViolated",
+		EventTest:            "test",
+	}
+	for _, typ := range EventTypes {
+		e, err := Sample(typ, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := json.Marshal(e)
+		var doc map[string]interface{}
+		json.Unmarshal(raw, &doc)
+		for _, field := range schema.Required {
+			if _, ok := doc[field]; !ok {
+				t.Errorf("%s: missing %s", typ, field)
+			}
+		}
+		data := doc["data"].(map[string]interface{})
+		for _, field := range schema.Defs[defs[typ]].Required {
+			if _, ok := data[field]; !ok {
+				t.Errorf("%s: missing data.%s", typ, field)
+			}
+		}
+	}
+	if _, err := Sample("report.deleted", time.Now()); err == nil {
+		t.Fatal("expected unknown types to fail")
+	}
+}
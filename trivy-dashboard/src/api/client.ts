@@ -20,12 +20,26 @@ export interface Cluster {
   name: string
   description?: string
   syncState?: string
+  apiServerUrl?: string
+  kubernetesVersion?: string
+  nodeCount?: number
+  platform?: string
+  pushed?: boolean
 }
 
+export interface NamespaceSuggestion {
+  cluster: string
+  namespace: string
+  reports: number
+}
+
+export type NamespaceScanStatus = "scanned" | "excluded" | "notTargeted" | "unknown"
+
 export interface Namespace {
   cluster: string
   name: string
   description?: string
+  scanStatus?: NamespaceScanStatus
 }
 
 export interface Report {
@@ -36,6 +50,25 @@ export interface Report {
   status?: string
   data: any
   updated_at?: string
+  scannedAt?: string
+  cachedAt?: string
+  // creationTimestamp is UTC; age is humanized server-side, e.g. 1d2h
+  creationTimestamp?: string
+  age?: string
+  effectiveSeverity?: string
+  effectiveSummary?: { critical: number; high: number; medium: number; low: number }
+  exposure?: ReportExposure
+  customLinks?: Record<string, string>
+}
+
+export interface ReportExposure {
+  privileged: boolean
+  runAsRoot: boolean
+  hostNetwork: boolean
+  hostPID: boolean
+  hostIPC: boolean
+  exposed: boolean
+  multiplier: number
 }
 
 export interface PaginatedResponse<T> {
@@ -137,6 +170,12 @@ export const api = {
     return fetchApi<Namespace[]>(`/api/clusters/${cluster}/namespaces`)
   },
 
+  suggestNamespaces: (q: string, clusters?: string[], signal?: AbortSignal): Promise<NamespaceSuggestion[]> => {
+    const params = new URLSearchParams({ q })
+    if (clusters?.length) params.set("clusters", clusters.join(","))
+    return fetchApi<NamespaceSuggestion[]>(`/api/v1/namespaces/suggest?${params.toString()}`, signal)
+  },
+
   getTypes: (): Promise<ReportType[]> => {
     return fetchApi<ReportType[]>("/api/v1/type")
   },
@@ -148,7 +187,8 @@ export const api = {
     cluster?: string,
     namespace?: string,
     search?: string,
-    onlyVulnerable?: boolean
+    onlyVulnerable?: boolean,
+    filter?: string
   ): Promise<PaginatedResponse<Report>> => {
     const params = new URLSearchParams()
     if (page) params.set("page", page.toString())
@@ -157,6 +197,7 @@ export const api = {
     if (namespace) params.set("namespace", namespace)
     if (search) params.set("search", search)
     if (onlyVulnerable !== undefined) params.set("onlyVulnerable", onlyVulnerable.toString())
+    if (filter) params.set("filter", filter)
     const query = params.toString()
     const url = `/api/v1/reports?type=${typeName}${query ? `&${query}` : ""}`
     return fetchApi<PaginatedResponse<Report>>(url)
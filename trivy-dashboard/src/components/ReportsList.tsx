@@ -54,6 +54,8 @@ export function ReportsList({
   const [copiedReportId, setCopiedReportId] = useState<string | null>(null)
   const [copiedField, setCopiedField] = useState<string | null>(null)
   const [namespacesLoaded, setNamespacesLoaded] = useState(false)
+  // Namespaces trivy-operator is configured not to scan, keyed by name
+  const [unscannedNamespaces, setUnscannedNamespaces] = useState<Record<string, string>>({})
   const observerTarget = useRef<HTMLDivElement>(null)
   const containerRef = useRef<HTMLDivElement>(null)
   const isFirstLoad = useRef(true)
@@ -253,7 +255,14 @@ export function ReportsList({
     try {
       const data = await api.getNamespacesByCluster(selectedCluster)
       const nsList = data.map((ns) => ns.name).sort()
+      const unscanned: Record<string, string> = {}
+      for (const ns of data) {
+        if (ns.scanStatus === "excluded" || ns.scanStatus === "notTargeted") {
+          unscanned[ns.name] = ns.scanStatus
+        }
+      }
       setNamespaces(nsList)
+      setUnscannedNamespaces(unscanned)
       setNamespacesLoaded(true)
       return nsList
     } catch (err) {
@@ -434,9 +443,14 @@ export function ReportsList({
   const namespaceOptions = useMemo(() => {
     return [
       { value: "all", label: "All Namespaces" },
-      ...namespaces.map((ns) => ({ value: ns, label: ns })),
+      ...namespaces.map((ns) => ({
+        value: ns,
+        label: unscannedNamespaces[ns] ? `${ns} (not scanned)` : ns,
+      })),
     ]
-  }, [namespaces])
+  }, [namespaces, unscannedNamespaces])
+
+  const selectedUnscanned = selectedNamespaces.filter((ns) => unscannedNamespaces[ns])
 
   if (loading) {
     return (
@@ -565,7 +579,9 @@ export function ReportsList({
             {reports.length === 0 ? "No reports found" : "No reports match the filter"}
           </div>
           <p className="text-sm text-muted-foreground/70 mt-1">
-            Try adjusting your search or filter criteria
+            {reports.length === 0 && selectedUnscanned.length > 0
+              ? `trivy-operator is not configured to scan ${selectedUnscanned.join(", ")}`
+              : "Try adjusting your search or filter criteria"}
           </p>
         </div>
       ) : (
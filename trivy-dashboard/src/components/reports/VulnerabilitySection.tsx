@@ -20,6 +20,7 @@ interface Vulnerability {
   fixedVersion?: string
   primaryLink?: string
   links?: string[]
+  customLinks?: Record<string, string>
   score?: number
   cvss?: {
     nvd?: {
@@ -267,6 +268,19 @@ export function VulnerabilitySection({ vulnerabilities }: VulnerabilitySectionPr
                                 View CVE
                               </a>
                             )}
+                            {Object.entries(vuln.customLinks || {}).map(([name, href]) => (
+                              <a
+                                key={name}
+                                href={href}
+                                target="_blank"
+                                rel="noopener noreferrer"
+                                onClick={(e) => e.stopPropagation()}
+                                className="inline-flex items-center gap-1.5 px-2 py-1 text-xs font-medium text-primary bg-primary/10 hover:bg-primary/20 rounded-md transition-colors"
+                              >
+                                <ExternalLink className="h-3 w-3" />
+                                {name}
+                              </a>
+                            ))}
                             {cvssScore && (
                               <span
                                 className={`text-xs font-medium px-2 py-0.5 rounded-full ${getSeverityBadgeColor(vuln.severity || "")}`}